@@ -64,8 +64,9 @@ func (c *Client) checkMemoRequired(transaction *txnbuild.Transaction) error {
 			continue
 		}
 
-		// TODO: once we support M-strkeys (SEP23), also check whether the destination
-		//       is a muxed account with a memo ID.
+		// Validate only accepts account ids (G...) as destinations, txnbuild
+		// doesn't build multiplexed destinations which would carry their own
+		// memo id, so the memo of the transaction is always checked.
 
 		if destinations[destination] {
 			continue
@@ -102,6 +103,57 @@ func (c *Client) checkMemoRequired(transaction *txnbuild.Transaction) error {
 // sendRequestURL sends a url to a horizon server.
 // It can be used for requests that do not implement the HorizonRequest interface.
 func (c *Client) sendRequestURL(requestURL string, method string, a interface{}) (err error) {
+	return c.sendRequestURLWithHeaders(requestURL, method, nil, a)
+}
+
+// sendPageRequestURL sends the url of the next or previous page of results to
+// a horizon server. When ConsistentPaging is set, the request is pinned to an
+// instance at least as up to date as the ones which served the previous
+// requests.
+func (c *Client) sendPageRequestURL(requestURL string, a interface{}) (err error) {
+	if !c.ConsistentPaging {
+		return c.sendRequestURL(requestURL, "get", a)
+	}
+
+	headers := http.Header{}
+	c.pagingMutex.Lock()
+	if c.pagingInstance != "" {
+		headers.Set(InstanceHeader, c.pagingInstance)
+	}
+	if c.pagingWatermark > 0 {
+		headers.Set(MinLedgerHeader, strconv.FormatUint(uint64(c.pagingWatermark), 10))
+	}
+	c.pagingMutex.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		err = c.sendRequestURLWithHeaders(requestURL, "get", headers, a)
+		if attempt >= pagingAttempts || !isStaleInstanceError(err) {
+			return err
+		}
+		// the pinned instance is behind, let the load balancer pick another
+		headers.Del(InstanceHeader)
+	}
+}
+
+// recordPagingHeaders keeps the instance and the highest ledger watermark of
+// the responses, used by sendPageRequestURL.
+func (c *Client) recordPagingHeaders(header http.Header) {
+	watermark, err := strconv.ParseUint(header.Get(LedgerWatermarkHeader), 10, 32)
+	if err != nil {
+		return
+	}
+
+	c.pagingMutex.Lock()
+	defer c.pagingMutex.Unlock()
+	c.pagingInstance = header.Get(InstanceHeader)
+	if uint32(watermark) > c.pagingWatermark {
+		c.pagingWatermark = uint32(watermark)
+	}
+}
+
+// sendRequestURLWithHeaders works like sendRequestURL but also sets the given
+// headers on the request.
+func (c *Client) sendRequestURLWithHeaders(requestURL string, method string, headers http.Header, a interface{}) (err error) {
 	var req *http.Request
 
 	if method == "post" || method == "POST" {
@@ -115,6 +167,9 @@ func (c *Client) sendRequestURL(requestURL string, method string, a interface{})
 		return errors.Wrap(err, "error creating HTTP request")
 	}
 	c.setClientAppHeaders(req)
+	for name := range headers {
+		req.Header.Set(name, headers.Get(name))
+	}
 	c.setDefaultClient()
 	if c.horizonTimeout == 0 {
 		c.horizonTimeout = HorizonTimeout
@@ -125,6 +180,9 @@ func (c *Client) sendRequestURL(requestURL string, method string, a interface{})
 		cancel()
 		return
 	}
+	if c.ConsistentPaging && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		c.recordPagingHeaders(resp.Header)
+	}
 
 	err = decodeResponse(resp, &a, c)
 	cancel()
@@ -364,6 +422,18 @@ func (c *Client) LedgerDetail(sequence uint32) (ledger hProtocol.Ledger, err err
 	return
 }
 
+// LedgerManifest returns the checksums of the trades and payments of the ledger
+// with the given sequence number.
+func (c *Client) LedgerManifest(sequence uint32) (manifest hProtocol.LedgerManifest, err error) {
+	if sequence == 0 {
+		return manifest, errors.New("invalid sequence number provided")
+	}
+
+	request := LedgerRequest{forSequence: sequence, manifest: true}
+	err = c.sendRequest(request, &manifest)
+	return
+}
+
 // FeeStats returns information about fees in the last 5 ledgers.
 // See https://www.stellar.org/developers/horizon/reference/endpoints/fee-stats.html
 func (c *Client) FeeStats() (feestats hProtocol.FeeStats, err error) {
@@ -516,6 +586,155 @@ func (c *Client) SubmitTransactionWithOptions(transaction *txnbuild.Transaction,
 	return c.SubmitTransactionXDR(txeBase64)
 }
 
+// SignSubmitAndConfirm builds the transaction described by params using the current sequence number
+// of its source account, signs it with opts.Signers, submits it and returns the transaction once it is
+// included in a ledger. The transaction is rebuilt and resubmitted when horizon rejects it with
+// tx_bad_seq, when opts.MaxBaseFee allows bumping the fee after a tx_insufficient_fee rejection and
+// when the submission times out and the sequence number is consumed by another transaction. If the
+// submission times out, the transactions of the source account are streamed until the transaction
+// is found, so ctx should have a deadline.
+func (c *Client) SignSubmitAndConfirm(ctx context.Context, params txnbuild.TransactionParams, opts SignSubmitOpts) (tx hProtocol.Transaction, err error) {
+	if params.SourceAccount == nil {
+		return tx, errors.New("transaction source account is required")
+	}
+	accountID := params.SourceAccount.GetAccountID()
+
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultSubmitAttempts
+	}
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		tx, err = c.signSubmitAndConfirm(ctx, accountID, params, opts)
+		if err == nil {
+			return
+		}
+
+		switch {
+		case err == errSequenceConsumed || transactionResultCode(err) == "tx_bad_seq":
+			continue
+		case transactionResultCode(err) == "tx_insufficient_fee" && params.BaseFee < opts.MaxBaseFee:
+			params.BaseFee *= 2
+			if params.BaseFee < txnbuild.MinBaseFee {
+				params.BaseFee = txnbuild.MinBaseFee
+			}
+			if params.BaseFee > opts.MaxBaseFee {
+				params.BaseFee = opts.MaxBaseFee
+			}
+			continue
+		}
+		return
+	}
+
+	err = errors.Wrapf(err, "transaction not confirmed after %d attempts", maxAttempts)
+	return
+}
+
+// signSubmitAndConfirm makes a single attempt of SignSubmitAndConfirm.
+func (c *Client) signSubmitAndConfirm(ctx context.Context, accountID string, params txnbuild.TransactionParams, opts SignSubmitOpts) (tx hProtocol.Transaction, err error) {
+	account, err := c.AccountDetail(AccountRequest{AccountID: accountID})
+	if err != nil {
+		return tx, errors.Wrap(err, "could not load source account")
+	}
+
+	// The latest transaction of the account is where the search for the
+	// submitted transaction starts if the submission times out.
+	latest, err := c.Transactions(TransactionRequest{
+		ForAccount: accountID,
+		Order:      OrderDesc,
+		Limit:      1,
+	})
+	if err != nil {
+		return tx, errors.Wrap(err, "could not load latest transaction of source account")
+	}
+	cursor := "now"
+	if records := latest.Embedded.Records; len(records) > 0 {
+		cursor = records[0].PagingToken()
+	}
+
+	params.SourceAccount = &account
+	params.IncrementSequenceNum = true
+	transaction, err := txnbuild.NewTransaction(params)
+	if err != nil {
+		return tx, errors.Wrap(err, "could not build transaction")
+	}
+	transaction, err = transaction.Sign(opts.NetworkPassphrase, opts.Signers...)
+	if err != nil {
+		return tx, errors.Wrap(err, "could not sign transaction")
+	}
+	hash, err := transaction.HashHex(opts.NetworkPassphrase)
+	if err != nil {
+		return tx, errors.Wrap(err, "could not hash transaction")
+	}
+
+	tx, err = c.SubmitTransactionWithOptions(transaction, opts.SubmitTxOpts)
+	if herr := GetError(err); herr == nil || herr.Problem.Status != http.StatusGatewayTimeout {
+		return
+	}
+
+	return c.waitForTransaction(ctx, accountID, cursor, hash, transaction.SourceAccount().Sequence)
+}
+
+// waitForTransaction streams the transactions of the account starting at cursor until the
+// transaction with the given hash is found. It returns errSequenceConsumed if another
+// transaction of the account used the sequence number first.
+func (c *Client) waitForTransaction(ctx context.Context, accountID, cursor, hash string, sequence int64) (tx hProtocol.Transaction, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var found, consumed bool
+	err = c.StreamTransactions(ctx, TransactionRequest{
+		ForAccount:    accountID,
+		Cursor:        cursor,
+		IncludeFailed: true,
+	}, func(streamed hProtocol.Transaction) {
+		if streamed.Hash == hash {
+			tx, found = streamed, true
+			cancel()
+			return
+		}
+
+		if streamed.Account != accountID {
+			return
+		}
+		streamedSequence, parseErr := strconv.ParseInt(streamed.AccountSequence, 10, 64)
+		if parseErr == nil && streamedSequence >= sequence {
+			consumed = true
+			cancel()
+		}
+	})
+
+	switch {
+	case found && !tx.Successful:
+		return tx, errors.Errorf("transaction %s failed", hash)
+	case found:
+		return tx, nil
+	case consumed:
+		return tx, errSequenceConsumed
+	case err != nil:
+		return tx, errors.Wrap(err, "error streaming transactions")
+	}
+	return tx, errors.Wrap(ctx.Err(), "transaction not found")
+}
+
+// transactionResultCode returns the transaction result code of a failed submission.
+func transactionResultCode(err error) string {
+	herr := GetError(err)
+	if herr == nil {
+		return ""
+	}
+
+	codes, err := herr.ResultCodes()
+	if err != nil {
+		return ""
+	}
+	return codes.TransactionCode
+}
+
 // Transactions returns stellar transactions (https://www.stellar.org/developers/horizon/reference/resources/transaction.html)
 // It can be used to return transactions for an account, a ledger,and all transactions on the network.
 func (c *Client) Transactions(request TransactionRequest) (txs hProtocol.TransactionsPage, err error) {
@@ -599,6 +818,39 @@ func (c *Client) TradeAggregations(request TradeAggregationRequest) (tds hProtoc
 	return
 }
 
+// AllTradeAggregations validates and aligns the request and returns all trade aggregations in
+// its time range by requesting consecutive pages from horizon until the range is exhausted.
+func (c *Client) AllTradeAggregations(request TradeAggregationRequest) ([]hProtocol.TradeAggregation, error) {
+	err := request.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid trade aggregation request")
+	}
+
+	request = request.Align()
+	var records []hProtocol.TradeAggregation
+	for request.StartTime.Before(request.EndTime) {
+		page, err := c.TradeAggregations(request)
+		if err != nil {
+			return nil, err
+		}
+
+		pageRecords := page.Embedded.Records
+		if len(pageRecords) == 0 {
+			break
+		}
+		records = append(records, pageRecords...)
+
+		last := time.Unix(0, pageRecords[len(pageRecords)-1].Timestamp*1e6)
+		if request.Order == OrderDesc {
+			request.EndTime = last
+		} else {
+			request.StartTime = last.Add(request.Resolution)
+		}
+	}
+
+	return records, nil
+}
+
 // StreamTransactions streams processed transactions. It can be used to stream all transactions and
 // transactions for an account. Use context.WithCancel to stop streaming or context.Background()
 // if you want to stream indefinitely. TransactionHandler is a user-supplied function that is executed for each streamed transaction received.
@@ -683,67 +935,67 @@ func (c *Client) Version() string {
 
 // NextAccountsPage returns the next page of accounts.
 func (c *Client) NextAccountsPage(page hProtocol.AccountsPage) (accounts hProtocol.AccountsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &accounts)
+	err = c.sendPageRequestURL(page.Links.Next.Href, &accounts)
 	return
 }
 
 // NextAssetsPage returns the next page of assets.
 func (c *Client) NextAssetsPage(page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &assets)
+	err = c.sendPageRequestURL(page.Links.Next.Href, &assets)
 	return
 }
 
 // PrevAssetsPage returns the previous page of assets.
 func (c *Client) PrevAssetsPage(page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &assets)
+	err = c.sendPageRequestURL(page.Links.Prev.Href, &assets)
 	return
 }
 
 // NextLedgersPage returns the next page of ledgers.
 func (c *Client) NextLedgersPage(page hProtocol.LedgersPage) (ledgers hProtocol.LedgersPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &ledgers)
+	err = c.sendPageRequestURL(page.Links.Next.Href, &ledgers)
 	return
 }
 
 // PrevLedgersPage returns the previous page of ledgers.
 func (c *Client) PrevLedgersPage(page hProtocol.LedgersPage) (ledgers hProtocol.LedgersPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &ledgers)
+	err = c.sendPageRequestURL(page.Links.Prev.Href, &ledgers)
 	return
 }
 
 // NextEffectsPage returns the next page of effects.
 func (c *Client) NextEffectsPage(page effects.EffectsPage) (efp effects.EffectsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &efp)
+	err = c.sendPageRequestURL(page.Links.Next.Href, &efp)
 	return
 }
 
 // PrevEffectsPage returns the previous page of effects.
 func (c *Client) PrevEffectsPage(page effects.EffectsPage) (efp effects.EffectsPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &efp)
+	err = c.sendPageRequestURL(page.Links.Prev.Href, &efp)
 	return
 }
 
 // NextTransactionsPage returns the next page of transactions.
 func (c *Client) NextTransactionsPage(page hProtocol.TransactionsPage) (transactions hProtocol.TransactionsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &transactions)
+	err = c.sendPageRequestURL(page.Links.Next.Href, &transactions)
 	return
 }
 
 // PrevTransactionsPage returns the previous page of transactions.
 func (c *Client) PrevTransactionsPage(page hProtocol.TransactionsPage) (transactions hProtocol.TransactionsPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &transactions)
+	err = c.sendPageRequestURL(page.Links.Prev.Href, &transactions)
 	return
 }
 
 // NextOperationsPage returns the next page of operations.
 func (c *Client) NextOperationsPage(page operations.OperationsPage) (operations operations.OperationsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &operations)
+	err = c.sendPageRequestURL(page.Links.Next.Href, &operations)
 	return
 }
 
 // PrevOperationsPage returns the previous page of operations.
 func (c *Client) PrevOperationsPage(page operations.OperationsPage) (operations operations.OperationsPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &operations)
+	err = c.sendPageRequestURL(page.Links.Prev.Href, &operations)
 	return
 }
 
@@ -759,25 +1011,25 @@ func (c *Client) PrevPaymentsPage(page operations.OperationsPage) (operations.Op
 
 // NextOffersPage returns the next page of offers.
 func (c *Client) NextOffersPage(page hProtocol.OffersPage) (offers hProtocol.OffersPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &offers)
+	err = c.sendPageRequestURL(page.Links.Next.Href, &offers)
 	return
 }
 
 // PrevOffersPage returns the previous page of offers.
 func (c *Client) PrevOffersPage(page hProtocol.OffersPage) (offers hProtocol.OffersPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &offers)
+	err = c.sendPageRequestURL(page.Links.Prev.Href, &offers)
 	return
 }
 
 // NextTradesPage returns the next page of trades.
 func (c *Client) NextTradesPage(page hProtocol.TradesPage) (trades hProtocol.TradesPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &trades)
+	err = c.sendPageRequestURL(page.Links.Next.Href, &trades)
 	return
 }
 
 // PrevTradesPage returns the previous page of trades.
 func (c *Client) PrevTradesPage(page hProtocol.TradesPage) (trades hProtocol.TradesPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &trades)
+	err = c.sendPageRequestURL(page.Links.Prev.Href, &trades)
 	return
 }
 
@@ -798,14 +1050,14 @@ func (c *Client) HomeDomainForAccount(aid string) (string, error) {
 // NextTradeAggregationsPage returns the next page of trade aggregations from the current
 // trade aggregations response.
 func (c *Client) NextTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (ta hProtocol.TradeAggregationsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &ta)
+	err = c.sendPageRequestURL(page.Links.Next.Href, &ta)
 	return
 }
 
 // PrevTradeAggregationsPage returns the previous page of trade aggregations from the current
 // trade aggregations response.
 func (c *Client) PrevTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (ta hProtocol.TradeAggregationsPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &ta)
+	err = c.sendPageRequestURL(page.Links.Prev.Href, &ta)
 	return
 }
 
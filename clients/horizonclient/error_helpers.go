@@ -23,6 +23,18 @@ func IsNotFoundError(err error) bool {
 	return hErr.Problem.Type == "https://stellar.org/horizon-errors/not_found"
 }
 
+// isStaleInstanceError returns true if the error is a horizonclient.Error with
+// a stale_instance problem indicating that the instance serving the request
+// has not ingested the ledger required by the X-Horizon-Min-Ledger header.
+func isStaleInstanceError(err error) bool {
+	hErr := GetError(err)
+	if hErr == nil {
+		return false
+	}
+
+	return hErr.Problem.Type == "https://stellar.org/horizon-errors/stale_instance"
+}
+
 // GetError returns an error that can be interpreted as a horizon-specific
 // error. If err cannot be interpreted as a horizon-specific error, a nil error
 // is returned. The caller should still check whether err is nil.
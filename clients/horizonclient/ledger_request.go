@@ -21,6 +21,9 @@ func (lr LedgerRequest) BuildURL() (endpoint string, err error) {
 			endpoint,
 			lr.forSequence,
 		)
+		if lr.manifest {
+			endpoint += "/manifest"
+		}
 	} else {
 		queryParams := addQueryParams(cursor(lr.Cursor), limit(lr.Limit), lr.Order)
 		if queryParams != "" {
@@ -2,8 +2,10 @@ package horizonclient
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
+	"github.com/jarcoal/httpmock"
 	hProtocol "github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/support/http/httptest"
 	"github.com/stretchr/testify/assert"
@@ -105,6 +107,43 @@ func TestLedgerDetail(t *testing.T) {
 	}
 }
 
+func TestLedgerManifest(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	_, err := client.LedgerManifest(0)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid sequence number provided")
+	}
+
+	hmock.On(
+		"GET",
+		"https://localhost/ledgers/69859/manifest",
+	).ReturnString(200, `{
+  "sequence": 69859,
+  "trades_hash": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+  "payments_hash": "71a40c0581d8d7c1158e1d9368024c5f9fd70de17a8d277cdd96781590cc10fb"
+}`)
+
+	manifest, err := client.LedgerManifest(69859)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(69859), manifest.Sequence)
+		assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", manifest.TradesHash)
+		assert.Equal(t, "71a40c0581d8d7c1158e1d9368024c5f9fd70de17a8d277cdd96781590cc10fb", manifest.PaymentsHash)
+	}
+
+	hmock.On(
+		"GET",
+		"https://localhost/ledgers/69859/manifest",
+	).ReturnString(404, notFoundResponse)
+
+	_, err = client.LedgerManifest(69859)
+	assert.True(t, IsNotFoundError(err))
+}
+
 func TestLedgerRequestStreamLedgers(t *testing.T) {
 	hmock := httptest.NewClient()
 	client := &Client{
@@ -182,6 +221,58 @@ func TestNextLedgersPage(t *testing.T) {
 	}
 }
 
+func TestNextLedgersPageConsistentPaging(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL:       "https://localhost/",
+		HTTP:             hmock,
+		ConsistentPaging: true,
+	}
+
+	hmock.On(
+		"GET",
+		"https://localhost/ledgers?limit=2",
+	).ReturnStringWithHeader(200, firstLedgersPage, http.Header{
+		InstanceHeader:        []string{"horizon-1"},
+		LedgerWatermarkHeader: []string{"100"},
+	})
+
+	ledgers, err := client.Ledgers(LedgerRequest{Limit: 2})
+	assert.NoError(t, err)
+
+	var requests []*http.Request
+	hmock.On(
+		"GET",
+		"https://horizon-testnet.stellar.org/ledgers?cursor=1559012998905856&limit=2&order=desc",
+	).Return(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req)
+		if len(requests) == 1 {
+			return httpmock.NewStringResponse(503, staleInstanceResponse), nil
+		}
+		return httpmock.NewStringResponse(200, emptyLedgersPage), nil
+	})
+
+	nextPage, err := client.NextLedgersPage(ledgers)
+	if assert.NoError(t, err) {
+		assert.Equal(t, len(nextPage.Embedded.Records), 0)
+	}
+
+	// the request is retried without pinning the stale instance
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, "horizon-1", requests[0].Header.Get(InstanceHeader))
+		assert.Equal(t, "100", requests[0].Header.Get(MinLedgerHeader))
+		assert.Equal(t, "", requests[1].Header.Get(InstanceHeader))
+		assert.Equal(t, "100", requests[1].Header.Get(MinLedgerHeader))
+	}
+}
+
+var staleInstanceResponse = `{
+  "type": "https://stellar.org/horizon-errors/stale_instance",
+  "title": "Instance Is Behind Requested Ledger",
+  "status": 503,
+  "detail": "This horizon instance has not yet ingested the ledger required by the X-Horizon-Min-Ledger header of the request, usually because a previous page of results was served by a more up to date instance. Please try your request again."
+}`
+
 var ledgerStreamResponse = `data: {"_links":{"self":{"href":"https://horizon-testnet.stellar.org/ledgers/560339"},"transactions":{"href":"https://horizon-testnet.stellar.org/ledgers/560339/transactions{?cursor,limit,order}","templated":true},"operations":{"href":"https://horizon-testnet.stellar.org/ledgers/560339/operations{?cursor,limit,order}","templated":true},"payments":{"href":"https://horizon-testnet.stellar.org/ledgers/560339/payments{?cursor,limit,order}","templated":true},"effects":{"href":"https://horizon-testnet.stellar.org/ledgers/560339/effects{?cursor,limit,order}","templated":true}},"id":"66f4d95dab22dbc422585cc4b011716014e81df3599cee8db9c776cfc3a31e93","paging_token":"2406637679673344","hash":"66f4d95dab22dbc422585cc4b011716014e81df3599cee8db9c776cfc3a31e93","prev_hash":"6071f1e52a6bf37aba3f7437081577eafe69f78593c465fc5028c46a4746dda3","sequence":560339,"successful_transaction_count":5,"failed_transaction_count":1,"operation_count":44,"closed_at":"2019-04-01T16:47:05Z","total_coins":"100057227213.0436903","fee_pool":"57227816.6766542","base_fee_in_stroops":100,"base_reserve_in_stroops":5000000,"max_tx_set_size":100,"protocol_version":10,"header_xdr":"AAAACmBx8eUqa/N6uj90NwgVd+r+afeFk8Rl/FAoxGpHRt2jdIn+3X+/O3PFUUZ8Tgy4rfD1oNamR+9NMOCM2V6ndksAAAAAXKJAiQAAAAAAAAAAPyIIYU6Y37lve/MwZls1vmbgxgFdx93hdzOn6g8kHhQ1BS9aAKuXtApQoE3gKpjQ5ze0H9qUruyOUsbM776zXQAIjNMN4r8uJHCvJwACCHvk18POAAAAAwAAAAAAQZnVAAAAZABMS0AAAABkkiIcXkjaTtc9zTQBn0o72CUBe3u+2Mz7W6dgkvkYcJJle8JCNmXx5HcRlDSHJzzBShc8C3rQUIsIuJ93eoBMgHeYAzfholE8hjvrHrqoHq8jfPowxj1FGD6HaUPD1PHTcBXmf0U0cs2Ki0NBDDKNcwKC84nUPdumCkdAxSuEzn4AAAAA"}
 `
 
@@ -18,6 +18,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/stellar/go/keypair"
 	hProtocol "github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/protocols/horizon/effects"
 	"github.com/stellar/go/protocols/horizon/operations"
@@ -64,6 +65,21 @@ const (
 	// accountRequiresMemo is the base64 encoding of "1".
 	// SEP 29 uses this value to define transaction memo requirements for incoming payments.
 	accountRequiresMemo = "MQ=="
+	// defaultSubmitAttempts is the default number of attempts of SignSubmitAndConfirm.
+	defaultSubmitAttempts = 3
+	// pagingAttempts is the number of attempts of page requests rejected
+	// by stale instances when ConsistentPaging is set.
+	pagingAttempts = 3
+
+	// InstanceHeader is the response header with the id of the horizon
+	// instance which served the request.
+	InstanceHeader = "X-Horizon-Instance"
+	// LedgerWatermarkHeader is the response header with the latest ledger
+	// ingested by the horizon instance which served the request.
+	LedgerWatermarkHeader = "X-Horizon-Ledger-Watermark"
+	// MinLedgerHeader is the request header with the lowest ledger watermark
+	// a horizon instance must have reached to serve the request.
+	MinLedgerHeader = "X-Horizon-Min-Ledger"
 )
 
 // Error struct contains the problem returned by Horizon
@@ -92,6 +108,10 @@ var (
 	// when any of the destination accounts required a memo in the transaction.
 	ErrAccountRequiresMemo = errors.New("destination account requires a memo in the transaction")
 
+	// errSequenceConsumed is returned when the sequence number of a submitted
+	// transaction was used by another transaction of the source account.
+	errSequenceConsumed = errors.New("sequence number consumed by another transaction")
+
 	// HorizonTimeout is the default number of nanoseconds before a request to horizon times out.
 	HorizonTimeout = 60 * time.Second
 
@@ -144,6 +164,18 @@ type Client struct {
 
 	// clock is a Clock returning the current time.
 	clock *clock.Clock
+
+	// ConsistentPaging pins requests for the next and previous pages of
+	// results to horizon instances at least as up to date as the ones which
+	// served the previous requests, avoiding missing or duplicated records
+	// when requests are balanced between several instances. Page requests
+	// are sent with the X-Horizon-Instance header of the last response (so
+	// load balancers can route them to the same instance) and are retried
+	// when rejected by an instance which is behind.
+	ConsistentPaging bool
+	pagingMutex      sync.Mutex
+	pagingInstance   string
+	pagingWatermark  uint32
 }
 
 // SubmitTxOpts represents the submit transaction options
@@ -151,6 +183,22 @@ type SubmitTxOpts struct {
 	SkipMemoRequiredCheck bool
 }
 
+// SignSubmitOpts represents the options of SignSubmitAndConfirm
+type SignSubmitOpts struct {
+	SubmitTxOpts
+	// NetworkPassphrase is the passphrase of the network the transaction is signed for.
+	NetworkPassphrase string
+	// Signers sign every transaction built by SignSubmitAndConfirm.
+	Signers []*keypair.Full
+	// MaxAttempts is the maximum number of times the transaction is rebuilt and
+	// submitted. Defaults to 3.
+	MaxAttempts int
+	// MaxBaseFee enables fee bumping on surge pricing. When set, a transaction
+	// rejected with tx_insufficient_fee is resubmitted with a doubled base fee
+	// as long as it does not exceed MaxBaseFee.
+	MaxBaseFee int64
+}
+
 // ClientInterface contains methods implemented by the horizon client
 type ClientInterface interface {
 	Accounts(request AccountsRequest) (hProtocol.AccountsPage, error)
@@ -160,6 +208,7 @@ type ClientInterface interface {
 	Assets(request AssetRequest) (hProtocol.AssetsPage, error)
 	Ledgers(request LedgerRequest) (hProtocol.LedgersPage, error)
 	LedgerDetail(sequence uint32) (hProtocol.Ledger, error)
+	LedgerManifest(sequence uint32) (hProtocol.LedgerManifest, error)
 	FeeStats() (hProtocol.FeeStats, error)
 	Offers(request OfferRequest) (hProtocol.OffersPage, error)
 	OfferDetails(offerID string) (offer hProtocol.Offer, err error)
@@ -170,12 +219,14 @@ type ClientInterface interface {
 	SubmitTransactionWithOptions(transaction *txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.Transaction, error)
 	SubmitFeeBumpTransaction(transaction *txnbuild.FeeBumpTransaction) (hProtocol.Transaction, error)
 	SubmitTransaction(transaction *txnbuild.Transaction) (hProtocol.Transaction, error)
+	SignSubmitAndConfirm(ctx context.Context, params txnbuild.TransactionParams, opts SignSubmitOpts) (hProtocol.Transaction, error)
 	Transactions(request TransactionRequest) (hProtocol.TransactionsPage, error)
 	TransactionDetail(txHash string) (hProtocol.Transaction, error)
 	OrderBook(request OrderBookRequest) (hProtocol.OrderBookSummary, error)
 	Paths(request PathsRequest) (hProtocol.PathsPage, error)
 	Payments(request OperationRequest) (operations.OperationsPage, error)
 	TradeAggregations(request TradeAggregationRequest) (hProtocol.TradeAggregationsPage, error)
+	AllTradeAggregations(request TradeAggregationRequest) ([]hProtocol.TradeAggregation, error)
 	Trades(request TradeRequest) (hProtocol.TradesPage, error)
 	Fund(addr string) (hProtocol.Transaction, error)
 	StreamTransactions(ctx context.Context, request TransactionRequest, handler TransactionHandler) error
@@ -280,6 +331,7 @@ type LedgerRequest struct {
 	Cursor      string
 	Limit       uint
 	forSequence uint32
+	manifest    bool
 }
 
 type feeStatsRequest struct {
@@ -1,12 +1,15 @@
 package horizonclient
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"net/url"
 	"testing"
 	"time"
 
+	"github.com/jarcoal/httpmock"
 	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/network"
 	hProtocol "github.com/stellar/go/protocols/horizon"
@@ -419,8 +422,7 @@ func TestEffectsRequest(t *testing.T) {
 		arEffect := effs.Embedded.Records[2]
 		assert.IsType(t, adEffect, effects.AccountDebited{})
 		assert.IsType(t, acEffect, effects.AccountCredited{})
-		// account_removed effect does not have a struct. Defaults to effects.Base
-		assert.IsType(t, arEffect, effects.Base{})
+		assert.IsType(t, arEffect, effects.AccountRemoved{})
 
 		c, ok := acEffect.(effects.AccountCredited)
 		assert.Equal(t, ok, true)
@@ -428,6 +430,11 @@ func TestEffectsRequest(t *testing.T) {
 		assert.Equal(t, c.Amount, "9999.9999900")
 		assert.Equal(t, c.Account, "GBO7LQUWCC7M237TU2PAXVPOLLYNHYCYYFCLVMX3RBJCML4WA742X3UB")
 		assert.Equal(t, c.Asset.Type, "native")
+
+		r, ok := arEffect.(effects.AccountRemoved)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, r.Destination, "GBO7LQUWCC7M237TU2PAXVPOLLYNHYCYYFCLVMX3RBJCML4WA742X3UB")
+		assert.Equal(t, r.Amount, "9999.9999900")
 	}
 
 	effectRequest = EffectRequest{ForAccount: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"}
@@ -1419,6 +1426,159 @@ func TestVersion(t *testing.T) {
 	assert.Equal(t, "2.1.0", client.Version())
 }
 
+func signSubmitTestClient(kp *keypair.Full) (*Client, *httptest.Client) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	// responders are called for every attempt so each needs a fresh body
+	hmock.On(
+		"GET",
+		fmt.Sprintf("https://localhost/accounts/%s", kp.Address()),
+	).Return(func(*http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(200, fmt.Sprintf(`{"id": "%[1]s", "account_id": "%[1]s", "sequence": "100"}`, kp.Address())), nil
+	})
+	hmock.On(
+		"GET",
+		fmt.Sprintf("https://localhost/accounts/%s/transactions?limit=1&order=desc", kp.Address()),
+	).Return(func(*http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(200, emptyTransactionsPage), nil
+	})
+
+	return client, hmock
+}
+
+func signSubmitTestParams(kp *keypair.Full) (txnbuild.TransactionParams, SignSubmitOpts) {
+	params := txnbuild.TransactionParams{
+		SourceAccount: &txnbuild.SimpleAccount{AccountID: kp.Address()},
+		Operations:    []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 0}},
+		BaseFee:       txnbuild.MinBaseFee,
+		Timebounds:    txnbuild.NewInfiniteTimeout(),
+	}
+	opts := SignSubmitOpts{
+		SubmitTxOpts:      SubmitTxOpts{SkipMemoRequiredCheck: true},
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		Signers:           []*keypair.Full{kp},
+		MaxBaseFee:        1000,
+	}
+	return params, opts
+}
+
+func submittedEnvelope(t *testing.T, r *http.Request) xdr.TransactionEnvelope {
+	var envelope xdr.TransactionEnvelope
+	require.NoError(t, xdr.SafeUnmarshalBase64(r.URL.Query().Get("tx"), &envelope))
+	return envelope
+}
+
+func transactionFailedResponse(code string) string {
+	return fmt.Sprintf(`{
+  "type": "https://stellar.org/horizon-errors/transaction_failed",
+  "title": "Transaction Failed",
+  "status": 400,
+  "extras": {
+    "result_codes": {
+      "transaction": "%s"
+    }
+  }
+}`, code)
+}
+
+func TestSignSubmitAndConfirmRetries(t *testing.T) {
+	kp := keypair.MustRandom()
+	client, hmock := signSubmitTestClient(kp)
+	params, opts := signSubmitTestParams(kp)
+
+	var fees []uint32
+	hmock.On("POST", "https://localhost/transactions").Return(func(r *http.Request) (*http.Response, error) {
+		envelope := submittedEnvelope(t, r)
+		assert.Equal(t, int64(101), envelope.SeqNum())
+		fees = append(fees, envelope.Fee())
+
+		switch len(fees) {
+		case 1:
+			return httpmock.NewStringResponse(400, transactionFailedResponse("tx_bad_seq")), nil
+		case 2:
+			return httpmock.NewStringResponse(400, transactionFailedResponse("tx_insufficient_fee")), nil
+		}
+		return httpmock.NewStringResponse(200, txSuccess), nil
+	})
+
+	tx, err := client.SignSubmitAndConfirm(context.Background(), params, opts)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca", tx.Hash)
+	}
+	assert.Equal(t, []uint32{100, 100, 200}, fees)
+
+	// other errors are not retried
+	fees = nil
+	hmock.On("POST", "https://localhost/transactions").Return(func(r *http.Request) (*http.Response, error) {
+		fees = append(fees, submittedEnvelope(t, r).Fee())
+		return httpmock.NewStringResponse(400, transactionFailedResponse("tx_no_source_account")), nil
+	})
+	_, err = client.SignSubmitAndConfirm(context.Background(), params, opts)
+	assert.Equal(t, "tx_no_source_account", transactionResultCode(err))
+	assert.Len(t, fees, 1)
+
+	// fees are not bumped above MaxBaseFee
+	fees = nil
+	hmock.On("POST", "https://localhost/transactions").Return(func(r *http.Request) (*http.Response, error) {
+		fees = append(fees, submittedEnvelope(t, r).Fee())
+		return httpmock.NewStringResponse(400, transactionFailedResponse("tx_insufficient_fee")), nil
+	})
+	opts.MaxAttempts = 5
+	opts.MaxBaseFee = 300
+	_, err = client.SignSubmitAndConfirm(context.Background(), params, opts)
+	assert.Equal(t, "tx_insufficient_fee", transactionResultCode(err))
+	assert.Equal(t, []uint32{100, 200, 300}, fees)
+}
+
+func TestSignSubmitAndConfirmTimeout(t *testing.T) {
+	kp := keypair.MustRandom()
+	client, hmock := signSubmitTestClient(kp)
+	params, opts := signSubmitTestParams(kp)
+
+	var hash string
+	hmock.On("POST", "https://localhost/transactions").Return(func(r *http.Request) (*http.Response, error) {
+		envelope := submittedEnvelope(t, r)
+		h, err := network.HashTransactionInEnvelope(envelope, network.TestNetworkPassphrase)
+		require.NoError(t, err)
+		hash = hex.EncodeToString(h[:])
+		return httpmock.NewStringResponse(504, `{"type": "https://stellar.org/horizon-errors/timeout", "title": "Timeout", "status": 504}`), nil
+	})
+	hmock.On(
+		"GET",
+		fmt.Sprintf("https://localhost/accounts/%s/transactions?cursor=now&include_failed=true", kp.Address()),
+	).Return(func(r *http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(200, fmt.Sprintf(
+			"data: {\"hash\":\"%s\",\"successful\":true,\"source_account\":\"%s\",\"source_account_sequence\":\"101\"}\n\n",
+			hash, kp.Address(),
+		)), nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tx, err := client.SignSubmitAndConfirm(ctx, params, opts)
+	if assert.NoError(t, err) {
+		assert.Equal(t, hash, tx.Hash)
+	}
+
+	// the sequence number is used by another transaction
+	hmock.On(
+		"GET",
+		fmt.Sprintf("https://localhost/accounts/%s/transactions?cursor=now&include_failed=true", kp.Address()),
+	).Return(func(*http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(200, fmt.Sprintf(
+			"data: {\"hash\":\"other\",\"successful\":true,\"source_account\":\"%s\",\"source_account_sequence\":\"101\"}\n\n",
+			kp.Address(),
+		)), nil
+	})
+	opts.MaxAttempts = 2
+	_, err = client.SignSubmitAndConfirm(ctx, params, opts)
+	assert.EqualError(t, err, "transaction not confirmed after 2 attempts: sequence number consumed by another transaction")
+}
+
 var accountsResponse = `{
   "_links": {
     "self": {
@@ -1666,7 +1826,9 @@ var effectsResponse = `{
         "account": "GANHAS5OMPLKD6VYU4LK7MBHSHB2Q37ZHAYWOBJRUXGDHMPJF3XNT45Y",
         "type": "account_removed",
         "type_i": 1,
-        "created_at": "2018-07-27T21:00:12Z"
+        "created_at": "2018-07-27T21:00:12Z",
+        "destination": "GBO7LQUWCC7M237TU2PAXVPOLLYNHYCYYFCLVMX3RBJCML4WA742X3UB",
+        "amount": "9999.9999900"
       }
     ]
   }
@@ -57,6 +57,12 @@ func (m *MockClient) LedgerDetail(sequence uint32) (hProtocol.Ledger, error) {
 	return a.Get(0).(hProtocol.Ledger), a.Error(1)
 }
 
+// LedgerManifest is a mocking method
+func (m *MockClient) LedgerManifest(sequence uint32) (hProtocol.LedgerManifest, error) {
+	a := m.Called(sequence)
+	return a.Get(0).(hProtocol.LedgerManifest), a.Error(1)
+}
+
 // FeeStats is a mocking method
 func (m *MockClient) FeeStats() (hProtocol.FeeStats, error) {
 	a := m.Called()
@@ -117,6 +123,12 @@ func (m *MockClient) SubmitTransactionWithOptions(transaction *txnbuild.Transact
 	return a.Get(0).(hProtocol.Transaction), a.Error(1)
 }
 
+// SignSubmitAndConfirm is a mocking method
+func (m *MockClient) SignSubmitAndConfirm(ctx context.Context, params txnbuild.TransactionParams, opts SignSubmitOpts) (hProtocol.Transaction, error) {
+	a := m.Called(ctx, params, opts)
+	return a.Get(0).(hProtocol.Transaction), a.Error(1)
+}
+
 // Transactions is a mocking method
 func (m *MockClient) Transactions(request TransactionRequest) (hProtocol.TransactionsPage, error) {
 	a := m.Called(request)
@@ -153,6 +165,12 @@ func (m *MockClient) TradeAggregations(request TradeAggregationRequest) (hProtoc
 	return a.Get(0).(hProtocol.TradeAggregationsPage), a.Error(1)
 }
 
+// AllTradeAggregations is a mocking method
+func (m *MockClient) AllTradeAggregations(request TradeAggregationRequest) ([]hProtocol.TradeAggregation, error) {
+	a := m.Called(request)
+	return a.Get(0).([]hProtocol.TradeAggregation), a.Error(1)
+}
+
 // Trades is a mocking method
 func (m *MockClient) Trades(request TradeRequest) (hProtocol.TradesPage, error) {
 	a := m.Called(request)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/stellar/go/support/errors"
 )
@@ -36,3 +37,65 @@ func (ta TradeAggregationRequest) BuildURL() (endpoint string, err error) {
 
 	return endpoint, err
 }
+
+// allowedResolutions are the resolutions accepted by the trade aggregations endpoint.
+var allowedResolutions = map[time.Duration]struct{}{
+	MinuteResolution:        {},
+	FiveMinuteResolution:    {},
+	FifteenMinuteResolution: {},
+	HourResolution:          {},
+	DayResolution:           {},
+	WeekResolution:          {},
+}
+
+// Validate checks the request against the constraints of the trade aggregations endpoint so
+// invalid requests can be rejected without a round trip to horizon.
+func (ta TradeAggregationRequest) Validate() error {
+	if _, ok := allowedResolutions[ta.Resolution]; !ok {
+		return errors.Errorf(
+			"invalid resolution %s, allowed resolutions are: 1 minute, 5 minutes, 15 minutes, 1 hour, 1 day and 1 week",
+			ta.Resolution,
+		)
+	}
+
+	if ta.Offset%time.Hour != 0 || ta.Offset < 0 || ta.Offset >= 24*time.Hour || ta.Offset > ta.Resolution {
+		return errors.New("invalid offset, offset must be a multiple of an hour, less than or equal to the resolution, and less than 24 hours")
+	}
+
+	if ta.EndTime.Before(ta.StartTime) {
+		return errors.New("end time must not be before start time")
+	}
+
+	if ta.BaseAssetType == "" || ta.CounterAssetType == "" {
+		return errors.New("base and counter asset types are required")
+	}
+
+	return nil
+}
+
+// Align returns a copy of the request with StartTime rounded down and EndTime rounded up to
+// the bucket boundaries defined by Resolution and Offset. Horizon only returns buckets fully
+// contained in the requested time range, aligning the request makes sure the buckets
+// containing the start and end times are included.
+func (ta TradeAggregationRequest) Align() TradeAggregationRequest {
+	resolution := ta.Resolution.Nanoseconds() / 1e6
+	if resolution <= 0 {
+		return ta
+	}
+	offset := ta.Offset.Nanoseconds() / 1e6
+
+	alignDown := func(t time.Time) int64 {
+		ms := t.UnixNano()/1e6 - offset
+		return ms - ms%resolution + offset
+	}
+
+	start := alignDown(ta.StartTime)
+	end := alignDown(ta.EndTime)
+	if end < ta.EndTime.UnixNano()/1e6 {
+		end += resolution
+	}
+
+	ta.StartTime = time.Unix(0, start*1e6)
+	ta.EndTime = time.Unix(0, end*1e6)
+	return ta
+}
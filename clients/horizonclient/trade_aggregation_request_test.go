@@ -94,6 +94,100 @@ func TestTradeAggregationsRequest(t *testing.T) {
 	}
 }
 
+func TestTradeAggregationRequestValidate(t *testing.T) {
+	ta := TradeAggregationRequest{
+		StartTime:        testTime,
+		EndTime:          testTime.Add(time.Hour),
+		Resolution:       HourResolution,
+		BaseAssetType:    AssetTypeNative,
+		CounterAssetType: AssetType4,
+	}
+	assert.NoError(t, ta.Validate())
+
+	invalid := ta
+	invalid.Resolution = 2 * time.Minute
+	assert.EqualError(t, invalid.Validate(), "invalid resolution 2m0s, allowed resolutions are: 1 minute, 5 minutes, 15 minutes, 1 hour, 1 day and 1 week")
+
+	invalid = ta
+	invalid.Offset = 2 * time.Hour
+	assert.EqualError(t, invalid.Validate(), "invalid offset, offset must be a multiple of an hour, less than or equal to the resolution, and less than 24 hours")
+
+	invalid = ta
+	invalid.Resolution = DayResolution
+	invalid.Offset = 30 * time.Minute
+	assert.Error(t, invalid.Validate())
+
+	invalid = ta
+	invalid.EndTime = testTime.Add(-time.Hour)
+	assert.EqualError(t, invalid.Validate(), "end time must not be before start time")
+
+	invalid = ta
+	invalid.CounterAssetType = ""
+	assert.EqualError(t, invalid.Validate(), "base and counter asset types are required")
+}
+
+func TestTradeAggregationRequestAlign(t *testing.T) {
+	ta := TradeAggregationRequest{
+		StartTime:  time.Unix(1517521726, 0),
+		EndTime:    time.Unix(1517532526, 0),
+		Resolution: HourResolution,
+	}
+	aligned := ta.Align()
+	assert.Equal(t, int64(1517518800), aligned.StartTime.Unix())
+	assert.Equal(t, int64(1517533200), aligned.EndTime.Unix())
+
+	// aligned times are not changed
+	assert.Equal(t, aligned, aligned.Align())
+
+	ta.Resolution = DayResolution
+	ta.Offset = 2 * time.Hour
+	aligned = ta.Align()
+	assert.Equal(t, int64(1517450400), aligned.StartTime.Unix())
+	assert.Equal(t, int64(1517536800), aligned.EndTime.Unix())
+}
+
+func TestAllTradeAggregations(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	taRequest := TradeAggregationRequest{
+		StartTime:          time.Unix(0, 1565026870000*1e6),
+		EndTime:            time.Unix(0, 1565027000000*1e6),
+		Resolution:         MinuteResolution,
+		BaseAssetType:      AssetType4,
+		BaseAssetCode:      "USD",
+		BaseAssetIssuer:    "GDLEUZYDSFMWA5ZLQIOCYS7DMLYDKFS2KWJ5M3RQ3P3WS4L75ZTWKELP",
+		CounterAssetType:   AssetType4,
+		CounterAssetCode:   "BTC",
+		CounterAssetIssuer: "GDLEUZYDSFMWA5ZLQIOCYS7DMLYDKFS2KWJ5M3RQ3P3WS4L75ZTWKELP",
+		Limit:              2,
+	}
+
+	hmock.On(
+		"GET",
+		"https://localhost/trade_aggregations?base_asset_code=USD&base_asset_issuer=GDLEUZYDSFMWA5ZLQIOCYS7DMLYDKFS2KWJ5M3RQ3P3WS4L75ZTWKELP&base_asset_type=credit_alphanum4&counter_asset_code=BTC&counter_asset_issuer=GDLEUZYDSFMWA5ZLQIOCYS7DMLYDKFS2KWJ5M3RQ3P3WS4L75ZTWKELP&counter_asset_type=credit_alphanum4&end_time=1565027040000&limit=2&offset=0&resolution=60000&start_time=1565026860000",
+	).ReturnString(200, firstTradeAggsPage)
+
+	hmock.On(
+		"GET",
+		"https://localhost/trade_aggregations?base_asset_code=USD&base_asset_issuer=GDLEUZYDSFMWA5ZLQIOCYS7DMLYDKFS2KWJ5M3RQ3P3WS4L75ZTWKELP&base_asset_type=credit_alphanum4&counter_asset_code=BTC&counter_asset_issuer=GDLEUZYDSFMWA5ZLQIOCYS7DMLYDKFS2KWJ5M3RQ3P3WS4L75ZTWKELP&counter_asset_type=credit_alphanum4&end_time=1565027040000&limit=2&offset=0&resolution=60000&start_time=1565026980000",
+	).ReturnString(200, emptyTradeAggsPage)
+
+	records, err := client.AllTradeAggregations(taRequest)
+	if assert.NoError(t, err) && assert.Len(t, records, 2) {
+		assert.Equal(t, int64(1565026860000), records[0].Timestamp)
+		assert.Equal(t, int64(1565026920000), records[1].Timestamp)
+	}
+
+	// invalid requests are not sent to horizon
+	taRequest.Resolution = 2 * time.Minute
+	_, err = client.AllTradeAggregations(taRequest)
+	assert.EqualError(t, err, "invalid trade aggregation request: invalid resolution 2m0s, allowed resolutions are: 1 minute, 5 minutes, 15 minutes, 1 hour, 1 day and 1 week")
+}
+
 func TestNextTradeAggregationsPage(t *testing.T) {
 	hmock := httptest.NewClient()
 	client := &Client{
@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"context"
+	stdio "io"
 
 	"github.com/stellar/go/exp/ingest/io"
 	"github.com/stellar/go/historyarchive"
@@ -17,6 +18,7 @@ type HistoryArchiveAdapter struct {
 type HistoryArchiveAdapterInterface interface {
 	GetLatestLedgerSequence() (uint32, error)
 	BucketListHash(sequence uint32) (xdr.Hash, error)
+	LedgerHash(checkpoint uint32) (xdr.Hash, error)
 	GetState(ctx context.Context, sequence uint32) (io.ChangeReader, error)
 }
 
@@ -54,6 +56,37 @@ func (haa *HistoryArchiveAdapter) BucketListHash(sequence uint32) (xdr.Hash, err
 	return has.BucketListHash()
 }
 
+// LedgerHash returns the hash of the header of the checkpoint ledger, as
+// published in the ledger category of the history archive.
+func (haa *HistoryArchiveAdapter) LedgerHash(checkpoint uint32) (xdr.Hash, error) {
+	exists, err := haa.archive.CategoryCheckpointExists("ledger", checkpoint)
+	if err != nil {
+		return xdr.Hash{}, errors.Wrap(err, "error checking if category checkpoint exists")
+	}
+	if !exists {
+		return xdr.Hash{}, errors.Errorf("ledger checkpoint does not exist for ledger %d", checkpoint)
+	}
+
+	stream, err := haa.archive.GetXdrStream(historyarchive.CategoryCheckpointPath("ledger", checkpoint))
+	if err != nil {
+		return xdr.Hash{}, errors.Wrapf(err, "unable to get ledger headers of checkpoint %d", checkpoint)
+	}
+	defer stream.Close()
+
+	for {
+		var entry xdr.LedgerHeaderHistoryEntry
+		if err = stream.ReadOne(&entry); err == stdio.EOF {
+			break
+		} else if err != nil {
+			return xdr.Hash{}, errors.Wrap(err, "unable to read ledger header")
+		}
+		if uint32(entry.Header.LedgerSeq) == checkpoint {
+			return entry.Hash, nil
+		}
+	}
+	return xdr.Hash{}, errors.Errorf("ledger %d not found in checkpoint", checkpoint)
+}
+
 // GetState returns a reader with the state of the ledger at the provided sequence number.
 func (haa *HistoryArchiveAdapter) GetState(ctx context.Context, sequence uint32) (io.ChangeReader, error) {
 	exists, err := haa.archive.CategoryCheckpointExists("history", sequence)
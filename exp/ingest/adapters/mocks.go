@@ -22,6 +22,11 @@ func (m *MockHistoryArchiveAdapter) BucketListHash(sequence uint32) (xdr.Hash, e
 	return args.Get(0).(xdr.Hash), args.Error(1)
 }
 
+func (m *MockHistoryArchiveAdapter) LedgerHash(checkpoint uint32) (xdr.Hash, error) {
+	args := m.Called(checkpoint)
+	return args.Get(0).(xdr.Hash), args.Error(1)
+}
+
 func (m *MockHistoryArchiveAdapter) GetState(ctx context.Context, sequence uint32) (io.ChangeReader, error) {
 	args := m.Called(ctx, sequence)
 	return args.Get(0).(io.ChangeReader), args.Error(1)
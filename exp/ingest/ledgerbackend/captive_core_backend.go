@@ -3,6 +3,7 @@ package ledgerbackend
 import (
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -31,7 +32,9 @@ func roundDownToFirstReplayAfterCheckpointStart(ledger uint32) uint32 {
 
 type metaResult struct {
 	*xdr.LedgerCloseMeta
-	err error
+	// size is the size of the framed meta read from the pipe
+	size int64
+	err  error
 }
 
 // CaptiveStellarCore is a ledger backend that starts internal Stellar-Core
@@ -85,6 +88,17 @@ type CaptiveStellarCore struct {
 	// wait is a waiting group waiting for a read-ahead buffer to return.
 	wait sync.WaitGroup
 
+	// readAheadLedgers and readAheadBytes limit the ledgers, and the size of
+	// their meta, held by the read-ahead buffer. Reading from Stellar-Core
+	// is paused while the buffer is full. The size isn't limited when
+	// readAheadBytes is 0.
+	readAheadLedgers int
+	readAheadBytes   int64
+	// bufferedBytes is the size of the meta in the read-ahead buffer,
+	// bufferReleased is notified when it decreases.
+	bufferedBytes  int64
+	bufferReleased chan struct{}
+
 	stellarCoreRunner stellarCoreRunnerInterface
 	cachedMeta        *xdr.LedgerCloseMeta
 
@@ -130,6 +144,55 @@ func NewCaptive(executablePath, configPath, networkPassphrase string, historyURL
 	}, nil
 }
 
+// SetReadAheadLimits sets the maximum number of ledgers, and the maximum size
+// in bytes of their meta, read from Stellar-Core ahead of GetLedger calls. The
+// size isn't limited when bytes is 0. A single ledger larger than bytes is
+// still read when the buffer is empty. It must be called before PrepareRange.
+func (c *CaptiveStellarCore) SetReadAheadLimits(ledgers int, bytes int64) {
+	c.readAheadLedgers = ledgers
+	c.readAheadBytes = bytes
+}
+
+// openReadAheadBuffer creates the read-ahead buffer of a new session.
+func (c *CaptiveStellarCore) openReadAheadBuffer() {
+	size := c.readAheadLedgers
+	if size <= 0 {
+		size = readAheadBufferSize
+	}
+	c.metaC = make(chan metaResult, size)
+	c.bufferReleased = make(chan struct{}, 1)
+	atomic.StoreInt64(&c.bufferedBytes, 0)
+	c.shutdown = make(chan struct{})
+}
+
+// waitForBufferSpace blocks until meta of the given size fits in the
+// read-ahead buffer. It returns false if the session is shut down.
+func (c *CaptiveStellarCore) waitForBufferSpace(size int64) bool {
+	for c.readAheadBytes > 0 {
+		buffered := atomic.LoadInt64(&c.bufferedBytes)
+		if buffered == 0 || buffered+size <= c.readAheadBytes {
+			break
+		}
+		select {
+		case <-c.shutdown:
+			return false
+		case <-c.bufferReleased:
+		}
+	}
+	atomic.AddInt64(&c.bufferedBytes, size)
+	return true
+}
+
+// releaseBufferSpace removes meta of the given size from the read-ahead
+// buffer.
+func (c *CaptiveStellarCore) releaseBufferSpace(size int64) {
+	atomic.AddInt64(&c.bufferedBytes, -size)
+	select {
+	case c.bufferReleased <- struct{}{}:
+	default:
+	}
+}
+
 func (c *CaptiveStellarCore) getLatestCheckpointSequence() (uint32, error) {
 	has, err := c.archive.GetRootHAS()
 	if err != nil {
@@ -180,9 +243,7 @@ func (c *CaptiveStellarCore) openOfflineReplaySubprocess(from, to uint32) error
 	c.processExit = false
 	c.processErr = nil
 
-	// read-ahead buffer
-	c.metaC = make(chan metaResult, readAheadBufferSize)
-	c.shutdown = make(chan struct{})
+	c.openReadAheadBuffer()
 	c.wait.Add(1)
 	go c.sendLedgerMeta(to)
 	return nil
@@ -239,9 +300,7 @@ func (c *CaptiveStellarCore) openOnlineReplaySubprocess(from uint32) error {
 	c.processExit = false
 	c.processErr = nil
 
-	// read-ahead buffer
-	c.metaC = make(chan metaResult, readAheadBufferSize)
-	c.shutdown = make(chan struct{})
+	c.openReadAheadBuffer()
 	c.wait.Add(1)
 	go c.sendLedgerMeta(0)
 	return nil
@@ -262,7 +321,7 @@ func (c *CaptiveStellarCore) sendLedgerMeta(untilSequence uint32) {
 		default:
 		}
 
-		meta, err := c.readLedgerMetaFromPipe()
+		meta, size, err := c.readLedgerMetaFromPipe()
 		if err != nil {
 			select {
 			case processErr := <-c.stellarCoreRunner.getProcessExitChan():
@@ -280,10 +339,13 @@ func (c *CaptiveStellarCore) sendLedgerMeta(untilSequence uint32) {
 			}
 			// When `GetLedger` sees the error it will close the backend. We shouldn't
 			// close it now because there may be some ledgers in a buffer.
-			c.metaC <- metaResult{nil, err}
+			c.metaC <- metaResult{nil, 0, err}
+			return
+		}
+		if !c.waitForBufferSpace(size) {
 			return
 		}
-		c.metaC <- metaResult{meta, nil}
+		c.metaC <- metaResult{meta, size, nil}
 
 		if untilSequence != 0 {
 			if meta.LedgerSequence() >= untilSequence {
@@ -294,21 +356,21 @@ func (c *CaptiveStellarCore) sendLedgerMeta(untilSequence uint32) {
 	}
 }
 
-func (c *CaptiveStellarCore) readLedgerMetaFromPipe() (*xdr.LedgerCloseMeta, error) {
+func (c *CaptiveStellarCore) readLedgerMetaFromPipe() (*xdr.LedgerCloseMeta, int64, error) {
 	metaPipe := c.stellarCoreRunner.getMetaPipe()
 	if metaPipe == nil {
-		return nil, errors.New("missing metadata pipe")
+		return nil, 0, errors.New("missing metadata pipe")
 	}
 	var xlcm xdr.LedgerCloseMeta
-	_, e0 := xdr.UnmarshalFramed(metaPipe, &xlcm)
+	n, e0 := xdr.UnmarshalFramed(metaPipe, &xlcm)
 	if e0 != nil {
 		if e0 == io.EOF {
-			return nil, errors.Wrap(e0, "got EOF from subprocess")
+			return nil, 0, errors.Wrap(e0, "got EOF from subprocess")
 		} else {
-			return nil, errors.Wrap(e0, "unmarshalling framed LedgerCloseMeta")
+			return nil, 0, errors.Wrap(e0, "unmarshalling framed LedgerCloseMeta")
 		}
 	}
-	return &xlcm, nil
+	return &xlcm, int64(n), nil
 }
 
 // PrepareRange prepares the given range (including from and to) to be loaded.
@@ -438,6 +500,7 @@ loop:
 		}
 
 		metaResult := <-c.metaC
+		c.releaseBufferSpace(metaResult.size)
 		if metaResult.err != nil {
 			errOut = metaResult.err
 			break loop
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/stellar/go/historyarchive"
 	"github.com/stellar/go/network"
@@ -510,6 +511,59 @@ func TestGetLatestLedgerSequence(t *testing.T) {
 	err = captiveBackend.Close()
 	assert.NoError(t, err)
 }
+func TestCaptiveReadAheadBytesLimit(t *testing.T) {
+	var buf bytes.Buffer
+
+	for i := 64; i <= 99; i++ {
+		writeLedgerHeader(&buf, uint32(i))
+	}
+
+	mockRunner := &stellarCoreRunnerMock{}
+	mockRunner.On("runFrom", uint32(64)).Return(nil).Once()
+	mockRunner.On("getMetaPipe").Return(&buf)
+	mockRunner.On("getProcessExitChan").Return(make(chan error))
+	mockRunner.On("close").Return(nil).Once()
+
+	mockArchive := &historyarchive.MockArchive{}
+	mockArchive.
+		On("GetRootHAS").
+		Return(historyarchive.HistoryArchiveState{
+			CurrentLedger: uint32(200),
+		}, nil)
+
+	captiveBackend := CaptiveStellarCore{
+		archive:           mockArchive,
+		networkPassphrase: network.PublicNetworkPassphrase,
+		stellarCoreRunner: mockRunner,
+	}
+	// every ledger is larger than the limit so a single ledger is buffered
+	captiveBackend.SetReadAheadLimits(10, 1)
+
+	err := captiveBackend.PrepareRange(UnboundedRange(64))
+	assert.NoError(t, err)
+
+	for len(captiveBackend.metaC) == 0 {
+	}
+	time.Sleep(50 * time.Millisecond)
+	latest, err := captiveBackend.GetLatestLedgerSequence()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(64), latest)
+
+	exists, _, err := captiveBackend.GetLedger(64)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	for len(captiveBackend.metaC) == 0 {
+	}
+	time.Sleep(50 * time.Millisecond)
+	latest, err = captiveBackend.GetLatestLedgerSequence()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(65), latest)
+
+	err = captiveBackend.Close()
+	assert.NoError(t, err)
+}
+
 func TestCaptiveGetLedger(t *testing.T) {
 	tt := assert.New(t)
 	var buf bytes.Buffer
@@ -285,6 +285,15 @@ func (graph *OrderBookGraph) remove(offerID xdr.Int64) error {
 	return nil
 }
 
+// LastLedger returns the sequence of the last ledger applied to the order
+// book, 0 if the order book was cleared.
+func (graph *OrderBookGraph) LastLedger() uint32 {
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	return graph.lastLedger
+}
+
 // IsEmpty returns true if the orderbook graph is not populated
 func (graph *OrderBookGraph) IsEmpty() bool {
 	graph.lock.RLock()
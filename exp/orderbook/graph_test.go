@@ -312,8 +312,8 @@ func TestApplyOutdatedLedger(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
-	if graph.lastLedger != 2 {
-		t.Fatalf("expected last ledger to be %v but got %v", 2, graph.lastLedger)
+	if graph.LastLedger() != 2 {
+		t.Fatalf("expected last ledger to be %v but got %v", 2, graph.LastLedger())
 	}
 
 	graph.AddOffer(eurOffer)
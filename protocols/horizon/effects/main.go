@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/protocols/horizon/base"
 	"github.com/stellar/go/support/render/hal"
 )
@@ -159,6 +160,9 @@ type Base struct {
 	Type            string    `json:"type"`
 	TypeI           int32     `json:"type_i"`
 	LedgerCloseTime time.Time `json:"created_at"`
+	// Transaction is non nil when the "join=transactions" parameter is present
+	// in the effects request
+	Transaction *horizon.Transaction `json:"transaction,omitempty"`
 }
 
 // PagingToken implements `hal.Pageable` and Effect
@@ -171,6 +175,15 @@ type AccountCreated struct {
 	StartingBalance string `json:"starting_balance"`
 }
 
+// AccountRemoved is the effect of an account merged into Destination, to
+// which its native balance, Amount, was transferred. Both are empty for
+// effects ingested by older Horizon versions.
+type AccountRemoved struct {
+	Base
+	Destination string `json:"destination,omitempty"`
+	Amount      string `json:"amount,omitempty"`
+}
+
 type AccountCredited struct {
 	Base
 	base.Asset
@@ -355,6 +368,12 @@ func UnmarshalEffect(effectType string, dataString []byte) (effects Effect, err
 			return
 		}
 		effects = effect
+	case EffectTypeNames[EffectAccountRemoved]:
+		var effect AccountRemoved
+		if err = json.Unmarshal(dataString, &effect); err != nil {
+			return
+		}
+		effects = effect
 	case EffectTypeNames[EffectAccountCredited]:
 		var effect AccountCredited
 		if err = json.Unmarshal(dataString, &effect); err != nil {
@@ -12,6 +12,7 @@ import (
 	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
 	"github.com/stellar/go/xdr"
 )
 
@@ -159,6 +160,9 @@ type AccountFlags struct {
 	AuthRequired  bool `json:"auth_required"`
 	AuthRevocable bool `json:"auth_revocable"`
 	AuthImmutable bool `json:"auth_immutable"`
+	// AuthClawbackEnabled is only set once the network supports clawbacks
+	// (CAP-35).
+	AuthClawbackEnabled bool `json:"auth_clawback_enabled"`
 }
 
 // AccountThresholds represents an accounts "thresholds", the numerical values
@@ -169,6 +173,27 @@ type AccountThresholds struct {
 	HighThreshold byte `json:"high_threshold"`
 }
 
+// AccountThresholdsChange is the master weight and thresholds of an account
+// after a set_options operation which changed them.
+type AccountThresholdsChange struct {
+	Links struct {
+		Account   hal.Link `json:"account"`
+		Operation hal.Link `json:"operation"`
+	} `json:"_links"`
+
+	ID              string            `json:"id"`
+	PT              string            `json:"paging_token"`
+	AccountID       string            `json:"account_id"`
+	LedgerCloseTime time.Time         `json:"ledger_close_time"`
+	MasterKeyWeight byte              `json:"master_key_weight"`
+	Thresholds      AccountThresholds `json:"thresholds"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res AccountThresholdsChange) PagingToken() string {
+	return res.PT
+}
+
 // Asset represents a single asset
 type Asset base.Asset
 
@@ -190,6 +215,26 @@ func (res AssetStat) PagingToken() string {
 	return res.PT
 }
 
+// AssetHolder represents an account holding a positive balance of an asset
+type AssetHolder struct {
+	Links struct {
+		Account hal.Link `json:"account"`
+	} `json:"_links"`
+
+	PT                                string `json:"paging_token"`
+	AccountID                         string `json:"account_id"`
+	Balance                           string `json:"balance"`
+	Limit                             string `json:"limit"`
+	LastModifiedLedger                uint32 `json:"last_modified_ledger"`
+	IsAuthorized                      bool   `json:"is_authorized"`
+	IsAuthorizedToMaintainLiabilities bool   `json:"is_authorized_to_maintain_liabilities"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res AssetHolder) PagingToken() string {
+	return res.PT
+}
+
 // Balance represents an account's holdings for a single currency type
 type Balance struct {
 	Balance                           string `json:"balance"`
@@ -234,6 +279,50 @@ func (l Ledger) PagingToken() string {
 	return l.PT
 }
 
+// LatestLedger is the latest ledger of the view selected by Source: "core",
+// the latest ledger closed by stellar-core, or "ingested", the latest ledger
+// of Horizon's history. The latest ledgers of both views are included.
+type LatestLedger struct {
+	Links struct {
+		Self   hal.Link `json:"self"`
+		Ledger hal.Link `json:"ledger"`
+	} `json:"_links"`
+
+	Source          string `json:"source"`
+	Sequence        int32  `json:"sequence"`
+	CoreSequence    int32  `json:"core_latest_ledger"`
+	HorizonSequence int32  `json:"history_latest_ledger"`
+}
+
+// LedgerManifest contains the checksums of the trades and payments ingested
+// for a ledger, used to verify that two Horizon instances hold the same data.
+type LedgerManifest struct {
+	Links struct {
+		Self     hal.Link `json:"self"`
+		Ledger   hal.Link `json:"ledger"`
+		Payments hal.Link `json:"payments"`
+	} `json:"_links"`
+	Sequence     int32  `json:"sequence"`
+	TradesHash   string `json:"trades_hash"`
+	PaymentsHash string `json:"payments_hash"`
+}
+
+// LedgerExport contains all the history of a ledger in a single document, so
+// indexers can replicate Horizon ledger by ledger.
+type LedgerExport struct {
+	Links struct {
+		Self   hal.Link `json:"self"`
+		Ledger hal.Link `json:"ledger"`
+	} `json:"_links"`
+	Ledger       Ledger        `json:"ledger"`
+	Transactions []Transaction `json:"transactions"`
+	// Operations and Effects are the resources of the operations and effects
+	// packages.
+	Operations []hal.Pageable `json:"operations"`
+	Trades     []Trade        `json:"trades"`
+	Effects    []hal.Pageable `json:"effects"`
+}
+
 // Offer is the display form of an offer to trade currency.
 type Offer struct {
 	Links struct {
@@ -265,6 +354,13 @@ type OrderBookSummary struct {
 	Buying  Asset        `json:"counter"`
 }
 
+// LedgerOrderBookSummary represents the order book of an asset pair at the
+// end of a ledger.
+type LedgerOrderBookSummary struct {
+	OrderBookSummary
+	Ledger int32 `json:"ledger"`
+}
+
 // Path represents a single payment path.
 type Path struct {
 	SourceAssetType        string  `json:"source_asset_type"`
@@ -303,6 +399,7 @@ type Root struct {
 		Effects             hal.Link  `json:"effects"`
 		FeeStats            hal.Link  `json:"fee_stats"`
 		Friendbot           *hal.Link `json:"friendbot,omitempty"`
+		LatestLedger        hal.Link  `json:"latest_ledger"`
 		Ledger              hal.Link  `json:"ledger"`
 		Ledgers             hal.Link  `json:"ledgers"`
 		Offer               *hal.Link `json:"offer,omitempty"`
@@ -326,6 +423,7 @@ type Root struct {
 	HorizonSequence              int32  `json:"history_latest_ledger"`
 	HistoryElderSequence         int32  `json:"history_elder_ledger"`
 	CoreSequence                 int32  `json:"core_latest_ledger"`
+	LatestLedgerSource           string `json:"latest_ledger_source"`
 	NetworkPassphrase            string `json:"network_passphrase"`
 	CurrentProtocolVersion       int32  `json:"current_protocol_version"`
 	CoreSupportedProtocolVersion int32  `json:"core_supported_protocol_version"`
@@ -364,7 +462,11 @@ type Trade struct {
 	CounterAssetCode   string    `json:"counter_asset_code,omitempty"`
 	CounterAssetIssuer string    `json:"counter_asset_issuer,omitempty"`
 	BaseIsSeller       bool      `json:"base_is_seller"`
+	BaseIsMaker        bool      `json:"base_is_maker"`
 	Price              *Price    `json:"price"`
+	// Transaction is non nil when the "join=transactions" parameter is present
+	// in the trades request
+	Transaction *Transaction `json:"transaction,omitempty"`
 }
 
 // PagingToken implementation for hal.Pageable
@@ -404,6 +506,8 @@ type TradeAggregation struct {
 	BaseVolume    string    `json:"base_volume"`
 	CounterVolume string    `json:"counter_volume"`
 	Average       string    `json:"avg"`
+	VWAP          string    `json:"vwap"`
+	AveragePrice  string    `json:"avg_price"`
 	High          string    `json:"high"`
 	HighR         xdr.Price `json:"high_r"`
 	Low           string    `json:"low"`
@@ -446,6 +550,8 @@ type Transaction struct {
 	FeeCharged         int64               `json:"fee_charged,string"`
 	MaxFee             int64               `json:"max_fee,string"`
 	OperationCount     int32               `json:"operation_count"`
+	TradeCount         int32               `json:"trade_count"`
+	EffectCount        int32               `json:"effect_count"`
 	EnvelopeXdr        string              `json:"envelope_xdr"`
 	ResultXdr          string              `json:"result_xdr"`
 	ResultMetaXdr      string              `json:"result_meta_xdr"`
@@ -540,6 +646,50 @@ type TransactionResultCodes struct {
 	OperationCodes  []string `json:"operations,omitempty"`
 }
 
+// TransactionCheck is the result of the pre-flight checks of a transaction
+// envelope by the `/transactions/check` end-point. Valid is false if any
+// finding is an error, the transaction would then be rejected if submitted.
+// Passing the checks doesn't guarantee the transaction succeeds.
+type TransactionCheck struct {
+	Hash     string                    `json:"hash,omitempty"`
+	Valid    bool                      `json:"valid"`
+	Findings []TransactionCheckFinding `json:"findings"`
+}
+
+// Severities of the findings of a TransactionCheck.
+const (
+	TransactionCheckError   = "error"
+	TransactionCheckWarning = "warning"
+)
+
+// TransactionCheckFinding is an issue found by a check of a TransactionCheck.
+// Operation is the index of the operation the finding is about, if any.
+type TransactionCheckFinding struct {
+	Check     string `json:"check"`
+	Severity  string `json:"severity"`
+	Code      string `json:"code"`
+	Detail    string `json:"detail"`
+	Operation *int   `json:"operation,omitempty"`
+}
+
+// TransactionBatch is the response of the `/transactions/batch` end-point,
+// its records are the results of the submitted envelopes in the order of the
+// request.
+type TransactionBatch struct {
+	Records []TransactionBatchResult `json:"records"`
+}
+
+// TransactionBatchResult is the result of the submission of an envelope of a
+// TransactionBatch. Transaction is the ingested transaction if it was
+// successful, otherwise Problem is the error its submission would have
+// returned on the `/transactions` end-point.
+type TransactionBatchResult struct {
+	Hash        string       `json:"hash,omitempty"`
+	Successful  bool         `json:"successful"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Problem     *problem.P   `json:"problem,omitempty"`
+}
+
 // KeyTypeFromAddress converts the version byte of the provided strkey encoded
 // value (for example an account id or a signer key) and returns the appropriate
 // horizon-specific type name.
@@ -596,6 +746,14 @@ type TradesPage struct {
 	} `json:"_embedded"`
 }
 
+// AccountThresholdsChangesPage returns a list of account thresholds changes
+type AccountThresholdsChangesPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []AccountThresholdsChange `json:"records"`
+	} `json:"_embedded"`
+}
+
 // OffersPage returns a list of offers
 type OffersPage struct {
 	Links    hal.Links `json:"_links"`
@@ -205,6 +205,17 @@ type AccountMerge struct {
 	Into    string `json:"into"`
 }
 
+// AccountMergePayment is the json resource representing an AccountMerge
+// operation as a payment of the merged native balance from the merged
+// account to the destination account. It is returned instead of
+// AccountMerge by payments end-points requested with
+// `merges_as_payments=true`.
+type AccountMergePayment struct {
+	Payment
+	Account string `json:"account"`
+	Into    string `json:"into"`
+}
+
 // Inflation is the json resource representing a single operation whose type is
 // Inflation.
 type Inflation struct {
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"go/types"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/services/horizon/internal/consistency"
+	support "github.com/stellar/go/support/config"
+	"github.com/stellar/go/support/log"
+)
+
+var compareFrom, compareTo, compareConcurrency uint32
+
+var compareCmdOpts = []*support.ConfigOption{
+	{
+		Name:        "from",
+		ConfigKey:   &compareFrom,
+		OptType:     types.Uint32,
+		Required:    true,
+		FlagDefault: uint32(0),
+		Usage:       "first ledger of the range to compare",
+	},
+	{
+		Name:        "to",
+		ConfigKey:   &compareTo,
+		OptType:     types.Uint32,
+		Required:    true,
+		FlagDefault: uint32(0),
+		Usage:       "last ledger of the range to compare",
+	},
+	{
+		Name:        "concurrency",
+		ConfigKey:   &compareConcurrency,
+		OptType:     types.Uint32,
+		Required:    false,
+		FlagDefault: uint32(10),
+		Usage:       "number of ledgers compared concurrently",
+	},
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare [horizon url] [other horizon url]",
+	Short: "compares the data of two horizon instances within a range",
+	Long: "compares the ledgers, transaction and operation counts, effects, and the trade and payment checksums of " +
+		"the ledger manifests of two horizon instances, ledger by ledger, and prints the divergences by table " +
+		"as JSON. Exits with status 1 when the instances diverge.",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, co := range compareCmdOpts {
+			co.Require()
+			co.SetValue()
+		}
+
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		checker := consistency.Checker{
+			A:           consistency.NewInstance(args[0]),
+			B:           consistency.NewInstance(args[1]),
+			Concurrency: int(compareConcurrency),
+		}
+		report, err := checker.Check(context.Background(), compareFrom, compareTo)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+
+		if !report.Consistent() {
+			log.WithField("divergences", len(report.Divergences)).Error("Horizon instances diverge")
+			os.Exit(1)
+		}
+		log.Info("Horizon instances are consistent")
+	},
+}
+
+func init() {
+	for _, co := range compareCmdOpts {
+		err := co.Init(compareCmd)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	rootCmd.AddCommand(compareCmd)
+}
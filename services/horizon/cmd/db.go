@@ -1,18 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"go/types"
 	"log"
+	"net/http"
 	"os"
 	"strconv"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/stellar/go/services/horizon/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/db2/schema"
 	"github.com/stellar/go/services/horizon/internal/expingest"
+	"github.com/stellar/go/services/horizon/internal/expingest/processors"
 	support "github.com/stellar/go/support/config"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
@@ -50,11 +57,31 @@ var dbInitCmd = &cobra.Command{
 	},
 }
 
+var migrateDryRun bool
+
+var migrateCmdOpts = []*support.ConfigOption{
+	{
+		Name:        "dry-run",
+		ConfigKey:   &migrateDryRun,
+		OptType:     types.Bool,
+		Required:    false,
+		FlagDefault: false,
+		Usage: "[optional] print the SQL of the migrations which would be run without applying them " +
+			"and verify the checksums of the applied migrations",
+	},
+}
+
 var dbMigrateCmd = &cobra.Command{
-	Use:   "migrate [up|down|redo] [COUNT]",
+	Use:   "migrate [up|down|redo|status] [COUNT]",
 	Short: "migrate schema",
-	Long:  "performs a schema migration command",
+	Long: "performs a schema migration command, status lists the applied and pending migrations " +
+		"and verifies the checksums of the applied ones",
 	Run: func(cmd *cobra.Command, args []string) {
+		for _, co := range migrateCmdOpts {
+			co.Require()
+			co.SetValue()
+		}
+
 		// Allow invokations with 1 or 2 args.  All other args counts are erroneous.
 		if len(args) < 1 || len(args) > 2 {
 			cmd.Usage()
@@ -85,6 +112,25 @@ var dbMigrateCmd = &cobra.Command{
 		}
 		pingDB(db)
 
+		if args[0] == "status" {
+			if !printMigrationStatus(db) {
+				os.Exit(1)
+			}
+			return
+		}
+
+		if migrateDryRun {
+			numMigrations, err := schema.DryRun(db, os.Stdout, dir, count)
+			if err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("%d migrations would be applied.\n", numMigrations)
+			if !verifyMigrationChecksums(db) {
+				os.Exit(1)
+			}
+			return
+		}
+
 		numMigrationsRun, err := schema.Migrate(db, dir, count)
 		if err != nil {
 			log.Fatal(err)
@@ -98,6 +144,58 @@ var dbMigrateCmd = &cobra.Command{
 	},
 }
 
+// printMigrationStatus prints the state of every migration. Returns false if
+// the file of an applied migration was modified.
+func printMigrationStatus(db *sql.DB) bool {
+	statuses, err := schema.Status(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "MIGRATION\tAPPLIED AT\tCHECKSUM")
+	valid := true
+	for _, status := range statuses {
+		appliedAt, checksum := "pending", ""
+		if status.Applied() {
+			appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+			switch {
+			case status.Checksum == "":
+				checksum = "unknown migration"
+			case status.AppliedChecksum == "":
+				checksum = "not recorded"
+			case status.Modified():
+				checksum = "MODIFIED"
+				valid = false
+			default:
+				checksum = "ok"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status.ID, appliedAt, checksum)
+	}
+	w.Flush()
+
+	return valid
+}
+
+// verifyMigrationChecksums logs the applied migrations whose file was
+// modified. Returns false if there are any.
+func verifyMigrationChecksums(db *sql.DB) bool {
+	statuses, err := schema.Status(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	valid := true
+	for _, status := range statuses {
+		if status.Modified() {
+			log.Printf("Applied migration %s was modified.\n", status.ID)
+			valid = false
+		}
+	}
+	return valid
+}
+
 var dbReapCmd = &cobra.Command{
 	Use:   "reap",
 	Short: "reaps (i.e. removes) any reapable history data",
@@ -129,6 +227,11 @@ var (
 	parallelJobSize     uint32
 	retries             uint
 	retryBackoffSeconds uint
+	analyzeTables       bool
+	vacuumTables        bool
+	fromHistoryArchive  bool
+	progressInterval    uint
+	progressFile        string
 )
 var reingestRangeCmdOpts = []*support.ConfigOption{
 	{
@@ -172,6 +275,48 @@ var reingestRangeCmdOpts = []*support.ConfigOption{
 		FlagDefault: uint(5),
 		Usage:       "[optional] backoff seconds between reingest retries",
 	},
+	{
+		Name:        "analyze-after-reingest",
+		ConfigKey:   &analyzeTables,
+		OptType:     types.Bool,
+		Required:    false,
+		FlagDefault: false,
+		Usage:       "[optional] run ANALYZE on history tables after every reingested range to refresh query planner statistics",
+	},
+	{
+		Name:        "vacuum-after-reingest",
+		ConfigKey:   &vacuumTables,
+		OptType:     types.Bool,
+		Required:    false,
+		FlagDefault: false,
+		Usage:       "[optional] run VACUUM ANALYZE on history tables after every reingested range (implies --analyze-after-reingest)",
+	},
+	{
+		Name:        "from-history-archive",
+		ConfigKey:   &fromHistoryArchive,
+		OptType:     types.Bool,
+		Required:    false,
+		FlagDefault: false,
+		Usage: "[optional] read the ledgers from the history archive instead of stellar-core. " +
+			"History archives don't contain transaction meta so effects, trades and account thresholds are not ingested, " +
+			"ranges containing ingested ledgers are refused",
+	},
+	{
+		Name:        "progress-interval",
+		ConfigKey:   &progressInterval,
+		OptType:     types.Uint,
+		Required:    false,
+		FlagDefault: uint(10),
+		Usage:       "[optional] seconds between the progress lines, with a progress bar and an ETA, printed to stderr, 0 disables them",
+	},
+	{
+		Name:        "progress-file",
+		ConfigKey:   &progressFile,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		Usage:       "[optional] file the progress is written to as JSON, on every progress line and when the reingestion ends",
+	},
 }
 
 var dbReingestRangeCmd = &cobra.Command{
@@ -209,17 +354,62 @@ var dbReingestRangeCmd = &cobra.Command{
 			log.Fatalf("cannot open Horizon DB: %v", err)
 		}
 
+		dataQualityRules, err := processors.ParseDataQualityRules(config.IngestDataQualityRules)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		transactionFilter, err := processors.ParseTransactionFilter(
+			config.IngestFilterAccounts, config.IngestFilterAssets,
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if config.IngestSkipFailedTransactions {
+			transactionFilter = processors.SkipFailedTransactions(transactionFilter)
+		}
+
+		ingestionProfile, err := processors.ParseIngestionProfile(config.IngestProfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		tradeConflicts, err := history.ParseTradeConflictMode(config.IngestTradeConflicts)
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		ingestConfig := expingest.Config{
 			NetworkPassphrase:           config.NetworkPassphrase,
 			HistorySession:              horizonSession,
 			HistoryArchiveURL:           config.HistoryArchiveURLs[0],
 			MaxReingestRetries:          int(retries),
 			ReingestRetryBackoffSeconds: int(retryBackoffSeconds),
+			ReingestProgress:            expingest.NewReingestProgress(argsInt32[0], argsInt32[1], parallelWorkers),
+			AnalyzeAfterReingest:        analyzeTables,
+			VacuumAfterReingest:         vacuumTables,
+			DataQualityRules:            dataQualityRules,
+			TransactionFilter:           transactionFilter,
+			IngestionProfile:            ingestionProfile,
+			TradeConflicts:              tradeConflicts,
+			ResumeReingest:              true,
+			Plugins:                     ingest.Plugins(),
+		}
+
+		if config.AdminPort != 0 {
+			serveReingestProgress(config.AdminPort, ingestConfig.ReingestProgress)
 		}
 
-		if config.EnableCaptiveCoreIngestion {
+		if fromHistoryArchive {
+			ingestConfig.IngestFromHistoryArchive = true
+			if len(dataQualityRules) > 0 {
+				hlog.Warn("Data quality rules are not checked when reingesting from the history archive")
+			}
+		} else if config.EnableCaptiveCoreIngestion {
 			ingestConfig.StellarCoreBinaryPath = config.StellarCoreBinaryPath
 			ingestConfig.RemoteCaptiveCoreURL = config.RemoteCaptiveCoreURL
+			ingestConfig.CaptiveCoreReadAheadLedgers = int(config.CaptiveCoreReadAheadLedgers)
+			ingestConfig.CaptiveCoreReadAheadBytes = int64(config.CaptiveCoreReadAheadBytes)
 		} else {
 			if config.StellarCoreDatabaseURL == "" {
 				log.Fatalf("flag --%s cannot be empty", stellarCoreDBURLFlagName)
@@ -231,6 +421,12 @@ var dbReingestRangeCmd = &cobra.Command{
 			ingestConfig.CoreSession = coreSession
 		}
 
+		stopProgress := reportReingestProgress(
+			ingestConfig.ReingestProgress,
+			time.Duration(progressInterval)*time.Second,
+			progressFile,
+		)
+
 		if parallelWorkers < 2 {
 			system, systemErr := expingest.NewSystem(ingestConfig)
 			if systemErr != nil {
@@ -255,6 +451,9 @@ var dbReingestRangeCmd = &cobra.Command{
 			)
 		}
 
+		ingestConfig.ReingestProgress.Finish(err)
+		stopProgress()
+
 		if err == nil {
 			hlog.Info("Range run successfully!")
 			return
@@ -276,6 +475,283 @@ var dbReingestRangeCmd = &cobra.Command{
 	},
 }
 
+// serveReingestProgress starts an admin http server exposing reingestion
+// progress at /ingestion/progress.
+func serveReingestProgress(port uint, progress *expingest.ReingestProgress) {
+	mux := http.NewServeMux()
+	mux.Handle("/ingestion/progress", progress)
+	server := &http.Server{
+		Addr:        fmt.Sprintf(":%d", port),
+		Handler:     mux,
+		ReadTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		hlog.Infof("Starting admin server on %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			hlog.Warn(errors.Wrap(err, "error in admin server"))
+		}
+	}()
+}
+
+// reportReingestProgress prints a progress line to stderr and writes the
+// progress file, when it's set, every interval until the returned function
+// is called. The returned function reports the final progress.
+func reportReingestProgress(progress *expingest.ReingestProgress, interval time.Duration, file string) func() {
+	report := func() {
+		snapshot := progress.Snapshot()
+		if interval > 0 {
+			fmt.Fprintln(os.Stderr, snapshot.ProgressLine())
+		}
+		if file != "" {
+			if err := snapshot.WriteFile(file); err != nil {
+				hlog.Warn(errors.Wrap(err, "error writing progress file"))
+			}
+		}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if interval <= 0 {
+			<-done
+			return
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		report()
+	}
+}
+
+var (
+	exportFormat  string
+	exportOutput  string
+	exportAccount string
+)
+var exportTradesCmdOpts = []*support.ConfigOption{
+	{
+		Name:        "format",
+		ConfigKey:   &exportFormat,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: string(history.ExportFormatCSV),
+		Usage:       "[optional] output format, csv or execution_reports (the trades of --account as newline delimited JSON execution reports)",
+	},
+	{
+		Name:        "account",
+		ConfigKey:   &exportAccount,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		Usage:       "[optional] account whose trades are exported, required by the execution_reports format",
+	},
+	{
+		Name:        "output",
+		ConfigKey:   &exportOutput,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		Usage:       "[optional] file to write trades to, defaults to stdout",
+	},
+}
+
+var dbExportTradesCmd = &cobra.Command{
+	Use:   "export-trades [Start sequence number] [End sequence number]",
+	Short: "exports trades within a range",
+	Long:  "streams trades in ledgers between X and Y sequence number (closed intervals) to a file or stdout",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, co := range exportTradesCmdOpts {
+			co.Require()
+			co.SetValue()
+		}
+
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		argsUInt32 := make([]uint32, 2)
+		for i, arg := range args {
+			seq, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				cmd.Usage()
+				log.Fatalf(`Invalid sequence number "%s"`, arg)
+			}
+			argsUInt32[i] = uint32(seq)
+		}
+
+		initRootConfig()
+
+		horizonSession, err := db.Open("postgres", config.DatabaseURL)
+		if err != nil {
+			log.Fatalf("cannot open Horizon DB: %v", err)
+		}
+
+		out := os.Stdout
+		if exportOutput != "" {
+			out, err = os.Create(exportOutput)
+			if err != nil {
+				log.Fatalf("cannot create output file: %v", err)
+			}
+			defer out.Close()
+		}
+
+		historyQ := &history.Q{horizonSession}
+		if history.ExportFormat(exportFormat) == history.ExportFormatExecutionReports {
+			if exportAccount == "" {
+				log.Fatal("--account is required by the execution_reports format")
+			}
+			err = historyQ.ExportExecutionReports(
+				context.Background(),
+				out,
+				exportAccount,
+				argsUInt32[0],
+				argsUInt32[1],
+			)
+		} else {
+			if exportAccount != "" {
+				log.Fatal("--account is only supported by the execution_reports format")
+			}
+			err = historyQ.ExportTrades(
+				context.Background(),
+				out,
+				argsUInt32[0],
+				argsUInt32[1],
+				history.ExportFormat(exportFormat),
+			)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var dbRebuildTradesCmd = &cobra.Command{
+	Use:   "rebuild-trades [Start sequence number] [End sequence number]",
+	Short: "rebuilds trades within a range",
+	Long: "removes trades in ledgers between X and Y sequence number (closed intervals) " +
+		"and derives them again from transactions stored in the history database",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		argsUInt32 := make([]uint32, 2)
+		for i, arg := range args {
+			seq, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				cmd.Usage()
+				log.Fatalf(`Invalid sequence number "%s"`, arg)
+			}
+			argsUInt32[i] = uint32(seq)
+		}
+
+		initRootConfig()
+
+		horizonSession, err := db.Open("postgres", config.DatabaseURL)
+		if err != nil {
+			log.Fatalf("cannot open Horizon DB: %v", err)
+		}
+
+		err = expingest.RebuildTrades(
+			context.Background(),
+			horizonSession,
+			argsUInt32[0],
+			argsUInt32[1],
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		hlog.Info("Trades rebuilt successfully!")
+	},
+}
+
+var dbBackfillManifestsCmd = &cobra.Command{
+	Use:   "backfill-manifests [Start sequence number] [End sequence number]",
+	Short: "computes the ledger manifests within a range",
+	Long: "computes the checksums of the trades and payments of the ledgers between X and Y " +
+		"sequence number (closed intervals) from the rows stored in the history database, " +
+		"ledgers ingested before the manifests were introduced don't have them",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		argsUInt32 := make([]uint32, 2)
+		for i, arg := range args {
+			seq, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				cmd.Usage()
+				log.Fatalf(`Invalid sequence number "%s"`, arg)
+			}
+			argsUInt32[i] = uint32(seq)
+		}
+
+		initRootConfig()
+
+		horizonSession, err := db.Open("postgres", config.DatabaseURL)
+		if err != nil {
+			log.Fatalf("cannot open Horizon DB: %v", err)
+		}
+
+		historyQ := &history.Q{horizonSession}
+		count, err := historyQ.BackfillLedgerManifests(argsUInt32[0], argsUInt32[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		hlog.WithField("ledgers", count).Info("Ledger manifests backfilled successfully!")
+	},
+}
+
+var dbPartitionHistoryCmd = &cobra.Command{
+	Use:   "partition-history",
+	Short: "partitions history tables",
+	Long: "converts the history_effects, history_operations and history_trades tables to tables " +
+		"partitioned by ledger ranges so that reaping and reingesting history drops partitions " +
+		"instead of deleting rows (requires postgres 11 or later)",
+	Run: func(cmd *cobra.Command, args []string) {
+		initRootConfig()
+
+		horizonSession, err := db.Open("postgres", config.DatabaseURL)
+		if err != nil {
+			log.Fatalf("cannot open Horizon DB: %v", err)
+		}
+
+		historyQ := &history.Q{horizonSession}
+		err = historyQ.Begin()
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer historyQ.Rollback()
+
+		err = historyQ.PartitionHistoryTables()
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = historyQ.Commit()
+		if err != nil {
+			log.Fatal(err)
+		}
+		hlog.Info("History tables partitioned successfully!")
+	},
+}
+
 func init() {
 	for _, co := range reingestRangeCmdOpts {
 		err := co.Init(dbReingestRangeCmd)
@@ -283,8 +759,22 @@ func init() {
 			log.Fatal(err.Error())
 		}
 	}
+	for _, co := range exportTradesCmdOpts {
+		err := co.Init(dbExportTradesCmd)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	for _, co := range migrateCmdOpts {
+		err := co.Init(dbMigrateCmd)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
 
 	viper.BindPFlags(dbReingestRangeCmd.PersistentFlags())
+	viper.BindPFlags(dbExportTradesCmd.PersistentFlags())
+	viper.BindPFlags(dbMigrateCmd.PersistentFlags())
 
 	rootCmd.AddCommand(dbCmd)
 	dbCmd.AddCommand(
@@ -292,6 +782,10 @@ func init() {
 		dbMigrateCmd,
 		dbReapCmd,
 		dbReingestCmd,
+		dbExportTradesCmd,
+		dbRebuildTradesCmd,
+		dbBackfillManifestsCmd,
+		dbPartitionHistoryCmd,
 	)
 	dbReingestCmd.AddCommand(dbReingestRangeCmd)
 }
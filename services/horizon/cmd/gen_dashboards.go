@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/services/horizon/internal/dashboards"
+	support "github.com/stellar/go/support/config"
+	"github.com/stellar/go/support/log"
+)
+
+const (
+	dashboardFile = "horizon-dashboard.json"
+	alertsFile    = "horizon-alerts.yml"
+)
+
+var genDashboardsOutputDir, genDashboardsDatasource string
+
+var genDashboardsCmdOpts = []*support.ConfigOption{
+	{
+		Name:        "output-dir",
+		ConfigKey:   &genDashboardsOutputDir,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: ".",
+		Usage:       "directory the dashboard (" + dashboardFile + ") and the alert rules (" + alertsFile + ") are written to",
+	},
+	{
+		Name:        "datasource",
+		ConfigKey:   &genDashboardsDatasource,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "Prometheus",
+		Usage:       "name of the Grafana datasource scraping horizon",
+	},
+}
+
+var genDashboardsCmd = &cobra.Command{
+	Use:   "gen-dashboards",
+	Short: "generates the Grafana dashboard and the Prometheus alert rules of horizon",
+	Long: "writes the Grafana dashboard JSON and the Prometheus alert rules monitoring the ingestion, the " +
+		"HTTP requests and the transaction submission, using the metrics exported by this version of horizon.",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, co := range genDashboardsCmdOpts {
+			co.Require()
+			co.SetValue()
+		}
+
+		dashboard, err := dashboards.Dashboard(genDashboardsDatasource)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		files := map[string][]byte{
+			dashboardFile: dashboard,
+			alertsFile:    dashboards.AlertRules(),
+		}
+		for name, content := range files {
+			path := filepath.Join(genDashboardsOutputDir, name)
+			if err := ioutil.WriteFile(path, content, 0644); err != nil {
+				log.Fatal(err)
+			}
+			log.WithField("path", path).Info("Written")
+		}
+	},
+}
+
+func init() {
+	for _, co := range genDashboardsCmdOpts {
+		err := co.Init(genDashboardsCmd)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	rootCmd.AddCommand(genDashboardsCmd)
+}
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"go/types"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -14,6 +17,7 @@ import (
 	support "github.com/stellar/go/support/config"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
 )
 
 var ingestCmd = &cobra.Command{
@@ -240,6 +244,151 @@ var ingestTriggerStateRebuildCmd = &cobra.Command{
 	},
 }
 
+var dumpAccountCheckpoint uint32
+
+var dumpAccountCmdOpts = []*support.ConfigOption{
+	{
+		Name:        "at-checkpoint",
+		ConfigKey:   &dumpAccountCheckpoint,
+		OptType:     types.Uint32,
+		Required:    true,
+		FlagDefault: uint32(0),
+		Usage:       "checkpoint ledger at which the account state is extracted",
+	},
+}
+
+var ingestDumpAccountCmd = &cobra.Command{
+	Use:   "dump-account [account id]",
+	Short: "prints all ledger entries of an account at a given checkpoint as JSON",
+	Long:  "extracts the account, trust lines, offers and data entries of an account from history archive buckets without connecting to Stellar-Core",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, co := range dumpAccountCmdOpts {
+			co.Require()
+			co.SetValue()
+		}
+
+		if len(args) != 1 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		initRootConfig()
+
+		if !historyarchive.IsCheckpoint(dumpAccountCheckpoint) {
+			log.Fatal("`--at-checkpoint` must be a checkpoint ledger")
+		}
+
+		archive, err := historyarchive.Connect(
+			config.HistoryArchiveURLs[0],
+			historyarchive.ConnectOptions{Context: context.Background()},
+		)
+		if err != nil {
+			log.Fatalf("cannot connect to history archive: %v", err)
+		}
+
+		snapshot, err := expingest.DumpAccount(context.Background(), archive, args[0], dumpAccountCheckpoint)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(snapshot); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var findEntryChangeKey string
+var findEntryChangeFrom, findEntryChangeTo uint32
+
+var findEntryChangeCmdOpts = []*support.ConfigOption{
+	{
+		Name:        "key",
+		ConfigKey:   &findEntryChangeKey,
+		OptType:     types.String,
+		Required:    true,
+		FlagDefault: "",
+		Usage:       "base64 encoded XDR of the ledger key",
+	},
+	{
+		Name:        "from",
+		ConfigKey:   &findEntryChangeFrom,
+		OptType:     types.Uint32,
+		Required:    true,
+		FlagDefault: uint32(0),
+		Usage:       "first checkpoint ledger of the range to search",
+	},
+	{
+		Name:        "to",
+		ConfigKey:   &findEntryChangeTo,
+		OptType:     types.Uint32,
+		Required:    true,
+		FlagDefault: uint32(0),
+		Usage:       "last checkpoint ledger of the range to search",
+	},
+}
+
+var ingestFindEntryChangeCmd = &cobra.Command{
+	Use:   "find-entry-change",
+	Short: "binary searches history archive checkpoints to find when a ledger entry changed",
+	Long:  "finds the checkpoint range in which a ledger entry was most recently created, updated or removed between --from and --to checkpoint ledgers",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, co := range findEntryChangeCmdOpts {
+			co.Require()
+			co.SetValue()
+		}
+
+		initRootConfig()
+
+		var key xdr.LedgerKey
+		if err := xdr.SafeUnmarshalBase64(findEntryChangeKey, &key); err != nil {
+			log.Fatalf("invalid `--key`: %v", err)
+		}
+
+		archive, err := historyarchive.Connect(
+			config.HistoryArchiveURLs[0],
+			historyarchive.ConnectOptions{Context: context.Background()},
+		)
+		if err != nil {
+			log.Fatalf("cannot connect to history archive: %v", err)
+		}
+
+		change, err := expingest.FindEntryChange(archive, key, findEntryChangeFrom, findEntryChangeTo)
+		if err == expingest.ErrEntryNotChanged {
+			log.Info("Ledger entry did not change in the given range")
+			return
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		output := struct {
+			expingest.EntryChange
+			Before string `json:"before,omitempty"`
+			After  string `json:"after,omitempty"`
+		}{EntryChange: change}
+		if change.Before != nil {
+			output.Before, err = xdr.MarshalBase64(change.Before)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if change.After != nil {
+			output.After, err = xdr.MarshalBase64(change.After)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(output); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
 func init() {
 	for _, co := range ingestVerifyRangeCmdOpts {
 		err := co.Init(ingestVerifyRangeCmd)
@@ -255,8 +404,30 @@ func init() {
 		}
 	}
 
+	for _, co := range dumpAccountCmdOpts {
+		err := co.Init(ingestDumpAccountCmd)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	for _, co := range findEntryChangeCmdOpts {
+		err := co.Init(ingestFindEntryChangeCmd)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
 	viper.BindPFlags(ingestVerifyRangeCmd.PersistentFlags())
+	viper.BindPFlags(ingestDumpAccountCmd.PersistentFlags())
+	viper.BindPFlags(ingestFindEntryChangeCmd.PersistentFlags())
 
 	rootCmd.AddCommand(ingestCmd)
-	ingestCmd.AddCommand(ingestVerifyRangeCmd, ingestStressTestCmd, ingestTriggerStateRebuildCmd)
+	ingestCmd.AddCommand(
+		ingestVerifyRangeCmd,
+		ingestStressTestCmd,
+		ingestTriggerStateRebuildCmd,
+		ingestDumpAccountCmd,
+		ingestFindEntryChangeCmd,
+	)
 }
@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	support "github.com/stellar/go/support/config"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	queryFormatJSON  = "json"
+	queryFormatTable = "table"
+)
+
+var (
+	queryCursor        string
+	queryOrder         string
+	queryLimit         uint
+	queryFormat        string
+	queryAccount       string
+	queryLedger        uint32
+	queryIncludeFailed bool
+	queryOfferID       int
+	queryBaseAsset     string
+	queryCounterAsset  string
+	queryTransaction   string
+	queryOnlyPayments  bool
+)
+
+var queryCmdOpts = []*support.ConfigOption{
+	{
+		Name:        "cursor",
+		ConfigKey:   &queryCursor,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		Usage:       "[optional] paging token of the record to start from, as accepted by the API",
+	},
+	{
+		Name:        "order",
+		ConfigKey:   &queryOrder,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: db2.OrderAscending,
+		Usage:       "[optional] order of the records, asc or desc",
+	},
+	{
+		Name:        "limit",
+		ConfigKey:   &queryLimit,
+		OptType:     types.Uint,
+		Required:    false,
+		FlagDefault: uint(db2.DefaultPageSize),
+		Usage:       fmt.Sprintf("[optional] maximum number of records to print, up to %d", db2.MaxPageSize),
+	},
+	{
+		Name:        "format",
+		ConfigKey:   &queryFormat,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: queryFormatTable,
+		Usage:       "[optional] output format, table or json (one row per line)",
+	},
+}
+
+func queryAccountOption() *support.ConfigOption {
+	return &support.ConfigOption{
+		Name:        "account",
+		ConfigKey:   &queryAccount,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		Usage:       "[optional] only print records of the account",
+	}
+}
+
+func queryLedgerOption() *support.ConfigOption {
+	return &support.ConfigOption{
+		Name:        "ledger",
+		ConfigKey:   &queryLedger,
+		OptType:     types.Uint32,
+		Required:    false,
+		FlagDefault: uint32(0),
+		Usage:       "[optional] only print records of the ledger",
+	}
+}
+
+func queryIncludeFailedOption() *support.ConfigOption {
+	return &support.ConfigOption{
+		Name:        "include-failed",
+		ConfigKey:   &queryIncludeFailed,
+		OptType:     types.Bool,
+		Required:    false,
+		FlagDefault: false,
+		Usage:       "[optional] include records of failed transactions",
+	}
+}
+
+var queryTradesCmdOpts = []*support.ConfigOption{
+	queryAccountOption(),
+	{
+		Name:        "offer-id",
+		ConfigKey:   &queryOfferID,
+		OptType:     types.Int,
+		Required:    false,
+		FlagDefault: 0,
+		Usage:       "[optional] only print trades of the offer",
+	},
+	{
+		Name:        "base-asset",
+		ConfigKey:   &queryBaseAsset,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		Usage:       "[optional] base asset of the trades, native or Code:IssuerAccountID (requires --counter-asset)",
+	},
+	{
+		Name:        "counter-asset",
+		ConfigKey:   &queryCounterAsset,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		Usage:       "[optional] counter asset of the trades, native or Code:IssuerAccountID (requires --base-asset)",
+	},
+}
+
+var queryOperationsCmdOpts = []*support.ConfigOption{
+	queryAccountOption(),
+	queryLedgerOption(),
+	queryIncludeFailedOption(),
+	{
+		Name:        "transaction",
+		ConfigKey:   &queryTransaction,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		Usage:       "[optional] only print operations of the transaction with the hash",
+	},
+	{
+		Name:        "only-payments",
+		ConfigKey:   &queryOnlyPayments,
+		OptType:     types.Bool,
+		Required:    false,
+		FlagDefault: false,
+		Usage:       "[optional] only print payment operations, as the payments endpoints",
+	},
+}
+
+var queryTransactionsCmdOpts = []*support.ConfigOption{
+	queryAccountOption(),
+	queryLedgerOption(),
+	queryIncludeFailedOption(),
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query [command]",
+	Short: "prints records of the history database",
+	Long: "query runs the queries behind the API endpoints directly against the history database " +
+		"and prints the rows, so data can be inspected without going through HTTP",
+}
+
+var queryTradesCmd = &cobra.Command{
+	Use:   "trades",
+	Short: "prints trades",
+	Run: func(cmd *cobra.Command, args []string) {
+		historyQ, pq := initQueryCmd(queryTradesCmdOpts)
+
+		filter := history.TradesFilter{
+			AccountID: queryAccount,
+			OfferID:   int64(queryOfferID),
+		}
+		if queryBaseAsset != "" || queryCounterAsset != "" {
+			if queryBaseAsset == "" || queryCounterAsset == "" {
+				log.Fatal("--base-asset and --counter-asset must be set together")
+			}
+			var err error
+			filter.BaseAssetID, err = historyQ.GetAssetID(parseQueryAsset("base-asset", queryBaseAsset))
+			if err != nil {
+				log.Fatalf("cannot find base asset: %v", err)
+			}
+			filter.CounterAssetID, err = historyQ.GetAssetID(parseQueryAsset("counter-asset", queryCounterAsset))
+			if err != nil {
+				log.Fatalf("cannot find counter asset: %v", err)
+			}
+		}
+
+		records, err := historyQ.GetTrades(context.Background(), filter, pq)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		printQueryRecords(os.Stdout, len(records), func(i int) interface{} { return records[i] },
+			"SEQUENCE\tCLOSED AT\tBASE ACCOUNT\tBASE AMOUNT\tCOUNTER ACCOUNT\tCOUNTER AMOUNT\tPRICE",
+			func(w io.Writer, i int) {
+				trade := records[i]
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s %s\t%s\t%s %s\t%d/%d\n",
+					trade.Sequence,
+					trade.LedgerCloseTime.UTC().Format("2006-01-02T15:04:05Z"),
+					trade.BaseAccount,
+					amount.String(trade.BaseAmount),
+					formatQueryAsset(trade.BaseAssetType, trade.BaseAssetCode, trade.BaseAssetIssuer),
+					trade.CounterAccount,
+					amount.String(trade.CounterAmount),
+					formatQueryAsset(trade.CounterAssetType, trade.CounterAssetCode, trade.CounterAssetIssuer),
+					trade.PriceN.Int64,
+					trade.PriceD.Int64,
+				)
+			},
+		)
+	},
+}
+
+var queryOperationsCmd = &cobra.Command{
+	Use:   "operations",
+	Short: "prints operations",
+	Run: func(cmd *cobra.Command, args []string) {
+		historyQ, pq := initQueryCmd(queryOperationsCmdOpts)
+
+		records, _, err := historyQ.GetOperations(context.Background(), history.OperationsFilter{
+			AccountID:       queryAccount,
+			LedgerSequence:  int32(queryLedger),
+			TransactionHash: queryTransaction,
+			OnlyPayments:    queryOnlyPayments,
+			IncludeFailed:   queryIncludeFailed,
+		}, pq)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		printQueryRecords(os.Stdout, len(records), func(i int) interface{} { return records[i] },
+			"ID\tTRANSACTION\tTYPE\tSOURCE ACCOUNT\tSUCCESSFUL",
+			func(w io.Writer, i int) {
+				operation := records[i]
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\n",
+					operation.ID,
+					operation.TransactionHash,
+					operation.Type.String(),
+					operation.SourceAccount,
+					operation.TransactionSuccessful,
+				)
+			},
+		)
+	},
+}
+
+var queryTransactionsCmd = &cobra.Command{
+	Use:   "transactions",
+	Short: "prints transactions",
+	Run: func(cmd *cobra.Command, args []string) {
+		historyQ, pq := initQueryCmd(queryTransactionsCmdOpts)
+
+		records, err := historyQ.GetTransactions(context.Background(), history.TransactionsFilter{
+			AccountID:      queryAccount,
+			LedgerSequence: int32(queryLedger),
+			IncludeFailed:  queryIncludeFailed,
+		}, pq)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		printQueryRecords(os.Stdout, len(records), func(i int) interface{} { return records[i] },
+			"ID\tHASH\tLEDGER\tACCOUNT\tOPERATIONS\tFEE CHARGED\tSUCCESSFUL",
+			func(w io.Writer, i int) {
+				transaction := records[i]
+				fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%d\t%d\t%t\n",
+					transaction.ID,
+					transaction.TransactionHash,
+					transaction.LedgerSequence,
+					transaction.Account,
+					transaction.OperationCount,
+					transaction.FeeCharged,
+					transaction.Successful,
+				)
+			},
+		)
+	},
+}
+
+// initQueryCmd sets the common and the given options of a query command and
+// returns a history Q connected to the Horizon DB with the page query.
+func initQueryCmd(opts []*support.ConfigOption) (*history.Q, db2.PageQuery) {
+	for _, co := range append(queryCmdOpts, opts...) {
+		co.Require()
+		co.SetValue()
+	}
+
+	if queryFormat != queryFormatJSON && queryFormat != queryFormatTable {
+		log.Fatalf("Invalid format %q, must be table or json", queryFormat)
+	}
+
+	pq, err := db2.NewPageQuery(queryCursor, false, queryOrder, uint64(queryLimit))
+	if err != nil {
+		log.Fatalf("Invalid paging parameters: %v", err)
+	}
+
+	initRootConfig()
+
+	horizonSession, err := db.Open("postgres", config.DatabaseURL)
+	if err != nil {
+		log.Fatalf("cannot open Horizon DB: %v", err)
+	}
+
+	return &history.Q{horizonSession}, pq
+}
+
+// printQueryRecords prints n records as newline delimited JSON or, in the
+// table format, as the header followed by a row written by printRow for each
+// record.
+func printQueryRecords(
+	out io.Writer,
+	n int,
+	record func(i int) interface{},
+	header string,
+	printRow func(w io.Writer, i int),
+) {
+	if queryFormat == queryFormatJSON {
+		encoder := json.NewEncoder(out)
+		for i := 0; i < n; i++ {
+			if err := encoder.Encode(record(i)); err != nil {
+				log.Fatal(err)
+			}
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	for i := 0; i < n; i++ {
+		printRow(w, i)
+	}
+	w.Flush()
+}
+
+func parseQueryAsset(flag, value string) xdr.Asset {
+	assets, err := xdr.BuildAssets(value)
+	if err != nil || len(assets) != 1 {
+		log.Fatalf("Invalid --%s %q, must be native or Code:IssuerAccountID", flag, value)
+	}
+	return assets[0]
+}
+
+func formatQueryAsset(assetType, code, issuer string) string {
+	if assetType == "native" {
+		return "native"
+	}
+	return code + ":" + issuer
+}
+
+func init() {
+	for _, co := range queryCmdOpts {
+		err := co.Init(queryCmd)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	for cmd, opts := range map[*cobra.Command][]*support.ConfigOption{
+		queryTradesCmd:       queryTradesCmdOpts,
+		queryOperationsCmd:   queryOperationsCmdOpts,
+		queryTransactionsCmd: queryTransactionsCmdOpts,
+	} {
+		for _, co := range opts {
+			err := co.Init(cmd)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+		}
+	}
+
+	viper.BindPFlags(queryCmd.PersistentFlags())
+
+	rootCmd.AddCommand(queryCmd)
+	queryCmd.AddCommand(
+		queryTradesCmd,
+		queryOperationsCmd,
+		queryTransactionsCmd,
+	)
+}
@@ -12,8 +12,15 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/stellar/go/network"
 	horizon "github.com/stellar/go/services/horizon/internal"
+	"github.com/stellar/go/services/horizon/internal/actions"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/db2/schema"
+	"github.com/stellar/go/services/horizon/internal/expingest/processors"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
+	"github.com/stellar/go/services/horizon/internal/txsub"
 	apkg "github.com/stellar/go/support/app"
 	support "github.com/stellar/go/support/config"
 	"github.com/stellar/go/support/log"
@@ -112,6 +119,24 @@ var dbURLConfigOption = &support.ConfigOption{
 // Add a new entry here to connect a new field in the horizon.Config struct
 var configOpts = support.ConfigOptions{
 	dbURLConfigOption,
+	&support.ConfigOption{
+		Name:        "db-replica-urls",
+		EnvVar:      "DATABASE_REPLICA_URLS",
+		ConfigKey:   &config.ReplicaDatabaseURLs,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		CustomSetValue: func(co *support.ConfigOption) {
+			var urls []string
+			for _, url := range strings.Split(viper.GetString(co.Name), ",") {
+				if url = strings.TrimSpace(url); url != "" {
+					urls = append(urls, url)
+				}
+			}
+			*(co.ConfigKey.(*[]string)) = urls
+		},
+		Usage: "comma-separated list of read replicas of the horizon postgres database, used to serve history requests",
+	},
 	&support.ConfigOption{
 		Name:        "stellar-core-binary-path",
 		OptType:     types.String,
@@ -144,6 +169,20 @@ var configOpts = support.ConfigOptions{
 		Usage:       "[experimental flag!] causes Horizon to ingest from a Stellar Core subprocess instead of a persistent Stellar Core database",
 		ConfigKey:   &config.EnableCaptiveCoreIngestion,
 	},
+	&support.ConfigOption{
+		Name:        "captive-core-read-ahead-ledgers",
+		ConfigKey:   &config.CaptiveCoreReadAheadLedgers,
+		OptType:     types.Uint,
+		FlagDefault: uint(2),
+		Usage:       "maximum number of ledgers read from captive stellar-core ahead of ingestion, reading is paused while they are not ingested",
+	},
+	&support.ConfigOption{
+		Name:        "captive-core-read-ahead-bytes",
+		ConfigKey:   &config.CaptiveCoreReadAheadBytes,
+		OptType:     types.Uint,
+		FlagDefault: uint(0),
+		Usage:       "maximum size in bytes of the meta of the ledgers read from captive stellar-core ahead of ingestion, 0 (default) doesn't limit it",
+	},
 	&support.ConfigOption{
 		Name:      stellarCoreDBURLFlagName,
 		EnvVar:    "STELLAR_CORE_DATABASE_URL",
@@ -185,6 +224,46 @@ var configOpts = support.ConfigOptions{
 		FlagDefault: uint(0),
 		Usage:       "WARNING: this should not be accessible from the Internet and does not use TLS, tcp port to listen on for admin http requests, 0 (default) disables the admin server",
 	},
+	&support.ConfigOption{
+		Name:        "grpc-port",
+		ConfigKey:   &config.GRPCPort,
+		OptType:     types.Uint,
+		FlagDefault: uint(0),
+		Usage:       "tcp port to listen on for gRPC requests streaming ledgers, transactions and trades, it uses TLS when --tls-cert and --tls-key are set, 0 (default) disables the gRPC server",
+	},
+	&support.ConfigOption{
+		Name:        "grpc-max-streams",
+		ConfigKey:   &config.GRPCMaxStreams,
+		OptType:     types.Int,
+		FlagDefault: 1000,
+		Usage:       "max number of concurrent streams of the gRPC server, 0 disables the limit",
+	},
+	&support.ConfigOption{
+		Name:        "grpc-max-streams-per-ip",
+		ConfigKey:   &config.GRPCMaxStreamsPerIP,
+		OptType:     types.Int,
+		FlagDefault: 10,
+		Usage:       "max number of concurrent streams of the gRPC server opened by a single remote ip address, 0 disables the limit",
+	},
+	&support.ConfigOption{
+		Name:      "instance-id",
+		ConfigKey: &config.InstanceID,
+		OptType:   types.String,
+		Usage:     "id of this horizon instance sent in the X-Horizon-Instance header of responses, defaults to the host name",
+	},
+	&support.ConfigOption{
+		Name:      "admin-debug-token",
+		ConfigKey: &config.AdminDebugToken,
+		OptType:   types.String,
+		Usage:     "bearer token required by the admin debugging endpoints (/debug/sql_plan), they are disabled when empty",
+	},
+	&support.ConfigOption{
+		Name:        "page-limits",
+		ConfigKey:   &config.PageLimits,
+		OptType:     types.String,
+		FlagDefault: "",
+		Usage:       "comma separated list of the default and max page sizes of route groups in the group=default:max format (ex. trades=20:500), groups: " + strings.Join(actions.PageLimitGroups, ", "),
+	},
 	&support.ConfigOption{
 		Name:        "max-db-connections",
 		ConfigKey:   &config.MaxDBConnections,
@@ -206,6 +285,36 @@ var configOpts = support.ConfigOptions{
 		FlagDefault: 20,
 		Usage:       "max horizon database idle connections. may need to be set to the same value as horizon-db-max-open-connections when responses are slow and DB CPU is normal, because it may indicate that a lot of time is spent closing/opening idle connections. This can happen in case of high variance in number of requests. must be equal or lower than max open connections",
 	},
+	&support.ConfigOption{
+		Name:           "horizon-db-query-timeout",
+		ConfigKey:      &config.HorizonDBQueryTimeout,
+		OptType:        types.Int,
+		FlagDefault:    0,
+		CustomSetValue: support.SetDuration,
+		Usage:          "max duration of a single horizon database query run when serving requests (in seconds), queries running longer are cancelled and the request fails with 504 Timeout. 0 means no limit",
+	},
+	&support.ConfigOption{
+		Name:        "horizon-db-statement-cache-size",
+		ConfigKey:   &config.HorizonDBStatementCacheSize,
+		OptType:     types.Int,
+		FlagDefault: 0,
+		Usage:       "number of prepared statements of frequent queries cached by horizon (per database connection pool), 0 disables prepared statements",
+	},
+	&support.ConfigOption{
+		Name:           "horizon-db-conn-max-lifetime",
+		ConfigKey:      &config.HorizonDBConnMaxLifetime,
+		OptType:        types.Int,
+		FlagDefault:    0,
+		CustomSetValue: support.SetDuration,
+		Usage:          "max duration a horizon database connection is reused (in seconds), older connections are closed and opened again. may need to be set when connecting through a load balancer or a connection pooler. 0 means no limit",
+	},
+	&support.ConfigOption{
+		Name:        "horizon-db-shed-load",
+		ConfigKey:   &config.HorizonDBShedLoad,
+		OptType:     types.Bool,
+		FlagDefault: false,
+		Usage:       "reject requests with 503 Server Over Capacity while all the horizon database connections are in use and queries are waiting for a connection",
+	},
 	&support.ConfigOption{
 		Name:           "sse-update-frequency",
 		ConfigKey:      &config.SSEUpdateFrequency,
@@ -214,6 +323,20 @@ var configOpts = support.ConfigOptions{
 		CustomSetValue: support.SetDuration,
 		Usage:          "defines how often streams should check if there's a new ledger (in seconds), may need to increase in case of big number of streams",
 	},
+	&support.ConfigOption{
+		Name:        "sse-buffer-size",
+		ConfigKey:   &config.SSEBufferSize,
+		OptType:     types.Int,
+		FlagDefault: 0,
+		Usage:       "number of events buffered per stream while its client is reading slower than events are sent, --sse-slow-consumer-policy is applied to streams whose buffer is full. It must not be lower than the max page size of --page-limits. 0 (default) disables buffering",
+	},
+	&support.ConfigOption{
+		Name:        "sse-slow-consumer-policy",
+		ConfigKey:   &config.SSESlowConsumerPolicy,
+		OptType:     types.String,
+		FlagDefault: string(sse.SlowConsumerDisconnect),
+		Usage:       "policy applied to streams whose buffer is full: \"disconnect\" closes the stream with the cursor to resume from, \"drop\" drops events and sends a \"gap\" event describing them",
+	},
 	&support.ConfigOption{
 		Name:           "connection-timeout",
 		ConfigKey:      &config.ConnectionTimeout,
@@ -286,6 +409,13 @@ var configOpts = support.ConfigOptions{
 		FlagDefault: uint(3),
 		Usage:       "the maximum number of assets on the path in `/paths` endpoint, warning: increasing this value will increase /paths response time",
 	},
+	&support.ConfigOption{
+		Name:        "path-cache-max-age",
+		ConfigKey:   &config.PathCacheMaxAge,
+		OptType:     types.Uint,
+		FlagDefault: uint(0),
+		Usage:       "number of ledgers for which results of identical `/paths` queries are reused (1 reuses them within the same ledger only), 0 disables the cache",
+	},
 	&support.ConfigOption{
 		Name:      "network-passphrase",
 		ConfigKey: &config.NetworkPassphrase,
@@ -367,6 +497,62 @@ var configOpts = support.ConfigOptions{
 		FlagDefault: false,
 		Usage:       "ingestion system runs a verification routing to compare state in local database with history buckets, this can be disabled however it's not recommended",
 	},
+	&support.ConfigOption{
+		Name:        "ingest-data-quality-rules",
+		ConfigKey:   &config.IngestDataQualityRules,
+		OptType:     types.String,
+		FlagDefault: "none",
+		Usage:       "comma separated list of the data quality rules checked when ingesting transactions (operation_count, trade_amounts, fee_charged), violations are recorded in history_data_quality_violations, use \"all\" to check all rules, checks are disabled by default",
+	},
+	&support.ConfigOption{
+		Name:        "ingest-filter-accounts",
+		ConfigKey:   &config.IngestFilterAccounts,
+		OptType:     types.String,
+		FlagDefault: "",
+		Usage:       "comma separated list of accounts, when set (or when --ingest-filter-assets is set) only the transactions with one of the accounts as a participant, in an effect or as the seller of a trade are ingested into the history tables",
+	},
+	&support.ConfigOption{
+		Name:        "ingest-filter-assets",
+		ConfigKey:   &config.IngestFilterAssets,
+		OptType:     types.String,
+		FlagDefault: "",
+		Usage:       "comma separated list of assets in the CODE:ISSUER format, when set (or when --ingest-filter-accounts is set) only the transactions with an operation using one of the assets are ingested into the history tables",
+	},
+	&support.ConfigOption{
+		Name:        "ingest-skip-failed-transactions",
+		ConfigKey:   &config.IngestSkipFailedTransactions,
+		OptType:     types.Bool,
+		FlagDefault: false,
+		Usage:       "failed transactions, and their operations, are not ingested into the history tables, they can't be queried with include_failed=true",
+	},
+	&support.ConfigOption{
+		Name:        "ingest-profile",
+		ConfigKey:   &config.IngestProfile,
+		OptType:     types.String,
+		FlagDefault: "full",
+		Usage:       "history processors run when ingesting transactions: \"full\", \"trades-only\", \"payments-only\" or a comma separated list of history processors (effects, operations, trades, participants, transactions, offers, account_events, account_thresholds), ledgers are always ingested",
+	},
+	&support.ConfigOption{
+		Name:        "ingest-trade-conflicts",
+		ConfigKey:   &config.IngestTradeConflicts,
+		OptType:     types.String,
+		FlagDefault: string(history.TradeConflictUpdate),
+		Usage:       "handling of the trades which were already ingested when a ledger is ingested again: \"update\" replaces the existing trades, \"fail\" stops ingestion, \"skip\" keeps the existing trades, \"verify\" keeps them only if they are identical",
+	},
+	&support.ConfigOption{
+		Name:        "ingest-asset-watches",
+		ConfigKey:   &config.IngestAssetWatches,
+		OptType:     types.Bool,
+		FlagDefault: false,
+		Usage:       "evaluates the asset watches registered with the /asset_watches admin end-point after ingesting every ledger and notifies their webhooks",
+	},
+	&support.ConfigOption{
+		Name:        "auto-reset-testnet",
+		ConfigKey:   &config.AutoResetTestnet,
+		OptType:     types.Bool,
+		FlagDefault: false,
+		Usage:       "clears the history and the state and restarts ingestion from the latest checkpoint when a reset of the network is detected (ex. a test network reset), ingestion stops when it's disabled. Can not be used on the public network",
+	},
 	&support.ConfigOption{
 		Name:        "apply-migrations",
 		ConfigKey:   &config.ApplyMigrations,
@@ -375,6 +561,87 @@ var configOpts = support.ConfigOptions{
 		Required:    false,
 		Usage:       "applies pending migrations before starting horizon",
 	},
+	&support.ConfigOption{
+		Name:        "read-only",
+		ConfigKey:   &config.ReadOnly,
+		OptType:     types.Bool,
+		FlagDefault: false,
+		Required:    false,
+		Usage:       "starts horizon in read-only mode (ex. during database maintenance): history and state are served but transaction submission is rejected and ingestion is paused, can be toggled with the /read_only admin endpoint",
+	},
+	&support.ConfigOption{
+		Name:           "schema-stats-interval",
+		ConfigKey:      &config.SchemaStatsInterval,
+		OptType:        types.Int,
+		FlagDefault:    300,
+		CustomSetValue: support.SetDuration,
+		Usage:          "defines how often the sizes and ledger ranges of the history tables are collected for the metrics and the /schema_stats admin endpoint (in seconds), 0 disables the collection",
+	},
+	&support.ConfigOption{
+		Name:           "ingest-gap-backfill-interval",
+		ConfigKey:      &config.IngestGapBackfillInterval,
+		OptType:        types.Int,
+		FlagDefault:    0,
+		CustomSetValue: support.SetDuration,
+		Usage:          "defines how often ingesting instances scan the history for ledger gaps and reingest them (in seconds), the gaps are reported by the /ledger_gaps admin endpoint, 0 disables the scans",
+	},
+	&support.ConfigOption{
+		Name:        "ingest-pause-during-catchup",
+		ConfigKey:   &config.IngestPauseDuringCatchup,
+		OptType:     types.Bool,
+		FlagDefault: false,
+		Usage:       "pauses live ingestion while ledger gaps are reingested (see --ingest-gap-backfill-interval), it catches up with stellar-core afterwards",
+	},
+	&support.ConfigOption{
+		Name:           "ingest-state-audit-interval",
+		ConfigKey:      &config.IngestStateAuditInterval,
+		OptType:        types.Int,
+		FlagDefault:    86400,
+		CustomSetValue: support.SetDuration,
+		Usage:          "defines how often the ingested state is audited against the next history archive checkpoint (in seconds), mismatching entries are quarantined and reported by the /state_quarantine admin endpoint, 0 disables the audits",
+	},
+	&support.ConfigOption{
+		Name:        "txsub-sequence-source",
+		ConfigKey:   &config.TxSubSequenceSource,
+		OptType:     types.String,
+		FlagDefault: string(txsub.SequenceSourceHorizon),
+		Usage:       "where transaction submission reads the sequence numbers of the source accounts from: \"horizon\" reads the ingested state, \"core\" reads the stellar-core database, \"remote\" requests the Horizon instance at --txsub-remote-horizon-url",
+	},
+	&support.ConfigOption{
+		Name:        "txsub-remote-horizon-url",
+		ConfigKey:   &config.TxSubRemoteHorizonURL,
+		OptType:     types.String,
+		FlagDefault: "",
+		Usage:       "Horizon instance requested for the sequence numbers of the source accounts when --txsub-sequence-source is \"remote\"",
+	},
+	&support.ConfigOption{
+		Name:        "latest-ledger-source",
+		ConfigKey:   &config.LatestLedgerSource,
+		OptType:     types.String,
+		FlagDefault: string(ledger.LatestSourceIngested),
+		Usage:       "default source of the /ledgers/latest endpoint: \"ingested\" is the latest ledger of Horizon's history, \"core\" is the latest ledger closed by stellar-core",
+	},
+	&support.ConfigOption{
+		Name:        "enable-graphql",
+		ConfigKey:   &config.EnableGraphQL,
+		OptType:     types.Bool,
+		FlagDefault: false,
+		Usage:       "serves the GraphQL endpoint, /graphql, querying accounts, transactions, operations, trades and effects",
+	},
+	&support.ConfigOption{
+		Name:        "readiness-max-ledger-lag",
+		ConfigKey:   &config.ReadinessMaxLedgerLag,
+		OptType:     types.Uint,
+		FlagDefault: uint(5),
+		Usage:       "the maximum number of ledgers the history db may be behind stellar-core for the /ready endpoint of the admin port to report horizon as ready to serve traffic",
+	},
+	&support.ConfigOption{
+		Name:        "max-transaction-batch-size",
+		ConfigKey:   &config.MaxTransactionBatchSize,
+		OptType:     types.Uint,
+		FlagDefault: uint(100),
+		Usage:       "the maximum number of transaction envelopes submitted by a request to the /transactions/batch endpoint",
+	},
 }
 
 func init() {
@@ -437,6 +704,63 @@ func initRootConfig() {
 		// When running live ingestion a config file is required too
 		validateBothOrNeither("stellar-core-binary-path", "stellar-core-config-path")
 	}
+	if _, err := processors.ParseDataQualityRules(config.IngestDataQualityRules); err != nil {
+		stdLog.Fatalf("Invalid config: --ingest-data-quality-rules: %s", err)
+	}
+	if config.AutoResetTestnet && config.NetworkPassphrase == network.PublicNetworkPassphrase {
+		stdLog.Fatalf("Invalid config: --auto-reset-testnet can not be used on the public network")
+	}
+	if _, err := processors.ParseTransactionFilter(config.IngestFilterAccounts, config.IngestFilterAssets); err != nil {
+		stdLog.Fatalf("Invalid config: --ingest-filter-accounts, --ingest-filter-assets: %s", err)
+	}
+	if _, err := processors.ParseIngestionProfile(config.IngestProfile); err != nil {
+		stdLog.Fatalf("Invalid config: --ingest-profile: %s", err)
+	}
+	if _, err := history.ParseTradeConflictMode(config.IngestTradeConflicts); err != nil {
+		stdLog.Fatalf("Invalid config: --ingest-trade-conflicts: %s", err)
+	}
+	sequenceSource, err := txsub.ParseSequenceSource(config.TxSubSequenceSource)
+	if err != nil {
+		stdLog.Fatalf("Invalid config: --txsub-sequence-source: %s", err)
+	}
+	if sequenceSource == txsub.SequenceSourceCore && config.StellarCoreDatabaseURL == "" {
+		stdLog.Fatalf("Invalid config: --%s must be set when --txsub-sequence-source is %q", stellarCoreDBURLFlagName, sequenceSource)
+	}
+	if sequenceSource == txsub.SequenceSourceRemote && config.TxSubRemoteHorizonURL == "" {
+		stdLog.Fatalf("Invalid config: --txsub-remote-horizon-url must be set when --txsub-sequence-source is %q", sequenceSource)
+	}
+	if config.CaptiveCoreReadAheadLedgers == 0 {
+		stdLog.Fatalf("Invalid config: --captive-core-read-ahead-ledgers must be positive")
+	}
+
+	if _, err := ledger.ParseLatestSource(config.LatestLedgerSource); err != nil {
+		stdLog.Fatalf("Invalid config: --latest-ledger-source: %s", err)
+	}
+
+	pageLimits, err := actions.ParsePageLimits(config.PageLimits)
+	if err != nil {
+		stdLog.Fatalf("Invalid config: --page-limits: %s", err)
+	}
+
+	if config.SSEBufferSize < 0 {
+		stdLog.Fatalf("Invalid config: --sse-buffer-size must not be negative")
+	}
+	// a stream sends a page of events at once, so a smaller buffer would
+	// overflow while catching up
+	if maxPageSize := pageLimits.MaxPageSize(); config.SSEBufferSize > 0 && uint64(config.SSEBufferSize) < maxPageSize {
+		stdLog.Fatalf("Invalid config: --sse-buffer-size must not be lower than the max page size (%d)", maxPageSize)
+	}
+	if _, err := sse.ParseSlowConsumerPolicy(config.SSESlowConsumerPolicy); err != nil {
+		stdLog.Fatalf("Invalid config: --sse-slow-consumer-policy: %s", err)
+	}
+
+	if config.InstanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			stdLog.Fatalf("Failed to get the host name for --instance-id: %s", err)
+		}
+		config.InstanceID = hostname
+	}
 
 	// Configure log file
 	if config.LogFile != "" {
@@ -0,0 +1,129 @@
+package ingest
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/stellar/go/services/horizon/internal/toid"
+)
+
+// Types of the events published by the ingestion.
+const (
+	// TableChanged events describe the rows of a table changed by an
+	// ingested ledger.
+	TableChanged = "table_changed"
+	// LedgerIngested events are published once a ledger is committed, after
+	// the TableChanged events of the ledger.
+	LedgerIngested = "ledger_ingested"
+)
+
+// Event is published by the live ingestion once a ledger is committed, its
+// changes can be read from the database when it's received.
+//
+// Delivery is best effort: events which don't fit in the buffer of a
+// subscription are dropped, see Subscription.Dropped, and the ledger
+// committed last before horizon (or its ingestion) restarts may not have
+// been published. Its LedgerIngested event is published again when live
+// ingestion resumes, so the same event can be received twice: sinks
+// forwarding events to webhooks or message queues should deduplicate them by
+// ID. TableChanged events of that ledger are not published again, sinks
+// which must not miss changes read them from the database when they receive
+// LedgerIngested.
+type Event struct {
+	// ID is an idempotency key of the event, stable across restarts: the
+	// same event of a ledger always gets the same ID. See EventID.
+	ID       string
+	Type     string
+	Sequence uint32
+	// Table, Created, Updated and Removed are the numbers of rows of Table
+	// created, updated and removed by the ledger. They are only set for
+	// TableChanged events. The history tables count the transactions and
+	// operations of the ledger, before ingestion filters and profiles are
+	// applied.
+	Table   string
+	Created int64
+	Updated int64
+	Removed int64
+}
+
+// EventID returns the ID of the event with the given order in the events of
+// the ledger. The name is the table of TableChanged events and the type of
+// other events, the order of a notification about a row is the id of the row
+// in the table. IDs are of the form "<toid of the ledger>-<order>-<name>".
+func EventID(sequence uint32, order int64, name string) string {
+	return fmt.Sprintf("%d-%d-%s", toid.New(int32(sequence), 0, 0).ToInt64(), order, name)
+}
+
+// Bus delivers the published events to its subscribers. Publishing never
+// blocks ingestion: the events which don't fit in the buffer of a
+// subscription are dropped. It's safe for concurrent use.
+type Bus struct {
+	mutex         sync.RWMutex
+	subscriptions map[*Subscription]bool
+}
+
+// NewBus creates a Bus without subscribers.
+func NewBus() *Bus {
+	return &Bus{subscriptions: map[*Subscription]bool{}}
+}
+
+// Events is the bus the live ingestion of horizon publishes its events to.
+// Sinks can subscribe to it before or after horizon starts, for example from
+// the main function registering plugins.
+var Events = NewBus()
+
+// Subscription receives the events published after Subscribe, until Close.
+type Subscription struct {
+	// C receives the events, it's closed by Close.
+	C <-chan Event
+
+	bus       *Bus
+	c         chan Event
+	dropped   int64
+	closeOnce sync.Once
+}
+
+// Subscribe returns a subscription buffering up to buffer events which are
+// not received yet.
+func (b *Bus) Subscribe(buffer int) *Subscription {
+	c := make(chan Event, buffer)
+	subscription := &Subscription{C: c, bus: b, c: c}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.subscriptions[subscription] = true
+	return subscription
+}
+
+// Publish delivers events, in order, to all the subscriptions.
+func (b *Bus) Publish(events ...Event) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for subscription := range b.subscriptions {
+		for _, event := range events {
+			select {
+			case subscription.c <- event:
+			default:
+				atomic.AddInt64(&subscription.dropped, 1)
+			}
+		}
+	}
+}
+
+// Dropped returns the number of events dropped because the buffer of the
+// subscription was full.
+func (s *Subscription) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Close stops the subscription and closes C.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.bus.mutex.Lock()
+		defer s.bus.mutex.Unlock()
+		delete(s.bus.subscriptions, s)
+		close(s.c)
+	})
+}
@@ -0,0 +1,43 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	bus := NewBus()
+	// events published without subscribers are discarded
+	bus.Publish(Event{Type: LedgerIngested, Sequence: 1})
+
+	fast := bus.Subscribe(10)
+	slow := bus.Subscribe(1)
+
+	bus.Publish(
+		Event{Type: TableChanged, Sequence: 2, Table: "accounts", Created: 1},
+		Event{Type: LedgerIngested, Sequence: 2},
+	)
+
+	assert.Equal(t, Event{Type: TableChanged, Sequence: 2, Table: "accounts", Created: 1}, <-fast.C)
+	assert.Equal(t, Event{Type: LedgerIngested, Sequence: 2}, <-fast.C)
+	assert.Equal(t, int64(0), fast.Dropped())
+
+	// the events which don't fit in the buffer are dropped
+	assert.Equal(t, Event{Type: TableChanged, Sequence: 2, Table: "accounts", Created: 1}, <-slow.C)
+	assert.Equal(t, int64(1), slow.Dropped())
+
+	slow.Close()
+	slow.Close()
+	_, ok := <-slow.C
+	assert.False(t, ok)
+
+	bus.Publish(Event{Type: LedgerIngested, Sequence: 3})
+	assert.Equal(t, Event{Type: LedgerIngested, Sequence: 3}, <-fast.C)
+	fast.Close()
+}
+
+func TestEventID(t *testing.T) {
+	assert.Equal(t, "4294967296-0-accounts", EventID(1, 0, "accounts"))
+	assert.Equal(t, "12884901888-6-ledger_ingested", EventID(3, 6, LedgerIngested))
+}
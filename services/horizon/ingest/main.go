@@ -0,0 +1,99 @@
+// Package ingest is the public API for extending the horizon ingestion
+// pipeline with custom processors. A plugin registered with Register before
+// horizon starts receives the ledger entry changes and the transactions of
+// every ingested ledger and can write them to its own tables, for example:
+//
+//	package main
+//
+//	import (
+//		"github.com/stellar/go/services/horizon/cmd"
+//		"github.com/stellar/go/services/horizon/ingest"
+//	)
+//
+//	func main() {
+//		ingest.Register(ingest.Plugin{
+//			Name:                    "my_payments",
+//			NewTransactionProcessor: newPaymentsProcessor,
+//		})
+//		cmd.Execute()
+//	}
+//
+// Processors run in the ingestion transaction, their writes are committed
+// with the ledger. Ledgers can be ingested again (ex. by `horizon db reingest
+// range`), so processors must replace the data they previously wrote for a
+// ledger instead of failing or duplicating it.
+package ingest
+
+import (
+	"sync"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/xdr"
+)
+
+// ChangeProcessor receives the ledger entry changes of a ledger, or of the
+// history archive checkpoint the state is built from. Commit is called once
+// all the changes were processed.
+type ChangeProcessor interface {
+	ProcessChange(change io.Change) error
+	Commit() error
+}
+
+// TransactionProcessor receives the transactions of a ledger. Commit is
+// called once all the transactions were processed.
+type TransactionProcessor interface {
+	ProcessTransaction(transaction io.LedgerTransaction) error
+	Commit() error
+}
+
+// Plugin creates the custom processors of every ingested ledger. A new
+// processor is created for every ledger. session is the session of the
+// ingestion transaction, processors must not commit or roll it back.
+type Plugin struct {
+	// Name identifies the plugin in logs, metrics and errors.
+	Name string
+	// NewChangeProcessor, if set, returns the processor of the ledger entry
+	// changes of the ledger with the given sequence.
+	NewChangeProcessor func(sequence uint32, session *db.Session) ChangeProcessor
+	// NewTransactionProcessor, if set, returns the processor of the
+	// transactions of the ledger. Transaction processors receive all the
+	// transactions, even when ingestion filters are configured.
+	NewTransactionProcessor func(ledger xdr.LedgerHeaderHistoryEntry, session *db.Session) TransactionProcessor
+}
+
+var (
+	pluginsLock sync.RWMutex
+	plugins     []Plugin
+)
+
+// Register adds plugin to the ingestion pipeline. It must be called before
+// horizon starts, plugins registered later are ignored. Plugins run in the
+// order they are registered, after the built-in processors. It panics when
+// the plugin has no name or processors, or when the name is already
+// registered.
+func Register(plugin Plugin) {
+	pluginsLock.Lock()
+	defer pluginsLock.Unlock()
+
+	if plugin.Name == "" {
+		panic("ingest: Register plugin name is empty")
+	}
+	if plugin.NewChangeProcessor == nil && plugin.NewTransactionProcessor == nil {
+		panic("ingest: Register plugin " + plugin.Name + " has no processors")
+	}
+	for _, registered := range plugins {
+		if registered.Name == plugin.Name {
+			panic("ingest: Register called twice for plugin " + plugin.Name)
+		}
+	}
+	plugins = append(plugins, plugin)
+}
+
+// Plugins returns the registered plugins.
+func Plugins() []Plugin {
+	pluginsLock.RLock()
+	defer pluginsLock.RUnlock()
+
+	return append([]Plugin(nil), plugins...)
+}
@@ -0,0 +1,42 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	defer func() { plugins = nil }()
+
+	newTransactionProcessor := func(xdr.LedgerHeaderHistoryEntry, *db.Session) TransactionProcessor {
+		return nil
+	}
+	newChangeProcessor := func(uint32, *db.Session) ChangeProcessor {
+		return nil
+	}
+
+	Register(Plugin{Name: "b", NewTransactionProcessor: newTransactionProcessor})
+	Register(Plugin{Name: "a", NewChangeProcessor: newChangeProcessor})
+	assert.PanicsWithValue(t, "ingest: Register called twice for plugin a", func() {
+		Register(Plugin{Name: "a", NewChangeProcessor: newChangeProcessor})
+	})
+	assert.PanicsWithValue(t, "ingest: Register plugin name is empty", func() {
+		Register(Plugin{NewChangeProcessor: newChangeProcessor})
+	})
+	assert.PanicsWithValue(t, "ingest: Register plugin c has no processors", func() {
+		Register(Plugin{Name: "c"})
+	})
+
+	registered := Plugins()
+	assert.Len(t, registered, 2)
+	// plugins are kept in the order of registration
+	assert.Equal(t, "b", registered[0].Name)
+	assert.Equal(t, "a", registered[1].Name)
+
+	// the returned slice is a copy
+	registered[0].Name = "d"
+	assert.Equal(t, "b", Plugins()[0].Name)
+}
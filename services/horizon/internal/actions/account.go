@@ -134,13 +134,13 @@ func (handler GetAccountsHandler) GetResourcePage(
 		return nil, err
 	}
 
-	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	qp := AccountsQuery{}
+	err = getParams(&qp, r)
 	if err != nil {
 		return nil, err
 	}
 
-	qp := AccountsQuery{}
-	err = getParams(&qp, r)
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
 	if err != nil {
 		return nil, err
 	}
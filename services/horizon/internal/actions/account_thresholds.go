@@ -0,0 +1,58 @@
+package actions
+
+import (
+	"net/http"
+
+	"github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+)
+
+// AccountThresholdsHistoryQuery query struct for the account thresholds
+// history end-point
+type AccountThresholdsHistoryQuery struct {
+	AccountID string `schema:"account_id" valid:"accountID,required"`
+}
+
+// GetAccountThresholdsHistoryHandler is the action handler for the
+// `/accounts/{account_id}/thresholds/history` endpoint which lists the
+// changes of the master weight and thresholds of an account.
+type GetAccountThresholdsHistoryHandler struct {
+}
+
+// GetResourcePage returns a page of thresholds changes of an account.
+func (handler GetAccountThresholdsHistoryHandler) GetResourcePage(
+	w HeaderWriter,
+	r *http.Request,
+) ([]hal.Pageable, error) {
+	pq, err := GetPageQuery(r, Int64Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	qp := AccountThresholdsHistoryQuery{}
+	if err = getParams(&qp, r); err != nil {
+		return nil, err
+	}
+
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := historyQ.AccountThresholdsHistory(qp.AccountID, pq)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading account thresholds records")
+	}
+
+	var changes []hal.Pageable
+	for _, record := range records {
+		var change horizon.AccountThresholdsChange
+		resourceadapter.PopulateAccountThresholdsChange(r.Context(), &change, record)
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
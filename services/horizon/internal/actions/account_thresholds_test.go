@@ -0,0 +1,55 @@
+package actions
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func TestGetAccountThresholdsHistoryHandler(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+
+	q := &history.Q{tt.HorizonSession()}
+	handler := GetAccountThresholdsHistoryHandler{}
+
+	account := "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+	batch := q.NewAccountThresholdsBatchInsertBuilder(0)
+	tt.Assert.NoError(batch.Add(toid.New(3, 1, 1).ToInt64(), account, xdr.Thresholds{1, 1, 2, 3}))
+	tt.Assert.NoError(batch.Add(toid.New(5, 1, 1).ToInt64(), account, xdr.Thresholds{0, 2, 2, 2}))
+	tt.Assert.NoError(batch.Exec())
+
+	records, err := handler.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t,
+			map[string]string{"order": "desc"},
+			map[string]string{"account_id": account},
+			q.Session,
+		),
+	)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 2) {
+		change := records[0].(horizon.AccountThresholdsChange)
+		tt.Assert.Equal(account, change.AccountID)
+		tt.Assert.Equal(byte(0), change.MasterKeyWeight)
+		tt.Assert.Equal(horizon.AccountThresholds{LowThreshold: 2, MedThreshold: 2, HighThreshold: 2}, change.Thresholds)
+		tt.Assert.Equal(toid.New(5, 1, 1).String(), change.PT)
+	}
+
+	_, err = handler.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t,
+			map[string]string{},
+			map[string]string{"account_id": "invalid"},
+			q.Session,
+		),
+	)
+	tt.Assert.Error(err)
+}
@@ -3,6 +3,7 @@ package actions
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/stellar/go/protocols/horizon"
@@ -20,7 +21,7 @@ import (
 type AssetStatsHandler struct {
 }
 
-func (handler AssetStatsHandler) validateAssetParams(code, issuer string, pq db2.PageQuery) error {
+func (handler AssetStatsHandler) validateAssetParams(code, issuer string, sortBy history.AssetStatsSort, pq db2.PageQuery) error {
 	if code != "" {
 		if !xdr.ValidAssetCode.MatchString(code) {
 			return problem.MakeInvalidFieldProblem(
@@ -39,8 +40,35 @@ func (handler AssetStatsHandler) validateAssetParams(code, issuer string, pq db2
 		}
 	}
 
+	switch sortBy {
+	case history.AssetStatsSortByAsset, history.AssetStatsSortByHolders, history.AssetStatsSortByAmount:
+	default:
+		return problem.MakeInvalidFieldProblem(
+			"sort",
+			fmt.Errorf("%s is not a valid sort, accepted values: holders, amount", sortBy),
+		)
+	}
+
 	if pq.Cursor != "" {
-		parts := strings.SplitN(pq.Cursor, "_", 3)
+		cursor := pq.Cursor
+		if sortBy != history.AssetStatsSortByAsset {
+			parts := strings.SplitN(cursor, "_", 2)
+			if len(parts) != 2 {
+				return problem.MakeInvalidFieldProblem(
+					"cursor",
+					fmt.Errorf("cursor must start with the %s of the asset", sortBy),
+				)
+			}
+			if !sortBy.ValidCursorValue(parts[0]) {
+				return problem.MakeInvalidFieldProblem(
+					"cursor",
+					fmt.Errorf("%s is not a valid %s", parts[0], sortBy),
+				)
+			}
+			cursor = parts[1]
+		}
+
+		parts := strings.SplitN(cursor, "_", 3)
 		if len(parts) != 3 {
 			return problem.MakeInvalidFieldProblem(
 				"cursor",
@@ -75,35 +103,45 @@ func (handler AssetStatsHandler) validateAssetParams(code, issuer string, pq db2
 	return nil
 }
 
-func (handler AssetStatsHandler) findIssuersForAssets(
-	historyQ *history.Q,
-	assetStats []history.ExpAssetStat,
-) (map[string]history.AccountEntry, error) {
-	issuerSet := map[string]bool{}
-	issuers := []string{}
-	for _, assetStat := range assetStats {
-		if issuerSet[assetStat.AssetIssuer] {
+// assetStatsIssuerFlags are the query parameters filtering assets by the
+// flags of their issuer.
+var assetStatsIssuerFlags = []struct {
+	param string
+	flag  xdr.AccountFlags
+}{
+	{"auth_required", xdr.AccountFlagsAuthRequiredFlag},
+	{"auth_revocable", xdr.AccountFlagsAuthRevocableFlag},
+	{"auth_immutable", xdr.AccountFlagsAuthImmutableFlag},
+	{"auth_clawback_enabled", history.AuthClawbackEnabledFlag},
+}
+
+// setIssuerFlagFilters adds the flags of the issuer flag query parameters to
+// the issuer flags filter.
+func (handler AssetStatsHandler) setIssuerFlagFilters(r *http.Request, filter *history.AssetStatsFilter) error {
+	for _, issuerFlag := range assetStatsIssuerFlags {
+		value, err := getString(r, issuerFlag.param)
+		if err != nil {
+			return err
+		}
+		if value == "" {
 			continue
 		}
-		issuerSet[assetStat.AssetIssuer] = true
-		issuers = append(issuers, assetStat.AssetIssuer)
-	}
 
-	accountsByID := map[string]history.AccountEntry{}
-	accounts, err := historyQ.GetAccountsByIDs(issuers)
-	if err != nil {
-		return nil, err
-	}
-	for _, account := range accounts {
-		accountsByID[account.AccountID] = account
-		delete(issuerSet, account.AccountID)
+		set, err := strconv.ParseBool(value)
+		if err != nil {
+			return problem.MakeInvalidFieldProblem(
+				issuerFlag.param,
+				fmt.Errorf("%s is not a valid boolean", value),
+			)
+		}
+		describeParam(r, issuerFlag.param, value)
+		if set {
+			filter.IssuerFlagsSet |= issuerFlag.flag
+		} else {
+			filter.IssuerFlagsUnset |= issuerFlag.flag
+		}
 	}
-
-	// Note it's possible that no accounts can be found for certain issuers.
-	// That can occur because an account can be removed when there are only empty trustlines
-	// pointing to it. We still continue to serve asset stats for such issuers.
-
-	return accountsByID, nil
+	return nil
 }
 
 // GetResourcePage returns a page of offers.
@@ -123,26 +161,39 @@ func (handler AssetStatsHandler) GetResourcePage(
 		return nil, err
 	}
 
+	sort, err := getString(r, "sort")
+	if err != nil {
+		return nil, err
+	}
+	sortBy := history.AssetStatsSort(sort)
+
 	pq, err := GetPageQuery(r, DisableCursorValidation)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = handler.validateAssetParams(code, issuer, pq); err != nil {
+	if err = handler.validateAssetParams(code, issuer, sortBy, pq); err != nil {
 		return nil, err
 	}
 
-	historyQ, err := context.HistoryQFromRequest(r)
-	if err != nil {
+	filter := history.AssetStatsFilter{
+		AssetCode:   code,
+		AssetIssuer: issuer,
+		SortBy:      sortBy,
+	}
+	if err = handler.setIssuerFlagFilters(r, &filter); err != nil {
 		return nil, err
 	}
+	describeParam(r, "asset_code", code)
+	describeParam(r, "asset_issuer", issuer)
+	describeParam(r, "sort", sort)
 
-	assetStats, err := historyQ.GetAssetStats(code, issuer, pq)
+	historyQ, err := context.HistoryQFromRequest(r)
 	if err != nil {
 		return nil, err
 	}
 
-	issuerAccounts, err := handler.findIssuersForAssets(historyQ, assetStats)
+	assetStats, err := historyQ.GetAssetStats(ctx, filter, pq)
 	if err != nil {
 		return nil, err
 	}
@@ -155,8 +206,8 @@ func (handler AssetStatsHandler) GetResourcePage(
 			ctx,
 			&assetStatResponse,
 			record,
-			issuerAccounts[record.AssetIssuer],
 		)
+		assetStatResponse.PT = record.PagingTokenFor(sortBy)
 		response = append(response, assetStatResponse)
 	}
 
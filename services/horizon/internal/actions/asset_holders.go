@@ -0,0 +1,88 @@
+package actions
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+// AssetHoldersQuery query struct for the /assets/{asset}/holders endpoint
+type AssetHoldersQuery struct {
+	AssetFilter string `schema:"asset" valid:"asset"`
+}
+
+// Validate runs custom validations.
+func (q AssetHoldersQuery) Validate() error {
+	if strings.ToLower(q.AssetFilter) == "native" {
+		return problem.MakeInvalidFieldProblem(
+			"asset",
+			errors.New("native assets are not held in trust lines"),
+		)
+	}
+
+	return nil
+}
+
+// Asset returns an xdr.Asset representing the asset whose holders are listed.
+func (q AssetHoldersQuery) Asset() xdr.Asset {
+	parts := strings.Split(q.AssetFilter, ":")
+	return xdr.MustNewCreditAsset(parts[0], parts[1])
+}
+
+// GetAssetHoldersHandler is the action handler for the
+// /assets/{asset}/holders endpoint
+type GetAssetHoldersHandler struct{}
+
+// GetResourcePage returns a page of the accounts holding an asset, ordered
+// by balance.
+func (handler GetAssetHoldersHandler) GetResourcePage(
+	w HeaderWriter,
+	r *http.Request,
+) ([]hal.Pageable, error) {
+	ctx := r.Context()
+	pq, err := GetPageQuery(r, DisableCursorValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	qp := AssetHoldersQuery{}
+	if err = getParams(&qp, r); err != nil {
+		return nil, err
+	}
+
+	if pq.Cursor != "" {
+		if _, _, err = history.ParseAssetHoldersCursor(pq.Cursor); err != nil {
+			return nil, problem.MakeInvalidFieldProblem(
+				"cursor",
+				errors.New("cursor must be of the form \"<balance>_<account id>\""),
+			)
+		}
+	}
+
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := historyQ.GetAssetHolders(ctx, qp.Asset(), pq)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading asset holders")
+	}
+
+	holders := make([]hal.Pageable, 0, len(records))
+	for _, record := range records {
+		var holder horizon.AssetHolder
+		resourceadapter.PopulateAssetHolder(ctx, &holder, record)
+		holders = append(holders, holder)
+	}
+
+	return holders, nil
+}
@@ -0,0 +1,94 @@
+package actions
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+func TestGetAssetHoldersHandlerInvalidParams(t *testing.T) {
+	for _, tc := range []struct {
+		desc                 string
+		asset                string
+		cursor               string
+		expectedInvalidField string
+	}{
+		{"native asset", "native", "", "asset"},
+		{"invalid asset", "USDCOP:someissuer", "", "asset"},
+		{"invalid cursor", "USD:" + trustLineIssuer, "10000", "cursor"},
+		{"invalid cursor balance", "USD:" + trustLineIssuer, "abc_" + accountOne, "cursor"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := GetAssetHoldersHandler{}.GetResourcePage(
+				httptest.NewRecorder(),
+				makeRequest(
+					t,
+					map[string]string{"cursor": tc.cursor},
+					map[string]string{"asset": tc.asset},
+					nil,
+				),
+			)
+			if assert.IsType(t, &problem.P{}, err) {
+				p := err.(*problem.P)
+				assert.Equal(t, "bad_request", p.Type)
+				assert.Equal(t, tc.expectedInvalidField, p.Extras["invalid_field"])
+			}
+		})
+	}
+}
+
+func TestGetAssetHoldersHandler(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &history.Q{tt.HorizonSession()}
+
+	richTrustLine := usdTrustLine
+	richTrustLine.AccountId = xdr.MustAddress(accountOne)
+	richTrustLine.Balance = 20000
+	richTrustLine.Flags = 1
+
+	for _, trustLine := range []xdr.TrustLineEntry{eurTrustLine, usdTrustLine, richTrustLine} {
+		_, err := q.InsertTrustLine(trustLine, 1234)
+		tt.Assert.NoError(err)
+	}
+
+	records, err := GetAssetHoldersHandler{}.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t,
+			map[string]string{"order": "desc"},
+			map[string]string{"asset": "USD:" + trustLineIssuer},
+			q.Session,
+		),
+	)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(records, 2)
+
+	first := records[0].(protocol.AssetHolder)
+	tt.Assert.Equal(accountOne, first.AccountID)
+	tt.Assert.Equal("0.0020000", first.Balance)
+	tt.Assert.True(first.IsAuthorized)
+	tt.Assert.Equal("20000_"+accountOne, first.PagingToken())
+	tt.Assert.Equal(accountTwo, records[1].(protocol.AssetHolder).AccountID)
+
+	records, err = GetAssetHoldersHandler{}.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t,
+			map[string]string{"order": "desc", "cursor": first.PagingToken()},
+			map[string]string{"asset": "USD:" + trustLineIssuer},
+			q.Session,
+		),
+	)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(records, 1)
+	tt.Assert.Equal(accountTwo, records[0].(protocol.AssetHolder).AccountID)
+}
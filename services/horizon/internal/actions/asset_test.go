@@ -47,6 +47,32 @@ func TestAssetStatsValidation(t *testing.T) {
 			"cursor",
 			"credit_alphanum4_ is not a valid asset type",
 		},
+		{
+			"invalid sort",
+			map[string]string{
+				"sort": "trades",
+			},
+			"sort",
+			"trades is not a valid sort",
+		},
+		{
+			"sorted cursor without sort value",
+			map[string]string{
+				"sort":   "holders",
+				"cursor": "ABC_GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H_credit_alphanum4",
+			},
+			"cursor",
+			"ABC is not a valid holders",
+		},
+		{
+			"sorted cursor with a negative amount",
+			map[string]string{
+				"sort":   "amount",
+				"cursor": "-1_ABC_GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H_credit_alphanum4",
+			},
+			"cursor",
+			"-1 is not a valid amount",
+		},
 		{
 			"invalid cursor code",
 			map[string]string{
@@ -71,6 +97,14 @@ func TestAssetStatsValidation(t *testing.T) {
 			"cursor",
 			"credit_alphanum123 is not a valid asset type",
 		},
+		{
+			"invalid issuer flag",
+			map[string]string{
+				"auth_required": "yes",
+			},
+			"auth_required",
+			"yes is not a valid boolean",
+		},
 	} {
 		t.Run(testCase.name, func(t *testing.T) {
 			r := makeRequest(t, testCase.queryParams, map[string]string{}, nil)
@@ -180,11 +214,12 @@ func TestAssetStats(t *testing.T) {
 		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
 		AssetIssuer: otherIssuer.AccountID,
 		AssetCode:   "EUR",
-		Amount:      "111",
+		// larger than int64
+		Amount:      "100000000000000000000",
 		NumAccounts: 3,
 	}
 	eurAssetStatResponse := horizon.AssetStat{
-		Amount:      "0.0000111",
+		Amount:      "10000000000000.0000000",
 		NumAccounts: eurAssetStat.NumAccounts,
 		Asset: base.Asset{
 			Type:   "credit_alphanum4",
@@ -224,6 +259,8 @@ func TestAssetStats(t *testing.T) {
 		tt.Assert.NoError(err)
 		tt.Assert.NoError(batch.Exec())
 	}
+	_, err := q.UpdateAssetStatsIssuers([]string{issuer.AccountID, otherIssuer.AccountID})
+	tt.Assert.NoError(err)
 
 	for _, testCase := range []struct {
 		name        string
@@ -261,6 +298,53 @@ func TestAssetStats(t *testing.T) {
 				etherAssetStatResponse,
 			},
 		},
+		{
+			"sort by holders",
+			map[string]string{"sort": "holders"},
+			[]horizon.AssetStat{
+				withPagingToken(etherAssetStatResponse, "1_"+etherAssetStat.PagingToken()),
+				withPagingToken(otherUSDAssetStatResponse, "2_"+otherUSDAssetStat.PagingToken()),
+				withPagingToken(usdAssetStatResponse, "2_"+usdAssetStat.PagingToken()),
+				withPagingToken(eurAssetStatResponse, "3_"+eurAssetStat.PagingToken()),
+			},
+		},
+		{
+			"sort by holders with cursor",
+			map[string]string{
+				"sort":   "holders",
+				"cursor": "2_" + otherUSDAssetStat.PagingToken(),
+			},
+			[]horizon.AssetStat{
+				withPagingToken(usdAssetStatResponse, "2_"+usdAssetStat.PagingToken()),
+				withPagingToken(eurAssetStatResponse, "3_"+eurAssetStat.PagingToken()),
+			},
+		},
+		{
+			"sort by amount descending",
+			map[string]string{
+				"sort":  "amount",
+				"order": "desc",
+			},
+			[]horizon.AssetStat{
+				withPagingToken(eurAssetStatResponse, "100000000000000000000_"+eurAssetStat.PagingToken()),
+				withPagingToken(etherAssetStatResponse, "23_"+etherAssetStat.PagingToken()),
+				withPagingToken(usdAssetStatResponse, "1_"+usdAssetStat.PagingToken()),
+				withPagingToken(otherUSDAssetStatResponse, "1_"+otherUSDAssetStat.PagingToken()),
+			},
+		},
+		{
+			"sort by amount descending with cursor",
+			map[string]string{
+				"sort":   "amount",
+				"order":  "desc",
+				"cursor": eurAssetStat.PagingTokenFor(history.AssetStatsSortByAmount),
+			},
+			[]horizon.AssetStat{
+				withPagingToken(etherAssetStatResponse, "23_"+etherAssetStat.PagingToken()),
+				withPagingToken(usdAssetStatResponse, "1_"+usdAssetStat.PagingToken()),
+				withPagingToken(otherUSDAssetStatResponse, "1_"+otherUSDAssetStat.PagingToken()),
+			},
+		},
 		{
 			"filter by asset code",
 			map[string]string{
@@ -291,6 +375,27 @@ func TestAssetStats(t *testing.T) {
 				usdAssetStatResponse,
 			},
 		},
+		{
+			"filter by issuer flag",
+			map[string]string{
+				"auth_required": "true",
+			},
+			[]horizon.AssetStat{
+				etherAssetStatResponse,
+				usdAssetStatResponse,
+			},
+		},
+		{
+			"filter by unset issuer flags",
+			map[string]string{
+				"auth_required":  "false",
+				"auth_revocable": "false",
+			},
+			[]horizon.AssetStat{
+				eurAssetStatResponse,
+				otherUSDAssetStatResponse,
+			},
+		},
 		{
 			"filter produces empty set",
 			map[string]string{
@@ -325,6 +430,11 @@ func TestAssetStats(t *testing.T) {
 	}
 }
 
+func withPagingToken(assetStat horizon.AssetStat, pagingToken string) horizon.AssetStat {
+	assetStat.PT = pagingToken
+	return assetStat
+}
+
 func TestAssetStatsIssuerDoesNotExist(t *testing.T) {
 	tt := test.Start(t)
 	defer tt.Finish()
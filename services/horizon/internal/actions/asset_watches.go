@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+// AssetWatch is the admin representation of an asset watch. TriggeredLedger
+// is the ledger which exceeded the threshold, it's omitted until the
+// threshold is exceeded.
+type AssetWatch struct {
+	ID              int64   `json:"id"`
+	AssetType       string  `json:"asset_type"`
+	AssetCode       string  `json:"asset_code"`
+	AssetIssuer     string  `json:"asset_issuer"`
+	Kind            string  `json:"kind"`
+	Threshold       float64 `json:"threshold"`
+	WebhookURL      string  `json:"webhook_url"`
+	TriggeredLedger *int32  `json:"triggered_ledger,omitempty"`
+}
+
+// AssetWatches is the response of the asset watches admin end-point.
+type AssetWatches struct {
+	Watches []AssetWatch `json:"watches"`
+}
+
+// AssetWatchQuery query struct for registering an asset watch. The asset is
+// read from the asset_type, asset_code and asset_issuer parameters.
+type AssetWatchQuery struct {
+	Kind       string  `schema:"kind" valid:"-"`
+	Threshold  float64 `schema:"threshold" valid:"-"`
+	WebhookURL string  `schema:"webhook_url" valid:"-"`
+}
+
+// Validate runs custom validations on the kind and the webhook url.
+func (q AssetWatchQuery) Validate() error {
+	switch q.Kind {
+	case history.AssetWatchHolders:
+	case history.AssetWatchHolderShare:
+		if q.Threshold < 0 || q.Threshold > 1 {
+			return problem.MakeInvalidFieldProblem(
+				"threshold",
+				errors.New("holder_share threshold must be between 0 and 1"),
+			)
+		}
+	default:
+		return problem.MakeInvalidFieldProblem(
+			"kind",
+			errors.New("kind must be holders or holder_share"),
+		)
+	}
+	if q.Threshold < 0 {
+		return problem.MakeInvalidFieldProblem(
+			"threshold",
+			errors.New("threshold must not be negative"),
+		)
+	}
+
+	u, err := url.Parse(q.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return problem.MakeInvalidFieldProblem(
+			"webhook_url",
+			errors.New("webhook_url must be an absolute http or https URL"),
+		)
+	}
+
+	return nil
+}
+
+// RemoveAssetWatchQuery query struct for removing an asset watch.
+type RemoveAssetWatchQuery struct {
+	ID int64 `schema:"id" valid:"-"`
+}
+
+// AssetWatchesHandler is the admin action handler listing (GET), registering
+// (PUT) and removing (DELETE) asset watches. The thresholds of the watches
+// are evaluated after ingesting every ledger.
+type AssetWatchesHandler struct {
+	HistoryQ *history.Q
+}
+
+// GetResource applies the change requested by the method of the request and
+// returns all the asset watches.
+func (handler AssetWatchesHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	switch r.Method {
+	case http.MethodPut:
+		if err := handler.add(r); err != nil {
+			return nil, err
+		}
+	case http.MethodDelete:
+		qp := RemoveAssetWatchQuery{}
+		if err := getParams(&qp, r); err != nil {
+			return nil, err
+		}
+		if _, err := handler.HistoryQ.RemoveAssetWatch(qp.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	records, err := handler.HistoryQ.AssetWatches()
+	if err != nil {
+		return nil, err
+	}
+
+	response := AssetWatches{Watches: []AssetWatch{}}
+	for _, record := range records {
+		response.Watches = append(response.Watches, AssetWatch{
+			ID:              record.ID,
+			AssetType:       xdr.AssetTypeToString[record.AssetType],
+			AssetCode:       record.AssetCode,
+			AssetIssuer:     record.AssetIssuer,
+			Kind:            record.Kind,
+			Threshold:       record.Threshold,
+			WebhookURL:      record.WebhookURL,
+			TriggeredLedger: record.TriggeredLedger,
+		})
+	}
+
+	return response, nil
+}
+
+func (handler AssetWatchesHandler) add(r *http.Request) error {
+	qp := AssetWatchQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return err
+	}
+
+	asset, err := getAsset(r, "")
+	if err != nil {
+		return err
+	}
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		return problem.MakeInvalidFieldProblem(
+			"asset_type",
+			errors.New("native asset can not be watched"),
+		)
+	}
+
+	_, err = handler.HistoryQ.AddAssetWatch(asset, qp.Kind, qp.Threshold, qp.WebhookURL)
+	return err
+}
@@ -0,0 +1,490 @@
+package actions
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// maxOperationsPerTransaction is the maximum number of operations of a
+// transaction accepted by stellar-core.
+const maxOperationsPerTransaction = 100
+
+// Checks of the `/transactions/check` end-point.
+const (
+	checkEnvelope   = "envelope"
+	checkSignatures = "signatures"
+	checkFee        = "fee"
+	checkSequence   = "sequence"
+	checkTimeBounds = "time_bounds"
+	checkReserve    = "reserve"
+)
+
+// CheckTransactionHandler is the action handler for the `/transactions/check`
+// end-point which runs inexpensive checks on a transaction envelope, against
+// the ingested state, without submitting it.
+type CheckTransactionHandler struct {
+	NetworkPassphrase string
+}
+
+// GetResource returns the findings of the checks of the transaction.
+func (handler CheckTransactionHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	if err := (SubmitTransactionHandler{}).validateBodyType(r); err != nil {
+		return nil, err
+	}
+
+	raw, err := getString(r, "tx")
+	if err != nil {
+		return nil, err
+	}
+
+	checker := transactionChecker{now: time.Now()}
+	info, err := extractEnvelopeInfo(raw, handler.NetworkPassphrase)
+	if err != nil {
+		checker.addError(checkEnvelope, "transaction_malformed", nil,
+			"the transaction envelope is not a base64 encoded XDR TransactionEnvelope")
+		return checker.result(""), nil
+	}
+
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if err = checker.check(historyQ, info, handler.NetworkPassphrase); err != nil {
+		return nil, err
+	}
+	return checker.result(info.hash), nil
+}
+
+// transactionChecker collects the findings of the checks of a transaction.
+type transactionChecker struct {
+	now      time.Time
+	findings []horizon.TransactionCheckFinding
+
+	accounts map[string]history.AccountEntry
+	signers  map[string][]history.AccountSigner
+	ledger   history.Ledger
+}
+
+func (c *transactionChecker) add(check, severity, code string, operation *int, detail string) {
+	c.findings = append(c.findings, horizon.TransactionCheckFinding{
+		Check:     check,
+		Severity:  severity,
+		Code:      code,
+		Detail:    detail,
+		Operation: operation,
+	})
+}
+
+func (c *transactionChecker) addError(check, code string, operation *int, detail string) {
+	c.add(check, horizon.TransactionCheckError, code, operation, detail)
+}
+
+func (c *transactionChecker) addWarning(check, code string, operation *int, detail string) {
+	c.add(check, horizon.TransactionCheckWarning, code, operation, detail)
+}
+
+func (c *transactionChecker) result(hash string) horizon.TransactionCheck {
+	result := horizon.TransactionCheck{
+		Hash:     hash,
+		Valid:    true,
+		Findings: []horizon.TransactionCheckFinding{},
+	}
+	for _, finding := range c.findings {
+		if finding.Severity == horizon.TransactionCheckError {
+			result.Valid = false
+		}
+		result.Findings = append(result.Findings, finding)
+	}
+	return result
+}
+
+// check runs all the checks of the transaction of info.
+func (c *transactionChecker) check(historyQ *history.Q, info envelopeInfo, passphrase string) error {
+	envelope := info.parsed
+	operations := envelope.Operations()
+	switch {
+	case len(operations) == 0:
+		c.addError(checkEnvelope, "missing_operation", nil, "the transaction has no operations")
+	case len(operations) > maxOperationsPerTransaction:
+		c.addError(checkEnvelope, "too_many_operations", nil, fmt.Sprintf(
+			"the transaction has %d operations, the maximum is %d",
+			len(operations), maxOperationsPerTransaction,
+		))
+	}
+
+	if err := c.load(historyQ, envelope); err != nil {
+		return err
+	}
+
+	c.checkTimeBounds(envelope)
+	c.checkFee(envelope)
+	c.checkSequence(envelope)
+	if err := c.checkSignatures(envelope, passphrase); err != nil {
+		return err
+	}
+	c.checkReserve(envelope)
+	return nil
+}
+
+// load loads the accounts of the transaction, their signers and the latest
+// ledger.
+func (c *transactionChecker) load(historyQ *history.Q, envelope xdr.TransactionEnvelope) error {
+	addresses := []string{accountAddress(envelope.SourceAccount())}
+	if envelope.IsFeeBump() {
+		addresses = append(addresses, accountAddress(envelope.FeeBumpAccount()))
+	}
+	for _, op := range envelope.Operations() {
+		if op.SourceAccount != nil {
+			addresses = append(addresses, accountAddress(*op.SourceAccount))
+		}
+	}
+
+	accounts, err := historyQ.GetAccountsByIDs(addresses)
+	if err != nil {
+		return errors.Wrap(err, "loading accounts")
+	}
+	c.accounts = map[string]history.AccountEntry{}
+	for _, account := range accounts {
+		c.accounts[account.AccountID] = account
+	}
+
+	signers, err := historyQ.SignersForAccounts(addresses)
+	if err != nil {
+		return errors.Wrap(err, "loading signers")
+	}
+	c.signers = map[string][]history.AccountSigner{}
+	for _, signer := range signers {
+		c.signers[signer.Account] = append(c.signers[signer.Account], signer)
+	}
+
+	var sequence int32
+	if err = historyQ.LatestLedger(&sequence); err != nil {
+		return errors.Wrap(err, "loading latest ledger sequence")
+	}
+	if sequence == 0 {
+		return nil
+	}
+	if err = historyQ.LedgerBySequence(&c.ledger, sequence); err != nil && !historyQ.NoRows(err) {
+		return errors.Wrap(err, "loading latest ledger")
+	}
+	return nil
+}
+
+func (c *transactionChecker) checkTimeBounds(envelope xdr.TransactionEnvelope) {
+	timeBounds := envelope.TimeBounds()
+	if timeBounds == nil {
+		return
+	}
+	now := c.now.Unix()
+	if timeBounds.MaxTime != 0 && now > int64(timeBounds.MaxTime) {
+		c.addError(checkTimeBounds, "too_late", nil, "the max time of the transaction has passed")
+	}
+	if now < int64(timeBounds.MinTime) {
+		c.addWarning(checkTimeBounds, "too_early", nil, "the min time of the transaction has not been reached yet")
+	}
+}
+
+// checkFee checks the fee against the base fee of the latest ledger. The fee
+// of the inner transaction of a fee bump transaction is not checked.
+func (c *transactionChecker) checkFee(envelope xdr.TransactionEnvelope) {
+	if c.ledger.Sequence == 0 {
+		c.addWarning(checkFee, "unknown_base_fee", nil, "no ledger was ingested, the fee was not checked")
+		return
+	}
+
+	operations := int64(len(envelope.Operations()))
+	fee := int64(envelope.Fee())
+	if envelope.IsFeeBump() {
+		// the fee bump counts as an operation
+		operations++
+		fee = envelope.FeeBumpFee()
+	}
+	if minFee := int64(c.ledger.BaseFee) * operations; fee < minFee {
+		c.addError(checkFee, "insufficient_fee", nil, fmt.Sprintf(
+			"the fee is %d stroops, the minimum for %d operations is %d stroops",
+			fee, operations, minFee,
+		))
+	}
+}
+
+// checkSequence checks that the sequence number of the transaction follows
+// the sequence number of its source account.
+func (c *transactionChecker) checkSequence(envelope xdr.TransactionEnvelope) {
+	address := accountAddress(envelope.SourceAccount())
+	account, ok := c.accounts[address]
+	if !ok {
+		c.addError(checkSequence, "no_source_account", nil, fmt.Sprintf("account %s does not exist", address))
+		return
+	}
+	if expected := account.SequenceNumber + 1; envelope.SeqNum() != expected {
+		c.addError(checkSequence, "bad_seq", nil, fmt.Sprintf(
+			"the sequence number is %d, the next sequence number of the source account is %d",
+			envelope.SeqNum(), expected,
+		))
+	}
+}
+
+// operationThreshold returns the threshold of the source account of op
+// needed to authorize it.
+func operationThreshold(account history.AccountEntry, op xdr.Operation) byte {
+	switch op.Body.Type {
+	case xdr.OperationTypeAllowTrust, xdr.OperationTypeBumpSequence, xdr.OperationTypeInflation:
+		return account.ThresholdLow
+	case xdr.OperationTypeAccountMerge:
+		return account.ThresholdHigh
+	case xdr.OperationTypeSetOptions:
+		options := op.Body.MustSetOptionsOp()
+		if options.MasterWeight != nil || options.LowThreshold != nil ||
+			options.MedThreshold != nil || options.HighThreshold != nil || options.Signer != nil {
+			return account.ThresholdHigh
+		}
+	}
+	return account.ThresholdMedium
+}
+
+// signatureChecker sums the weights of the signers of the accounts which
+// signed a transaction hash.
+type signatureChecker struct {
+	hash       [32]byte
+	signatures []xdr.DecoratedSignature
+	used       []bool
+}
+
+func newSignatureChecker(hash [32]byte, signatures []xdr.DecoratedSignature) *signatureChecker {
+	return &signatureChecker{
+		hash:       hash,
+		signatures: signatures,
+		used:       make([]bool, len(signatures)),
+	}
+}
+
+// weight returns the total weight of the signers which signed the hash,
+// a weight above 255 is 255.
+func (s *signatureChecker) weight(signers []history.AccountSigner) int32 {
+	var weight int32
+	for _, signer := range signers {
+		if s.signed(signer.Signer) {
+			weight += signer.Weight
+		}
+	}
+	if weight > 255 {
+		weight = 255
+	}
+	return weight
+}
+
+// signed returns true if signer signed the hash, the matching signatures are
+// marked as used.
+func (s *signatureChecker) signed(signer string) bool {
+	version, key, err := strkey.DecodeAny(signer)
+	if err != nil {
+		return false
+	}
+
+	switch version {
+	case strkey.VersionByteHashTx:
+		return bytes.Equal(key, s.hash[:])
+	case strkey.VersionByteHashX:
+		for i, signature := range s.signatures {
+			preimageHash := sha256.Sum256(signature.Signature)
+			if bytes.Equal(signature.Hint[:], key[len(key)-4:]) && bytes.Equal(preimageHash[:], key) {
+				s.used[i] = true
+				return true
+			}
+		}
+	case strkey.VersionByteAccountID:
+		kp, err := keypair.ParseAddress(signer)
+		if err != nil {
+			return false
+		}
+		for i, signature := range s.signatures {
+			if signature.Hint == kp.Hint() && kp.Verify(s.hash[:], signature.Signature) == nil {
+				s.used[i] = true
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *signatureChecker) unused() int {
+	unused := 0
+	for _, used := range s.used {
+		if !used {
+			unused++
+		}
+	}
+	return unused
+}
+
+// checkSignatures verifies the signatures of the transaction against the
+// current signers and thresholds of its accounts.
+func (c *transactionChecker) checkSignatures(envelope xdr.TransactionEnvelope, passphrase string) error {
+	var innerHash [32]byte
+	var err error
+	if envelope.IsFeeBump() {
+		innerHash, err = network.HashTransaction(envelope.FeeBump.Tx.InnerTx.V1.Tx, passphrase)
+	} else {
+		innerHash, err = network.HashTransactionInEnvelope(envelope, passphrase)
+	}
+	if err != nil {
+		return errors.Wrap(err, "hashing transaction")
+	}
+
+	complete := true
+	source := accountAddress(envelope.SourceAccount())
+	signatures := newSignatureChecker(innerHash, envelope.Signatures())
+	authorize := func(address string, threshold byte, operation *int) {
+		if _, ok := c.accounts[address]; !ok {
+			complete = false
+			// a missing source account is reported by the sequence check
+			if address != source {
+				c.addError(checkSignatures, "no_account", operation, fmt.Sprintf("account %s does not exist", address))
+			}
+			return
+		}
+		if weight := signatures.weight(c.signers[address]); weight == 0 || weight < int32(threshold) {
+			c.addError(checkSignatures, "bad_auth", operation, fmt.Sprintf(
+				"the signers of account %s have a weight of %d, the threshold is %d",
+				address, weight, threshold,
+			))
+		}
+	}
+
+	authorize(source, c.accounts[source].ThresholdLow, nil)
+	for i, op := range envelope.Operations() {
+		address := source
+		if op.SourceAccount != nil {
+			address = accountAddress(*op.SourceAccount)
+		}
+		index := i
+		authorize(address, operationThreshold(c.accounts[address], op), &index)
+	}
+	if unused := signatures.unused(); complete && unused > 0 {
+		c.addError(checkSignatures, "bad_auth_extra", nil, fmt.Sprintf("%d signatures are not needed", unused))
+	}
+
+	if !envelope.IsFeeBump() {
+		return nil
+	}
+	outerHash, err := network.HashTransactionInEnvelope(envelope, passphrase)
+	if err != nil {
+		return errors.Wrap(err, "hashing fee bump transaction")
+	}
+	feeBumpSignatures := newSignatureChecker(outerHash, envelope.FeeBumpSignatures())
+	feeSource := accountAddress(envelope.FeeBumpAccount())
+	if account, ok := c.accounts[feeSource]; !ok {
+		c.addError(checkSignatures, "no_fee_account", nil, fmt.Sprintf("account %s does not exist", feeSource))
+	} else if weight := feeBumpSignatures.weight(c.signers[feeSource]); weight == 0 || weight < int32(account.ThresholdLow) {
+		c.addError(checkSignatures, "bad_auth", nil, fmt.Sprintf(
+			"the signers of fee account %s have a weight of %d, the threshold is %d",
+			feeSource, weight, account.ThresholdLow,
+		))
+	} else if unused := feeBumpSignatures.unused(); unused > 0 {
+		c.addError(checkSignatures, "bad_auth_extra", nil, fmt.Sprintf("%d fee bump signatures are not needed", unused))
+	}
+	return nil
+}
+
+// checkReserve estimates if the balance of the source account covers its
+// minimum balance after the transaction: the lumens it sends, the fee it pays
+// and the reserve of the sub-entries its operations may create.
+func (c *transactionChecker) checkReserve(envelope xdr.TransactionEnvelope) {
+	source := accountAddress(envelope.SourceAccount())
+	account, ok := c.accounts[source]
+	if !ok || c.ledger.Sequence == 0 {
+		return
+	}
+
+	spent := int64(0)
+	if !envelope.IsFeeBump() {
+		spent += int64(envelope.Fee())
+	}
+	subEntries := int64(account.NumSubEntries)
+	for _, op := range envelope.Operations() {
+		if op.SourceAccount != nil && accountAddress(*op.SourceAccount) != source {
+			continue
+		}
+		spent += nativeAmountSent(op)
+		if createsSubEntry(op) {
+			subEntries++
+		}
+	}
+
+	baseReserve := int64(c.ledger.BaseReserve)
+	minBalance := (2 + subEntries) * baseReserve
+	if available := account.Balance - account.SellingLiabilities - spent; available < minBalance {
+		c.addWarning(checkReserve, "low_reserve", nil, fmt.Sprintf(
+			"the source account would have %s XLM available for a minimum balance of %s XLM",
+			amount.StringFromInt64(available), amount.StringFromInt64(minBalance),
+		))
+	}
+}
+
+// nativeAmountSent returns the maximum amount of lumens sent by the source
+// account of op.
+func nativeAmountSent(op xdr.Operation) int64 {
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		return int64(op.Body.MustCreateAccountOp().StartingBalance)
+	case xdr.OperationTypePayment:
+		payment := op.Body.MustPaymentOp()
+		if payment.Asset.Type == xdr.AssetTypeAssetTypeNative {
+			return int64(payment.Amount)
+		}
+	case xdr.OperationTypePathPaymentStrictReceive:
+		payment := op.Body.MustPathPaymentStrictReceiveOp()
+		if payment.SendAsset.Type == xdr.AssetTypeAssetTypeNative {
+			return int64(payment.SendMax)
+		}
+	case xdr.OperationTypePathPaymentStrictSend:
+		payment := op.Body.MustPathPaymentStrictSendOp()
+		if payment.SendAsset.Type == xdr.AssetTypeAssetTypeNative {
+			return int64(payment.SendAmount)
+		}
+	}
+	return 0
+}
+
+// createsSubEntry returns true if op may create a sub-entry of its source
+// account.
+func createsSubEntry(op xdr.Operation) bool {
+	switch op.Body.Type {
+	case xdr.OperationTypeManageSellOffer:
+		offer := op.Body.MustManageSellOfferOp()
+		return offer.OfferId == 0 && offer.Amount > 0
+	case xdr.OperationTypeManageBuyOffer:
+		offer := op.Body.MustManageBuyOfferOp()
+		return offer.OfferId == 0 && offer.BuyAmount > 0
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		return true
+	case xdr.OperationTypeChangeTrust:
+		return op.Body.MustChangeTrustOp().Limit > 0
+	case xdr.OperationTypeManageData:
+		return op.Body.MustManageDataOp().DataValue != nil
+	case xdr.OperationTypeSetOptions:
+		signer := op.Body.MustSetOptionsOp().Signer
+		return signer != nil && signer.Weight > 0
+	}
+	return false
+}
+
+// accountAddress returns the G-address of the account underlying account, the
+// state tables don't store muxed accounts.
+func accountAddress(account xdr.MuxedAccount) string {
+	accountID := account.ToAccountId()
+	return accountID.Address()
+}
@@ -0,0 +1,213 @@
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+func checkedEnvelope(source *keypair.Full, seq int64, fee uint32, ops ...xdr.Operation) xdr.TransactionEnvelope {
+	return xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{
+			Tx: xdr.Transaction{
+				SourceAccount: xdr.MustMuxedAddress(source.Address()),
+				Fee:           xdr.Uint32(fee),
+				SeqNum:        xdr.SequenceNumber(seq),
+				Operations:    ops,
+			},
+		},
+	}
+}
+
+func signEnvelope(t *testing.T, envelope *xdr.TransactionEnvelope, signers ...*keypair.Full) {
+	hash, err := network.HashTransactionInEnvelope(*envelope, network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	for _, signer := range signers {
+		signature, err := signer.SignDecorated(hash[:])
+		assert.NoError(t, err)
+		envelope.V1.Signatures = append(envelope.V1.Signatures, signature)
+	}
+}
+
+func paymentOp(amount xdr.Int64) xdr.Operation {
+	return xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePayment,
+			PaymentOp: &xdr.PaymentOp{
+				Destination: xdr.MustMuxedAddress("GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU"),
+				Asset:       xdr.MustNewNativeAsset(),
+				Amount:      amount,
+			},
+		},
+	}
+}
+
+func newTransactionChecker(accounts ...history.AccountEntry) *transactionChecker {
+	checker := &transactionChecker{
+		now:      time.Unix(1000, 0),
+		accounts: map[string]history.AccountEntry{},
+		signers:  map[string][]history.AccountSigner{},
+		ledger:   history.Ledger{Sequence: 10, BaseFee: 100, BaseReserve: 5000000},
+	}
+	for _, account := range accounts {
+		checker.accounts[account.AccountID] = account
+		checker.signers[account.AccountID] = []history.AccountSigner{{
+			Account: account.AccountID,
+			Signer:  account.AccountID,
+			Weight:  int32(account.MasterWeight),
+		}}
+	}
+	return checker
+}
+
+func findingCodes(checker *transactionChecker) []string {
+	codes := []string{}
+	for _, finding := range checker.findings {
+		codes = append(codes, finding.Check+":"+finding.Code)
+	}
+	return codes
+}
+
+func TestCheckTransactionValid(t *testing.T) {
+	source := keypair.MustRandom()
+	checker := newTransactionChecker(history.AccountEntry{
+		AccountID:       source.Address(),
+		Balance:         100000000,
+		SequenceNumber:  41,
+		MasterWeight:    1,
+		ThresholdMedium: 1,
+	})
+
+	envelope := checkedEnvelope(source, 42, 100, paymentOp(10000000))
+	signEnvelope(t, &envelope, source)
+
+	assert.NoError(t, checker.checkSignatures(envelope, network.TestNetworkPassphrase))
+	checker.checkFee(envelope)
+	checker.checkSequence(envelope)
+	checker.checkTimeBounds(envelope)
+	checker.checkReserve(envelope)
+	assert.Empty(t, checker.findings)
+	assert.True(t, checker.result("").Valid)
+}
+
+func TestCheckTransactionMuxedSource(t *testing.T) {
+	source := keypair.MustRandom()
+	checker := newTransactionChecker(history.AccountEntry{
+		AccountID:       source.Address(),
+		Balance:         100000000,
+		SequenceNumber:  41,
+		MasterWeight:    1,
+		ThresholdMedium: 1,
+	})
+
+	op := paymentOp(10000000)
+	op.SourceAccount = &xdr.MuxedAccount{
+		Type: xdr.CryptoKeyTypeKeyTypeMuxedEd25519,
+		Med25519: &xdr.MuxedAccountMed25519{
+			Id:      2,
+			Ed25519: xdr.MustAddress(source.Address()).MustEd25519(),
+		},
+	}
+	envelope := checkedEnvelope(source, 42, 100, op)
+	envelope.V1.Tx.SourceAccount = xdr.MuxedAccount{
+		Type: xdr.CryptoKeyTypeKeyTypeMuxedEd25519,
+		Med25519: &xdr.MuxedAccountMed25519{
+			Id:      1,
+			Ed25519: xdr.MustAddress(source.Address()).MustEd25519(),
+		},
+	}
+	signEnvelope(t, &envelope, source)
+
+	assert.NoError(t, checker.checkSignatures(envelope, network.TestNetworkPassphrase))
+	checker.checkSequence(envelope)
+	checker.checkReserve(envelope)
+	assert.Empty(t, checker.findings)
+}
+
+func TestCheckTransactionFindings(t *testing.T) {
+	source, other := keypair.MustRandom(), keypair.MustRandom()
+	checker := newTransactionChecker(history.AccountEntry{
+		AccountID:       source.Address(),
+		Balance:         100000000,
+		SequenceNumber:  42,
+		MasterWeight:    1,
+		ThresholdMedium: 2,
+	})
+
+	envelope := checkedEnvelope(source, 42, 100, paymentOp(95000000), paymentOp(1))
+	envelope.V1.Tx.TimeBounds = &xdr.TimeBounds{MaxTime: 999}
+	signEnvelope(t, &envelope, source, other)
+
+	assert.NoError(t, checker.checkSignatures(envelope, network.TestNetworkPassphrase))
+	checker.checkFee(envelope)
+	checker.checkSequence(envelope)
+	checker.checkTimeBounds(envelope)
+	checker.checkReserve(envelope)
+	assert.Equal(t, []string{
+		"signatures:bad_auth",
+		"signatures:bad_auth",
+		"signatures:bad_auth_extra",
+		"fee:insufficient_fee",
+		"sequence:bad_seq",
+		"time_bounds:too_late",
+		"reserve:low_reserve",
+	}, findingCodes(checker))
+
+	result := checker.result("")
+	assert.False(t, result.Valid)
+	assert.Equal(t, 0, *result.Findings[0].Operation)
+	assert.Equal(t, horizon.TransactionCheckWarning, result.Findings[6].Severity)
+}
+
+func TestCheckTransactionMissingSource(t *testing.T) {
+	source := keypair.MustRandom()
+	checker := newTransactionChecker()
+
+	envelope := checkedEnvelope(source, 1, 100, paymentOp(1))
+	signEnvelope(t, &envelope, source)
+
+	assert.NoError(t, checker.checkSignatures(envelope, network.TestNetworkPassphrase))
+	checker.checkSequence(envelope)
+	checker.checkReserve(envelope)
+	assert.Equal(t, []string{"sequence:no_source_account"}, findingCodes(checker))
+}
+
+func TestSignatureCheckerSigners(t *testing.T) {
+	signer := keypair.MustRandom()
+	hash := [32]byte{1, 2, 3}
+	signature, err := signer.SignDecorated(hash[:])
+	assert.NoError(t, err)
+
+	checker := newSignatureChecker(hash, []xdr.DecoratedSignature{signature})
+	assert.Equal(t, int32(255), checker.weight([]history.AccountSigner{
+		{Signer: signer.Address(), Weight: 200},
+		{Signer: keypair.MustRandom().Address(), Weight: 10},
+		{Signer: strkey.MustEncode(strkey.VersionByteHashTx, hash[:]), Weight: 100},
+	}))
+	assert.Equal(t, 0, checker.unused())
+}
+
+func TestOperationThreshold(t *testing.T) {
+	account := history.AccountEntry{ThresholdLow: 1, ThresholdMedium: 2, ThresholdHigh: 3}
+	weight := xdr.Uint32(1)
+	for _, testCase := range []struct {
+		body     xdr.OperationBody
+		expected byte
+	}{
+		{xdr.OperationBody{Type: xdr.OperationTypeBumpSequence, BumpSequenceOp: &xdr.BumpSequenceOp{}}, 1},
+		{paymentOp(1).Body, 2},
+		{xdr.OperationBody{Type: xdr.OperationTypeSetOptions, SetOptionsOp: &xdr.SetOptionsOp{}}, 2},
+		{xdr.OperationBody{Type: xdr.OperationTypeSetOptions, SetOptionsOp: &xdr.SetOptionsOp{MasterWeight: &weight}}, 3},
+	} {
+		assert.Equal(t, testCase.expected, operationThreshold(account, xdr.Operation{Body: testCase.body}))
+	}
+}
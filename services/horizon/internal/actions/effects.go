@@ -2,22 +2,49 @@ package actions
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/stellar/go/services/horizon/internal/context"
-	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/resourceadapter"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/hal"
 	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/support/time"
 )
 
 // EffectsQuery query struct for effects end-points
 type EffectsQuery struct {
-	AccountID   string `schema:"account_id" valid:"accountID,optional"`
-	OperationID uint64 `schema:"op_id" valid:"-"`
-	TxHash      string `schema:"tx_id" valid:"transactionHash,optional"`
-	LedgerID    uint32 `schema:"ledger_id" valid:"-"`
+	Joinable    `valid:"optional"`
+	AccountID   string      `schema:"account_id" valid:"accountID,optional"`
+	OperationID uint64      `schema:"op_id" valid:"-"`
+	TxHash      string      `schema:"tx_id" valid:"transactionHash,optional"`
+	LedgerID    uint32      `schema:"ledger_id" valid:"-"`
+	Types       string      `schema:"type" valid:"-"`
+	StartTime   time.Millis `schema:"start_time" valid:"-"`
+	EndTime     time.Millis `schema:"end_time" valid:"-"`
+}
+
+// EffectTypes returns the effect types listed in the comma separated `type`
+// parameter.
+func (qp EffectsQuery) EffectTypes() ([]history.EffectType, error) {
+	if qp.Types == "" {
+		return nil, nil
+	}
+
+	var types []history.EffectType
+	for _, name := range strings.Split(qp.Types, ",") {
+		effectType, ok := effectTypesByName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, problem.MakeInvalidFieldProblem(
+				"type",
+				errors.Errorf("Unknown effect type: %s", name),
+			)
+		}
+		types = append(types, effectType)
+	}
+
+	return types, nil
 }
 
 // Validate runs extra validations on query parameters
@@ -39,9 +66,25 @@ func (qp EffectsQuery) Validate() error {
 			errors.New("Use a single filter for effects, you can only use one of account_id, op_id, tx_id or ledger_id"),
 		)
 	}
+
+	if err := validateTimeRange(qp.StartTime, qp.EndTime); err != nil {
+		return err
+	}
+
+	if _, err := qp.EffectTypes(); err != nil {
+		return err
+	}
 	return nil
 }
 
+var effectTypesByName = func() map[string]history.EffectType {
+	types := map[string]history.EffectType{}
+	for effectType, name := range resourceadapter.EffectTypeNames {
+		types[name] = effectType
+	}
+	return types
+}()
+
 type GetEffectsHandler struct{}
 
 func (handler GetEffectsHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
@@ -61,12 +104,25 @@ func (handler GetEffectsHandler) GetResourcePage(w HeaderWriter, r *http.Request
 		return nil, err
 	}
 
+	types, err := qp.EffectTypes()
+	if err != nil {
+		return nil, err
+	}
+
 	historyQ, err := context.HistoryQFromRequest(r)
 	if err != nil {
 		return nil, err
 	}
 
-	records, err := loadEffectRecords(historyQ, qp.AccountID, int64(qp.OperationID), qp.TxHash, qp.LedgerID, pq)
+	records, err := historyQ.GetEffects(r.Context(), history.EffectsFilter{
+		AccountID:       qp.AccountID,
+		LedgerSequence:  int32(qp.LedgerID),
+		OperationID:     int64(qp.OperationID),
+		TransactionHash: qp.TxHash,
+		Types:           types,
+		StartTime:       qp.StartTime,
+		EndTime:         qp.EndTime,
+	}, pq)
 	if err != nil {
 		return nil, errors.Wrap(err, "loading transaction records")
 	}
@@ -76,9 +132,30 @@ func (handler GetEffectsHandler) GetResourcePage(w HeaderWriter, r *http.Request
 		return nil, errors.Wrap(err, "loading ledgers")
 	}
 
+	var transactions map[int64]history.Transaction
+	if qp.IncludeTransactions() {
+		operationIDs := make([]int64, 0, len(records))
+		for _, record := range records {
+			operationIDs = append(operationIDs, record.HistoryOperationID)
+		}
+		transactions, err = loadJoinedTransactions(historyQ, operationIDs)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading transactions")
+		}
+	}
+
 	var result []hal.Pageable
 	for _, record := range records {
-		effect, err := resourceadapter.NewEffect(r.Context(), record, ledgers[record.LedgerSequence()])
+		var transaction *history.Transaction
+		if qp.IncludeTransactions() {
+			tx, ok := transactions[transactionIDForOperation(record.HistoryOperationID)]
+			if !ok {
+				return nil, errors.Errorf("could not find transaction for effect %s", record.ID())
+			}
+			transaction = &tx
+		}
+
+		effect, err := resourceadapter.NewEffect(r.Context(), record, ledgers[record.LedgerSequence()], transaction)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create effect")
 		}
@@ -88,27 +165,6 @@ func (handler GetEffectsHandler) GetResourcePage(w HeaderWriter, r *http.Request
 	return result, nil
 }
 
-func loadEffectRecords(hq *history.Q, accountID string, operationID int64, transactionHash string, ledgerID uint32,
-	pq db2.PageQuery) ([]history.Effect, error) {
-	effects := hq.Effects()
-
-	switch {
-	case accountID != "":
-		effects.ForAccount(accountID)
-	case ledgerID > 0:
-		effects.ForLedger(int32(ledgerID))
-	case operationID > 0:
-		effects.ForOperation(operationID)
-	case transactionHash != "":
-		effects.ForTransaction(transactionHash)
-	}
-
-	var result []history.Effect
-	err := effects.Page(pq).Select(&result)
-
-	return result, err
-}
-
 func loadEffectLedgers(hq *history.Q, effects []history.Effect) (map[int32]history.Ledger, error) {
 	ledgers := &history.LedgerCache{}
 
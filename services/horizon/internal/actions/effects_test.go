@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/support/http/httptest"
 	"github.com/stellar/go/support/render/problem"
 )
@@ -36,3 +37,51 @@ func TestEffectsQuery_BadOperationID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, called)
 }
+
+func TestEffectsQuery_Types(t *testing.T) {
+	qp := EffectsQuery{Types: "trade, account_credited"}
+	types, err := qp.EffectTypes()
+	assert.NoError(t, err)
+	assert.Equal(t, []history.EffectType{history.EffectTrade, history.EffectAccountCredited}, types)
+
+	qp = EffectsQuery{}
+	types, err = qp.EffectTypes()
+	assert.NoError(t, err)
+	assert.Empty(t, types)
+
+	qp = EffectsQuery{Types: "trade,foo"}
+	err = qp.Validate()
+	p, ok := err.(*problem.P)
+	if assert.True(t, ok) {
+		assert.Equal(t, 400, p.Status)
+		assert.Equal(t, "type", p.Extras["invalid_field"])
+		assert.Equal(t, "Unknown effect type: foo", p.Extras["reason"])
+	}
+}
+
+func TestEffectsQuery_TimeRange(t *testing.T) {
+	qp := EffectsQuery{StartTime: 1000, EndTime: 2000}
+	assert.NoError(t, qp.Validate())
+
+	qp = EffectsQuery{AccountID: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", StartTime: 1000}
+	assert.NoError(t, qp.Validate())
+
+	for _, testCase := range []struct {
+		qp     EffectsQuery
+		field  string
+		reason string
+	}{
+		{EffectsQuery{StartTime: -1}, "start_time", "Start time must be a positive number of milliseconds since epoch"},
+		{EffectsQuery{EndTime: -1}, "end_time", "End time must be a positive number of milliseconds since epoch"},
+		{EffectsQuery{StartTime: 2000, EndTime: 2000}, "end_time", "End time must be greater than start time"},
+		{EffectsQuery{StartTime: 2000, EndTime: 1000}, "end_time", "End time must be greater than start time"},
+	} {
+		err := testCase.qp.Validate()
+		p, ok := err.(*problem.P)
+		if assert.True(t, ok) {
+			assert.Equal(t, 400, p.Status)
+			assert.Equal(t, testCase.field, p.Extras["invalid_field"])
+			assert.Equal(t, testCase.reason, p.Extras["reason"])
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/stellar/go/services/horizon/internal/toid"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/support/time"
 	"github.com/stellar/go/xdr"
 )
 
@@ -33,10 +34,21 @@ const (
 	ParamCursor = "cursor"
 	// ParamOrder is a query string param name
 	ParamOrder = "order"
+	// ParamStopCursor is a query string param name
+	ParamStopCursor = "stop_cursor"
 	// ParamLimit is a query string param name
 	ParamLimit = "limit"
 	// LastLedgerHeaderName is the header which is set on all endpoints
 	LastLedgerHeaderName = "Latest-Ledger"
+	// InstanceHeaderName is the header with the id of the horizon instance
+	// which served the request
+	InstanceHeaderName = "X-Horizon-Instance"
+	// LedgerWatermarkHeaderName is the header with the latest ledger
+	// ingested into the history database of the instance
+	LedgerWatermarkHeaderName = "X-Horizon-Ledger-Watermark"
+	// MinLedgerHeaderName is the request header with the lowest ledger
+	// watermark an instance must have reached to serve the request
+	MinLedgerHeaderName = "X-Horizon-Min-Ledger"
 )
 
 type Opt int
@@ -44,6 +56,12 @@ type Opt int
 const (
 	// DisableCursorValidation disables cursor validation in GetPageQuery
 	DisableCursorValidation Opt = iota
+	// Int64Cursor makes GetPageQuery accept only cursors which are a single
+	// int64 (ex. ids of transactions) instead of a pair of int64s
+	Int64Cursor
+	// AllowStopCursor makes GetPageQuery accept the stop_cursor parameter,
+	// only the end-points whose queries are bounded by it must use it
+	AllowStopCursor
 )
 
 // HeaderWriter is an interface for setting HTTP response headers
@@ -179,12 +197,20 @@ func getLimit(r *http.Request, name string, def uint64, max uint64) (uint64, err
 }
 
 // GetPageQuery is a helper that returns a new db.PageQuery struct initialized
-// using the results from a call to GetPagingParams()
+// using the results from a call to GetPagingParams(). The limit is checked
+// against the page limit of the route group of the request.
 func GetPageQuery(r *http.Request, opts ...Opt) (db2.PageQuery, error) {
 	disableCursorValidation := false
+	allowStopCursor := false
+	cursorFormat := db2.CursorFormatInt64Pair
 	for _, opt := range opts {
-		if opt == DisableCursorValidation {
+		switch opt {
+		case DisableCursorValidation:
 			disableCursorValidation = true
+		case Int64Cursor:
+			cursorFormat = db2.CursorFormatInt64
+		case AllowStopCursor:
+			allowStopCursor = true
 		}
 	}
 
@@ -196,12 +222,13 @@ func GetPageQuery(r *http.Request, opts ...Opt) (db2.PageQuery, error) {
 	if err != nil {
 		return db2.PageQuery{}, err
 	}
-	limit, err := getLimit(r, ParamLimit, db2.DefaultPageSize, db2.MaxPageSize)
+	pageLimit := getPageLimit(r)
+	limit, err := getLimit(r, ParamLimit, pageLimit.Default, pageLimit.Max)
 	if err != nil {
 		return db2.PageQuery{}, err
 	}
 
-	pageQuery, err := db2.NewPageQuery(cursor, !disableCursorValidation, order, limit)
+	pageQuery, err := db2.NewPageQueryWithMaxLimit(cursor, false, order, limit, pageLimit.Max)
 	if err != nil {
 		if invalidFieldError, ok := err.(*db2.InvalidFieldError); ok {
 			err = problem.MakeInvalidFieldProblem(
@@ -215,9 +242,46 @@ func GetPageQuery(r *http.Request, opts ...Opt) (db2.PageQuery, error) {
 		return db2.PageQuery{}, err
 	}
 
+	pageQuery.StopCursor, err = getString(r, ParamStopCursor)
+	if err != nil {
+		return db2.PageQuery{}, err
+	}
+	if pageQuery.StopCursor != "" && !allowStopCursor {
+		return db2.PageQuery{}, problem.MakeInvalidFieldProblem(
+			ParamStopCursor,
+			errors.New("stop_cursor is not supported by this endpoint"),
+		)
+	}
+
+	if !disableCursorValidation {
+		if err = validateCursor(ParamCursor, pageQuery.Cursor, cursorFormat); err != nil {
+			return db2.PageQuery{}, err
+		}
+		if err = validateCursor(ParamStopCursor, pageQuery.StopCursor, cursorFormat); err != nil {
+			return db2.PageQuery{}, err
+		}
+	}
+
+	describePageQuery(r, pageQuery)
 	return pageQuery, nil
 }
 
+// validateCursor returns an invalid field problem describing why the cursor
+// in the given parameter doesn't match the format.
+func validateCursor(name, cursor string, format db2.CursorFormat) error {
+	err := db2.ValidateCursor(cursor, format)
+	if err == nil {
+		return nil
+	}
+	if invalidCursorError, ok := err.(*db2.InvalidCursorError); ok {
+		return problem.MakeInvalidFieldProblem(
+			name,
+			errors.New(invalidCursorError.Reason),
+		)
+	}
+	return err
+}
+
 // GetTransactionID retireves a transaction identifier by attempting to decode an hex-encoded,
 // 64-digit lowercase string at the provided name.
 func GetTransactionID(r *http.Request, name string) (string, error) {
@@ -422,6 +486,7 @@ func getParams(dst interface{}, r *http.Request) error {
 		}
 	}
 
+	describeParams(r, dst)
 	return nil
 }
 
@@ -569,6 +634,33 @@ func validateCursorWithinHistory(pq db2.PageQuery) error {
 	return nil
 }
 
+// validateTimeRange checks the start_time and end_time query parameters
+// used to filter records by the close time of their ledger.
+func validateTimeRange(start, end time.Millis) error {
+	if start < 0 {
+		return problem.MakeInvalidFieldProblem(
+			"start_time",
+			errors.New("Start time must be a positive number of milliseconds since epoch"),
+		)
+	}
+
+	if end < 0 {
+		return problem.MakeInvalidFieldProblem(
+			"end_time",
+			errors.New("End time must be a positive number of milliseconds since epoch"),
+		)
+	}
+
+	if !start.IsNil() && !end.IsNil() && end <= start {
+		return problem.MakeInvalidFieldProblem(
+			"end_time",
+			errors.New("End time must be greater than start time"),
+		)
+	}
+
+	return nil
+}
+
 func countNonEmpty(params ...interface{}) (int, error) {
 	count := 0
 
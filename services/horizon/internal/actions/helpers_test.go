@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"testing"
+	"testing/quick"
 
 	"github.com/go-chi/chi"
 	"github.com/stretchr/testify/assert"
@@ -284,6 +286,167 @@ func TestGetPageQuery(t *testing.T) {
 	r = makeTestActionRequest("/?limit=0", nil)
 	_, err = GetPageQuery(r)
 	tt.Assert.Error(err)
+
+	r = makeTestActionRequest("/?order=desc&stop_cursor=1231-4456", nil)
+	pq, err = GetPageQuery(r, AllowStopCursor)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("1231-4456", pq.StopCursor)
+
+	r = makeTestActionRequest("/?order=desc&stop_cursor=foo", nil)
+	_, err = GetPageQuery(r, AllowStopCursor)
+	if tt.Assert.IsType(&problem.P{}, err) {
+		tt.Assert.Equal("stop_cursor", err.(*problem.P).Extras["invalid_field"])
+	}
+
+	// end-points which are not bounded by stop_cursor reject it
+	r = makeTestActionRequest("/?order=desc&stop_cursor=1231-4456", nil)
+	_, err = GetPageQuery(r)
+	if tt.Assert.IsType(&problem.P{}, err) {
+		tt.Assert.Equal("stop_cursor", err.(*problem.P).Extras["invalid_field"])
+		tt.Assert.Equal("stop_cursor is not supported by this endpoint", err.(*problem.P).Extras["reason"])
+	}
+}
+
+func TestGetPageQueryPageLimits(t *testing.T) {
+	makeRequest := func(query, pattern string, limits PageLimits) *http.Request {
+		r := makeTestActionRequest("/?"+query, nil)
+		chi.RouteContext(r.Context()).RoutePatterns = []string{pattern}
+		if limits != nil {
+			r = r.WithContext(WithPageLimits(r.Context(), limits))
+		}
+		return r
+	}
+	limits := PageLimits{"trades": {Default: 50, Max: 500}}
+
+	pq, err := GetPageQuery(makeRequest("", "/accounts/{account_id:\\w+}/trades", limits))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50), pq.Limit)
+
+	pq, err = GetPageQuery(makeRequest("limit=500", "/trades", limits))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(500), pq.Limit)
+
+	_, err = GetPageQuery(makeRequest("limit=501", "/trades", limits))
+	assert.Error(t, err)
+
+	// the other route groups keep their default limits
+	pq, err = GetPageQuery(makeRequest("", "/effects", limits))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(db2.DefaultPageSize), pq.Limit)
+
+	_, err = GetPageQuery(makeRequest("limit=201", "/effects", limits))
+	assert.Error(t, err)
+
+	_, err = GetPageQuery(makeRequest("limit=500", "/trades", nil))
+	assert.Error(t, err)
+
+	assert.Equal(t, PageLimit{Default: 20, Max: 200}, getPageLimit(makeRequest("", "/order_book", nil)))
+}
+
+func TestRouteGroup(t *testing.T) {
+	assert.Equal(t, "accounts", routeGroup("/accounts/"))
+	assert.Equal(t, "trades", routeGroup("/trades"))
+	assert.Equal(t, "trades", routeGroup("/offers/{offer_id}/trades"))
+	assert.Equal(t, "transactions", routeGroup("/ledgers/{ledger_id}/transactions/"))
+	assert.Equal(t, "thresholds/history", routeGroup("/accounts/{account_id:\\w+}/thresholds/history"))
+	assert.Equal(t, "", routeGroup(""))
+}
+
+func TestParsePageLimits(t *testing.T) {
+	limits, err := ParsePageLimits("")
+	assert.NoError(t, err)
+	assert.Empty(t, limits)
+
+	limits, err = ParsePageLimits("trades=20:500, thresholds/history=10:100")
+	assert.NoError(t, err)
+	assert.Equal(t, PageLimits{
+		"trades":             {Default: 20, Max: 500},
+		"thresholds/history": {Default: 10, Max: 100},
+	}, limits)
+
+	for value, expected := range map[string]string{
+		"trades":                "invalid page limit trades, expected group=default:max",
+		"trades=20":             "invalid page limit trades=20, expected group=default:max",
+		"foo=20:500":            "unknown route group foo",
+		"trades=0:500":          "invalid default page size of route group trades",
+		"trades=20:10":          "max page size of route group trades must not be lower than its default",
+		"trades=20:x":           "max page size of route group trades must not be lower than its default",
+		"trades=1:2,trades=3:4": "duplicate page limit for route group trades",
+	} {
+		_, err := ParsePageLimits(value)
+		assert.EqualError(t, err, expected, value)
+	}
+}
+
+func TestPageLimitsMaxPageSize(t *testing.T) {
+	assert.Equal(t, uint64(db2.MaxPageSize), PageLimits{}.MaxPageSize())
+	assert.Equal(t, uint64(db2.MaxPageSize), PageLimits{"trades": {Default: 5, Max: 50}}.MaxPageSize())
+	assert.Equal(t, uint64(500), PageLimits{
+		"trades":     {Default: 20, Max: 500},
+		"order_book": {Default: 20, Max: 100},
+	}.MaxPageSize())
+}
+
+func TestGetPageQueryCursorFormat(t *testing.T) {
+	for _, testCase := range []struct {
+		query  string
+		opts   []Opt
+		field  string
+		reason string
+	}{
+		{"cursor=123-4", nil, "", ""},
+		{"cursor=123", []Opt{Int64Cursor}, "", ""},
+		{"cursor=foo", []Opt{DisableCursorValidation}, "", ""},
+		{"cursor=123-4", []Opt{Int64Cursor}, "cursor", `"123-4" is not a number`},
+		{"cursor=1-2-3", nil, "cursor", `expected at most 2 numbers separated by "-"`},
+		{"cursor=99999999999999999999", nil, "cursor", `"99999999999999999999" is out of range`},
+		{"cursor=1-foo", nil, "cursor", `"foo" is not a number`},
+		{"order=desc&stop_cursor=1-2", []Opt{Int64Cursor, AllowStopCursor}, "stop_cursor", `"1-2" is not a number`},
+	} {
+		t.Run(testCase.query, func(t *testing.T) {
+			r := makeTestActionRequest("/?"+testCase.query, nil)
+			_, err := GetPageQuery(r, testCase.opts...)
+			if testCase.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.IsType(t, &problem.P{}, err) {
+				p := err.(*problem.P)
+				assert.Equal(t, 400, p.Status)
+				assert.Equal(t, testCase.field, p.Extras["invalid_field"])
+				assert.Equal(t, testCase.reason, p.Extras["reason"])
+			}
+		})
+	}
+}
+
+func TestGetPageQueryRandomCursors(t *testing.T) {
+	alphabet := []string{"0", "1", "9", "-", "+", " ", "a", "%", "\x00", "now", "9223372036854775808", "é"}
+	check := func(seed int64) bool {
+		random := rand.New(rand.NewSource(seed))
+		cursor := ""
+		for i := random.Intn(8); i > 0; i-- {
+			cursor += alphabet[random.Intn(len(alphabet))]
+		}
+
+		for _, opts := range [][]Opt{{AllowStopCursor}, {Int64Cursor, AllowStopCursor}} {
+			for _, param := range []string{ParamCursor, ParamStopCursor} {
+				r := makeTestActionRequest("/?"+param+"="+url.QueryEscape(cursor), nil)
+				_, err := GetPageQuery(r, opts...)
+				if err == nil {
+					continue
+				}
+				// invalid cursors must be rendered as bad requests
+				p, ok := err.(*problem.P)
+				if !ok || p.Status != http.StatusBadRequest {
+					t.Logf("cursor %q: unexpected error %v", cursor, err)
+					return false
+				}
+			}
+		}
+		return true
+	}
+	assert.NoError(t, quick.Check(check, &quick.Config{MaxCount: 5000}))
 }
 
 func TestGetString(t *testing.T) {
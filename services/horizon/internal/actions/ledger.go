@@ -10,12 +10,25 @@ import (
 	"github.com/stellar/go/services/horizon/internal/render/problem"
 	"github.com/stellar/go/services/horizon/internal/resourceadapter"
 	"github.com/stellar/go/support/render/hal"
+	supportProblem "github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/support/time"
 )
 
+// LedgersQuery query struct for the ledgers end-point
+type LedgersQuery struct {
+	StartTime time.Millis `schema:"start_time" valid:"-"`
+	EndTime   time.Millis `schema:"end_time" valid:"-"`
+}
+
+// Validate runs extra validations on query parameters
+func (qp LedgersQuery) Validate() error {
+	return validateTimeRange(qp.StartTime, qp.EndTime)
+}
+
 type GetLedgersHandler struct{}
 
 func (handler GetLedgersHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
-	pq, err := GetPageQuery(r)
+	pq, err := GetPageQuery(r, Int64Cursor)
 	if err != nil {
 		return nil, err
 	}
@@ -25,13 +38,21 @@ func (handler GetLedgersHandler) GetResourcePage(w HeaderWriter, r *http.Request
 		return nil, err
 	}
 
+	qp := LedgersQuery{}
+	if err = getParams(&qp, r); err != nil {
+		return nil, err
+	}
+
 	historyQ, err := context.HistoryQFromRequest(r)
 	if err != nil {
 		return nil, err
 	}
 
-	var records []history.Ledger
-	if err = historyQ.Ledgers().Page(pq).Select(&records); err != nil {
+	records, err := historyQ.GetLedgers(r.Context(), history.LedgersFilter{
+		StartTime: qp.StartTime,
+		EndTime:   qp.EndTime,
+	}, pq)
+	if err != nil {
 		return nil, err
 	}
 
@@ -77,3 +98,98 @@ func (handler GetLedgerByIDHandler) GetResource(w HeaderWriter, r *http.Request)
 	resourceadapter.PopulateLedger(r.Context(), &result, ledger)
 	return result, nil
 }
+
+// GetLedgerManifestHandler is the action handler for the
+// `/ledgers/{ledger_id}/manifest` endpoint returning the checksums of the
+// trades and payments of a ledger.
+type GetLedgerManifestHandler struct{}
+
+// GetResource returns the manifest of a ledger.
+func (handler GetLedgerManifestHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	qp := LedgerByIDQuery{}
+	err := getParams(&qp, r)
+	if err != nil {
+		return nil, err
+	}
+	if int32(qp.LedgerID) < ledger.CurrentState().HistoryElder {
+		return nil, problem.BeforeHistory
+	}
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	var manifest history.LedgerManifest
+	err = historyQ.LedgerManifest(&manifest, qp.LedgerID)
+	if err != nil {
+		return nil, err
+	}
+	var result horizon.LedgerManifest
+	resourceadapter.PopulateLedgerManifest(r.Context(), &result, manifest)
+	return result, nil
+}
+
+// GetLedgerExportHandler is the action handler for the
+// `/ledgers/{ledger_id}/export` endpoint returning all the history rows of a
+// ledger in a single document.
+type GetLedgerExportHandler struct{}
+
+// GetResource returns the export of a ledger.
+func (handler GetLedgerExportHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	qp := LedgerByIDQuery{}
+	err := getParams(&qp, r)
+	if err != nil {
+		return nil, err
+	}
+	if int32(qp.LedgerID) < ledger.CurrentState().HistoryElder {
+		return nil, problem.BeforeHistory
+	}
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	var export history.LedgerExport
+	err = historyQ.LedgerExport(&export, qp.LedgerID)
+	if err != nil {
+		return nil, err
+	}
+	var result horizon.LedgerExport
+	err = resourceadapter.PopulateLedgerExport(r.Context(), &result, export)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// LatestLedgerQuery query struct for the ledgers/latest end-point
+type LatestLedgerQuery struct {
+	Source string `schema:"source" valid:"-"`
+}
+
+// GetLatestLedgerHandler is the action handler for the `/ledgers/latest`
+// end-point which returns the latest ledger of stellar-core or of Horizon's
+// history. DefaultSource is used when the source parameter is not set.
+type GetLatestLedgerHandler struct {
+	DefaultSource ledger.LatestSource
+}
+
+// GetResource returns the latest ledger of the requested source.
+func (handler GetLatestLedgerHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	qp := LatestLedgerQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, err
+	}
+
+	source := handler.DefaultSource
+	if qp.Source != "" {
+		var err error
+		if source, err = ledger.ParseLatestSource(qp.Source); err != nil {
+			return nil, supportProblem.MakeInvalidFieldProblem("source", err)
+		}
+	} else if source == "" {
+		source = ledger.LatestSourceIngested
+	}
+
+	var result horizon.LatestLedger
+	resourceadapter.PopulateLatestLedger(r.Context(), &result, ledger.CurrentState(), source)
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/support/render/problem"
+)
+
+func TestGetLatestLedger(t *testing.T) {
+	defer ledger.SetState(ledger.CurrentState())
+	ledger.SetState(ledger.State{CoreLatest: 10, HistoryLatest: 8})
+
+	handler := GetLatestLedgerHandler{DefaultSource: ledger.LatestSourceCore}
+	response, err := handler.GetResource(nil, makeRequest(t, map[string]string{}, map[string]string{}, nil))
+	assert.NoError(t, err)
+	latest := response.(horizon.LatestLedger)
+	assert.Equal(t, "core", latest.Source)
+	assert.Equal(t, int32(10), latest.Sequence)
+	assert.Equal(t, int32(10), latest.CoreSequence)
+	assert.Equal(t, int32(8), latest.HorizonSequence)
+	assert.Equal(t, "/ledgers/10", latest.Links.Ledger.Href)
+
+	response, err = handler.GetResource(nil, makeRequest(t, map[string]string{"source": "ingested"}, map[string]string{}, nil))
+	assert.NoError(t, err)
+	latest = response.(horizon.LatestLedger)
+	assert.Equal(t, "ingested", latest.Source)
+	assert.Equal(t, int32(8), latest.Sequence)
+
+	_, err = handler.GetResource(nil, makeRequest(t, map[string]string{"source": "history"}, map[string]string{}, nil))
+	if assert.IsType(t, &problem.P{}, err) {
+		assert.Equal(t, "source", err.(*problem.P).Extras["invalid_field"])
+	}
+}
+
+func TestLedgersQueryTimeRange(t *testing.T) {
+	assert.NoError(t, LedgersQuery{StartTime: 1000}.Validate())
+
+	err := LedgersQuery{StartTime: -1}.Validate()
+	p, ok := err.(*problem.P)
+	if assert.True(t, ok) {
+		assert.Equal(t, 400, p.Status)
+		assert.Equal(t, "start_time", p.Extras["invalid_field"])
+		assert.Equal(t, "Start time must be a positive number of milliseconds since epoch", p.Extras["reason"])
+	}
+}
@@ -88,7 +88,7 @@ func (handler GetOffersHandler) GetResourcePage(
 		return nil, err
 	}
 
-	pq, err := GetPageQuery(r)
+	pq, err := GetPageQuery(r, Int64Cursor)
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +133,7 @@ type GetAccountOffersHandler struct {
 }
 
 func (handler GetAccountOffersHandler) parseOffersQuery(r *http.Request) (history.OffersQuery, error) {
-	pq, err := GetPageQuery(r)
+	pq, err := GetPageQuery(r, Int64Cursor)
 	if err != nil {
 		return history.OffersQuery{}, err
 	}
@@ -14,6 +14,7 @@ import (
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/hal"
 	supportProblem "github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
 )
 
 // Joinable query struct for join query parameter
@@ -26,6 +27,34 @@ func (qp Joinable) IncludeTransactions() bool {
 	return qp.Join == "transactions"
 }
 
+// loadJoinedTransactions loads in a single query the transactions which
+// contain the given operations. The result is keyed by transaction id.
+func loadJoinedTransactions(historyQ *history.Q, operationIDs []int64) (map[int64]history.Transaction, error) {
+	if len(operationIDs) == 0 {
+		return map[int64]history.Transaction{}, nil
+	}
+
+	var transactionIDs []int64
+	seen := map[int64]bool{}
+	for _, operationID := range operationIDs {
+		transactionID := transactionIDForOperation(operationID)
+		if !seen[transactionID] {
+			seen[transactionID] = true
+			transactionIDs = append(transactionIDs, transactionID)
+		}
+	}
+
+	return historyQ.TransactionsByIDs(transactionIDs...)
+}
+
+// transactionIDForOperation returns the id of the transaction containing the
+// operation with the given id.
+func transactionIDForOperation(operationID int64) int64 {
+	id := toid.Parse(operationID)
+	id.OperationOrder = 0
+	return id.ToInt64()
+}
+
 // OperationsQuery query struct for operations end-points
 type OperationsQuery struct {
 	Joinable                  `valid:"optional"`
@@ -33,6 +62,35 @@ type OperationsQuery struct {
 	TransactionHash           string `schema:"tx_id" valid:"transactionHash,optional"`
 	IncludeFailedTransactions bool   `schema:"include_failed" valid:"-"`
 	LedgerID                  uint32 `schema:"ledger_id" valid:"-"`
+	SourceAssetType           string `schema:"source_asset_type" valid:"assetType,optional"`
+	SourceAssetIssuer         string `schema:"source_asset_issuer" valid:"accountID,optional"`
+	SourceAssetCode           string `schema:"source_asset_code" valid:"-"`
+	// MergesAsPayments renders account merge operations as payments of the
+	// merged balance, it is only accepted by payments end-points.
+	MergesAsPayments bool `schema:"merges_as_payments" valid:"-"`
+}
+
+// SourceAsset returns an xdr.Asset representing the asset used to fund path
+// payments or nil if the source asset filter is not set.
+func (qp OperationsQuery) SourceAsset() (*xdr.Asset, error) {
+	if len(qp.SourceAssetType) == 0 {
+		return nil, nil
+	}
+
+	asset, err := xdr.BuildAsset(
+		qp.SourceAssetType,
+		qp.SourceAssetIssuer,
+		qp.SourceAssetCode,
+	)
+
+	if err != nil {
+		return nil, supportProblem.MakeInvalidFieldProblem(
+			"source_asset",
+			errors.New(fmt.Sprintf("invalid source_asset: %s", err.Error())),
+		)
+	}
+
+	return &asset, nil
 }
 
 // Validate runs extra validations on query parameters
@@ -54,6 +112,10 @@ func (qp OperationsQuery) Validate() error {
 		)
 	}
 
+	if _, err := qp.SourceAsset(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -66,7 +128,7 @@ type GetOperationsHandler struct {
 func (handler GetOperationsHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
 	ctx := r.Context()
 
-	pq, err := GetPageQuery(r)
+	pq, err := GetPageQuery(r, Int64Cursor)
 	if err != nil {
 		return nil, err
 	}
@@ -82,42 +144,48 @@ func (handler GetOperationsHandler) GetResourcePage(w HeaderWriter, r *http.Requ
 		return nil, err
 	}
 
+	if qp.MergesAsPayments && !handler.OnlyPayments {
+		return nil, supportProblem.MakeInvalidFieldProblem(
+			"merges_as_payments",
+			errors.New("merges_as_payments is only supported by payments end-points"),
+		)
+	}
+
+	sourceAsset, err := qp.SourceAsset()
+	if err != nil {
+		return nil, err
+	}
+
 	historyQ, err := horizonContext.HistoryQFromRequest(r)
 	if err != nil {
 		return nil, err
 	}
 
-	query := historyQ.Operations()
+	filter := history.OperationsFilter{
+		// When querying operations for transaction return both successful
+		// and failed operations. We assume that because the user is querying
+		// this specific transactions, they knows its status.
+		IncludeFailed:       qp.TransactionHash != "" || qp.IncludeFailedTransactions,
+		IncludeTransactions: qp.IncludeTransactions(),
+		OnlyPayments:        handler.OnlyPayments,
+		SourceAsset:         sourceAsset,
+	}
 
 	switch {
 	case qp.AccountID != "":
-		query.ForAccount(qp.AccountID)
+		filter.AccountID = qp.AccountID
 	case qp.LedgerID > 0:
-		query.ForLedger(int32(qp.LedgerID))
+		filter.LedgerSequence = int32(qp.LedgerID)
 	case qp.TransactionHash != "":
-		query.ForTransaction(qp.TransactionHash)
-	}
-	// When querying operations for transaction return both successful
-	// and failed operations. We assume that because the user is querying
-	// this specific transactions, they knows its status.
-	if qp.TransactionHash != "" || qp.IncludeFailedTransactions {
-		query.IncludeFailed()
-	}
-
-	if qp.IncludeTransactions() {
-		query.IncludeTransactions()
+		filter.TransactionHash = qp.TransactionHash
 	}
 
-	if handler.OnlyPayments {
-		query.OnlyPayments()
-	}
-
-	ops, txs, err := query.Page(pq).Fetch()
+	ops, txs, err := historyQ.GetOperations(ctx, filter, pq)
 	if err != nil {
 		return nil, err
 	}
 
-	return buildOperationsPage(ctx, historyQ, ops, txs, qp.IncludeTransactions())
+	return buildOperationsPage(ctx, historyQ, ops, txs, qp.IncludeTransactions(), qp.MergesAsPayments)
 }
 
 // GetOperationByIDHandler is the action handler for all end-points returning a list of operations.
@@ -171,7 +239,7 @@ func (handler GetOperationByIDHandler) GetResource(w HeaderWriter, r *http.Reque
 	)
 }
 
-func buildOperationsPage(ctx context.Context, historyQ *history.Q, operations []history.Operation, transactions []history.Transaction, includeTransactions bool) ([]hal.Pageable, error) {
+func buildOperationsPage(ctx context.Context, historyQ *history.Q, operations []history.Operation, transactions []history.Transaction, includeTransactions, mergesAsPayments bool) ([]hal.Pageable, error) {
 	ledgerCache := history.LedgerCache{}
 	for _, record := range operations {
 		ledgerCache.Queue(record.LedgerSequence())
@@ -195,8 +263,13 @@ func buildOperationsPage(ctx context.Context, historyQ *history.Q, operations []
 			transactionRecord = &transactions[i]
 		}
 
+		newOperation := resourceadapter.NewOperation
+		if mergesAsPayments && operationRecord.Type == xdr.OperationTypeAccountMerge {
+			newOperation = resourceadapter.NewAccountMergePayment
+		}
+
 		var res hal.Pageable
-		res, err := resourceadapter.NewOperation(
+		res, err := newOperation(
 			ctx,
 			operationRecord,
 			operationRecord.TransactionHash,
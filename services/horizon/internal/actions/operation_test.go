@@ -482,6 +482,100 @@ func TestGetOperationsOnlyPayments(t *testing.T) {
 
 	record := records[0].(operations.PathPayment)
 	tt.Assert.Equal("10.0000000", record.SourceAmount)
+
+	records, err = handler.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t, map[string]string{
+				"source_asset_type":   "credit_alphanum4",
+				"source_asset_code":   "USD",
+				"source_asset_issuer": "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4",
+			}, map[string]string{}, q.Session,
+		),
+	)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 1) {
+		record = records[0].(operations.PathPayment)
+		tt.Assert.Equal("USD", record.SourceAssetCode)
+	}
+
+	records, err = handler.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t, map[string]string{
+				"source_asset_type": "native",
+			}, map[string]string{}, q.Session,
+		),
+	)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(records, 0)
+
+	_, err = handler.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t, map[string]string{
+				"source_asset_type": "credit_alphanum4",
+				"source_asset_code": "USD",
+			}, map[string]string{}, q.Session,
+		),
+	)
+	tt.Assert.IsType(&supportProblem.P{}, err)
+	p := err.(*supportProblem.P)
+	tt.Assert.Equal("source_asset", p.Extras["invalid_field"])
+}
+
+func TestGetOperationsMergesAsPayments(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	tt.Scenario("account_merge")
+
+	q := &history.Q{tt.HorizonSession()}
+	handler := GetOperationsHandler{
+		OnlyPayments: true,
+	}
+
+	records, err := handler.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t, map[string]string{"ledger_id": "3"}, map[string]string{}, q.Session,
+		),
+	)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 1) {
+		tt.Assert.IsType(operations.AccountMerge{}, records[0])
+	}
+
+	records, err = handler.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t, map[string]string{
+				"ledger_id":          "3",
+				"merges_as_payments": "true",
+			}, map[string]string{}, q.Session,
+		),
+	)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 1) {
+		record := records[0].(operations.AccountMergePayment)
+		tt.Assert.Equal("account_merge", record.Base.Type)
+		tt.Assert.Equal("GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU", record.From)
+		tt.Assert.Equal(record.Account, record.From)
+		tt.Assert.Equal("GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2", record.To)
+		tt.Assert.Equal(record.Into, record.To)
+		tt.Assert.Equal("native", record.Asset.Type)
+		tt.Assert.Equal("999.9999900", record.Amount)
+	}
+
+	// only payments end-points accept the parameter
+	_, err = GetOperationsHandler{}.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t, map[string]string{"merges_as_payments": "true"}, map[string]string{}, q.Session,
+		),
+	)
+	tt.Assert.IsType(&supportProblem.P{}, err)
+	p := err.(*supportProblem.P)
+	tt.Assert.Equal("merges_as_payments", p.Extras["invalid_field"])
 }
 
 func TestOperation_CreatedAt(t *testing.T) {
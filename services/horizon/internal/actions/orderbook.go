@@ -6,6 +6,8 @@ import (
 	protocol "github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/services/horizon/internal/context"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
 	"github.com/stellar/go/services/horizon/internal/resourceadapter"
 	"github.com/stellar/go/support/render/problem"
 )
@@ -90,7 +92,8 @@ func (handler GetOrderbookHandler) GetResource(w HeaderWriter, r *http.Request)
 	if err != nil {
 		return nil, invalidOrderBook
 	}
-	limit, err := getLimit(r, "limit", 20, 200)
+	pageLimit := getPageLimit(r)
+	limit, err := getLimit(r, "limit", pageLimit.Default, pageLimit.Max)
 	if err != nil {
 		return nil, invalidOrderBook
 	}
@@ -117,3 +120,59 @@ func (handler GetOrderbookHandler) GetResource(w HeaderWriter, r *http.Request)
 
 	return response, nil
 }
+
+// GetLedgerOrderbookHandler is the action handler for the
+// `/ledgers/{ledger_id}/order_book` endpoint returning the order book of an
+// asset pair at the end of a ledger, reconstructed from the offer history.
+type GetLedgerOrderbookHandler struct{}
+
+// GetResource returns the order book at the end of the requested ledger.
+func (handler GetLedgerOrderbookHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	qp := LedgerByIDQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, err
+	}
+	selling, err := getAsset(r, "selling_")
+	if err != nil {
+		return nil, invalidOrderBook
+	}
+	buying, err := getAsset(r, "buying_")
+	if err != nil {
+		return nil, invalidOrderBook
+	}
+	pageLimit := getPageLimit(r)
+	limit, err := getLimit(r, "limit", pageLimit.Default, pageLimit.Max)
+	if err != nil {
+		return nil, invalidOrderBook
+	}
+
+	state := ledger.CurrentState()
+	if int32(qp.LedgerID) < state.HistoryElder {
+		return nil, hProblem.BeforeHistory
+	}
+	if qp.LedgerID == 0 || int32(qp.LedgerID) > state.HistoryLatest {
+		return nil, problem.NotFound
+	}
+
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := historyQ.GetOrderBookSummaryAtLedger(r.Context(), selling, buying, qp.LedgerID, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	result := protocol.LedgerOrderBookSummary{Ledger: int32(qp.LedgerID)}
+	if err := resourceadapter.PopulateAsset(r.Context(), &result.Selling, selling); err != nil {
+		return nil, err
+	}
+	if err := resourceadapter.PopulateAsset(r.Context(), &result.Buying, buying); err != nil {
+		return nil, err
+	}
+	result.Bids = convertPriceLevels(summary.Bids)
+	result.Asks = convertPriceLevels(summary.Asks)
+
+	return result, nil
+}
@@ -11,6 +11,9 @@ import (
 	"testing"
 
 	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/support/render/problem"
 	"github.com/stellar/go/xdr"
 )
 
@@ -626,3 +629,29 @@ func TestOrderbookGetResource(t *testing.T) {
 		})
 	}
 }
+
+func TestLedgerOrderbookGetResourceValidation(t *testing.T) {
+	defer ledger.SetState(ledger.CurrentState())
+	ledger.SetState(ledger.State{HistoryElder: 5, HistoryLatest: 10})
+
+	var eurAssetType, eurAssetCode, eurAssetIssuer string
+	if err := eurAsset.Extract(&eurAssetType, &eurAssetCode, &eurAssetIssuer); err != nil {
+		t.Fatalf("cound not extract eur asset: %v", err)
+	}
+	queryParams := map[string]string{
+		"selling_asset_type":  "native",
+		"buying_asset_type":   eurAssetType,
+		"buying_asset_code":   eurAssetCode,
+		"buying_asset_issuer": eurAssetIssuer,
+	}
+
+	handler := GetLedgerOrderbookHandler{}
+	_, err := handler.GetResource(nil, makeRequest(t, map[string]string{}, map[string]string{"ledger_id": "7"}, nil))
+	assert.Equal(t, invalidOrderBook, err)
+
+	_, err = handler.GetResource(nil, makeRequest(t, queryParams, map[string]string{"ledger_id": "4"}, nil))
+	assert.Equal(t, hProblem.BeforeHistory, err)
+
+	_, err = handler.GetResource(nil, makeRequest(t, queryParams, map[string]string{"ledger_id": "11"}, nil))
+	assert.Equal(t, problem.NotFound, err)
+}
@@ -0,0 +1,147 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/errors"
+)
+
+// PageLimit is the default and the maximum page size of a route group.
+type PageLimit struct {
+	Default uint64
+	Max     uint64
+}
+
+// PageLimits are the page limits of route groups. The group of a route is
+// the path following its last parameter, ex. "trades" for /trades and
+// /accounts/{account_id}/trades, "thresholds/history" for
+// /accounts/{account_id}/thresholds/history.
+type PageLimits map[string]PageLimit
+
+// defaultPageLimit is the page limit of the route groups which are not in
+// defaultPageLimits.
+var defaultPageLimit = PageLimit{Default: db2.DefaultPageSize, Max: db2.MaxPageSize}
+
+// defaultPageLimits are the page limits of the route groups which differ
+// from defaultPageLimit.
+var defaultPageLimits = PageLimits{
+	"order_book": {Default: 20, Max: 200},
+}
+
+// PageLimitGroups are the route groups of the paged end-points.
+var PageLimitGroups = []string{
+	"accounts",
+	"assets",
+	"effects",
+	"ledgers",
+	"offers",
+	"operations",
+	"order_book",
+	"payments",
+	"thresholds/history",
+	"trade_aggregations",
+	"trades",
+	"transactions",
+}
+
+// ParsePageLimits parses a comma separated list of page limits in the
+// group=default:max format, ex. "trades=20:500,effects=50:200".
+func ParsePageLimits(value string) (PageLimits, error) {
+	limits := PageLimits{}
+	if value = strings.TrimSpace(value); value == "" {
+		return limits, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid page limit %s, expected group=default:max", entry)
+		}
+		group := parts[0]
+		i := sort.SearchStrings(PageLimitGroups, group)
+		if i == len(PageLimitGroups) || PageLimitGroups[i] != group {
+			return nil, errors.Errorf("unknown route group %s", group)
+		}
+		if _, exists := limits[group]; exists {
+			return nil, errors.Errorf("duplicate page limit for route group %s", group)
+		}
+
+		sizes := strings.SplitN(parts[1], ":", 2)
+		if len(sizes) != 2 {
+			return nil, errors.Errorf("invalid page limit %s, expected group=default:max", entry)
+		}
+		def, err := strconv.ParseUint(sizes[0], 10, 64)
+		if err != nil || def == 0 {
+			return nil, errors.Errorf("invalid default page size of route group %s", group)
+		}
+		max, err := strconv.ParseUint(sizes[1], 10, 64)
+		if err != nil || max < def {
+			return nil, errors.Errorf("max page size of route group %s must not be lower than its default", group)
+		}
+		limits[group] = PageLimit{Default: def, Max: max}
+	}
+	return limits, nil
+}
+
+// MaxPageSize returns the largest max page size of all the route groups,
+// including the groups using the default page limits.
+func (limits PageLimits) MaxPageSize() uint64 {
+	max := defaultPageLimit.Max
+	for _, group := range PageLimitGroups {
+		limit, ok := limits[group]
+		if !ok {
+			limit, ok = defaultPageLimits[group]
+		}
+		if ok && limit.Max > max {
+			max = limit.Max
+		}
+	}
+	return max
+}
+
+type pageLimitsContextKey struct{}
+
+// WithPageLimits returns a copy of ctx in which the page limits of the
+// route groups configured by the operator are stored. They override the
+// default page limits.
+func WithPageLimits(ctx context.Context, limits PageLimits) context.Context {
+	return context.WithValue(ctx, pageLimitsContextKey{}, limits)
+}
+
+// getPageLimit returns the page limit of the route group of r.
+func getPageLimit(r *http.Request) PageLimit {
+	var group string
+	if routeContext := chi.RouteContext(r.Context()); routeContext != nil {
+		group = routeGroup(routeContext.RoutePattern())
+	}
+
+	if limits, ok := r.Context().Value(pageLimitsContextKey{}).(PageLimits); ok {
+		if limit, ok := limits[group]; ok {
+			return limit
+		}
+	}
+	if limit, ok := defaultPageLimits[group]; ok {
+		return limit
+	}
+	return defaultPageLimit
+}
+
+// routeGroup returns the path following the last parameter of the route
+// pattern.
+func routeGroup(pattern string) string {
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if strings.HasPrefix(segments[i], "{") {
+			segments = segments[i+1:]
+			break
+		}
+	}
+	return strings.Join(segments, "/")
+}
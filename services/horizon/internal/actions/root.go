@@ -24,6 +24,9 @@ type GetRootHandler struct {
 	NetworkPassphrase string
 	FriendbotURL      *url.URL
 	HorizonVersion    string
+	// LatestLedgerSource is the default source of the latest ledger
+	// end-point.
+	LatestLedgerSource ledger.LatestSource
 }
 
 func (handler GetRootHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
@@ -47,5 +50,9 @@ func (handler GetRootHandler) GetResource(w HeaderWriter, r *http.Request) (inte
 		handler.FriendbotURL,
 		templates,
 	)
+	res.LatestLedgerSource = string(handler.LatestLedgerSource)
+	if res.LatestLedgerSource == "" {
+		res.LatestLedgerSource = string(ledger.LatestSourceIngested)
+	}
 	return res, nil
 }
@@ -0,0 +1,61 @@
+package actions
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+)
+
+// QuarantinedEntry is the admin representation of a ledger entry quarantined
+// by the state audit. Expected and Actual are omitted when the entry is
+// missing from the ingested state or from the checkpoint.
+type QuarantinedEntry struct {
+	EntryType string    `json:"entry_type"`
+	LedgerKey string    `json:"ledger_key,omitempty"`
+	Reason    string    `json:"reason"`
+	Expected  string    `json:"expected,omitempty"`
+	Actual    string    `json:"actual,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StateQuarantine is the response of the state quarantine admin end-point.
+// Ledger is the checkpoint ledger of the last state audit, 0 if the state
+// was never audited.
+type StateQuarantine struct {
+	Ledger  uint32             `json:"ledger"`
+	Entries []QuarantinedEntry `json:"entries"`
+}
+
+// StateQuarantineHandler is the admin action handler listing the entries
+// quarantined by the last state audit.
+type StateQuarantineHandler struct {
+	HistoryQ *history.Q
+}
+
+// GetResource returns the entries quarantined by the last state audit.
+func (handler StateQuarantineHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	ledger, err := handler.HistoryQ.GetStateAuditLedger()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := handler.HistoryQ.QuarantinedEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	response := StateQuarantine{Ledger: ledger, Entries: []QuarantinedEntry{}}
+	for _, record := range records {
+		response.Entries = append(response.Entries, QuarantinedEntry{
+			EntryType: record.EntryType,
+			LedgerKey: record.LedgerKey,
+			Reason:    record.Reason,
+			Expected:  record.Expected.String,
+			Actual:    record.Actual.String,
+			CreatedAt: record.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
@@ -16,9 +16,15 @@ import (
 	"github.com/stellar/go/xdr"
 )
 
+// ReadOnlyModeGetter reports if Horizon is in read-only mode.
+type ReadOnlyModeGetter interface {
+	Enabled() bool
+}
+
 type SubmitTransactionHandler struct {
 	Submitter         *txsub.System
 	NetworkPassphrase string
+	ReadOnly          ReadOnlyModeGetter
 }
 
 type envelopeInfo struct {
@@ -43,6 +49,24 @@ func extractEnvelopeInfo(raw string, passphrase string) (envelopeInfo, error) {
 	return result, nil
 }
 
+// malformedTransactionProblem returns the problem of an envelope which could
+// not be decoded.
+func malformedTransactionProblem(raw string) *problem.P {
+	return &problem.P{
+		Type:   "transaction_malformed",
+		Title:  "Transaction Malformed",
+		Status: http.StatusBadRequest,
+		Detail: "Horizon could not decode the transaction envelope in this " +
+			"request. A transaction should be an XDR TransactionEnvelope struct " +
+			"encoded using base64.  The envelope read from this request is " +
+			"echoed in the `extras.envelope_xdr` field of this response for your " +
+			"convenience.",
+		Extras: map[string]interface{}{
+			"envelope_xdr": raw,
+		},
+	}
+}
+
 func (handler SubmitTransactionHandler) validateBodyType(r *http.Request) error {
 	c := r.Header.Get("Content-Type")
 	if c == "" {
@@ -110,6 +134,10 @@ func (handler SubmitTransactionHandler) response(r *http.Request, info envelopeI
 }
 
 func (handler SubmitTransactionHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	if handler.ReadOnly != nil && handler.ReadOnly.Enabled() {
+		return nil, &hProblem.ReadOnly
+	}
+
 	if err := handler.validateBodyType(r); err != nil {
 		return nil, err
 	}
@@ -121,19 +149,7 @@ func (handler SubmitTransactionHandler) GetResource(w HeaderWriter, r *http.Requ
 
 	info, err := extractEnvelopeInfo(raw, handler.NetworkPassphrase)
 	if err != nil {
-		return nil, &problem.P{
-			Type:   "transaction_malformed",
-			Title:  "Transaction Malformed",
-			Status: http.StatusBadRequest,
-			Detail: "Horizon could not decode the transaction envelope in this " +
-				"request. A transaction should be an XDR TransactionEnvelope struct " +
-				"encoded using base64.  The envelope read from this request is " +
-				"echoed in the `extras.envelope_xdr` field of this response for your " +
-				"convenience.",
-			Extras: map[string]interface{}{
-				"envelope_xdr": raw,
-			},
-		}
+		return nil, malformedTransactionProblem(raw)
 	}
 
 	submission := handler.Submitter.Submit(
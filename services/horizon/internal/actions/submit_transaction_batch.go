@@ -0,0 +1,221 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/stellar/throttled"
+
+	"github.com/stellar/go/protocols/horizon"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/services/horizon/internal/txsub"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// SubmitTransactionBatchHandler submits the envelopes of the `tx` form values
+// of a request concurrently and responds with the result of each of them.
+// The envelopes of a source account are submitted one after the other in the
+// order of their sequence numbers.
+type SubmitTransactionBatchHandler struct {
+	SubmitTransactionHandler
+	// MaxSize is the maximum number of envelopes of a batch.
+	MaxSize uint
+	// RateLimiter, if set, counts each envelope of a batch as a request.
+	RateLimiter *throttled.HTTPRateLimiter
+}
+
+func (handler SubmitTransactionBatchHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	if handler.ReadOnly != nil && handler.ReadOnly.Enabled() {
+		return nil, &hProblem.ReadOnly
+	}
+
+	if err := handler.validateBodyType(r); err != nil {
+		return nil, err
+	}
+
+	raws, err := getBatchEnvelopes(r, handler.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := handler.rateLimit(r, len(raws)); err != nil {
+		return nil, err
+	}
+
+	results := make([]horizon.TransactionBatchResult, len(raws))
+	infos := make([]envelopeInfo, len(raws))
+	bySource := map[string][]int{}
+	for i, raw := range raws {
+		info, err := extractEnvelopeInfo(raw, handler.NetworkPassphrase)
+		if err != nil {
+			results[i].Problem = batchProblem(r, malformedTransactionProblem(raw))
+			continue
+		}
+		infos[i] = info
+		results[i].Hash = info.hash
+
+		source := info.parsed.SourceAccount().ToAccountId()
+		address := source.Address()
+		bySource[address] = append(bySource[address], i)
+	}
+
+	var wg sync.WaitGroup
+	for _, indexes := range bySource {
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return infos[indexes[a]].parsed.SeqNum() < infos[indexes[b]].parsed.SeqNum()
+		})
+
+		wg.Add(1)
+		go func(indexes []int) {
+			defer wg.Done()
+			handler.submitAccountEnvelopes(r, infos, indexes, results)
+		}(indexes)
+	}
+	wg.Wait()
+
+	return horizon.TransactionBatch{Records: results}, nil
+}
+
+// rateLimit counts the envelopes of a batch against the rate limit, the
+// request itself was counted as one by the rate limiting middleware.
+func (handler SubmitTransactionBatchHandler) rateLimit(r *http.Request, envelopes int) error {
+	rateLimiter := handler.RateLimiter
+	if rateLimiter == nil || envelopes <= 1 {
+		return nil
+	}
+
+	limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(r), envelopes-1)
+	if err != nil {
+		return errors.Wrap(err, "RateLimiter error")
+	}
+	if limited {
+		return &hProblem.RateLimitExceeded
+	}
+	return nil
+}
+
+// maxBatchFormMemory is the maximum number of bytes of a multipart batch form
+// kept in memory.
+const maxBatchFormMemory = 32 << 20
+
+// getBatchEnvelopes returns the `tx` form values of the request body, there
+// must be at least one and at most maxSize of them.
+func getBatchEnvelopes(r *http.Request, maxSize uint) ([]string, error) {
+	err := r.ParseMultipartForm(maxBatchFormMemory)
+	if err != nil && err != http.ErrNotMultipart {
+		return nil, problem.MakeInvalidFieldProblem("tx", err)
+	}
+
+	raws := r.PostForm["tx"]
+	if len(raws) == 0 {
+		return nil, problem.MakeInvalidFieldProblem("tx", errors.New("at least one envelope is required"))
+	}
+	if uint(len(raws)) > maxSize {
+		return nil, problem.MakeInvalidFieldProblem(
+			"tx",
+			fmt.Errorf("a batch can't have more than %d envelopes", maxSize),
+		)
+	}
+	for _, raw := range raws {
+		if err := checkUTF8("tx", raw); err != nil {
+			return nil, err
+		}
+	}
+	return raws, nil
+}
+
+// submitAccountEnvelopes submits the envelopes of a source account at the
+// given indexes, sorted by sequence number. Each envelope is submitted once
+// the previous one was sent to stellar-core, the results are then awaited
+// in the same order. Once an envelope fails without being ingested, ex. it's
+// rejected by stellar-core, its sequence number isn't consumed and the
+// following ones would wait in the sequence queue of the account until the
+// request ends, they are skipped instead.
+func (handler SubmitTransactionBatchHandler) submitAccountEnvelopes(
+	r *http.Request,
+	infos []envelopeInfo,
+	indexes []int,
+	results []horizon.TransactionBatchResult,
+) {
+	ctx := r.Context()
+	submissions := make([]<-chan txsub.Result, len(indexes))
+submit:
+	for i, index := range indexes {
+		info := infos[index]
+		submission := handler.Submitter.Submit(ctx, info.raw, info.parsed, info.hash)
+		// the result of a submission is ready when it's returned unless it
+		// was sent to stellar-core and waits to be ingested
+		select {
+		case result := <-submission:
+			handler.setBatchResult(r, info, result, &results[index])
+			if result.Err == nil || result.Transaction.TransactionHash != "" {
+				continue
+			}
+			for _, skipped := range indexes[i+1:] {
+				results[skipped].Problem = batchProblem(r, &hProblem.TransactionSkipped)
+			}
+			break submit
+		default:
+			submissions[i] = submission
+		}
+	}
+
+	for i, index := range indexes {
+		if submissions[i] == nil {
+			continue
+		}
+		select {
+		case result := <-submissions[i]:
+			handler.setBatchResult(r, infos[index], result, &results[index])
+		case <-ctx.Done():
+			results[index].Problem = batchProblem(r, &hProblem.Timeout)
+		}
+	}
+}
+
+// setBatchResult sets the batch result of an envelope to its submission
+// result.
+func (handler SubmitTransactionBatchHandler) setBatchResult(
+	r *http.Request,
+	info envelopeInfo,
+	result txsub.Result,
+	batchResult *horizon.TransactionBatchResult,
+) {
+	resource, err := handler.response(r, info, result)
+	if err != nil {
+		batchResult.Problem = batchProblem(r, err)
+		return
+	}
+	transaction := resource.(horizon.Transaction)
+	batchResult.Successful = true
+	batchResult.Transaction = &transaction
+}
+
+// batchProblem returns the problem rendered for err by the `/transactions`
+// end-point, for the result of an envelope of a batch.
+func batchProblem(r *http.Request, err error) *problem.P {
+	var p problem.P
+	switch cause := errors.Cause(err).(type) {
+	case problem.P:
+		p = cause
+	case *problem.P:
+		p = *cause
+	default:
+		if known, ok := problem.IsKnownError(err).(problem.P); ok {
+			p = known
+		} else {
+			log.Ctx(r.Context()).WithStack(err).Error(err)
+			p = problem.ServerError
+		}
+	}
+
+	host := problem.Default.ServiceHost()
+	if host != "" && !strings.HasPrefix(p.Type, host) {
+		p.Type = host + p.Type
+	}
+	return &p
+}
@@ -0,0 +1,213 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stellar/throttled"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/protocols/horizon"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/services/horizon/internal/txsub"
+	"github.com/stellar/go/services/horizon/internal/txsub/sequence"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+func makeBatchRequest(envelopes ...string) *http.Request {
+	body := url.Values{"tx": envelopes}.Encode()
+	r := httptest.NewRequest(http.MethodPost, "/transactions/batch", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestSubmitTransactionBatchInvalidSize(t *testing.T) {
+	handler := SubmitTransactionBatchHandler{MaxSize: 2}
+
+	_, err := handler.GetResource(httptest.NewRecorder(), makeBatchRequest())
+	if assert.IsType(t, &problem.P{}, err) {
+		assert.Equal(t, "tx", err.(*problem.P).Extras["invalid_field"])
+		assert.Equal(t, "at least one envelope is required", err.(*problem.P).Extras["reason"])
+	}
+
+	_, err = handler.GetResource(httptest.NewRecorder(), makeBatchRequest("a", "b", "c"))
+	if assert.IsType(t, &problem.P{}, err) {
+		assert.Equal(t, "tx", err.(*problem.P).Extras["invalid_field"])
+		assert.Equal(t, "a batch can't have more than 2 envelopes", err.(*problem.P).Extras["reason"])
+	}
+}
+
+func TestSubmitTransactionBatchMalformedEnvelopes(t *testing.T) {
+	handler := SubmitTransactionBatchHandler{
+		SubmitTransactionHandler: SubmitTransactionHandler{NetworkPassphrase: network.TestNetworkPassphrase},
+		MaxSize:                  10,
+	}
+
+	resource, err := handler.GetResource(httptest.NewRecorder(), makeBatchRequest("a", "b"))
+	assert.NoError(t, err)
+	batch := resource.(horizon.TransactionBatch)
+	if assert.Len(t, batch.Records, 2) {
+		for i, envelope := range []string{"a", "b"} {
+			record := batch.Records[i]
+			assert.False(t, record.Successful)
+			assert.Empty(t, record.Hash)
+			assert.Equal(t, problem.Default.ServiceHost()+"transaction_malformed", record.Problem.Type)
+			assert.Equal(t, envelope, record.Problem.Extras["envelope_xdr"])
+		}
+	}
+}
+
+func TestBatchProblem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/transactions/batch", nil)
+	host := problem.Default.ServiceHost()
+
+	p := batchProblem(r, &hProblem.Timeout)
+	assert.Equal(t, host+hProblem.Timeout.Type, p.Type)
+	assert.Equal(t, hProblem.Timeout.Status, p.Status)
+	// the registered problems are not modified
+	assert.False(t, strings.HasPrefix(hProblem.Timeout.Type, host))
+
+	p = batchProblem(r, errors.Wrap(hProblem.ReadOnly, "wrapped"))
+	assert.Equal(t, host+hProblem.ReadOnly.Type, p.Type)
+
+	p = batchProblem(r, errors.New("unexpected"))
+	assert.Equal(t, host+problem.ServerError.Type, p.Type)
+	assert.Equal(t, http.StatusInternalServerError, p.Status)
+}
+
+// batchTestDB is a horizon database in which no transaction is ingested, the
+// sequence number of the source account is the number of its envelopes
+// accepted by batchTestCore.
+type batchTestDB struct {
+	core *batchTestCore
+}
+
+func (db *batchTestDB) TransactionByHash(dest interface{}, hash string) error {
+	return sql.ErrNoRows
+}
+
+func (db *batchTestDB) GetSequenceNumbers(addresses []string) (map[string]uint64, error) {
+	db.core.mutex.Lock()
+	defer db.core.mutex.Unlock()
+	sequences := map[string]uint64{}
+	for _, address := range addresses {
+		sequences[address] = uint64(len(db.core.accepted))
+	}
+	return sequences, nil
+}
+
+func (db *batchTestDB) BeginTx(*sql.TxOptions) error { return nil }
+func (db *batchTestDB) Rollback() error              { return nil }
+func (db *batchTestDB) NoRows(err error) bool        { return err == sql.ErrNoRows }
+
+// batchTestCore accepts the submitted envelopes except the rejected ones.
+type batchTestCore struct {
+	mutex     sync.Mutex
+	rejected  map[string]bool
+	accepted  []string
+	submitted []string
+}
+
+func (core *batchTestCore) Submit(ctx context.Context, raw string) txsub.SubmissionResult {
+	core.mutex.Lock()
+	defer core.mutex.Unlock()
+	core.submitted = append(core.submitted, raw)
+	if core.rejected[raw] {
+		resultXDR, err := xdr.MarshalBase64(xdr.TransactionResult{
+			FeeCharged: 100,
+			Result:     xdr.TransactionResultResult{Code: xdr.TransactionResultCodeTxBadAuth},
+		})
+		if err != nil {
+			return txsub.SubmissionResult{Err: err}
+		}
+		return txsub.SubmissionResult{Err: &txsub.FailedTransactionError{ResultXDR: resultXDR}}
+	}
+	core.accepted = append(core.accepted, raw)
+	return txsub.SubmissionResult{}
+}
+
+func batchTestEnvelope(t *testing.T, sequence int64) envelopeInfo {
+	raw, err := xdr.MarshalBase64(xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{
+			Tx: xdr.Transaction{
+				SourceAccount: xdr.MustMuxedAddress("GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU"),
+				Fee:           100,
+				SeqNum:        xdr.SequenceNumber(sequence),
+				Operations: []xdr.Operation{{Body: xdr.OperationBody{
+					Type:           xdr.OperationTypeBumpSequence,
+					BumpSequenceOp: &xdr.BumpSequenceOp{},
+				}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	info, err := extractEnvelopeInfo(raw, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	return info
+}
+
+func TestSubmitAccountEnvelopesSkipsAfterFailure(t *testing.T) {
+	infos := []envelopeInfo{batchTestEnvelope(t, 1), batchTestEnvelope(t, 2), batchTestEnvelope(t, 3)}
+	core := &batchTestCore{rejected: map[string]bool{infos[1].raw: true}}
+	system := &txsub.System{
+		DB:              func(context.Context) txsub.HorizonDB { return &batchTestDB{core: core} },
+		Pending:         txsub.NewDefaultSubmissionList(),
+		Submitter:       core,
+		SubmissionQueue: sequence.NewManager(),
+	}
+	handler := SubmitTransactionBatchHandler{
+		SubmitTransactionHandler: SubmitTransactionHandler{Submitter: system},
+	}
+
+	// the first envelope is accepted but not ingested before the request
+	// ends, the second one is rejected and the third one isn't submitted
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	r := makeBatchRequest().WithContext(ctx)
+	results := make([]horizon.TransactionBatchResult, len(infos))
+	handler.submitAccountEnvelopes(r, infos, []int{0, 1, 2}, results)
+
+	assert.Equal(t, []string{infos[0].raw, infos[1].raw}, core.submitted)
+	host := problem.Default.ServiceHost()
+	assert.Equal(t, host+hProblem.Timeout.Type, results[0].Problem.Type)
+	assert.Equal(t, host+"transaction_failed", results[1].Problem.Type)
+	assert.Equal(t, host+hProblem.TransactionSkipped.Type, results[2].Problem.Type)
+	for _, result := range results {
+		assert.False(t, result.Successful)
+	}
+}
+
+func TestSubmitTransactionBatchRateLimit(t *testing.T) {
+	rateLimiter, err := throttled.NewGCRARateLimiter(10, throttled.RateQuota{
+		MaxRate:  throttled.PerHour(1),
+		MaxBurst: 4,
+	})
+	require.NoError(t, err)
+	handler := SubmitTransactionBatchHandler{
+		RateLimiter: &throttled.HTTPRateLimiter{
+			RateLimiter: rateLimiter,
+			VaryBy:      &throttled.VaryBy{RemoteAddr: true},
+		},
+	}
+	r := makeBatchRequest()
+
+	// each envelope after the first one, counted by the middleware, is a
+	// hit, the burst allows 5 hits
+	assert.NoError(t, handler.rateLimit(r, 1))
+	assert.NoError(t, handler.rateLimit(r, 5))
+	assert.Equal(t, &hProblem.RateLimitExceeded, handler.rateLimit(r, 3))
+	assert.NoError(t, handler.rateLimit(r, 2))
+	assert.Equal(t, &hProblem.RateLimitExceeded, handler.rateLimit(r, 2))
+}
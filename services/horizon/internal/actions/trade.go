@@ -20,65 +20,123 @@ import (
 
 // TradeAssetsQueryParams represents the base and counter assets on trade related end-points.
 type TradeAssetsQueryParams struct {
-	BaseAssetType      string `schema:"base_asset_type" valid:"assetType,optional"`
+	BaseAssetType      string `schema:"base_asset_type" valid:"-"`
 	BaseAssetIssuer    string `schema:"base_asset_issuer" valid:"accountID,optional"`
 	BaseAssetCode      string `schema:"base_asset_code" valid:"-"`
-	CounterAssetType   string `schema:"counter_asset_type" valid:"assetType,optional"`
+	CounterAssetType   string `schema:"counter_asset_type" valid:"-"`
 	CounterAssetIssuer string `schema:"counter_asset_issuer" valid:"accountID,optional"`
 	CounterAssetCode   string `schema:"counter_asset_code" valid:"-"`
+
+	// allow base and counter using an asset's canonical representation.
+	BaseAsset    string `schema:"base" valid:"asset,optional"`
+	CounterAsset string `schema:"counter" valid:"asset,optional"`
+}
+
+// unsupportedTradeAssetTypes are asset types which are recognized by the
+// trade end-points but can't be used as a trade asset yet, mapped to the
+// reason returned to the client.
+var unsupportedTradeAssetTypes = map[string]string{
+	"liquidity_pool_shares": "liquidity pool shares are not supported by this endpoint",
 }
 
 // Base returns an xdr.Asset representing the base side of the trade.
 func (q TradeAssetsQueryParams) Base() (*xdr.Asset, error) {
-	if len(q.BaseAssetType) == 0 {
-		return nil, nil
-	}
-
-	base, err := xdr.BuildAsset(
+	return parseTradeAsset(
+		"base",
+		q.BaseAsset,
 		q.BaseAssetType,
 		q.BaseAssetIssuer,
 		q.BaseAssetCode,
 	)
-
-	if err != nil {
-		return nil, problem.MakeInvalidFieldProblem(
-			"base_asset",
-			errors.New(fmt.Sprintf("invalid base_asset: %s", err.Error())),
-		)
-	}
-
-	return &base, nil
 }
 
 // Counter returns an *xdr.Asset representing the counter asset side of the trade.
 func (q TradeAssetsQueryParams) Counter() (*xdr.Asset, error) {
-	if len(q.CounterAssetType) == 0 {
-		return nil, nil
-	}
-
-	counter, err := xdr.BuildAsset(
+	return parseTradeAsset(
+		"counter",
+		q.CounterAsset,
 		q.CounterAssetType,
 		q.CounterAssetIssuer,
 		q.CounterAssetCode,
 	)
+}
+
+// parseTradeAsset resolves the asset given either in the canonical form in
+// the `side` parameter or using the `side`_asset_type, `side`_asset_code and
+// `side`_asset_issuer parameters. It returns nil if the asset is not set.
+func parseTradeAsset(side, canonical, assetType, issuer, code string) (*xdr.Asset, error) {
+	prefix := side + "_"
+
+	if len(canonical) > 0 {
+		if len(assetType) > 0 {
+			return nil, problem.MakeInvalidFieldProblem(
+				prefix+"asset_type",
+				errors.New(fmt.Sprintf(
+					"Ambiguous parameter, you can't include both `%[1]s` and `%[1]s_asset_type`. Remove all parameters of the form `%[1]s_`",
+					side,
+				)),
+			)
+		}
 
+		parsed, err := xdr.BuildAssets(canonical)
+		if err != nil || len(parsed) != 1 {
+			return nil, problem.MakeInvalidFieldProblem(
+				side,
+				errors.New(customTagsErrorMessages["asset"]),
+			)
+		}
+		return &parsed[0], nil
+	}
+
+	if reason, ok := unsupportedTradeAssetTypes[assetType]; ok {
+		return nil, problem.MakeInvalidFieldProblem(
+			prefix+"asset_type",
+			errors.New(reason),
+		)
+	}
+	if err := validateAssetParams(assetType, code, issuer, prefix); err != nil {
+		return nil, err
+	}
+	if len(assetType) == 0 {
+		return nil, nil
+	}
+
+	asset, err := xdr.BuildAsset(assetType, issuer, code)
 	if err != nil {
 		return nil, problem.MakeInvalidFieldProblem(
-			"counter_asset",
-			errors.New(fmt.Sprintf("invalid counter_asset: %s", err.Error())),
+			prefix+"asset",
+			errors.New(fmt.Sprintf("invalid %s_asset: %s", side, err.Error())),
 		)
 	}
 
-	return &counter, nil
+	return &asset, nil
 }
 
 // TradesQuery query struct for trades end-points
 type TradesQuery struct {
-	AccountID              string `schema:"account_id" valid:"accountID,optional"`
-	OfferID                uint64 `schema:"offer_id" valid:"-"`
+	Joinable               `valid:"optional"`
+	AccountID              string      `schema:"account_id" valid:"accountID,optional"`
+	OfferID                uint64      `schema:"offer_id" valid:"-"`
+	StartTime              time.Millis `schema:"start_time" valid:"-"`
+	EndTime                time.Millis `schema:"end_time" valid:"-"`
+	PagingTokenVersion     uint32      `schema:"paging_token_version" valid:"-"`
 	TradeAssetsQueryParams `valid:"optional"`
 }
 
+// Paging token versions of trades. Version 1 tokens are made of the id of the
+// operation and the order of the trade in the operation, version 2 tokens
+// are the global sequence of the trade.
+const (
+	tradePagingTokenV1 = 1
+	tradePagingTokenV2 = 2
+)
+
+// PagingBySequence returns true if trades are paged using version 2 paging
+// tokens.
+func (q TradesQuery) PagingBySequence() bool {
+	return q.PagingTokenVersion == tradePagingTokenV2
+}
+
 // Validate runs custom validations base and counter
 func (q TradesQuery) Validate() error {
 	base, err := q.Base()
@@ -97,7 +155,16 @@ func (q TradesQuery) Validate() error {
 		)
 	}
 
-	return nil
+	switch q.PagingTokenVersion {
+	case 0, tradePagingTokenV1, tradePagingTokenV2:
+	default:
+		return problem.MakeInvalidFieldProblem(
+			"paging_token_version",
+			errors.New("Paging token version must be 1 or 2"),
+		)
+	}
+
+	return validateTimeRange(q.StartTime, q.EndTime)
 }
 
 // GetTradesHandler is the action handler for all end-points returning a list of trades.
@@ -108,80 +175,128 @@ type GetTradesHandler struct {
 func (handler GetTradesHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
 	ctx := r.Context()
 
-	pq, err := GetPageQuery(r)
-	if err != nil {
+	qp := TradesQuery{}
+	if err := getParams(&qp, r); err != nil {
 		return nil, err
 	}
 
-	err = validateCursorWithinHistory(pq)
+	pq, err := getTradesPageQuery(r, qp)
 	if err != nil {
 		return nil, err
 	}
 
-	qp := TradesQuery{}
-	if err = getParams(&qp, r); err != nil {
+	baseAsset, err := qp.Base()
+	if err != nil {
 		return nil, err
 	}
-
-	historyQ, err := context.HistoryQFromRequest(r)
+	counterAsset, err := qp.Counter()
 	if err != nil {
 		return nil, err
 	}
 
-	trades := historyQ.Trades()
-
-	if qp.AccountID != "" {
-		trades.ForAccount(qp.AccountID)
-	}
-
-	baseAsset, err := qp.Base()
+	historyQ, err := context.HistoryQFromRequest(r)
 	if err != nil {
 		return nil, err
 	}
 
-	if baseAsset != nil {
-		baseAssetID, err2 := historyQ.GetAssetID(*baseAsset)
-		if err2 != nil {
-			return nil, err2
-		}
+	filter := history.TradesFilter{
+		OfferID:    int64(qp.OfferID),
+		StartTime:  qp.StartTime,
+		EndTime:    qp.EndTime,
+		BySequence: qp.PagingBySequence(),
+	}
 
-		counterAsset, err2 := qp.Counter()
-		if err2 != nil {
-			return nil, err2
+	// the asset pair filter replaces the account filter
+	if baseAsset != nil {
+		filter.BaseAssetID, err = historyQ.GetAssetID(*baseAsset)
+		if err != nil {
+			return nil, err
 		}
 
-		counterAssetID, err2 := historyQ.GetAssetID(*counterAsset)
-		if err2 != nil {
-			return nil, err2
+		filter.CounterAssetID, err = historyQ.GetAssetID(*counterAsset)
+		if err != nil {
+			return nil, err
 		}
-		trades = historyQ.TradesForAssetPair(baseAssetID, counterAssetID)
+	} else {
+		filter.AccountID = qp.AccountID
 	}
 
-	if qp.OfferID != 0 {
-		trades = trades.ForOffer(int64(qp.OfferID))
+	records, err := historyQ.GetTrades(ctx, filter, pq)
+	if err != nil {
+		return nil, err
 	}
 
-	var records []history.Trade
-	if err = trades.Page(pq).Select(&records); err != nil {
-		return nil, err
+	var transactions map[int64]history.Transaction
+	if qp.IncludeTransactions() {
+		operationIDs := make([]int64, 0, len(records))
+		for _, record := range records {
+			operationIDs = append(operationIDs, record.HistoryOperationID)
+		}
+		transactions, err = loadJoinedTransactions(historyQ, operationIDs)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading transactions")
+		}
 	}
+
 	var response []hal.Pageable
 
 	for _, record := range records {
 		var res horizon.Trade
 		resourceadapter.PopulateTrade(ctx, &res, record)
+		if qp.PagingBySequence() {
+			res.PT = strconv.FormatInt(record.Sequence, 10)
+		}
+
+		if qp.IncludeTransactions() {
+			transaction, ok := transactions[transactionIDForOperation(record.HistoryOperationID)]
+			if !ok {
+				return nil, errors.Errorf("could not find transaction for trade %s", record.PagingToken())
+			}
+			res.Transaction = new(horizon.Transaction)
+			err = resourceadapter.PopulateTransaction(ctx, transaction.TransactionHash, res.Transaction, transaction)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		response = append(response, res)
 	}
 
 	return response, nil
 }
 
+// getTradesPageQuery returns the page query of a trades request. Cursors of
+// version 2 paging tokens are trade sequences so they can't be compared with
+// the history range nor set to "now", which is resolved to an operation id.
+func getTradesPageQuery(r *http.Request, qp TradesQuery) (db2.PageQuery, error) {
+	if !qp.PagingBySequence() {
+		pq, err := GetPageQuery(r, AllowStopCursor)
+		if err != nil {
+			return pq, err
+		}
+		return pq, validateCursorWithinHistory(pq)
+	}
+
+	cursor, err := getString(r, ParamCursor)
+	if err != nil {
+		return db2.PageQuery{}, err
+	}
+	if cursor == "now" && r.Header.Get("Last-Event-ID") == "" {
+		return db2.PageQuery{}, problem.MakeInvalidFieldProblem(
+			ParamCursor,
+			errors.New("now is not supported with version 2 paging tokens"),
+		)
+	}
+	return GetPageQuery(r, Int64Cursor, AllowStopCursor)
+}
+
 // TradeAggregationsQuery query struct for trade_aggregations end-point
 type TradeAggregationsQuery struct {
 	OffsetFilter           uint64      `schema:"offset" valid:"-"`
 	StartTimeFilter        time.Millis `schema:"start_time" valid:"-"`
 	EndTimeFilter          time.Millis `schema:"end_time" valid:"-"`
 	ResolutionFilter       uint64      `schema:"resolution" valid:"-"`
+	FillGaps               bool        `schema:"fill_gaps" valid:"-"`
 	TradeAssetsQueryParams `valid:"optional"`
 }
 
@@ -359,7 +474,30 @@ func (handler GetTradeAggregationsHandler) fetchRecords(historyQ *history.Q, qp
 	if err != nil {
 		return nil, err
 	}
-	return records, err
+
+	if qp.FillGaps {
+		gaps := history.TradeAggregationGaps{
+			Resolution: int64(qp.ResolutionFilter),
+			Order:      pq.Order,
+			Limit:      pq.Limit,
+		}
+		if start, ok := tradeAggregationsQ.PageStart(); ok {
+			gaps.Start = &start
+			if pq.Order != db2.OrderDescending {
+				// the close price of the previous page is carried forward
+				// in the buckets before the first record
+				var previousClose xdr.Price
+				err = historyQ.Get(&previousClose, tradeAggregationsQ.GetPreviousCloseSql())
+				if err == nil {
+					gaps.PreviousClose = &previousClose
+				} else if !historyQ.NoRows(err) {
+					return nil, err
+				}
+			}
+		}
+		records = history.FillTradeAggregationGaps(records, gaps)
+	}
+	return records, nil
 }
 
 // BuildPage builds a custom hal page for this handler
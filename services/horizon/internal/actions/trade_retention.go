@@ -0,0 +1,152 @@
+package actions
+
+import (
+	"net/http"
+	gTime "time"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+// TradeRetentionPolicy is the admin representation of a trade retention
+// policy of an asset pair. RetentionSeconds equal to 0 means trades of the
+// pair are kept forever.
+type TradeRetentionPolicy struct {
+	BaseAssetType      string `json:"base_asset_type"`
+	BaseAssetCode      string `json:"base_asset_code,omitempty"`
+	BaseAssetIssuer    string `json:"base_asset_issuer,omitempty"`
+	CounterAssetType   string `json:"counter_asset_type"`
+	CounterAssetCode   string `json:"counter_asset_code,omitempty"`
+	CounterAssetIssuer string `json:"counter_asset_issuer,omitempty"`
+	RetentionSeconds   int64  `json:"retention_seconds"`
+}
+
+// TradeRetentionPolicies is the response of the trade retention policies
+// admin end-point.
+type TradeRetentionPolicies struct {
+	Policies []TradeRetentionPolicy `json:"policies"`
+}
+
+// TradeRetentionPolicyQuery query struct for changing the retention policy of
+// an asset pair.
+type TradeRetentionPolicyQuery struct {
+	RetentionSeconds       uint64 `schema:"retention_seconds" valid:"-"`
+	TradeAssetsQueryParams `valid:"optional"`
+}
+
+// Validate runs custom validations base and counter
+func (q TradeRetentionPolicyQuery) Validate() error {
+	base, err := q.Base()
+	if err != nil {
+		return err
+	}
+	if base == nil {
+		return problem.MakeInvalidFieldProblem(
+			"base_asset_type",
+			errors.New("Missing required field"),
+		)
+	}
+	counter, err := q.Counter()
+	if err != nil {
+		return err
+	}
+	if counter == nil {
+		return problem.MakeInvalidFieldProblem(
+			"counter_asset_type",
+			errors.New("Missing required field"),
+		)
+	}
+	if base.Equals(*counter) {
+		return problem.MakeInvalidFieldProblem(
+			"counter_asset_type",
+			errors.New("base and counter assets must be different"),
+		)
+	}
+
+	return nil
+}
+
+// TradeRetentionPoliciesHandler is the admin action handler listing (GET),
+// creating or updating (PUT) and removing (DELETE) per asset pair trade
+// retention policies used by the reaper.
+type TradeRetentionPoliciesHandler struct {
+	HistoryQ *history.Q
+}
+
+// GetResource applies the change requested by the method of the request and
+// returns all the trade retention policies.
+func (handler TradeRetentionPoliciesHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	switch r.Method {
+	case http.MethodPut, http.MethodDelete:
+		if err := handler.change(r); err != nil {
+			return nil, err
+		}
+	}
+
+	records, err := handler.HistoryQ.TradeRetentionPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	response := TradeRetentionPolicies{Policies: []TradeRetentionPolicy{}}
+	for _, record := range records {
+		response.Policies = append(response.Policies, TradeRetentionPolicy{
+			BaseAssetType:      record.BaseAssetType,
+			BaseAssetCode:      record.BaseAssetCode,
+			BaseAssetIssuer:    record.BaseAssetIssuer,
+			CounterAssetType:   record.CounterAssetType,
+			CounterAssetCode:   record.CounterAssetCode,
+			CounterAssetIssuer: record.CounterAssetIssuer,
+			RetentionSeconds:   record.RetentionSeconds,
+		})
+	}
+
+	return response, nil
+}
+
+func (handler TradeRetentionPoliciesHandler) change(r *http.Request) error {
+	qp := TradeRetentionPolicyQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return err
+	}
+
+	// Validate guarantees both assets are present.
+	base, _ := qp.Base()
+	counter, _ := qp.Counter()
+
+	baseAssetID, err := handler.assetID(*base, "base_asset")
+	if err != nil {
+		return err
+	}
+	counterAssetID, err := handler.assetID(*counter, "counter_asset")
+	if err != nil {
+		return err
+	}
+
+	if r.Method == http.MethodDelete {
+		_, err = handler.HistoryQ.RemoveTradeRetentionPolicy(baseAssetID, counterAssetID)
+		return err
+	}
+
+	return handler.HistoryQ.UpsertTradeRetentionPolicy(
+		baseAssetID,
+		counterAssetID,
+		gTime.Duration(qp.RetentionSeconds)*gTime.Second,
+	)
+}
+
+// assetID returns the history id of asset. Assets which were never ingested
+// can't have trades so a policy for them is rendered as not found.
+func (handler TradeRetentionPoliciesHandler) assetID(asset xdr.Asset, field string) (int64, error) {
+	id, err := handler.HistoryQ.GetAssetID(asset)
+	if handler.HistoryQ.NoRows(err) {
+		return 0, problem.NewProblemWithInvalidField(
+			problem.NotFound,
+			field,
+			errors.New("not found"),
+		)
+	}
+	return id, err
+}
@@ -0,0 +1,185 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+func TestTradesQueryAssets(t *testing.T) {
+	issuer := "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+
+	testCases := []struct {
+		desc                 string
+		urlParams            map[string]string
+		expectedBase         *xdr.Asset
+		expectedCounter      *xdr.Asset
+		expectedInvalidField string
+		expectedErr          string
+	}{
+		{
+			desc:      "no assets",
+			urlParams: map[string]string{},
+		},
+		{
+			desc: "asset type, code and issuer",
+			urlParams: map[string]string{
+				"base_asset_type":      "credit_alphanum4",
+				"base_asset_code":      "USD",
+				"base_asset_issuer":    issuer,
+				"counter_asset_type":   "native",
+				"counter_asset_code":   "",
+				"counter_asset_issuer": "",
+			},
+			expectedBase:    &usd,
+			expectedCounter: &native,
+		},
+		{
+			desc: "canonical representation",
+			urlParams: map[string]string{
+				"base":    "EUR:" + issuer,
+				"counter": "native",
+			},
+			expectedBase:    &euro,
+			expectedCounter: &native,
+		},
+		{
+			desc: "canonical representation and asset type",
+			urlParams: map[string]string{
+				"base":                 "EUR:" + issuer,
+				"counter":              "native",
+				"counter_asset_type":   "credit_alphanum4",
+				"counter_asset_code":   "USD",
+				"counter_asset_issuer": issuer,
+			},
+			expectedInvalidField: "counter_asset_type",
+			expectedErr:          "Ambiguous parameter, you can't include both `counter` and `counter_asset_type`. Remove all parameters of the form `counter_`",
+		},
+		{
+			desc: "invalid canonical representation",
+			urlParams: map[string]string{
+				"base":    "EUR",
+				"counter": "native",
+			},
+			expectedInvalidField: "base",
+			expectedErr:          "Asset must be the string \"native\" or a string of the form \"Code:IssuerAccountID\" for issued assets.",
+		},
+		{
+			desc: "liquidity pool shares",
+			urlParams: map[string]string{
+				"base":               "native",
+				"counter_asset_type": "liquidity_pool_shares",
+			},
+			expectedInvalidField: "counter_asset_type",
+			expectedErr:          "liquidity pool shares are not supported by this endpoint",
+		},
+		{
+			desc: "native asset with a code",
+			urlParams: map[string]string{
+				"base_asset_type":    "native",
+				"base_asset_code":    "XLM",
+				"counter_asset_type": "native",
+			},
+			expectedInvalidField: "base_asset_code",
+			expectedErr:          "native asset does not have a code",
+		},
+		{
+			desc: "asset code without asset type",
+			urlParams: map[string]string{
+				"base_asset_code": "USD",
+				"counter":         "native",
+			},
+			expectedInvalidField: "base_asset_type",
+			expectedErr:          "Missing parameter",
+		},
+		{
+			desc: "unknown asset type",
+			urlParams: map[string]string{
+				"base_asset_type": "credit_alphanum8",
+				"counter":         "native",
+			},
+			expectedInvalidField: "base_asset_type",
+			expectedErr:          "invalid asset type: was not one of 'native', 'credit_alphanum4', 'credit_alphanum12'",
+		},
+		{
+			desc: "only one asset",
+			urlParams: map[string]string{
+				"base": "native",
+			},
+			expectedInvalidField: "base_asset_type,counter_asset_type",
+			expectedErr:          "this endpoint supports asset pairs but only one asset supplied",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tt := assert.New(t)
+			r := makeTestActionRequest("/", tc.urlParams)
+			qp := TradesQuery{}
+			err := getParams(&qp, r)
+
+			if len(tc.expectedInvalidField) == 0 {
+				tt.NoError(err)
+				base, baseErr := qp.Base()
+				tt.NoError(baseErr)
+				counter, counterErr := qp.Counter()
+				tt.NoError(counterErr)
+				tt.Equal(tc.expectedBase, base)
+				tt.Equal(tc.expectedCounter, counter)
+			} else if tt.IsType(&problem.P{}, err) {
+				p := err.(*problem.P)
+				tt.Equal("bad_request", p.Type)
+				tt.Equal(tc.expectedInvalidField, p.Extras["invalid_field"])
+				tt.Equal(tc.expectedErr, p.Extras["reason"])
+			}
+		})
+	}
+}
+
+func TestTradesQueryTimeRange(t *testing.T) {
+	assert.NoError(t, TradesQuery{StartTime: 1000, EndTime: 2000}.Validate())
+
+	err := TradesQuery{StartTime: 2000, EndTime: 1000}.Validate()
+	p, ok := err.(*problem.P)
+	if assert.True(t, ok) {
+		assert.Equal(t, 400, p.Status)
+		assert.Equal(t, "end_time", p.Extras["invalid_field"])
+		assert.Equal(t, "End time must be greater than start time", p.Extras["reason"])
+	}
+}
+
+func TestTradesQueryPagingTokenVersion(t *testing.T) {
+	assert.False(t, TradesQuery{}.PagingBySequence())
+	assert.False(t, TradesQuery{PagingTokenVersion: 1}.PagingBySequence())
+	assert.True(t, TradesQuery{PagingTokenVersion: 2}.PagingBySequence())
+
+	err := TradesQuery{PagingTokenVersion: 3}.Validate()
+	p, ok := err.(*problem.P)
+	if assert.True(t, ok) {
+		assert.Equal(t, "paging_token_version", p.Extras["invalid_field"])
+		assert.Equal(t, "Paging token version must be 1 or 2", p.Extras["reason"])
+	}
+}
+
+func TestGetTradesPageQuery(t *testing.T) {
+	qp := TradesQuery{PagingTokenVersion: 2}
+
+	// sequences are not checked against the history range
+	pq, err := getTradesPageQuery(makeRequest(t, map[string]string{"cursor": "12"}, map[string]string{}, nil), qp)
+	assert.NoError(t, err)
+	assert.Equal(t, "12", pq.Cursor)
+
+	_, err = getTradesPageQuery(makeRequest(t, map[string]string{"cursor": "12-1"}, map[string]string{}, nil), qp)
+	if assert.IsType(t, &problem.P{}, err) {
+		assert.Equal(t, "cursor", err.(*problem.P).Extras["invalid_field"])
+	}
+
+	_, err = getTradesPageQuery(makeRequest(t, map[string]string{"cursor": "now"}, map[string]string{}, nil), qp)
+	if assert.IsType(t, &problem.P{}, err) {
+		assert.Equal(t, "cursor", err.(*problem.P).Extras["invalid_field"])
+		assert.Equal(t, "now is not supported with version 2 paging tokens", err.(*problem.P).Extras["reason"])
+	}
+}
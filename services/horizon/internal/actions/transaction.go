@@ -1,13 +1,15 @@
 package actions
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/stellar/go/protocols/horizon"
-	"github.com/stellar/go/services/horizon/internal/context"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
 	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/resourceadapter"
+	"github.com/stellar/go/services/horizon/internal/timing"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/hal"
 	supportProblem "github.com/stellar/go/support/render/problem"
@@ -32,7 +34,7 @@ func (handler GetTransactionByHashHandler) GetResource(w HeaderWriter, r *http.R
 		return nil, err
 	}
 
-	historyQ, err := context.HistoryQFromRequest(r)
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
 	if err != nil {
 		return nil, err
 	}
@@ -58,6 +60,7 @@ type TransactionsQuery struct {
 	AccountID                 string `schema:"account_id" valid:"accountID,optional"`
 	IncludeFailedTransactions bool   `schema:"include_failed" valid:"-"`
 	LedgerID                  uint32 `schema:"ledger_id" valid:"-"`
+	MinTrades                 uint32 `schema:"min_trades" valid:"-"`
 }
 
 // Validate runs extra validations on query parameters
@@ -89,7 +92,7 @@ type GetTransactionsHandler struct {
 func (handler GetTransactionsHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
 	ctx := r.Context()
 
-	pq, err := GetPageQuery(r)
+	pq, err := GetPageQuery(r, Int64Cursor)
 	if err != nil {
 		return nil, err
 	}
@@ -105,12 +108,17 @@ func (handler GetTransactionsHandler) GetResourcePage(w HeaderWriter, r *http.Re
 		return nil, err
 	}
 
-	historyQ, err := context.HistoryQFromRequest(r)
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
 	if err != nil {
 		return nil, err
 	}
 
-	records, err := loadTransactionRecords(historyQ, qp.AccountID, int32(qp.LedgerID), qp.IncludeFailedTransactions, pq)
+	records, err := loadTransactionRecords(ctx, historyQ, history.TransactionsFilter{
+		AccountID:      qp.AccountID,
+		LedgerSequence: int32(qp.LedgerID),
+		IncludeFailed:  qp.IncludeFailedTransactions,
+		MinTrades:      int32(qp.MinTrades),
+	}, pq)
 	if err != nil {
 		return nil, errors.Wrap(err, "loading transaction records")
 	}
@@ -129,41 +137,26 @@ func (handler GetTransactionsHandler) GetResourcePage(w HeaderWriter, r *http.Re
 	return response, nil
 }
 
-// loadTransactionRecords returns a slice of transaction records of an
-// account/ledger identified by accountID/ledgerID based on pq and
-// includeFailedTx.
-func loadTransactionRecords(hq *history.Q, accountID string, ledgerID int32, includeFailedTx bool, pq db2.PageQuery) ([]history.Transaction, error) {
-	if accountID != "" && ledgerID != 0 {
+// loadTransactionRecords returns a slice of transaction records matching
+// filter based on pq.
+func loadTransactionRecords(ctx context.Context, hq *history.Q, filter history.TransactionsFilter, pq db2.PageQuery) ([]history.Transaction, error) {
+	if filter.AccountID != "" && filter.LedgerSequence != 0 {
 		return nil, errors.New("conflicting exclusive fields are present: account_id and ledger_id")
 	}
 
-	var records []history.Transaction
-
-	txs := hq.Transactions()
-	switch {
-	case accountID != "":
-		txs.ForAccount(accountID)
-	case ledgerID > 0:
-		txs.ForLedger(ledgerID)
-	}
-
-	if includeFailedTx {
-		txs.IncludeFailed()
-	}
-
-	err := txs.Page(pq).Select(&records)
+	records, err := hq.GetTransactions(ctx, filter, pq)
 	if err != nil {
 		return nil, errors.Wrap(err, "executing transaction records query")
 	}
 
 	for _, t := range records {
-		if !includeFailedTx {
+		if !filter.IncludeFailed {
 			if !t.Successful {
 				return nil, errors.Errorf("Corrupted data! `include_failed=false` but returned transaction is failed: %s", t.TransactionHash)
 			}
 
 			var resultXDR xdr.TransactionResult
-			err = xdr.SafeUnmarshalBase64(t.TxResult, &resultXDR)
+			err = timing.UnmarshalXDR(ctx, t.TxResult, &resultXDR)
 			if err != nil {
 				return nil, errors.Wrap(err, "unmarshalling tx result")
 			}
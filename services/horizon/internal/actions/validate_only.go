@@ -0,0 +1,135 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/go-chi/chi"
+
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+)
+
+// ParamValidateOnly is the query parameter of list end-points which, when
+// true, validates the request and returns its QueryDescription instead of
+// executing it.
+const ParamValidateOnly = "validate_only"
+
+// Cost classes of the queries described by QueryDescription.
+const (
+	QueryCostLow    = "low"
+	QueryCostMedium = "medium"
+	QueryCostHigh   = "high"
+)
+
+// QueryDescription is the canonical form of a validated list request: its
+// route, the filters parsed from its parameters and its page. CostClass is
+// an estimate of the cost of the query based on the size of the page.
+type QueryDescription struct {
+	Route     string            `json:"route"`
+	Params    map[string]string `json:"params"`
+	Cursor    string            `json:"cursor"`
+	Order     string            `json:"order"`
+	Limit     uint64            `json:"limit"`
+	CostClass string            `json:"cost_class"`
+
+	pageLimit PageLimit
+}
+
+type queryDescriptionContextKey struct{}
+
+// DescribePageQuery runs getPage, the GetResourcePage method of a page
+// action, without querying the history database and returns the description
+// of the query it validated.
+func DescribePageQuery(
+	w HeaderWriter,
+	r *http.Request,
+	getPage func(HeaderWriter, *http.Request) ([]hal.Pageable, error),
+) (QueryDescription, error) {
+	description := &QueryDescription{
+		Params:    map[string]string{},
+		pageLimit: getPageLimit(r),
+	}
+	if routeContext := chi.RouteContext(r.Context()); routeContext != nil {
+		description.Route = routeContext.RoutePattern()
+	}
+
+	ctx := context.WithValue(r.Context(), queryDescriptionContextKey{}, description)
+	ctx = horizonContext.WithValidateOnly(ctx)
+	if _, err := getPage(w, r.WithContext(ctx)); err != nil && errors.Cause(err) != horizonContext.ErrValidateOnly {
+		return QueryDescription{}, err
+	}
+
+	description.CostClass = description.costClass()
+	return *description, nil
+}
+
+// costClass is low for pages up to the default page size of the route
+// group and high for pages larger than db2.MaxPageSize, which are only
+// allowed by custom page limits. Joined resources raise the class.
+func (d *QueryDescription) costClass() string {
+	classes := []string{QueryCostLow, QueryCostMedium, QueryCostHigh}
+	class := 0
+	switch {
+	case d.Limit > db2.MaxPageSize:
+		class = 2
+	case d.Limit > d.pageLimit.Default:
+		class = 1
+	}
+	if d.Params["join"] != "" && class < 2 {
+		class++
+	}
+	return classes[class]
+}
+
+func queryDescriptionFromRequest(r *http.Request) *QueryDescription {
+	description, _ := r.Context().Value(queryDescriptionContextKey{}).(*QueryDescription)
+	return description
+}
+
+// describePageQuery records pq in the description of the request, if any.
+func describePageQuery(r *http.Request, pq db2.PageQuery) {
+	if description := queryDescriptionFromRequest(r); description != nil {
+		description.Cursor = pq.Cursor
+		description.Order = pq.Order
+		description.Limit = pq.Limit
+	}
+}
+
+// describeParam records a parameter in the description of the request, if
+// any. Empty values are omitted.
+func describeParam(r *http.Request, name, value string) {
+	if description := queryDescriptionFromRequest(r); description != nil && value != "" {
+		description.Params[name] = value
+	}
+}
+
+// describeParams records the non-zero fields of a query struct filled by
+// getParams in the description of the request, if any.
+func describeParams(r *http.Request, params interface{}) {
+	if queryDescriptionFromRequest(r) == nil {
+		return
+	}
+	describeFields(r, reflect.Indirect(reflect.ValueOf(params)))
+}
+
+func describeFields(r *http.Request, v reflect.Value) {
+	qt := v.Type()
+	for i := 0; i < qt.NumField(); i++ {
+		f := qt.Field(i)
+		// Query structs can have embedded query structs
+		if f.Type.Kind() == reflect.Struct {
+			describeFields(r, v.Field(i))
+			continue
+		}
+		tag, ok := f.Tag.Lookup("schema")
+		if !ok || v.Field(i).IsZero() {
+			continue
+		}
+		describeParam(r, tag, fmt.Sprint(v.Field(i).Interface()))
+	}
+}
@@ -0,0 +1,92 @@
+package actions
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
+)
+
+func TestDescribePageQuery(t *testing.T) {
+	account := "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"
+	r := makeRequest(
+		t,
+		map[string]string{"include_failed": "true", "limit": "50", "order": "desc", "validate_only": "true"},
+		map[string]string{"account_id": account},
+		nil,
+	)
+	chi.RouteContext(r.Context()).RoutePatterns = []string{"/accounts/{account_id}/transactions"}
+
+	description, err := DescribePageQuery(nil, r, GetTransactionsHandler{}.GetResourcePage)
+	assert.NoError(t, err)
+	assert.Equal(t, "/accounts/{account_id}/transactions", description.Route)
+	assert.Equal(t, map[string]string{"account_id": account, "include_failed": "true"}, description.Params)
+	assert.Equal(t, "", description.Cursor)
+	assert.Equal(t, "desc", description.Order)
+	assert.Equal(t, uint64(50), description.Limit)
+	assert.Equal(t, QueryCostMedium, description.CostClass)
+
+	// the database can't be queried
+	_, err = horizonContext.HistoryQFromRequest(r.WithContext(horizonContext.WithValidateOnly(r.Context())))
+	assert.Equal(t, horizonContext.ErrValidateOnly, err)
+}
+
+func TestDescribePageQueryInvalid(t *testing.T) {
+	r := makeRequest(
+		t,
+		map[string]string{"account_id": "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY", "ledger_id": "10"},
+		map[string]string{},
+		nil,
+	)
+
+	_, err := DescribePageQuery(nil, r, GetTransactionsHandler{}.GetResourcePage)
+	assert.IsType(t, &problem.P{}, err)
+	assert.Equal(t, "filters", err.(*problem.P).Extras["invalid_field"])
+}
+
+func TestDescribePageQueryValidatesAllParams(t *testing.T) {
+	for _, testCase := range []struct {
+		query   map[string]string
+		getPage func(HeaderWriter, *http.Request) ([]hal.Pageable, error)
+		field   string
+	}{
+		{map[string]string{"type": "foo"}, GetEffectsHandler{}.GetResourcePage, "type"},
+		{map[string]string{"merges_as_payments": "true"}, GetOperationsHandler{}.GetResourcePage, "merges_as_payments"},
+	} {
+		r := makeRequest(t, testCase.query, map[string]string{}, nil)
+
+		_, err := DescribePageQuery(nil, r, testCase.getPage)
+		if assert.IsType(t, &problem.P{}, err) {
+			assert.Equal(t, testCase.field, err.(*problem.P).Extras["invalid_field"])
+		}
+	}
+}
+
+func TestQueryDescriptionCostClass(t *testing.T) {
+	pageLimit := PageLimit{Default: db2.DefaultPageSize, Max: 1000}
+	for _, testCase := range []struct {
+		limit    uint64
+		join     string
+		expected string
+	}{
+		{10, "", QueryCostLow},
+		{10, "transactions", QueryCostMedium},
+		{200, "", QueryCostMedium},
+		{200, "transactions", QueryCostHigh},
+		{1000, "", QueryCostHigh},
+		{1000, "transactions", QueryCostHigh},
+	} {
+		description := QueryDescription{
+			Params:    map[string]string{"join": testCase.join},
+			Limit:     testCase.limit,
+			pageLimit: pageLimit,
+		}
+		assert.Equal(t, testCase.expected, description.costClass(), "limit %d join %q", testCase.limit, testCase.join)
+	}
+}
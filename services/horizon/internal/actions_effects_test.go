@@ -94,12 +94,48 @@ func TestEffectActions_Index(t *testing.T) {
 			ht.Assert.PageOf(3, w.Body)
 		}
 
+		// filtered by time range, ledger 2 closed at 1572527985000 and
+		// ledger 3 at 1572527986000
+		w = ht.Get("/effects?start_time=1572527986000")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(2, w.Body)
+		}
+
+		w = ht.Get("/effects?end_time=1572527986000")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(9, w.Body)
+		}
+
+		w = ht.Get("/effects?start_time=1572527985000&end_time=1572527986000")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(9, w.Body)
+		}
+
+		w = ht.Get("/effects?start_time=1572527987000")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(0, w.Body)
+		}
+
+		w = ht.Get("/accounts/GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU/effects?start_time=1572527986000")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(1, w.Body)
+		}
+
+		w = ht.Get("/effects?start_time=1572527986000&end_time=1572527985000")
+		ht.Assert.Equal(400, w.Code)
+
 		// Check extra params
 		w = ht.Get("/ledgers/100/effects?account_id=GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
 		ht.Assert.Equal(400, w.Code)
 		w = ht.Get("/accounts/GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU/effects?ledger_id=5")
 		ht.Assert.Equal(400, w.Code)
 
+		// malformed cursors
+		w = ht.Get("/effects?cursor=8589938689-1-1")
+		ht.Assert.Equal(400, w.Code)
+		w = ht.Get("/effects?cursor=8589938689-99999999999999999999")
+		ht.Assert.Equal(400, w.Code)
+
 		// before history
 		ht.ReapHistory(1)
 		w = ht.Get("/effects?order=desc&cursor=8589938689-1")
@@ -129,6 +165,45 @@ func TestEffectActions_Index(t *testing.T) {
 	})
 }
 
+func TestEffectActions_IncludeTransactions(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	w := ht.Get("/effects?join=accounts")
+	ht.Assert.Equal(400, w.Code)
+
+	w = ht.Get("/effects?limit=20")
+	if ht.Assert.Equal(200, w.Code) {
+		var result []effects.Base
+		ht.UnmarshalPage(w.Body, &result)
+		for _, effect := range result {
+			ht.Assert.Nil(effect.Transaction)
+		}
+	}
+
+	w = ht.Get("/transactions/2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d/effects?join=transactions")
+	if ht.Assert.Equal(200, w.Code) {
+		var result []effects.Base
+		ht.UnmarshalPage(w.Body, &result)
+		ht.Assert.Len(result, 3)
+		for _, effect := range result {
+			if ht.Assert.NotNil(effect.Transaction) {
+				ht.Assert.Equal("2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d", effect.Transaction.Hash)
+			}
+		}
+	}
+
+	w = ht.Get("/effects?limit=20&join=transactions")
+	if ht.Assert.Equal(200, w.Code) {
+		var result []effects.Base
+		ht.UnmarshalPage(w.Body, &result)
+		ht.Assert.Len(result, 11)
+		for _, effect := range result {
+			ht.Assert.NotNil(effect.Transaction)
+		}
+	}
+}
+
 func TestEffectsForFeeBumpTransaction(t *testing.T) {
 	ht := StartHTTPTestWithoutScenario(t)
 	defer ht.Finish()
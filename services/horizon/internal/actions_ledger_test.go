@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
 )
 
 func TestLedgerActions_Index(t *testing.T) {
@@ -73,3 +74,59 @@ func TestLedgerActions_Show(t *testing.T) {
 	w = ht.Get("/ledgers/1")
 	ht.Assert.Equal(410, w.Code)
 }
+
+func TestLedgerActions_Manifest(t *testing.T) {
+	ht := StartHTTPTest(t, "kahuna")
+	defer ht.Finish()
+	q := &history.Q{ht.HorizonSession()}
+
+	w := ht.Get("/ledgers/24/manifest")
+	ht.Assert.Equal(404, w.Code)
+
+	ht.Require.NoError(q.UpdateLedgerManifest(24))
+	var manifest history.LedgerManifest
+	ht.Require.NoError(q.LedgerManifest(&manifest, 24))
+
+	w = ht.Get("/ledgers/24/manifest")
+	ht.Assert.Equal(200, w.Code)
+
+	var result horizon.LedgerManifest
+	err := json.Unmarshal(w.Body.Bytes(), &result)
+	if ht.Assert.NoError(err) {
+		ht.Assert.Equal(int32(24), result.Sequence)
+		ht.Assert.Equal(manifest.TradesHash, result.TradesHash)
+		ht.Assert.Equal(manifest.PaymentsHash, result.PaymentsHash)
+		ht.Assert.Contains(result.Links.Self.Href, "/ledgers/24/manifest")
+	}
+}
+
+func TestLedgerActions_Export(t *testing.T) {
+	ht := StartHTTPTest(t, "kahuna")
+	defer ht.Finish()
+	q := &history.Q{ht.HorizonSession()}
+
+	var export history.LedgerExport
+	ht.Require.NoError(q.LedgerExport(&export, 24))
+
+	w := ht.Get("/ledgers/24/export")
+	ht.Assert.Equal(200, w.Code)
+
+	var result struct {
+		Ledger       horizon.Ledger        `json:"ledger"`
+		Transactions []horizon.Transaction `json:"transactions"`
+		Operations   []json.RawMessage     `json:"operations"`
+		Trades       []horizon.Trade       `json:"trades"`
+		Effects      []json.RawMessage     `json:"effects"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &result)
+	if ht.Assert.NoError(err) {
+		ht.Assert.Equal(int32(24), result.Ledger.Sequence)
+		ht.Assert.Len(result.Transactions, len(export.Transactions))
+		ht.Assert.Len(result.Operations, len(export.Operations))
+		ht.Assert.Len(result.Trades, len(export.Trades))
+		ht.Assert.Len(result.Effects, len(export.Effects))
+	}
+
+	w = ht.Get("/ledgers/1000/export")
+	ht.Assert.Equal(404, w.Code)
+}
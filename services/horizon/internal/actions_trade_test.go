@@ -162,6 +162,43 @@ func TestTradeActions_Index(t *testing.T) {
 	}
 }
 
+func TestTradeActions_IncludeTransactions(t *testing.T) {
+	ht := StartHTTPTest(t, "trades")
+	defer ht.Finish()
+
+	w := ht.Get("/trades?join=accounts")
+	ht.Assert.Equal(400, w.Code)
+
+	var records []horizon.Trade
+	w = ht.Get("/trades")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.UnmarshalPage(w.Body, &records)
+		for _, record := range records {
+			ht.Assert.Nil(record.Transaction)
+		}
+	}
+
+	w = ht.Get("/trades?join=transactions")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(2, w.Body)
+		ht.UnmarshalPage(w.Body, &records)
+
+		hq := history.Q{Session: ht.HorizonSession()}
+		for _, record := range records {
+			if !ht.Assert.NotNil(record.Transaction) {
+				continue
+			}
+
+			operationID, err := strconv.ParseInt(strings.Split(record.PT, "-")[0], 10, 64)
+			ht.Require.NoError(err)
+			operation, _, err := hq.OperationByID(false, operationID)
+			ht.Require.NoError(err)
+			ht.Assert.Equal(operation.TransactionHash, record.Transaction.Hash)
+			ht.Assert.Equal(strconv.FormatInt(operation.TransactionID, 10), record.Transaction.PT)
+		}
+	}
+}
+
 // setAssetQuery adds an asset filter with a given prefix to a query
 func setAssetQuery(q *url.Values, prefix string, asset xdr.Asset) {
 	var assetType, assetCode, assetFilter string
@@ -271,6 +308,8 @@ func TestTradeActions_Aggregation(t *testing.T) {
 			ht.Assert.Equal(int64(1), records[0].TradeCount)
 			ht.Assert.Equal("0.0000100", records[0].BaseVolume)
 			ht.Assert.Equal("1.0000000", records[0].Average)
+			ht.Assert.Equal("1.0000000", records[0].VWAP)
+			ht.Assert.Equal("1.0000000", records[0].AveragePrice)
 		}
 	}
 
@@ -479,6 +518,70 @@ func TestTradeActions_AggregationOrdering(t *testing.T) {
 	}
 }
 
+// TestTradeActions_AggregationAveragePrices ensures that avg, vwap and avg_price are computed
+// from the amounts, from the prices weighted by the base amounts and from the prices alone.
+func TestTradeActions_AggregationAveragePrices(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	seller := GetTestAccount()
+	buyer := GetTestAccount()
+	ass1 := GetTestAsset("usd")
+	ass2 := GetTestAsset("euro")
+
+	dbQ := &Q{ht.HorizonSession()}
+	ht.Require.NoError(IngestTestTradeAtPrice(
+		dbQ, ass1, ass2, seller, buyer, 100, 200, xdr.Price{N: 2, D: 1}, 0, 1))
+	// the second trade is filled at a better rate than its price
+	ht.Require.NoError(IngestTestTradeAtPrice(
+		dbQ, ass1, ass2, seller, buyer, 300, 1000, xdr.Price{N: 3, D: 1}, 0, 2))
+
+	q := make(url.Values)
+	setAssetQuery(&q, "base_", ass1)
+	setAssetQuery(&q, "counter_", ass2)
+	q.Add("start_time", "0")
+	q.Add("end_time", "60000")
+	q.Add("resolution", "60000")
+
+	var records []horizon.TradeAggregation
+	w := ht.GetWithParams("/trade_aggregations", q)
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Equal("0.0000400", records[0].BaseVolume)
+		ht.Assert.Equal("0.0001200", records[0].CounterVolume)
+		// 1200 / 400
+		ht.Assert.Equal("3.0000000", records[0].Average)
+		// (2*100 + 3*300) / 400
+		ht.Assert.Equal("2.7500000", records[0].VWAP)
+		// (2 + 3) / 2
+		ht.Assert.Equal("2.5000000", records[0].AveragePrice)
+	}
+
+	// the prices of the reversed pair are inverted and weighted by the
+	// amounts of its base asset
+	q = make(url.Values)
+	setAssetQuery(&q, "base_", ass2)
+	setAssetQuery(&q, "counter_", ass1)
+	q.Add("start_time", "0")
+	q.Add("end_time", "60000")
+	q.Add("resolution", "60000")
+
+	w = ht.GetWithParams("/trade_aggregations", q)
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+		ht.UnmarshalPage(w.Body, &records)
+		ht.Assert.Equal("0.0001200", records[0].BaseVolume)
+		ht.Assert.Equal("0.0000400", records[0].CounterVolume)
+		// 400 / 1200
+		ht.Assert.Equal("0.3333333", records[0].Average)
+		// (1/2*200 + 1/3*1000) / 1200
+		ht.Assert.Equal("0.3611111", records[0].VWAP)
+		// (1/2 + 1/3) / 2
+		ht.Assert.Equal("0.4166667", records[0].AveragePrice)
+	}
+}
+
 func assertOfferType(ht *HTTPT, offerId string, idType OfferIDType) {
 	offerIdInt64, _ := strconv.ParseInt(offerId, 10, 64)
 	_, offerType := DecodeOfferID(offerIdInt64)
@@ -234,6 +234,10 @@ func TestTransactionActions_Index(t *testing.T) {
 	w = ht.Get("/transactions?limit=0")
 	ht.Assert.Equal(400, w.Code)
 
+	// transactions cursors are a single number
+	w = ht.Get("/transactions?cursor=8589938689-1")
+	ht.Assert.Equal(400, w.Code)
+	ht.Assert.Contains(w.Body.String(), `"invalid_field": "cursor"`)
 }
 
 func TestTransactionActions_Post(t *testing.T) {
@@ -275,6 +279,37 @@ func TestTransactionActions_Post(t *testing.T) {
 	// existing transaction
 	w := ht.Post("/transactions", form)
 	ht.Assert.Equal(200, w.Code)
+
+	// submission is rejected in read-only mode
+	ht.App.readOnly.Set(true)
+	w = ht.Post("/transactions", form)
+	ht.Assert.Equal(503, w.Code)
+	ht.Assert.Contains(w.Body.String(), "read_only")
+
+	ht.App.readOnly.Set(false)
+	w = ht.Post("/transactions", form)
+	ht.Assert.Equal(200, w.Code)
+
+	// the results of a batch are in the order of its envelopes
+	w = ht.Post("/transactions/batch", url.Values{"tx": []string{txStr, "not an envelope"}})
+	ht.Assert.Equal(200, w.Code)
+	var batch horizon.TransactionBatch
+	ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &batch))
+	if ht.Assert.Len(batch.Records, 2) {
+		ht.Assert.True(batch.Records[0].Successful)
+		ht.Assert.Equal(batch.Records[0].Hash, batch.Records[0].Transaction.Hash)
+		ht.Assert.Nil(batch.Records[0].Problem)
+
+		ht.Assert.False(batch.Records[1].Successful)
+		ht.Assert.Nil(batch.Records[1].Transaction)
+		ht.Assert.Equal(400, batch.Records[1].Problem.Status)
+		ht.Assert.Contains(batch.Records[1].Problem.Type, "transaction_malformed")
+	}
+
+	ht.App.readOnly.Set(true)
+	w = ht.Post("/transactions/batch", form)
+	ht.Assert.Equal(503, w.Code)
+	ht.App.readOnly.Set(false)
 }
 
 func TestTransactionActions_PostSuccessful(t *testing.T) {
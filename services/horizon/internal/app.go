@@ -3,6 +3,8 @@ package horizon
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,18 +13,25 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
 
 	"github.com/stellar/go/clients/stellarcore"
+	"github.com/stellar/go/exp/orderbook"
 	proto "github.com/stellar/go/protocols/stellarcore"
+	"github.com/stellar/go/services/horizon/ingest"
 	"github.com/stellar/go/services/horizon/internal/actions"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/expingest"
 	"github.com/stellar/go/services/horizon/internal/httpx"
+	"github.com/stellar/go/services/horizon/internal/jobs"
 	"github.com/stellar/go/services/horizon/internal/ledger"
 	"github.com/stellar/go/services/horizon/internal/logmetrics"
 	"github.com/stellar/go/services/horizon/internal/operationfeestats"
 	"github.com/stellar/go/services/horizon/internal/paths"
+	"github.com/stellar/go/services/horizon/internal/readonly"
 	"github.com/stellar/go/services/horizon/internal/reap"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
+	"github.com/stellar/go/services/horizon/internal/schemastats"
 	"github.com/stellar/go/services/horizon/internal/txsub"
 	"github.com/stellar/go/support/app"
 	"github.com/stellar/go/support/db"
@@ -30,6 +39,10 @@ import (
 	"github.com/stellar/go/support/log"
 )
 
+// replicaHealthCheckInterval is the interval of health checks of the horizon
+// database replicas.
+const replicaHealthCheckInterval = 5 * time.Second
+
 type coreSettingsStore struct {
 	sync.RWMutex
 	actions.CoreSettings
@@ -54,16 +67,24 @@ type App struct {
 	done            chan struct{}
 	config          Config
 	webServer       *httpx.Server
+	grpcServer      *grpc.Server
 	historyQ        *history.Q
+	dbPoolMonitor   *db.PoolMonitor
 	ctx             context.Context
 	cancel          func()
 	horizonVersion  string
 	coreSettings    coreSettingsStore
 	orderBookStream *expingest.OrderBookStream
+	orderBookGraph  *orderbook.OrderBookGraph
 	submitter       *txsub.System
 	paths           paths.Finder
+	pathsCache      *paths.CachedFinder
 	expingester     expingest.System
+	gapBackfiller   *expingest.GapBackfiller
 	reaper          *reap.System
+	readOnly        *readonly.Mode
+	schemaStats     *schemastats.System
+	jobs            *jobs.Scheduler
 	ticks           *time.Ticker
 
 	// metrics
@@ -111,6 +132,9 @@ func (a *App) Serve() {
 
 	go a.run()
 	go a.orderBookStream.Run(a.ctx)
+	if replicas := a.historyQ.Session.Replicas; replicas != nil {
+		go replicas.Run(a.ctx, replicaHealthCheckInterval)
+	}
 
 	// WaitGroup for all go routines. Makes sure that DB is closed when
 	// all services gracefully shutdown.
@@ -124,6 +148,21 @@ func (a *App) Serve() {
 		}()
 	}
 
+	if a.grpcServer != nil {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", a.config.GRPCPort))
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("Starting gRPC server on :%d", a.config.GRPCPort)
+		wg.Add(1)
+		go func() {
+			if err := a.grpcServer.Serve(listener); err != nil {
+				log.Errorf("gRPC server stopped: %v", err)
+			}
+			wg.Done()
+		}()
+	}
+
 	// configure shutdown signal handler
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
@@ -154,6 +193,11 @@ func (a *App) waitForDone() {
 	webShutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	a.webServer.Shutdown(webShutdownCtx)
+	if a.grpcServer != nil {
+		// streams only end when clients cancel them so they are not waited
+		// for like HTTP requests
+		a.grpcServer.Stop()
+	}
 	a.cancel()
 	if a.expingester != nil {
 		a.expingester.Shutdown()
@@ -165,7 +209,13 @@ func (a *App) waitForDone() {
 // sure all requests are first properly finished to avoid "sql: database is
 // closed" errors.
 func (a *App) CloseDB() {
+	if a.historyQ.Session.Statements != nil {
+		a.historyQ.Session.Statements.Close()
+	}
 	a.historyQ.Session.DB.Close()
+	if a.historyQ.Session.Replicas != nil {
+		a.historyQ.Session.Replicas.Close()
+	}
 }
 
 // HistoryQ returns a helper object for performing sql queries against the
@@ -177,7 +227,13 @@ func (a *App) HistoryQ() *history.Q {
 // HorizonSession returns a new session that loads data from the horizon
 // database. The returned session is bound to `ctx`.
 func (a *App) HorizonSession(ctx context.Context) *db.Session {
-	return &db.Session{DB: a.historyQ.Session.DB, Ctx: ctx}
+	return &db.Session{
+		DB:         a.historyQ.Session.DB,
+		Replicas:   a.historyQ.Session.Replicas,
+		Metrics:    a.historyQ.Session.Metrics,
+		Statements: a.historyQ.Session.Statements,
+		Ctx:        ctx,
+	}
 }
 
 // UpdateLedgerState triggers a refresh of several metrics gauges, such as open
@@ -381,7 +437,7 @@ func (a *App) DeleteUnretainedHistory() error {
 }
 
 // Tick triggers horizon to update all of it's background processes such as
-// transaction submission, metrics, ingestion and reaping.
+// transaction submission, metrics and the background jobs.
 func (a *App) Tick() {
 	var wg sync.WaitGroup
 	log.Debug("ticking app")
@@ -392,8 +448,12 @@ func (a *App) Tick() {
 	go func() { a.UpdateStellarCoreInfo(); wg.Done() }()
 	wg.Wait()
 
-	wg.Add(2)
-	go func() { a.reaper.Tick(); wg.Done() }()
+	// jobs are not waited for so that a long job (ex. reaping history)
+	// doesn't delay the jobs due on the next ticks, the scheduler doesn't
+	// start a job again while it's running
+	go a.jobs.Tick(a.ctx)
+
+	wg.Add(1)
 	go func() { a.submitter.Tick(a.ctx); wg.Done() }()
 	wg.Wait()
 
@@ -419,6 +479,9 @@ func (a *App) init() error {
 	// stellarCoreInfo
 	a.UpdateStellarCoreInfo()
 
+	// read-only mode
+	a.readOnly = readonly.NewMode(a.config.ReadOnly)
+
 	// horizon-db and core-db
 	mustInitHorizonDB(a)
 
@@ -434,6 +497,12 @@ func (a *App) init() error {
 	// reaper
 	a.reaper = reap.New(a.config.HistoryRetentionCount, a.HorizonSession(context.Background()))
 
+	// schema stats
+	a.schemaStats = schemastats.New(a.HorizonSession(context.Background()))
+
+	// background jobs
+	initJobs(a)
+
 	// metrics and log.metrics
 	a.prometheusRegistry = prometheus.NewRegistry()
 	for _, meter := range *logmetrics.DefaultMetrics {
@@ -449,6 +518,32 @@ func (a *App) init() error {
 	// txsub.metrics
 	initTxSubMetrics(a)
 
+	// schema.metrics
+	a.schemaStats.RegisterMetrics(a.prometheusRegistry)
+
+	// jobs.metrics
+	a.jobs.RegisterMetrics(a.prometheusRegistry)
+
+	// grpc
+	if err := initGRPCServer(a); err != nil {
+		return err
+	}
+
+	pageLimits, err := actions.ParsePageLimits(a.config.PageLimits)
+	if err != nil {
+		return err
+	}
+
+	slowConsumerPolicy, err := sse.ParseSlowConsumerPolicy(a.config.SSESlowConsumerPolicy)
+	if err != nil {
+		return err
+	}
+
+	latestLedgerSource, err := ledger.ParseLatestSource(a.config.LatestLedgerSource)
+	if err != nil {
+		return err
+	}
+
 	routerConfig := httpx.RouterConfig{
 		DBSession:          a.historyQ.Session,
 		TxSubmitter:        a.submitter,
@@ -463,9 +558,29 @@ func (a *App) init() error {
 		CoreGetter:         a,
 		HorizonVersion:     a.horizonVersion,
 		FriendbotURL:       a.config.FriendbotURL,
+		ReadOnly:           a.readOnly,
+		SchemaStats:        a.schemaStats,
+		Jobs:               a.jobs,
+		DBPoolMonitor:      a.dbPoolMonitor,
+		ShedLoad:           a.config.HorizonDBShedLoad,
+		InstanceID:         a.config.InstanceID,
+		AdminDebugToken:    a.config.AdminDebugToken,
+		PageLimits:         pageLimits,
+		GapBackfiller:      a.gapBackfiller,
+
+		SSEBufferSize:         a.config.SSEBufferSize,
+		SSESlowConsumerPolicy: slowConsumerPolicy,
+		LatestLedgerSource:    latestLedgerSource,
+		EnableGraphQL:         a.config.EnableGraphQL,
+		OrderBookGraph:        a.orderBookGraph,
+		ReadinessMaxLedgerLag: a.config.ReadinessMaxLedgerLag,
+
+		MaxTransactionBatchSize: a.config.MaxTransactionBatchSize,
+	}
+	if a.expingester != nil {
+		routerConfig.EventBus = ingest.Events
 	}
 
-	var err error
 	config := httpx.ServerConfig{
 		Port:      uint16(a.config.Port),
 		AdminPort: uint16(a.config.AdminPort),
@@ -11,10 +11,30 @@ import (
 // Config is the configuration for horizon.  It gets populated by the
 // app's main function and is provided to NewApp.
 type Config struct {
-	DatabaseURL        string
-	HistoryArchiveURLs []string
-	Port               uint
-	AdminPort          uint
+	DatabaseURL string
+	// ReplicaDatabaseURLs are read replicas of the horizon database. Requests
+	// reading history are served from replicas when available.
+	ReplicaDatabaseURLs []string
+	HistoryArchiveURLs  []string
+	Port                uint
+	AdminPort           uint
+	// GRPCPort is the port of the gRPC server streaming ledgers, transactions
+	// and trades, it's disabled when 0.
+	GRPCPort uint
+	// GRPCMaxStreams and GRPCMaxStreamsPerIP limit the concurrent streams of
+	// the gRPC server and of a single client IP address, they are not
+	// limited when 0.
+	GRPCMaxStreams      int
+	GRPCMaxStreamsPerIP int
+	// InstanceID identifies this horizon instance in the X-Horizon-Instance
+	// header of responses.
+	InstanceID string
+	// AdminDebugToken is the bearer token required by the admin debugging
+	// end-points, they are disabled when it's empty.
+	AdminDebugToken string
+	// PageLimits is a comma separated list of the default and max page sizes
+	// of route groups in the group=default:max format.
+	PageLimits string
 
 	EnableCaptiveCoreIngestion bool
 	StellarCoreBinaryPath      string
@@ -22,11 +42,31 @@ type Config struct {
 	StellarCoreDatabaseURL     string
 	StellarCoreURL             string
 	RemoteCaptiveCoreURL       string
+	// CaptiveCoreReadAheadLedgers and CaptiveCoreReadAheadBytes limit the
+	// ledgers, and the size of their meta, read from captive stellar-core
+	// ahead of ingestion. The size isn't limited when
+	// CaptiveCoreReadAheadBytes is 0.
+	CaptiveCoreReadAheadLedgers uint
+	CaptiveCoreReadAheadBytes   uint
 
 	// MaxDBConnections has a priority over all 4 values below.
 	MaxDBConnections            int
 	HorizonDBMaxOpenConnections int
 	HorizonDBMaxIdleConnections int
+	// HorizonDBQueryTimeout is the maximum duration of a single query run
+	// when serving requests. Queries are not limited when it is 0.
+	HorizonDBQueryTimeout time.Duration
+	// HorizonDBStatementCacheSize is the number of prepared statements
+	// cached by the horizon database session. Statements are not prepared
+	// when it is 0.
+	HorizonDBStatementCacheSize int
+	// HorizonDBConnMaxLifetime is the maximum time a connection to the
+	// horizon database is reused. Connections are reused forever when it is
+	// 0.
+	HorizonDBConnMaxLifetime time.Duration
+	// HorizonDBShedLoad rejects requests with 503 Server Over Capacity while
+	// the connection pool of the horizon database is exhausted.
+	HorizonDBShedLoad bool
 
 	SSEUpdateFrequency time.Duration
 	ConnectionTimeout  time.Duration
@@ -34,8 +74,17 @@ type Config struct {
 	FriendbotURL       *url.URL
 	LogLevel           logrus.Level
 	LogFile            string
+	// SSEBufferSize is the number of events buffered per stream waiting to
+	// be written to a slow client, streams are unbuffered when it is 0.
+	SSEBufferSize int
+	// SSESlowConsumerPolicy is applied to the streams whose buffer is full
+	// ("disconnect" or "drop").
+	SSESlowConsumerPolicy string
 	// MaxPathLength is the maximum length of the path returned by `/paths` endpoint.
-	MaxPathLength     uint
+	MaxPathLength uint
+	// PathCacheMaxAge is the number of ledgers for which the results of
+	// identical `/paths` queries are reused, 0 disables the cache.
+	PathCacheMaxAge   uint
 	NetworkPassphrase string
 	SentryDSN         string
 	LogglyToken       string
@@ -66,7 +115,72 @@ type Config struct {
 	// IngestDisableStateVerification disables state verification
 	// `System.verifyState()` when set to `true`.
 	IngestDisableStateVerification bool
+	// IngestDataQualityRules is a comma separated list of the data quality
+	// rules checked when ingesting transactions ("all" or "none", the
+	// default).
+	IngestDataQualityRules string
+	// IngestFilterAccounts and IngestFilterAssets are comma separated lists
+	// of the accounts and assets whose transactions are ingested into the
+	// history tables, all transactions are ingested when both are empty.
+	IngestFilterAccounts string
+	IngestFilterAssets   string
+	// IngestSkipFailedTransactions omits the failed transactions, and their
+	// operations, from the history tables.
+	IngestSkipFailedTransactions bool
+	// IngestProfile selects the history processors run when ingesting
+	// transactions: "full", a built-in profile ("trades-only",
+	// "payments-only") or a comma separated list of history processors.
+	IngestProfile string
+	// IngestTradeConflicts selects how the trades which were already
+	// ingested are handled when a ledger is ingested again: "fail", "skip"
+	// or "verify".
+	IngestTradeConflicts string
+	// IngestAssetWatches evaluates the asset watches after ingesting every
+	// ledger and notifies their webhooks.
+	IngestAssetWatches bool
+	// AutoResetTestnet clears the history and the state when ingestion
+	// detects that the network was reset, instead of stopping ingestion.
+	AutoResetTestnet bool
 	// ApplyMigrations will apply pending migrations to the horizon database
 	// before starting the horizon service
 	ApplyMigrations bool
+	// ReadOnly starts horizon in read-only mode: reads are served but
+	// transaction submission is rejected and ingestion doesn't write to the
+	// database. It can be toggled at runtime with the `/read_only` admin
+	// endpoint.
+	ReadOnly bool
+	// SchemaStatsInterval is the interval of collection of the history
+	// tables statistics exported as metrics and by the `/schema_stats` admin
+	// endpoint. Statistics are not collected when it is 0.
+	SchemaStatsInterval time.Duration
+	// IngestGapBackfillInterval is the interval of the scans of the history
+	// for ledger gaps, which are reingested. Gaps are not detected when it
+	// is 0.
+	IngestGapBackfillInterval time.Duration
+	// IngestPauseDuringCatchup pauses live ingestion while ledger gaps are
+	// backfilled.
+	IngestPauseDuringCatchup bool
+	// IngestStateAuditInterval is the interval of the audits of the ingested
+	// state, which quarantine the entries not matching the history archive
+	// checkpoint. The state is not audited when it is 0.
+	IngestStateAuditInterval time.Duration
+	// TxSubSequenceSource is where the transaction submission system reads
+	// the sequence numbers of the source accounts from: "horizon", "core"
+	// or "remote".
+	TxSubSequenceSource string
+	// TxSubRemoteHorizonURL is the Horizon instance requested for the
+	// sequence numbers when TxSubSequenceSource is "remote".
+	TxSubRemoteHorizonURL string
+	// LatestLedgerSource is the default source of the `/ledgers/latest`
+	// end-point: "ingested" or "core".
+	LatestLedgerSource string
+	// EnableGraphQL serves the `/graphql` end-point.
+	EnableGraphQL bool
+	// ReadinessMaxLedgerLag is the maximum number of ledgers the history
+	// database may be behind stellar-core for the `/ready` admin end-point to
+	// report the instance as ready.
+	ReadinessMaxLedgerLag uint
+	// MaxTransactionBatchSize is the maximum number of envelopes submitted
+	// by a request to the `/transactions/batch` end-point.
+	MaxTransactionBatchSize uint
 }
@@ -0,0 +1,6 @@
+// Package consistency compares the data served by two horizon instances over
+// a range of ledgers, ledger by ledger, using the ledger resources (hashes and
+// row counts), the effects of the ledgers and the ledger manifests
+// (checksums of the trades and payments). It's used to validate a new deployment against a reference
+// instance before switching traffic to it.
+package consistency
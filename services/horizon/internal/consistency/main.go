@@ -0,0 +1,411 @@
+package consistency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/support/errors"
+)
+
+const (
+	// requestTimeout is the maximum duration of a request to an instance.
+	requestTimeout = 30 * time.Second
+	// defaultConcurrency is the number of ledgers compared concurrently when
+	// Checker.Concurrency is not set.
+	defaultConcurrency = 10
+	// maxRateLimitedAttempts is the number of times a request rate limited
+	// by an instance is sent before giving up.
+	maxRateLimitedAttempts = 5
+	// effectsPageLimit is the number of effects loaded per request.
+	effectsPageLimit = 200
+)
+
+// rateLimitedDelay is the delay before sending again a request rate limited
+// by an instance without a Retry-After header, it's doubled on every attempt.
+var rateLimitedDelay = time.Second
+
+// errBeforeHistory is returned when the ledger is older than the history
+// of an instance.
+var errBeforeHistory = errors.New("ledger before history")
+
+// Tables are the labels of the compared data, named after the history tables
+// they are stored in.
+const (
+	TableLedgers      = "history_ledgers"
+	TableTransactions = "history_transactions"
+	TableOperations   = "history_operations"
+	TableTrades       = "history_trades"
+	TableEffects      = "history_effects"
+	// TablePayments are the payment operations of history_operations.
+	TablePayments = "history_operations(payments)"
+)
+
+// tables are the compared tables in the order they are reported.
+var tables = []string{TableLedgers, TableTransactions, TableOperations, TableEffects, TableTrades, TablePayments}
+
+// Instance is a horizon instance compared by a Checker. The requests are
+// sent with horizonclient using Client.
+type Instance struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewInstance returns an Instance of the horizon at url.
+func NewInstance(url string) Instance {
+	return Instance{
+		URL:    strings.TrimRight(url, "/"),
+		Client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Divergence is the data of a table for a ledger which differs between the
+// instances. A and B describe the data of each instance, ex. a checksum or a
+// row count.
+type Divergence struct {
+	Ledger uint32 `json:"ledger"`
+	Table  string `json:"table"`
+	A      string `json:"a"`
+	B      string `json:"b"`
+}
+
+// Report is the result of the comparison of a range of ledgers.
+type Report struct {
+	FromLedger  uint32       `json:"from_ledger"`
+	ToLedger    uint32       `json:"to_ledger"`
+	Divergences []Divergence `json:"divergences"`
+	// DivergentLedgers is the number of ledgers with divergences by table.
+	DivergentLedgers map[string]int `json:"divergent_ledgers"`
+	// UnverifiedLedgers is the number of ledgers whose trades and payments
+	// were not compared because one of the instances has no manifest for
+	// them (ex. the instance doesn't serve manifests or the ledger was
+	// ingested before they were introduced).
+	UnverifiedLedgers int `json:"unverified_ledgers"`
+	// OutOfHistoryLedgers is the number of ledgers which were not compared
+	// because they are older than the history of one of the instances.
+	OutOfHistoryLedgers int `json:"out_of_history_ledgers"`
+}
+
+// Consistent returns true if no divergences were found.
+func (r Report) Consistent() bool {
+	return len(r.Divergences) == 0
+}
+
+// Checker compares the ledgers of instance B with the ledgers of instance A.
+type Checker struct {
+	A Instance
+	B Instance
+	// Concurrency is the number of ledgers compared concurrently.
+	Concurrency int
+}
+
+// Check compares the ledgers of the [fromLedger, toLedger] range (closed
+// interval). The divergences are sorted by ledger.
+func (c *Checker) Check(ctx context.Context, fromLedger, toLedger uint32) (Report, error) {
+	report := Report{
+		FromLedger:       fromLedger,
+		ToLedger:         toLedger,
+		Divergences:      []Divergence{},
+		DivergentLedgers: map[string]int{},
+	}
+	if fromLedger == 0 || fromLedger > toLedger {
+		return report, errors.Errorf("invalid range: [%d, %d]", fromLedger, toLedger)
+	}
+
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ledgers := make(chan uint32)
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		firstErr error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sequence := range ledgers {
+				result, err := c.checkLedger(ctx, sequence)
+
+				mutex.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				report.Divergences = append(report.Divergences, result.divergences...)
+				switch {
+				case result.outOfHistory:
+					report.OutOfHistoryLedgers++
+				case !result.verified:
+					report.UnverifiedLedgers++
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+loop:
+	for sequence := fromLedger; sequence <= toLedger; sequence++ {
+		select {
+		case ledgers <- sequence:
+		case <-ctx.Done():
+			break loop
+		}
+		// toLedger can be the largest uint32
+		if sequence == toLedger {
+			break
+		}
+	}
+	close(ledgers)
+	wg.Wait()
+
+	if firstErr != nil {
+		return report, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	sort.Slice(report.Divergences, func(i, j int) bool {
+		a, b := report.Divergences[i], report.Divergences[j]
+		if a.Ledger != b.Ledger {
+			return a.Ledger < b.Ledger
+		}
+		return tableIndex(a.Table) < tableIndex(b.Table)
+	})
+	for _, divergence := range report.Divergences {
+		report.DivergentLedgers[divergence.Table]++
+	}
+	return report, nil
+}
+
+// ledgerCheck is the result of the comparison of a ledger. verified is false
+// when the trades and payments couldn't be compared, outOfHistory is true
+// when the ledger is older than the history of one of the instances and
+// wasn't compared.
+type ledgerCheck struct {
+	divergences  []Divergence
+	verified     bool
+	outOfHistory bool
+}
+
+// checkLedger compares a ledger.
+func (c *Checker) checkLedger(ctx context.Context, sequence uint32) (ledgerCheck, error) {
+	a, err := c.A.summary(ctx, sequence)
+	if err != nil {
+		return ledgerCheck{}, errors.Wrapf(err, "could not load ledger %d from %s", sequence, c.A.URL)
+	}
+	b, err := c.B.summary(ctx, sequence)
+	if err != nil {
+		return ledgerCheck{}, errors.Wrapf(err, "could not load ledger %d from %s", sequence, c.B.URL)
+	}
+
+	if a.beforeHistory || b.beforeHistory {
+		return ledgerCheck{outOfHistory: true}, nil
+	}
+	// ledgers missing in both instances (ex. not ingested yet by both) are
+	// consistent
+	if !a.found && !b.found {
+		return ledgerCheck{verified: true}, nil
+	}
+	if a.found != b.found {
+		return ledgerCheck{
+			divergences: []Divergence{{
+				Ledger: sequence,
+				Table:  TableLedgers,
+				A:      a.values[TableLedgers],
+				B:      b.values[TableLedgers],
+			}},
+			verified: true,
+		}, nil
+	}
+
+	result := ledgerCheck{verified: a.manifest && b.manifest}
+	for _, table := range tables {
+		valueA, okA := a.values[table]
+		valueB, okB := b.values[table]
+		if !okA || !okB || valueA == valueB {
+			continue
+		}
+		result.divergences = append(result.divergences, Divergence{
+			Ledger: sequence,
+			Table:  table,
+			A:      valueA,
+			B:      valueB,
+		})
+	}
+	return result, nil
+}
+
+// ledgerSummary describes the data of a ledger in an instance by table.
+type ledgerSummary struct {
+	found         bool
+	beforeHistory bool
+	manifest      bool
+	values        map[string]string
+}
+
+// client returns a horizonclient client of the instance. Clients are not
+// shared between goroutines.
+func (i Instance) client() *horizonclient.Client {
+	return &horizonclient.Client{
+		HorizonURL: i.URL + "/",
+		HTTP:       i.Client,
+	}
+}
+
+func (i Instance) summary(ctx context.Context, sequence uint32) (ledgerSummary, error) {
+	summary := ledgerSummary{values: map[string]string{TableLedgers: "missing"}}
+	client := i.client()
+
+	var ledger horizon.Ledger
+	found, err := get(ctx, func() (err error) {
+		ledger, err = client.LedgerDetail(sequence)
+		return err
+	})
+	if err == errBeforeHistory {
+		summary.beforeHistory = true
+		return summary, nil
+	}
+	if err != nil || !found {
+		return summary, err
+	}
+	summary.found = true
+	summary.values[TableLedgers] = ledger.Hash
+	summary.values[TableTransactions] = fmt.Sprintf(
+		"%d successful, %s failed",
+		ledger.SuccessfulTransactionCount,
+		optionalCount(ledger.FailedTransactionCount),
+	)
+	summary.values[TableOperations] = fmt.Sprintf("%d operations", ledger.OperationCount)
+
+	if summary.values[TableEffects], err = effectsChecksum(ctx, client, sequence); err != nil {
+		return summary, err
+	}
+
+	var manifest horizon.LedgerManifest
+	found, err = get(ctx, func() (err error) {
+		manifest, err = client.LedgerManifest(sequence)
+		return err
+	})
+	if err != nil || !found {
+		return summary, err
+	}
+	summary.manifest = true
+	summary.values[TableTrades] = manifest.TradesHash
+	summary.values[TablePayments] = manifest.PaymentsHash
+	return summary, nil
+}
+
+// effectsChecksum returns the number of effects of the ledger and the hex
+// encoded SHA-256 hash of their ids, types and accounts, one effect per
+// line. The links of the effects contain the url of the instance so they
+// are not part of the checksum.
+func effectsChecksum(ctx context.Context, client *horizonclient.Client, sequence uint32) (string, error) {
+	hash := sha256.New()
+	count := 0
+	request := horizonclient.EffectRequest{
+		ForLedger: strconv.FormatUint(uint64(sequence), 10),
+		Order:     horizonclient.OrderAsc,
+		Limit:     effectsPageLimit,
+	}
+	for {
+		var page effects.EffectsPage
+		_, err := get(ctx, func() (err error) {
+			page, err = client.Effects(request)
+			return err
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "could not load effects")
+		}
+
+		for _, effect := range page.Embedded.Records {
+			fmt.Fprintf(hash, "%s %s %s\n", effect.GetID(), effect.GetType(), effect.GetAccount())
+			request.Cursor = effect.PagingToken()
+		}
+		count += len(page.Embedded.Records)
+		if len(page.Embedded.Records) < effectsPageLimit {
+			break
+		}
+	}
+	return fmt.Sprintf("%d effects, %s", count, hex.EncodeToString(hash.Sum(nil))), nil
+}
+
+// get sends a request with send, it returns false if the resource doesn't
+// exist and errBeforeHistory if it's older than the history of the
+// instance. Requests rate limited by the instance are sent again after the
+// delay of the Retry-After header, or an exponential backoff, up to
+// maxRateLimitedAttempts times.
+func get(ctx context.Context, send func() error) (bool, error) {
+	delay := rateLimitedDelay
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		err := send()
+		if err == nil {
+			return true, nil
+		}
+		horizonError := horizonclient.GetError(err)
+		if horizonError == nil || horizonError.Response == nil {
+			return false, err
+		}
+
+		switch horizonError.Response.StatusCode {
+		case http.StatusNotFound:
+			return false, nil
+		case http.StatusGone:
+			return false, errBeforeHistory
+		case http.StatusTooManyRequests:
+			if attempt >= maxRateLimitedAttempts {
+				return false, errors.Wrapf(err, "rate limited after %d attempts", attempt)
+			}
+		default:
+			return false, err
+		}
+
+		wait := delay
+		if seconds, parseErr := strconv.Atoi(horizonError.Response.Header.Get("Retry-After")); parseErr == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		delay *= 2
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+func optionalCount(count *int32) string {
+	if count == nil {
+		return "unknown"
+	}
+	return strconv.Itoa(int(*count))
+}
+
+func tableIndex(table string) int {
+	for i, t := range tables {
+		if t == table {
+			return i
+		}
+	}
+	return len(tables)
+}
@@ -0,0 +1,239 @@
+package consistency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHorizon serves the ledgers, effects and manifests of maps by sequence.
+// Ledgers before elder are out of its history. The first rateLimited
+// requests are rate limited.
+type fakeHorizon struct {
+	ledgers     map[int32]horizon.Ledger
+	effects     map[int32][]effects.Base
+	manifests   map[int32]horizon.LedgerManifest
+	elder       int32
+	status      int
+	rateLimited *int32
+}
+
+func writeProblem(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":   "https://stellar.org/horizon-errors/" + strconv.Itoa(status),
+		"title":  http.StatusText(status),
+		"status": status,
+	})
+}
+
+func (f fakeHorizon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.status != 0 {
+		writeProblem(w, f.status)
+		return
+	}
+	if f.rateLimited != nil && atomic.AddInt32(f.rateLimited, -1) >= 0 {
+		writeProblem(w, http.StatusTooManyRequests)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/ledgers/"), "/")
+	sequence, err := strconv.Atoi(parts[0])
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest)
+		return
+	}
+	if int32(sequence) < f.elder {
+		writeProblem(w, http.StatusGone)
+		return
+	}
+
+	var resource interface{}
+	var ok bool
+	switch {
+	case len(parts) == 2 && parts[1] == "manifest":
+		resource, ok = f.manifests[int32(sequence)]
+	case len(parts) == 2 && parts[1] == "effects":
+		var page struct {
+			Embedded struct {
+				Records []effects.Base `json:"records"`
+			} `json:"_embedded"`
+		}
+		page.Embedded.Records = []effects.Base{}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		cursor := r.URL.Query().Get("cursor")
+		for _, effect := range f.effects[int32(sequence)] {
+			if len(page.Embedded.Records) == limit {
+				break
+			}
+			if effect.PT > cursor {
+				page.Embedded.Records = append(page.Embedded.Records, effect)
+			}
+		}
+		resource, ok = page, true
+	default:
+		resource, ok = f.ledgers[int32(sequence)]
+	}
+	if !ok {
+		writeProblem(w, http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(resource)
+}
+
+func newFakeHorizon(from, to int32) fakeHorizon {
+	f := fakeHorizon{
+		ledgers:   map[int32]horizon.Ledger{},
+		effects:   map[int32][]effects.Base{},
+		manifests: map[int32]horizon.LedgerManifest{},
+	}
+	failed := int32(0)
+	for sequence := from; sequence <= to; sequence++ {
+		f.ledgers[sequence] = horizon.Ledger{
+			Hash:                       "hash" + strconv.Itoa(int(sequence)),
+			Sequence:                   sequence,
+			SuccessfulTransactionCount: 2,
+			FailedTransactionCount:     &failed,
+			OperationCount:             5,
+		}
+		// more effects than fit in a page
+		for i := 0; i < effectsPageLimit+1; i++ {
+			id := fmt.Sprintf("%010d-%010d", sequence, i)
+			f.effects[sequence] = append(f.effects[sequence], effects.Base{
+				ID:      id,
+				PT:      id,
+				Account: "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+				Type:    "account_credited",
+			})
+		}
+		f.manifests[sequence] = horizon.LedgerManifest{
+			Sequence:     sequence,
+			TradesHash:   "trades",
+			PaymentsHash: "payments",
+		}
+	}
+	return f
+}
+
+func check(t *testing.T, a, b fakeHorizon, from, to uint32) (Report, error) {
+	serverA := httptest.NewServer(a)
+	defer serverA.Close()
+	serverB := httptest.NewServer(b)
+	defer serverB.Close()
+
+	checker := Checker{
+		A:           NewInstance(serverA.URL),
+		B:           NewInstance(serverB.URL + "/"),
+		Concurrency: 3,
+	}
+	return checker.Check(context.Background(), from, to)
+}
+
+func TestCheckConsistent(t *testing.T) {
+	report, err := check(t, newFakeHorizon(1, 20), newFakeHorizon(1, 20), 1, 20)
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent())
+	assert.Equal(t, 0, report.UnverifiedLedgers)
+	assert.Empty(t, report.DivergentLedgers)
+}
+
+func TestCheckDivergences(t *testing.T) {
+	a := newFakeHorizon(1, 20)
+	b := newFakeHorizon(1, 19)
+
+	ledger := b.ledgers[3]
+	ledger.OperationCount = 4
+	b.ledgers[3] = ledger
+	manifest := b.manifests[3]
+	manifest.TradesHash = "other"
+	b.manifests[3] = manifest
+	manifest = b.manifests[10]
+	manifest.PaymentsHash = "other"
+	b.manifests[10] = manifest
+	// ledgers without manifests are compared without trades and payments
+	delete(b.manifests, 12)
+	ledger = b.ledgers[13]
+	ledger.FailedTransactionCount = nil
+	b.ledgers[13] = ledger
+	// the last effect of the ledger is on the second page
+	b.effects[5] = b.effects[5][:effectsPageLimit]
+
+	report, err := check(t, a, b, 1, 22)
+	assert.NoError(t, err)
+	assert.False(t, report.Consistent())
+	if assert.Len(t, report.Divergences, 6) {
+		effectsDivergence := report.Divergences[2]
+		assert.Equal(t, uint32(5), effectsDivergence.Ledger)
+		assert.Equal(t, TableEffects, effectsDivergence.Table)
+		assert.True(t, strings.HasPrefix(effectsDivergence.A, "201 effects, "))
+		assert.True(t, strings.HasPrefix(effectsDivergence.B, "200 effects, "))
+		report.Divergences = append(report.Divergences[:2], report.Divergences[3:]...)
+	}
+	assert.Equal(t, []Divergence{
+		{Ledger: 3, Table: TableOperations, A: "5 operations", B: "4 operations"},
+		{Ledger: 3, Table: TableTrades, A: "trades", B: "other"},
+		{Ledger: 10, Table: TablePayments, A: "payments", B: "other"},
+		{Ledger: 13, Table: TableTransactions, A: "2 successful, 0 failed", B: "2 successful, unknown failed"},
+		{Ledger: 20, Table: TableLedgers, A: "hash20", B: "missing"},
+	}, report.Divergences)
+	assert.Equal(t, map[string]int{
+		TableLedgers:      1,
+		TableTransactions: 1,
+		TableOperations:   1,
+		TableEffects:      1,
+		TableTrades:       1,
+		TablePayments:     1,
+	}, report.DivergentLedgers)
+	assert.Equal(t, 1, report.UnverifiedLedgers)
+}
+
+func TestCheckOutOfHistory(t *testing.T) {
+	// ledgers before the history of an instance are not compared
+	b := newFakeHorizon(1, 20)
+	b.elder = 6
+	report, err := check(t, newFakeHorizon(1, 20), b, 1, 20)
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent())
+	assert.Equal(t, 5, report.OutOfHistoryLedgers)
+	assert.Equal(t, 0, report.UnverifiedLedgers)
+}
+
+func TestCheckRateLimited(t *testing.T) {
+	defer func(delay time.Duration) { rateLimitedDelay = delay }(rateLimitedDelay)
+	rateLimitedDelay = time.Millisecond
+
+	// rate limited requests are sent again
+	b := newFakeHorizon(1, 5)
+	rateLimited := int32(3)
+	b.rateLimited = &rateLimited
+	report, err := check(t, newFakeHorizon(1, 5), b, 1, 5)
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent())
+
+	unavailable := fakeHorizon{status: http.StatusTooManyRequests}
+	_, err = check(t, newFakeHorizon(1, 20), unavailable, 1, 20)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limited after 5 attempts")
+}
+
+func TestCheckErrors(t *testing.T) {
+	_, err := check(t, newFakeHorizon(1, 2), newFakeHorizon(1, 2), 3, 2)
+	assert.EqualError(t, err, "invalid range: [3, 2]")
+
+	unavailable := fakeHorizon{status: http.StatusServiceUnavailable}
+	_, err = check(t, newFakeHorizon(1, 20), unavailable, 1, 20)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Service Unavailable")
+}
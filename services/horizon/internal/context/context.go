@@ -14,6 +14,13 @@ type CtxKey string
 
 var RequestContextKey = CtxKey("request")
 var SessionContextKey = CtxKey("session")
+var ValidateOnlyContextKey = CtxKey("validate_only")
+
+// ErrValidateOnly is returned by HistoryQFromRequest for requests which are
+// only validated, see WithValidateOnly. Actions must validate all the
+// parameters of a request before getting its HistoryQ, the validations
+// following it are skipped.
+var ErrValidateOnly = errors.New("the request is only validated")
 
 func RequestFromContext(ctx context.Context) *http.Request {
 	found, _ := ctx.Value(&RequestContextKey).(*http.Request)
@@ -53,8 +60,17 @@ func BaseURL(ctx context.Context) *url.URL {
 	}
 }
 
+// WithValidateOnly returns a copy of ctx in which the history database can't
+// be queried, so actions stop once the request has been validated.
+func WithValidateOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, &ValidateOnlyContextKey, true)
+}
+
 func HistoryQFromRequest(request *http.Request) (*history.Q, error) {
 	ctx := request.Context()
+	if validateOnly, _ := ctx.Value(&ValidateOnlyContextKey).(bool); validateOnly {
+		return nil, ErrValidateOnly
+	}
 	session, ok := ctx.Value(&SessionContextKey).(*db.Session)
 	if !ok {
 		return nil, errors.New("missing session in request context")
@@ -0,0 +1,27 @@
+package dashboards
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// AlertRules returns the Prometheus rules file defining Alerts in the horizon
+// group. Strings are double quoted, the escapes of strconv.Quote are valid
+// YAML escapes.
+func AlertRules() []byte {
+	var b bytes.Buffer
+	b.WriteString("groups:\n")
+	b.WriteString("- name: horizon\n")
+	b.WriteString("  rules:\n")
+	for _, alert := range Alerts {
+		fmt.Fprintf(&b, "  - alert: %s\n", alert.Name)
+		fmt.Fprintf(&b, "    expr: %s\n", strconv.Quote(alert.Expr))
+		fmt.Fprintf(&b, "    for: %s\n", alert.For)
+		b.WriteString("    labels:\n")
+		fmt.Fprintf(&b, "      severity: %s\n", alert.Severity)
+		b.WriteString("    annotations:\n")
+		fmt.Fprintf(&b, "      summary: %s\n", strconv.Quote(alert.Summary))
+	}
+	return b.Bytes()
+}
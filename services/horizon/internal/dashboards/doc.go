@@ -0,0 +1,6 @@
+// Package dashboards defines the Grafana dashboard and the Prometheus alert
+// rules monitoring horizon, written by `horizon gen-dashboards`. The panels
+// and alerts only reference metrics exported by the binary: the packages
+// defining the metrics check that the ones referenced here exist, so renaming
+// a metric without updating the dashboard fails their tests.
+package dashboards
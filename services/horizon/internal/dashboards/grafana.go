@@ -0,0 +1,105 @@
+package dashboards
+
+import (
+	"encoding/json"
+)
+
+const (
+	// panelWidth and panelHeight are the size of the panels in the 24 columns
+	// wide grid of Grafana, two panels fit in a line.
+	panelWidth  = 12
+	panelHeight = 8
+)
+
+type gridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type grafanaTarget struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+	RefID        string `json:"refId"`
+}
+
+type grafanaAxis struct {
+	Format string `json:"format"`
+	Show   bool   `json:"show"`
+}
+
+type grafanaPanel struct {
+	ID         int             `json:"id"`
+	Type       string          `json:"type"`
+	Title      string          `json:"title"`
+	GridPos    gridPos         `json:"gridPos"`
+	Datasource string          `json:"datasource,omitempty"`
+	Targets    []grafanaTarget `json:"targets,omitempty"`
+	Yaxes      []grafanaAxis   `json:"yaxes,omitempty"`
+	Lines      bool            `json:"lines,omitempty"`
+}
+
+type grafanaDashboard struct {
+	UID           string            `json:"uid"`
+	Title         string            `json:"title"`
+	Tags          []string          `json:"tags"`
+	Timezone      string            `json:"timezone"`
+	SchemaVersion int               `json:"schemaVersion"`
+	Refresh       string            `json:"refresh"`
+	Time          map[string]string `json:"time"`
+	Panels        []grafanaPanel    `json:"panels"`
+}
+
+// Dashboard returns the Grafana dashboard JSON drawing Rows, querying the
+// Prometheus datasource named datasource.
+func Dashboard(datasource string) ([]byte, error) {
+	dashboard := grafanaDashboard{
+		UID:           "horizon",
+		Title:         "Horizon",
+		Tags:          []string{"horizon", "stellar"},
+		Timezone:      "utc",
+		SchemaVersion: 22,
+		Refresh:       "30s",
+		Time:          map[string]string{"from": "now-6h", "to": "now"},
+		Panels:        []grafanaPanel{},
+	}
+
+	id, y := 1, 0
+	for _, row := range Rows {
+		dashboard.Panels = append(dashboard.Panels, grafanaPanel{
+			ID:      id,
+			Type:    "row",
+			Title:   row.Title,
+			GridPos: gridPos{H: 1, W: 2 * panelWidth, X: 0, Y: y},
+		})
+		id++
+		y++
+
+		for i, panel := range row.Panels {
+			targets := make([]grafanaTarget, len(panel.Targets))
+			for j, target := range panel.Targets {
+				targets[j] = grafanaTarget{
+					Expr:         target.Expr,
+					LegendFormat: target.Legend,
+					RefID:        string(rune('A' + j)),
+				}
+			}
+
+			dashboard.Panels = append(dashboard.Panels, grafanaPanel{
+				ID:         id,
+				Type:       "graph",
+				Title:      panel.Title,
+				GridPos:    gridPos{H: panelHeight, W: panelWidth, X: (i % 2) * panelWidth, Y: y + (i/2)*panelHeight},
+				Datasource: datasource,
+				Targets:    targets,
+				Yaxes:      []grafanaAxis{{Format: panel.Unit, Show: true}, {Format: "short", Show: false}},
+				Lines:      true,
+			})
+			id++
+		}
+		y += (len(row.Panels) + 1) / 2 * panelHeight
+	}
+
+	return json.MarshalIndent(dashboard, "", "  ")
+}
@@ -0,0 +1,289 @@
+package dashboards
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Target is a query drawn by a panel.
+type Target struct {
+	Expr   string
+	Legend string
+}
+
+// Panel is a graph of the dashboard. Unit is the Grafana unit of its y axis.
+type Panel struct {
+	Title   string
+	Unit    string
+	Targets []Target
+}
+
+// Row groups the panels of a subsystem.
+type Row struct {
+	Title  string
+	Panels []Panel
+}
+
+// Alert is a Prometheus alerting rule, firing when Expr returns results for
+// the For duration.
+type Alert struct {
+	Name     string
+	Expr     string
+	For      string
+	Severity string
+	Summary  string
+}
+
+// Rows are the rows of the dashboard, in order.
+var Rows = []Row{
+	{
+		Title: "Ingestion",
+		Panels: []Panel{
+			{
+				Title: "Ledgers behind stellar-core",
+				Unit:  "short",
+				Targets: []Target{
+					{Expr: "horizon_ingest_ledgers_behind", Legend: "{{instance}}"},
+				},
+			},
+			{
+				Title: "Ledger ingestion latency",
+				Unit:  "s",
+				Targets: []Target{
+					{Expr: `horizon_ingest_ledger_ingestion_latency_seconds{quantile="0.99"}`, Legend: "p99 {{instance}}"},
+					{Expr: `horizon_ingest_ledger_ingestion_latency_seconds{quantile="0.5"}`, Legend: "p50 {{instance}}"},
+				},
+			},
+			{
+				Title: "Ledgers ingested",
+				Unit:  "ops",
+				Targets: []Target{
+					{Expr: "rate(horizon_ingest_ledgers_ingested_total[5m])", Legend: "{{instance}}"},
+				},
+			},
+			{
+				Title: "Ledger ingestion duration",
+				Unit:  "s",
+				Targets: []Target{
+					{Expr: `horizon_ingest_ledger_ingestion_duration_seconds{quantile="0.99"}`, Legend: "p99 {{instance}}"},
+				},
+			},
+			{
+				Title: "Processor duration",
+				Unit:  "s",
+				Targets: []Target{
+					{Expr: `horizon_ingest_processor_duration_seconds{quantile="0.99"}`, Legend: "{{processor}}"},
+				},
+			},
+			{
+				Title: "State",
+				Unit:  "short",
+				Targets: []Target{
+					{Expr: "horizon_ingest_state_invalid", Legend: "invalid {{instance}}"},
+					{Expr: "horizon_ingest_state_quarantined_entries", Legend: "quarantined entries"},
+					{Expr: "increase(horizon_ingest_data_quality_violations_total[1h])", Legend: "{{rule}}"},
+				},
+			},
+		},
+	},
+	{
+		Title: "HTTP",
+		Panels: []Panel{
+			{
+				Title: "Requests",
+				Unit:  "reqps",
+				Targets: []Target{
+					{Expr: "sum by (status) (rate(horizon_http_requests_duration_seconds_count[5m]))", Legend: "{{status}}"},
+				},
+			},
+			{
+				Title: "Request latency",
+				Unit:  "s",
+				Targets: []Target{
+					{Expr: `max by (route) (horizon_http_requests_duration_seconds{quantile="0.99",streaming="false"})`, Legend: "{{route}}"},
+				},
+			},
+			{
+				Title: "Request phases",
+				Unit:  "s",
+				Targets: []Target{
+					{
+						Expr:   "histogram_quantile(0.99, sum by (le, phase) (rate(horizon_http_request_phase_duration_seconds_bucket[5m])))",
+						Legend: "p99 {{phase}}",
+					},
+				},
+			},
+			{
+				Title: "Slow stream consumers",
+				Unit:  "short",
+				Targets: []Target{
+					{Expr: "sum by (policy) (increase(horizon_sse_slow_consumers_total[5m]))", Legend: "{{policy}}"},
+					{Expr: "sum(increase(horizon_sse_dropped_events_total[5m]))", Legend: "dropped events"},
+				},
+			},
+		},
+	},
+	{
+		Title: "Transaction submission",
+		Panels: []Panel{
+			{
+				Title: "Submission queue",
+				Unit:  "short",
+				Targets: []Target{
+					{Expr: "horizon_txsub_open", Legend: "open {{instance}}"},
+					{Expr: "horizon_txsub_buffered", Legend: "buffered {{instance}}"},
+				},
+			},
+			{
+				Title: "Submissions",
+				Unit:  "ops",
+				Targets: []Target{
+					{Expr: "sum(rate(horizon_txsub_succeeded[5m]))", Legend: "succeeded"},
+					{Expr: "sum(rate(horizon_txsub_failed[5m]))", Legend: "failed"},
+				},
+			},
+			{
+				Title: "Submission duration",
+				Unit:  "s",
+				Targets: []Target{
+					{Expr: `horizon_txsub_submission_duration_seconds{quantile="0.99"}`, Legend: "p99 {{instance}}"},
+				},
+			},
+			{
+				Title: "Transaction envelopes",
+				Unit:  "ops",
+				Targets: []Target{
+					{Expr: "sum(rate(horizon_txsub_v0[5m]))", Legend: "v0"},
+					{Expr: "sum(rate(horizon_txsub_v1[5m]))", Legend: "v1"},
+					{Expr: "sum(rate(horizon_txsub_feebump[5m]))", Legend: "fee bump"},
+				},
+			},
+		},
+	},
+}
+
+// Alerts are the alerting rules of the alerts group.
+var Alerts = []Alert{
+	{
+		// ingesting instances take turns ingesting the ledgers, each one
+		// reports the lag of the last ledger it ingested
+		Name:     "HorizonIngestionBehind",
+		Expr:     "max(horizon_ingest_ledgers_behind) >= 10",
+		For:      "5m",
+		Severity: "warning",
+		Summary:  "Horizon ingestion is {{ $value }} ledgers behind the network",
+	},
+	{
+		// each ledger is ingested by a single instance of the cluster
+		Name:     "HorizonIngestionStalled",
+		Expr:     "sum(rate(horizon_ingest_ledgers_ingested_total[5m])) == 0",
+		For:      "10m",
+		Severity: "critical",
+		Summary:  "Horizon hasn't ingested a ledger for 10 minutes",
+	},
+	{
+		Name:     "HorizonIngestionLatencyHigh",
+		Expr:     `horizon_ingest_ledger_ingestion_latency_seconds{quantile="0.99"} > 30`,
+		For:      "10m",
+		Severity: "warning",
+		Summary:  "Ledgers are ingested {{ $value }}s after they are closed",
+	},
+	{
+		Name:     "HorizonStateInvalid",
+		Expr:     "horizon_ingest_state_invalid == 1",
+		For:      "1m",
+		Severity: "critical",
+		Summary:  "Horizon state verification failed, the state needs to be rebuilt",
+	},
+	{
+		Name:     "HorizonStateQuarantine",
+		Expr:     "max(horizon_ingest_state_quarantined_entries) > 0",
+		For:      "1m",
+		Severity: "warning",
+		Summary:  "The last state audit quarantined {{ $value }} ledger entries, see /state_quarantine",
+	},
+	{
+		Name:     "HorizonRequestLatencyHigh",
+		Expr:     `max by (route) (horizon_http_requests_duration_seconds{quantile="0.99",streaming="false"}) > 5`,
+		For:      "10m",
+		Severity: "warning",
+		Summary:  "Requests to {{ $labels.route }} take {{ $value }}s (p99)",
+	},
+	{
+		Name:     "HorizonServerErrors",
+		Expr:     `sum(rate(horizon_http_requests_duration_seconds_count{status=~"5.."}[5m])) / sum(rate(horizon_http_requests_duration_seconds_count[5m])) > 0.05`,
+		For:      "5m",
+		Severity: "warning",
+		Summary:  "More than 5% of the requests fail with a server error",
+	},
+	{
+		Name:     "HorizonTxSubQueueBacklog",
+		Expr:     "horizon_txsub_buffered > 100",
+		For:      "5m",
+		Severity: "warning",
+		Summary:  "{{ $value }} transaction submissions are waiting for stellar-core",
+	},
+}
+
+var metricNameRegexp = regexp.MustCompile(`horizon_[a-z0-9_]+`)
+
+// Metrics returns the sorted names of the metrics starting with prefix which
+// are referenced by the panels and the alerts. The _bucket, _sum and _count
+// series of histograms and summaries are reported as their metric.
+func Metrics(prefix string) []string {
+	exprs := []string{}
+	for _, row := range Rows {
+		for _, panel := range row.Panels {
+			for _, target := range panel.Targets {
+				exprs = append(exprs, target.Expr)
+			}
+		}
+	}
+	for _, alert := range Alerts {
+		exprs = append(exprs, alert.Expr)
+	}
+
+	set := map[string]bool{}
+	for _, expr := range exprs {
+		for _, name := range metricNameRegexp.FindAllString(expr, -1) {
+			for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+				name = strings.TrimSuffix(name, suffix)
+			}
+			if strings.HasPrefix(name, prefix) {
+				set[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var fqNameRegexp = regexp.MustCompile(`fqName: "([^"]*)"`)
+
+// MetricNames returns the set of the names of the metrics described by
+// collectors.
+func MetricNames(collectors ...prometheus.Collector) map[string]bool {
+	names := map[string]bool{}
+	for _, collector := range collectors {
+		descs := make(chan *prometheus.Desc)
+		go func() {
+			collector.Describe(descs)
+			close(descs)
+		}()
+		for desc := range descs {
+			// Desc doesn't expose its name, it's only part of its string.
+			if match := fqNameRegexp.FindStringSubmatch(desc.String()); match != nil {
+				names[match[1]] = true
+			}
+		}
+	}
+	return names
+}
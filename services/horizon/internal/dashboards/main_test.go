@@ -0,0 +1,111 @@
+package dashboards
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	assert.Equal(t, []string{
+		"horizon_txsub_buffered",
+		"horizon_txsub_failed",
+		"horizon_txsub_feebump",
+		"horizon_txsub_open",
+		"horizon_txsub_submission_duration_seconds",
+		"horizon_txsub_succeeded",
+		"horizon_txsub_v0",
+		"horizon_txsub_v1",
+	}, Metrics("horizon_txsub_"))
+
+	assert.Contains(t, Metrics("horizon_http_"), "horizon_http_requests_duration_seconds")
+	assert.Contains(t, Metrics("horizon_http_"), "horizon_http_request_phase_duration_seconds")
+}
+
+// TestMetricsChecked makes sure all the referenced metrics belong to a
+// subsystem checking them, see the TestDashboardMetrics tests.
+func TestMetricsChecked(t *testing.T) {
+	for _, name := range Metrics("horizon_") {
+		checked := false
+		for _, prefix := range []string{"horizon_ingest_", "horizon_http_", "horizon_sse_", "horizon_txsub_"} {
+			checked = checked || strings.HasPrefix(name, prefix)
+		}
+		assert.True(t, checked, name)
+	}
+}
+
+func TestMetricNames(t *testing.T) {
+	names := MetricNames(
+		prometheus.NewCounter(prometheus.CounterOpts{Namespace: "horizon", Subsystem: "test", Name: "a"}),
+		prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{Namespace: "horizon", Subsystem: "test", Name: "b_seconds"},
+			[]string{"route"},
+		),
+	)
+	assert.Equal(t, map[string]bool{"horizon_test_a": true, "horizon_test_b_seconds": true}, names)
+}
+
+func TestDashboard(t *testing.T) {
+	raw, err := Dashboard("Prometheus")
+	assert.NoError(t, err)
+
+	var dashboard struct {
+		Panels []struct {
+			ID         int    `json:"id"`
+			Type       string `json:"type"`
+			Datasource string `json:"datasource"`
+			GridPos    struct {
+				X, Y int
+			} `json:"gridPos"`
+			Targets []struct {
+				Expr  string `json:"expr"`
+				RefID string `json:"refId"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}
+	assert.NoError(t, json.Unmarshal(raw, &dashboard))
+
+	expected := 0
+	for _, row := range Rows {
+		expected += 1 + len(row.Panels)
+	}
+	assert.Len(t, dashboard.Panels, expected)
+
+	ids := map[int]bool{}
+	for _, panel := range dashboard.Panels {
+		assert.False(t, ids[panel.ID], "duplicate id %d", panel.ID)
+		ids[panel.ID] = true
+		if panel.Type == "row" {
+			continue
+		}
+		assert.Equal(t, "Prometheus", panel.Datasource)
+		assert.NotEmpty(t, panel.Targets)
+		assert.Equal(t, "A", panel.Targets[0].RefID)
+	}
+
+	// The first graph is right below the first row.
+	assert.Equal(t, 0, dashboard.Panels[1].GridPos.X)
+	assert.Equal(t, 1, dashboard.Panels[1].GridPos.Y)
+	assert.Equal(t, panelWidth, dashboard.Panels[2].GridPos.X)
+	assert.Equal(t, 1, dashboard.Panels[2].GridPos.Y)
+}
+
+func TestAlertRules(t *testing.T) {
+	rules := string(AlertRules())
+	assert.True(t, strings.HasPrefix(rules, "groups:\n- name: horizon\n  rules:\n"))
+	assert.Equal(t, len(Alerts), strings.Count(rules, "  - alert: "))
+	assert.Contains(t, rules,
+		"  - alert: HorizonIngestionBehind\n"+
+			"    expr: \"max(horizon_ingest_ledgers_behind) >= 10\"\n"+
+			"    for: 5m\n"+
+			"    labels:\n"+
+			"      severity: warning\n"+
+			"    annotations:\n"+
+			"      summary: \"Horizon ingestion is {{ $value }} ledgers behind the network\"\n",
+	)
+	assert.Contains(t, rules, `expr: "horizon_ingest_ledger_ingestion_latency_seconds{quantile=\"0.99\"} > 30"`)
+	assert.Contains(t, rules, `expr: "sum(rate(horizon_ingest_ledgers_ingested_total[5m])) == 0"`)
+}
@@ -29,7 +29,7 @@ func (q *Q) CreateAccounts(addresses []string, batchSize int) (map[string]int64,
 	builder := &db.BatchInsertBuilder{
 		Table:        q.GetTable("history_accounts"),
 		MaxBatchSize: batchSize,
-		Suffix:       "ON CONFLICT (address) DO NOTHING",
+		OnConflict:   &db.ConflictStrategy{Target: []string{"address"}},
 	}
 
 	// sort assets before inserting rows into history_assets to prevent deadlocks on acquiring a ShareLock
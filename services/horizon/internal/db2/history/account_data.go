@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
@@ -91,22 +92,19 @@ func (q *Q) InsertAccountData(data xdr.DataEntry, lastModifiedLedger xdr.Uint32)
 		return 0, errors.Wrap(err, "Error running dataEntryToLedgerKeyString")
 	}
 
-	sql := sq.Insert("accounts_data").
-		Columns("ledger_key", "account_id", "name", "value", "last_modified_ledger").
-		Values(
-			key,
-			data.AccountId.Address(),
-			data.DataName,
-			AccountDataValue(data.DataValue),
-			lastModifiedLedger,
-		)
-
-	result, err := q.Exec(sql)
+	builder := &db.BatchInsertBuilder{Table: q.GetTable("accounts_data")}
+	err = builder.Row(map[string]interface{}{
+		"ledger_key":           key,
+		"account_id":           data.AccountId.Address(),
+		"name":                 data.DataName,
+		"value":                AccountDataValue(data.DataValue),
+		"last_modified_ledger": lastModifiedLedger,
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	return result.RowsAffected()
+	return builder.ExecRowsAffected()
 }
 
 // UpdateAccountData updates a row in the accounts_data table.
@@ -0,0 +1,128 @@
+package history
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountEventType is the type of an account lifecycle event.
+type AccountEventType int16
+
+const (
+	// AccountEventCreated occurs when an account is created by a create
+	// account operation.
+	AccountEventCreated AccountEventType = 1
+	// AccountEventMerged occurs when an account is merged into another
+	// account (and removed from the ledger) by an account merge operation.
+	AccountEventMerged AccountEventType = 2
+)
+
+// String returns the name of the event type.
+func (t AccountEventType) String() string {
+	switch t {
+	case AccountEventCreated:
+		return "created"
+	case AccountEventMerged:
+		return "merged"
+	default:
+		return "unknown"
+	}
+}
+
+// AccountEvent is a row of data from the `history_account_events` table.
+// RelatedAccount is the account which funded a created account or the
+// destination of a merged account. Amount is respectively the starting
+// balance or the balance transferred by the merge.
+type AccountEvent struct {
+	HistoryOperationID int64            `db:"history_operation_id"`
+	Account            string           `db:"account"`
+	Type               AccountEventType `db:"type"`
+	RelatedAccount     string           `db:"related_account"`
+	Amount             xdr.Int64        `db:"amount"`
+	LedgerCloseTime    time.Time        `db:"ledger_close_time"`
+}
+
+// QAccountEvents defines account events related queries used by ingestion.
+type QAccountEvents interface {
+	NewAccountEventsBatchInsertBuilder(maxBatchSize int) AccountEventsBatchInsertBuilder
+}
+
+// AccountEventsBatchInsertBuilder is used to insert account lifecycle events
+// into the history_account_events table.
+type AccountEventsBatchInsertBuilder interface {
+	Add(
+		historyOperationID int64,
+		eventType AccountEventType,
+		account string,
+		relatedAccount string,
+		amount xdr.Int64,
+	) error
+	Exec() error
+}
+
+// accountEventsBatchInsertBuilder is a simple wrapper around db.BatchInsertBuilder
+type accountEventsBatchInsertBuilder struct {
+	builder db.BatchInsertBuilder
+}
+
+// NewAccountEventsBatchInsertBuilder constructs a new AccountEventsBatchInsertBuilder instance
+func (q *Q) NewAccountEventsBatchInsertBuilder(maxBatchSize int) AccountEventsBatchInsertBuilder {
+	return &accountEventsBatchInsertBuilder{
+		builder: db.BatchInsertBuilder{
+			Table:        q.GetTable("history_account_events"),
+			MaxBatchSize: maxBatchSize,
+		},
+	}
+}
+
+// Add adds a new account event to the batch
+func (i *accountEventsBatchInsertBuilder) Add(
+	historyOperationID int64,
+	eventType AccountEventType,
+	account string,
+	relatedAccount string,
+	amount xdr.Int64,
+) error {
+	return i.builder.Row(map[string]interface{}{
+		"history_operation_id": historyOperationID,
+		"account":              account,
+		"type":                 int16(eventType),
+		"related_account":      relatedAccount,
+		"amount":               amount,
+	})
+}
+
+func (i *accountEventsBatchInsertBuilder) Exec() error {
+	return i.builder.Exec()
+}
+
+// AccountHistory returns all the lifecycle events (creations and merges) of
+// the given account ordered from the oldest. An account can be created again
+// after being merged so it can have multiple events of each type. Events are
+// recorded by the ingestion, the ledgers ingested before the table was added
+// have none until they are reingested.
+func (q *Q) AccountHistory(address string) ([]AccountEvent, error) {
+	var events []AccountEvent
+	sql := selectAccountEvents.
+		Where("hae.account = ?", address).
+		OrderBy("hae.history_operation_id asc")
+	if err := q.Select(&events, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select account events")
+	}
+	return events, nil
+}
+
+var selectAccountEvents = sq.Select(
+	"hae.history_operation_id",
+	"hae.account",
+	"hae.type",
+	"hae.related_account",
+	"hae.amount",
+	"hl.closed_at as ledger_close_time",
+).
+	From("history_account_events hae").
+	Join("history_ledgers hl ON hl.sequence = (hae.history_operation_id >> 32)::integer")
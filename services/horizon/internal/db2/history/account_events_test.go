@@ -0,0 +1,43 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/services/horizon/internal/toid"
+)
+
+func TestAccountHistory(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	funder := "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"
+	account := "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+	createdID := toid.New(3, 1, 1).ToInt64()
+	mergedID := toid.New(5, 1, 1).ToInt64()
+
+	batch := q.NewAccountEventsBatchInsertBuilder(0)
+	tt.Assert.NoError(batch.Add(mergedID, AccountEventMerged, account, funder, 40000000))
+	tt.Assert.NoError(batch.Add(createdID, AccountEventCreated, account, funder, 50000000))
+	tt.Assert.NoError(batch.Add(toid.New(3, 1, 2).ToInt64(), AccountEventCreated, funder, account, 1))
+	tt.Assert.NoError(batch.Exec())
+
+	events, err := q.AccountHistory(account)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(events, 2) {
+		tt.Assert.Equal(createdID, events[0].HistoryOperationID)
+		tt.Assert.Equal(AccountEventCreated, events[0].Type)
+		tt.Assert.Equal(funder, events[0].RelatedAccount)
+		tt.Assert.Equal(int64(50000000), int64(events[0].Amount))
+		tt.Assert.False(events[0].LedgerCloseTime.IsZero())
+
+		tt.Assert.Equal(mergedID, events[1].HistoryOperationID)
+		tt.Assert.Equal(AccountEventMerged, events[1].Type)
+		tt.Assert.Equal("merged", events[1].Type.String())
+	}
+
+	events, err = q.AccountHistory("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+	tt.Assert.NoError(err)
+	tt.Assert.Len(events, 0)
+}
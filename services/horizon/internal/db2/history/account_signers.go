@@ -4,6 +4,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 
 	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
 )
 
@@ -51,16 +52,17 @@ func (q *Q) AccountsForSigner(signer string, page db2.PageQuery) ([]AccountSigne
 // CreateAccountSigner creates a row in the accounts_signers table.
 // Returns number of rows affected and error.
 func (q *Q) CreateAccountSigner(account, signer string, weight int32) (int64, error) {
-	sql := sq.Insert("accounts_signers").
-		Columns("account_id", "signer", "weight").
-		Values(account, signer, weight)
-
-	result, err := q.Exec(sql)
+	builder := &db.BatchInsertBuilder{Table: q.GetTable("accounts_signers")}
+	err := builder.Row(map[string]interface{}{
+		"account_id": account,
+		"signer":     signer,
+		"weight":     weight,
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	return result.RowsAffected()
+	return builder.ExecRowsAffected()
 }
 
 // RemoveAccountSigner deletes a row in the accounts_signers table.
@@ -0,0 +1,109 @@
+package history
+
+import (
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountThresholdsChange is a row of data from the
+// `history_account_thresholds` table. It holds the master weight and
+// thresholds of the account after the set_options operation which changed
+// them.
+type AccountThresholdsChange struct {
+	HistoryOperationID int64     `db:"history_operation_id"`
+	Account            string    `db:"account"`
+	MasterWeight       int16     `db:"master_weight"`
+	LowThreshold       int16     `db:"low_threshold"`
+	MedThreshold       int16     `db:"med_threshold"`
+	HighThreshold      int16     `db:"high_threshold"`
+	LedgerCloseTime    time.Time `db:"ledger_close_time"`
+}
+
+// PagingToken returns a cursor for this record
+func (r AccountThresholdsChange) PagingToken() string {
+	return fmt.Sprintf("%d", r.HistoryOperationID)
+}
+
+// QAccountThresholds defines account thresholds related queries used by
+// ingestion.
+type QAccountThresholds interface {
+	NewAccountThresholdsBatchInsertBuilder(maxBatchSize int) AccountThresholdsBatchInsertBuilder
+}
+
+// AccountThresholdsBatchInsertBuilder is used to insert changes of account
+// thresholds into the history_account_thresholds table.
+type AccountThresholdsBatchInsertBuilder interface {
+	Add(historyOperationID int64, account string, thresholds xdr.Thresholds) error
+	Exec() error
+}
+
+// accountThresholdsBatchInsertBuilder is a simple wrapper around db.BatchInsertBuilder
+type accountThresholdsBatchInsertBuilder struct {
+	builder db.BatchInsertBuilder
+}
+
+// NewAccountThresholdsBatchInsertBuilder constructs a new AccountThresholdsBatchInsertBuilder instance
+func (q *Q) NewAccountThresholdsBatchInsertBuilder(maxBatchSize int) AccountThresholdsBatchInsertBuilder {
+	return &accountThresholdsBatchInsertBuilder{
+		builder: db.BatchInsertBuilder{
+			Table:        q.GetTable("history_account_thresholds"),
+			MaxBatchSize: maxBatchSize,
+		},
+	}
+}
+
+// Add adds a new account thresholds change to the batch
+func (i *accountThresholdsBatchInsertBuilder) Add(
+	historyOperationID int64,
+	account string,
+	thresholds xdr.Thresholds,
+) error {
+	return i.builder.Row(map[string]interface{}{
+		"history_operation_id": historyOperationID,
+		"account":              account,
+		"master_weight":        int16(thresholds.MasterKeyWeight()),
+		"low_threshold":        int16(thresholds.ThresholdLow()),
+		"med_threshold":        int16(thresholds.ThresholdMedium()),
+		"high_threshold":       int16(thresholds.ThresholdHigh()),
+	})
+}
+
+func (i *accountThresholdsBatchInsertBuilder) Exec() error {
+	return i.builder.Exec()
+}
+
+// AccountThresholdsHistory returns a page of the changes of the master weight
+// and thresholds of the given account.
+func (q *Q) AccountThresholdsHistory(address string, page db2.PageQuery) ([]AccountThresholdsChange, error) {
+	sql, err := page.ApplyTo(
+		selectAccountThresholds.Where("hat.account = ?", address),
+		"hat.history_operation_id",
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not apply page query")
+	}
+
+	var changes []AccountThresholdsChange
+	if err := q.Select(&changes, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select account thresholds")
+	}
+	return changes, nil
+}
+
+var selectAccountThresholds = sq.Select(
+	"hat.history_operation_id",
+	"hat.account",
+	"hat.master_weight",
+	"hat.low_threshold",
+	"hat.med_threshold",
+	"hat.high_threshold",
+	"hl.closed_at as ledger_close_time",
+).
+	From("history_account_thresholds hat").
+	Join("history_ledgers hl ON hl.sequence = (hat.history_operation_id >> 32)::integer")
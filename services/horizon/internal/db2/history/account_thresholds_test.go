@@ -0,0 +1,59 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func TestAccountThresholdsHistory(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	account := "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+	firstID := toid.New(3, 1, 1).ToInt64()
+	secondID := toid.New(5, 1, 1).ToInt64()
+
+	batch := q.NewAccountThresholdsBatchInsertBuilder(0)
+	tt.Assert.NoError(batch.Add(secondID, account, xdr.Thresholds{0, 2, 2, 2}))
+	tt.Assert.NoError(batch.Add(firstID, account, xdr.Thresholds{1, 1, 2, 3}))
+	tt.Assert.NoError(batch.Add(
+		toid.New(4, 1, 1).ToInt64(),
+		"GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+		xdr.Thresholds{1, 0, 0, 0},
+	))
+	tt.Assert.NoError(batch.Exec())
+
+	page := db2.PageQuery{Order: db2.OrderAscending, Limit: 10}
+	changes, err := q.AccountThresholdsHistory(account, page)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(changes, 2) {
+		tt.Assert.Equal(firstID, changes[0].HistoryOperationID)
+		tt.Assert.Equal(int16(1), changes[0].MasterWeight)
+		tt.Assert.Equal(int16(1), changes[0].LowThreshold)
+		tt.Assert.Equal(int16(2), changes[0].MedThreshold)
+		tt.Assert.Equal(int16(3), changes[0].HighThreshold)
+		tt.Assert.False(changes[0].LedgerCloseTime.IsZero())
+
+		tt.Assert.Equal(secondID, changes[1].HistoryOperationID)
+		tt.Assert.Equal(int16(0), changes[1].MasterWeight)
+	}
+
+	page.Cursor = changes[0].PagingToken()
+	changes, err = q.AccountThresholdsHistory(account, page)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(changes, 1) {
+		tt.Assert.Equal(secondID, changes[0].HistoryOperationID)
+	}
+
+	page = db2.PageQuery{Order: db2.OrderDescending, Limit: 1}
+	changes, err = q.AccountThresholdsHistory(account, page)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(changes, 1) {
+		tt.Assert.Equal(secondID, changes[0].HistoryOperationID)
+	}
+}
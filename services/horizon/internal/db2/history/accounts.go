@@ -9,6 +9,12 @@ import (
 	"github.com/stellar/go/xdr"
 )
 
+// AuthClawbackEnabledFlag is the "AUTH_CLAWBACK_ENABLED" flag of CAP-35,
+// allowing the issuer to claw back its assets. It's not defined by the XDR of
+// the current protocol, accounts can only set it once the network supports
+// clawbacks.
+const AuthClawbackEnabledFlag xdr.AccountFlags = 8
+
 // IsAuthRequired returns true if the account has the "AUTH_REQUIRED" option
 // turned on.
 func (account AccountEntry) IsAuthRequired() bool {
@@ -27,6 +33,12 @@ func (account AccountEntry) IsAuthImmutable() bool {
 	return xdr.AccountFlags(account.Flags).IsAuthImmutable()
 }
 
+// IsAuthClawbackEnabled returns true if the account has the
+// "AUTH_CLAWBACK_ENABLED" option turned on.
+func (account AccountEntry) IsAuthClawbackEnabled() bool {
+	return xdr.AccountFlags(account.Flags)&AuthClawbackEnabledFlag != 0
+}
+
 func (q *Q) CountAccounts() (int, error) {
 	sql := sq.Select("count(*)").From("accounts")
 
@@ -39,7 +39,7 @@ func (q *Q) CreateAssets(assets []xdr.Asset, batchSize int) (map[string]Asset, e
 	builder := &db.BatchInsertBuilder{
 		Table:        q.GetTable("history_assets"),
 		MaxBatchSize: batchSize,
-		Suffix:       "ON CONFLICT (asset_code, asset_type, asset_issuer) DO NOTHING",
+		OnConflict:   &db.ConflictStrategy{Target: []string{"asset_code", "asset_type", "asset_issuer"}},
 	}
 
 	// sort assets before inserting rows into history_assets to prevent deadlocks on acquiring a ShareLock
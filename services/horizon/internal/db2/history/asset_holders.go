@@ -0,0 +1,98 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// GetAssetHolders returns a page of the trust lines with a positive balance
+// of the given asset. Rows are ordered by balance and then by account id,
+// matching the trust_lines_by_asset_balance index.
+func (q *Q) GetAssetHolders(ctx context.Context, asset xdr.Asset, page db2.PageQuery) ([]TrustLine, error) {
+	sql, err := assetHoldersSQL(asset, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var trustLines []TrustLine
+	if err := q.WithContext(ctx).Named("asset_holders").Select(&trustLines, sql); err != nil {
+		return nil, errors.Wrap(err, "could not run select query")
+	}
+	return trustLines, nil
+}
+
+func assetHoldersSQL(asset xdr.Asset, page db2.PageQuery) (sq.SelectBuilder, error) {
+	var assetType, code, issuer string
+	if err := asset.Extract(&assetType, &code, &issuer); err != nil {
+		return sq.SelectBuilder{}, errors.Wrap(err, "could not extract asset")
+	}
+
+	sql := selectTrustLines.Where(map[string]interface{}{
+		"trust_lines.asset_type":   int32(asset.Type),
+		"trust_lines.asset_code":   code,
+		"trust_lines.asset_issuer": issuer,
+	}).Where("trust_lines.balance > 0")
+
+	var cursorComparison, orderBy string
+	switch page.Order {
+	case "asc":
+		cursorComparison, orderBy = ">", "asc"
+	case "desc":
+		cursorComparison, orderBy = "<", "desc"
+	default:
+		return sql, fmt.Errorf("invalid page order %s", page.Order)
+	}
+
+	if page.Cursor != "" {
+		balance, accountID, err := ParseAssetHoldersCursor(page.Cursor)
+		if err != nil {
+			return sql, err
+		}
+
+		sql = sql.Where(
+			"((trust_lines.balance, trust_lines.account_id) "+cursorComparison+" (?::bigint,?))",
+			balance, accountID,
+		)
+	}
+
+	return sql.
+		OrderBy("trust_lines.balance "+orderBy, "trust_lines.account_id "+orderBy).
+		Limit(page.Limit), nil
+}
+
+// ParseAssetHoldersCursor parses a cursor of the form "<balance>_<account id>".
+func ParseAssetHoldersCursor(cursor string) (int64, string, error) {
+	parts := strings.SplitN(cursor, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("invalid asset holders cursor: %v", cursor)
+	}
+
+	balance, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || balance < 0 {
+		return 0, "", fmt.Errorf("invalid balance in asset holders cursor: %v", cursor)
+	}
+
+	var accountID xdr.AccountId
+	if err := accountID.SetAddress(parts[1]); err != nil {
+		return 0, "", errors.Wrap(
+			err,
+			fmt.Sprintf("invalid account id in asset holders cursor: %v", cursor),
+		)
+	}
+
+	return balance, parts[1], nil
+}
+
+// PagingToken returns a cursor for the trust line when listed as an asset
+// holder.
+func (trustLine TrustLine) PagingToken() string {
+	return fmt.Sprintf("%d_%s", trustLine.Balance, trustLine.AccountID)
+}
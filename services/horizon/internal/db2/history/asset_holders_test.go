@@ -0,0 +1,95 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAssetHoldersCursor(t *testing.T) {
+	balance, accountID, err := ParseAssetHoldersCursor("10000_" + usdTrustLine.AccountId.Address())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10000), balance)
+	assert.Equal(t, usdTrustLine.AccountId.Address(), accountID)
+
+	for _, cursor := range []string{
+		"10000",
+		"abc_" + usdTrustLine.AccountId.Address(),
+		"-1_" + usdTrustLine.AccountId.Address(),
+		"10000_GABC",
+	} {
+		_, _, err := ParseAssetHoldersCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+}
+
+func TestAssetHolders(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	richTrustLine := usdTrustLine
+	richTrustLine.AccountId = xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB")
+	richTrustLine.Balance = 20000
+
+	emptyTrustLine := usdTrustLine
+	emptyTrustLine.AccountId = account1.AccountId
+	emptyTrustLine.Balance = 0
+
+	for _, trustLine := range []xdr.TrustLineEntry{
+		eurTrustLine, usdTrustLine, usdTrustLine2, richTrustLine, emptyTrustLine,
+	} {
+		_, err := q.InsertTrustLine(trustLine, 1234)
+		tt.Assert.NoError(err)
+	}
+
+	// usdTrustLine and usdTrustLine2 have the same balance so they are
+	// ordered by account id
+	expected := []string{
+		usdTrustLine2.AccountId.Address(),
+		usdTrustLine.AccountId.Address(),
+		richTrustLine.AccountId.Address(),
+	}
+
+	lines, err := q.GetAssetHolders(tt.Ctx, usdTrustLine.Asset, db2.PageQuery{Order: "asc", Limit: 10})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(expected, assetHolderIDs(lines))
+
+	lines, err = q.GetAssetHolders(
+		tt.Ctx,
+		usdTrustLine.Asset,
+		db2.PageQuery{Order: "asc", Limit: 10, Cursor: assetHolderCursor(usdTrustLine2)},
+	)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(expected[1:], assetHolderIDs(lines))
+
+	lines, err = q.GetAssetHolders(tt.Ctx, usdTrustLine.Asset, db2.PageQuery{Order: "desc", Limit: 2})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]string{expected[2], expected[1]}, assetHolderIDs(lines))
+
+	_, err = q.GetAssetHolders(
+		tt.Ctx,
+		usdTrustLine.Asset,
+		db2.PageQuery{Order: "desc", Limit: 2, Cursor: "invalid"},
+	)
+	tt.Assert.Error(err)
+}
+
+func assetHolderCursor(trustLine xdr.TrustLineEntry) string {
+	return TrustLine{
+		AccountID: trustLine.AccountId.Address(),
+		Balance:   int64(trustLine.Balance),
+	}.PagingToken()
+}
+
+func assetHolderIDs(lines []TrustLine) []string {
+	ids := []string{}
+	for _, line := range lines {
+		ids = append(ids, line.AccountID)
+	}
+	return ids
+}
@@ -1,10 +1,12 @@
 package history
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
 	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
@@ -18,6 +20,8 @@ func assetStatToMap(assetStat ExpAssetStat) map[string]interface{} {
 		"asset_issuer": assetStat.AssetIssuer,
 		"amount":       assetStat.Amount,
 		"num_accounts": assetStat.NumAccounts,
+		"flags":        assetStat.Flags,
+		"home_domain":  assetStat.HomeDomain,
 	}
 }
 
@@ -52,13 +56,12 @@ func (q *Q) InsertAssetStats(assetStats []ExpAssetStat, batchSize int) error {
 // InsertAssetStat a single asset assetStat row into the exp_asset_stats
 // Returns number of rows affected and error.
 func (q *Q) InsertAssetStat(assetStat ExpAssetStat) (int64, error) {
-	sql := sq.Insert("exp_asset_stats").SetMap(assetStatToMap(assetStat))
-	result, err := q.Exec(sql)
-	if err != nil {
+	builder := &db.BatchInsertBuilder{Table: q.GetTable("exp_asset_stats")}
+	if err := builder.Row(assetStatToMap(assetStat)); err != nil {
 		return 0, err
 	}
 
-	return result.RowsAffected()
+	return builder.ExecRowsAffected()
 }
 
 // UpdateAssetStat updates a row in the exp_asset_stats table.
@@ -91,6 +94,30 @@ func (q *Q) RemoveAssetStat(assetType xdr.AssetType, assetCode, assetIssuer stri
 	return result.RowsAffected()
 }
 
+// UpdateAssetStatsIssuers copies the flags and the home domain of the given
+// issuers from the accounts table to the stats of their assets. The columns
+// are reset for issuers missing from the accounts table.
+// Returns number of rows affected and error.
+func (q *Q) UpdateAssetStatsIssuers(issuers []string) (int64, error) {
+	if len(issuers) == 0 {
+		return 0, nil
+	}
+
+	result, err := q.ExecRaw(`
+		UPDATE exp_asset_stats
+		SET flags = COALESCE(accounts.flags, 0), home_domain = COALESCE(accounts.home_domain, '')
+		FROM unnest(?::text[]) AS issuers(account_id)
+		LEFT JOIN accounts ON accounts.account_id = issuers.account_id
+		WHERE exp_asset_stats.asset_issuer = issuers.account_id`,
+		pq.Array(issuers),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
 // GetAssetStat returns a row in the exp_asset_stats table.
 func (q *Q) GetAssetStat(assetType xdr.AssetType, assetCode, assetIssuer string) (ExpAssetStat, error) {
 	sql := selectAssetStats.Where(map[string]interface{}{
@@ -134,19 +161,58 @@ func parseAssetStatsCursor(cursor string) (string, string, error) {
 	return code, issuer, nil
 }
 
-// GetAssetStats returns a page of exp_asset_stats rows.
-func (q *Q) GetAssetStats(assetCode, assetIssuer string, page db2.PageQuery) ([]ExpAssetStat, error) {
-	sql := selectAssetStats
-	filters := map[string]interface{}{}
-	if assetCode != "" {
-		filters["asset_code"] = assetCode
+// AssetStatsFilter defines the filters of an asset stats query. Zero values
+// leave the corresponding filter unset, all the filters which are set must
+// match.
+type AssetStatsFilter struct {
+	// AssetCode only includes assets with the given code.
+	AssetCode string
+	// AssetIssuer only includes assets issued by the given account.
+	AssetIssuer string
+	// IssuerFlagsSet only includes assets whose issuer has all the given
+	// flags set.
+	IssuerFlagsSet xdr.AccountFlags
+	// IssuerFlagsUnset only includes assets whose issuer has none of the
+	// given flags set.
+	IssuerFlagsUnset xdr.AccountFlags
+	// SortBy is the order of the rows, it defines the format of the cursor
+	// of the page.
+	SortBy AssetStatsSort
+}
+
+// GetAssetStats returns a page of exp_asset_stats rows matching the filter.
+func (q *Q) GetAssetStats(ctx context.Context, filter AssetStatsFilter, page db2.PageQuery) ([]ExpAssetStat, error) {
+	sql, err := assetStatsSQL(filter, page)
+	if err != nil {
+		return nil, err
 	}
-	if assetIssuer != "" {
-		filters["asset_issuer"] = assetIssuer
+
+	var results []ExpAssetStat
+	if err := q.WithContext(ctx).Named("asset_stats").Select(&results, sql); err != nil {
+		return nil, errors.Wrap(err, "could not run select query")
 	}
+	return results, nil
+}
+
+func assetStatsSQL(filter AssetStatsFilter, page db2.PageQuery) (sq.SelectBuilder, error) {
+	sql := selectAssetStats
 
-	if len(filters) > 0 {
-		sql = sql.Where(filters)
+	sort, ok := assetStatsSortExpressions[filter.SortBy]
+	if !ok {
+		return sql, errors.Errorf("invalid asset stats sort: %s", filter.SortBy)
+	}
+
+	if filter.AssetCode != "" {
+		sql = sql.Where(sq.Eq{"asset_code": filter.AssetCode})
+	}
+	if filter.AssetIssuer != "" {
+		sql = sql.Where(sq.Eq{"asset_issuer": filter.AssetIssuer})
+	}
+	if filter.IssuerFlagsSet != 0 {
+		sql = sql.Where("flags & ? = ?", int32(filter.IssuerFlagsSet), int32(filter.IssuerFlagsSet))
+	}
+	if filter.IssuerFlagsUnset != 0 {
+		sql = sql.Where("flags & ? = 0", int32(filter.IssuerFlagsUnset))
 	}
 
 	var cursorComparison, orderBy string
@@ -156,26 +222,56 @@ func (q *Q) GetAssetStats(assetCode, assetIssuer string, page db2.PageQuery) ([]
 	case "desc":
 		cursorComparison, orderBy = "<", "desc"
 	default:
-		return nil, fmt.Errorf("invalid page order %s", page.Order)
+		return sql, fmt.Errorf("invalid page order %s", page.Order)
 	}
 
 	if page.Cursor != "" {
-		cursorCode, cursorIssuer, err := parseAssetStatsCursor(page.Cursor)
+		cursor := page.Cursor
+		var sortValue string
+		if sort.column != "" {
+			parts := strings.SplitN(cursor, "_", 2)
+			if len(parts) != 2 {
+				return sql, fmt.Errorf("invalid asset stats cursor: %v", cursor)
+			}
+			if !filter.SortBy.ValidCursorValue(parts[0]) {
+				return sql, fmt.Errorf("invalid %s in asset stats cursor: %v", filter.SortBy, cursor)
+			}
+			sortValue, cursor = parts[0], parts[1]
+		}
+
+		cursorCode, cursorIssuer, err := parseAssetStatsCursor(cursor)
 		if err != nil {
-			return nil, err
+			return sql, err
 		}
 
-		sql = sql.Where("((asset_code, asset_issuer) "+cursorComparison+" (?,?))", cursorCode, cursorIssuer)
+		if sort.column != "" {
+			sql = sql.Where(
+				"(("+sort.column+", asset_code, asset_issuer) "+cursorComparison+" ("+sort.cursorValue+",?,?))",
+				sortValue, cursorCode, cursorIssuer,
+			)
+		} else {
+			sql = sql.Where("((asset_code, asset_issuer) "+cursorComparison+" (?,?))", cursorCode, cursorIssuer)
+		}
 	}
 
-	sql = sql.OrderBy("(asset_code, asset_issuer) " + orderBy).Limit(page.Limit)
-
-	var results []ExpAssetStat
-	if err := q.Select(&results, sql); err != nil {
-		return nil, errors.Wrap(err, "could not run select query")
+	if sort.column != "" {
+		sql = sql.OrderBy("(" + sort.column + ", asset_code, asset_issuer) " + orderBy)
+	} else {
+		sql = sql.OrderBy("(asset_code, asset_issuer) " + orderBy)
 	}
+	return sql.Limit(page.Limit), nil
+}
 
-	return results, nil
+// assetStatsSortExpressions maps the sort orders to the expressions the rows
+// are sorted by before the asset code and issuer. The columns match the
+// indexes created in the 47_exp_asset_stats_sort_indexes migration.
+var assetStatsSortExpressions = map[AssetStatsSort]struct {
+	column      string
+	cursorValue string
+}{
+	AssetStatsSortByAsset:   {},
+	AssetStatsSortByHolders: {"num_accounts", "?::integer"},
+	AssetStatsSortByAmount:  {"(amount::numeric)", "?::numeric"},
 }
 
 var selectAssetStats = sq.Select("exp_asset_stats.*").From("exp_asset_stats")
@@ -228,6 +228,75 @@ func TestRemoveAssetStat(t *testing.T) {
 	tt.Assert.Equal(err, sql.ErrNoRows)
 }
 
+func TestUpdateAssetStatsIssuers(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+
+	q := &Q{tt.HorizonSession()}
+
+	issuer := xdr.AccountEntry{
+		AccountId:  xdr.MustAddress("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"),
+		Flags:      xdr.Uint32(xdr.AccountFlagsAuthRequiredFlag | xdr.AccountFlagsAuthRevocableFlag),
+		HomeDomain: "example.com",
+	}
+	batch := q.NewAccountsBatchInsertBuilder(0)
+	tt.Assert.NoError(batch.Add(issuer, 3))
+	tt.Assert.NoError(batch.Exec())
+
+	usdAssetStat := ExpAssetStat{
+		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+		AssetIssuer: issuer.AccountId.Address(),
+		AssetCode:   "USD",
+		Amount:      "1",
+		NumAccounts: 2,
+	}
+	// the issuer account doesn't exist
+	otherAssetStat := ExpAssetStat{
+		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+		AssetIssuer: "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2",
+		AssetCode:   "EUR",
+		Amount:      "1",
+		NumAccounts: 1,
+		Flags:       uint32(xdr.AccountFlagsAuthRequiredFlag),
+		HomeDomain:  "removed.com",
+	}
+	tt.Assert.NoError(q.InsertAssetStats([]ExpAssetStat{usdAssetStat, otherAssetStat}, 10))
+
+	numChanged, err := q.UpdateAssetStatsIssuers(nil)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(0), numChanged)
+
+	numChanged, err = q.UpdateAssetStatsIssuers([]string{usdAssetStat.AssetIssuer, otherAssetStat.AssetIssuer})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(2), numChanged)
+
+	usdAssetStat.Flags = uint32(issuer.Flags)
+	usdAssetStat.HomeDomain = "example.com"
+	otherAssetStat.Flags = 0
+	otherAssetStat.HomeDomain = ""
+
+	got, err := q.GetAssetStat(usdAssetStat.AssetType, usdAssetStat.AssetCode, usdAssetStat.AssetIssuer)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(usdAssetStat, got)
+	got, err = q.GetAssetStat(otherAssetStat.AssetType, otherAssetStat.AssetCode, otherAssetStat.AssetIssuer)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(otherAssetStat, got)
+
+	page := db2.PageQuery{Order: "asc", Limit: 10}
+	results, err := q.GetAssetStats(tt.Ctx, AssetStatsFilter{
+		IssuerFlagsSet: xdr.AccountFlagsAuthRevocableFlag,
+	}, page)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ExpAssetStat{usdAssetStat}, results)
+
+	results, err = q.GetAssetStats(tt.Ctx, AssetStatsFilter{
+		IssuerFlagsUnset: xdr.AccountFlagsAuthRequiredFlag,
+	}, page)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ExpAssetStat{otherAssetStat}, results)
+}
+
 func TestGetAssetStatsCursorValidation(t *testing.T) {
 	tt := test.Start(t)
 	defer tt.Finish()
@@ -277,7 +346,7 @@ func TestGetAssetStatsCursorValidation(t *testing.T) {
 				Order:  "asc",
 				Limit:  5,
 			}
-			results, err := q.GetAssetStats("", "", page)
+			results, err := q.GetAssetStats(tt.Ctx, AssetStatsFilter{}, page)
 			tt.Assert.Empty(results)
 			tt.Assert.NotNil(err)
 			tt.Assert.Contains(err.Error(), testCase.expectedError)
@@ -296,7 +365,7 @@ func TestGetAssetStatsOrderValidation(t *testing.T) {
 		Order: "invalid",
 		Limit: 5,
 	}
-	results, err := q.GetAssetStats("", "", page)
+	results, err := q.GetAssetStats(tt.Ctx, AssetStatsFilter{}, page)
 	tt.Assert.Empty(results)
 	tt.Assert.NotNil(err)
 	tt.Assert.Contains(err.Error(), "invalid page order")
@@ -586,17 +655,21 @@ func TestGetAssetStatsFiltersAndCursor(t *testing.T) {
 		},
 	} {
 		t.Run(testCase.name, func(t *testing.T) {
+			filter := AssetStatsFilter{
+				AssetCode:   testCase.assetCode,
+				AssetIssuer: testCase.assetIssuer,
+			}
 			page := db2.PageQuery{
 				Order:  testCase.order,
 				Cursor: testCase.cursor,
 				Limit:  5,
 			}
-			results, err := q.GetAssetStats(testCase.assetCode, testCase.assetIssuer, page)
+			results, err := q.GetAssetStats(tt.Ctx, filter, page)
 			tt.Assert.NoError(err)
 			tt.Assert.Equal(testCase.expected, results)
 
 			page.Limit = 1
-			results, err = q.GetAssetStats(testCase.assetCode, testCase.assetIssuer, page)
+			results, err = q.GetAssetStats(tt.Ctx, filter, page)
 			tt.Assert.NoError(err)
 			if len(testCase.expected) == 0 {
 				tt.Assert.Equal(testCase.expected, results)
@@ -608,7 +681,7 @@ func TestGetAssetStatsFiltersAndCursor(t *testing.T) {
 				page = page.Invert()
 				page.Limit = 5
 
-				results, err = q.GetAssetStats(testCase.assetCode, testCase.assetIssuer, page)
+				results, err = q.GetAssetStats(tt.Ctx, filter, page)
 				tt.Assert.NoError(err)
 				reverseAssetStats(results)
 				tt.Assert.Equal(testCase.expected, results)
@@ -616,3 +689,69 @@ func TestGetAssetStatsFiltersAndCursor(t *testing.T) {
 		})
 	}
 }
+
+func TestAssetStatsSortBy(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+
+	q := &Q{tt.HorizonSession()}
+
+	small := ExpAssetStat{
+		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+		AssetIssuer: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+		AssetCode:   "AAA",
+		Amount:      "9",
+		NumAccounts: 5,
+	}
+	large := ExpAssetStat{
+		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+		AssetIssuer: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+		AssetCode:   "BBB",
+		// larger than int64 and sorted numerically, not lexically
+		Amount:      "100000000000000000000",
+		NumAccounts: 1,
+	}
+	tt.Assert.NoError(q.InsertAssetStats([]ExpAssetStat{small, large}, 1))
+
+	byAmount := AssetStatsFilter{SortBy: AssetStatsSortByAmount}
+	byHolders := AssetStatsFilter{SortBy: AssetStatsSortByHolders}
+	page := db2.PageQuery{Order: "desc", Limit: 5}
+	results, err := q.GetAssetStats(tt.Ctx, byAmount, page)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ExpAssetStat{large, small}, results)
+
+	results, err = q.GetAssetStats(tt.Ctx, byHolders, page)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ExpAssetStat{small, large}, results)
+
+	page.Cursor = small.PagingTokenFor(AssetStatsSortByHolders)
+	results, err = q.GetAssetStats(tt.Ctx, byHolders, page)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ExpAssetStat{large}, results)
+
+	// amount cursors can exceed 64 bits
+	page.Cursor = large.PagingTokenFor(AssetStatsSortByAmount)
+	results, err = q.GetAssetStats(tt.Ctx, byAmount, page)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ExpAssetStat{small}, results)
+
+	page.Order = "asc"
+	page.Cursor = small.PagingTokenFor(AssetStatsSortByAmount)
+	results, err = q.GetAssetStats(tt.Ctx, byAmount, page)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ExpAssetStat{large}, results)
+
+	page.Cursor = "-1_" + small.PagingToken()
+	_, err = q.GetAssetStats(tt.Ctx, byAmount, page)
+	tt.Assert.EqualError(err, "invalid amount in asset stats cursor: -1_"+small.PagingToken())
+
+	page.Order = "desc"
+	page.Cursor = small.PagingToken()
+	_, err = q.GetAssetStats(tt.Ctx, byHolders, page)
+	tt.Assert.Error(err)
+	tt.Assert.Contains(err.Error(), "invalid holders in asset stats cursor")
+
+	_, err = q.GetAssetStats(tt.Ctx, AssetStatsFilter{SortBy: "trades"}, page)
+	tt.Assert.EqualError(err, "invalid asset stats sort: trades")
+}
@@ -0,0 +1,209 @@
+package history
+
+import (
+	"fmt"
+	"math/big"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	// AssetWatchHolders watches the number of authorized trust lines of an
+	// asset.
+	AssetWatchHolders = "holders"
+	// AssetWatchHolderShare watches the largest balance held by a single
+	// authorized trust line of an asset, as a fraction of the amount of the
+	// asset held by authorized trust lines.
+	AssetWatchHolderShare = "holder_share"
+)
+
+// AssetWatch is a threshold on the trust lines of an asset which notifies a
+// webhook when it is exceeded. TriggeredLedger is the ledger which exceeded
+// the threshold, it's nil when the threshold is not exceeded.
+type AssetWatch struct {
+	ID              int64         `db:"id"`
+	AssetType       xdr.AssetType `db:"asset_type"`
+	AssetCode       string        `db:"asset_code"`
+	AssetIssuer     string        `db:"asset_issuer"`
+	Kind            string        `db:"kind"`
+	Threshold       float64       `db:"threshold"`
+	WebhookURL      string        `db:"webhook_url"`
+	TriggeredLedger *int32        `db:"triggered_ledger"`
+}
+
+// AssetWatchEvent is a watch whose threshold was exceeded by the ledger
+// Ledger. Holder is the account holding the largest share of the asset for
+// AssetWatchHolderShare watches.
+type AssetWatchEvent struct {
+	AssetWatch
+	Ledger uint32
+	Value  float64
+	Holder string
+}
+
+type assetWatchValues struct {
+	AssetWatch
+	NumAccounts int32   `db:"num_accounts"`
+	Amount      string  `db:"amount"`
+	Holder      *string `db:"holder"`
+	Balance     *int64  `db:"balance"`
+}
+
+// value returns the value of the watched quantity.
+func (v assetWatchValues) value() (float64, error) {
+	if v.Kind == AssetWatchHolders {
+		return float64(v.NumAccounts), nil
+	}
+	if v.Balance == nil {
+		return 0, nil
+	}
+
+	amount, ok := new(big.Float).SetString(v.Amount)
+	if !ok {
+		return 0, errors.Errorf("invalid asset amount: %s", v.Amount)
+	}
+	if amount.Sign() <= 0 {
+		return 0, nil
+	}
+	share, _ := new(big.Float).Quo(big.NewFloat(float64(*v.Balance)), amount).Float64()
+	return share, nil
+}
+
+var selectAssetWatch = sq.Select(
+	"w.id",
+	"w.asset_type",
+	"w.asset_code",
+	"w.asset_issuer",
+	"w.kind",
+	"w.threshold",
+	"w.webhook_url",
+	"w.triggered_ledger",
+).From("asset_watches w")
+
+// AssetWatches loads all the asset watches.
+func (q *Q) AssetWatches() ([]AssetWatch, error) {
+	var watches []AssetWatch
+	err := q.Select(&watches, selectAssetWatch.OrderBy("w.id asc"))
+	return watches, err
+}
+
+// AddAssetWatch registers a watch notifying webhookURL when the quantity of
+// the given kind exceeds threshold for asset. Returns the id of the watch.
+func (q *Q) AddAssetWatch(asset xdr.Asset, kind string, threshold float64, webhookURL string) (int64, error) {
+	var assetType, code, issuer string
+	if err := asset.Extract(&assetType, &code, &issuer); err != nil {
+		return 0, errors.Wrap(err, "could not extract asset")
+	}
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		return 0, errors.New("native asset can not be watched")
+	}
+
+	switch kind {
+	case AssetWatchHolders:
+		if threshold < 0 {
+			return 0, errors.New("threshold must not be negative")
+		}
+	case AssetWatchHolderShare:
+		if threshold < 0 || threshold > 1 {
+			return 0, errors.New("threshold must be between 0 and 1")
+		}
+	default:
+		return 0, errors.Errorf("invalid watch kind: %s", kind)
+	}
+
+	var id int64
+	err := q.GetRaw(&id, `
+		INSERT INTO asset_watches (asset_type, asset_code, asset_issuer, kind, threshold, webhook_url)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		asset.Type, code, issuer, kind, threshold, webhookURL,
+	)
+	return id, err
+}
+
+// RemoveAssetWatch removes the watch with the given id. Returns the number of
+// rows affected.
+func (q *Q) RemoveAssetWatch(id int64) (int64, error) {
+	result, err := q.Exec(sq.Delete("asset_watches").Where(sq.Eq{"id": id}))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// topHolders selects the authorized trust line holding the largest balance
+// of each asset with a holder_share watch. Only authorized trust lines are
+// considered because the amount of the asset in exp_asset_stats, which the
+// balance is compared to, is held by authorized trust lines. Assets with
+// several watches are scanned once.
+var topHolders = fmt.Sprintf(`
+	SELECT a.asset_type, a.asset_code, a.asset_issuer, top.account_id, top.balance
+	FROM (
+		SELECT DISTINCT asset_type, asset_code, asset_issuer FROM asset_watches WHERE kind = '%s'
+	) a
+	JOIN LATERAL (
+		SELECT tl.account_id, tl.balance FROM trust_lines tl
+		WHERE tl.asset_type = a.asset_type AND tl.asset_code = a.asset_code AND tl.asset_issuer = a.asset_issuer
+		AND tl.flags & %d <> 0
+		ORDER BY tl.balance DESC, tl.account_id ASC LIMIT 1
+	) top ON true`,
+	AssetWatchHolderShare,
+	xdr.TrustLineFlagsAuthorizedFlag,
+)
+
+// EvaluateAssetWatches compares the asset watches with the state of the trust
+// lines after ingesting ledger and returns the watches whose threshold was
+// exceeded for the first time. A watch fires again once its value fell back
+// to or below the threshold. It must be called in the ingestion transaction
+// so the triggered state is committed with the ledger.
+func (q *Q) EvaluateAssetWatches(ledger uint32) ([]AssetWatchEvent, error) {
+	sql := selectAssetWatch.Columns(
+		"COALESCE(s.num_accounts, 0) AS num_accounts",
+		"COALESCE(s.amount, '0') AS amount",
+		"top.account_id AS holder",
+		"top.balance",
+	).
+		LeftJoin("exp_asset_stats s ON " +
+			"s.asset_type = w.asset_type AND s.asset_code = w.asset_code AND s.asset_issuer = w.asset_issuer").
+		LeftJoin("(" + topHolders + ") top ON w.kind = '" + AssetWatchHolderShare + "' AND " +
+			"top.asset_type = w.asset_type AND top.asset_code = w.asset_code AND top.asset_issuer = w.asset_issuer").
+		OrderBy("w.id asc")
+
+	var rows []assetWatchValues
+	if err := q.Select(&rows, sql); err != nil {
+		return nil, errors.Wrap(err, "could not load asset watches")
+	}
+
+	var events []AssetWatchEvent
+	for _, row := range rows {
+		value, err := row.value()
+		if err != nil {
+			return nil, err
+		}
+
+		exceeded := value > row.Threshold
+		switch {
+		case exceeded && row.TriggeredLedger == nil:
+			event := AssetWatchEvent{AssetWatch: row.AssetWatch, Ledger: ledger, Value: value}
+			if row.Kind == AssetWatchHolderShare && row.Holder != nil {
+				event.Holder = *row.Holder
+			}
+			events = append(events, event)
+			_, err = q.Exec(sq.Update("asset_watches").
+				Set("triggered_ledger", ledger).
+				Where(sq.Eq{"id": row.ID}))
+		case !exceeded && row.TriggeredLedger != nil:
+			_, err = q.Exec(sq.Update("asset_watches").
+				Set("triggered_ledger", nil).
+				Where(sq.Eq{"id": row.ID}))
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not update asset watch")
+		}
+	}
+
+	return events, nil
+}
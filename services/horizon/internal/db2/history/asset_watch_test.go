@@ -0,0 +1,99 @@
+package history
+
+import (
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/xdr"
+)
+
+func TestAssetWatches(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	usd := usdTrustLine.Asset
+	_, err := q.AddAssetWatch(xdr.MustNewNativeAsset(), AssetWatchHolders, 1, "http://localhost")
+	tt.Assert.EqualError(err, "native asset can not be watched")
+	_, err = q.AddAssetWatch(usd, AssetWatchHolderShare, 5, "http://localhost")
+	tt.Assert.EqualError(err, "threshold must be between 0 and 1")
+	_, err = q.AddAssetWatch(usd, "supply", 5, "http://localhost")
+	tt.Assert.EqualError(err, "invalid watch kind: supply")
+
+	holders, err := q.AddAssetWatch(usd, AssetWatchHolders, 1, "http://localhost/holders")
+	tt.Require.NoError(err)
+	share, err := q.AddAssetWatch(usd, AssetWatchHolderShare, 0.3, "http://localhost/share")
+	tt.Require.NoError(err)
+
+	watches, err := q.AssetWatches()
+	tt.Require.NoError(err)
+	tt.Require.Len(watches, 2)
+	tt.Assert.Equal("USDUSD", watches[0].AssetCode)
+	tt.Assert.Nil(watches[0].TriggeredLedger)
+
+	// no trust lines, no events
+	events, err := q.EvaluateAssetWatches(10)
+	tt.Require.NoError(err)
+	tt.Assert.Empty(events)
+
+	authorized := usdTrustLine
+	authorized.Flags = xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag)
+	_, err = q.InsertTrustLine(authorized, 10)
+	tt.Require.NoError(err)
+	authorized2 := usdTrustLine2
+	authorized2.Flags = xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag)
+	_, err = q.InsertTrustLine(authorized2, 10)
+	tt.Require.NoError(err)
+	// unauthorized trust lines are not counted in the asset stats so they
+	// are not holders
+	unauthorized := usdTrustLine
+	unauthorized.AccountId = xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB")
+	unauthorized.Balance = 20000
+	_, err = q.InsertTrustLine(unauthorized, 10)
+	tt.Require.NoError(err)
+	_, err = q.InsertAssetStat(ExpAssetStat{
+		AssetType:   usd.Type,
+		AssetCode:   "USDUSD",
+		AssetIssuer: trustLineIssuer.Address(),
+		Amount:      "20000",
+		NumAccounts: 2,
+	})
+	tt.Require.NoError(err)
+
+	events, err = q.EvaluateAssetWatches(11)
+	tt.Require.NoError(err)
+	tt.Require.Len(events, 2)
+	tt.Assert.Equal(holders, events[0].ID)
+	tt.Assert.Equal(float64(2), events[0].Value)
+	tt.Assert.Equal(uint32(11), events[0].Ledger)
+	tt.Assert.Equal(share, events[1].ID)
+	tt.Assert.Equal(usdTrustLine2.AccountId.Address(), events[1].Holder)
+	tt.Assert.Equal(0.5, events[1].Value)
+
+	// watches only fire when the threshold is exceeded for the first time
+	events, err = q.EvaluateAssetWatches(12)
+	tt.Require.NoError(err)
+	tt.Assert.Empty(events)
+
+	// and are rearmed when the value falls back below the threshold
+	_, err = q.Exec(sq.Update("exp_asset_stats").Set("num_accounts", 1))
+	tt.Require.NoError(err)
+	events, err = q.EvaluateAssetWatches(13)
+	tt.Require.NoError(err)
+	tt.Assert.Empty(events)
+	_, err = q.Exec(sq.Update("exp_asset_stats").Set("num_accounts", 3))
+	tt.Require.NoError(err)
+	events, err = q.EvaluateAssetWatches(14)
+	tt.Require.NoError(err)
+	tt.Require.Len(events, 1)
+	tt.Assert.Equal(holders, events[0].ID)
+
+	rows, err := q.RemoveAssetWatch(holders)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(int64(1), rows)
+	watches, err = q.AssetWatches()
+	tt.Require.NoError(err)
+	tt.Assert.Len(watches, 1)
+}
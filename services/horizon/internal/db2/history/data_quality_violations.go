@@ -0,0 +1,78 @@
+package history
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+)
+
+// DataQualityViolation is a row of data from the
+// `history_data_quality_violations` table. Violations are recorded when a
+// transaction breaks one of the data quality rules checked during ingestion.
+type DataQualityViolation struct {
+	HistoryTransactionID int64  `db:"history_transaction_id"`
+	TransactionHash      string `db:"transaction_hash"`
+	Rule                 string `db:"rule"`
+	Details              string `db:"details"`
+}
+
+// QDataQualityViolations defines data quality violations related queries used
+// by ingestion.
+type QDataQualityViolations interface {
+	NewDataQualityViolationsBatchInsertBuilder(maxBatchSize int) DataQualityViolationsBatchInsertBuilder
+}
+
+// DataQualityViolationsBatchInsertBuilder is used to insert data quality
+// violations into the history_data_quality_violations table.
+type DataQualityViolationsBatchInsertBuilder interface {
+	Add(violation DataQualityViolation) error
+	Exec() error
+}
+
+// dataQualityViolationsBatchInsertBuilder is a simple wrapper around db.BatchInsertBuilder
+type dataQualityViolationsBatchInsertBuilder struct {
+	builder db.BatchInsertBuilder
+}
+
+// NewDataQualityViolationsBatchInsertBuilder constructs a new DataQualityViolationsBatchInsertBuilder instance
+func (q *Q) NewDataQualityViolationsBatchInsertBuilder(maxBatchSize int) DataQualityViolationsBatchInsertBuilder {
+	return &dataQualityViolationsBatchInsertBuilder{
+		builder: db.BatchInsertBuilder{
+			Table:        q.GetTable("history_data_quality_violations"),
+			MaxBatchSize: maxBatchSize,
+		},
+	}
+}
+
+// Add adds a new data quality violation to the batch
+func (i *dataQualityViolationsBatchInsertBuilder) Add(violation DataQualityViolation) error {
+	return i.builder.RowStruct(violation)
+}
+
+func (i *dataQualityViolationsBatchInsertBuilder) Exec() error {
+	return i.builder.Exec()
+}
+
+// DataQualityViolations returns the data quality violations of transactions
+// in ledgers in the [fromLedger, toLedger] range (closed interval) ordered by
+// transaction.
+func (q *Q) DataQualityViolations(fromLedger, toLedger int32) ([]DataQualityViolation, error) {
+	var violations []DataQualityViolation
+	sql := sq.Select(
+		"hdqv.history_transaction_id",
+		"hdqv.transaction_hash",
+		"hdqv.rule",
+		"hdqv.details",
+	).
+		From("history_data_quality_violations hdqv").
+		Where("hdqv.history_transaction_id >= ? AND hdqv.history_transaction_id < ?",
+			toid.ID{LedgerSequence: fromLedger}.ToInt64(),
+			toid.ID{LedgerSequence: toLedger + 1}.ToInt64(),
+		).
+		OrderBy("hdqv.history_transaction_id asc, hdqv.rule asc")
+	if err := q.Select(&violations, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select data quality violations")
+	}
+	return violations, nil
+}
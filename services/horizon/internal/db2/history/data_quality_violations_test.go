@@ -0,0 +1,45 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/services/horizon/internal/toid"
+)
+
+func TestDataQualityViolations(t *testing.T) {
+	tt := test.Start(t).Scenario("base")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	first := DataQualityViolation{
+		HistoryTransactionID: toid.New(3, 1, 0).ToInt64(),
+		TransactionHash:      "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d",
+		Rule:                 "trade_amounts",
+		Details:              "operation 0: trade of offer 3 has amount sold 100 and amount bought 0",
+	}
+	second := DataQualityViolation{
+		HistoryTransactionID: toid.New(5, 2, 0).ToInt64(),
+		TransactionHash:      "cebb875a00ff6e1383aef0fd251a76f22c1f9ab2a2dffcb077855736ade2659a",
+		Rule:                 "fee_charged",
+		Details:              "fee charged 200 is not in [0, 100]",
+	}
+
+	batch := q.NewDataQualityViolationsBatchInsertBuilder(0)
+	tt.Assert.NoError(batch.Add(second))
+	tt.Assert.NoError(batch.Add(first))
+	tt.Assert.NoError(batch.Exec())
+
+	violations, err := q.DataQualityViolations(1, 5)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]DataQualityViolation{first, second}, violations)
+
+	violations, err = q.DataQualityViolations(4, 4)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(violations, 0)
+
+	tt.Assert.NoError(q.DeleteRangeAll(toid.New(5, 0, 0).ToInt64(), toid.New(6, 0, 0).ToInt64()))
+	violations, err = q.DataQualityViolations(1, 5)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]DataQualityViolation{first}, violations)
+}
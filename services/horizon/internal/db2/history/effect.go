@@ -1,6 +1,7 @@
 package history
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -10,6 +11,7 @@ import (
 	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/services/horizon/internal/toid"
 	"github.com/stellar/go/support/errors"
+	strtime "github.com/stellar/go/support/time"
 )
 
 // UnmarshalDetails unmarshals the details of this effect into `dest`
@@ -37,137 +39,222 @@ func (r *Effect) PagingToken() string {
 	return fmt.Sprintf("%d-%d", r.HistoryOperationID, r.Order)
 }
 
+// EffectsFilter defines the filters of an effects query. Zero values leave
+// the corresponding filter unset, all the filters which are set must match.
+type EffectsFilter struct {
+	// AccountID only includes effects of the given account.
+	AccountID string
+	// LedgerSequence only includes effects of the given ledger.
+	LedgerSequence int32
+	// OperationID only includes effects of the given operation.
+	OperationID int64
+	// TransactionHash only includes effects of the transaction with the
+	// given hex-encoded hash.
+	TransactionHash string
+	// Types only includes effects of the given types.
+	Types []EffectType
+	// StartTime and EndTime only include effects in ledgers closed within
+	// [StartTime, EndTime).
+	StartTime strtime.Millis
+	EndTime   strtime.Millis
+}
+
+// GetEffects returns a page of effects matching the filter. Unlike the
+// EffectsQ builder, errors of the lookups required by the filters (for
+// example the account of AccountID) are returned immediately.
+func (q *Q) GetEffects(ctx context.Context, filter EffectsFilter, page db2.PageQuery) ([]Effect, error) {
+	return q.selectEffects(ctx, filter, &page)
+}
+
+func (q *Q) selectEffects(ctx context.Context, filter EffectsFilter, page *db2.PageQuery) ([]Effect, error) {
+	q = &Q{q.WithContext(ctx).Replica()}
+
+	sql, err := q.effectsSQL(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if page != nil {
+		sql, err = pageEffects(sql, *page)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var effects []Effect
+	if err := q.Named("effects").Select(&effects, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select effects")
+	}
+	return effects, nil
+}
+
+func (q *Q) effectsSQL(filter EffectsFilter) (sq.SelectBuilder, error) {
+	sql := selectEffect
+
+	if filter.AccountID != "" {
+		var account Account
+		if err := q.AccountByAddress(&account, filter.AccountID); err != nil {
+			return sql, errors.Wrap(err, "could not load account")
+		}
+
+		sql = sql.Where("heff.history_account_id = ?", account.ID)
+	}
+
+	if filter.LedgerSequence != 0 {
+		var ledger Ledger
+		if err := q.LedgerBySequence(&ledger, filter.LedgerSequence); err != nil {
+			return sql, errors.Wrap(err, "could not load ledger")
+		}
+
+		start := toid.ID{LedgerSequence: filter.LedgerSequence}
+		end := toid.ID{LedgerSequence: filter.LedgerSequence + 1}
+		sql = sql.Where(
+			"heff.history_operation_id >= ? AND heff.history_operation_id < ?",
+			start.ToInt64(),
+			end.ToInt64(),
+		)
+	}
+
+	if filter.OperationID != 0 {
+		start := toid.Parse(filter.OperationID)
+		end := start
+		end.IncOperationOrder()
+		sql = sql.Where(
+			"heff.history_operation_id >= ? AND heff.history_operation_id < ?",
+			start.ToInt64(),
+			end.ToInt64(),
+		)
+	}
+
+	if filter.TransactionHash != "" {
+		var tx Transaction
+		if err := q.TransactionByHash(&tx, filter.TransactionHash); err != nil {
+			return sql, errors.Wrap(err, "could not load transaction")
+		}
+
+		start := toid.Parse(tx.ID)
+		end := start
+		end.TransactionOrder++
+		sql = sql.Where(
+			"heff.history_operation_id >= ? AND heff.history_operation_id < ?",
+			start.ToInt64(),
+			end.ToInt64(),
+		)
+	}
+
+	if len(filter.Types) > 0 {
+		sql = sql.Where(sq.Eq{"heff.type": filter.Types})
+	}
+
+	// The time range is translated into operation id bounds using the first
+	// and the last ledger closed in the range so the history operation id
+	// index can be used.
+	if !filter.StartTime.IsNil() {
+		sql = sql.Where(
+			"heff.history_operation_id >= ((SELECT MIN(hl.sequence) FROM history_ledgers hl WHERE hl.closed_at >= ?)::bigint << 32)",
+			filter.StartTime.ToTime(),
+		)
+	}
+
+	if !filter.EndTime.IsNil() {
+		sql = sql.Where(
+			"heff.history_operation_id < (((SELECT MAX(hl.sequence) FROM history_ledgers hl WHERE hl.closed_at < ?) + 1)::bigint << 32)",
+			filter.EndTime.ToTime(),
+		)
+	}
+
+	return sql, nil
+}
+
+// effectsPager pages through effects, it's using the multicolumn index on
+// history_operation_id and order.
+var effectsPager = db2.NewKeysetPager("heff.history_operation_id", "heff.order")
+
+func pageEffects(sql sq.SelectBuilder, page db2.PageQuery) (sq.SelectBuilder, error) {
+	op, idx, err := page.CursorInt64Pair(db2.DefaultPairSep)
+	if err != nil {
+		return sql, err
+	}
+
+	if idx > math.MaxInt32 {
+		idx = math.MaxInt32
+	}
+
+	sql, err = effectsPager.ApplyTo(sql, page.Order, op, idx)
+	if err != nil {
+		return sql, err
+	}
+
+	return sql.Limit(page.Limit), nil
+}
+
 // Effects provides a helper to filter rows from the `history_effects`
-// table with pre-defined filters.  See `TransactionsQ` methods for the
+// table with pre-defined filters.  See `EffectsQ` methods for the
 // available filters.
+//
+// Deprecated: EffectsQ is a wrapper of GetEffects kept during the migration
+// to the context aware API, use GetEffects instead.
 func (q *Q) Effects() *EffectsQ {
 	return &EffectsQ{
 		parent: q,
-		sql:    selectEffect,
 	}
 }
 
 // ForAccount filters the operations collection to a specific account
 func (q *EffectsQ) ForAccount(aid string) *EffectsQ {
-	var account Account
-	q.Err = q.parent.AccountByAddress(&account, aid)
-	if q.Err != nil {
-		return q
-	}
-
-	q.sql = q.sql.Where("heff.history_account_id = ?", account.ID)
-
+	q.filter.AccountID = aid
 	return q
 }
 
 // ForLedger filters the query to only effects in a specific ledger,
 // specified by its sequence.
 func (q *EffectsQ) ForLedger(seq int32) *EffectsQ {
-	var ledger Ledger
-	q.Err = q.parent.LedgerBySequence(&ledger, seq)
-	if q.Err != nil {
-		return q
-	}
-
-	start := toid.ID{LedgerSequence: seq}
-	end := toid.ID{LedgerSequence: seq + 1}
-	q.sql = q.sql.Where(
-		"heff.history_operation_id >= ? AND heff.history_operation_id < ?",
-		start.ToInt64(),
-		end.ToInt64(),
-	)
-
+	q.filter.LedgerSequence = seq
 	return q
 }
 
 // ForOperation filters the query to only effects in a specific operation,
 // specified by its id.
 func (q *EffectsQ) ForOperation(id int64) *EffectsQ {
-	start := toid.Parse(id)
-	end := start
-	end.IncOperationOrder()
-	q.sql = q.sql.Where(
-		"heff.history_operation_id >= ? AND heff.history_operation_id < ?",
-		start.ToInt64(),
-		end.ToInt64(),
-	)
-
+	q.filter.OperationID = id
 	return q
 }
 
 // ForTransaction filters the query to only effects in a specific
 // transaction, specified by the transactions's hex-encoded hash.
 func (q *EffectsQ) ForTransaction(hash string) *EffectsQ {
-	var tx Transaction
-	q.Err = q.parent.TransactionByHash(&tx, hash)
-	if q.Err != nil {
-		return q
-	}
+	q.filter.TransactionHash = hash
+	return q
+}
 
-	start := toid.Parse(tx.ID)
-	end := start
-	end.TransactionOrder++
-	q.sql = q.sql.Where(
-		"heff.history_operation_id >= ? AND heff.history_operation_id < ?",
-		start.ToInt64(),
-		end.ToInt64(),
-	)
+// ForTimeRange filters the query to only effects in ledgers closed within
+// [start, end). A nil time leaves its bound open.
+func (q *EffectsQ) ForTimeRange(start, end strtime.Millis) *EffectsQ {
+	q.filter.StartTime = start
+	q.filter.EndTime = end
+	return q
+}
 
+// ForTypes filters the query to only effects of the provided types. Calling
+// it without any type leaves the query unfiltered.
+func (q *EffectsQ) ForTypes(types ...EffectType) *EffectsQ {
+	q.filter.Types = append(q.filter.Types, types...)
 	return q
 }
 
 // Page specifies the paging constraints for the query being built by `q`.
 func (q *EffectsQ) Page(page db2.PageQuery) *EffectsQ {
-	if q.Err != nil {
-		return q
-	}
-
-	op, idx, err := page.CursorInt64Pair(db2.DefaultPairSep)
-	if err != nil {
-		q.Err = err
-		return q
-	}
-
-	if idx > math.MaxInt32 {
-		idx = math.MaxInt32
-	}
-
-	// NOTE: Remember to test the queries below with EXPLAIN / EXPLAIN ANALYZE
-	// before changing them.
-	// This condition is using multicolumn index and it's easy to write it in a way that
-	// DB will perform a full table scan.
-	switch page.Order {
-	case "asc":
-		q.sql = q.sql.
-			Where(`(
-					 heff.history_operation_id >= ?
-				AND (
-					 heff.history_operation_id > ? OR
-					(heff.history_operation_id = ? AND heff.order > ?)
-				))`, op, op, op, idx).
-			OrderBy("heff.history_operation_id asc, heff.order asc")
-	case "desc":
-		q.sql = q.sql.
-			Where(`(
-					 heff.history_operation_id <= ?
-				AND (
-					 heff.history_operation_id < ? OR
-					(heff.history_operation_id = ? AND heff.order < ?)
-				))`, op, op, op, idx).
-			OrderBy("heff.history_operation_id desc, heff.order desc")
-	}
-
-	q.sql = q.sql.Limit(page.Limit)
+	q.page = &page
 	return q
 }
 
 // Select loads the results of the query specified by `q` into `dest`.
-func (q *EffectsQ) Select(dest interface{}) error {
+func (q *EffectsQ) Select(dest *[]Effect) error {
 	if q.Err != nil {
 		return q.Err
 	}
 
-	q.Err = q.parent.Select(dest, q.sql)
+	*dest, q.Err = q.parent.selectEffects(q.parent.Ctx, q.filter, q.page)
 	return q.Err
 }
 
@@ -0,0 +1,49 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/errors"
+)
+
+func TestGetEffects(t *testing.T) {
+	tt := test.Start(t).Scenario("base")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+	page := db2.PageQuery{Order: "asc", Limit: 20}
+
+	effects, err := q.GetEffects(context.Background(), EffectsFilter{}, page)
+	tt.Require.NoError(err)
+	tt.Assert.Len(effects, 11)
+
+	effects, err = q.GetEffects(context.Background(), EffectsFilter{
+		LedgerSequence: 2,
+		Types:          []EffectType{EffectAccountCreated},
+	}, page)
+	tt.Require.NoError(err)
+	tt.Assert.Len(effects, 3)
+
+	// lookup errors are returned immediately
+	_, err = q.GetEffects(context.Background(), EffectsFilter{
+		AccountID: "GB5FZF7VGVO5KI5DDWL6VGUX6JDB5SQKTWN4ZUB6NHMUA2YQGWDEHALK",
+	}, page)
+	tt.Assert.Equal(sql.ErrNoRows, errors.Cause(err))
+
+	// the deprecated builder returns the same error from Select
+	err = q.Effects().
+		ForAccount("GB5FZF7VGVO5KI5DDWL6VGUX6JDB5SQKTWN4ZUB6NHMUA2YQGWDEHALK").
+		Page(page).
+		Select(&effects)
+	tt.Assert.Equal(sql.ErrNoRows, errors.Cause(err))
+
+	_, err = q.GetEffects(context.Background(), EffectsFilter{}, db2.PageQuery{
+		Cursor: "invalid",
+		Order:  "asc",
+		Limit:  20,
+	})
+	tt.Assert.Error(err)
+}
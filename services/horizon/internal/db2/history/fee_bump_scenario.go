@@ -244,8 +244,8 @@ func FeeBumpScenario(tt *test.T, q *Q, successful bool) FeeBumpFixture {
 	insertBuilder := q.NewTransactionBatchInsertBuilder(2)
 	// include both fee bump and normal transaction in the same batch
 	// to make sure both kinds of transactions can be inserted using a single exec statement
-	tt.Assert.NoError(insertBuilder.Add(feeBumpTransaction, sequence))
-	tt.Assert.NoError(insertBuilder.Add(normalTransaction, sequence))
+	tt.Assert.NoError(insertBuilder.Add(feeBumpTransaction, sequence, TransactionCounts{}))
+	tt.Assert.NoError(insertBuilder.Add(normalTransaction, sequence, TransactionCounts{}))
 	tt.Assert.NoError(insertBuilder.Exec())
 
 	account := fixture.Envelope.SourceAccount().ToAccountId()
@@ -0,0 +1,207 @@
+package history
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// HistoryOffer is a row of data from the `history_offers` table: a snapshot
+// of an offer at the end of ledger `LedgerSequence`. The snapshot is valid
+// until `ValidTo` (exclusive), the ledger of the next snapshot of the offer,
+// or is the latest known state of the offer if `ValidTo` is null. `Deleted`
+// snapshots contain the last state of removed offers.
+type HistoryOffer struct {
+	OfferID        int64     `db:"offer_id"`
+	LedgerSequence uint32    `db:"ledger_sequence"`
+	ValidTo        null.Int  `db:"valid_to"`
+	SellerID       string    `db:"seller_id"`
+	SellingAsset   xdr.Asset `db:"selling_asset"`
+	BuyingAsset    xdr.Asset `db:"buying_asset"`
+	Amount         xdr.Int64 `db:"amount"`
+	Pricen         int32     `db:"pricen"`
+	Priced         int32     `db:"priced"`
+	Price          float64   `db:"price"`
+	Flags          uint32    `db:"flags"`
+	Deleted        bool      `db:"deleted"`
+}
+
+// QHistoryOffers defines history offers related queries used by ingestion.
+type QHistoryOffers interface {
+	NewHistoryOffersBatchInsertBuilder(maxBatchSize int) HistoryOffersBatchInsertBuilder
+}
+
+// HistoryOffersBatchInsertBuilder is used to insert offer snapshots into the
+// history_offers table.
+type HistoryOffersBatchInsertBuilder interface {
+	Add(offer xdr.OfferEntry, ledgerSequence uint32, deleted bool) error
+	Exec() error
+}
+
+// historyOffersBatchInsertBuilder is a simple wrapper around db.BatchInsertBuilder
+type historyOffersBatchInsertBuilder struct {
+	builder db.BatchInsertBuilder
+}
+
+// NewHistoryOffersBatchInsertBuilder constructs a new HistoryOffersBatchInsertBuilder instance
+func (q *Q) NewHistoryOffersBatchInsertBuilder(maxBatchSize int) HistoryOffersBatchInsertBuilder {
+	return &historyOffersBatchInsertBuilder{
+		builder: db.BatchInsertBuilder{
+			Table:        q.GetTable("history_offers"),
+			MaxBatchSize: maxBatchSize,
+		},
+	}
+}
+
+// Add adds a snapshot of the offer at the end of ledger `ledgerSequence` to
+// the batch. `deleted` should be true when the offer was removed in the ledger,
+// `offer` is then its last state.
+func (i *historyOffersBatchInsertBuilder) Add(offer xdr.OfferEntry, ledgerSequence uint32, deleted bool) error {
+	var price float64
+	if offer.Price.D == 0 {
+		return errors.New("offer price denominator is zero")
+	} else if offer.Price.N > 0 {
+		price = float64(offer.Price.N) / float64(offer.Price.D)
+	}
+
+	return i.builder.Row(map[string]interface{}{
+		"offer_id":        offer.OfferId,
+		"ledger_sequence": ledgerSequence,
+		"seller_id":       offer.SellerId.Address(),
+		"selling_asset":   offer.Selling,
+		"buying_asset":    offer.Buying,
+		"amount":          offer.Amount,
+		"pricen":          offer.Price.N,
+		"priced":          offer.Price.D,
+		"price":           price,
+		"flags":           offer.Flags,
+		"deleted":         deleted,
+	})
+}
+
+func (i *historyOffersBatchInsertBuilder) Exec() error {
+	return i.builder.Exec()
+}
+
+// HistoryOffersFilter defines the filters of a history offers query. Zero
+// values leave the corresponding filter unset, all the filters which are set
+// must match.
+type HistoryOffersFilter struct {
+	// OfferID only includes snapshots of a single offer.
+	OfferID int64
+	// SellerID only includes snapshots of offers created by the given
+	// account.
+	SellerID string
+	// Selling and Buying only include snapshots of offers selling Selling for
+	// Buying. They must be set together. The assets of an offer can be
+	// changed by updating it, so the snapshots with other assets still bound
+	// the validity of the matching ones.
+	Selling *xdr.Asset
+	Buying  *xdr.Asset
+	// AtLedger returns the state of offers at the end of the given ledger:
+	// for every offer only the snapshot valid in that ledger is returned and
+	// offers that were removed before (or in) the ledger are skipped.
+	AtLedger uint32
+}
+
+// GetHistoryOffers returns the snapshots of offers matching the filter.
+// Rows are ordered by offer id and ledger sequence.
+func (q *Q) GetHistoryOffers(ctx context.Context, filter HistoryOffersFilter) ([]HistoryOffer, error) {
+	query, err := historyOffersSQL(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var offers []HistoryOffer
+	if err := q.WithContext(ctx).Named("history_offers").Select(&offers, query); err != nil {
+		return nil, errors.Wrap(err, "could not select history offers")
+	}
+	return offers, nil
+}
+
+func historyOffersSQL(filter HistoryOffersFilter) (sq.SelectBuilder, error) {
+	snapshots := selectHistoryOffers
+	if filter.OfferID != 0 {
+		snapshots = snapshots.Where("ho.offer_id = ?", filter.OfferID)
+	}
+	if filter.SellerID != "" {
+		snapshots = snapshots.Where("ho.seller_id = ?", filter.SellerID)
+	}
+
+	// assetsFilter is applied after valid_to is computed, it filters on
+	// columns which can change between the snapshots of an offer.
+	var assetsFilter sq.Sqlizer
+	if filter.Selling != nil || filter.Buying != nil {
+		if filter.Selling == nil || filter.Buying == nil {
+			return snapshots, errors.New("selling and buying assets must be set together")
+		}
+
+		sellingB64, err := xdr.MarshalBase64(*filter.Selling)
+		if err != nil {
+			return snapshots, errors.Wrap(err, "cannot marshal selling asset")
+		}
+		buyingB64, err := xdr.MarshalBase64(*filter.Buying)
+		if err != nil {
+			return snapshots, errors.Wrap(err, "cannot marshal buying asset")
+		}
+
+		assetsFilter = sq.Eq{
+			"selling_asset": sellingB64,
+			"buying_asset":  buyingB64,
+		}
+	}
+
+	// valid_to has to be computed on all the snapshots of an offer before
+	// selecting the ones valid in the requested ledger. Snapshots after the
+	// ledger don't bound the validity of the ones before it, and only the
+	// offers with a snapshot matching the filters are needed, so neither is
+	// scanned.
+	if filter.AtLedger > 0 {
+		snapshots = snapshots.Where("ho.ledger_sequence <= ?", filter.AtLedger)
+	}
+	if assetsFilter != nil {
+		offers := sq.Select("offer_id").From("history_offers").Where(assetsFilter)
+		if filter.AtLedger > 0 {
+			offers = offers.Where("ledger_sequence <= ?", filter.AtLedger)
+		}
+		offersSQL, args, err := offers.ToSql()
+		if err != nil {
+			return snapshots, errors.Wrap(err, "could not build offers query")
+		}
+		snapshots = snapshots.Where("ho.offer_id IN ("+offersSQL+")", args...)
+	}
+
+	query := sq.Select("*").
+		FromSelect(snapshots, "snapshots").
+		OrderBy("offer_id asc", "ledger_sequence asc")
+	if filter.AtLedger > 0 {
+		query = query.
+			Where("ledger_sequence <= ?", filter.AtLedger).
+			Where("(valid_to IS NULL OR valid_to > ?)", filter.AtLedger).
+			Where("deleted = false")
+	}
+	if assetsFilter != nil {
+		query = query.Where(assetsFilter)
+	}
+
+	return query, nil
+}
+
+var selectHistoryOffers = sq.Select(
+	"ho.offer_id",
+	"ho.ledger_sequence",
+	"LEAD(ho.ledger_sequence) OVER (PARTITION BY ho.offer_id ORDER BY ho.ledger_sequence) as valid_to",
+	"ho.seller_id",
+	"ho.selling_asset",
+	"ho.buying_asset",
+	"ho.amount",
+	"ho.pricen",
+	"ho.priced",
+	"ho.price",
+	"ho.flags",
+	"ho.deleted",
+).From("history_offers ho")
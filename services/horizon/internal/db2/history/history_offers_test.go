@@ -0,0 +1,112 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func TestHistoryOffers(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	usd := xdr.MustNewCreditAsset("USD", issuer.Address())
+	offer := func(id xdr.Int64, amount xdr.Int64) xdr.OfferEntry {
+		return xdr.OfferEntry{
+			SellerId: issuer,
+			OfferId:  id,
+			Selling:  nativeAsset,
+			Buying:   usd,
+			Amount:   amount,
+			Price:    xdr.Price{N: 1, D: 2},
+		}
+	}
+
+	batch := q.NewHistoryOffersBatchInsertBuilder(0)
+	tt.Assert.NoError(batch.Add(offer(1, 100), 10, false))
+	tt.Assert.NoError(batch.Add(offer(1, 80), 12, false))
+	tt.Assert.NoError(batch.Add(offer(1, 80), 15, true))
+	tt.Assert.NoError(batch.Add(offer(2, 10), 11, false))
+	tt.Assert.NoError(batch.Exec())
+
+	snapshots, err := q.GetHistoryOffers(tt.Ctx, HistoryOffersFilter{OfferID: 1})
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(snapshots, 3) {
+		tt.Assert.Equal(uint32(10), snapshots[0].LedgerSequence)
+		tt.Assert.Equal(int64(12), snapshots[0].ValidTo.Int64)
+		tt.Assert.Equal(xdr.Int64(80), snapshots[1].Amount)
+		tt.Assert.Equal(int64(15), snapshots[1].ValidTo.Int64)
+		tt.Assert.True(snapshots[2].Deleted)
+		tt.Assert.False(snapshots[2].ValidTo.Valid)
+	}
+
+	snapshots, err = q.GetHistoryOffers(tt.Ctx, HistoryOffersFilter{AtLedger: 11})
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(snapshots, 2) {
+		tt.Assert.Equal(int64(1), snapshots[0].OfferID)
+		tt.Assert.Equal(xdr.Int64(100), snapshots[0].Amount)
+		tt.Assert.Equal(int64(2), snapshots[1].OfferID)
+	}
+
+	// offer 1 was removed in ledger 15
+	snapshots, err = q.GetHistoryOffers(tt.Ctx, HistoryOffersFilter{
+		Selling:  &nativeAsset,
+		Buying:   &usd,
+		AtLedger: 15,
+	})
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(snapshots, 1) {
+		tt.Assert.Equal(int64(2), snapshots[0].OfferID)
+	}
+
+	snapshots, err = q.GetHistoryOffers(tt.Ctx, HistoryOffersFilter{Selling: &usd, Buying: &nativeAsset})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(snapshots, 0)
+
+	_, err = q.GetHistoryOffers(tt.Ctx, HistoryOffersFilter{Selling: &usd})
+	tt.Assert.EqualError(err, "selling and buying assets must be set together")
+
+	// snapshots are removed with other history when ranges are deleted
+	err = q.DeleteRangeAll(
+		toid.ID{LedgerSequence: 12}.ToInt64(),
+		toid.ID{LedgerSequence: 16}.ToInt64(),
+	)
+	tt.Assert.NoError(err)
+	snapshots, err = q.GetHistoryOffers(tt.Ctx, HistoryOffersFilter{})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(snapshots, 2)
+
+	// the assets of offer 3 are changed in ledger 22, its first snapshot is
+	// not valid after that even if only the first snapshot matches the assets
+	updated := offer(3, 10)
+	updated.Selling, updated.Buying = usd, nativeAsset
+	batch = q.NewHistoryOffersBatchInsertBuilder(0)
+	tt.Assert.NoError(batch.Add(offer(3, 10), 20, false))
+	tt.Assert.NoError(batch.Add(updated, 22, false))
+	tt.Assert.NoError(batch.Exec())
+
+	snapshots, err = q.GetHistoryOffers(tt.Ctx, HistoryOffersFilter{
+		OfferID:  3,
+		Selling:  &nativeAsset,
+		Buying:   &usd,
+		AtLedger: 23,
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(snapshots, 0)
+
+	snapshots, err = q.GetHistoryOffers(tt.Ctx, HistoryOffersFilter{
+		OfferID:  3,
+		Selling:  &usd,
+		Buying:   &nativeAsset,
+		AtLedger: 23,
+	})
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(snapshots, 1) {
+		tt.Assert.Equal(int64(3), snapshots[0].OfferID)
+		tt.Assert.Equal(uint32(22), snapshots[0].LedgerSequence)
+	}
+}
@@ -1,17 +1,45 @@
 package history
 
+import (
+	sq "github.com/Masterminds/squirrel"
+)
+
 // TruncateExpingestStateTables clears out ingestion state tables.
 // Ingestion state tables are horizon database tables populated by
 // the ingestion system using history archive snapshots.
 // Any horizon database tables which cannot be populated using
 // history archive snapshots will not be truncated.
 func (q *Q) TruncateExpingestStateTables() error {
-	return q.TruncateTables([]string{
+	err := q.TruncateTables([]string{
 		"accounts",
 		"accounts_data",
 		"accounts_signers",
 		"exp_asset_stats",
+		"exp_state_quarantine",
 		"offers",
 		"trust_lines",
 	})
+	if err != nil {
+		return err
+	}
+
+	// the last state audit was of the truncated state
+	_, err = q.Exec(sq.Delete("key_value_store").Where(sq.Eq{"key": stateAuditLedger}))
+	return err
+}
+
+// TruncateHistoryTables clears out the history tables written to when
+// ingesting ledgers, along with the reingestion checkpoints and the ledger
+// gap claims which refer to their ledgers. history_accounts and
+// history_assets are kept: they only map addresses and assets to ids, and
+// truncating them would cascade to the tables configured by operators which
+// reference them (ex. trade_retention_policies).
+func (q *Q) TruncateHistoryTables() error {
+	tables := []string{"ledger_gap_claims", "reingest_checkpoints"}
+	for _, table := range ingestedHistoryTables {
+		if table != "history_accounts" && table != "history_assets" {
+			tables = append(tables, table)
+		}
+	}
+	return q.TruncateTables(tables)
 }
@@ -0,0 +1,32 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestTruncateHistoryTablesClearsReingestionState(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	tt.Assert.NoError(q.UpdateReingestCheckpoint(1, 100, 1, 50))
+	gap := LedgerGap{StartSequence: 10, EndSequence: 20}
+	_, ok, err := q.ClaimLedgerGap([]LedgerGap{gap}, time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.True(ok)
+
+	tt.Assert.NoError(q.TruncateHistoryTables())
+
+	checkpoints, err := q.GetReingestCheckpoints(1, 100)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(checkpoints)
+
+	// the gap claimed on the previous network can be claimed again
+	_, ok, err = q.ClaimLedgerGap([]LedgerGap{gap}, time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.True(ok)
+}
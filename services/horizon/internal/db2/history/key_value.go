@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
 )
 
@@ -16,6 +17,8 @@ const (
 	lastLedgerKey           = "exp_ingest_last_ledger"
 	stateInvalid            = "exp_state_invalid"
 	offerCompactionSequence = "offer_compaction_sequence"
+	networkPassphrase       = "exp_network_passphrase"
+	stateAuditLedger        = "exp_state_audit_ledger"
 )
 
 // GetLastLedgerExpIngestNonBlocking works like GetLastLedgerExpIngest but
@@ -159,6 +162,37 @@ func (q *Q) UpdateOfferCompactionSequence(sequence uint32) error {
 	)
 }
 
+// GetNetworkPassphrase returns the passphrase of the network the ledgers in
+// the database were ingested from, or "" if it was not recorded yet.
+func (q *Q) GetNetworkPassphrase() (string, error) {
+	return q.getValueFromStore(networkPassphrase, false)
+}
+
+// UpdateNetworkPassphrase records the passphrase of the network the ledgers
+// in the database are ingested from.
+func (q *Q) UpdateNetworkPassphrase(passphrase string) error {
+	return q.updateValueInStore(networkPassphrase, passphrase)
+}
+
+// GetStateAuditLedger returns the checkpoint ledger of the last state audit,
+// or 0 if the state was never audited.
+func (q *Q) GetStateAuditLedger() (uint32, error) {
+	sequence, err := q.getValueFromStore(stateAuditLedger, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if sequence == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseUint(sequence, 10, 32)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error converting sequence value")
+	}
+
+	return uint32(parsed), nil
+}
+
 // getValueFromStore returns a value for a given key from KV store. If value
 // is not present in the key value store "" will be returned.
 func (q *Q) getValueFromStore(key string, forUpdate bool) (string, error) {
@@ -183,11 +217,20 @@ func (q *Q) getValueFromStore(key string, forUpdate bool) (string, error) {
 
 // updateValueInStore updates a value for a given key in KV store
 func (q *Q) updateValueInStore(key, value string) error {
-	query := sq.Insert("key_value_store").
-		Columns("key", "value").
-		Values(key, value).
-		Suffix("ON CONFLICT (key) DO UPDATE SET value=EXCLUDED.value")
+	builder := &db.BatchInsertBuilder{
+		Table: q.GetTable("key_value_store"),
+		OnConflict: &db.ConflictStrategy{
+			Target: []string{"key"},
+			Update: []string{"value"},
+		},
+	}
+	err := builder.Row(map[string]interface{}{
+		"key":   key,
+		"value": value,
+	})
+	if err != nil {
+		return err
+	}
 
-	_, err := q.Exec(query)
-	return err
+	return builder.Exec()
 }
@@ -1,6 +1,7 @@
 package history
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"time"
@@ -9,7 +10,9 @@ import (
 	"github.com/guregu/null"
 	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
+	strtime "github.com/stellar/go/support/time"
 	"github.com/stellar/go/xdr"
 )
 
@@ -22,12 +25,98 @@ func (q *Q) LedgerBySequence(dest interface{}, seq int32) error {
 	return q.Get(dest, sql)
 }
 
+// GetLedgerHash returns the hash of the ledger at sequence, or "" when the
+// ledger is not in the `history_ledgers` table.
+func (q *Q) GetLedgerHash(sequence uint32) (string, error) {
+	var hash string
+	err := q.Get(&hash, sq.Select("ledger_hash").
+		From("history_ledgers").
+		Where("sequence = ?", sequence))
+	if q.NoRows(err) {
+		return "", nil
+	}
+	return hash, err
+}
+
+// LedgerGap is a range of ledgers (closed interval) missing from the
+// `history_ledgers` table.
+type LedgerGap struct {
+	StartSequence uint32 `db:"gap_start" json:"from_ledger"`
+	EndSequence   uint32 `db:"gap_end" json:"to_ledger"`
+}
+
+// GetLedgerGaps returns the gaps between the oldest and the latest ledger of
+// the `history_ledgers` table, ordered by sequence.
+func (q *Q) GetLedgerGaps() ([]LedgerGap, error) {
+	var gaps []LedgerGap
+	err := q.SelectRaw(&gaps, `
+		SELECT sequence + 1 AS gap_start, next_sequence - 1 AS gap_end
+		FROM (
+			SELECT sequence, LEAD(sequence) OVER (ORDER BY sequence) AS next_sequence
+			FROM history_ledgers
+		) AS sequences
+		WHERE next_sequence - sequence > 1
+		ORDER BY sequence`)
+	return gaps, err
+}
+
+// CountLedgersInRange returns the number of ledgers of the `history_ledgers`
+// table between fromSeq and toSeq (closed interval).
+func (q *Q) CountLedgersInRange(fromSeq, toSeq uint32) (int, error) {
+	var count int
+	err := q.Get(&count, sq.Select("COUNT(*)").From("history_ledgers").
+		Where("sequence BETWEEN ? AND ?", fromSeq, toSeq))
+	return count, err
+}
+
+// LedgersFilter defines the filters of a ledgers query. Zero values leave
+// the corresponding filter unset, all the filters which are set must match.
+type LedgersFilter struct {
+	// StartTime and EndTime only include ledgers closed within
+	// [StartTime, EndTime).
+	StartTime strtime.Millis
+	EndTime   strtime.Millis
+}
+
+// GetLedgers returns a page of ledgers matching the filter.
+func (q *Q) GetLedgers(ctx context.Context, filter LedgersFilter, page db2.PageQuery) ([]Ledger, error) {
+	var ledgers []Ledger
+	if err := q.selectLedgers(ctx, filter, &page, &ledgers); err != nil {
+		return nil, err
+	}
+	return ledgers, nil
+}
+
+func (q *Q) selectLedgers(ctx context.Context, filter LedgersFilter, page *db2.PageQuery, dest interface{}) error {
+	sql := selectLedger
+	if !filter.StartTime.IsNil() {
+		sql = sql.Where("hl.closed_at >= ?", filter.StartTime.ToTime())
+	}
+	if !filter.EndTime.IsNil() {
+		sql = sql.Where("hl.closed_at < ?", filter.EndTime.ToTime())
+	}
+
+	if page != nil {
+		var err error
+		if sql, err = page.ApplyTo(sql, "hl.id"); err != nil {
+			return err
+		}
+	}
+
+	if err := q.WithContext(ctx).Named("ledgers").Select(dest, sql); err != nil {
+		return errors.Wrap(err, "could not select ledgers")
+	}
+	return nil
+}
+
 // Ledgers provides a helper to filter rows from the `history_ledgers` table
 // with pre-defined filters.  See `LedgersQ` methods for the available filters.
+//
+// Deprecated: LedgersQ is a wrapper of GetLedgers kept during the migration
+// to the context aware API, use GetLedgers instead.
 func (q *Q) Ledgers() *LedgersQ {
 	return &LedgersQ{
 		parent: q,
-		sql:    selectLedger,
 	}
 }
 
@@ -67,21 +156,18 @@ func (q *Q) LedgerCapacityUsageStats(currentSeq int32, dest *LedgerCapacityUsage
 
 // Page specifies the paging constraints for the query being built by `q`.
 func (q *LedgersQ) Page(page db2.PageQuery) *LedgersQ {
-	if q.Err != nil {
-		return q
-	}
-
-	q.sql, q.Err = page.ApplyTo(q.sql, "hl.id")
+	q.page = &page
 	return q
 }
 
-// Select loads the results of the query specified by `q` into `dest`.
-func (q *LedgersQ) Select(dest interface{}) error {
+// Select loads the results of the query specified by `q` into `dest`. The
+// query is cancelled when ctx is done.
+func (q *LedgersQ) Select(ctx context.Context, dest interface{}) error {
 	if q.Err != nil {
 		return q.Err
 	}
 
-	q.Err = q.parent.Select(dest, q.sql)
+	q.Err = q.parent.selectLedgers(ctx, q.filter, q.page, dest)
 	return q.Err
 }
 
@@ -119,13 +205,12 @@ func (q *Q) InsertLedger(
 		return 0, err
 	}
 
-	sql := sq.Insert("history_ledgers").SetMap(m)
-	result, err := q.Exec(sql)
-	if err != nil {
+	builder := &db.BatchInsertBuilder{Table: q.GetTable("history_ledgers")}
+	if err = builder.Row(m); err != nil {
 		return 0, err
 	}
 
-	return result.RowsAffected()
+	return builder.ExecRowsAffected()
 }
 
 func ledgerHeaderToMap(
@@ -0,0 +1,75 @@
+package history
+
+import (
+	"database/sql"
+
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/errors"
+)
+
+// LedgerExport holds all the history rows of a ledger: its transactions,
+// including the failed ones, operations, trades and effects, in the order of
+// their ids.
+type LedgerExport struct {
+	Ledger       Ledger
+	Transactions []Transaction
+	Operations   []Operation
+	Trades       []Trade
+	Effects      []Effect
+}
+
+// LedgerExport loads the history rows of the ledger with the given sequence.
+// The rows are loaded in a repeatable read transaction so they belong to the
+// same version of the ledger even when it's reingested concurrently.
+func (q *Q) LedgerExport(dest *LedgerExport, sequence uint32) error {
+	start, end, err := toid.LedgerRangeInclusive(int32(sequence), int32(sequence))
+	if err != nil {
+		return errors.Wrap(err, "invalid ledger")
+	}
+
+	exportQ := &Q{q.Clone()}
+	err = exportQ.BeginTx(&sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer exportQ.Rollback()
+
+	var export LedgerExport
+	if err = exportQ.LedgerBySequence(&export.Ledger, int32(sequence)); err != nil {
+		return err
+	}
+
+	err = exportQ.Select(&export.Transactions, selectTransaction.
+		Where("ht.id >= ? AND ht.id < ?", start, end).
+		OrderBy("ht.id asc"))
+	if err != nil {
+		return errors.Wrap(err, "could not load transactions")
+	}
+
+	err = exportQ.Select(&export.Operations, selectOperation.
+		Where("hop.id >= ? AND hop.id < ?", start, end).
+		OrderBy("hop.id asc"))
+	if err != nil {
+		return errors.Wrap(err, "could not load operations")
+	}
+
+	err = exportQ.Select(&export.Trades, selectTrades(selectTradeFields).
+		Where("htrd.history_operation_id >= ? AND htrd.history_operation_id < ?", start, end).
+		OrderBy("htrd.history_operation_id asc", "htrd.\"order\" asc"))
+	if err != nil {
+		return errors.Wrap(err, "could not load trades")
+	}
+
+	err = exportQ.Select(&export.Effects, selectEffect.
+		Where("heff.history_operation_id >= ? AND heff.history_operation_id < ?", start, end).
+		OrderBy("heff.history_operation_id asc", "heff.\"order\" asc"))
+	if err != nil {
+		return errors.Wrap(err, "could not load effects")
+	}
+
+	*dest = export
+	return nil
+}
@@ -0,0 +1,42 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/services/horizon/internal/toid"
+)
+
+func TestLedgerExport(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	var export LedgerExport
+	err := q.LedgerExport(&export, 1000)
+	tt.Assert.True(q.NoRows(err))
+
+	tt.Assert.NoError(q.LedgerExport(&export, 24))
+	tt.Assert.Equal(int32(24), export.Ledger.Sequence)
+	tt.Assert.NotEmpty(export.Transactions)
+	tt.Assert.NotEmpty(export.Operations)
+	tt.Assert.NotEmpty(export.Trades)
+
+	start, end, err := toid.LedgerRangeInclusive(24, 24)
+	tt.Assert.NoError(err)
+	for _, transaction := range export.Transactions {
+		tt.Assert.Equal(int32(24), transaction.LedgerSequence)
+	}
+	for i, operation := range export.Operations {
+		tt.Assert.True(operation.ID >= start && operation.ID < end)
+		if i > 0 {
+			tt.Assert.True(export.Operations[i-1].ID < operation.ID)
+		}
+	}
+	for _, trade := range export.Trades {
+		tt.Assert.True(trade.HistoryOperationID >= start && trade.HistoryOperationID < end)
+	}
+	for _, effect := range export.Effects {
+		tt.Assert.True(effect.HistoryOperationID >= start && effect.HistoryOperationID < end)
+	}
+}
@@ -0,0 +1,85 @@
+package history
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/support/errors"
+)
+
+// ledgerGapClaimsLockID is the key of the advisory lock held while a ledger
+// gap is claimed, so instances don't claim overlapping gaps.
+const ledgerGapClaimsLockID = 7304926104271513001
+
+// ClaimLedgerGap claims the first gap of gaps which doesn't overlap a gap
+// claimed by another instance. Claims not refreshed for staleAfter are
+// removed. It returns false if all the gaps are claimed. The claims are
+// locked for the duration of its transaction only, the claimed gap must be
+// refreshed with RefreshLedgerGapClaim while it's backfilled and released
+// with ReleaseLedgerGapClaim afterwards.
+func (q *Q) ClaimLedgerGap(gaps []LedgerGap, staleAfter time.Duration) (LedgerGap, bool, error) {
+	if err := q.Begin(); err != nil {
+		return LedgerGap{}, false, errors.Wrap(err, "could not start transaction")
+	}
+	defer q.Rollback()
+
+	if _, err := q.ExecRaw("SELECT pg_advisory_xact_lock(?)", ledgerGapClaimsLockID); err != nil {
+		return LedgerGap{}, false, errors.Wrap(err, "could not lock ledger gap claims")
+	}
+
+	_, err := q.Exec(sq.Delete("ledger_gap_claims").
+		Where("claimed_at < ?", time.Now().UTC().Add(-staleAfter)))
+	if err != nil {
+		return LedgerGap{}, false, errors.Wrap(err, "could not remove stale ledger gap claims")
+	}
+
+	var claims []LedgerGap
+	err = q.Select(&claims, sq.Select("start_sequence AS gap_start", "end_sequence AS gap_end").
+		From("ledger_gap_claims"))
+	if err != nil {
+		return LedgerGap{}, false, errors.Wrap(err, "could not load ledger gap claims")
+	}
+
+	for _, gap := range gaps {
+		if gap.overlapsAny(claims) {
+			continue
+		}
+		_, err = q.Exec(sq.Insert("ledger_gap_claims").
+			Columns("start_sequence", "end_sequence", "claimed_at").
+			Values(gap.StartSequence, gap.EndSequence, time.Now().UTC()))
+		if err != nil {
+			return LedgerGap{}, false, errors.Wrap(err, "could not claim ledger gap")
+		}
+		if err = q.Commit(); err != nil {
+			return LedgerGap{}, false, errors.Wrap(err, "could not commit ledger gap claim")
+		}
+		return gap, true, nil
+	}
+	return LedgerGap{}, false, nil
+}
+
+func (g LedgerGap) overlapsAny(others []LedgerGap) bool {
+	for _, other := range others {
+		if g.StartSequence <= other.EndSequence && other.StartSequence <= g.EndSequence {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshLedgerGapClaim records that the claimed gap is still being
+// backfilled.
+func (q *Q) RefreshLedgerGapClaim(gap LedgerGap) error {
+	_, err := q.Exec(sq.Update("ledger_gap_claims").
+		Set("claimed_at", time.Now().UTC()).
+		Where(sq.Eq{"start_sequence": gap.StartSequence, "end_sequence": gap.EndSequence}))
+	return err
+}
+
+// ReleaseLedgerGapClaim removes the claim of gap once it's backfilled, or
+// failed to be, so it can be claimed again.
+func (q *Q) ReleaseLedgerGapClaim(gap LedgerGap) error {
+	_, err := q.Exec(sq.Delete("ledger_gap_claims").
+		Where(sq.Eq{"start_sequence": gap.StartSequence, "end_sequence": gap.EndSequence}))
+	return err
+}
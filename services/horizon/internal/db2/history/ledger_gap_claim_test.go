@@ -0,0 +1,55 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestClaimLedgerGap(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+	other := &Q{tt.HorizonSession()}
+
+	gaps := []LedgerGap{
+		{StartSequence: 5, EndSequence: 6},
+		{StartSequence: 10, EndSequence: 20},
+	}
+	gap, ok, err := q.ClaimLedgerGap(gaps, time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.True(ok)
+	tt.Assert.Equal(gaps[0], gap)
+
+	// another instance claims the next gap, even when its scan found a
+	// different range overlapping the claimed one
+	gap, ok, err = other.ClaimLedgerGap([]LedgerGap{{StartSequence: 6, EndSequence: 6}, gaps[1]}, time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.True(ok)
+	tt.Assert.Equal(gaps[1], gap)
+
+	_, ok, err = q.ClaimLedgerGap(gaps, time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.False(ok)
+
+	// released gaps can be claimed again
+	tt.Assert.NoError(q.ReleaseLedgerGapClaim(gaps[0]))
+	gap, ok, err = other.ClaimLedgerGap(gaps, time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.True(ok)
+	tt.Assert.Equal(gaps[0], gap)
+
+	// claims which are not refreshed are taken over
+	_, err = q.ExecRaw("UPDATE ledger_gap_claims SET claimed_at = claimed_at - interval '1 hour'")
+	tt.Assert.NoError(err)
+	tt.Assert.NoError(other.RefreshLedgerGapClaim(gaps[1]))
+	gap, ok, err = q.ClaimLedgerGap(gaps, time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.True(ok)
+	tt.Assert.Equal(gaps[0], gap)
+	_, ok, err = q.ClaimLedgerGap(gaps, time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.False(ok)
+}
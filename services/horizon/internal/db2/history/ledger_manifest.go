@@ -0,0 +1,367 @@
+package history
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// LedgerManifest holds the checksums of the trades and payments ingested
+// for a ledger. Each checksum is the hex encoded SHA-256 hash of the
+// canonical JSON encoding of the rows, one per line, in the order of their
+// ids. Mirrors holding the same rows compute the same checksums.
+type LedgerManifest struct {
+	LedgerSequence uint32 `db:"ledger_sequence"`
+	TradesHash     string `db:"trades_hash"`
+	PaymentsHash   string `db:"payments_hash"`
+}
+
+// QLedgerManifests defines ledger manifest related queries.
+type QLedgerManifests interface {
+	InsertLedgerManifest(manifest LedgerManifest) error
+}
+
+// TradeManifestEntry is the canonical representation of a trade. It's
+// described from the side of the seller, the owner of the claimed offer, so
+// that it doesn't depend on the ids of the assets. Ingestion creates the
+// entries of a ledger with NewTradeManifestEntry and backfills create them
+// from the rows of the ledger, both give the same entries.
+type TradeManifestEntry struct {
+	ID              string    `json:"id"`
+	LedgerCloseTime int64     `json:"ledger_close_time"`
+	OfferID         int64     `json:"offer_id"`
+	Seller          string    `json:"seller"`
+	SoldAsset       string    `json:"sold_asset"`
+	AmountSold      xdr.Int64 `json:"amount_sold"`
+	BuyOfferID      *int64    `json:"buy_offer_id"`
+	Buyer           string    `json:"buyer"`
+	BoughtAsset     string    `json:"bought_asset"`
+	AmountBought    xdr.Int64 `json:"amount_bought"`
+	PriceN          null.Int  `json:"price_n"`
+	PriceD          null.Int  `json:"price_d"`
+
+	historyOperationID int64
+	order              int32
+}
+
+// PaymentManifestEntry is the canonical representation of a payment
+// operation. The keys of the details are sorted.
+type PaymentManifestEntry struct {
+	ID              int64             `json:"id"`
+	TransactionHash string            `json:"transaction_hash"`
+	Type            xdr.OperationType `json:"type"`
+	SourceAccount   string            `json:"source_account"`
+	Successful      bool              `json:"successful"`
+	Details         interface{}       `json:"details"`
+}
+
+// NewTradeManifestEntry returns the manifest entry of the trade as inserted
+// by TradeBatchInsertBuilder.Add, buyer is the address of the buyer.
+func NewTradeManifestEntry(trade InsertTrade, buyer string) (TradeManifestEntry, error) {
+	var buyOfferID int64
+	if trade.BuyOfferExists {
+		buyOfferID = EncodeOfferId(uint64(trade.BuyOfferID), CoreOfferIDType)
+	} else {
+		buyOfferID = EncodeOfferId(uint64(trade.HistoryOperationID), TOIDType)
+	}
+
+	soldAsset, err := manifestAsset(trade.Trade.AssetSold)
+	if err != nil {
+		return TradeManifestEntry{}, err
+	}
+	boughtAsset, err := manifestAsset(trade.Trade.AssetBought)
+	if err != nil {
+		return TradeManifestEntry{}, err
+	}
+
+	return TradeManifestEntry{
+		ID:                 fmt.Sprintf("%d-%d", trade.HistoryOperationID, trade.Order),
+		LedgerCloseTime:    trade.LedgerCloseTime.Unix(),
+		OfferID:            int64(trade.Trade.OfferId),
+		Seller:             trade.Trade.SellerId.Address(),
+		SoldAsset:          soldAsset,
+		AmountSold:         trade.Trade.AmountSold,
+		BuyOfferID:         &buyOfferID,
+		Buyer:              buyer,
+		BoughtAsset:        boughtAsset,
+		AmountBought:       trade.Trade.AmountBought,
+		PriceN:             null.IntFrom(int64(trade.SellPrice.N)),
+		PriceD:             null.IntFrom(int64(trade.SellPrice.D)),
+		historyOperationID: trade.HistoryOperationID,
+		order:              trade.Order,
+	}, nil
+}
+
+// manifestEntry returns the manifest entry of the trade, it reverses the
+// conversion done by TradeBatchInsertBuilder.Add.
+func (r Trade) manifestEntry() TradeManifestEntry {
+	entry := TradeManifestEntry{
+		ID:                 r.PagingToken(),
+		LedgerCloseTime:    r.LedgerCloseTime.Unix(),
+		OfferID:            r.OfferID,
+		historyOperationID: r.HistoryOperationID,
+		order:              r.Order,
+	}
+	base := assetSymbol(r.BaseAssetType, r.BaseAssetCode, r.BaseAssetIssuer)
+	counter := assetSymbol(r.CounterAssetType, r.CounterAssetCode, r.CounterAssetIssuer)
+	if r.BaseIsSeller {
+		entry.Seller, entry.SoldAsset, entry.AmountSold = r.BaseAccount, base, r.BaseAmount
+		entry.Buyer, entry.BoughtAsset, entry.AmountBought = r.CounterAccount, counter, r.CounterAmount
+		entry.BuyOfferID = r.CounterOfferID
+		entry.PriceN, entry.PriceD = r.PriceN, r.PriceD
+	} else {
+		// the price was inverted when the trade was inserted
+		entry.Seller, entry.SoldAsset, entry.AmountSold = r.CounterAccount, counter, r.CounterAmount
+		entry.Buyer, entry.BoughtAsset, entry.AmountBought = r.BaseAccount, base, r.BaseAmount
+		entry.BuyOfferID = r.BaseOfferID
+		entry.PriceN, entry.PriceD = r.PriceD, r.PriceN
+	}
+	return entry
+}
+
+func manifestAsset(asset xdr.Asset) (string, error) {
+	var assetType, code, issuer string
+	if err := asset.Extract(&assetType, &code, &issuer); err != nil {
+		return "", errors.Wrap(err, "could not extract asset")
+	}
+	return assetSymbol(assetType, code, issuer), nil
+}
+
+// IsPaymentOperation returns true if the operations of the given type are
+// in the "payment" class of operations, which is in the manifests.
+func IsPaymentOperation(operationType xdr.OperationType) bool {
+	for _, paymentType := range paymentOperationTypes {
+		if operationType == paymentType {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPaymentManifestEntry returns the manifest entry of a payment operation,
+// details are the details of the operation encoded as JSON, as stored in
+// history_operations.
+func NewPaymentManifestEntry(
+	id int64,
+	transactionHash string,
+	operationType xdr.OperationType,
+	sourceAccount string,
+	successful bool,
+	details []byte,
+) (PaymentManifestEntry, error) {
+	entry := PaymentManifestEntry{
+		ID:              id,
+		TransactionHash: transactionHash,
+		Type:            operationType,
+		SourceAccount:   sourceAccount,
+		Successful:      successful,
+	}
+	// details are decoded so their keys are sorted when encoded again,
+	// numbers are kept as they are
+	if details != nil {
+		decoder := json.NewDecoder(bytes.NewReader(details))
+		decoder.UseNumber()
+		if err := decoder.Decode(&entry.Details); err != nil {
+			return entry, errors.Wrapf(err, "could not decode details of operation %d", id)
+		}
+	}
+	return entry, nil
+}
+
+// LedgerManifest loads the manifest of the ledger with the given sequence.
+func (q *Q) LedgerManifest(dest *LedgerManifest, sequence uint32) error {
+	return q.Get(dest, sq.Select("ledger_sequence", "trades_hash", "payments_hash").
+		From("history_ledger_manifests").
+		Where(sq.Eq{"ledger_sequence": sequence}))
+}
+
+// InsertLedgerManifest stores manifest, replacing the manifest of the ledger
+// if it exists.
+func (q *Q) InsertLedgerManifest(manifest LedgerManifest) error {
+	_, err := q.ExecRaw(`
+		INSERT INTO history_ledger_manifests (ledger_sequence, trades_hash, payments_hash)
+		VALUES (?, ?, ?)
+		ON CONFLICT (ledger_sequence) DO UPDATE SET
+			trades_hash = excluded.trades_hash,
+			payments_hash = excluded.payments_hash`,
+		manifest.LedgerSequence, manifest.TradesHash, manifest.PaymentsHash,
+	)
+	return errors.Wrap(err, "could not update ledger manifest")
+}
+
+// UpdateLedgerManifest computes the checksums of the trades and payments of
+// the ledger with the given sequence from its rows in the database and
+// stores them. Ingestion computes the manifests of the ledgers it ingests
+// from the ledgers, it's used to backfill the manifests of ledgers ingested
+// before.
+func (q *Q) UpdateLedgerManifest(sequence uint32) error {
+	start, end, err := toid.LedgerRangeInclusive(int32(sequence), int32(sequence))
+	if err != nil {
+		return errors.Wrap(err, "invalid ledger")
+	}
+
+	var trades []Trade
+	err = q.Select(&trades, selectTrades(selectTradeFields).
+		Where("htrd.history_operation_id >= ? AND htrd.history_operation_id < ?", start, end).
+		OrderBy("htrd.history_operation_id asc", "htrd.\"order\" asc"))
+	if err != nil {
+		return errors.Wrap(err, "could not load trades")
+	}
+
+	var payments []Operation
+	err = q.Select(&payments, selectOperation.
+		Where("hop.id >= ? AND hop.id < ?", start, end).
+		Where(sq.Eq{"hop.type": paymentOperationTypes}).
+		OrderBy("hop.id asc"))
+	if err != nil {
+		return errors.Wrap(err, "could not load payments")
+	}
+
+	tradeEntries := make([]TradeManifestEntry, 0, len(trades))
+	for _, trade := range trades {
+		tradeEntries = append(tradeEntries, trade.manifestEntry())
+	}
+
+	paymentEntries := make([]PaymentManifestEntry, 0, len(payments))
+	for _, payment := range payments {
+		var details []byte
+		if payment.DetailsString.Valid {
+			details = []byte(payment.DetailsString.String)
+		}
+		entry, err := NewPaymentManifestEntry(
+			payment.ID,
+			payment.TransactionHash,
+			payment.Type,
+			payment.SourceAccount,
+			payment.TransactionSuccessful,
+			details,
+		)
+		if err != nil {
+			return err
+		}
+		paymentEntries = append(paymentEntries, entry)
+	}
+
+	manifest, err := NewLedgerManifest(sequence, tradeEntries, paymentEntries)
+	if err != nil {
+		return err
+	}
+	return q.InsertLedgerManifest(manifest)
+}
+
+// ledgerManifestBackfillBatch is the number of ledgers whose manifests are
+// backfilled in a single transaction.
+const ledgerManifestBackfillBatch = 1000
+
+// BackfillLedgerManifests computes the manifests of the ledgers of the
+// [fromLedger, toLedger] range (closed interval) found in history_ledgers
+// from their rows in the database, see UpdateLedgerManifest. Every
+// ledgerManifestBackfillBatch ledgers are committed in their own
+// transaction. It returns the number of manifests stored.
+func (q *Q) BackfillLedgerManifests(fromLedger, toLedger uint32) (int, error) {
+	if fromLedger == 0 || fromLedger > toLedger {
+		return 0, errors.Errorf("invalid range: [%d, %d]", fromLedger, toLedger)
+	}
+
+	backfilled := 0
+	for batchStart := uint64(fromLedger); batchStart <= uint64(toLedger); batchStart += ledgerManifestBackfillBatch {
+		batchEnd := batchStart + ledgerManifestBackfillBatch - 1
+		if batchEnd > uint64(toLedger) {
+			batchEnd = uint64(toLedger)
+		}
+
+		count, err := q.backfillLedgerManifests(uint32(batchStart), uint32(batchEnd))
+		if err != nil {
+			return backfilled, err
+		}
+		backfilled += count
+	}
+	return backfilled, nil
+}
+
+func (q *Q) backfillLedgerManifests(fromLedger, toLedger uint32) (int, error) {
+	if err := q.Begin(); err != nil {
+		return 0, errors.Wrap(err, "could not start transaction")
+	}
+	defer q.Rollback()
+
+	var sequences []uint32
+	err := q.Select(&sequences, sq.Select("sequence").
+		From("history_ledgers").
+		Where("sequence >= ? AND sequence <= ?", fromLedger, toLedger).
+		OrderBy("sequence asc"))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not load ledgers")
+	}
+
+	for _, sequence := range sequences {
+		if err = q.UpdateLedgerManifest(sequence); err != nil {
+			return 0, errors.Wrapf(err, "could not update manifest of ledger %d", sequence)
+		}
+	}
+
+	if err = q.Commit(); err != nil {
+		return 0, errors.Wrap(err, "could not commit transaction")
+	}
+	return len(sequences), nil
+}
+
+// NewLedgerManifest returns the manifest of the ledger with the given
+// sequence from the entries of its trades and payments.
+func NewLedgerManifest(
+	sequence uint32,
+	trades []TradeManifestEntry,
+	payments []PaymentManifestEntry,
+) (LedgerManifest, error) {
+	manifest := LedgerManifest{LedgerSequence: sequence}
+
+	sort.SliceStable(trades, func(i, j int) bool {
+		if trades[i].historyOperationID != trades[j].historyOperationID {
+			return trades[i].historyOperationID < trades[j].historyOperationID
+		}
+		return trades[i].order < trades[j].order
+	})
+	sort.SliceStable(payments, func(i, j int) bool {
+		return payments[i].ID < payments[j].ID
+	})
+
+	tradeEntries := make([]interface{}, 0, len(trades))
+	for _, trade := range trades {
+		tradeEntries = append(tradeEntries, trade)
+	}
+	paymentEntries := make([]interface{}, 0, len(payments))
+	for _, payment := range payments {
+		paymentEntries = append(paymentEntries, payment)
+	}
+
+	var err error
+	if manifest.TradesHash, err = manifestHash(tradeEntries); err != nil {
+		return manifest, errors.Wrap(err, "could not hash trades")
+	}
+	if manifest.PaymentsHash, err = manifestHash(paymentEntries); err != nil {
+		return manifest, errors.Wrap(err, "could not hash payments")
+	}
+	return manifest, nil
+}
+
+// manifestHash returns the hex encoded SHA-256 hash of entries encoded as
+// JSON, one per line.
+func manifestHash(entries []interface{}) (string, error) {
+	hash := sha256.New()
+	encoder := json.NewEncoder(hash)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
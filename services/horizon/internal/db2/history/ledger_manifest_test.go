@@ -0,0 +1,173 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+// emptyManifestHash is the SHA-256 hash of no data.
+const emptyManifestHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func TestLedgerManifest(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	var manifest LedgerManifest
+	err := q.LedgerManifest(&manifest, 24)
+	tt.Assert.True(q.NoRows(err))
+
+	tt.Assert.NoError(q.UpdateLedgerManifest(24))
+	tt.Assert.NoError(q.LedgerManifest(&manifest, 24))
+	tt.Assert.Equal(uint32(24), manifest.LedgerSequence)
+	tt.Assert.Len(manifest.TradesHash, 64)
+	tt.Assert.NotEqual(emptyManifestHash, manifest.TradesHash)
+
+	// updating the manifest again gives the same checksums
+	var updated LedgerManifest
+	tt.Assert.NoError(q.UpdateLedgerManifest(24))
+	tt.Assert.NoError(q.LedgerManifest(&updated, 24))
+	tt.Assert.Equal(manifest, updated)
+
+	// ledgers without trades and payments have the checksums of no rows
+	tt.Assert.NoError(q.UpdateLedgerManifest(1000))
+	tt.Assert.NoError(q.LedgerManifest(&manifest, 1000))
+	tt.Assert.Equal(LedgerManifest{
+		LedgerSequence: 1000,
+		TradesHash:     emptyManifestHash,
+		PaymentsHash:   emptyManifestHash,
+	}, manifest)
+
+	start, end, err := toid.LedgerRangeInclusive(20, 30)
+	tt.Assert.NoError(err)
+	tt.Assert.NoError(q.DeleteRangeAll(start, end))
+	err = q.LedgerManifest(&manifest, 24)
+	tt.Assert.True(q.NoRows(err))
+	tt.Assert.NoError(q.LedgerManifest(&manifest, 1000))
+}
+
+func TestNewLedgerManifest(t *testing.T) {
+	details := []byte(`{"to": "GA", "from": "GB", "amount": "10.0000000"}`)
+	payment, err := NewPaymentManifestEntry(
+		1,
+		"2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d",
+		xdr.OperationTypePayment,
+		"GB",
+		true,
+		details,
+	)
+	assert.NoError(t, err)
+	trade := Trade{HistoryOperationID: 1, BaseAccount: "GA", BaseAssetType: "native", BaseAmount: 10}
+
+	manifest, err := NewLedgerManifest(1, []TradeManifestEntry{trade.manifestEntry()}, []PaymentManifestEntry{payment})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), manifest.LedgerSequence)
+
+	// the order of the keys of the details doesn't change the checksum
+	reordered, err := NewPaymentManifestEntry(
+		1,
+		"2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d",
+		xdr.OperationTypePayment,
+		"GB",
+		true,
+		[]byte(`{"amount":"10.0000000","from":"GB","to":"GA"}`),
+	)
+	assert.NoError(t, err)
+	other, err := NewLedgerManifest(1, []TradeManifestEntry{trade.manifestEntry()}, []PaymentManifestEntry{reordered})
+	assert.NoError(t, err)
+	assert.Equal(t, manifest, other)
+
+	// any change of the rows does
+	trade.BaseAmount = 11
+	other, err = NewLedgerManifest(1, []TradeManifestEntry{trade.manifestEntry()}, []PaymentManifestEntry{payment})
+	assert.NoError(t, err)
+	assert.NotEqual(t, manifest.TradesHash, other.TradesHash)
+	assert.Equal(t, manifest.PaymentsHash, other.PaymentsHash)
+
+	reordered.Details = map[string]interface{}{"amount": "10.0000001", "from": "GB", "to": "GA"}
+	other, err = NewLedgerManifest(1, nil, []PaymentManifestEntry{reordered})
+	assert.NoError(t, err)
+	assert.Equal(t, emptyManifestHash, other.TradesHash)
+	assert.NotEqual(t, manifest.PaymentsHash, other.PaymentsHash)
+
+	_, err = NewPaymentManifestEntry(1, "", xdr.OperationTypePayment, "GB", true, []byte(`{`))
+	assert.EqualError(t, err, "could not decode details of operation 1: unexpected EOF")
+}
+
+func TestTradeManifestEntry(t *testing.T) {
+	seller := xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB")
+	buyer := "GBB4JST32UWKOLGYYSCEYBHBCOFL2TGBHDVOMZP462ET4ZRD4ULA7S2L"
+	usd := xdr.MustNewCreditAsset("USD", buyer)
+	closeTime := time.Unix(1594900000, 0).UTC()
+
+	insert := InsertTrade{
+		HistoryOperationID: toid.New(10, 1, 1).ToInt64(),
+		Order:              1,
+		LedgerCloseTime:    closeTime,
+		Trade: xdr.ClaimOfferAtom{
+			SellerId:     seller,
+			OfferId:      5,
+			AssetSold:    xdr.MustNewNativeAsset(),
+			AmountSold:   100,
+			AssetBought:  usd,
+			AmountBought: 20,
+		},
+		SellPrice: xdr.Price{N: 1, D: 5},
+	}
+	entry, err := NewTradeManifestEntry(insert, buyer)
+	assert.NoError(t, err)
+	assert.Equal(t, EncodeOfferId(uint64(insert.HistoryOperationID), TOIDType), *entry.BuyOfferID)
+
+	sellOfferID := int64(5)
+	buyOfferID := *entry.BuyOfferID
+
+	// the rows inserted by TradeBatchInsertBuilder.Add with the native asset
+	// as base and as counter give the same entry
+	baseIsSeller := Trade{
+		HistoryOperationID: insert.HistoryOperationID,
+		Order:              1,
+		LedgerCloseTime:    closeTime,
+		OfferID:            5,
+		BaseOfferID:        &sellOfferID,
+		BaseAccount:        seller.Address(),
+		BaseAssetType:      "native",
+		BaseAmount:         100,
+		CounterOfferID:     &buyOfferID,
+		CounterAccount:     buyer,
+		CounterAssetType:   "credit_alphanum4",
+		CounterAssetCode:   "USD",
+		CounterAssetIssuer: buyer,
+		CounterAmount:      20,
+		BaseIsSeller:       true,
+		PriceN:             null.IntFrom(1),
+		PriceD:             null.IntFrom(5),
+	}
+	assert.Equal(t, entry, baseIsSeller.manifestEntry())
+
+	baseIsBuyer := Trade{
+		HistoryOperationID: insert.HistoryOperationID,
+		Order:              1,
+		LedgerCloseTime:    closeTime,
+		OfferID:            5,
+		BaseOfferID:        &buyOfferID,
+		BaseAccount:        buyer,
+		BaseAssetType:      "credit_alphanum4",
+		BaseAssetCode:      "USD",
+		BaseAssetIssuer:    buyer,
+		BaseAmount:         20,
+		CounterOfferID:     &sellOfferID,
+		CounterAccount:     seller.Address(),
+		CounterAssetType:   "native",
+		CounterAmount:      100,
+		BaseIsSeller:       false,
+		PriceN:             null.IntFrom(5),
+		PriceD:             null.IntFrom(1),
+	}
+	assert.Equal(t, entry, baseIsBuyer.manifestEntry())
+}
@@ -0,0 +1,95 @@
+package history
+
+import (
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/errors"
+)
+
+// LedgerStat contains activity counters of a single ledger.
+type LedgerStat struct {
+	Sequence                   int32     `db:"sequence"`
+	ClosedAt                   time.Time `db:"closed_at"`
+	SuccessfulTransactionCount int32     `db:"successful_transaction_count"`
+	FailedTransactionCount     int32     `db:"failed_transaction_count"`
+	OperationCount             int32     `db:"operation_count"`
+	TradeCount                 int64     `db:"trade_count"`
+	FeeCharged                 int64     `db:"fee_charged"`
+}
+
+// LedgerStatsSummary aggregates the counters of all the ledgers in a range.
+type LedgerStatsSummary struct {
+	LedgerCount                int64
+	SuccessfulTransactionCount int64
+	FailedTransactionCount     int64
+	OperationCount             int64
+	TradeCount                 int64
+	FeeCharged                 int64
+}
+
+// LedgerStats is the result of LedgerStats query.
+type LedgerStats struct {
+	Ledgers []LedgerStat
+	Summary LedgerStatsSummary
+}
+
+// LedgerStats returns per-ledger counts of transactions, operations, trades
+// and total fees charged for all ledgers in the [fromSeq, toSeq] range
+// (closed interval), ordered by sequence, together with a summary of the
+// whole range. Fees are summed over all transactions stored in history so
+// they include failed transactions only if they were ingested.
+func (q *Q) LedgerStats(fromSeq, toSeq int32) (LedgerStats, error) {
+	var stats LedgerStats
+	if fromSeq > toSeq {
+		return stats, errors.New("from sequence must not be greater than to sequence")
+	}
+
+	start := toid.ID{LedgerSequence: fromSeq}.ToInt64()
+	end := toid.ID{LedgerSequence: toSeq + 1}.ToInt64()
+	err := q.SelectRaw(&stats.Ledgers, selectLedgerStats, fromSeq, toSeq, start, end)
+	if err != nil {
+		return stats, errors.Wrap(err, "could not select ledger stats")
+	}
+
+	for _, ledger := range stats.Ledgers {
+		stats.Summary.LedgerCount++
+		stats.Summary.SuccessfulTransactionCount += int64(ledger.SuccessfulTransactionCount)
+		stats.Summary.FailedTransactionCount += int64(ledger.FailedTransactionCount)
+		stats.Summary.OperationCount += int64(ledger.OperationCount)
+		stats.Summary.TradeCount += ledger.TradeCount
+		stats.Summary.FeeCharged += ledger.FeeCharged
+	}
+
+	return stats, nil
+}
+
+// selectLedgerStats aggregates transactions and trades in subqueries so
+// each of them is scanned once for the whole range instead of once per
+// ledger. Trades are grouped by the ledger sequence encoded in the upper 32
+// bits of history_operation_id (see toid).
+const selectLedgerStats = `
+	SELECT
+		hl.sequence,
+		hl.closed_at,
+		COALESCE(hl.successful_transaction_count, hl.transaction_count) as successful_transaction_count,
+		COALESCE(hl.failed_transaction_count, 0) as failed_transaction_count,
+		hl.operation_count,
+		COALESCE(trades.trade_count, 0) as trade_count,
+		COALESCE(txs.fee_charged, 0) as fee_charged
+	FROM history_ledgers hl
+	LEFT JOIN (
+		SELECT ledger_sequence, SUM(fee_charged) as fee_charged
+		FROM history_transactions
+		WHERE ledger_sequence >= $1 AND ledger_sequence <= $2
+		GROUP BY ledger_sequence
+	) txs ON txs.ledger_sequence = hl.sequence
+	LEFT JOIN (
+		SELECT (history_operation_id >> 32)::integer as ledger_sequence, COUNT(*) as trade_count
+		FROM history_trades
+		WHERE history_operation_id >= $3 AND history_operation_id < $4
+		GROUP BY 1
+	) trades ON trades.ledger_sequence = hl.sequence
+	WHERE hl.sequence >= $1 AND hl.sequence <= $2
+	ORDER BY hl.sequence ASC
+`
@@ -0,0 +1,50 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestLedgerStats(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	_, err := q.LedgerStats(10, 1)
+	tt.Assert.EqualError(err, "from sequence must not be greater than to sequence")
+
+	var latest int32
+	tt.Require.NoError(q.GetRaw(&latest, "SELECT MAX(sequence) FROM history_ledgers"))
+
+	stats, err := q.LedgerStats(1, latest)
+	tt.Require.NoError(err)
+	tt.Assert.Len(stats.Ledgers, int(latest))
+	tt.Assert.Equal(int64(latest), stats.Summary.LedgerCount)
+
+	for i, ledger := range stats.Ledgers {
+		tt.Assert.Equal(int32(i+1), ledger.Sequence)
+	}
+
+	var expected struct {
+		Operations int64 `db:"operations"`
+		Trades     int64 `db:"trades"`
+		Fees       int64 `db:"fees"`
+	}
+	tt.Require.NoError(q.GetRaw(&expected, `
+		SELECT
+			(SELECT COALESCE(SUM(operation_count), 0) FROM history_ledgers) as operations,
+			(SELECT COUNT(*) FROM history_trades) as trades,
+			(SELECT COALESCE(SUM(fee_charged), 0) FROM history_transactions) as fees
+	`))
+	tt.Assert.Equal(expected.Operations, stats.Summary.OperationCount)
+	tt.Assert.Equal(expected.Trades, stats.Summary.TradeCount)
+	tt.Assert.Equal(expected.Fees, stats.Summary.FeeCharged)
+	tt.Assert.NotZero(stats.Summary.TradeCount)
+
+	single, err := q.LedgerStats(latest, latest)
+	tt.Require.NoError(err)
+	if tt.Assert.Len(single.Ledgers, 1) {
+		tt.Assert.Equal(stats.Ledgers[latest-1], single.Ledgers[0])
+	}
+}
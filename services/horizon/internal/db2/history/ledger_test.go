@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/guregu/null"
+	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/services/horizon/internal/test"
 	"github.com/stellar/go/services/horizon/internal/toid"
+	supportTime "github.com/stellar/go/support/time"
 	"github.com/stellar/go/xdr"
 )
 
@@ -27,12 +29,32 @@ func TestLedgerQueries(t *testing.T) {
 
 	// Test Ledgers()
 	ls := []Ledger{}
-	err = q.Ledgers().Select(&ls)
+	err = q.Ledgers().Select(tt.Ctx, &ls)
 
 	if tt.Assert.NoError(err) {
 		tt.Assert.Len(ls, 3)
 	}
 
+	// GetLedgers filtered by close time, the end of the range is exclusive
+	err = q.LedgerBySequence(&l, 3)
+	tt.Assert.NoError(err)
+	closedAt := supportTime.MillisFromSeconds(l.ClosedAt.Unix())
+	page := db2.PageQuery{Order: "asc", Limit: 10}
+
+	ls, err = q.GetLedgers(tt.Ctx, LedgersFilter{StartTime: closedAt}, page)
+	if tt.Assert.NoError(err) && tt.Assert.NotEmpty(ls) {
+		for _, ledger := range ls {
+			tt.Assert.False(ledger.ClosedAt.Before(l.ClosedAt))
+		}
+	}
+
+	ls, err = q.GetLedgers(tt.Ctx, LedgersFilter{EndTime: closedAt}, page)
+	if tt.Assert.NoError(err) {
+		for _, ledger := range ls {
+			tt.Assert.True(ledger.ClosedAt.Before(l.ClosedAt))
+		}
+	}
+
 	// LedgersBySequence
 	err = q.LedgersBySequence(&ls, 1, 2, 3)
 
@@ -48,6 +70,17 @@ func TestLedgerQueries(t *testing.T) {
 		tt.Assert.Contains(foundSeqs, int32(2))
 		tt.Assert.Contains(foundSeqs, int32(3))
 	}
+
+	// GetLedgerHash
+	err = q.LedgerBySequence(&l, 3)
+	tt.Assert.NoError(err)
+	hash, err := q.GetLedgerHash(3)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(l.LedgerHash, hash)
+
+	hash, err = q.GetLedgerHash(100000)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("", hash)
 }
 
 func TestInsertLedger(t *testing.T) {
@@ -136,3 +169,55 @@ func TestInsertLedger(t *testing.T) {
 
 	tt.Assert.Equal(expectedLedger, ledgerFromDB)
 }
+
+func TestGetLedgerGaps(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	gaps, err := q.GetLedgerGaps()
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(gaps)
+
+	for _, sequence := range []uint32{3, 4, 7, 8, 9, 12} {
+		ledger := xdr.LedgerHeaderHistoryEntry{
+			Hash:   xdr.Hash{byte(sequence)},
+			Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(sequence)},
+		}
+		_, err = q.InsertLedger(ledger, 0, 0, 0, 0, 1)
+		tt.Assert.NoError(err)
+	}
+
+	// the ledgers before the oldest one are not gaps, they were reaped
+	gaps, err = q.GetLedgerGaps()
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]LedgerGap{
+		{StartSequence: 5, EndSequence: 6},
+		{StartSequence: 10, EndSequence: 11},
+	}, gaps)
+}
+
+func TestCountLedgersInRange(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	for _, sequence := range []uint32{3, 4, 7} {
+		ledger := xdr.LedgerHeaderHistoryEntry{
+			Hash:   xdr.Hash{byte(sequence)},
+			Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(sequence)},
+		}
+		_, err := q.InsertLedger(ledger, 0, 0, 0, 0, 1)
+		tt.Assert.NoError(err)
+	}
+
+	count, err := q.CountLedgersInRange(4, 7)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(2, count)
+
+	count, err = q.CountLedgersInRange(5, 6)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(0, count)
+}
@@ -3,8 +3,11 @@
 package history
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"math/big"
+	"strconv"
 	"sync"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	"github.com/jmoiron/sqlx"
 
 	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/toid"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
@@ -146,11 +150,16 @@ type AccountsBatchInsertBuilder interface {
 }
 
 type IngestionQ interface {
+	QAccountEvents
+	QAccountThresholds
 	QAccounts
 	QAssetStats
 	QData
+	QDataQualityViolations
 	QEffects
 	QLedgers
+	QLedgerManifests
+	QHistoryOffers
 	QOffers
 	QOperations
 	// QParticipants
@@ -160,7 +169,7 @@ type IngestionQ interface {
 	NewOperationParticipantBatchInsertBuilder(maxBatchSize int) OperationParticipantBatchInsertBuilder
 	QSigners
 	//QTrades
-	NewTradeBatchInsertBuilder(maxBatchSize int) TradeBatchInsertBuilder
+	NewTradeBatchInsertBuilder(maxBatchSize int, onConflict TradeConflictMode) TradeBatchInsertBuilder
 	CreateAssets(assets []xdr.Asset, batchSize int) (map[string]Asset, error)
 	QTransactions
 	QTrustLines
@@ -178,7 +187,21 @@ type IngestionQ interface {
 	GetLatestLedger() (uint32, error)
 	GetOfferCompactionSequence() (uint32, error)
 	TruncateExpingestStateTables() error
+	TruncateHistoryTables() error
+	GetReingestCheckpoints(rangeFrom, rangeTo uint32) ([]ReingestCheckpoint, error)
+	UpdateReingestCheckpoint(rangeFrom, rangeTo, batchFrom, lastLedger uint32) error
+	DeleteReingestCheckpoints(rangeFrom, rangeTo uint32) error
+	GetLedgerHash(sequence uint32) (string, error)
+	CountLedgersInRange(fromSeq, toSeq uint32) (int, error)
+	GetNetworkPassphrase() (string, error)
+	UpdateNetworkPassphrase(passphrase string) error
 	DeleteRangeAll(start, end int64) error
+	AnalyzeHistoryTables(vacuum bool) error
+	CreateHistoryPartitions(ledger uint32) error
+	EvaluateAssetWatches(ledger uint32) ([]AssetWatchEvent, error)
+	ReplaceQuarantinedEntries(sequence uint32, entries []QuarantinedEntry) error
+	CountQuarantinedEntries() (int, error)
+	RetryableTransaction(ctx context.Context, fn func() error) error
 }
 
 // QAccounts defines account related queries.
@@ -251,6 +274,10 @@ type ExpAssetStat struct {
 	AssetIssuer string        `db:"asset_issuer"`
 	Amount      string        `db:"amount"`
 	NumAccounts int32         `db:"num_accounts"`
+	// Flags and HomeDomain are the flags and the home domain of the issuer,
+	// maintained by UpdateAssetStatsIssuers.
+	Flags      uint32 `db:"flags"`
+	HomeDomain string `db:"home_domain"`
 }
 
 // PagingToken returns a cursor for this asset stat
@@ -263,6 +290,49 @@ func (e ExpAssetStat) PagingToken() string {
 	)
 }
 
+// PagingTokenFor returns a cursor for this asset stat in pages sorted by the
+// given order.
+func (e ExpAssetStat) PagingTokenFor(sortBy AssetStatsSort) string {
+	switch sortBy {
+	case AssetStatsSortByHolders:
+		return fmt.Sprintf("%d_%s", e.NumAccounts, e.PagingToken())
+	case AssetStatsSortByAmount:
+		return fmt.Sprintf("%s_%s", e.Amount, e.PagingToken())
+	default:
+		return e.PagingToken()
+	}
+}
+
+// AssetStatsSort is the order of asset stats pages.
+type AssetStatsSort string
+
+const (
+	// AssetStatsSortByAsset sorts asset stats by asset code and issuer.
+	AssetStatsSortByAsset AssetStatsSort = ""
+	// AssetStatsSortByHolders sorts asset stats by the number of accounts
+	// holding a trust line to the asset.
+	AssetStatsSortByHolders AssetStatsSort = "holders"
+	// AssetStatsSortByAmount sorts asset stats by the total amount of the
+	// asset held by accounts.
+	AssetStatsSortByAmount AssetStatsSort = "amount"
+)
+
+// ValidCursorValue returns true if value is a valid sort value of the cursors
+// of pages sorted by s, as returned by ExpAssetStat.PagingTokenFor. Amounts
+// are decimal strings as they can exceed 64 bits.
+func (s AssetStatsSort) ValidCursorValue(value string) bool {
+	switch s {
+	case AssetStatsSortByHolders:
+		_, err := strconv.ParseUint(value, 10, 64)
+		return err == nil
+	case AssetStatsSortByAmount:
+		amount, ok := new(big.Int).SetString(value, 10)
+		return ok && amount.Sign() >= 0
+	default:
+		return false
+	}
+}
+
 // QAssetStats defines exp_asset_stats related queries.
 type QAssetStats interface {
 	InsertAssetStats(stats []ExpAssetStat, batchSize int) error
@@ -270,7 +340,8 @@ type QAssetStats interface {
 	UpdateAssetStat(stat ExpAssetStat) (int64, error)
 	GetAssetStat(assetType xdr.AssetType, assetCode, assetIssuer string) (ExpAssetStat, error)
 	RemoveAssetStat(assetType xdr.AssetType, assetCode, assetIssuer string) (int64, error)
-	GetAssetStats(assetCode, assetIssuer string, page db2.PageQuery) ([]ExpAssetStat, error)
+	UpdateAssetStatsIssuers(issuers []string) (int64, error)
+	GetAssetStats(ctx context.Context, filter AssetStatsFilter, page db2.PageQuery) ([]ExpAssetStat, error)
 	CountTrustLines() (int, error)
 }
 
@@ -314,7 +385,8 @@ type SequenceBumped struct {
 type EffectsQ struct {
 	Err    error
 	parent *Q
-	sql    sq.SelectBuilder
+	filter EffectsFilter
+	page   *db2.PageQuery
 }
 
 // EffectType is the numeric type for an effect, used as the `type` field in the
@@ -404,7 +476,8 @@ type LedgerCache struct {
 type LedgersQ struct {
 	Err    error
 	parent *Q
-	sql    sq.SelectBuilder
+	filter LedgersFilter
+	page   *db2.PageQuery
 }
 
 // Operation is a row of data from the `history_operations` table
@@ -456,12 +529,10 @@ type offersBatchInsertBuilder struct {
 // OperationsQ is a helper struct to aid in configuring queries that loads
 // slices of Operation structs.
 type OperationsQ struct {
-	Err                 error
-	parent              *Q
-	sql                 sq.SelectBuilder
-	opIdCol             string
-	includeFailed       bool
-	includeTransactions bool
+	Err    error
+	parent *Q
+	filter OperationsFilter
+	page   *db2.PageQuery
 }
 
 // Q is a helper struct on which to hang common_trades queries against a history
@@ -517,26 +588,23 @@ type Trade struct {
 	CounterAssetIssuer string    `db:"counter_asset_issuer"`
 	CounterAmount      xdr.Int64 `db:"counter_amount"`
 	BaseIsSeller       bool      `db:"base_is_seller"`
+	BaseIsMaker        bool      `db:"base_is_maker"`
 	PriceN             null.Int  `db:"price_n"`
 	PriceD             null.Int  `db:"price_d"`
+	Sequence           int64     `db:"sequence"`
 }
 
 // TradesQ is a helper struct to aid in configuring queries that loads
 // slices of trade structs.
 type TradesQ struct {
-	Err        error
-	parent     *Q
-	sql        sq.SelectBuilder
-	pageCalled bool
-
-	// For queries for account and offer we construct UNION query. The alternative
-	// is to use (base = X OR counter = X) query but it's costly.
-	forAccountID int64
-	forOfferID   int64
+	Err    error
+	parent *Q
+	filter TradesFilter
+	page   *db2.PageQuery
 
-	// rawSQL will be executed if present (instead of sql - sq.SelectBuilder).
-	rawSQL  string
-	rawArgs []interface{}
+	// reversed is true when base and counter (and price) are swapped in
+	// selected fields.
+	reversed bool
 }
 
 // Transaction is a row of data from the `history_transactions` table
@@ -548,10 +616,10 @@ type Transaction struct {
 // TransactionsQ is a helper struct to aid in configuring queries that loads
 // slices of transaction structs.
 type TransactionsQ struct {
-	Err           error
-	parent        *Q
-	sql           sq.SelectBuilder
-	includeFailed bool
+	Err    error
+	parent *Q
+	filter TransactionsFilter
+	page   *db2.PageQuery
 }
 
 // TrustLine is row of data from the `trust_lines` table from horizon DB
@@ -669,7 +737,23 @@ func (q *Q) CloneIngestionQ() IngestionQ {
 // DeleteRangeAll deletes a range of rows from all history tables between
 // `start` and `end` (exclusive).
 func (q *Q) DeleteRangeAll(start, end int64) error {
-	err := q.DeleteRange(start, end, "history_effects", "history_operation_id")
+	return q.deleteRangeAll(start, end, false)
+}
+
+// DeleteUnretainedRangeAll is the same as DeleteRangeAll except that trades
+// of asset pairs with a trade retention policy are kept. These are removed
+// by DeleteExpiredRetainedTrades instead.
+func (q *Q) DeleteUnretainedRangeAll(start, end int64) error {
+	return q.deleteRangeAll(start, end, true)
+}
+
+// deleteRangeAll deletes the range from all history tables. When reaping,
+// trades of asset pairs with a trade retention policy are kept and the
+// partitions covered by the range are dropped. Otherwise the range is about
+// to be ingested again so the partitions are only truncated, see
+// deleteHistoryRange.
+func (q *Q) deleteRangeAll(start, end int64, reaping bool) error {
+	err := q.deleteHistoryRange(start, end, "history_effects", "history_operation_id", reaping)
 	if err != nil {
 		return errors.Wrap(err, "Error clearing history_effects")
 	}
@@ -677,7 +761,7 @@ func (q *Q) DeleteRangeAll(start, end int64) error {
 	if err != nil {
 		return errors.Wrap(err, "Error clearing history_operation_participants")
 	}
-	err = q.DeleteRange(start, end, "history_operations", "id")
+	err = q.deleteHistoryRange(start, end, "history_operations", "id", reaping)
 	if err != nil {
 		return errors.Wrap(err, "Error clearing history_operations")
 	}
@@ -689,14 +773,100 @@ func (q *Q) DeleteRangeAll(start, end int64) error {
 	if err != nil {
 		return errors.Wrap(err, "Error clearing history_transactions")
 	}
+	err = q.DeleteRange(start, end, "history_account_events", "history_operation_id")
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_account_events")
+	}
+	err = q.DeleteRange(start, end, "history_account_thresholds", "history_operation_id")
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_account_thresholds")
+	}
+	err = q.DeleteRange(start, end, "history_data_quality_violations", "history_transaction_id")
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_data_quality_violations")
+	}
 	err = q.DeleteRange(start, end, "history_ledgers", "id")
 	if err != nil {
 		return errors.Wrap(err, "Error clearing history_ledgers")
 	}
-	err = q.DeleteRange(start, end, "history_trades", "history_operation_id")
+	// partitions of history_trades can only be dropped if they don't hold
+	// retained trades
+	var retentionPolicies int
+	if reaping {
+		err = q.GetRaw(&retentionPolicies, "SELECT COUNT(*) FROM trade_retention_policies")
+		if err != nil {
+			return errors.Wrap(err, "Error loading trade retention policies")
+		}
+	}
+	if retentionPolicies > 0 {
+		_, err = q.Exec(sq.Delete("history_trades htrd").
+			Where("history_operation_id >= ? AND history_operation_id < ?", start, end).
+			Where(`NOT EXISTS (
+				SELECT 1 FROM trade_retention_policies p
+				WHERE p.base_asset_id = htrd.base_asset_id
+				AND p.counter_asset_id = htrd.counter_asset_id
+			)`))
+	} else {
+		err = q.deleteHistoryRange(start, end, "history_trades", "history_operation_id", reaping)
+	}
 	if err != nil {
 		return errors.Wrap(err, "Error clearing history_trades")
 	}
+	// history_offers rows are not identified by a toid so the range is
+	// converted to ledger sequences.
+	_, err = q.Exec(sq.Delete("history_offers").Where(
+		"ledger_sequence >= ? AND ledger_sequence < ?",
+		toid.Parse(start).LedgerSequence,
+		toid.Parse(end).LedgerSequence,
+	))
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_offers")
+	}
+	_, err = q.Exec(sq.Delete("history_ledger_manifests").Where(
+		"ledger_sequence >= ? AND ledger_sequence < ?",
+		toid.Parse(start).LedgerSequence,
+		toid.Parse(end).LedgerSequence,
+	))
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_ledger_manifests")
+	}
 
 	return nil
 }
+
+// ingestedHistoryTables are the history tables written to when ingesting
+// ledgers.
+var ingestedHistoryTables = []string{
+	"history_account_events",
+	"history_account_thresholds",
+	"history_accounts",
+	"history_assets",
+	"history_data_quality_violations",
+	"history_effects",
+	"history_ledger_manifests",
+	"history_ledgers",
+	"history_offers",
+	"history_operation_participants",
+	"history_operations",
+	"history_trades",
+	"history_transaction_participants",
+	"history_transactions",
+}
+
+// AnalyzeHistoryTables refreshes planner statistics of all history tables
+// written to during ingestion. When vacuum is true `VACUUM ANALYZE` is run
+// instead. Because VACUUM cannot run inside a transaction block it must be
+// called outside of a transaction.
+func (q *Q) AnalyzeHistoryTables(vacuum bool) error {
+	command := "ANALYZE"
+	if vacuum {
+		command = "VACUUM ANALYZE"
+	}
+
+	for _, table := range ingestedHistoryTables {
+		if _, err := q.ExecRaw(command + " " + table); err != nil {
+			return errors.Wrapf(err, "Error running %s on %s", command, table)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package history
+
+import (
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQAccountEvents is a mock implementation of the QAccountEvents interface
+type MockQAccountEvents struct {
+	mock.Mock
+}
+
+func (m *MockQAccountEvents) NewAccountEventsBatchInsertBuilder(maxBatchSize int) AccountEventsBatchInsertBuilder {
+	a := m.Called(maxBatchSize)
+	return a.Get(0).(AccountEventsBatchInsertBuilder)
+}
+
+type MockAccountEventsBatchInsertBuilder struct {
+	mock.Mock
+}
+
+func (m *MockAccountEventsBatchInsertBuilder) Add(
+	historyOperationID int64,
+	eventType AccountEventType,
+	account string,
+	relatedAccount string,
+	amount xdr.Int64,
+) error {
+	a := m.Called(historyOperationID, eventType, account, relatedAccount, amount)
+	return a.Error(0)
+}
+
+func (m *MockAccountEventsBatchInsertBuilder) Exec() error {
+	a := m.Called()
+	return a.Error(0)
+}
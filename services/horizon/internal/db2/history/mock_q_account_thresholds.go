@@ -0,0 +1,34 @@
+package history
+
+import (
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQAccountThresholds is a mock implementation of the QAccountThresholds interface
+type MockQAccountThresholds struct {
+	mock.Mock
+}
+
+func (m *MockQAccountThresholds) NewAccountThresholdsBatchInsertBuilder(maxBatchSize int) AccountThresholdsBatchInsertBuilder {
+	a := m.Called(maxBatchSize)
+	return a.Get(0).(AccountThresholdsBatchInsertBuilder)
+}
+
+type MockAccountThresholdsBatchInsertBuilder struct {
+	mock.Mock
+}
+
+func (m *MockAccountThresholdsBatchInsertBuilder) Add(
+	historyOperationID int64,
+	account string,
+	thresholds xdr.Thresholds,
+) error {
+	a := m.Called(historyOperationID, account, thresholds)
+	return a.Error(0)
+}
+
+func (m *MockAccountThresholdsBatchInsertBuilder) Exec() error {
+	a := m.Called()
+	return a.Error(0)
+}
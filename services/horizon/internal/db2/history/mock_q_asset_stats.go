@@ -1,6 +1,8 @@
 package history
 
 import (
+	"context"
+
 	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/xdr"
 	"github.com/stretchr/testify/mock"
@@ -36,8 +38,13 @@ func (m *MockQAssetStats) RemoveAssetStat(assetType xdr.AssetType, assetCode, as
 	return a.Get(0).(int64), a.Error(1)
 }
 
-func (m *MockQAssetStats) GetAssetStats(assetCode, assetIssuer string, page db2.PageQuery) ([]ExpAssetStat, error) {
-	a := m.Called(assetCode, assetIssuer, page)
+func (m *MockQAssetStats) UpdateAssetStatsIssuers(issuers []string) (int64, error) {
+	a := m.Called(issuers)
+	return a.Get(0).(int64), a.Error(1)
+}
+
+func (m *MockQAssetStats) GetAssetStats(ctx context.Context, filter AssetStatsFilter, page db2.PageQuery) ([]ExpAssetStat, error) {
+	a := m.Called(ctx, filter, page)
 	return a.Get(0).([]ExpAssetStat), a.Error(1)
 }
 
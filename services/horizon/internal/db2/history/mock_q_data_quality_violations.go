@@ -0,0 +1,29 @@
+package history
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQDataQualityViolations is a mock implementation of the QDataQualityViolations interface
+type MockQDataQualityViolations struct {
+	mock.Mock
+}
+
+func (m *MockQDataQualityViolations) NewDataQualityViolationsBatchInsertBuilder(maxBatchSize int) DataQualityViolationsBatchInsertBuilder {
+	a := m.Called(maxBatchSize)
+	return a.Get(0).(DataQualityViolationsBatchInsertBuilder)
+}
+
+type MockDataQualityViolationsBatchInsertBuilder struct {
+	mock.Mock
+}
+
+func (m *MockDataQualityViolationsBatchInsertBuilder) Add(violation DataQualityViolation) error {
+	a := m.Called(violation)
+	return a.Error(0)
+}
+
+func (m *MockDataQualityViolationsBatchInsertBuilder) Exec() error {
+	a := m.Called()
+	return a.Error(0)
+}
@@ -0,0 +1,30 @@
+package history
+
+import (
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQHistoryOffers is a mock implementation of the QHistoryOffers interface
+type MockQHistoryOffers struct {
+	mock.Mock
+}
+
+func (m *MockQHistoryOffers) NewHistoryOffersBatchInsertBuilder(maxBatchSize int) HistoryOffersBatchInsertBuilder {
+	a := m.Called(maxBatchSize)
+	return a.Get(0).(HistoryOffersBatchInsertBuilder)
+}
+
+type MockHistoryOffersBatchInsertBuilder struct {
+	mock.Mock
+}
+
+func (m *MockHistoryOffersBatchInsertBuilder) Add(offer xdr.OfferEntry, ledgerSequence uint32, deleted bool) error {
+	a := m.Called(offer, ledgerSequence, deleted)
+	return a.Error(0)
+}
+
+func (m *MockHistoryOffersBatchInsertBuilder) Exec() error {
+	a := m.Called()
+	return a.Error(0)
+}
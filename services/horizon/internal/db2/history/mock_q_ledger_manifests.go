@@ -0,0 +1,15 @@
+package history
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQLedgerManifests is a mock implementation of the QLedgerManifests interface
+type MockQLedgerManifests struct {
+	mock.Mock
+}
+
+func (m *MockQLedgerManifests) InsertLedgerManifest(manifest LedgerManifest) error {
+	a := m.Called(manifest)
+	return a.Error(0)
+}
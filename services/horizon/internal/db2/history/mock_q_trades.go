@@ -19,8 +19,8 @@ func (m *MockQTrades) CreateAssets(assets []xdr.Asset, maxBatchSize int) (map[st
 	return a.Get(0).(map[string]Asset), a.Error(1)
 }
 
-func (m *MockQTrades) NewTradeBatchInsertBuilder(maxBatchSize int) TradeBatchInsertBuilder {
-	a := m.Called(maxBatchSize)
+func (m *MockQTrades) NewTradeBatchInsertBuilder(maxBatchSize int, onConflict TradeConflictMode) TradeBatchInsertBuilder {
+	a := m.Called(maxBatchSize, onConflict)
 	return a.Get(0).(TradeBatchInsertBuilder)
 }
 
@@ -9,8 +9,8 @@ type MockTransactionsBatchInsertBuilder struct {
 	mock.Mock
 }
 
-func (m *MockTransactionsBatchInsertBuilder) Add(transaction io.LedgerTransaction, sequence uint32) error {
-	a := m.Called(transaction, sequence)
+func (m *MockTransactionsBatchInsertBuilder) Add(transaction io.LedgerTransaction, sequence uint32, counts TransactionCounts) error {
+	a := m.Called(transaction, sequence, counts)
 	return a.Error(0)
 }
 
@@ -1,12 +1,15 @@
 package history
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/go-errors/errors"
 	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/timing"
 	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
 
@@ -22,65 +25,248 @@ func (r *Operation) UnmarshalDetails(dest interface{}) error {
 		return nil
 	}
 
-	err := json.Unmarshal([]byte(r.DetailsString.String), &dest)
-	if err != nil {
-		err = errors.Wrap(err, 1)
-	}
-
-	return err
+	return errors.Wrap(json.Unmarshal([]byte(r.DetailsString.String), &dest), "unmarshal operation details failed")
 }
 
 // FeeStats returns operation fee stats for the last 5 ledgers.
 // Currently, we hard code the query to return the last 5 ledgers worth of transactions.
 // TODO: make the number of ledgers configurable.
 func (q *Q) FeeStats(currentSeq int32, dest *FeeStats) error {
-	return q.GetRaw(dest, `
-		SELECT
-			ceil(max(fee_charged/operation_count))::bigint AS "fee_charged_max",
-			ceil(min(fee_charged/operation_count))::bigint AS "fee_charged_min",
-			ceil(mode() within group (order by fee_charged/operation_count))::bigint AS "fee_charged_mode",
-			ceil(percentile_disc(0.10) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p10",
-			ceil(percentile_disc(0.20) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p20",
-			ceil(percentile_disc(0.30) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p30",
-			ceil(percentile_disc(0.40) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p40",
-			ceil(percentile_disc(0.50) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p50",
-			ceil(percentile_disc(0.60) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p60",
-			ceil(percentile_disc(0.70) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p70",
-			ceil(percentile_disc(0.80) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p80",
-			ceil(percentile_disc(0.90) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p90",
-			ceil(percentile_disc(0.95) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p95",
-			ceil(percentile_disc(0.99) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p99",
-			ceil(max(max_fee/operation_count))::bigint AS "max_fee_max",
-			ceil(min(max_fee/operation_count))::bigint AS "max_fee_min",
-			ceil(mode() within group (order by max_fee/operation_count))::bigint AS "max_fee_mode",
-			ceil(percentile_disc(0.10) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p10",
-			ceil(percentile_disc(0.20) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p20",
-			ceil(percentile_disc(0.30) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p30",
-			ceil(percentile_disc(0.40) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p40",
-			ceil(percentile_disc(0.50) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p50",
-			ceil(percentile_disc(0.60) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p60",
-			ceil(percentile_disc(0.70) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p70",
-			ceil(percentile_disc(0.80) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p80",
-			ceil(percentile_disc(0.90) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p90",
-			ceil(percentile_disc(0.95) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p95",
-			ceil(percentile_disc(0.99) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p99"
-		FROM history_transactions
-		WHERE ledger_sequence > $1 AND ledger_sequence <= $2
-	`, currentSeq-5, currentSeq)
+	return q.GetRaw(dest, selectFeeStats, currentSeq-4, currentSeq)
+}
+
+// FeeStatsForLedgerRange returns operation fee stats of all transactions in
+// the [fromSeq, toSeq] ledger range (closed interval). Fields of dest are
+// null when there are no transactions in the range.
+func (q *Q) FeeStatsForLedgerRange(fromSeq, toSeq int32, dest *FeeStats) error {
+	if fromSeq > toSeq {
+		return errors.New("from sequence must not be greater than to sequence")
+	}
+	return q.GetRaw(dest, selectFeeStats, fromSeq, toSeq)
+}
+
+// OperationsFilter defines the filters of an operations query. Zero values
+// leave the corresponding filter unset, all the filters which are set must
+// match.
+type OperationsFilter struct {
+	// AccountID only includes operations the given account participated in.
+	AccountID string
+	// LedgerSequence only includes operations of the given ledger.
+	LedgerSequence int32
+	// TransactionHash only includes operations of the transaction with the
+	// given hex-encoded hash.
+	TransactionHash string
+	// Types only includes operations of the given types.
+	Types []xdr.OperationType
+	// OnlyPayments only includes operations in the "payment" class of
+	// operations: CreateAccountOps, Payments, PathPayments and AccountMerges.
+	OnlyPayments bool
+	// SourceAsset only includes path payments (strict receive and strict
+	// send) which were funded by the given asset. The conditions match the
+	// index_history_operations_on_path_payment_source_asset partial index.
+	SourceAsset *xdr.Asset
+	// IncludeFailed includes operations of failed transactions.
+	IncludeFailed bool
+	// IncludeTransactions fetches the transactions of the operations in
+	// addition to operation records.
+	IncludeTransactions bool
+}
+
+// GetOperations returns a page of operations matching the filter and, when
+// IncludeTransactions is set, their transactions. Unlike the OperationsQ
+// builder, errors of the lookups required by the filters (for example the
+// account of AccountID) are returned immediately.
+func (q *Q) GetOperations(ctx context.Context, filter OperationsFilter, page db2.PageQuery) ([]Operation, []Transaction, error) {
+	return q.selectOperations(ctx, filter, &page)
+}
+
+func (q *Q) selectOperations(ctx context.Context, filter OperationsFilter, page *db2.PageQuery) ([]Operation, []Transaction, error) {
+	q = &Q{q.WithContext(ctx).Replica()}
+
+	sql, err := q.operationsSQL(filter, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var operations []Operation
+	if err := q.Named("operations").Select(&operations, sql); err != nil {
+		return nil, nil, errors.Wrap(err, "could not select operations")
+	}
+
+	set := map[int64]bool{}
+	transactionIDs := []int64{}
+
+	for _, o := range operations {
+		var resultXDR xdr.TransactionResult
+		err := timing.UnmarshalXDR(ctx, o.TxResult, &resultXDR)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !set[o.TransactionID] {
+			set[o.TransactionID] = true
+			transactionIDs = append(transactionIDs, o.TransactionID)
+		}
+
+		if !filter.IncludeFailed {
+			if !o.TransactionSuccessful {
+				return nil, nil, errors.Errorf("Corrupted data! `include_failed=false` but returned transaction is failed: %s", o.TransactionHash)
+			}
+
+			if !resultXDR.Successful() {
+				return nil, nil, errors.Errorf("Corrupted data! `include_failed=false` but returned transaction is failed: %s %s", o.TransactionHash, o.TxResult)
+			}
+		}
+
+		// Check if `successful` equals resultXDR
+		if o.TransactionSuccessful && !resultXDR.Successful() {
+			return nil, nil, errors.Errorf("Corrupted data! `successful=true` but returned transaction is not success: %s %s", o.TransactionHash, o.TxResult)
+		}
+
+		if !o.TransactionSuccessful && resultXDR.Successful() {
+			return nil, nil, errors.Errorf("Corrupted data! `successful=false` but returned transaction is success: %s %s", o.TransactionHash, o.TxResult)
+		}
+	}
+
+	var transactions []Transaction
+	if filter.IncludeTransactions && len(transactionIDs) > 0 {
+		transactionsByID, err := q.TransactionsByIDs(transactionIDs...)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, o := range operations {
+			transaction, ok := transactionsByID[o.TransactionID]
+			if !ok {
+				return nil, nil, errors.Errorf("transaction with id %v could not be found", o.TransactionID)
+			}
+			err = validateTransactionForOperation(transaction, o)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			transactions = append(transactions, transaction)
+		}
+	}
+
+	return operations, transactions, nil
+}
+
+func (q *Q) operationsSQL(filter OperationsFilter, page *db2.PageQuery) (sq.SelectBuilder, error) {
+	sql := selectOperation
+	opIDCol := "hop.id"
+
+	if filter.AccountID != "" {
+		var account Account
+		if err := q.AccountByAddress(&account, filter.AccountID); err != nil {
+			return sql, errors.Wrap(err, "could not load account")
+		}
+
+		sql = sql.Join(
+			"history_operation_participants hopp ON "+
+				"hopp.history_operation_id = hop.id",
+		).Where("hopp.history_account_id = ?", account.ID)
+
+		// in order to use history_operation_participants.hist_op_p_id index
+		opIDCol = "hopp.history_operation_id"
+	}
+
+	if filter.LedgerSequence != 0 {
+		var ledger Ledger
+		if err := q.LedgerBySequence(&ledger, filter.LedgerSequence); err != nil {
+			return sql, errors.Wrap(err, "could not load ledger")
+		}
+
+		start := toid.ID{LedgerSequence: filter.LedgerSequence}
+		end := toid.ID{LedgerSequence: filter.LedgerSequence + 1}
+		sql = sql.Where(
+			"hop.id >= ? AND hop.id < ?",
+			start.ToInt64(),
+			end.ToInt64(),
+		)
+	}
+
+	if filter.TransactionHash != "" {
+		var tx Transaction
+		if err := q.TransactionByHash(&tx, filter.TransactionHash); err != nil {
+			return sql, errors.Wrap(err, "could not load transaction")
+		}
+
+		start := toid.Parse(tx.ID)
+		end := start
+		end.TransactionOrder++
+		sql = sql.Where(
+			"hop.id >= ? AND hop.id < ?",
+			start.ToInt64(),
+			end.ToInt64(),
+		)
+	}
+
+	if len(filter.Types) > 0 {
+		sql = sql.Where(sq.Eq{"hop.type": filter.Types})
+	}
+
+	if filter.OnlyPayments {
+		sql = sql.Where(sq.Eq{"hop.type": paymentOperationTypes})
+	}
+
+	if filter.SourceAsset != nil {
+		var assetType, code, issuer string
+		if err := filter.SourceAsset.Extract(&assetType, &code, &issuer); err != nil {
+			return sql, errors.Wrap(err, "could not extract source asset")
+		}
+
+		sql = sql.Where(fmt.Sprintf(
+			"hop.type IN (%d, %d)",
+			xdr.OperationTypePathPaymentStrictReceive,
+			xdr.OperationTypePathPaymentStrictSend,
+		)).Where("hop.details->>'source_asset_type' = ?", assetType)
+
+		if filter.SourceAsset.Type != xdr.AssetTypeAssetTypeNative {
+			sql = sql.
+				Where("hop.details->>'source_asset_code' = ?", code).
+				Where("hop.details->>'source_asset_issuer' = ?", issuer)
+		}
+	}
+
+	if page != nil {
+		cursor, err := page.CursorInt64()
+		if err != nil {
+			return sql, err
+		}
+
+		sql, err = db2.NewKeysetPager(opIDCol).ApplyTo(sql, page.Order, cursor)
+		if err != nil {
+			return sql, err
+		}
+		sql = sql.Limit(page.Limit)
+	}
+
+	if !filter.IncludeFailed {
+		sql = sql.Where("(ht.successful = true OR ht.successful IS NULL)")
+	}
+
+	return sql, nil
+}
+
+// paymentOperationTypes are the operation types in the "payment" class of
+// operations.
+var paymentOperationTypes = []xdr.OperationType{
+	xdr.OperationTypeCreateAccount,
+	xdr.OperationTypePayment,
+	xdr.OperationTypePathPaymentStrictReceive,
+	xdr.OperationTypePathPaymentStrictSend,
+	xdr.OperationTypeAccountMerge,
 }
 
 // Operations provides a helper to filter the operations table with pre-defined
 // filters.  See `OperationsQ` for the available filters.
+//
+// Deprecated: OperationsQ is a wrapper of GetOperations kept during the
+// migration to the context aware API, use GetOperations instead.
 func (q *Q) Operations() *OperationsQ {
-	query := &OperationsQ{
-		parent:              q,
-		opIdCol:             "hop.id",
-		includeFailed:       false,
-		includeTransactions: false,
-		sql:                 selectOperation,
+	return &OperationsQ{
+		parent: q,
 	}
-
-	return query
 }
 
 // OperationByID returns an Operation and optionally a Transaction given an operation id
@@ -113,61 +299,36 @@ func (q *Q) OperationByID(includeTransactions bool, id int64) (Operation, *Trans
 
 // ForAccount filters the operations collection to a specific account
 func (q *OperationsQ) ForAccount(aid string) *OperationsQ {
-	var account Account
-	q.Err = q.parent.AccountByAddress(&account, aid)
-	if q.Err != nil {
-		return q
-	}
-
-	q.sql = q.sql.Join(
-		"history_operation_participants hopp ON "+
-			"hopp.history_operation_id = hop.id",
-	).Where("hopp.history_account_id = ?", account.ID)
-
-	// in order to use history_operation_participants.hist_op_p_id index
-	q.opIdCol = "hopp.history_operation_id"
-
+	q.filter.AccountID = aid
 	return q
 }
 
 // ForLedger filters the query to a only operations in a specific ledger,
 // specified by its sequence.
 func (q *OperationsQ) ForLedger(seq int32) *OperationsQ {
-	var ledger Ledger
-	q.Err = q.parent.LedgerBySequence(&ledger, seq)
-	if q.Err != nil {
-		return q
-	}
-
-	start := toid.ID{LedgerSequence: seq}
-	end := toid.ID{LedgerSequence: seq + 1}
-	q.sql = q.sql.Where(
-		"hop.id >= ? AND hop.id < ?",
-		start.ToInt64(),
-		end.ToInt64(),
-	)
-
+	q.filter.LedgerSequence = seq
 	return q
 }
 
 // ForTransaction filters the query to a only operations in a specific
 // transaction, specified by the transactions's hex-encoded hash.
 func (q *OperationsQ) ForTransaction(hash string) *OperationsQ {
-	var tx Transaction
-	q.Err = q.parent.TransactionByHash(&tx, hash)
-	if q.Err != nil {
-		return q
-	}
+	q.filter.TransactionHash = hash
+	return q
+}
 
-	start := toid.Parse(tx.ID)
-	end := start
-	end.TransactionOrder++
-	q.sql = q.sql.Where(
-		"hop.id >= ? AND hop.id < ?",
-		start.ToInt64(),
-		end.ToInt64(),
-	)
+// ForTypes filters the query being built to only include operations of the
+// provided types. Calling it without any type leaves the query unfiltered.
+func (q *OperationsQ) ForTypes(types ...xdr.OperationType) *OperationsQ {
+	q.filter.Types = append(q.filter.Types, types...)
+	return q
+}
 
+// ForSourceAsset filters the query being built to only include path
+// payments (strict receive and strict send) which were funded by the given
+// asset.
+func (q *OperationsQ) ForSourceAsset(asset xdr.Asset) *OperationsQ {
+	q.filter.SourceAsset = &asset
 	return q
 }
 
@@ -175,110 +336,38 @@ func (q *OperationsQ) ForTransaction(hash string) *OperationsQ {
 // are in the "payment" class of operations:  CreateAccountOps, Payments, and
 // PathPayments.
 func (q *OperationsQ) OnlyPayments() *OperationsQ {
-	q.sql = q.sql.Where(sq.Eq{"hop.type": []xdr.OperationType{
-		xdr.OperationTypeCreateAccount,
-		xdr.OperationTypePayment,
-		xdr.OperationTypePathPaymentStrictReceive,
-		xdr.OperationTypePathPaymentStrictSend,
-		xdr.OperationTypeAccountMerge,
-	}})
+	q.filter.OnlyPayments = true
 	return q
 }
 
 // IncludeFailed changes the query to include failed transactions.
 func (q *OperationsQ) IncludeFailed() *OperationsQ {
-	q.includeFailed = true
+	q.filter.IncludeFailed = true
 	return q
 }
 
 // IncludeTransactions changes the query to fetch transaction data in addition to operation records.
 func (q *OperationsQ) IncludeTransactions() *OperationsQ {
-	q.includeTransactions = true
+	q.filter.IncludeTransactions = true
 	return q
 }
 
 // Page specifies the paging constraints for the query being built by `q`.
 func (q *OperationsQ) Page(page db2.PageQuery) *OperationsQ {
-	if q.Err != nil {
-		return q
-	}
-
-	q.sql, q.Err = page.ApplyTo(q.sql, q.opIdCol)
+	q.page = &page
 	return q
 }
 
-// Fetch returns results specified by a filtered operations query
-func (q *OperationsQ) Fetch() ([]Operation, []Transaction, error) {
+// Fetch returns results specified by a filtered operations query. The
+// queries are cancelled when ctx is done.
+func (q *OperationsQ) Fetch(ctx context.Context) ([]Operation, []Transaction, error) {
 	if q.Err != nil {
 		return nil, nil, q.Err
 	}
 
-	if !q.includeFailed {
-		q.sql = q.sql.
-			Where("(ht.successful = true OR ht.successful IS NULL)")
-	}
-
-	var operations []Operation
-	var transactions []Transaction
-	q.Err = q.parent.Select(&operations, q.sql)
-	if q.Err != nil {
-		return nil, nil, q.Err
-	}
-	set := map[int64]bool{}
-	transactionIDs := []int64{}
-
-	for _, o := range operations {
-		var resultXDR xdr.TransactionResult
-		err := xdr.SafeUnmarshalBase64(o.TxResult, &resultXDR)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		if !set[o.TransactionID] {
-			set[o.TransactionID] = true
-			transactionIDs = append(transactionIDs, o.TransactionID)
-		}
-
-		if !q.includeFailed {
-			if !o.TransactionSuccessful {
-				return nil, nil, errors.Errorf("Corrupted data! `include_failed=false` but returned transaction is failed: %s", o.TransactionHash)
-			}
-
-			if !resultXDR.Successful() {
-				return nil, nil, errors.Errorf("Corrupted data! `include_failed=false` but returned transaction is failed: %s %s", o.TransactionHash, o.TxResult)
-			}
-		}
-
-		// Check if `successful` equals resultXDR
-		if o.TransactionSuccessful && !resultXDR.Successful() {
-			return nil, nil, errors.Errorf("Corrupted data! `successful=true` but returned transaction is not success: %s %s", o.TransactionHash, o.TxResult)
-		}
-
-		if !o.TransactionSuccessful && resultXDR.Successful() {
-			return nil, nil, errors.Errorf("Corrupted data! `successful=false` but returned transaction is success: %s %s", o.TransactionHash, o.TxResult)
-		}
-	}
-
-	if q.includeTransactions && len(transactionIDs) > 0 {
-		transactionsByID, err := q.parent.TransactionsByIDs(transactionIDs...)
-		if err != nil {
-			return nil, nil, err
-		}
-		for _, o := range operations {
-			transaction, ok := transactionsByID[o.TransactionID]
-			if !ok {
-				return nil, nil, errors.Errorf("transaction with id %v could not be found", o.TransactionID)
-			}
-			err = validateTransactionForOperation(transaction, o)
-			if err != nil {
-				return nil, nil, err
-			}
-
-			transactions = append(transactions, transaction)
-		}
-	}
-
-	return operations, transactions, nil
+	operations, transactions, err := q.parent.selectOperations(ctx, q.filter, q.page)
+	q.Err = err
+	return operations, transactions, err
 }
 
 func validateTransactionForOperation(transaction Transaction, operation Operation) error {
@@ -331,3 +420,37 @@ var selectOperation = sq.Select(
 		"COALESCE(ht.successful, true) as transaction_successful").
 	From("history_operations hop").
 	LeftJoin("history_transactions ht ON ht.id = hop.transaction_id")
+
+var selectFeeStats = `
+	SELECT
+		ceil(max(fee_charged/operation_count))::bigint AS "fee_charged_max",
+		ceil(min(fee_charged/operation_count))::bigint AS "fee_charged_min",
+		ceil(mode() within group (order by fee_charged/operation_count))::bigint AS "fee_charged_mode",
+		ceil(percentile_disc(0.10) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p10",
+		ceil(percentile_disc(0.20) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p20",
+		ceil(percentile_disc(0.30) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p30",
+		ceil(percentile_disc(0.40) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p40",
+		ceil(percentile_disc(0.50) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p50",
+		ceil(percentile_disc(0.60) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p60",
+		ceil(percentile_disc(0.70) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p70",
+		ceil(percentile_disc(0.80) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p80",
+		ceil(percentile_disc(0.90) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p90",
+		ceil(percentile_disc(0.95) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p95",
+		ceil(percentile_disc(0.99) WITHIN GROUP (ORDER BY fee_charged/operation_count))::bigint AS "fee_charged_p99",
+		ceil(max(max_fee/operation_count))::bigint AS "max_fee_max",
+		ceil(min(max_fee/operation_count))::bigint AS "max_fee_min",
+		ceil(mode() within group (order by max_fee/operation_count))::bigint AS "max_fee_mode",
+		ceil(percentile_disc(0.10) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p10",
+		ceil(percentile_disc(0.20) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p20",
+		ceil(percentile_disc(0.30) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p30",
+		ceil(percentile_disc(0.40) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p40",
+		ceil(percentile_disc(0.50) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p50",
+		ceil(percentile_disc(0.60) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p60",
+		ceil(percentile_disc(0.70) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p70",
+		ceil(percentile_disc(0.80) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p80",
+		ceil(percentile_disc(0.90) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p90",
+		ceil(percentile_disc(0.95) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p95",
+		ceil(percentile_disc(0.99) WITHIN GROUP (ORDER BY max_fee/operation_count))::bigint AS "max_fee_p99"
+	FROM history_transactions
+	WHERE ledger_sequence >= $1 AND ledger_sequence <= $2
+`
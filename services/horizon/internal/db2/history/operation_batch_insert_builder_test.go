@@ -34,7 +34,7 @@ func TestAddOperation(t *testing.T) {
 	)
 
 	sequence := int32(56)
-	tt.Assert.NoError(txBatch.Add(transaction, uint32(sequence)))
+	tt.Assert.NoError(txBatch.Add(transaction, uint32(sequence), TransactionCounts{}))
 	tt.Assert.NoError(txBatch.Exec())
 
 	details, err := json.Marshal(map[string]string{
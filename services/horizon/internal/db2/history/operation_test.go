@@ -6,6 +6,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/xdr"
 )
 
 func TestOperationQueries(t *testing.T) {
@@ -23,14 +24,14 @@ func TestOperationQueries(t *testing.T) {
 	// Test Operations()
 	ops, transactions, err := q.Operations().
 		ForAccount("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON").
-		Fetch()
+		Fetch(tt.Ctx)
 	if tt.Assert.NoError(err) {
 		tt.Assert.Len(ops, 2)
 	}
 	tt.Assert.Len(transactions, 0)
 
 	// ledger filter works
-	ops, transactions, err = q.Operations().ForLedger(2).Fetch()
+	ops, transactions, err = q.Operations().ForLedger(2).Fetch(tt.Ctx)
 	if tt.Assert.NoError(err) {
 		tt.Assert.Len(ops, 3)
 	}
@@ -38,7 +39,7 @@ func TestOperationQueries(t *testing.T) {
 
 	// tx filter works
 	hash := "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d"
-	ops, transactions, err = q.Operations().ForTransaction(hash).Fetch()
+	ops, transactions, err = q.Operations().ForTransaction(hash).Fetch(tt.Ctx)
 	if tt.Assert.NoError(err) {
 		tt.Assert.Len(ops, 1)
 	}
@@ -46,7 +47,7 @@ func TestOperationQueries(t *testing.T) {
 
 	// payment filter works
 	tt.Scenario("pathed_payment")
-	ops, transactions, err = q.Operations().OnlyPayments().Fetch()
+	ops, transactions, err = q.Operations().OnlyPayments().Fetch(tt.Ctx)
 	if tt.Assert.NoError(err) {
 		tt.Assert.Len(ops, 10)
 	}
@@ -54,11 +55,49 @@ func TestOperationQueries(t *testing.T) {
 
 	// payment filter includes account merges
 	tt.Scenario("account_merge")
-	ops, transactions, err = q.Operations().OnlyPayments().Fetch()
+	ops, transactions, err = q.Operations().OnlyPayments().Fetch(tt.Ctx)
 	if tt.Assert.NoError(err) {
 		tt.Assert.Len(ops, 3)
 	}
 	tt.Assert.Len(transactions, 0)
+
+	// type filter works
+	ops, transactions, err = q.Operations().ForTypes(xdr.OperationTypeAccountMerge).Fetch(tt.Ctx)
+	if tt.Assert.NoError(err) && tt.Assert.NotEmpty(ops) {
+		for _, op := range ops {
+			tt.Assert.Equal(xdr.OperationTypeAccountMerge, op.Type)
+		}
+	}
+	tt.Assert.Len(transactions, 0)
+
+	ops, _, err = q.Operations().ForTypes(
+		xdr.OperationTypeCreateAccount,
+		xdr.OperationTypeAccountMerge,
+	).Fetch(tt.Ctx)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 3)
+	}
+
+	// source asset filter only includes path payments funded by the asset
+	tt.Scenario("paths_strict_send")
+	usd := xdr.MustNewCreditAsset("USD", "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+	ops, _, err = q.Operations().OnlyPayments().ForSourceAsset(usd).Fetch(tt.Ctx)
+	if tt.Assert.NoError(err) && tt.Assert.Len(ops, 3) {
+		for _, op := range ops {
+			tt.Assert.Equal(xdr.OperationTypePathPaymentStrictSend, op.Type)
+		}
+	}
+
+	eur := xdr.MustNewCreditAsset("EUR", "GCQPYGH4K57XBDENKKX55KDTWOTK5WDWRQOH2LHEDX3EKVIQRLMESGBG")
+	ops, _, err = q.Operations().ForSourceAsset(eur).Fetch(tt.Ctx)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 0)
+	}
+
+	ops, _, err = q.Operations().ForSourceAsset(xdr.MustNewNativeAsset()).Fetch(tt.Ctx)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 0)
+	}
 }
 
 func TestOperationQueryBuilder(t *testing.T) {
@@ -66,23 +105,29 @@ func TestOperationQueryBuilder(t *testing.T) {
 	defer tt.Finish()
 	q := &Q{tt.HorizonSession()}
 
-	opsQ := q.Operations().ForAccount("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON").Page(db2.PageQuery{Cursor: "8589938689", Order: "asc", Limit: 10})
-	tt.Assert.NoError(opsQ.Err)
-	got, _, err := opsQ.sql.ToSql()
+	page := db2.PageQuery{Cursor: "8589938689", Order: "asc", Limit: 10}
+	sql, err := q.operationsSQL(OperationsFilter{
+		AccountID: "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON",
+	}, &page)
+	tt.Assert.NoError(err)
+	got, _, err := sql.ToSql()
 	tt.Assert.NoError(err)
 
 	// Operations for account queries will use hopp.history_operation_id in their predicates.
-	want := "SELECT hop.id, hop.transaction_id, hop.application_order, hop.type, hop.details, hop.source_account, ht.transaction_hash, ht.tx_result, COALESCE(ht.successful, true) as transaction_successful FROM history_operations hop LEFT JOIN history_transactions ht ON ht.id = hop.transaction_id JOIN history_operation_participants hopp ON hopp.history_operation_id = hop.id WHERE hopp.history_account_id = ? AND hopp.history_operation_id > ? ORDER BY hopp.history_operation_id asc LIMIT 10"
+	want := "SELECT hop.id, hop.transaction_id, hop.application_order, hop.type, hop.details, hop.source_account, ht.transaction_hash, ht.tx_result, COALESCE(ht.successful, true) as transaction_successful FROM history_operations hop LEFT JOIN history_transactions ht ON ht.id = hop.transaction_id JOIN history_operation_participants hopp ON hopp.history_operation_id = hop.id WHERE hopp.history_account_id = ? AND hopp.history_operation_id > ? AND (ht.successful = true OR ht.successful IS NULL) ORDER BY hopp.history_operation_id asc LIMIT 10"
 	tt.Assert.EqualValues(want, got)
 
-	opsQ = q.Operations().ForLedger(2).Page(db2.PageQuery{Cursor: "8589938689", Order: "asc", Limit: 10})
-	tt.Assert.NoError(opsQ.Err)
-	got, _, err = opsQ.sql.ToSql()
+	sql, err = q.operationsSQL(OperationsFilter{LedgerSequence: 2, IncludeFailed: true}, &page)
+	tt.Assert.NoError(err)
+	got, _, err = sql.ToSql()
 	tt.Assert.NoError(err)
 
 	// Other operation queries will use hop.id in their predicates.
 	want = "SELECT hop.id, hop.transaction_id, hop.application_order, hop.type, hop.details, hop.source_account, ht.transaction_hash, ht.tx_result, COALESCE(ht.successful, true) as transaction_successful FROM history_operations hop LEFT JOIN history_transactions ht ON ht.id = hop.transaction_id WHERE hop.id >= ? AND hop.id < ? AND hop.id > ? ORDER BY hop.id asc LIMIT 10"
 	tt.Assert.EqualValues(want, got)
+
+	_, err = q.operationsSQL(OperationsFilter{LedgerSequence: 100000}, &page)
+	tt.Assert.EqualError(err, "could not load ledger: sql: no rows in result set")
 }
 
 // TestOperationSuccessfulOnly tests if default query returns operations in
@@ -97,7 +142,7 @@ func TestOperationSuccessfulOnly(t *testing.T) {
 	query := q.Operations().
 		ForAccount("GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2")
 
-	operations, transactions, err := query.Fetch()
+	operations, transactions, err := query.Fetch(tt.Ctx)
 	tt.Assert.NoError(err)
 	tt.Assert.Len(transactions, 0)
 
@@ -107,10 +152,12 @@ func TestOperationSuccessfulOnly(t *testing.T) {
 		tt.Assert.True(operation.TransactionSuccessful)
 	}
 
-	sql, _, err := query.sql.ToSql()
+	sql, err := q.operationsSQL(query.filter, nil)
+	tt.Assert.NoError(err)
+	got, _, err := sql.ToSql()
 	tt.Assert.NoError(err)
 	// Note: brackets around `(ht.successful = true OR ht.successful IS NULL)` are critical!
-	tt.Assert.Contains(sql, "WHERE hopp.history_account_id = ? AND (ht.successful = true OR ht.successful IS NULL)")
+	tt.Assert.Contains(got, "WHERE hopp.history_account_id = ? AND (ht.successful = true OR ht.successful IS NULL)")
 }
 
 // TestOperationIncludeFailed tests `IncludeFailed` method.
@@ -123,7 +170,7 @@ func TestOperationIncludeFailed(t *testing.T) {
 		ForAccount("GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2").
 		IncludeFailed()
 
-	operations, transactions, err := query.Fetch()
+	operations, transactions, err := query.Fetch(tt.Ctx)
 	tt.Assert.NoError(err)
 	tt.Assert.Len(transactions, 0)
 
@@ -139,9 +186,11 @@ func TestOperationIncludeFailed(t *testing.T) {
 	tt.Assert.Equal(3, successful)
 	tt.Assert.Equal(1, failed)
 
-	sql, _, err := query.sql.ToSql()
+	sql, err := q.operationsSQL(query.filter, nil)
 	tt.Assert.NoError(err)
-	tt.Assert.Equal("SELECT hop.id, hop.transaction_id, hop.application_order, hop.type, hop.details, hop.source_account, ht.transaction_hash, ht.tx_result, COALESCE(ht.successful, true) as transaction_successful FROM history_operations hop LEFT JOIN history_transactions ht ON ht.id = hop.transaction_id JOIN history_operation_participants hopp ON hopp.history_operation_id = hop.id WHERE hopp.history_account_id = ?", sql)
+	got, _, err := sql.ToSql()
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("SELECT hop.id, hop.transaction_id, hop.application_order, hop.type, hop.details, hop.source_account, ht.transaction_hash, ht.tx_result, COALESCE(ht.successful, true) as transaction_successful FROM history_operations hop LEFT JOIN history_transactions ht ON ht.id = hop.transaction_id JOIN history_operation_participants hopp ON hopp.history_operation_id = hop.id WHERE hopp.history_account_id = ?", got)
 }
 
 // TestPaymentsSuccessfulOnly tests if default query returns payments in
@@ -157,7 +206,7 @@ func TestPaymentsSuccessfulOnly(t *testing.T) {
 		OnlyPayments().
 		ForAccount("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON")
 
-	operations, transactions, err := query.Fetch()
+	operations, transactions, err := query.Fetch(tt.Ctx)
 	tt.Assert.NoError(err)
 	tt.Assert.Len(transactions, 0)
 
@@ -167,10 +216,12 @@ func TestPaymentsSuccessfulOnly(t *testing.T) {
 		tt.Assert.True(operation.TransactionSuccessful)
 	}
 
-	sql, _, err := query.sql.ToSql()
+	sql, err := q.operationsSQL(query.filter, nil)
+	tt.Assert.NoError(err)
+	got, _, err := sql.ToSql()
 	tt.Assert.NoError(err)
 	// Note: brackets around `(ht.successful = true OR ht.successful IS NULL)` are critical!
-	tt.Assert.Contains(sql, "WHERE hop.type IN (?,?,?,?,?) AND hopp.history_account_id = ? AND (ht.successful = true OR ht.successful IS NULL)")
+	tt.Assert.Contains(got, "WHERE hopp.history_account_id = ? AND hop.type IN (?,?,?,?,?) AND (ht.successful = true OR ht.successful IS NULL)")
 }
 
 // TestPaymentsIncludeFailed tests `IncludeFailed` method.
@@ -184,7 +235,7 @@ func TestPaymentsIncludeFailed(t *testing.T) {
 		ForAccount("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON").
 		IncludeFailed()
 
-	operations, transactions, err := query.Fetch()
+	operations, transactions, err := query.Fetch(tt.Ctx)
 	tt.Assert.NoError(err)
 	tt.Assert.Len(transactions, 0)
 
@@ -200,9 +251,11 @@ func TestPaymentsIncludeFailed(t *testing.T) {
 	tt.Assert.Equal(2, successful)
 	tt.Assert.Equal(1, failed)
 
-	sql, _, err := query.sql.ToSql()
+	sql, err := q.operationsSQL(query.filter, nil)
 	tt.Assert.NoError(err)
-	tt.Assert.Equal("SELECT hop.id, hop.transaction_id, hop.application_order, hop.type, hop.details, hop.source_account, ht.transaction_hash, ht.tx_result, COALESCE(ht.successful, true) as transaction_successful FROM history_operations hop LEFT JOIN history_transactions ht ON ht.id = hop.transaction_id JOIN history_operation_participants hopp ON hopp.history_operation_id = hop.id WHERE hop.type IN (?,?,?,?,?) AND hopp.history_account_id = ?", sql)
+	got, _, err := sql.ToSql()
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("SELECT hop.id, hop.transaction_id, hop.application_order, hop.type, hop.details, hop.source_account, ht.transaction_hash, ht.tx_result, COALESCE(ht.successful, true) as transaction_successful FROM history_operations hop LEFT JOIN history_transactions ht ON ht.id = hop.transaction_id JOIN history_operation_participants hopp ON hopp.history_operation_id = hop.id WHERE hopp.history_account_id = ? AND hop.type IN (?,?,?,?,?)", got)
 }
 
 func TestExtraChecksOperationsTransactionSuccessfulTrueResultFalse(t *testing.T) {
@@ -220,7 +273,7 @@ func TestExtraChecksOperationsTransactionSuccessfulTrueResultFalse(t *testing.T)
 		ForAccount("GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2").
 		IncludeFailed()
 
-	_, _, err = query.Fetch()
+	_, _, err = query.Fetch(tt.Ctx)
 	tt.Assert.Error(err)
 	tt.Assert.Contains(err.Error(), "Corrupted data! `successful=true` but returned transaction is not success")
 }
@@ -240,7 +293,7 @@ func TestExtraChecksOperationsTransactionSuccessfulFalseResultTrue(t *testing.T)
 		ForAccount("GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON").
 		IncludeFailed()
 
-	_, _, err = query.Fetch()
+	_, _, err = query.Fetch(tt.Ctx)
 	tt.Assert.Error(err)
 	tt.Assert.Contains(err.Error(), "Corrupted data! `successful=false` but returned transaction is success")
 }
@@ -264,7 +317,7 @@ func TestOperationIncludeTransactions(t *testing.T) {
 		IncludeTransactions().
 		ForAccount(accountID)
 
-	operations, transactions, err := query.Fetch()
+	operations, transactions, err := query.Fetch(tt.Ctx)
 	tt.Assert.NoError(err)
 	tt.Assert.Len(transactions, 3)
 	tt.Assert.Len(transactions, len(operations))
@@ -282,7 +335,7 @@ func TestOperationIncludeTransactions(t *testing.T) {
 	err = (&Q{tt.HorizonSession()}).Transactions().ForAccount(accountID).Select(&expectedTransactions)
 	tt.Assert.NoError(err)
 
-	expectedOperations, _, err := withoutTransactionsQuery.Fetch()
+	expectedOperations, _, err := withoutTransactionsQuery.Fetch(tt.Ctx)
 	tt.Assert.NoError(err)
 
 	tt.Assert.Equal(operations, expectedOperations)
@@ -321,7 +374,7 @@ func TestValidateTransactionForOperation(t *testing.T) {
 		IncludeTransactions().
 		ForAccount(accountID)
 
-	_, _, err := query.Fetch()
+	_, _, err := query.Fetch(tt.Ctx)
 	tt.Assert.Error(err)
 	tt.Assert.EqualError(err, "transaction with id 17179877376 could not be found")
 
@@ -338,7 +391,7 @@ func TestValidateTransactionForOperation(t *testing.T) {
 		IncludeTransactions().
 		ForAccount(accountID)
 
-	_, _, err = query.Fetch()
+	_, _, err = query.Fetch(tt.Ctx)
 	tt.Assert.Error(err)
 	tt.Assert.EqualError(err, "transaction result  does not match transaction result in operation AAAAAAAAAGQAAAAAAAAAAQAAAAAAAAABAAAAAAAAAAA=")
 
@@ -355,7 +408,7 @@ func TestValidateTransactionForOperation(t *testing.T) {
 		IncludeTransactions().
 		ForAccount(accountID)
 
-	_, _, err = query.Fetch()
+	_, _, err = query.Fetch(tt.Ctx)
 	tt.Assert.Error(err)
 	tt.Assert.EqualError(err, "transaction hash  does not match transaction hash in operation 1c454630267aa8767ec8c8e30450cea6ba660145e9c924abb75d7a6669b6c28a")
 
@@ -372,7 +425,7 @@ func TestValidateTransactionForOperation(t *testing.T) {
 		IncludeTransactions().
 		ForAccount(accountID)
 
-	_, _, err = query.Fetch()
+	_, _, err = query.Fetch(tt.Ctx)
 	tt.Assert.Error(err)
 	tt.Assert.EqualError(err, "transaction successful flag false does not match transaction successful flag in operation true")
 
@@ -380,3 +433,34 @@ func TestValidateTransactionForOperation(t *testing.T) {
 	tt.Assert.Error(err)
 	tt.Assert.EqualError(err, "transaction successful flag false does not match transaction successful flag in operation true")
 }
+
+func TestFeeStatsForLedgerRange(t *testing.T) {
+	tt := test.Start(t).Scenario("base")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	var stats FeeStats
+	err := q.FeeStatsForLedgerRange(3, 2, &stats)
+	tt.Assert.EqualError(err, "from sequence must not be greater than to sequence")
+
+	_, err = q.ExecRaw("UPDATE history_transactions SET fee_charged = 200 WHERE ledger_sequence = 3")
+	tt.Require.NoError(err)
+
+	tt.Require.NoError(q.FeeStatsForLedgerRange(2, 3, &stats))
+	tt.Assert.Equal(int64(100), stats.FeeChargedMin.Int64)
+	tt.Assert.Equal(int64(200), stats.FeeChargedMax.Int64)
+	tt.Assert.Equal(int64(100), stats.FeeChargedMode.Int64)
+	tt.Assert.Equal(int64(100), stats.FeeChargedP50.Int64)
+	tt.Assert.Equal(int64(200), stats.FeeChargedP99.Int64)
+	tt.Assert.Equal(int64(100), stats.MaxFeeMax.Int64)
+
+	stats = FeeStats{}
+	tt.Require.NoError(q.FeeStatsForLedgerRange(3, 3, &stats))
+	tt.Assert.Equal(int64(200), stats.FeeChargedMin.Int64)
+	tt.Assert.Equal(int64(200), stats.FeeChargedP10.Int64)
+
+	stats = FeeStats{}
+	tt.Require.NoError(q.FeeStatsForLedgerRange(4, 10, &stats))
+	tt.Assert.False(stats.FeeChargedMax.Valid)
+	tt.Assert.False(stats.MaxFeeP50.Valid)
+}
@@ -1,9 +1,11 @@
 package history
 
 import (
+	"context"
 	"database/sql"
 	"github.com/stellar/go/amount"
 	"math/big"
+	"sort"
 
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
@@ -153,3 +155,101 @@ func (q *Q) GetOrderBookSummary(sellingAsset, buyingAsset xdr.Asset, maxPriceLev
 
 	return result, nil
 }
+
+// GetOrderBookSummaryAtLedger returns an OrderBookSummary for a given trading
+// pair as it was at the end of the given ledger. The summary is built from
+// the offer snapshots in the history_offers table so it's only available for
+// ledgers ingested with offer history.
+func (q *Q) GetOrderBookSummaryAtLedger(
+	ctx context.Context,
+	sellingAsset, buyingAsset xdr.Asset,
+	sequence uint32,
+	maxPriceLevels int,
+) (OrderBookSummary, error) {
+	var result OrderBookSummary
+
+	if sequence == 0 {
+		return result, errors.New("ledger sequence must be greater than 0")
+	}
+
+	asks, err := q.GetHistoryOffers(ctx, HistoryOffersFilter{
+		Selling:  &sellingAsset,
+		Buying:   &buyingAsset,
+		AtLedger: sequence,
+	})
+	if err != nil {
+		return result, errors.Wrap(err, "cannot select asks")
+	}
+	bids, err := q.GetHistoryOffers(ctx, HistoryOffersFilter{
+		Selling:  &buyingAsset,
+		Buying:   &sellingAsset,
+		AtLedger: sequence,
+	})
+	if err != nil {
+		return result, errors.Wrap(err, "cannot select bids")
+	}
+
+	if result.Asks, err = summarizeHistoryOffers(asks, false, maxPriceLevels); err != nil {
+		return result, errors.Wrap(err, "cannot summarize asks")
+	}
+	if result.Bids, err = summarizeHistoryOffers(bids, true, maxPriceLevels); err != nil {
+		return result, errors.Wrap(err, "cannot summarize bids")
+	}
+	return result, nil
+}
+
+// summarizeHistoryOffers aggregates the amounts of offers at the same price
+// into at most maxPriceLevels price levels, cheapest offers first. Prices of
+// bids are inverted so they are expressed in the same terms as asks.
+func summarizeHistoryOffers(offers []HistoryOffer, bids bool, maxPriceLevels int) ([]PriceLevel, error) {
+	type level struct {
+		price  *big.Rat
+		amount *big.Int
+	}
+
+	levelsByPrice := map[string]*level{}
+	var levels []*level
+	for _, offer := range offers {
+		if offer.Priced == 0 {
+			return nil, errors.Errorf("offer %d has price denominator equal to 0", offer.OfferID)
+		}
+		price := big.NewRat(int64(offer.Pricen), int64(offer.Priced))
+		l, ok := levelsByPrice[price.String()]
+		if !ok {
+			l = &level{price: price, amount: new(big.Int)}
+			levelsByPrice[price.String()] = l
+			levels = append(levels, l)
+		}
+		l.amount.Add(l.amount, big.NewInt(int64(offer.Amount)))
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].price.Cmp(levels[j].price) < 0
+	})
+	if len(levels) > maxPriceLevels {
+		levels = levels[:maxPriceLevels]
+	}
+
+	var result []PriceLevel
+	for _, l := range levels {
+		priceFraction := l.price
+		if bids {
+			if priceFraction.Sign() == 0 {
+				return nil, errors.New("bid has price numerator equal to 0")
+			}
+			priceFraction = new(big.Rat).Inv(priceFraction)
+		}
+
+		amountString, err := amount.IntStringToAmount(l.amount.String())
+		if err != nil {
+			return nil, errors.Wrap(err, "could not determine summary amount")
+		}
+		result = append(result, PriceLevel{
+			Pricef: priceFraction.FloatString(7),
+			Pricen: int32(priceFraction.Num().Int64()),
+			Priced: int32(priceFraction.Denom().Int64()),
+			Amount: amountString,
+		})
+	}
+	return result, nil
+}
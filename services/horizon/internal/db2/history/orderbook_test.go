@@ -265,3 +265,75 @@ func TestGetOrderBookSummaryExcludesRemovedOffers(t *testing.T) {
 
 	assert.NoError(t, q.Rollback())
 }
+
+func TestSummarizeHistoryOffers(t *testing.T) {
+	offers := []HistoryOffer{
+		{OfferID: 1, Pricen: 2, Priced: 1, Amount: 500},
+		{OfferID: 2, Pricen: 3, Priced: 1, Amount: 100},
+		// same price as offer 1 in a non-canonical representation
+		{OfferID: 3, Pricen: 30, Priced: 15, Amount: 250},
+		{OfferID: 4, Pricen: 1, Priced: 2, Amount: 10},
+	}
+
+	levels, err := summarizeHistoryOffers(offers, false, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []PriceLevel{
+		{Pricen: 1, Priced: 2, Pricef: "0.5000000", Amount: "0.0000010"},
+		{Pricen: 2, Priced: 1, Pricef: "2.0000000", Amount: "0.0000750"},
+	}, levels)
+
+	levels, err = summarizeHistoryOffers(offers, true, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []PriceLevel{
+		{Pricen: 2, Priced: 1, Pricef: "2.0000000", Amount: "0.0000010"},
+		{Pricen: 1, Priced: 2, Pricef: "0.5000000", Amount: "0.0000750"},
+		{Pricen: 1, Priced: 3, Pricef: "0.3333333", Amount: "0.0000100"},
+	}, levels)
+
+	levels, err = summarizeHistoryOffers(nil, false, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, levels)
+
+	_, err = summarizeHistoryOffers([]HistoryOffer{{OfferID: 5, Pricen: 0, Priced: 1}}, true, 10)
+	assert.EqualError(t, err, "bid has price numerator equal to 0")
+}
+
+func TestGetOrderBookSummaryAtLedger(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	sellEurOffer := twoEurOffer
+	sellEurOffer.Buying, sellEurOffer.Selling = sellEurOffer.Selling, sellEurOffer.Buying
+	sellEurOffer.OfferId = 15
+
+	batch := q.NewHistoryOffersBatchInsertBuilder(0)
+	assert.NoError(t, batch.Add(twoEurOffer, 10, false))
+	assert.NoError(t, batch.Add(threeEurOffer, 11, false))
+	assert.NoError(t, batch.Add(sellEurOffer, 11, false))
+	assert.NoError(t, batch.Add(twoEurOffer, 12, true))
+	assert.NoError(t, batch.Exec())
+
+	result, err := q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, 10, 100)
+	assert.NoError(t, err)
+	assert.Len(t, result.Asks, 1)
+	assert.Len(t, result.Bids, 0)
+
+	result, err = q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, 11, 100)
+	assert.NoError(t, err)
+	if assert.Len(t, result.Asks, 2) {
+		assert.Equal(t, "2.0000000", result.Asks[0].Pricef)
+		assert.Equal(t, "3.0000000", result.Asks[1].Pricef)
+	}
+	if assert.Len(t, result.Bids, 1) {
+		assert.Equal(t, "0.5000000", result.Bids[0].Pricef)
+	}
+
+	// twoEurOffer was removed in ledger 12
+	result, err = q.GetOrderBookSummaryAtLedger(tt.Ctx, nativeAsset, eurAsset, 12, 100)
+	assert.NoError(t, err)
+	if assert.Len(t, result.Asks, 1) {
+		assert.Equal(t, "3.0000000", result.Asks[0].Pricef)
+	}
+}
@@ -0,0 +1,105 @@
+package history
+
+import (
+	"strings"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// normalizeSQL removes the formatting whitespace of hand written queries.
+func normalizeSQL(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	sql = strings.Replace(sql, "( ", "(", -1)
+	return strings.Replace(sql, " )", ")", -1)
+}
+
+func assertSameSQL(t *testing.T, expected, actual sq.Sqlizer) {
+	expectedSQL, expectedArgs, err := expected.ToSql()
+	require.NoError(t, err)
+	actualSQL, actualArgs, err := actual.ToSql()
+	require.NoError(t, err)
+	assert.Equal(t, normalizeSQL(expectedSQL), normalizeSQL(actualSQL))
+	assert.Equal(t, expectedArgs, actualArgs)
+}
+
+// The queries below were hand written before paging used db2.KeysetPager.
+
+func TestTradesPagingSQL(t *testing.T) {
+	page := db2.MustPageQuery("10-2", false, "asc", 10)
+	page.StopCursor = "20-1"
+	sql, err := (&Q{}).tradesSQL(TradesFilter{}, false, page)
+	require.NoError(t, err)
+	assertSameSQL(t, selectTrades(selectTradeFields).
+		Where(`(
+				htrd.history_operation_id >= ?
+			AND (
+				htrd.history_operation_id > ? OR
+				(htrd.history_operation_id = ? AND htrd.order > ?)
+			))`, int64(10), int64(10), int64(10), int64(2)).
+		OrderBy("htrd.history_operation_id asc, htrd.order asc").
+		Where(`(
+				htrd.history_operation_id <= ?
+			AND (
+				htrd.history_operation_id < ? OR
+				(htrd.history_operation_id = ? AND htrd.order < ?)
+			))`, int64(20), int64(20), int64(20), int64(1)).
+		Limit(10), sql)
+
+	page = db2.MustPageQuery("10-2", false, "desc", 10)
+	sql, err = (&Q{}).tradesSQL(TradesFilter{}, false, page)
+	require.NoError(t, err)
+	assertSameSQL(t, selectTrades(selectTradeFields).
+		Where(`(
+				htrd.history_operation_id <= ?
+			AND (
+				htrd.history_operation_id < ? OR
+				(htrd.history_operation_id = ? AND htrd.order < ?)
+			))`, int64(10), int64(10), int64(10), int64(2)).
+		OrderBy("htrd.history_operation_id desc, htrd.order desc").
+		Limit(10), sql)
+}
+
+func TestEffectsPagingSQL(t *testing.T) {
+	for _, order := range []string{"asc", "desc"} {
+		sql, err := pageEffects(selectEffect, db2.MustPageQuery("10-2", false, order, 10))
+		require.NoError(t, err)
+
+		expected := selectEffect
+		if order == "asc" {
+			expected = expected.
+				Where(`(
+						 heff.history_operation_id >= ?
+					AND (
+						 heff.history_operation_id > ? OR
+						(heff.history_operation_id = ? AND heff.order > ?)
+					))`, int64(10), int64(10), int64(10), int64(2)).
+				OrderBy("heff.history_operation_id asc, heff.order asc")
+		} else {
+			expected = expected.
+				Where(`(
+						 heff.history_operation_id <= ?
+					AND (
+						 heff.history_operation_id < ? OR
+						(heff.history_operation_id = ? AND heff.order < ?)
+					))`, int64(10), int64(10), int64(10), int64(2)).
+				OrderBy("heff.history_operation_id desc, heff.order desc")
+		}
+		assertSameSQL(t, expected.Limit(10), sql)
+	}
+}
+
+func TestOperationsPagingSQL(t *testing.T) {
+	for _, order := range []string{"asc", "desc"} {
+		page := db2.MustPageQuery("10", false, order, 10)
+		sql, err := (&Q{}).operationsSQL(OperationsFilter{IncludeFailed: true}, &page)
+		require.NoError(t, err)
+
+		expected, err := page.ApplyTo(selectOperation, "hop.id")
+		require.NoError(t, err)
+		assertSameSQL(t, expected, sql)
+	}
+}
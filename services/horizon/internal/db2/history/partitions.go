@@ -0,0 +1,220 @@
+package history
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/db/pg"
+	"github.com/stellar/go/support/errors"
+)
+
+// HistoryPartitionLedgers is the number of ledgers in each partition of the
+// partitioned history tables, about a week of ledgers.
+const HistoryPartitionLedgers = 120960
+
+// partitionedHistoryTables maps the history tables which can be partitioned
+// to the toid column they are partitioned by.
+var partitionedHistoryTables = []struct {
+	table  string
+	column string
+}{
+	{"history_effects", "history_operation_id"},
+	{"history_operations", "id"},
+	{"history_trades", "history_operation_id"},
+}
+
+// historyPartition is a partition of a history table holding the rows of
+// ledgers between startLedger (inclusive) and endLedger (exclusive).
+// Partitions are named <table>_<startLedger>_<endLedger>.
+type historyPartition struct {
+	name        string
+	startLedger uint32
+	endLedger   uint32
+}
+
+func newHistoryPartition(table string, ledger uint32) historyPartition {
+	start := ledger - ledger%HistoryPartitionLedgers
+	end := start + HistoryPartitionLedgers
+	return historyPartition{
+		name:        fmt.Sprintf("%s_%d_%d", table, start, end),
+		startLedger: start,
+		endLedger:   end,
+	}
+}
+
+// parseHistoryPartition parses the name of a partition of table, returning
+// false if it's not named like a partition created by horizon.
+func parseHistoryPartition(table, name string) (historyPartition, bool) {
+	parts := strings.Split(strings.TrimPrefix(name, table+"_"), "_")
+	if !strings.HasPrefix(name, table+"_") || len(parts) != 2 {
+		return historyPartition{}, false
+	}
+	start, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return historyPartition{}, false
+	}
+	end, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil || end <= start {
+		return historyPartition{}, false
+	}
+	return historyPartition{name: name, startLedger: uint32(start), endLedger: uint32(end)}, true
+}
+
+func (p historyPartition) contains(ledger uint32) bool {
+	return ledger >= p.startLedger && ledger < p.endLedger
+}
+
+// within returns true if all the rows the partition can hold have ids between
+// start and end (exclusive).
+func (p historyPartition) within(start, end int64) bool {
+	// the partition created by partition_history_table for the existing rows
+	// has no lower bound
+	if p.startLedger == 0 && start > 0 {
+		return false
+	}
+	return toid.New(int32(p.startLedger), 0, 0).ToInt64() >= start &&
+		toid.New(int32(p.endLedger), 0, 0).ToInt64() <= end
+}
+
+// PartitionHistoryTables converts history_effects, history_operations and
+// history_trades to tables partitioned by ranges of HistoryPartitionLedgers
+// ledgers. The existing rows are kept in a single partition. It requires
+// postgres 11 or later and does nothing for tables which are already
+// partitioned.
+func (q *Q) PartitionHistoryTables() error {
+	for _, t := range partitionedHistoryTables {
+		_, err := q.ExecRaw(
+			"SELECT partition_history_table($1, $2, $3)",
+			t.table, t.column, HistoryPartitionLedgers,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "could not partition %s", t.table)
+		}
+	}
+	return nil
+}
+
+// partitionedTables returns the history tables which are partitioned.
+func (q *Q) partitionedTables() ([]string, error) {
+	tables := make([]string, 0, len(partitionedHistoryTables))
+	for _, t := range partitionedHistoryTables {
+		tables = append(tables, t.table)
+	}
+
+	var partitioned []string
+	err := q.SelectRaw(
+		&partitioned,
+		"SELECT relname FROM pg_class WHERE relkind = 'p' AND relname = ANY($1) AND pg_table_is_visible(oid)",
+		pq.Array(tables),
+	)
+	return partitioned, err
+}
+
+// historyPartitions returns the partitions of table created by horizon.
+func (q *Q) historyPartitions(table string) ([]historyPartition, error) {
+	var names []string
+	err := q.SelectRaw(&names, `
+		SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = $1::regclass
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]historyPartition, 0, len(names))
+	for _, name := range names {
+		if partition, ok := parseHistoryPartition(table, name); ok {
+			partitions = append(partitions, partition)
+		}
+	}
+	return partitions, nil
+}
+
+// CreateHistoryPartitions creates the partitions of the partitioned history
+// tables holding the rows of ledger if they don't exist yet. It must be
+// called before inserting the rows of a ledger into the history tables, on a
+// session outside of the ingestion transaction: creating a partition locks
+// the partitioned table until the transaction creating it ends. Partitions
+// created concurrently by other nodes are not errors.
+func (q *Q) CreateHistoryPartitions(ledger uint32) error {
+	tables, err := q.partitionedTables()
+	if err != nil {
+		return errors.Wrap(err, "could not load partitioned tables")
+	}
+
+	for _, table := range tables {
+		partitions, err := q.historyPartitions(table)
+		if err != nil {
+			return errors.Wrapf(err, "could not load partitions of %s", table)
+		}
+
+		exists := false
+		for _, partition := range partitions {
+			if partition.contains(ledger) {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+
+		partition := newHistoryPartition(table, ledger)
+		_, err = q.ExecRaw(fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d)",
+			partition.name,
+			table,
+			toid.New(int32(partition.startLedger), 0, 0).ToInt64(),
+			toid.New(int32(partition.endLedger), 0, 0).ToInt64(),
+		))
+		// IF NOT EXISTS doesn't prevent errors when the partition is
+		// created by another node at the same time
+		if err != nil && !pg.IsDuplicateTable(err) && !pg.IsUniqueViolation(err) {
+			return errors.Wrapf(err, "could not create partition %s", partition.name)
+		}
+	}
+	return nil
+}
+
+// deleteHistoryRange deletes the rows of table whose idCol is between start
+// and end (exclusive). When table is partitioned, the partitions holding only
+// rows of the range are dropped (or truncated unless drop is set) instead of
+// deleting their rows one by one. Partitions of ranges ingested again must
+// not be dropped: dropping a partition locks the partitioned table until the
+// end of the transaction so CreateHistoryPartitions, which runs outside of
+// it, would wait forever.
+func (q *Q) deleteHistoryRange(start, end int64, table, idCol string, drop bool) error {
+	tables, err := q.partitionedTables()
+	if err != nil {
+		return errors.Wrap(err, "could not load partitioned tables")
+	}
+
+	for _, partitioned := range tables {
+		if partitioned != table {
+			continue
+		}
+
+		partitions, err := q.historyPartitions(table)
+		if err != nil {
+			return errors.Wrapf(err, "could not load partitions of %s", table)
+		}
+		for _, partition := range partitions {
+			if !partition.within(start, end) {
+				continue
+			}
+			statement := "TRUNCATE TABLE "
+			if drop {
+				statement = "DROP TABLE "
+			}
+			if _, err := q.ExecRaw(statement + partition.name); err != nil {
+				return errors.Wrapf(err, "could not delete partition %s", partition.name)
+			}
+		}
+	}
+
+	return q.DeleteRange(start, end, table, idCol)
+}
@@ -0,0 +1,51 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryPartitionNames(t *testing.T) {
+	partition := newHistoryPartition("history_trades", 2*HistoryPartitionLedgers+10)
+	assert.Equal(t, "history_trades_241920_362880", partition.name)
+	assert.True(t, partition.contains(2*HistoryPartitionLedgers))
+	assert.False(t, partition.contains(3*HistoryPartitionLedgers))
+
+	parsed, ok := parseHistoryPartition("history_trades", partition.name)
+	assert.True(t, ok)
+	assert.Equal(t, partition, parsed)
+
+	for _, name := range []string{
+		"history_trades_1",
+		"history_trades_10_5",
+		"history_trades_a_10",
+		"history_trades_legacy",
+		"history_effects_0_10",
+	} {
+		_, ok := parseHistoryPartition("history_trades", name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestHistoryPartitionWithin(t *testing.T) {
+	first := historyPartition{name: "history_trades_0_200", startLedger: 0, endLedger: 200}
+	second := historyPartition{name: "history_trades_200_300", startLedger: 200, endLedger: 300}
+
+	start, end, err := toid.LedgerRangeInclusive(1, 299)
+	assert.NoError(t, err)
+	assert.True(t, first.within(start, end))
+	assert.True(t, second.within(start, end))
+
+	start, end, err = toid.LedgerRangeInclusive(1, 298)
+	assert.NoError(t, err)
+	assert.True(t, first.within(start, end))
+	assert.False(t, second.within(start, end))
+
+	// the first partition has no lower bound
+	start, end, err = toid.LedgerRangeInclusive(2, 299)
+	assert.NoError(t, err)
+	assert.False(t, first.within(start, end))
+	assert.True(t, second.within(start, end))
+}
@@ -0,0 +1,49 @@
+package history
+
+import (
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ReingestCheckpoint records that all the ledgers of [BatchFrom, LastLedger]
+// were committed by the reingestion of the [RangeFrom, RangeTo] range.
+type ReingestCheckpoint struct {
+	RangeFrom  uint32 `db:"range_from"`
+	RangeTo    uint32 `db:"range_to"`
+	BatchFrom  uint32 `db:"batch_from"`
+	LastLedger uint32 `db:"last_ledger"`
+}
+
+// GetReingestCheckpoints loads the checkpoints of the reingestion of the
+// [rangeFrom, rangeTo] range.
+func (q *Q) GetReingestCheckpoints(rangeFrom, rangeTo uint32) ([]ReingestCheckpoint, error) {
+	sql := sq.Select("range_from", "range_to", "batch_from", "last_ledger").
+		From("reingest_checkpoints").
+		Where(sq.Eq{"range_from": rangeFrom, "range_to": rangeTo}).
+		OrderBy("batch_from asc")
+
+	var checkpoints []ReingestCheckpoint
+	err := q.Select(&checkpoints, sql)
+	return checkpoints, err
+}
+
+// UpdateReingestCheckpoint records that all the ledgers of [batchFrom,
+// lastLedger] were committed by the reingestion of the [rangeFrom, rangeTo]
+// range. It must be called in the transaction committing lastLedger.
+func (q *Q) UpdateReingestCheckpoint(rangeFrom, rangeTo, batchFrom, lastLedger uint32) error {
+	_, err := q.ExecRaw(`
+		INSERT INTO reingest_checkpoints (range_from, range_to, batch_from, last_ledger)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (range_from, range_to, batch_from)
+		DO UPDATE SET last_ledger = EXCLUDED.last_ledger, updated_at = now()`,
+		rangeFrom, rangeTo, batchFrom, lastLedger,
+	)
+	return err
+}
+
+// DeleteReingestCheckpoints removes the checkpoints of the reingestion of
+// the [rangeFrom, rangeTo] range once it has been fully reingested.
+func (q *Q) DeleteReingestCheckpoints(rangeFrom, rangeTo uint32) error {
+	_, err := q.Exec(sq.Delete("reingest_checkpoints").
+		Where(sq.Eq{"range_from": rangeFrom, "range_to": rangeTo}))
+	return err
+}
@@ -0,0 +1,38 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestReingestCheckpoints(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	checkpoints, err := q.GetReingestCheckpoints(100, 300)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(checkpoints)
+
+	tt.Assert.NoError(q.UpdateReingestCheckpoint(100, 300, 200, 201))
+	tt.Assert.NoError(q.UpdateReingestCheckpoint(100, 300, 100, 150))
+	tt.Assert.NoError(q.UpdateReingestCheckpoint(100, 300, 200, 250))
+	tt.Assert.NoError(q.UpdateReingestCheckpoint(100, 200, 100, 200))
+
+	checkpoints, err = q.GetReingestCheckpoints(100, 300)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]ReingestCheckpoint{
+		{RangeFrom: 100, RangeTo: 300, BatchFrom: 100, LastLedger: 150},
+		{RangeFrom: 100, RangeTo: 300, BatchFrom: 200, LastLedger: 250},
+	}, checkpoints)
+
+	tt.Assert.NoError(q.DeleteReingestCheckpoints(100, 300))
+	checkpoints, err = q.GetReingestCheckpoints(100, 300)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(checkpoints)
+
+	checkpoints, err = q.GetReingestCheckpoints(100, 200)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(checkpoints, 1)
+}
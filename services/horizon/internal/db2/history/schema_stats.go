@@ -0,0 +1,126 @@
+package history
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// TableStats contains the size statistics of a history table.
+type TableStats struct {
+	Table string `db:"table_name" json:"table"`
+	// TableSize is the size of the table (including TOAST data) in bytes.
+	TableSize int64 `db:"table_size" json:"table_size"`
+	// IndexesSize is the size of all the indexes of the table in bytes.
+	IndexesSize int64 `db:"indexes_size" json:"indexes_size"`
+	// EstimatedRows is the number of rows estimated by the query planner.
+	EstimatedRows int64 `db:"estimated_rows" json:"estimated_rows"`
+	LiveRows      int64 `db:"live_rows" json:"live_rows"`
+	DeadRows      int64 `db:"dead_rows" json:"dead_rows"`
+	// BloatEstimate is the estimated number of bytes used by dead rows.
+	BloatEstimate int64 `db:"-" json:"bloat_estimate"`
+	// OldestLedger and NewestLedger are the ledger range of the rows in
+	// the table. They are 0 if the table is empty or is not indexed by
+	// ledger.
+	OldestLedger int32        `db:"-" json:"oldest_ledger"`
+	NewestLedger int32        `db:"-" json:"newest_ledger"`
+	Indexes      []IndexStats `db:"-" json:"indexes"`
+}
+
+// IndexStats contains the size of an index of a history table.
+type IndexStats struct {
+	Table string `db:"table_name" json:"-"`
+	Index string `db:"index_name" json:"index"`
+	Size  int64  `db:"index_size" json:"size"`
+}
+
+// historyTableLedgerColumns maps history tables to their column containing
+// a total order id (see toid package) which encodes the ledger sequence.
+var historyTableLedgerColumns = map[string]string{
+	"history_account_events":           "history_operation_id",
+	"history_account_thresholds":       "history_operation_id",
+	"history_data_quality_violations":  "history_transaction_id",
+	"history_effects":                  "history_operation_id",
+	"history_ledgers":                  "id",
+	"history_operation_participants":   "history_operation_id",
+	"history_operations":               "id",
+	"history_trades":                   "history_operation_id",
+	"history_transaction_participants": "history_transaction_id",
+	"history_transactions":             "id",
+}
+
+// SchemaStats returns the size statistics of all the history tables ordered
+// by table name. Sizes are read from the Postgres catalog so they are cheap
+// to compute, row counts and bloat estimates are only as accurate as the
+// statistics collected by the last ANALYZE.
+func (q *Q) SchemaStats() ([]TableStats, error) {
+	var tables []TableStats
+	if err := q.SelectRaw(&tables, selectTableStats); err != nil {
+		return nil, errors.Wrap(err, "could not select table stats")
+	}
+
+	var indexes []IndexStats
+	if err := q.SelectRaw(&indexes, selectIndexStats); err != nil {
+		return nil, errors.Wrap(err, "could not select index stats")
+	}
+
+	for i := range tables {
+		table := &tables[i]
+		for _, index := range indexes {
+			if index.Table == table.Table {
+				table.Indexes = append(table.Indexes, index)
+			}
+		}
+
+		if total := table.LiveRows + table.DeadRows; total > 0 {
+			table.BloatEstimate = int64(float64(table.TableSize) * float64(table.DeadRows) / float64(total))
+		}
+
+		column, ok := historyTableLedgerColumns[table.Table]
+		if !ok {
+			continue
+		}
+		var ledgers struct {
+			Oldest int32 `db:"oldest"`
+			Newest int32 `db:"newest"`
+		}
+		// min and max of an indexed column are read from the index
+		err := q.GetRaw(&ledgers, fmt.Sprintf(
+			"SELECT COALESCE(MIN(%[1]s) >> 32, 0) AS oldest, COALESCE(MAX(%[1]s) >> 32, 0) AS newest FROM %[2]s",
+			column, table.Table,
+		))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not select ledger range of %s", table.Table)
+		}
+		table.OldestLedger = ledgers.Oldest
+		table.NewestLedger = ledgers.Newest
+	}
+
+	return tables, nil
+}
+
+var selectTableStats = `
+	SELECT
+		c.relname AS table_name,
+		pg_table_size(c.oid) AS table_size,
+		pg_indexes_size(c.oid) AS indexes_size,
+		GREATEST(c.reltuples, 0)::bigint AS estimated_rows,
+		COALESCE(s.n_live_tup, 0) AS live_rows,
+		COALESCE(s.n_dead_tup, 0) AS dead_rows
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+	WHERE c.relkind = 'r'
+	AND n.nspname = current_schema()
+	AND c.relname LIKE 'history\_%'
+	ORDER BY c.relname`
+
+var selectIndexStats = `
+	SELECT
+		s.relname AS table_name,
+		s.indexrelname AS index_name,
+		pg_relation_size(s.indexrelid) AS index_size
+	FROM pg_stat_user_indexes s
+	WHERE s.schemaname = current_schema()
+	AND s.relname LIKE 'history\_%'
+	ORDER BY s.relname, s.indexrelname`
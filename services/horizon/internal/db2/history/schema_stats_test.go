@@ -0,0 +1,60 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestSchemaStats(t *testing.T) {
+	tt := test.Start(t).Scenario("base")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	var elder, latest int32
+	tt.Require.NoError(q.ElderLedger(&elder))
+	tt.Require.NoError(q.LatestLedger(&latest))
+
+	_, err := q.ExecRaw("ANALYZE")
+	tt.Require.NoError(err)
+
+	stats, err := q.SchemaStats()
+	tt.Require.NoError(err)
+
+	byTable := map[string]TableStats{}
+	for _, table := range stats {
+		tt.Assert.Regexp("^history_", table.Table)
+		byTable[table.Table] = table
+	}
+
+	for table := range historyTableLedgerColumns {
+		tt.Assert.Contains(byTable, table)
+	}
+
+	ledgers := byTable["history_ledgers"]
+	tt.Assert.True(ledgers.TableSize > 0)
+	tt.Assert.True(ledgers.IndexesSize > 0)
+	tt.Assert.Equal(elder, ledgers.OldestLedger)
+	tt.Assert.Equal(latest, ledgers.NewestLedger)
+	if tt.Assert.NotEmpty(ledgers.Indexes) {
+		var total int64
+		for _, index := range ledgers.Indexes {
+			tt.Assert.Equal("history_ledgers", index.Table)
+			total += index.Size
+		}
+		tt.Assert.Equal(ledgers.IndexesSize, total)
+	}
+
+	transactions := byTable["history_transactions"]
+	tt.Assert.True(transactions.EstimatedRows > 0)
+	tt.Assert.True(transactions.OldestLedger >= elder)
+	tt.Assert.True(transactions.NewestLedger <= latest)
+
+	// tables without rows have no ledger range
+	accountEvents := byTable["history_account_events"]
+	tt.Assert.Equal(int32(0), accountEvents.OldestLedger)
+	tt.Assert.Equal(int32(0), accountEvents.NewestLedger)
+
+	// tables which are not indexed by ledger have no ledger range
+	tt.Assert.Equal(int32(0), byTable["history_accounts"].NewestLedger)
+}
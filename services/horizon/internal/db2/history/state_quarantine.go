@@ -0,0 +1,100 @@
+package history
+
+import (
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+)
+
+// Reasons of the quarantined entries, see QuarantinedEntry.
+const (
+	// QuarantineMismatch entries differ from the checkpoint.
+	QuarantineMismatch = "mismatch"
+	// QuarantineMissing entries of the checkpoint are not ingested.
+	QuarantineMissing = "missing"
+	// QuarantineCount entries record that the number of ingested entries of a
+	// type differs from the checkpoint, their LedgerKey is empty.
+	QuarantineCount = "count"
+)
+
+// QuarantinedEntry is a row of the `exp_state_quarantine` table, a ledger
+// entry whose ingested state doesn't match the history archive checkpoint
+// LedgerSequence. LedgerKey, Expected and Actual are base64 encoded XDR.
+type QuarantinedEntry struct {
+	ID             int64       `db:"id"`
+	LedgerSequence uint32      `db:"ledger_sequence"`
+	EntryType      string      `db:"entry_type"`
+	LedgerKey      string      `db:"ledger_key"`
+	Reason         string      `db:"reason"`
+	Expected       null.String `db:"expected"`
+	Actual         null.String `db:"actual"`
+	CreatedAt      time.Time   `db:"created_at"`
+}
+
+// ReplaceQuarantinedEntries replaces the quarantined entries with the ones
+// found by the state audit of the checkpoint ledger sequence and records
+// sequence as the ledger of the last audit. It should be run in a
+// transaction so the previous entries are kept if it fails.
+func (q *Q) ReplaceQuarantinedEntries(sequence uint32, entries []QuarantinedEntry) error {
+	if _, err := q.Exec(sq.Delete("exp_state_quarantine")); err != nil {
+		return errors.Wrap(err, "could not delete quarantined entries")
+	}
+
+	builder := db.BatchInsertBuilder{
+		Table:        q.GetTable("exp_state_quarantine"),
+		MaxBatchSize: 1000,
+	}
+	for _, entry := range entries {
+		err := builder.Row(map[string]interface{}{
+			"ledger_sequence": sequence,
+			"entry_type":      entry.EntryType,
+			"ledger_key":      entry.LedgerKey,
+			"reason":          entry.Reason,
+			"expected":        entry.Expected,
+			"actual":          entry.Actual,
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not add quarantined entry")
+		}
+	}
+	if err := builder.Exec(); err != nil {
+		return errors.Wrap(err, "could not insert quarantined entries")
+	}
+
+	return q.updateValueInStore(stateAuditLedger, strconv.FormatUint(uint64(sequence), 10))
+}
+
+// QuarantinedEntries loads the entries quarantined by the last state audit.
+func (q *Q) QuarantinedEntries() ([]QuarantinedEntry, error) {
+	var entries []QuarantinedEntry
+	sql := sq.Select(
+		"esq.id",
+		"esq.ledger_sequence",
+		"esq.entry_type",
+		"esq.ledger_key",
+		"esq.reason",
+		"esq.expected",
+		"esq.actual",
+		"esq.created_at",
+	).
+		From("exp_state_quarantine esq").
+		OrderBy("esq.id asc")
+	if err := q.Select(&entries, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select quarantined entries")
+	}
+	return entries, nil
+}
+
+// CountQuarantinedEntries returns the number of entries quarantined by the
+// last state audit.
+func (q *Q) CountQuarantinedEntries() (int, error) {
+	var count int
+	if err := q.Get(&count, sq.Select("count(*)").From("exp_state_quarantine")); err != nil {
+		return 0, errors.Wrap(err, "could not count quarantined entries")
+	}
+	return count, nil
+}
@@ -0,0 +1,65 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestQuarantinedEntries(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	sequence, err := q.GetStateAuditLedger()
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(0), sequence)
+
+	mismatch := QuarantinedEntry{
+		EntryType: "account",
+		LedgerKey: "AAAAAAAAAAC2LgFRDBZ3J52nLm30kq2iMgrO7dYzYAN3hvjtf1IHWg==",
+		Reason:    QuarantineMismatch,
+		Expected:  null.StringFrom("expected"),
+		Actual:    null.StringFrom("actual"),
+	}
+	count := QuarantinedEntry{
+		EntryType: "offer",
+		Reason:    QuarantineCount,
+		Expected:  null.StringFrom("2"),
+		Actual:    null.StringFrom("1"),
+	}
+	tt.Assert.NoError(q.ReplaceQuarantinedEntries(63, []QuarantinedEntry{mismatch, count}))
+
+	entries, err := q.QuarantinedEntries()
+	tt.Assert.NoError(err)
+	tt.Assert.Len(entries, 2)
+	for i, expected := range []QuarantinedEntry{mismatch, count} {
+		tt.Assert.Equal(uint32(63), entries[i].LedgerSequence)
+		tt.Assert.Equal(expected.EntryType, entries[i].EntryType)
+		tt.Assert.Equal(expected.LedgerKey, entries[i].LedgerKey)
+		tt.Assert.Equal(expected.Reason, entries[i].Reason)
+		tt.Assert.Equal(expected.Expected, entries[i].Expected)
+		tt.Assert.Equal(expected.Actual, entries[i].Actual)
+	}
+
+	// the next audit replaces the entries
+	tt.Assert.NoError(q.ReplaceQuarantinedEntries(127, []QuarantinedEntry{count}))
+	n, err := q.CountQuarantinedEntries()
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(1, n)
+
+	sequence, err = q.GetStateAuditLedger()
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(127), sequence)
+
+	tt.Assert.NoError(q.TruncateExpingestStateTables())
+	n, err = q.CountQuarantinedEntries()
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(0, n)
+
+	sequence, err = q.GetStateAuditLedger()
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(0), sequence)
+}
@@ -1,6 +1,7 @@
 package history
 
 import (
+	"context"
 	"fmt"
 	"math"
 
@@ -8,6 +9,7 @@ import (
 
 	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/support/errors"
+	strtime "github.com/stellar/go/support/time"
 	"github.com/stellar/go/xdr"
 )
 
@@ -21,187 +23,403 @@ func (r *Trade) HasPrice() bool {
 	return r.PriceN.Valid && r.PriceD.Valid
 }
 
+// TradesFilter defines the filters of a trades query. Zero values leave the
+// corresponding filter unset, all the filters which are set must match.
+type TradesFilter struct {
+	// AccountID only includes trades of the given account on either side.
+	AccountID string
+	// OfferID only includes trades of the given offer on either side.
+	OfferID int64
+	// AssetID only includes trades involving the given asset on either
+	// side. The asset is always rendered as the base asset of returned
+	// trades. It can't be combined with AccountID or OfferID.
+	AssetID int64
+	// BaseAssetID and CounterAssetID only include trades of the given asset
+	// pair, rendered with the given base and counter assets. They must be set
+	// together.
+	BaseAssetID    int64
+	CounterAssetID int64
+	// PriceRange only includes trades executed at a price within the range.
+	PriceRange *TradePriceRange
+	// StartTime and EndTime only include trades in ledgers closed within
+	// [StartTime, EndTime).
+	StartTime strtime.Millis
+	EndTime   strtime.Millis
+	// BySequence pages the trades by their sequence instead of their
+	// (history_operation_id, order) pair: the cursors of the page are trade
+	// sequences.
+	BySequence bool
+}
+
+// TradePriceRange is a closed range of prices between MinN/MinD and
+// MaxN/MaxD. Prices are compared as rational numbers, the same way as
+// xdr.Price, so no precision is lost. The price is the one returned in trade
+// records, ie. it takes into account reversed base/counter assets.
+type TradePriceRange struct {
+	MinN, MinD, MaxN, MaxD int32
+}
+
+func (r *TradePriceRange) validate() error {
+	if r.MinN < 0 || r.MaxN < 0 || r.MinD <= 0 || r.MaxD <= 0 {
+		return errors.New("invalid price range: numerators must be non-negative and denominators positive")
+	}
+	if int64(r.MinN)*int64(r.MaxD) > int64(r.MaxN)*int64(r.MinD) {
+		return errors.New("invalid price range: minimum price is greater than maximum price")
+	}
+	return nil
+}
+
+// GetTrades returns a page of trades matching the filter. Unlike the TradesQ
+// builder, errors of the lookups required by the filters (for example the
+// account of AccountID) are returned immediately.
+func (q *Q) GetTrades(ctx context.Context, filter TradesFilter, page db2.PageQuery) ([]Trade, error) {
+	var trades []Trade
+	if err := q.loadTrades(ctx, filter, false, page, &trades); err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// loadTrades loads the trades matching the filter into dest. reversed is true
+// when base and counter (and price) are swapped in selected fields.
+func (q *Q) loadTrades(ctx context.Context, filter TradesFilter, reversed bool, page db2.PageQuery, dest interface{}) error {
+	// trades are only read by history endpoints so they can be served by a
+	// replica of the database
+	q = &Q{q.WithContext(ctx).Replica()}
+
+	sql, err := q.tradesSQL(filter, reversed, page)
+	if err != nil {
+		return err
+	}
+
+	if err := q.Named("trades").Select(dest, sql); err != nil {
+		return errors.Wrap(err, "could not select trades")
+	}
+	return nil
+}
+
+// tradesSQL builds the query of a page of trades matching the filter. For
+// queries for account, offer and asset we construct UNION query. The
+// alternative is to use (base = X OR counter = X) query but it's costly.
+func (q *Q) tradesSQL(filter TradesFilter, reversed bool, page db2.PageQuery) (sq.Sqlizer, error) {
+	var conditions []sq.Sqlizer
+	if filter.BaseAssetID != 0 || filter.CounterAssetID != 0 {
+		if filter.BaseAssetID == 0 || filter.CounterAssetID == 0 {
+			return nil, errors.New("base and counter assets must be set together")
+		}
+		// The asset pair is always stored in canonical order, trades of the
+		// pair in the other order are rendered using reversed fields.
+		orderPreserved, baseAssetID, counterAssetID := getCanonicalAssetOrder(filter.BaseAssetID, filter.CounterAssetID)
+		if !orderPreserved {
+			reversed = !reversed
+		}
+		conditions = append(conditions, sq.Eq{"base_asset_id": baseAssetID, "counter_asset_id": counterAssetID})
+	}
+
+	if filter.PriceRange != nil {
+		if err := filter.PriceRange.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.AssetID != 0 && (filter.AccountID != "" || filter.OfferID != 0) {
+		return nil, errors.New("AssetID cannot be combined with AccountID or OfferID")
+	}
+
+	var accountID int64
+	if filter.AccountID != "" {
+		var account Account
+		if err := q.AccountByAddress(&account, filter.AccountID); err != nil {
+			return nil, errors.Wrap(err, "could not load account")
+		}
+		accountID = account.ID
+	}
+
+	cursor, stopCursor, err := tradeCursors(filter.BySequence, page)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := tradesPager
+	unionPager := tradesUnionPager
+	if filter.BySequence {
+		pager = tradesSequencePager
+		unionPager = tradesSequenceUnionPager
+	}
+
+	// appendFilters adds the conditions, the price range, the time range and
+	// the paging constraints of the query to sel.
+	appendFilters := func(sel sq.SelectBuilder, reversed bool) (sq.SelectBuilder, error) {
+		for _, condition := range conditions {
+			sel = sel.Where(condition)
+		}
+		sel = appendTradeTimeRange(appendTradePriceRange(sel, filter.PriceRange, reversed), filter)
+
+		sel, err := pager.ApplyTo(sel, page.Order, cursor...)
+		if err != nil {
+			return sel, err
+		}
+		if stopCursor != nil {
+			// bound the query on the opposite side of the cursor so trades
+			// can be streamed in both directions using the same paging tokens
+			return pager.ApplyStopTo(sel, page.Order, stopCursor...)
+		}
+		return sel, nil
+	}
+
+	fields := selectTradeFields
+	if reversed {
+		fields = selectReverseTradeFields
+	}
+
+	if accountID == 0 && filter.OfferID == 0 && filter.AssetID == 0 {
+		sql, err := appendFilters(selectTrades(fields), reversed)
+		if err != nil {
+			return nil, err
+		}
+		return sql.Limit(page.Limit), nil
+	}
+
+	// Construct UNION query
+	var firstSelect, secondSelect sq.SelectBuilder
+	firstReversed, secondReversed := reversed, reversed
+	switch {
+	case accountID != 0:
+		firstSelect = selectTrades(fields).Where("htrd.base_account_id = ?", accountID)
+		secondSelect = selectTrades(fields).Where("htrd.counter_account_id = ?", accountID)
+	case filter.OfferID != 0:
+		firstSelect = selectTrades(fields).Where("htrd.base_offer_id = ?", filter.OfferID)
+		secondSelect = selectTrades(fields).Where("htrd.counter_offer_id = ?", filter.OfferID)
+	case filter.AssetID != 0:
+		// Use reversed fields for trades where the asset is a counter asset
+		// so it's always returned as a base asset.
+		firstSelect = selectTrades(selectTradeFields).Where("htrd.base_asset_id = ?", filter.AssetID)
+		secondSelect = selectTrades(selectReverseTradeFields).Where("htrd.counter_asset_id = ?", filter.AssetID)
+		firstReversed, secondReversed = false, true
+	}
+
+	if firstSelect, err = appendFilters(firstSelect, firstReversed); err != nil {
+		return nil, err
+	}
+	if secondSelect, err = appendFilters(secondSelect, secondReversed); err != nil {
+		return nil, err
+	}
+
+	firstSQL, firstArgs, err := firstSelect.ToSql()
+	if err != nil {
+		return nil, errors.New("error building a firstSelect query")
+	}
+	secondSQL, secondArgs, err := secondSelect.ToSql()
+	if err != nil {
+		return nil, errors.New("error building a secondSelect query")
+	}
+
+	// Order the final UNION:
+	orderBy, err := unionPager.OrderBy(page.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	rawSQL := fmt.Sprintf("(%s) UNION (%s) ORDER BY %s LIMIT %d", firstSQL, secondSQL, orderBy, page.Limit)
+	return sq.Expr(rawSQL, append(firstArgs, secondArgs...)...), nil
+}
+
+// appendTradePriceRange adds the price range condition to sel. Both sides of
+// the comparison are multiplied by denominators so the condition can be
+// evaluated using integer arithmetic.
+func appendTradePriceRange(sel sq.SelectBuilder, r *TradePriceRange, reversed bool) sq.SelectBuilder {
+	if r == nil {
+		return sel
+	}
+
+	n, d := "htrd.price_n", "htrd.price_d"
+	if reversed {
+		n, d = d, n
+	}
+	return sel.Where(
+		fmt.Sprintf("%s * ? >= ? * %s AND %s * ? <= ? * %s", n, d, n, d),
+		r.MinD, r.MinN, r.MaxD, r.MaxN,
+	)
+}
+
+// appendTradeTimeRange adds the time range conditions of the filter to sel.
+func appendTradeTimeRange(sel sq.SelectBuilder, filter TradesFilter) sq.SelectBuilder {
+	if !filter.StartTime.IsNil() {
+		sel = sel.Where("htrd.ledger_closed_at >= ?", filter.StartTime.ToTime())
+	}
+	if !filter.EndTime.IsNil() {
+		sel = sel.Where("htrd.ledger_closed_at < ?", filter.EndTime.ToTime())
+	}
+	return sel
+}
+
+// tradeCursors parses the cursor and the stop cursor of page into keyset
+// values of the pager of the query. stopCursor is nil if page has no stop
+// cursor.
+func tradeCursors(bySequence bool, page db2.PageQuery) (cursor, stopCursor []int64, err error) {
+	if bySequence {
+		sequence, err := page.CursorInt64()
+		if err != nil {
+			return nil, nil, err
+		}
+		cursor = []int64{sequence}
+		if page.StopCursor != "" {
+			if sequence, err = page.StopCursorInt64(); err != nil {
+				return nil, nil, err
+			}
+			stopCursor = []int64{sequence}
+		}
+		return cursor, stopCursor, nil
+	}
+
+	op, idx, err := page.CursorInt64Pair(db2.DefaultPairSep)
+	if err != nil {
+		return nil, nil, err
+	}
+	// constrain the second portion of the cursor pair to 32-bits
+	if idx > math.MaxInt32 {
+		idx = math.MaxInt32
+	}
+	cursor = []int64{op, idx}
+
+	if page.StopCursor != "" {
+		stopOp, stopIdx, err := page.StopCursorInt64Pair(db2.DefaultPairSep)
+		if err != nil {
+			return nil, nil, err
+		}
+		if stopIdx > math.MaxInt32 {
+			stopIdx = math.MaxInt32
+		}
+		stopCursor = []int64{stopOp, stopIdx}
+	}
+	return cursor, stopCursor, nil
+}
+
+// tradesPager pages through trades, it's using the multicolumn index on
+// history_operation_id and order.
+var tradesPager = db2.NewKeysetPager("htrd.history_operation_id", "htrd.order")
+
+// tradesUnionPager sorts the UNION of the queries of trades of an account,
+// offer or asset.
+var tradesUnionPager = db2.NewKeysetPager("history_operation_id", `"order"`)
+
+// tradesSequencePager and tradesSequenceUnionPager page through trades by
+// their sequence.
+var (
+	tradesSequencePager      = db2.NewKeysetPager("htrd.sequence")
+	tradesSequenceUnionPager = db2.NewKeysetPager("sequence")
+)
+
 // Trades provides a helper to filter rows from the `history_trades` table
 // with pre-defined filters.  See `TradesQ` methods for the available filters.
+//
+// Deprecated: TradesQ is a wrapper of GetTrades kept during the migration to
+// the context aware API, use GetTrades instead.
 func (q *Q) Trades() *TradesQ {
 	return &TradesQ{
 		parent: q,
-		sql: joinTradeAssets(
-			joinTradeAccounts(
-				selectTradeFields.From("history_trades htrd"),
-				"history_accounts",
-			),
-			"history_assets",
-		),
 	}
 }
 
 // ReverseTrades provides a helper to filter rows from the `history_trades` table
 // with pre-defined filters and reversed base/counter.  See `TradesQ` methods for the available filters.
+//
+// Deprecated: TradesQ is a wrapper of GetTrades kept during the migration to
+// the context aware API, use GetTrades instead.
 func (q *Q) ReverseTrades() *TradesQ {
 	return &TradesQ{
-		parent: q,
-		sql: joinTradeAssets(
-			joinTradeAccounts(
-				selectReverseTradeFields.From("history_trades htrd"),
-				"history_accounts",
-			),
-			"history_assets",
-		),
+		parent:   q,
+		reversed: true,
 	}
 }
 
 // TradesForAssetPair provides a helper to filter rows from the `history_trades` table
 // with the base filter of a specific asset pair.  See `TradesQ` methods for further available filters.
+//
+// Deprecated: TradesQ is a wrapper of GetTrades kept during the migration to
+// the context aware API, use GetTrades with BaseAssetID and CounterAssetID
+// instead.
 func (q *Q) TradesForAssetPair(baseAssetId int64, counterAssetId int64) *TradesQ {
-	orderPreserved, baseAssetId, counterAssetId := getCanonicalAssetOrder(baseAssetId, counterAssetId)
-	var trades *TradesQ
-	if orderPreserved {
-		trades = q.Trades()
-	} else {
-		trades = q.ReverseTrades()
+	return &TradesQ{
+		parent: q,
+		filter: TradesFilter{BaseAssetID: baseAssetId, CounterAssetID: counterAssetId},
 	}
-	return trades.forAssetPair(baseAssetId, counterAssetId)
+}
+
+// BySequence pages the query results by the sequence of trades instead of
+// their (history_operation_id, order) pair: the cursors of the page query
+// passed to Page are trade sequences.
+func (q *TradesQ) BySequence() *TradesQ {
+	q.filter.BySequence = true
+	return q
 }
 
 // ForOffer filters the query results by the offer id.
 func (q *TradesQ) ForOffer(id int64) *TradesQ {
-	q.forOfferID = id
+	q.filter.OfferID = id
+	return q
+}
+
+// ForAsset filters the query results to trades involving the given asset on
+// either side. The requested asset is always rendered as the base asset of
+// returned trades. It can't be combined with ForAccount or ForOffer.
+func (q *TradesQ) ForAsset(assetID int64) *TradesQ {
+	q.filter.AssetID = assetID
+	return q
+}
+
+// ForPriceRange filters the query results to trades executed at a price
+// between minN/minD and maxN/maxD (inclusive). See TradePriceRange.
+func (q *TradesQ) ForPriceRange(minN, minD, maxN, maxD int32) *TradesQ {
+	q.filter.PriceRange = &TradePriceRange{MinN: minN, MinD: minD, MaxN: maxN, MaxD: maxD}
 	return q
 }
 
-//Filter by asset pair. This function is private to ensure that correct order and proper select statement are coupled
-func (q *TradesQ) forAssetPair(baseAssetId int64, counterAssetId int64) *TradesQ {
-	q.sql = q.sql.Where(sq.Eq{"base_asset_id": baseAssetId, "counter_asset_id": counterAssetId})
+// ForTimeRange filters the query results to trades executed in ledgers
+// closed within [start, end). A nil time leaves its bound open.
+func (q *TradesQ) ForTimeRange(start, end strtime.Millis) *TradesQ {
+	q.filter.StartTime = start
+	q.filter.EndTime = end
 	return q
 }
 
 // ForAccount filter Trades by account id
 func (q *TradesQ) ForAccount(aid string) *TradesQ {
-	var account Account
-	q.Err = q.parent.AccountByAddress(&account, aid)
-	if q.Err != nil {
-		return q
-	}
-
-	q.forAccountID = account.ID
+	q.filter.AccountID = aid
 	return q
 }
 
 // Page specifies the paging constraints for the query being built by `q`.
 func (q *TradesQ) Page(page db2.PageQuery) *TradesQ {
-	if q.Err != nil {
-		return q
-	}
-
-	op, idx, err := page.CursorInt64Pair(db2.DefaultPairSep)
-	if err != nil {
-		q.Err = err
-		return q
-	}
-
-	// constrain the second portion of the cursor pair to 32-bits
-	if idx > math.MaxInt32 {
-		idx = math.MaxInt32
-	}
-
-	q.pageCalled = true
-
-	if q.forAccountID != 0 || q.forOfferID != 0 {
-		// Construct UNION query
-		var firstSelect, secondSelect sq.SelectBuilder
-		switch {
-		case q.forAccountID != 0:
-			firstSelect = q.sql.Where("htrd.base_account_id = ?", q.forAccountID)
-			secondSelect = q.sql.Where("htrd.counter_account_id = ?", q.forAccountID)
-		case q.forOfferID != 0:
-			firstSelect = q.sql.Where("htrd.base_offer_id = ?", q.forOfferID)
-			secondSelect = q.sql.Where("htrd.counter_offer_id = ?", q.forOfferID)
-		}
-
-		firstSelect = q.appendOrdering(firstSelect, op, idx, page.Order)
-		secondSelect = q.appendOrdering(secondSelect, op, idx, page.Order)
-
-		firstSQL, firstArgs, err := firstSelect.ToSql()
-		if err != nil {
-			q.Err = errors.New("error building a firstSelect query")
-			return q
-		}
-		secondSQL, secondArgs, err := secondSelect.ToSql()
-		if err != nil {
-			q.Err = errors.New("error building a secondSelect query")
-			return q
-		}
-
-		q.rawSQL = fmt.Sprintf("(%s) UNION (%s) ", firstSQL, secondSQL)
-		q.rawArgs = append(q.rawArgs, firstArgs...)
-		q.rawArgs = append(q.rawArgs, secondArgs...)
-		// Order the final UNION:
-		switch page.Order {
-		case "asc":
-			q.rawSQL = q.rawSQL + `ORDER BY history_operation_id asc, "order" asc `
-		case "desc":
-			q.rawSQL = q.rawSQL + `ORDER BY history_operation_id desc, "order" desc `
-		default:
-			panic("Invalid order")
-		}
-		q.rawSQL = q.rawSQL + fmt.Sprintf("LIMIT %d", page.Limit)
-		// Reset sql so it's not used accidentally
-		q.sql = sq.SelectBuilder{}
-	} else {
-		q.sql = q.appendOrdering(q.sql, op, idx, page.Order)
-		q.sql = q.sql.Limit(page.Limit)
-	}
+	q.page = &page
 	return q
 }
 
-func (q *TradesQ) appendOrdering(sel sq.SelectBuilder, op, idx int64, order string) sq.SelectBuilder {
-	// NOTE: Remember to test the queries below with EXPLAIN / EXPLAIN ANALYZE
-	// before changing them.
-	// This condition is using multicolumn index and it's easy to write it in a way that
-	// DB will perform a full table scan.
-	switch order {
-	case "asc":
-		return sel.
-			Where(`(
-				htrd.history_operation_id >= ?
-			AND (
-				htrd.history_operation_id > ? OR
-				(htrd.history_operation_id = ? AND htrd.order > ?)
-			))`, op, op, op, idx).
-			OrderBy("htrd.history_operation_id asc, htrd.order asc")
-	case "desc":
-		return sel.
-			Where(`(
-				htrd.history_operation_id <= ?
-			AND (
-				htrd.history_operation_id < ? OR
-				(htrd.history_operation_id = ? AND htrd.order < ?)
-			))`, op, op, op, idx).
-			OrderBy("htrd.history_operation_id desc, htrd.order desc")
-	default:
-		panic("Invalid order")
-	}
-}
-
-// Select loads the results of the query specified by `q` into `dest`.
-func (q *TradesQ) Select(dest interface{}) error {
+// Select loads the results of the query specified by `q` into `dest`. The
+// query is cancelled when ctx is done.
+func (q *TradesQ) Select(ctx context.Context, dest interface{}) error {
 	if q.Err != nil {
 		return q.Err
 	}
 
-	if !q.pageCalled {
+	if q.page == nil {
 		return errors.New("TradesQ.Page call is required before calling Select")
 	}
 
-	if q.rawSQL != "" {
-		q.Err = q.parent.SelectRaw(dest, q.rawSQL, q.rawArgs...)
-	} else {
-		q.Err = q.parent.Select(dest, q.sql)
-	}
+	q.Err = q.parent.loadTrades(ctx, q.filter, q.reversed, *q.page, dest)
 	return q.Err
 }
 
+func selectTrades(fields sq.SelectBuilder) sq.SelectBuilder {
+	return joinTradeAssets(
+		joinTradeAccounts(
+			fields.From("history_trades htrd"),
+			"history_accounts",
+		),
+		"history_assets",
+	)
+}
+
 func joinTradeAccounts(selectBuilder sq.SelectBuilder, historyAccountsTable string) sq.SelectBuilder {
 	return selectBuilder.
 		Join(historyAccountsTable + " base_accounts ON base_account_id = base_accounts.id").
@@ -232,8 +450,10 @@ var selectTradeFields = sq.Select(
 	"counter_assets.asset_issuer as counter_asset_issuer",
 	"htrd.counter_amount",
 	"htrd.base_is_seller",
+	"htrd.base_is_maker",
 	"htrd.price_n",
 	"htrd.price_d",
+	"htrd.sequence",
 )
 
 var selectReverseTradeFields = sq.Select(
@@ -254,8 +474,10 @@ var selectReverseTradeFields = sq.Select(
 	"base_assets.asset_issuer as counter_asset_issuer",
 	"htrd.base_amount as counter_amount",
 	"NOT(htrd.base_is_seller) as base_is_seller",
+	"NOT(htrd.base_is_maker) as base_is_maker",
 	"htrd.price_d as price_n",
 	"htrd.price_n as price_d",
+	"htrd.sequence",
 )
 
 func getCanonicalAssetOrder(assetId1 int64, assetId2 int64) (orderPreserved bool, baseAssetId int64, counterAssetId int64) {
@@ -268,6 +490,6 @@ func getCanonicalAssetOrder(assetId1 int64, assetId2 int64) (orderPreserved bool
 
 type QTrades interface {
 	QCreateAccountsHistory
-	NewTradeBatchInsertBuilder(maxBatchSize int) TradeBatchInsertBuilder
+	NewTradeBatchInsertBuilder(maxBatchSize int, onConflict TradeConflictMode) TradeBatchInsertBuilder
 	CreateAssets(assets []xdr.Asset, maxBatchSize int) (map[string]Asset, error)
 }
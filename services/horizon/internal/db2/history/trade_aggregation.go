@@ -33,6 +33,8 @@ type TradeAggregation struct {
 	BaseVolume    string    `db:"base_volume"`
 	CounterVolume string    `db:"counter_volume"`
 	Average       float64   `db:"avg"`
+	VWAP          float64   `db:"vwap"`
+	AveragePrice  float64   `db:"avg_price"`
 	High          xdr.Price `db:"high"`
 	Low           xdr.Price `db:"low"`
 	Open          xdr.Price `db:"open"`
@@ -119,8 +121,7 @@ func (q *TradeAggregationsQ) WithEndTime(endTime strtime.Millis) (*TradeAggregat
 
 // GetSql generates a sql statement to aggregate Trades based on given parameters
 func (q *TradeAggregationsQ) GetSql() sq.SelectBuilder {
-	var orderPreserved bool
-	orderPreserved, q.baseAssetID, q.counterAssetID = getCanonicalAssetOrder(q.baseAssetID, q.counterAssetID)
+	orderPreserved, baseAssetID, counterAssetID := getCanonicalAssetOrder(q.baseAssetID, q.counterAssetID)
 
 	var bucketSQL sq.SelectBuilder
 	if orderPreserved {
@@ -130,7 +131,7 @@ func (q *TradeAggregationsQ) GetSql() sq.SelectBuilder {
 	}
 
 	bucketSQL = bucketSQL.From("history_trades").
-		Where(sq.Eq{"base_asset_id": q.baseAssetID, "counter_asset_id": q.counterAssetID})
+		Where(sq.Eq{"base_asset_id": baseAssetID, "counter_asset_id": counterAssetID})
 
 	//adjust time range and apply time filters
 	bucketSQL = bucketSQL.Where(sq.GtOrEq{"ledger_closed_at": q.startTime.ToTime()})
@@ -147,6 +148,8 @@ func (q *TradeAggregationsQ) GetSql() sq.SelectBuilder {
 		"sum(base_amount) as base_volume",
 		"sum(counter_amount) as counter_volume",
 		"sum(counter_amount)/sum(base_amount) as avg",
+		"sum(price[1]::numeric/price[2]*base_amount)/sum(base_amount) as vwap",
+		"avg(price[1]::numeric/price[2]) as avg_price",
 		"max_price(price) as high",
 		"min_price(price) as low",
 		"first(price)  as open",
@@ -158,6 +161,127 @@ func (q *TradeAggregationsQ) GetSql() sq.SelectBuilder {
 		OrderBy("timestamp " + q.pagingParams.Order)
 }
 
+// PageStart returns the timestamp of the first bucket of the page of the
+// query: the start time of an ascending page or the last bucket before the
+// end time of a descending page. It returns false if the page is unbounded on
+// that side.
+func (q *TradeAggregationsQ) PageStart() (int64, bool) {
+	if q.pagingParams.Order == db2.OrderDescending {
+		if q.endTime.IsNil() {
+			return 0, false
+		}
+		return q.endTime.ToInt64() - q.resolution, true
+	}
+	if q.startTime.IsNil() {
+		return 0, false
+	}
+	return q.startTime.ToInt64(), true
+}
+
+// GetPreviousCloseSql generates a sql statement selecting the price of the
+// last trade before the start time of the query, which is the close price of
+// the last bucket before an ascending page.
+func (q *TradeAggregationsQ) GetPreviousCloseSql() sq.SelectBuilder {
+	orderPreserved, baseAssetID, counterAssetID := getCanonicalAssetOrder(q.baseAssetID, q.counterAssetID)
+	price := "ARRAY[price_n, price_d] as price"
+	if !orderPreserved {
+		price = "ARRAY[price_d, price_n] as price"
+	}
+
+	return sq.Select(price).
+		From("history_trades").
+		Where(sq.Eq{"base_asset_id": baseAssetID, "counter_asset_id": counterAssetID}).
+		Where(sq.Lt{"ledger_closed_at": q.startTime.ToTime()}).
+		OrderBy("ledger_closed_at DESC", "history_operation_id DESC", "\"order\" DESC").
+		Limit(1)
+}
+
+// TradeAggregationGaps configures the empty buckets inserted by
+// FillTradeAggregationGaps.
+type TradeAggregationGaps struct {
+	Resolution int64
+	Order      string
+	Limit      uint64
+	// Start is the timestamp of the first bucket of the page, see
+	// TradeAggregationsQ.PageStart. The buckets between Start and the first
+	// record are filled, so that consecutive pages have no gaps at their
+	// boundary. It's nil if the page is unbounded.
+	Start *int64
+	// PreviousClose is the close price of the last bucket before an
+	// ascending page. The buckets before the first record of the page are
+	// only filled when it's set, there is no price to carry forward
+	// otherwise.
+	PreviousClose *xdr.Price
+}
+
+// FillTradeAggregationGaps returns records with empty buckets inserted
+// between buckets with trades, and between the start of the page and the
+// first record. Empty buckets carry forward the close price of the previous
+// bucket and have zero volume. Records must be ordered by timestamp in the
+// given order and the result is truncated to the limit.
+func FillTradeAggregationGaps(records []TradeAggregation, gaps TradeAggregationGaps) []TradeAggregation {
+	resolution, limit := gaps.Resolution, gaps.Limit
+	if len(records) == 0 || resolution <= 0 {
+		return records
+	}
+
+	filled := make([]TradeAggregation, 0, len(records))
+	if gaps.Start != nil {
+		first := records[0]
+		if gaps.Order == db2.OrderDescending {
+			// the buckets after the first record carry forward its close
+			// price
+			for ts := *gaps.Start; ts > first.Timestamp && uint64(len(filled)) < limit; ts -= resolution {
+				filled = append(filled, emptyTradeAggregation(ts, first.Close))
+			}
+		} else if gaps.PreviousClose != nil {
+			for ts := *gaps.Start; ts < first.Timestamp && uint64(len(filled)) < limit; ts += resolution {
+				filled = append(filled, emptyTradeAggregation(ts, *gaps.PreviousClose))
+			}
+		}
+	}
+	if uint64(len(filled)) < limit {
+		filled = append(filled, records[0])
+	}
+	for i := 1; i < len(records) && uint64(len(filled)) < limit; i++ {
+		prev, next := records[i-1], records[i]
+
+		if gaps.Order == db2.OrderDescending {
+			// Price is carried forward in time so in desc order empty buckets
+			// use close price of the next (older) record.
+			for ts := prev.Timestamp - resolution; ts > next.Timestamp && uint64(len(filled)) < limit; ts -= resolution {
+				filled = append(filled, emptyTradeAggregation(ts, next.Close))
+			}
+		} else {
+			for ts := prev.Timestamp + resolution; ts < next.Timestamp && uint64(len(filled)) < limit; ts += resolution {
+				filled = append(filled, emptyTradeAggregation(ts, prev.Close))
+			}
+		}
+
+		if uint64(len(filled)) < limit {
+			filled = append(filled, next)
+		}
+	}
+
+	return filled
+}
+
+func emptyTradeAggregation(timestamp int64, price xdr.Price) TradeAggregation {
+	return TradeAggregation{
+		Timestamp:     timestamp,
+		TradeCount:    0,
+		BaseVolume:    "0",
+		CounterVolume: "0",
+		Average:       float64(price.N) / float64(price.D),
+		VWAP:          float64(price.N) / float64(price.D),
+		AveragePrice:  float64(price.N) / float64(price.D),
+		High:          price,
+		Low:           price,
+		Open:          price,
+		Close:         price,
+	}
+}
+
 // formatBucketTimestampSelect formats a sql select clause for a bucketed timestamp, based on given resolution
 // and the offset. Given a time t, it gives it a timestamp defined by
 // f(t) = ((t - offset)/resolution)*resolution + offset.
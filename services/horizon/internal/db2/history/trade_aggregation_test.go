@@ -0,0 +1,153 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	strtime "github.com/stellar/go/support/time"
+	"github.com/stellar/go/xdr"
+)
+
+func TestFillTradeAggregationGaps(t *testing.T) {
+	first := TradeAggregation{
+		Timestamp:     1000,
+		TradeCount:    2,
+		BaseVolume:    "100",
+		CounterVolume: "200",
+		Average:       2,
+		High:          xdr.Price{N: 3, D: 1},
+		Low:           xdr.Price{N: 1, D: 1},
+		Open:          xdr.Price{N: 1, D: 1},
+		Close:         xdr.Price{N: 3, D: 1},
+	}
+	second := TradeAggregation{
+		Timestamp:     4000,
+		TradeCount:    1,
+		BaseVolume:    "10",
+		CounterVolume: "40",
+		Average:       4,
+		High:          xdr.Price{N: 4, D: 1},
+		Low:           xdr.Price{N: 4, D: 1},
+		Open:          xdr.Price{N: 4, D: 1},
+		Close:         xdr.Price{N: 4, D: 1},
+	}
+	third := second
+	third.Timestamp = 5000
+
+	asc := TradeAggregationGaps{Resolution: 1000, Order: db2.OrderAscending, Limit: 200}
+	desc := TradeAggregationGaps{Resolution: 1000, Order: db2.OrderDescending, Limit: 200}
+
+	filled := FillTradeAggregationGaps([]TradeAggregation{first, second, third}, asc)
+	assert.Equal(t, []TradeAggregation{
+		first,
+		emptyTradeAggregation(2000, first.Close),
+		emptyTradeAggregation(3000, first.Close),
+		second,
+		third,
+	}, filled)
+	assert.Equal(t, float64(3), filled[1].Average)
+	assert.Equal(t, "0", filled[1].BaseVolume)
+	assert.Equal(t, int64(0), filled[1].TradeCount)
+
+	filled = FillTradeAggregationGaps([]TradeAggregation{third, second, first}, desc)
+	assert.Equal(t, []TradeAggregation{
+		third,
+		second,
+		emptyTradeAggregation(3000, first.Close),
+		emptyTradeAggregation(2000, first.Close),
+		first,
+	}, filled)
+
+	// result is truncated to the page limit
+	truncated := asc
+	truncated.Limit = 2
+	filled = FillTradeAggregationGaps([]TradeAggregation{first, second, third}, truncated)
+	assert.Equal(t, []TradeAggregation{
+		first,
+		emptyTradeAggregation(2000, first.Close),
+	}, filled)
+
+	// nothing to fill
+	filled = FillTradeAggregationGaps([]TradeAggregation{first}, asc)
+	assert.Equal(t, []TradeAggregation{first}, filled)
+	filled = FillTradeAggregationGaps(nil, asc)
+	assert.Empty(t, filled)
+}
+
+func TestFillTradeAggregationLeadingGaps(t *testing.T) {
+	record := TradeAggregation{
+		Timestamp:  3000,
+		TradeCount: 1,
+		BaseVolume: "10",
+		Close:      xdr.Price{N: 4, D: 1},
+	}
+	start := int64(1000)
+	previousClose := xdr.Price{N: 2, D: 1}
+
+	// an ascending page carries forward the close of the previous page
+	filled := FillTradeAggregationGaps([]TradeAggregation{record}, TradeAggregationGaps{
+		Resolution:    1000,
+		Order:         db2.OrderAscending,
+		Limit:         200,
+		Start:         &start,
+		PreviousClose: &previousClose,
+	})
+	assert.Equal(t, []TradeAggregation{
+		emptyTradeAggregation(1000, previousClose),
+		emptyTradeAggregation(2000, previousClose),
+		record,
+	}, filled)
+
+	// there is no price to carry forward before the first trade of the pair
+	filled = FillTradeAggregationGaps([]TradeAggregation{record}, TradeAggregationGaps{
+		Resolution: 1000,
+		Order:      db2.OrderAscending,
+		Limit:      200,
+		Start:      &start,
+	})
+	assert.Equal(t, []TradeAggregation{record}, filled)
+
+	// a descending page starts with the buckets after its first record
+	start = 5000
+	filled = FillTradeAggregationGaps([]TradeAggregation{record}, TradeAggregationGaps{
+		Resolution: 1000,
+		Order:      db2.OrderDescending,
+		Limit:      2,
+		Start:      &start,
+	})
+	assert.Equal(t, []TradeAggregation{
+		emptyTradeAggregation(5000, record.Close),
+		emptyTradeAggregation(4000, record.Close),
+	}, filled)
+}
+
+func TestTradeAggregationsPageStart(t *testing.T) {
+	q, err := Q{}.GetTradeAggregationsQ(1, 2, 60000, 0, db2.PageQuery{Order: db2.OrderAscending, Limit: 10})
+	assert.NoError(t, err)
+	_, ok := q.PageStart()
+	assert.False(t, ok)
+
+	q, err = q.WithStartTime(strtime.MillisFromInt64(90000))
+	assert.NoError(t, err)
+	start, ok := q.PageStart()
+	assert.True(t, ok)
+	assert.Equal(t, int64(120000), start)
+
+	q, err = Q{}.GetTradeAggregationsQ(1, 2, 60000, 0, db2.PageQuery{Order: db2.OrderDescending, Limit: 10})
+	assert.NoError(t, err)
+	q, err = q.WithEndTime(strtime.MillisFromInt64(150000))
+	assert.NoError(t, err)
+	start, ok = q.PageStart()
+	assert.True(t, ok)
+	assert.Equal(t, int64(60000), start)
+
+	// generating the SQL of the query doesn't change its asset pair
+	q, err = Q{}.GetTradeAggregationsQ(2, 1, 60000, 0, db2.PageQuery{Order: db2.OrderAscending, Limit: 10})
+	assert.NoError(t, err)
+	q.GetSql()
+	q.GetPreviousCloseSql()
+	assert.Equal(t, int64(2), q.baseAssetID)
+	assert.Equal(t, int64(1), q.counterAssetID)
+}
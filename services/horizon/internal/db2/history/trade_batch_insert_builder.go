@@ -3,13 +3,19 @@ package history
 import (
 	"time"
 
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+	"github.com/lib/pq"
+
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
 
 // InsertTrade represents the arguments to TradeBatchInsertBuilder.Add() which is used to insert
-// rows into the history_trades table
+// rows into the history_trades table. Account and asset ids must be resolved
+// before adding the trade (see CreateAccounts and CreateAssets) so that no
+// lookups are done per trade.
 type InsertTrade struct {
 	HistoryOperationID int64
 	Order              int32
@@ -24,8 +30,41 @@ type InsertTrade struct {
 	SellPrice          xdr.Price
 }
 
+// TradeConflictMode is the behaviour of a TradeBatchInsertBuilder when a
+// trade was already inserted, with the same history_operation_id and order,
+// for example when the ingestion of a ledger is retried.
+type TradeConflictMode string
+
+const (
+	// TradeConflictFail fails the insert with a unique constraint violation.
+	// It's the behaviour of the zero value.
+	TradeConflictFail TradeConflictMode = "fail"
+	// TradeConflictSkip keeps the existing trades (ON CONFLICT DO NOTHING).
+	TradeConflictSkip TradeConflictMode = "skip"
+	// TradeConflictVerify keeps the existing trades identical to the inserted
+	// ones and fails when they differ.
+	TradeConflictVerify TradeConflictMode = "verify"
+	// TradeConflictUpdate replaces the existing trades with the inserted ones
+	// (ON CONFLICT DO UPDATE), so inserting the trades of a ledger again is
+	// idempotent.
+	TradeConflictUpdate TradeConflictMode = "update"
+)
+
+// ParseTradeConflictMode returns the mode called name, an empty name is
+// TradeConflictUpdate.
+func ParseTradeConflictMode(name string) (TradeConflictMode, error) {
+	switch mode := TradeConflictMode(name); mode {
+	case "":
+		return TradeConflictUpdate, nil
+	case TradeConflictFail, TradeConflictSkip, TradeConflictVerify, TradeConflictUpdate:
+		return mode, nil
+	default:
+		return "", errors.Errorf("unknown trade conflict mode: %s", name)
+	}
+}
+
 // TradeBatchInsertBuilder is used to insert trades into the
-// history_trades table
+// history_trades table using multi-row inserts
 type TradeBatchInsertBuilder interface {
 	Add(entries ...InsertTrade) error
 	Exec() error
@@ -34,23 +73,154 @@ type TradeBatchInsertBuilder interface {
 // tradeBatchInsertBuilder is a simple wrapper around db.BatchInsertBuilder
 type tradeBatchInsertBuilder struct {
 	builder db.BatchInsertBuilder
+	// verify holds the trades until they are checked against the existing
+	// trades in TradeConflictVerify mode.
+	verify       bool
+	pending      []tradeRow
+	maxBatchSize int
+}
+
+// tradeRow is a row of the history_trades table.
+type tradeRow struct {
+	HistoryOperationID int64     `db:"history_operation_id"`
+	Order              int32     `db:"order"`
+	LedgerClosedAt     time.Time `db:"ledger_closed_at"`
+	OfferID            int64     `db:"offer_id"`
+	BaseOfferID        null.Int  `db:"base_offer_id"`
+	BaseAccountID      int64     `db:"base_account_id"`
+	BaseAssetID        int64     `db:"base_asset_id"`
+	BaseAmount         int64     `db:"base_amount"`
+	CounterOfferID     null.Int  `db:"counter_offer_id"`
+	CounterAccountID   int64     `db:"counter_account_id"`
+	CounterAssetID     int64     `db:"counter_asset_id"`
+	CounterAmount      int64     `db:"counter_amount"`
+	BaseIsSeller       null.Bool `db:"base_is_seller"`
+	BaseIsMaker        null.Bool `db:"base_is_maker"`
+	PriceN             null.Int  `db:"price_n"`
+	PriceD             null.Int  `db:"price_d"`
+}
+
+// tradeRowColumns are the columns of tradeRow, starting with the
+// (history_operation_id, "order") key of the trades.
+var tradeRowColumns = []string{
+	"history_operation_id", `"order"`, "ledger_closed_at", "offer_id",
+	"base_offer_id", "base_account_id", "base_asset_id", "base_amount",
+	"counter_offer_id", "counter_account_id", "counter_asset_id", "counter_amount",
+	"base_is_seller", "base_is_maker", "price_n", "price_d",
+}
+
+func (r tradeRow) values() map[string]interface{} {
+	return map[string]interface{}{
+		"history_operation_id": r.HistoryOperationID,
+		"\"order\"":            r.Order,
+		"ledger_closed_at":     r.LedgerClosedAt,
+		"offer_id":             r.OfferID,
+		"base_offer_id":        r.BaseOfferID,
+		"base_account_id":      r.BaseAccountID,
+		"base_asset_id":        r.BaseAssetID,
+		"base_amount":          r.BaseAmount,
+		"counter_offer_id":     r.CounterOfferID,
+		"counter_account_id":   r.CounterAccountID,
+		"counter_asset_id":     r.CounterAssetID,
+		"counter_amount":       r.CounterAmount,
+		"base_is_seller":       r.BaseIsSeller,
+		"base_is_maker":        r.BaseIsMaker,
+		"price_n":              r.PriceN,
+		"price_d":              r.PriceD,
+	}
+}
+
+// equal returns true if both rows describe the same trade, the close times
+// are compared as instants.
+func (r tradeRow) equal(other tradeRow) bool {
+	if !r.LedgerClosedAt.Equal(other.LedgerClosedAt) {
+		return false
+	}
+	r.LedgerClosedAt = other.LedgerClosedAt
+	return r == other
 }
 
 // NewTradeBatchInsertBuilder constructs a new TradeBatchInsertBuilder instance
-func (q *Q) NewTradeBatchInsertBuilder(maxBatchSize int) TradeBatchInsertBuilder {
-	return &tradeBatchInsertBuilder{
+// handling the trades which were already inserted according to onConflict.
+func (q *Q) NewTradeBatchInsertBuilder(maxBatchSize int, onConflict TradeConflictMode) TradeBatchInsertBuilder {
+	builder := &tradeBatchInsertBuilder{
 		builder: db.BatchInsertBuilder{
 			Table:        q.GetTable("history_trades"),
 			MaxBatchSize: maxBatchSize,
 		},
+		maxBatchSize: maxBatchSize,
+	}
+	switch onConflict {
+	case TradeConflictSkip:
+		builder.builder.OnConflict = &db.ConflictStrategy{
+			Target: []string{"history_operation_id", `"order"`},
+		}
+	case TradeConflictVerify:
+		builder.verify = true
+	case TradeConflictUpdate:
+		builder.builder.OnConflict = &db.ConflictStrategy{
+			Target: []string{"history_operation_id", `"order"`},
+			Update: tradeRowColumns[2:],
+		}
 	}
+	return builder
 }
 
 // Exec flushes all outstanding trades to the database
 func (i *tradeBatchInsertBuilder) Exec() error {
+	if err := i.flushPending(); err != nil {
+		return err
+	}
 	return i.builder.Exec()
 }
 
+// flushPending adds the pending trades which don't exist yet to the batch,
+// it fails if an existing trade differs from the pending one.
+func (i *tradeBatchInsertBuilder) flushPending() error {
+	if len(i.pending) == 0 {
+		return nil
+	}
+	pending := i.pending
+	i.pending = nil
+
+	ids := make([]int64, 0, len(pending))
+	for _, row := range pending {
+		ids = append(ids, row.HistoryOperationID)
+	}
+	var existing []tradeRow
+	sql := sq.Select(tradeRowColumns...).
+		From("history_trades").
+		Where("history_operation_id = ANY(?)", pq.Array(ids))
+	if err := i.builder.Table.Session.Select(&existing, sql); err != nil {
+		return errors.Wrap(err, "could not load existing trades")
+	}
+
+	type tradeKey struct {
+		operationID int64
+		order       int32
+	}
+	existingByKey := make(map[tradeKey]tradeRow, len(existing))
+	for _, row := range existing {
+		existingByKey[tradeKey{row.HistoryOperationID, row.Order}] = row
+	}
+
+	for _, row := range pending {
+		if found, ok := existingByKey[tradeKey{row.HistoryOperationID, row.Order}]; ok {
+			if !found.equal(row) {
+				return errors.Errorf(
+					"trade %d-%d conflicts with an existing trade",
+					row.HistoryOperationID, row.Order,
+				)
+			}
+			continue
+		}
+		if err := i.builder.Row(row.values()); err != nil {
+			return errors.Wrap(err, "failed to add trade")
+		}
+	}
+	return nil
+}
+
 // Add adds a new trade to the batch
 func (i *tradeBatchInsertBuilder) Add(entries ...InsertTrade) error {
 	for _, entry := range entries {
@@ -90,24 +260,38 @@ func (i *tradeBatchInsertBuilder) Add(entries ...InsertTrade) error {
 			entry.SellPrice.Invert()
 		}
 
-		err := i.builder.Row(map[string]interface{}{
-			"history_operation_id": entry.HistoryOperationID,
-			"\"order\"":            entry.Order,
-			"ledger_closed_at":     entry.LedgerCloseTime,
-			"offer_id":             entry.Trade.OfferId,
-			"base_offer_id":        baseOfferID,
-			"base_account_id":      baseAccountID,
-			"base_asset_id":        baseAssetID,
-			"base_amount":          baseAmount,
-			"counter_offer_id":     counterOfferID,
-			"counter_account_id":   counterAccountID,
-			"counter_asset_id":     counterAssetID,
-			"counter_amount":       counterAmount,
-			"base_is_seller":       orderPreserved,
-			"price_n":              entry.SellPrice.N,
-			"price_d":              entry.SellPrice.D,
-		})
-		if err != nil {
+		// the maker is the owner of the claimed offer, which was resting in
+		// the order book, the taker crossed it
+		baseIsMaker := baseOfferID == sellOfferID
+
+		row := tradeRow{
+			HistoryOperationID: entry.HistoryOperationID,
+			Order:              entry.Order,
+			LedgerClosedAt:     entry.LedgerCloseTime,
+			OfferID:            int64(entry.Trade.OfferId),
+			BaseOfferID:        null.IntFrom(baseOfferID),
+			BaseAccountID:      baseAccountID,
+			BaseAssetID:        baseAssetID,
+			BaseAmount:         int64(baseAmount),
+			CounterOfferID:     null.IntFrom(counterOfferID),
+			CounterAccountID:   counterAccountID,
+			CounterAssetID:     counterAssetID,
+			CounterAmount:      int64(counterAmount),
+			BaseIsSeller:       null.BoolFrom(orderPreserved),
+			BaseIsMaker:        null.BoolFrom(baseIsMaker),
+			PriceN:             null.IntFrom(int64(entry.SellPrice.N)),
+			PriceD:             null.IntFrom(int64(entry.SellPrice.D)),
+		}
+		if i.verify {
+			i.pending = append(i.pending, row)
+			if len(i.pending) == i.maxBatchSize {
+				if err := i.flushPending(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := i.builder.Row(row.values()); err != nil {
 			return errors.Wrap(err, "failed to add trade")
 		}
 	}
@@ -0,0 +1,210 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/errors"
+)
+
+const (
+	// ExportFormatExecutionReports writes the trades of an account as
+	// newline delimited JSON execution reports.
+	ExportFormatExecutionReports ExportFormat = "execution_reports"
+)
+
+// ExecutionReport describes a fill of an order of an account using the
+// vocabulary of FIX execution reports (MsgType 35=8) so trades can be mapped
+// into FIX-based systems. The FIX tag of each field is given in brackets.
+type ExecutionReport struct {
+	// ExecID [17] is the paging token of the trade, suffixed with the side
+	// for self trades so it stays unique.
+	ExecID string `json:"exec_id"`
+	// OrderID [37] is the offer id of the account when OrderIDType is
+	// "offer", or the id of the operation which was immediately filled
+	// without creating an offer when OrderIDType is "operation".
+	OrderID     string `json:"order_id"`
+	OrderIDType string `json:"order_id_type"`
+	// Account [1] placed the order, Counterparty is the other account of
+	// the trade.
+	Account      string `json:"account"`
+	Counterparty string `json:"counterparty"`
+	// Symbol [55] is the traded pair, base asset first, assets are "native"
+	// or "CODE:ISSUER".
+	Symbol string `json:"symbol"`
+	// Side [54] is "buy" or "sell" of the base asset.
+	Side string `json:"side"`
+	// LastQty [32] is the amount of the base asset, LastPx [31] the price of
+	// the base asset in the counter asset and GrossTradeAmt [381] the amount
+	// of the counter asset.
+	LastQty       string `json:"last_qty"`
+	LastPx        string `json:"last_px"`
+	GrossTradeAmt string `json:"gross_trade_amt"`
+	// LastLiquidityInd [851] is "maker" when the order was resting in the
+	// order book (added liquidity) and "taker" when it crossed it (removed
+	// liquidity).
+	LastLiquidityInd string `json:"last_liquidity_ind"`
+	// TransactTime [60] is the close time of the ledger of the trade.
+	TransactTime string `json:"transact_time"`
+	Ledger       int32  `json:"ledger"`
+}
+
+// ExportExecutionReports streams the trades of account in the
+// [fromLedger, toLedger] range (closed interval) to w as execution reports,
+// one JSON object per line.
+func (q *Q) ExportExecutionReports(
+	ctx context.Context,
+	w io.Writer,
+	account string,
+	fromLedger, toLedger uint32,
+) error {
+	if fromLedger > toLedger {
+		return errors.New("from ledger must not be greater than to ledger")
+	}
+
+	var historyAccount Account
+	if err := q.AccountByAddress(&historyAccount, account); err != nil {
+		if q.NoRows(err) {
+			return errors.Errorf("unknown account: %s", account)
+		}
+		return errors.Wrap(err, "could not load account")
+	}
+
+	start := toid.ID{LedgerSequence: int32(fromLedger)}
+	end := toid.ID{LedgerSequence: int32(toLedger) + 1}
+	sql := selectTrades(selectTradeFields).
+		Where(
+			"htrd.history_operation_id >= ? AND htrd.history_operation_id < ?",
+			start.ToInt64(),
+			end.ToInt64(),
+		).
+		Where(
+			"(htrd.base_account_id = ? OR htrd.counter_account_id = ?)",
+			historyAccount.ID,
+			historyAccount.ID,
+		).
+		OrderBy("htrd.history_operation_id asc", "htrd.\"order\" asc")
+
+	session := q.Clone()
+	session.Ctx = ctx
+	rows, err := session.Query(sql)
+	if err != nil {
+		return errors.Wrap(err, "could not run export execution reports query")
+	}
+	defer rows.Close()
+
+	return exportExecutionReports(rows, w, account)
+}
+
+func exportExecutionReports(rows tradeRows, w io.Writer, account string) error {
+	encoder := json.NewEncoder(w)
+	for rows.Next() {
+		var trade Trade
+		if err := rows.StructScan(&trade); err != nil {
+			return errors.Wrap(err, "could not scan trade")
+		}
+
+		for _, report := range tradeExecutionReports(trade, account) {
+			if err := encoder.Encode(report); err != nil {
+				return errors.Wrap(err, "could not write execution report")
+			}
+		}
+	}
+
+	return errors.Wrap(rows.Err(), "could not read trades")
+}
+
+// tradeExecutionReports returns the execution reports of the sides of trade
+// of account, there are two reports when the account traded with itself.
+func tradeExecutionReports(trade Trade, account string) []ExecutionReport {
+	var reports []ExecutionReport
+	self := trade.BaseAccount == account && trade.CounterAccount == account
+	for _, base := range []bool{true, false} {
+		if (base && trade.BaseAccount != account) || (!base && trade.CounterAccount != account) {
+			continue
+		}
+
+		report := tradeExecutionReport(trade, base)
+		if self {
+			report.ExecID += "-" + report.Side
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// tradeExecutionReport returns the execution report of the base or counter
+// side of trade. The base account sells the base asset.
+func tradeExecutionReport(trade Trade, base bool) ExecutionReport {
+	report := ExecutionReport{
+		ExecID: trade.PagingToken(),
+		Symbol: assetSymbol(trade.BaseAssetType, trade.BaseAssetCode, trade.BaseAssetIssuer) + "/" +
+			assetSymbol(trade.CounterAssetType, trade.CounterAssetCode, trade.CounterAssetIssuer),
+		LastQty:       amount.String(trade.BaseAmount),
+		GrossTradeAmt: amount.String(trade.CounterAmount),
+		TransactTime:  trade.LedgerCloseTime.UTC().Format(time.RFC3339),
+		Ledger:        toid.Parse(trade.HistoryOperationID).LedgerSequence,
+	}
+
+	// the seller's offer was claimed from the order book, its id is OfferID
+	// when the offer id of the side was not recorded
+	var offerID *int64
+	seller := base == trade.BaseIsSeller
+	if base {
+		report.Side = "sell"
+		report.Account, report.Counterparty = trade.BaseAccount, trade.CounterAccount
+		offerID = trade.BaseOfferID
+	} else {
+		report.Side = "buy"
+		report.Account, report.Counterparty = trade.CounterAccount, trade.BaseAccount
+		offerID = trade.CounterOfferID
+	}
+	if offerID == nil && seller {
+		offerID = &trade.OfferID
+	}
+
+	idType := TOIDType
+	if offerID != nil {
+		var id uint64
+		id, idType = DecodeOfferID(*offerID)
+		report.OrderID = strconv.FormatUint(id, 10)
+	}
+	switch idType {
+	case CoreOfferIDType:
+		report.OrderIDType = "offer"
+	default:
+		report.OrderIDType = "operation"
+		if offerID == nil {
+			report.OrderID = strconv.FormatInt(trade.HistoryOperationID, 10)
+		}
+	}
+
+	if base == trade.BaseIsMaker {
+		report.LastLiquidityInd = "maker"
+	} else {
+		report.LastLiquidityInd = "taker"
+	}
+
+	// old trades have no price, it's derived from the amounts
+	switch {
+	case trade.HasPrice() && trade.PriceD.Int64 != 0:
+		report.LastPx = big.NewRat(trade.PriceN.Int64, trade.PriceD.Int64).FloatString(7)
+	case trade.BaseAmount != 0:
+		report.LastPx = big.NewRat(int64(trade.CounterAmount), int64(trade.BaseAmount)).FloatString(7)
+	}
+
+	return report
+}
+
+func assetSymbol(assetType, code, issuer string) string {
+	if assetType == "native" {
+		return "native"
+	}
+	return code + ":" + issuer
+}
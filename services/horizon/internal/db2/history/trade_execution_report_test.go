@@ -0,0 +1,91 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/services/horizon/internal/toid"
+)
+
+func TestExportExecutionReports(t *testing.T) {
+	maker := "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"
+	taker := "GBB4JST32UWKOLGYYSCEYBHBCOFL2TGBHDVOMZP462ET4ZRD4ULA7S2L"
+	opID := toid.New(10, 1, 1).ToInt64()
+	makerOfferID := int64(3)
+	takerOfferID := EncodeOfferId(uint64(opID), TOIDType)
+	trade := Trade{
+		HistoryOperationID: opID,
+		Order:              0,
+		LedgerCloseTime:    time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+		OfferID:            3,
+		BaseOfferID:        &makerOfferID,
+		BaseAccount:        maker,
+		BaseAssetType:      "native",
+		BaseAmount:         10000000,
+		CounterOfferID:     &takerOfferID,
+		CounterAccount:     taker,
+		CounterAssetType:   "credit_alphanum4",
+		CounterAssetCode:   "USD",
+		CounterAssetIssuer: taker,
+		CounterAmount:      25000000,
+		BaseIsSeller:       true,
+		BaseIsMaker:        true,
+		PriceN:             null.IntFrom(5),
+		PriceD:             null.IntFrom(2),
+	}
+
+	decode := func(account string) []ExecutionReport {
+		var out bytes.Buffer
+		require.NoError(t, exportExecutionReports(&sliceTradeRows{trades: []Trade{trade}}, &out, account))
+		var reports []ExecutionReport
+		decoder := json.NewDecoder(&out)
+		for decoder.More() {
+			var report ExecutionReport
+			require.NoError(t, decoder.Decode(&report))
+			reports = append(reports, report)
+		}
+		return reports
+	}
+
+	assert.Equal(t, []ExecutionReport{{
+		ExecID:           "42949677057-0",
+		OrderID:          "3",
+		OrderIDType:      "offer",
+		Account:          maker,
+		Counterparty:     taker,
+		Symbol:           "native/USD:" + taker,
+		Side:             "sell",
+		LastQty:          "1.0000000",
+		LastPx:           "2.5000000",
+		GrossTradeAmt:    "2.5000000",
+		LastLiquidityInd: "maker",
+		TransactTime:     "2020-06-01T12:00:00Z",
+		Ledger:           10,
+	}}, decode(maker))
+
+	reports := decode(taker)
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, "buy", reports[0].Side)
+		assert.Equal(t, "42949677057", reports[0].OrderID)
+		assert.Equal(t, "operation", reports[0].OrderIDType)
+		assert.Equal(t, "taker", reports[0].LastLiquidityInd)
+		assert.Equal(t, maker, reports[0].Counterparty)
+	}
+
+	// trades without price derive it from the amounts and self trades have
+	// a report for each side
+	trade.PriceN, trade.PriceD = null.Int{}, null.Int{}
+	trade.CounterAccount = maker
+	reports = decode(maker)
+	if assert.Len(t, reports, 2) {
+		assert.Equal(t, "42949677057-0-sell", reports[0].ExecID)
+		assert.Equal(t, "42949677057-0-buy", reports[1].ExecID)
+		assert.Equal(t, "2.5000000", reports[1].LastPx)
+	}
+}
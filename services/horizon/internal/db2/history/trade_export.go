@@ -0,0 +1,151 @@
+package history
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/errors"
+)
+
+// ExportFormat is the output format used by ExportTrades.
+//
+// Parquet is not supported: writing it requires an encoder which is not a
+// dependency of this module. Exports meant for columnar storage can be
+// converted from CSV.
+type ExportFormat string
+
+const (
+	// ExportFormatCSV writes trades as comma separated values with a header
+	// row.
+	ExportFormatCSV ExportFormat = "csv"
+)
+
+// tradeRows is the subset of *sqlx.Rows used when exporting trades.
+type tradeRows interface {
+	Next() bool
+	StructScan(dest interface{}) error
+	Err() error
+}
+
+var tradeExportHeader = []string{
+	"ledger",
+	"history_operation_id",
+	"order",
+	"ledger_closed_at",
+	"offer_id",
+	"base_offer_id",
+	"base_account",
+	"base_asset_type",
+	"base_asset_code",
+	"base_asset_issuer",
+	"base_amount",
+	"counter_offer_id",
+	"counter_account",
+	"counter_asset_type",
+	"counter_asset_code",
+	"counter_asset_issuer",
+	"counter_amount",
+	"base_is_seller",
+	"price_n",
+	"price_d",
+}
+
+// ExportTrades streams all trades in the [fromLedger, toLedger] range
+// (closed interval) to w in the given format. Rows are written as they are
+// read from the database so the whole range is never kept in memory.
+func (q *Q) ExportTrades(
+	ctx context.Context,
+	w io.Writer,
+	fromLedger, toLedger uint32,
+	format ExportFormat,
+) error {
+	if fromLedger > toLedger {
+		return errors.New("from ledger must not be greater than to ledger")
+	}
+	if format == "parquet" {
+		return errors.New("parquet export is not supported, use csv")
+	}
+	if format != ExportFormatCSV {
+		return errors.Errorf("unsupported export format: %s", format)
+	}
+
+	start := toid.ID{LedgerSequence: int32(fromLedger)}
+	end := toid.ID{LedgerSequence: int32(toLedger) + 1}
+	sql := selectTrades(selectTradeFields).
+		Where(
+			"htrd.history_operation_id >= ? AND htrd.history_operation_id < ?",
+			start.ToInt64(),
+			end.ToInt64(),
+		).
+		OrderBy("htrd.history_operation_id asc", "htrd.\"order\" asc")
+
+	session := q.Clone()
+	session.Ctx = ctx
+	rows, err := session.Query(sql)
+	if err != nil {
+		return errors.Wrap(err, "could not run export trades query")
+	}
+	defer rows.Close()
+
+	return exportTradesCSV(rows, w)
+}
+
+func exportTradesCSV(rows tradeRows, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(tradeExportHeader); err != nil {
+		return errors.Wrap(err, "could not write csv header")
+	}
+
+	for rows.Next() {
+		var trade Trade
+		if err := rows.StructScan(&trade); err != nil {
+			return errors.Wrap(err, "could not scan trade")
+		}
+		if err := writer.Write(tradeExportRecord(trade)); err != nil {
+			return errors.Wrap(err, "could not write csv row")
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "could not read trades")
+	}
+
+	writer.Flush()
+	return errors.Wrap(writer.Error(), "could not flush csv writer")
+}
+
+func tradeExportRecord(trade Trade) []string {
+	return []string{
+		strconv.FormatInt(int64(toid.Parse(trade.HistoryOperationID).LedgerSequence), 10),
+		strconv.FormatInt(trade.HistoryOperationID, 10),
+		strconv.FormatInt(int64(trade.Order), 10),
+		trade.LedgerCloseTime.UTC().Format(time.RFC3339),
+		strconv.FormatInt(trade.OfferID, 10),
+		optionalInt64String(trade.BaseOfferID),
+		trade.BaseAccount,
+		trade.BaseAssetType,
+		trade.BaseAssetCode,
+		trade.BaseAssetIssuer,
+		amount.String(trade.BaseAmount),
+		optionalInt64String(trade.CounterOfferID),
+		trade.CounterAccount,
+		trade.CounterAssetType,
+		trade.CounterAssetCode,
+		trade.CounterAssetIssuer,
+		amount.String(trade.CounterAmount),
+		strconv.FormatBool(trade.BaseIsSeller),
+		strconv.FormatInt(trade.PriceN.Int64, 10),
+		strconv.FormatInt(trade.PriceD.Int64, 10),
+	}
+}
+
+func optionalInt64String(value *int64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(*value, 10)
+}
@@ -0,0 +1,97 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/services/horizon/internal/toid"
+)
+
+type sliceTradeRows struct {
+	trades []Trade
+	next   int
+}
+
+func (r *sliceTradeRows) Next() bool {
+	r.next++
+	return r.next <= len(r.trades)
+}
+
+func (r *sliceTradeRows) StructScan(dest interface{}) error {
+	*dest.(*Trade) = r.trades[r.next-1]
+	return nil
+}
+
+func (r *sliceTradeRows) Err() error {
+	return nil
+}
+
+func TestExportTradesCSV(t *testing.T) {
+	baseOfferID := int64(3)
+	rows := &sliceTradeRows{trades: []Trade{
+		{
+			HistoryOperationID: toid.New(10, 1, 1).ToInt64(),
+			Order:              0,
+			LedgerCloseTime:    time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+			OfferID:            3,
+			BaseOfferID:        &baseOfferID,
+			BaseAccount:        "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB",
+			BaseAssetType:      "native",
+			BaseAmount:         10000000,
+			CounterAccount:     "GBB4JST32UWKOLGYYSCEYBHBCOFL2TGBHDVOMZP462ET4ZRD4ULA7S2L",
+			CounterAssetType:   "credit_alphanum4",
+			CounterAssetCode:   "USD",
+			CounterAssetIssuer: "GBB4JST32UWKOLGYYSCEYBHBCOFL2TGBHDVOMZP462ET4ZRD4ULA7S2L",
+			CounterAmount:      20000000,
+			BaseIsSeller:       true,
+			PriceN:             null.IntFrom(2),
+			PriceD:             null.IntFrom(1),
+		},
+	}}
+
+	var out bytes.Buffer
+	assert.NoError(t, exportTradesCSV(rows, &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, strings.Join(tradeExportHeader, ","), lines[0])
+		assert.Equal(
+			t,
+			"10,42949677057,0,2020-06-01T12:00:00Z,3,3,"+
+				"GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB,native,,,1.0000000,,"+
+				"GBB4JST32UWKOLGYYSCEYBHBCOFL2TGBHDVOMZP462ET4ZRD4ULA7S2L,credit_alphanum4,USD,"+
+				"GBB4JST32UWKOLGYYSCEYBHBCOFL2TGBHDVOMZP462ET4ZRD4ULA7S2L,2.0000000,true,2,1",
+			lines[1],
+		)
+	}
+}
+
+func TestExportTrades(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	var out bytes.Buffer
+	err := q.ExportTrades(context.Background(), &out, 1, 1000, ExportFormatCSV)
+	if tt.Assert.NoError(err) {
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		// header and all 4 kahuna trades
+		tt.Assert.Len(lines, 5)
+	}
+
+	err = q.ExportTrades(context.Background(), &out, 10, 1, ExportFormatCSV)
+	tt.Assert.EqualError(err, "from ledger must not be greater than to ledger")
+
+	err = q.ExportTrades(context.Background(), &out, 1, 10, ExportFormat("xml"))
+	tt.Assert.EqualError(err, "unsupported export format: xml")
+
+	err = q.ExportTrades(context.Background(), &out, 1, 10, ExportFormat("parquet"))
+	tt.Assert.EqualError(err, "parquet export is not supported, use csv")
+}
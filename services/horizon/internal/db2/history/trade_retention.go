@@ -0,0 +1,106 @@
+package history
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/support/errors"
+)
+
+// TradeRetentionPolicy overrides the history retention of history_trades rows
+// for a single asset pair. RetentionSeconds equal to 0 keeps the trades of
+// the pair forever.
+type TradeRetentionPolicy struct {
+	BaseAssetID        int64  `db:"base_asset_id"`
+	BaseAssetType      string `db:"base_asset_type"`
+	BaseAssetCode      string `db:"base_asset_code"`
+	BaseAssetIssuer    string `db:"base_asset_issuer"`
+	CounterAssetID     int64  `db:"counter_asset_id"`
+	CounterAssetType   string `db:"counter_asset_type"`
+	CounterAssetCode   string `db:"counter_asset_code"`
+	CounterAssetIssuer string `db:"counter_asset_issuer"`
+	RetentionSeconds   int64  `db:"retention_seconds"`
+}
+
+// TradeRetentionPolicies loads all the trade retention policies.
+func (q *Q) TradeRetentionPolicies() ([]TradeRetentionPolicy, error) {
+	sql := sq.Select(
+		"p.base_asset_id",
+		"base_assets.asset_type as base_asset_type",
+		"base_assets.asset_code as base_asset_code",
+		"base_assets.asset_issuer as base_asset_issuer",
+		"p.counter_asset_id",
+		"counter_assets.asset_type as counter_asset_type",
+		"counter_assets.asset_code as counter_asset_code",
+		"counter_assets.asset_issuer as counter_asset_issuer",
+		"p.retention_seconds",
+	).
+		From("trade_retention_policies p").
+		Join("history_assets base_assets ON p.base_asset_id = base_assets.id").
+		Join("history_assets counter_assets ON p.counter_asset_id = counter_assets.id").
+		OrderBy("p.base_asset_id asc, p.counter_asset_id asc")
+
+	var policies []TradeRetentionPolicy
+	err := q.Select(&policies, sql)
+	return policies, err
+}
+
+// UpsertTradeRetentionPolicy creates or updates the retention policy of the
+// given asset pair. The pair is stored in the canonical order used by
+// history_trades so the order of the arguments does not matter.
+func (q *Q) UpsertTradeRetentionPolicy(baseAssetID, counterAssetID int64, retention time.Duration) error {
+	if baseAssetID == counterAssetID {
+		return errors.New("base and counter assets must be different")
+	}
+	if retention < 0 {
+		return errors.New("retention must not be negative")
+	}
+
+	_, baseAssetID, counterAssetID = getCanonicalAssetOrder(baseAssetID, counterAssetID)
+	_, err := q.ExecRaw(`
+		INSERT INTO trade_retention_policies (base_asset_id, counter_asset_id, retention_seconds)
+		VALUES (?, ?, ?)
+		ON CONFLICT (base_asset_id, counter_asset_id)
+		DO UPDATE SET retention_seconds = EXCLUDED.retention_seconds`,
+		baseAssetID, counterAssetID, int64(retention/time.Second),
+	)
+	return err
+}
+
+// RemoveTradeRetentionPolicy removes the retention policy of the given asset
+// pair so its trades follow the global history retention again. Returns the
+// number of rows affected.
+func (q *Q) RemoveTradeRetentionPolicy(baseAssetID, counterAssetID int64) (int64, error) {
+	_, baseAssetID, counterAssetID = getCanonicalAssetOrder(baseAssetID, counterAssetID)
+	sql := sq.Delete("trade_retention_policies").Where(sq.Eq{
+		"base_asset_id":    baseAssetID,
+		"counter_asset_id": counterAssetID,
+	})
+
+	result, err := q.Exec(sql)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteExpiredRetainedTrades removes trades of asset pairs with a positive
+// retention policy which closed more than the policy retention before now.
+// Returns the number of rows deleted.
+func (q *Q) DeleteExpiredRetainedTrades(now time.Time) (int64, error) {
+	result, err := q.ExecRaw(`
+		DELETE FROM history_trades htrd
+		USING trade_retention_policies p
+		WHERE p.retention_seconds > 0
+		AND htrd.base_asset_id = p.base_asset_id
+		AND htrd.counter_asset_id = p.counter_asset_id
+		AND htrd.ledger_closed_at < ?::timestamp - p.retention_seconds * interval '1 second'`,
+		now.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
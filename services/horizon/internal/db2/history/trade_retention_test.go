@@ -0,0 +1,73 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/xdr"
+)
+
+func TestTradeRetentionPolicies(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	lumen, err := q.GetAssetID(xdr.MustNewNativeAsset())
+	tt.Require.NoError(err)
+	assetUSD, err := q.GetAssetID(xdr.MustNewCreditAsset("USD", "GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU"))
+	tt.Require.NoError(err)
+
+	tt.Assert.EqualError(
+		q.UpsertTradeRetentionPolicy(lumen, lumen, 0),
+		"base and counter assets must be different",
+	)
+	tt.Assert.EqualError(
+		q.UpsertTradeRetentionPolicy(lumen, assetUSD, -time.Second),
+		"retention must not be negative",
+	)
+
+	// arguments are stored in canonical order
+	tt.Require.NoError(q.UpsertTradeRetentionPolicy(assetUSD, lumen, time.Hour))
+	tt.Require.NoError(q.UpsertTradeRetentionPolicy(lumen, assetUSD, 0))
+
+	policies, err := q.TradeRetentionPolicies()
+	tt.Require.NoError(err)
+	if tt.Assert.Len(policies, 1) {
+		tt.Assert.Equal(int64(0), policies[0].RetentionSeconds)
+		tt.Assert.Equal("native", policies[0].BaseAssetType)
+		tt.Assert.Equal("USD", policies[0].CounterAssetCode)
+	}
+
+	var before []Trade
+	err = q.TradesForAssetPair(lumen, assetUSD).Page(db2.MustPageQuery("", false, "asc", 100)).Select(tt.Ctx, &before)
+	tt.Require.NoError(err)
+	tt.Require.NotEmpty(before)
+
+	// retained trades survive history reaping
+	tt.Require.NoError(q.DeleteUnretainedRangeAll(0, before[len(before)-1].HistoryOperationID+1))
+
+	var after []Trade
+	err = q.TradesForAssetPair(lumen, assetUSD).Page(db2.MustPageQuery("", false, "asc", 100)).Select(tt.Ctx, &after)
+	tt.Require.NoError(err)
+	tt.Assert.Len(after, len(before))
+
+	// a retention of 0 keeps trades forever
+	deleted, err := q.DeleteExpiredRetainedTrades(time.Now())
+	tt.Require.NoError(err)
+	tt.Assert.Equal(int64(0), deleted)
+
+	tt.Require.NoError(q.UpsertTradeRetentionPolicy(lumen, assetUSD, time.Hour))
+	deleted, err = q.DeleteExpiredRetainedTrades(time.Now())
+	tt.Require.NoError(err)
+	tt.Assert.Equal(int64(len(before)), deleted)
+
+	removed, err := q.RemoveTradeRetentionPolicy(assetUSD, lumen)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(int64(1), removed)
+
+	policies, err = q.TradeRetentionPolicies()
+	tt.Require.NoError(err)
+	tt.Assert.Len(policies, 0)
+}
@@ -1,6 +1,7 @@
 package history
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/stellar/go/services/horizon/internal/toid"
 	supportTime "github.com/stellar/go/support/time"
 	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestTradeQueries(t *testing.T) {
@@ -19,7 +21,7 @@ func TestTradeQueries(t *testing.T) {
 	var trades []Trade
 
 	// All trades
-	err := q.Trades().Page(db2.MustPageQuery("", false, "asc", 100)).Select(&trades)
+	err := q.Trades().Page(db2.MustPageQuery("", false, "asc", 100)).Select(tt.Ctx, &trades)
 	if tt.Assert.NoError(err) {
 		tt.Assert.Len(trades, 4)
 	}
@@ -28,7 +30,7 @@ func TestTradeQueries(t *testing.T) {
 	pq := db2.MustPageQuery(trades[0].PagingToken(), false, "asc", 1)
 	var pt []Trade
 
-	err = q.Trades().Page(pq).Select(&pt)
+	err = q.Trades().Page(pq).Select(tt.Ctx, &pt)
 	if tt.Assert.NoError(err) {
 		if tt.Assert.Len(pt, 1) {
 			tt.Assert.Equal(trades[1], pt[0])
@@ -37,7 +39,7 @@ func TestTradeQueries(t *testing.T) {
 
 	// Cursor bounds checking
 	pq = db2.MustPageQuery("", false, "desc", 1)
-	err = q.Trades().Page(pq).Select(&pt)
+	err = q.Trades().Page(pq).Select(tt.Ctx, &pt)
 	tt.Require.NoError(err)
 
 	// test for asset pairs
@@ -48,29 +50,31 @@ func TestTradeQueries(t *testing.T) {
 	assetEUR, err := q.GetAssetID(xdr.MustNewCreditAsset("EUR", "GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU"))
 	tt.Require.NoError(err)
 
-	err = q.TradesForAssetPair(assetUSD, assetEUR).Page(db2.MustPageQuery("", false, "asc", 100)).Select(&trades)
+	err = q.TradesForAssetPair(assetUSD, assetEUR).Page(db2.MustPageQuery("", false, "asc", 100)).Select(tt.Ctx, &trades)
 	tt.Require.NoError(err)
 	tt.Assert.Len(trades, 0)
 
 	assetUSD, err = q.GetAssetID(xdr.MustNewCreditAsset("USD", "GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU"))
 	tt.Require.NoError(err)
 
-	err = q.TradesForAssetPair(lumen, assetUSD).Page(db2.MustPageQuery("", false, "asc", 100)).Select(&trades)
+	err = q.TradesForAssetPair(lumen, assetUSD).Page(db2.MustPageQuery("", false, "asc", 100)).Select(tt.Ctx, &trades)
 	tt.Require.NoError(err)
 	tt.Assert.Len(trades, 1)
 
 	tt.Assert.Equal(xdr.Int64(2000000000), trades[0].BaseAmount)
 	tt.Assert.Equal(xdr.Int64(1000000000), trades[0].CounterAmount)
 	tt.Assert.Equal(true, trades[0].BaseIsSeller)
+	tt.Assert.Equal(true, trades[0].BaseIsMaker)
 
 	// reverse assets
-	err = q.TradesForAssetPair(assetUSD, lumen).Page(db2.MustPageQuery("", false, "asc", 100)).Select(&trades)
+	err = q.TradesForAssetPair(assetUSD, lumen).Page(db2.MustPageQuery("", false, "asc", 100)).Select(tt.Ctx, &trades)
 	tt.Require.NoError(err)
 	tt.Assert.Len(trades, 1)
 
 	tt.Assert.Equal(xdr.Int64(1000000000), trades[0].BaseAmount)
 	tt.Assert.Equal(xdr.Int64(2000000000), trades[0].CounterAmount)
 	tt.Assert.Equal(false, trades[0].BaseIsSeller)
+	tt.Assert.Equal(false, trades[0].BaseIsMaker)
 }
 
 func createInsertTrades(
@@ -192,14 +196,14 @@ func TestBatchInsertTrade(t *testing.T) {
 
 	first, second, third := createInsertTrades(accountIDs, assetIDs, 3)
 
-	builder := q.NewTradeBatchInsertBuilder(1)
+	builder := q.NewTradeBatchInsertBuilder(1, TradeConflictFail)
 	tt.Assert.NoError(
 		builder.Add(first, second, third),
 	)
 	tt.Assert.NoError(builder.Exec())
 
 	var rows []Trade
-	tt.Assert.NoError(q.Trades().Page(db2.MustPageQuery("", false, "asc", 100)).Select(&rows))
+	tt.Assert.NoError(q.Trades().Page(db2.MustPageQuery("", false, "asc", 100)).Select(tt.Ctx, &rows))
 
 	idToAccount := buildIDtoAccountMapping(addresses, accountIDs)
 	idToAsset := buildIDtoAssetMapping(assets, assetIDs)
@@ -256,6 +260,7 @@ func TestBatchInsertTrade(t *testing.T) {
 			CounterAssetCode:   firstBoughtAssetCode,
 			CounterAmount:      first.Trade.AmountBought,
 			BaseIsSeller:       true,
+			BaseIsMaker:        true,
 			PriceN:             null.NewInt(int64(first.SellPrice.N), true),
 			PriceD:             null.NewInt(int64(first.SellPrice.D), true),
 		},
@@ -277,6 +282,7 @@ func TestBatchInsertTrade(t *testing.T) {
 			CounterAssetIssuer: secondBoughtAssetIssuer,
 			CounterAmount:      second.Trade.AmountBought,
 			BaseIsSeller:       true,
+			BaseIsMaker:        true,
 			PriceN:             null.NewInt(int64(second.SellPrice.N), true),
 			PriceD:             null.NewInt(int64(second.SellPrice.D), true),
 		},
@@ -314,37 +320,115 @@ func TestBatchInsertTrade(t *testing.T) {
 	}
 }
 
+func TestBatchInsertTradeConflicts(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	addresses := []string{
+		"GB2QIYT2IAUFMRXKLSLLPRECC6OCOGJMADSPTRK7TGNT2SFR2YGWDARD",
+		"GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU",
+	}
+	accountIDs, assetIDs := createAccountsAndAssets(
+		tt, q,
+		addresses,
+		[]xdr.Asset{eurAsset, usdAsset, nativeAsset},
+	)
+	first, second, third := createInsertTrades(accountIDs, assetIDs, 3)
+
+	builder := q.NewTradeBatchInsertBuilder(0, TradeConflictFail)
+	tt.Assert.NoError(builder.Add(first, second))
+	tt.Assert.NoError(builder.Exec())
+
+	countTrades := func() int {
+		var rows []Trade
+		tt.Assert.NoError(q.Trades().Page(db2.MustPageQuery("", false, "asc", 100)).Select(tt.Ctx, &rows))
+		return len(rows)
+	}
+
+	builder = q.NewTradeBatchInsertBuilder(0, TradeConflictFail)
+	tt.Assert.NoError(builder.Add(first))
+	tt.Assert.Error(builder.Exec())
+
+	builder = q.NewTradeBatchInsertBuilder(0, TradeConflictSkip)
+	tt.Assert.NoError(builder.Add(first, third))
+	tt.Assert.NoError(builder.Exec())
+	tt.Assert.Equal(3, countTrades())
+
+	// identical trades are skipped
+	builder = q.NewTradeBatchInsertBuilder(1, TradeConflictVerify)
+	tt.Assert.NoError(builder.Add(first, second, third))
+	tt.Assert.NoError(builder.Exec())
+	tt.Assert.Equal(3, countTrades())
+
+	changed := first
+	changed.Trade.AmountSold++
+	builder = q.NewTradeBatchInsertBuilder(0, TradeConflictVerify)
+	tt.Assert.NoError(builder.Add(changed))
+	tt.Assert.EqualError(
+		builder.Exec(),
+		fmt.Sprintf("trade %d-%d conflicts with an existing trade", first.HistoryOperationID, first.Order),
+	)
+
+	// existing trades are replaced
+	builder = q.NewTradeBatchInsertBuilder(0, TradeConflictUpdate)
+	tt.Assert.NoError(builder.Add(changed, second))
+	tt.Assert.NoError(builder.Exec())
+	tt.Assert.Equal(3, countTrades())
+
+	var amount int64
+	tt.Assert.NoError(q.GetRaw(
+		&amount,
+		`SELECT base_amount FROM history_trades WHERE history_operation_id = ? AND "order" = ?`,
+		first.HistoryOperationID, first.Order,
+	))
+	tt.Assert.Equal(int64(changed.Trade.AmountSold), amount)
+}
+
+func TestParseTradeConflictMode(t *testing.T) {
+	for _, name := range []string{"fail", "skip", "verify", "update"} {
+		mode, err := ParseTradeConflictMode(name)
+		assert.NoError(t, err)
+		assert.Equal(t, TradeConflictMode(name), mode)
+	}
+
+	mode, err := ParseTradeConflictMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, TradeConflictUpdate, mode)
+
+	_, err = ParseTradeConflictMode("ignore")
+	assert.EqualError(t, err, "unknown trade conflict mode: ignore")
+}
+
 func TestTradesQueryForAccount(t *testing.T) {
 	tt := test.Start(t).Scenario("kahuna")
 	defer tt.Finish()
 	q := &Q{tt.HorizonSession()}
-	tradesQ := q.Trades()
-	var trades []Trade
-
 	account := "GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU"
-	tradesQ.ForAccount(account)
-	tt.Assert.Equal(int64(15), tradesQ.forAccountID)
-	tt.Assert.Equal(int64(0), tradesQ.forOfferID)
-
-	tradesQ.Page(db2.MustPageQuery("", false, "desc", 100))
-	_, _, err := tradesQ.sql.ToSql()
-	// q.sql was reset in Page so should return error
-	tt.Assert.EqualError(err, "select statements must have at least one result column")
+	filter := TradesFilter{AccountID: account}
+	page := db2.MustPageQuery("", false, "desc", 100)
+	sql, err := q.tradesSQL(filter, false, page)
+	tt.Assert.NoError(err)
+	rawSQL, args, err := sql.ToSql()
+	tt.Assert.NoError(err)
+	// the account id is looked up before building the query
+	tt.Assert.Equal(int64(15), args[0])
 
-	expectedRawSQL := `(SELECT history_operation_id, htrd."order", htrd.ledger_closed_at, htrd.offer_id, htrd.base_offer_id, base_accounts.address as base_account, base_assets.asset_type as base_asset_type, base_assets.asset_code as base_asset_code, base_assets.asset_issuer as base_asset_issuer, htrd.base_amount, htrd.counter_offer_id, counter_accounts.address as counter_account, counter_assets.asset_type as counter_asset_type, counter_assets.asset_code as counter_asset_code, counter_assets.asset_issuer as counter_asset_issuer, htrd.counter_amount, htrd.base_is_seller, htrd.price_n, htrd.price_d FROM history_trades htrd JOIN history_accounts base_accounts ON base_account_id = base_accounts.id JOIN history_accounts counter_accounts ON counter_account_id = counter_accounts.id JOIN history_assets base_assets ON base_asset_id = base_assets.id JOIN history_assets counter_assets ON counter_asset_id = counter_assets.id WHERE htrd.base_account_id = ? AND (
+	expectedRawSQL := `(SELECT history_operation_id, htrd."order", htrd.ledger_closed_at, htrd.offer_id, htrd.base_offer_id, base_accounts.address as base_account, base_assets.asset_type as base_asset_type, base_assets.asset_code as base_asset_code, base_assets.asset_issuer as base_asset_issuer, htrd.base_amount, htrd.counter_offer_id, counter_accounts.address as counter_account, counter_assets.asset_type as counter_asset_type, counter_assets.asset_code as counter_asset_code, counter_assets.asset_issuer as counter_asset_issuer, htrd.counter_amount, htrd.base_is_seller, htrd.base_is_maker, htrd.price_n, htrd.price_d, htrd.sequence FROM history_trades htrd JOIN history_accounts base_accounts ON base_account_id = base_accounts.id JOIN history_accounts counter_accounts ON counter_account_id = counter_accounts.id JOIN history_assets base_assets ON base_asset_id = base_assets.id JOIN history_assets counter_assets ON counter_asset_id = counter_assets.id WHERE htrd.base_account_id = ? AND (
 				htrd.history_operation_id <= ?
 			AND (
 				htrd.history_operation_id < ? OR
 				(htrd.history_operation_id = ? AND htrd.order < ?)
-			)) ORDER BY htrd.history_operation_id desc, htrd.order desc) UNION (SELECT history_operation_id, htrd."order", htrd.ledger_closed_at, htrd.offer_id, htrd.base_offer_id, base_accounts.address as base_account, base_assets.asset_type as base_asset_type, base_assets.asset_code as base_asset_code, base_assets.asset_issuer as base_asset_issuer, htrd.base_amount, htrd.counter_offer_id, counter_accounts.address as counter_account, counter_assets.asset_type as counter_asset_type, counter_assets.asset_code as counter_asset_code, counter_assets.asset_issuer as counter_asset_issuer, htrd.counter_amount, htrd.base_is_seller, htrd.price_n, htrd.price_d FROM history_trades htrd JOIN history_accounts base_accounts ON base_account_id = base_accounts.id JOIN history_accounts counter_accounts ON counter_account_id = counter_accounts.id JOIN history_assets base_assets ON base_asset_id = base_assets.id JOIN history_assets counter_assets ON counter_asset_id = counter_assets.id WHERE htrd.counter_account_id = ? AND (
+			)) ORDER BY htrd.history_operation_id desc, htrd.order desc) UNION (SELECT history_operation_id, htrd."order", htrd.ledger_closed_at, htrd.offer_id, htrd.base_offer_id, base_accounts.address as base_account, base_assets.asset_type as base_asset_type, base_assets.asset_code as base_asset_code, base_assets.asset_issuer as base_asset_issuer, htrd.base_amount, htrd.counter_offer_id, counter_accounts.address as counter_account, counter_assets.asset_type as counter_asset_type, counter_assets.asset_code as counter_asset_code, counter_assets.asset_issuer as counter_asset_issuer, htrd.counter_amount, htrd.base_is_seller, htrd.base_is_maker, htrd.price_n, htrd.price_d, htrd.sequence FROM history_trades htrd JOIN history_accounts base_accounts ON base_account_id = base_accounts.id JOIN history_accounts counter_accounts ON counter_account_id = counter_accounts.id JOIN history_assets base_assets ON base_asset_id = base_assets.id JOIN history_assets counter_assets ON counter_asset_id = counter_assets.id WHERE htrd.counter_account_id = ? AND (
 				htrd.history_operation_id <= ?
 			AND (
 				htrd.history_operation_id < ? OR
 				(htrd.history_operation_id = ? AND htrd.order < ?)
 			)) ORDER BY htrd.history_operation_id desc, htrd.order desc) ORDER BY history_operation_id desc, "order" desc LIMIT 100`
-	tt.Assert.Equal(expectedRawSQL, tradesQ.rawSQL)
+	tt.Assert.Equal(expectedRawSQL, rawSQL)
 
-	err = tradesQ.Select(&trades)
+	trades, err := q.GetTrades(tt.Ctx, filter, page)
 	tt.Assert.NoError(err)
 	tt.Assert.Len(trades, 3)
 
@@ -365,33 +449,28 @@ func TestTradesQueryForOffer(t *testing.T) {
 	tt := test.Start(t).Scenario("kahuna")
 	defer tt.Finish()
 	q := &Q{tt.HorizonSession()}
-	tradesQ := q.Trades()
-	var trades []Trade
-
 	offerID := int64(2)
-	tradesQ.ForOffer(offerID)
-	tt.Assert.Equal(int64(0), tradesQ.forAccountID)
-	tt.Assert.Equal(int64(2), tradesQ.forOfferID)
-
-	tradesQ.Page(db2.MustPageQuery("", false, "asc", 100))
-	_, _, err := tradesQ.sql.ToSql()
-	// q.sql was reset in Page so should return error
-	tt.Assert.EqualError(err, "select statements must have at least one result column")
+	filter := TradesFilter{OfferID: offerID}
+	page := db2.MustPageQuery("", false, "asc", 100)
+	sql, err := q.tradesSQL(filter, false, page)
+	tt.Assert.NoError(err)
+	rawSQL, _, err := sql.ToSql()
+	tt.Assert.NoError(err)
 
-	expectedRawSQL := `(SELECT history_operation_id, htrd."order", htrd.ledger_closed_at, htrd.offer_id, htrd.base_offer_id, base_accounts.address as base_account, base_assets.asset_type as base_asset_type, base_assets.asset_code as base_asset_code, base_assets.asset_issuer as base_asset_issuer, htrd.base_amount, htrd.counter_offer_id, counter_accounts.address as counter_account, counter_assets.asset_type as counter_asset_type, counter_assets.asset_code as counter_asset_code, counter_assets.asset_issuer as counter_asset_issuer, htrd.counter_amount, htrd.base_is_seller, htrd.price_n, htrd.price_d FROM history_trades htrd JOIN history_accounts base_accounts ON base_account_id = base_accounts.id JOIN history_accounts counter_accounts ON counter_account_id = counter_accounts.id JOIN history_assets base_assets ON base_asset_id = base_assets.id JOIN history_assets counter_assets ON counter_asset_id = counter_assets.id WHERE htrd.base_offer_id = ? AND (
+	expectedRawSQL := `(SELECT history_operation_id, htrd."order", htrd.ledger_closed_at, htrd.offer_id, htrd.base_offer_id, base_accounts.address as base_account, base_assets.asset_type as base_asset_type, base_assets.asset_code as base_asset_code, base_assets.asset_issuer as base_asset_issuer, htrd.base_amount, htrd.counter_offer_id, counter_accounts.address as counter_account, counter_assets.asset_type as counter_asset_type, counter_assets.asset_code as counter_asset_code, counter_assets.asset_issuer as counter_asset_issuer, htrd.counter_amount, htrd.base_is_seller, htrd.base_is_maker, htrd.price_n, htrd.price_d, htrd.sequence FROM history_trades htrd JOIN history_accounts base_accounts ON base_account_id = base_accounts.id JOIN history_accounts counter_accounts ON counter_account_id = counter_accounts.id JOIN history_assets base_assets ON base_asset_id = base_assets.id JOIN history_assets counter_assets ON counter_asset_id = counter_assets.id WHERE htrd.base_offer_id = ? AND (
 				htrd.history_operation_id >= ?
 			AND (
 				htrd.history_operation_id > ? OR
 				(htrd.history_operation_id = ? AND htrd.order > ?)
-			)) ORDER BY htrd.history_operation_id asc, htrd.order asc) UNION (SELECT history_operation_id, htrd."order", htrd.ledger_closed_at, htrd.offer_id, htrd.base_offer_id, base_accounts.address as base_account, base_assets.asset_type as base_asset_type, base_assets.asset_code as base_asset_code, base_assets.asset_issuer as base_asset_issuer, htrd.base_amount, htrd.counter_offer_id, counter_accounts.address as counter_account, counter_assets.asset_type as counter_asset_type, counter_assets.asset_code as counter_asset_code, counter_assets.asset_issuer as counter_asset_issuer, htrd.counter_amount, htrd.base_is_seller, htrd.price_n, htrd.price_d FROM history_trades htrd JOIN history_accounts base_accounts ON base_account_id = base_accounts.id JOIN history_accounts counter_accounts ON counter_account_id = counter_accounts.id JOIN history_assets base_assets ON base_asset_id = base_assets.id JOIN history_assets counter_assets ON counter_asset_id = counter_assets.id WHERE htrd.counter_offer_id = ? AND (
+			)) ORDER BY htrd.history_operation_id asc, htrd.order asc) UNION (SELECT history_operation_id, htrd."order", htrd.ledger_closed_at, htrd.offer_id, htrd.base_offer_id, base_accounts.address as base_account, base_assets.asset_type as base_asset_type, base_assets.asset_code as base_asset_code, base_assets.asset_issuer as base_asset_issuer, htrd.base_amount, htrd.counter_offer_id, counter_accounts.address as counter_account, counter_assets.asset_type as counter_asset_type, counter_assets.asset_code as counter_asset_code, counter_assets.asset_issuer as counter_asset_issuer, htrd.counter_amount, htrd.base_is_seller, htrd.base_is_maker, htrd.price_n, htrd.price_d, htrd.sequence FROM history_trades htrd JOIN history_accounts base_accounts ON base_account_id = base_accounts.id JOIN history_accounts counter_accounts ON counter_account_id = counter_accounts.id JOIN history_assets base_assets ON base_asset_id = base_assets.id JOIN history_assets counter_assets ON counter_asset_id = counter_assets.id WHERE htrd.counter_offer_id = ? AND (
 				htrd.history_operation_id >= ?
 			AND (
 				htrd.history_operation_id > ? OR
 				(htrd.history_operation_id = ? AND htrd.order > ?)
 			)) ORDER BY htrd.history_operation_id asc, htrd.order asc) ORDER BY history_operation_id asc, "order" asc LIMIT 100`
-	tt.Assert.Equal(expectedRawSQL, tradesQ.rawSQL)
+	tt.Assert.Equal(expectedRawSQL, rawSQL)
 
-	err = tradesQ.Select(&trades)
+	trades, err := q.GetTrades(tt.Ctx, filter, page)
 	tt.Assert.NoError(err)
 	tt.Assert.Len(trades, 2)
 
@@ -402,3 +481,237 @@ func TestTradesQueryForOffer(t *testing.T) {
 	tt.Assert.Equal(int64(85899350017), trades[1].HistoryOperationID)
 	tt.Assert.Equal(offerID, trades[1].OfferID)
 }
+
+func TestTradesQueryForAsset(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+	lumen, err := q.GetAssetID(xdr.MustNewNativeAsset())
+	tt.Require.NoError(err)
+
+	// native asset is a counter asset in all kahuna trades
+	trades, err := q.GetTrades(tt.Ctx, TradesFilter{AssetID: lumen}, db2.MustPageQuery("", false, "desc", 100))
+	tt.Require.NoError(err)
+	tt.Assert.Len(trades, 4)
+	for _, trade := range trades {
+		tt.Assert.Equal("native", trade.BaseAssetType)
+		tt.Assert.NotEqual("native", trade.CounterAssetType)
+	}
+
+	tt.Assert.Equal(int64(103079219201), trades[0].HistoryOperationID)
+	tt.Assert.Equal(xdr.Int64(200000000), trades[0].BaseAmount)
+	tt.Assert.Equal("USD", trades[0].CounterAssetCode)
+	tt.Assert.Equal(true, trades[0].BaseIsSeller)
+
+	eur, err := q.GetAssetID(xdr.MustNewCreditAsset("EUR", "GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU"))
+	tt.Require.NoError(err)
+
+	trades, err = q.GetTrades(tt.Ctx, TradesFilter{AssetID: eur}, db2.MustPageQuery("", false, "asc", 100))
+	tt.Require.NoError(err)
+	tt.Assert.Len(trades, 2)
+	for _, trade := range trades {
+		tt.Assert.Equal("EUR", trade.BaseAssetCode)
+		tt.Assert.Equal("native", trade.CounterAssetType)
+	}
+	tt.Assert.Equal(int64(81604382721), trades[0].HistoryOperationID)
+	tt.Assert.Equal(int32(1), trades[0].Order)
+	tt.Assert.Equal(int64(85899350017), trades[1].HistoryOperationID)
+
+	// the asset pair filters both sides of the UNION
+	usd, err := q.GetAssetID(xdr.MustNewCreditAsset("USD", "GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU"))
+	tt.Require.NoError(err)
+	trades, err = q.GetTrades(
+		tt.Ctx,
+		TradesFilter{AssetID: lumen, BaseAssetID: usd, CounterAssetID: lumen},
+		db2.MustPageQuery("", false, "asc", 100),
+	)
+	tt.Require.NoError(err)
+	tt.Assert.NotEmpty(trades)
+	for _, trade := range trades {
+		tt.Assert.Equal("native", trade.BaseAssetType)
+		tt.Assert.Equal("USD", trade.CounterAssetCode)
+	}
+	trades, err = q.GetTrades(
+		tt.Ctx,
+		TradesFilter{AssetID: eur, BaseAssetID: usd, CounterAssetID: lumen},
+		db2.MustPageQuery("", false, "asc", 100),
+	)
+	tt.Require.NoError(err)
+	tt.Assert.Empty(trades)
+
+	// AssetID can't be combined with other UNION filters
+	_, err = q.GetTrades(tt.Ctx, TradesFilter{AssetID: eur, OfferID: 2}, db2.MustPageQuery("", false, "asc", 100))
+	tt.Assert.EqualError(err, "AssetID cannot be combined with AccountID or OfferID")
+
+	_, err = q.GetTrades(tt.Ctx, TradesFilter{BaseAssetID: usd}, db2.MustPageQuery("", false, "asc", 100))
+	tt.Assert.EqualError(err, "base and counter assets must be set together")
+
+	// the deprecated builder renders the same trades
+	var built []Trade
+	err = q.Trades().ForAsset(eur).Page(db2.MustPageQuery("", false, "asc", 100)).Select(tt.Ctx, &built)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(built, 2)
+}
+
+func TestTradesQueryStopCursor(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	all, err := q.GetTrades(tt.Ctx, TradesFilter{}, db2.MustPageQuery("", false, "asc", 100))
+	tt.Require.NoError(err)
+	tt.Require.Len(all, 4)
+
+	// streaming backwards from the head stops at the stop cursor
+	pq := db2.MustPageQuery("", false, "desc", 100)
+	pq.StopCursor = all[1].PagingToken()
+	trades, err := q.GetTrades(tt.Ctx, TradesFilter{}, pq)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Equal([]Trade{all[3], all[2]}, trades)
+	}
+
+	// the same cursor bounds ascending pages
+	pq = db2.MustPageQuery("", false, "asc", 100)
+	pq.StopCursor = all[2].PagingToken()
+	trades, err = q.GetTrades(tt.Ctx, TradesFilter{}, pq)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Equal([]Trade{all[0], all[1]}, trades)
+	}
+
+	// and UNION queries
+	pq = db2.MustPageQuery("", false, "desc", 100)
+	pq.StopCursor = all[0].PagingToken()
+	trades, err = q.GetTrades(tt.Ctx, TradesFilter{AccountID: all[0].BaseAccount}, pq)
+	if tt.Assert.NoError(err) {
+		for _, trade := range trades {
+			tt.Assert.NotEqual(all[0].PagingToken(), trade.PagingToken())
+		}
+	}
+}
+
+func TestTradesQueryForPriceRange(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	all, err := q.GetTrades(tt.Ctx, TradesFilter{}, db2.MustPageQuery("", false, "asc", 100))
+	tt.Require.NoError(err)
+	tt.Require.Len(all, 4)
+
+	n, d := int32(all[0].PriceN.Int64), int32(all[0].PriceD.Int64)
+
+	page := db2.MustPageQuery("", false, "asc", 100)
+	trades, err := q.GetTrades(tt.Ctx, TradesFilter{
+		PriceRange: &TradePriceRange{MinN: n, MinD: d, MaxN: n, MaxD: d},
+	}, page)
+	if tt.Assert.NoError(err) && tt.Assert.NotEmpty(trades) {
+		for _, trade := range trades {
+			tt.Assert.Equal(all[0].PriceN.Int64*int64(d), trade.PriceN.Int64*all[0].PriceD.Int64)
+		}
+	}
+
+	// reversed trades compare against the reversed price
+	err = q.ReverseTrades().
+		ForPriceRange(d, n, d, n).
+		Page(page).
+		Select(tt.Ctx, &trades)
+	if tt.Assert.NoError(err) {
+		tt.Assert.NotEmpty(trades)
+	}
+
+	// nothing is traded at a price of zero
+	trades, err = q.GetTrades(tt.Ctx, TradesFilter{
+		PriceRange: &TradePriceRange{MinN: 0, MinD: 1, MaxN: 0, MaxD: 1},
+	}, page)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(trades, 0)
+	}
+
+	_, err = q.GetTrades(tt.Ctx, TradesFilter{
+		PriceRange: &TradePriceRange{MinN: 2, MinD: 1, MaxN: 1, MaxD: 1},
+	}, page)
+	tt.Assert.EqualError(err, "invalid price range: minimum price is greater than maximum price")
+
+	_, err = q.GetTrades(tt.Ctx, TradesFilter{
+		PriceRange: &TradePriceRange{MinN: 1, MinD: 0, MaxN: 1, MaxD: 1},
+	}, page)
+	tt.Assert.EqualError(err, "invalid price range: numerators must be non-negative and denominators positive")
+}
+
+func TestTradesQueryForTimeRange(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	all, err := q.GetTrades(tt.Ctx, TradesFilter{}, db2.MustPageQuery("", false, "asc", 100))
+	tt.Require.NoError(err)
+	tt.Require.NotEmpty(all)
+
+	start := supportTime.MillisFromSeconds(all[0].LedgerCloseTime.Unix())
+	end := supportTime.MillisFromSeconds(all[0].LedgerCloseTime.Unix() + 1)
+
+	page := db2.MustPageQuery("", false, "asc", 100)
+	trades, err := q.GetTrades(tt.Ctx, TradesFilter{StartTime: start, EndTime: end}, page)
+	if tt.Assert.NoError(err) && tt.Assert.NotEmpty(trades) {
+		for _, trade := range trades {
+			tt.Assert.True(trade.LedgerCloseTime.Equal(all[0].LedgerCloseTime))
+		}
+	}
+
+	// the end of the range is exclusive
+	trades, err = q.GetTrades(tt.Ctx, TradesFilter{EndTime: start}, page)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(trades, 0)
+	}
+
+	// UNION queries
+	trades, err = q.GetTrades(tt.Ctx, TradesFilter{AccountID: all[0].BaseAccount, StartTime: start}, page)
+	if tt.Assert.NoError(err) {
+		tt.Assert.NotEmpty(trades)
+	}
+}
+
+func TestTradesQueryBySequence(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	all, err := q.GetTrades(tt.Ctx, TradesFilter{}, db2.MustPageQuery("", false, "asc", 100))
+	tt.Require.NoError(err)
+	tt.Require.Len(all, 4)
+
+	// trades are numbered in the order of their ids
+	for i, trade := range all {
+		tt.Assert.Equal(int64(i+1), trade.Sequence)
+	}
+
+	bySequence := TradesFilter{BySequence: true}
+	trades, err := q.GetTrades(tt.Ctx, bySequence, db2.MustPageQuery("2", false, "asc", 100))
+	if tt.Assert.NoError(err) && tt.Assert.Len(trades, 2) {
+		tt.Assert.Equal(int64(3), trades[0].Sequence)
+		tt.Assert.Equal(int64(4), trades[1].Sequence)
+	}
+
+	pq := db2.MustPageQuery("", false, "desc", 100)
+	pq.StopCursor = "2"
+	trades, err = q.GetTrades(tt.Ctx, bySequence, pq)
+	if tt.Assert.NoError(err) && tt.Assert.Len(trades, 2) {
+		tt.Assert.Equal(int64(4), trades[0].Sequence)
+		tt.Assert.Equal(int64(3), trades[1].Sequence)
+	}
+
+	// and UNION queries
+	trades, err = q.GetTrades(
+		tt.Ctx,
+		TradesFilter{AccountID: all[0].BaseAccount, BySequence: true},
+		db2.MustPageQuery("1", false, "asc", 100),
+	)
+	if tt.Assert.NoError(err) {
+		for _, trade := range trades {
+			tt.Assert.True(trade.Sequence > 1)
+		}
+	}
+
+	_, err = q.GetTrades(tt.Ctx, bySequence, db2.MustPageQuery("1-0", false, "asc", 100))
+	tt.Assert.Error(err)
+}
@@ -1,8 +1,11 @@
 package history
 
 import (
+	"context"
+
 	sq "github.com/Masterminds/squirrel"
 	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/timing"
 	"github.com/stellar/go/services/horizon/internal/toid"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
@@ -37,7 +40,7 @@ func (q *Q) TransactionsByIDs(ids ...int64) (map[int64]Transaction, error) {
 	})
 
 	var transactions []Transaction
-	if err := q.Select(&transactions, sql); err != nil {
+	if err := q.Named("transactions").Select(&transactions, sql); err != nil {
 		return nil, err
 	}
 
@@ -49,97 +52,125 @@ func (q *Q) TransactionsByIDs(ids ...int64) (map[int64]Transaction, error) {
 	return byID, nil
 }
 
-// Transactions provides a helper to filter rows from the `history_transactions`
-// table with pre-defined filters.  See `TransactionsQ` methods for the
-// available filters.
-func (q *Q) Transactions() *TransactionsQ {
-	return &TransactionsQ{
-		parent:        q,
-		sql:           selectTransaction,
-		includeFailed: false,
-	}
+// TransactionsFilter defines the filters of a transactions query. Zero values
+// leave the corresponding filter unset, all the filters which are set must
+// match.
+type TransactionsFilter struct {
+	// AccountID only includes transactions the given account participated in.
+	AccountID string
+	// LedgerSequence only includes transactions of the given ledger.
+	LedgerSequence int32
+	// MemoType and Memo only include transactions with the given memo. The
+	// memo type is one of "text", "id", "hash" or "return" and the memo value
+	// is formatted the way it is stored in the `history_transactions` table:
+	// memo ids as decimal strings and memo hashes (and returns) base64
+	// encoded.
+	MemoType string
+	Memo     string
+	// IncludeFailed includes failed transactions.
+	IncludeFailed bool
+	// MinTrades only includes transactions which generated at least the
+	// given number of trades.
+	MinTrades int32
 }
 
-// ForAccount filters the transactions collection to a specific account
-func (q *TransactionsQ) ForAccount(aid string) *TransactionsQ {
-	var account Account
-	q.Err = q.parent.AccountByAddress(&account, aid)
-	if q.Err != nil {
-		return q
-	}
+// GetTransactions returns a page of transactions matching the filter. Unlike
+// the TransactionsQ builder, errors of the lookups required by the filters
+// (for example the account of AccountID) are returned immediately.
+func (q *Q) GetTransactions(ctx context.Context, filter TransactionsFilter, page db2.PageQuery) ([]Transaction, error) {
+	return q.selectTransactions(ctx, filter, &page)
+}
 
-	q.sql = q.sql.
-		Join("history_transaction_participants htp ON htp.history_transaction_id = ht.id").
-		Where("htp.history_account_id = ?", account.ID)
+func (q *Q) selectTransactions(ctx context.Context, filter TransactionsFilter, page *db2.PageQuery) ([]Transaction, error) {
+	q = &Q{q.WithContext(ctx).Replica()}
 
-	return q
-}
+	sql, err := q.transactionsSQL(filter)
+	if err != nil {
+		return nil, err
+	}
 
-// ForLedger filters the query to a only transactions in a specific ledger,
-// specified by its sequence.
-func (q *TransactionsQ) ForLedger(seq int32) *TransactionsQ {
-	var ledger Ledger
-	q.Err = q.parent.LedgerBySequence(&ledger, seq)
-	if q.Err != nil {
-		return q
+	if page != nil {
+		sql, err = page.ApplyTo(sql, "ht.id")
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	start := toid.ID{LedgerSequence: seq}
-	end := toid.ID{LedgerSequence: seq + 1}
-	q.sql = q.sql.Where(
-		"ht.id >= ? AND ht.id < ?",
-		start.ToInt64(),
-		end.ToInt64(),
-	)
+	var transactions []Transaction
+	if err := q.Named("transactions").Select(&transactions, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select transactions")
+	}
 
-	return q
+	if err := checkTransactionsStatus(ctx, transactions, filter.IncludeFailed); err != nil {
+		return nil, err
+	}
+	return transactions, nil
 }
 
-// IncludeFailed changes the query to include failed transactions.
-func (q *TransactionsQ) IncludeFailed() *TransactionsQ {
-	q.includeFailed = true
-	return q
-}
+func (q *Q) transactionsSQL(filter TransactionsFilter) (sq.SelectBuilder, error) {
+	sql := selectTransaction
 
-// Page specifies the paging constraints for the query being built by `q`.
-func (q *TransactionsQ) Page(page db2.PageQuery) *TransactionsQ {
-	if q.Err != nil {
-		return q
+	if filter.AccountID != "" {
+		var account Account
+		if err := q.AccountByAddress(&account, filter.AccountID); err != nil {
+			return sql, errors.Wrap(err, "could not load account")
+		}
+
+		sql = sql.
+			Join("history_transaction_participants htp ON htp.history_transaction_id = ht.id").
+			Where("htp.history_account_id = ?", account.ID)
 	}
 
-	q.sql, q.Err = page.ApplyTo(q.sql, "ht.id")
-	return q
-}
+	if filter.LedgerSequence != 0 {
+		var ledger Ledger
+		if err := q.LedgerBySequence(&ledger, filter.LedgerSequence); err != nil {
+			return sql, errors.Wrap(err, "could not load ledger")
+		}
 
-// Select loads the results of the query specified by `q` into `dest`.
-func (q *TransactionsQ) Select(dest interface{}) error {
-	if q.Err != nil {
-		return q.Err
+		start := toid.ID{LedgerSequence: filter.LedgerSequence}
+		end := toid.ID{LedgerSequence: filter.LedgerSequence + 1}
+		sql = sql.Where(
+			"ht.id >= ? AND ht.id < ?",
+			start.ToInt64(),
+			end.ToInt64(),
+		)
 	}
 
-	if !q.includeFailed {
-		q.sql = q.sql.
-			Where("(ht.successful = true OR ht.successful IS NULL)")
+	if filter.MemoType != "" {
+		switch filter.MemoType {
+		case "text", "id", "hash", "return":
+		default:
+			return sql, errors.Errorf("invalid memo type: %s", filter.MemoType)
+		}
+
+		sql = sql.Where(sq.Eq{
+			"ht.memo_type": filter.MemoType,
+			"ht.memo":      filter.Memo,
+		})
 	}
 
-	q.Err = q.parent.Select(dest, q.sql)
-	if q.Err != nil {
-		return q.Err
+	if !filter.IncludeFailed {
+		sql = sql.Where("(ht.successful = true OR ht.successful IS NULL)")
 	}
 
-	transactions, ok := dest.(*[]Transaction)
-	if !ok {
-		return errors.New("dest is not *[]Transaction")
+	if filter.MinTrades > 0 {
+		sql = sql.Where("ht.trade_count >= ?", filter.MinTrades)
 	}
 
-	for _, t := range *transactions {
+	return sql, nil
+}
+
+// checkTransactionsStatus checks that the `successful` column of the
+// transactions matches their results.
+func checkTransactionsStatus(ctx context.Context, transactions []Transaction, includeFailed bool) error {
+	for _, t := range transactions {
 		var resultXDR xdr.TransactionResult
-		err := xdr.SafeUnmarshalBase64(t.TxResult, &resultXDR)
+		err := timing.UnmarshalXDR(ctx, t.TxResult, &resultXDR)
 		if err != nil {
 			return err
 		}
 
-		if !q.includeFailed {
+		if !includeFailed {
 			if !t.Successful {
 				return errors.Errorf("Corrupted data! `include_failed=false` but returned transaction is failed: %s", t.TransactionHash)
 			}
@@ -162,6 +193,61 @@ func (q *TransactionsQ) Select(dest interface{}) error {
 	return nil
 }
 
+// Transactions provides a helper to filter rows from the `history_transactions`
+// table with pre-defined filters.  See `TransactionsQ` methods for the
+// available filters.
+//
+// Deprecated: TransactionsQ is a wrapper of GetTransactions kept during the
+// migration to the context aware API, use GetTransactions instead.
+func (q *Q) Transactions() *TransactionsQ {
+	return &TransactionsQ{
+		parent: q,
+	}
+}
+
+// ForAccount filters the transactions collection to a specific account
+func (q *TransactionsQ) ForAccount(aid string) *TransactionsQ {
+	q.filter.AccountID = aid
+	return q
+}
+
+// ForLedger filters the query to a only transactions in a specific ledger,
+// specified by its sequence.
+func (q *TransactionsQ) ForLedger(seq int32) *TransactionsQ {
+	q.filter.LedgerSequence = seq
+	return q
+}
+
+// ForMemo filters the query to only transactions with the given memo. See
+// TransactionsFilter for the format of the memo.
+func (q *TransactionsQ) ForMemo(memoType, memo string) *TransactionsQ {
+	q.filter.MemoType = memoType
+	q.filter.Memo = memo
+	return q
+}
+
+// IncludeFailed changes the query to include failed transactions.
+func (q *TransactionsQ) IncludeFailed() *TransactionsQ {
+	q.filter.IncludeFailed = true
+	return q
+}
+
+// Page specifies the paging constraints for the query being built by `q`.
+func (q *TransactionsQ) Page(page db2.PageQuery) *TransactionsQ {
+	q.page = &page
+	return q
+}
+
+// Select loads the results of the query specified by `q` into `dest`.
+func (q *TransactionsQ) Select(dest *[]Transaction) error {
+	if q.Err != nil {
+		return q.Err
+	}
+
+	*dest, q.Err = q.parent.selectTransactions(q.parent.Ctx, q.filter, q.page)
+	return q.Err
+}
+
 // QTransactions defines transaction related queries.
 type QTransactions interface {
 	NewTransactionBatchInsertBuilder(maxBatchSize int) TransactionBatchInsertBuilder
@@ -194,6 +280,8 @@ var selectTransaction = sq.Select(
 		"ht.inner_transaction_hash, " +
 		"ht.fee_account, " +
 		"ht.new_max_fee, " +
-		"ht.inner_signatures").
+		"ht.inner_signatures, " +
+		"ht.trade_count, " +
+		"ht.effect_count").
 	From("history_transactions ht").
 	LeftJoin("history_ledgers hl ON ht.ledger_sequence = hl.sequence")
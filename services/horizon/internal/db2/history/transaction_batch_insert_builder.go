@@ -23,7 +23,7 @@ import (
 // TransactionBatchInsertBuilder is used to insert transactions into the
 // history_transactions table
 type TransactionBatchInsertBuilder interface {
-	Add(transaction io.LedgerTransaction, sequence uint32) error
+	Add(transaction io.LedgerTransaction, sequence uint32, counts TransactionCounts) error
 	Exec() error
 }
 
@@ -42,9 +42,16 @@ func (q *Q) NewTransactionBatchInsertBuilder(maxBatchSize int) TransactionBatchI
 	}
 }
 
+// TransactionCounts are the numbers of records generated by a transaction,
+// they are computed by the ingestion and stored with the transaction.
+type TransactionCounts struct {
+	Trades  int32
+	Effects int32
+}
+
 // Add adds a new transaction to the batch
-func (i *transactionBatchInsertBuilder) Add(transaction io.LedgerTransaction, sequence uint32) error {
-	row, err := transactionToRow(transaction, sequence)
+func (i *transactionBatchInsertBuilder) Add(transaction io.LedgerTransaction, sequence uint32, counts TransactionCounts) error {
+	row, err := transactionToRow(transaction, sequence, counts)
 	if err != nil {
 		return err
 	}
@@ -223,9 +230,11 @@ type TransactionWithoutLedger struct {
 	InnerTransactionHash null.String    `db:"inner_transaction_hash"`
 	NewMaxFee            null.Int       `db:"new_max_fee"`
 	InnerSignatures      pq.StringArray `db:"inner_signatures"`
+	TradeCount           int32          `db:"trade_count"`
+	EffectCount          int32          `db:"effect_count"`
 }
 
-func transactionToRow(transaction io.LedgerTransaction, sequence uint32) (TransactionWithoutLedger, error) {
+func transactionToRow(transaction io.LedgerTransaction, sequence uint32, counts TransactionCounts) (TransactionWithoutLedger, error) {
 	envelopeBase64, err := xdr.MarshalBase64(transaction.Envelope)
 	if err != nil {
 		return TransactionWithoutLedger{}, err
@@ -263,6 +272,8 @@ func transactionToRow(transaction io.LedgerTransaction, sequence uint32) (Transa
 		CreatedAt:        time.Now().UTC(),
 		UpdatedAt:        time.Now().UTC(),
 		Successful:       transaction.Result.Successful(),
+		TradeCount:       counts.Trades,
+		EffectCount:      counts.Effects,
 	}
 	t.TotalOrderID.ID = toid.New(int32(sequence), int32(transaction.Index), 0).ToInt64()
 
@@ -84,7 +84,7 @@ func TestTransactionToMap_muxed(t *testing.T) {
 			},
 		},
 	}
-	row, err := transactionToRow(tx, 20)
+	row, err := transactionToRow(tx, 20, TransactionCounts{})
 	assert.NoError(t, err)
 
 	assert.Equal(t, innerAccountID.Address(), row.Account)
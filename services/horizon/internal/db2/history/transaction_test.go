@@ -1,6 +1,7 @@
 package history
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
@@ -9,8 +10,10 @@ import (
 	"github.com/guregu/null"
 
 	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/services/horizon/internal/test"
 	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/errors"
 )
 
 func TestTransactionQueries(t *testing.T) {
@@ -29,6 +32,65 @@ func TestTransactionQueries(t *testing.T) {
 	tt.Assert.Equal(err, sql.ErrNoRows)
 }
 
+func TestTransactionsForMemo(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	var transactions []Transaction
+	err := q.Transactions().ForMemo("id", "123").Select(&transactions)
+	tt.Require.NoError(err)
+	if tt.Assert.Len(transactions, 1) {
+		tt.Assert.Equal("id", transactions[0].MemoType)
+		tt.Assert.Equal("123", transactions[0].Memo.String)
+	}
+
+	transactions = nil
+	err = q.Transactions().ForMemo("text", "123").Select(&transactions)
+	tt.Require.NoError(err)
+	tt.Assert.Len(transactions, 0)
+
+	err = q.Transactions().ForMemo("none", "").Select(&transactions)
+	tt.Assert.EqualError(err, "invalid memo type: none")
+}
+
+func TestGetTransactions(t *testing.T) {
+	tt := test.Start(t).Scenario("base")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+	page := db2.PageQuery{Order: "asc", Limit: 10}
+
+	transactions, err := q.GetTransactions(context.Background(), TransactionsFilter{}, page)
+	tt.Require.NoError(err)
+	tt.Assert.Len(transactions, 4)
+
+	transactions, err = q.GetTransactions(context.Background(), TransactionsFilter{LedgerSequence: 2}, page)
+	tt.Require.NoError(err)
+	tt.Assert.Len(transactions, 3)
+
+	_, err = q.ExecRaw("UPDATE history_transactions SET trade_count = 2 WHERE id = ?", transactions[1].ID)
+	tt.Require.NoError(err)
+	withTrades, err := q.GetTransactions(context.Background(), TransactionsFilter{MinTrades: 1}, page)
+	tt.Require.NoError(err)
+	tt.Require.Len(withTrades, 1)
+	tt.Assert.Equal(transactions[1].ID, withTrades[0].ID)
+	tt.Assert.Equal(int32(2), withTrades[0].TradeCount)
+
+	// lookup errors are returned immediately
+	_, err = q.GetTransactions(context.Background(), TransactionsFilter{
+		AccountID: "GB5FZF7VGVO5KI5DDWL6VGUX6JDB5SQKTWN4ZUB6NHMUA2YQGWDEHALK",
+	}, page)
+	tt.Assert.Equal(sql.ErrNoRows, errors.Cause(err))
+
+	_, err = q.GetTransactions(context.Background(), TransactionsFilter{MemoType: "none"}, page)
+	tt.Assert.EqualError(err, "invalid memo type: none")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = q.GetTransactions(ctx, TransactionsFilter{}, page)
+	tt.Assert.Error(err)
+}
+
 // TestTransactionSuccessfulOnly tests if default query returns successful
 // transactions only.
 // If it's not enclosed in brackets, it may return incorrect result when mixed
@@ -52,7 +114,9 @@ func TestTransactionSuccessfulOnly(t *testing.T) {
 		tt.Assert.True(transaction.Successful)
 	}
 
-	sql, _, err := query.sql.ToSql()
+	selectSQL, err := q.transactionsSQL(query.filter)
+	tt.Assert.NoError(err)
+	sql, _, err := selectSQL.ToSql()
 	tt.Assert.NoError(err)
 	// Note: brackets around `(ht.successful = true OR ht.successful IS NULL)` are critical!
 	tt.Assert.Contains(sql, "WHERE htp.history_account_id = ? AND (ht.successful = true OR ht.successful IS NULL)")
@@ -85,7 +149,9 @@ func TestTransactionIncludeFailed(t *testing.T) {
 	tt.Assert.Equal(3, successful)
 	tt.Assert.Equal(1, failed)
 
-	sql, _, err := query.sql.ToSql()
+	selectSQL, err := q.transactionsSQL(query.filter)
+	tt.Assert.NoError(err)
+	sql, _, err := selectSQL.ToSql()
 	tt.Assert.NoError(err)
 	tt.Assert.Equal("SELECT ht.id, ht.transaction_hash, ht.ledger_sequence, ht.application_order, ht.account, ht.account_sequence, ht.max_fee, COALESCE(ht.fee_charged, ht.max_fee) as fee_charged, ht.operation_count, ht.tx_envelope, ht.tx_result, ht.tx_meta, ht.tx_fee_meta, ht.created_at, ht.updated_at, COALESCE(ht.successful, true) as successful, ht.signatures, ht.memo_type, ht.memo, time_bounds, hl.closed_at AS ledger_close_time, ht.inner_transaction_hash, ht.fee_account, ht.new_max_fee, ht.inner_signatures FROM history_transactions ht LEFT JOIN history_ledgers hl ON ht.ledger_sequence = hl.sequence JOIN history_transaction_participants htp ON htp.history_transaction_id = ht.id WHERE htp.history_account_id = ?", sql)
 }
@@ -160,10 +226,10 @@ func TestInsertTransactionDoesNotAllowDuplicateIndex(t *testing.T) {
 		hash:          "7e2def20d5a21a56be2a457b648f702ee1af889d3df65790e92a05081e9fabf1",
 	})
 
-	tt.Assert.NoError(insertBuilder.Add(firstTransaction, sequence))
+	tt.Assert.NoError(insertBuilder.Add(firstTransaction, sequence, TransactionCounts{}))
 	tt.Assert.NoError(insertBuilder.Exec())
 
-	tt.Assert.NoError(insertBuilder.Add(secondTransaction, sequence))
+	tt.Assert.NoError(insertBuilder.Add(secondTransaction, sequence, TransactionCounts{}))
 	tt.Assert.EqualError(
 		insertBuilder.Exec(),
 		"error adding values while inserting to history_transactions: "+
@@ -687,7 +753,7 @@ func TestInsertTransaction(t *testing.T) {
 		},
 	} {
 		t.Run(testCase.name, func(t *testing.T) {
-			tt.Assert.NoError(insertBuilder.Add(testCase.toInsert, sequence))
+			tt.Assert.NoError(insertBuilder.Add(testCase.toInsert, sequence, TransactionCounts{}))
 			tt.Assert.NoError(insertBuilder.Exec())
 
 			var transactions []Transaction
@@ -729,13 +795,13 @@ func TestFetchFeeBumpTransaction(t *testing.T) {
 	tt.Assert.Equal(byOuterhash, fixture.Transaction)
 
 	outerOps, outerTransactions, err := q.Operations().IncludeTransactions().
-		ForTransaction(fixture.OuterHash).Fetch()
+		ForTransaction(fixture.OuterHash).Fetch(tt.Ctx)
 	tt.Assert.NoError(err)
 	tt.Assert.Len(outerTransactions, 1)
 	tt.Assert.Len(outerOps, 1)
 
 	innerOps, innerTransactions, err := q.Operations().IncludeTransactions().
-		ForTransaction(fixture.InnerHash).Fetch()
+		ForTransaction(fixture.InnerHash).Fetch(tt.Ctx)
 	tt.Assert.NoError(err)
 	tt.Assert.Len(innerTransactions, 1)
 	tt.Assert.Equal(innerOps, outerOps)
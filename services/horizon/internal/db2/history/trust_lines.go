@@ -6,6 +6,7 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/lib/pq"
+	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
@@ -104,13 +105,12 @@ func (q *Q) InsertTrustLine(trustLine xdr.TrustLineEntry, lastModifiedLedger xdr
 	}
 	m["ledger_key"] = key
 
-	sql := sq.Insert("trust_lines").SetMap(m)
-	result, err := q.Exec(sql)
-	if err != nil {
+	builder := &db.BatchInsertBuilder{Table: q.GetTable("trust_lines")}
+	if err = builder.Row(m); err != nil {
 		return 0, err
 	}
 
-	return result.RowsAffected()
+	return builder.ExecRowsAffected()
 }
 
 // UpdateTrustLine updates a row in the trust lines table.
@@ -0,0 +1,119 @@
+package db2
+
+import (
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// KeysetPager pages through rows ordered by a set of columns (the keyset)
+// using cursors holding the values of these columns for the last row of the
+// previous page.
+//
+// The generated predicates bound the first column with an inclusive
+// comparison so that Postgres can use a multicolumn index on the keyset
+// columns instead of scanning the whole table, remember to test queries with
+// EXPLAIN / EXPLAIN ANALYZE before changing them. For the cursor (a, b) in
+// ascending order the predicate is:
+//
+//	(c1 >= a AND (c1 > a OR (c1 = a AND c2 > b)))
+type KeysetPager struct {
+	// Columns are the columns of the keyset, most significant first.
+	Columns []string
+}
+
+// NewKeysetPager creates a KeysetPager ordering rows by columns, most
+// significant first.
+func NewKeysetPager(columns ...string) KeysetPager {
+	return KeysetPager{Columns: columns}
+}
+
+// Where returns the predicate selecting the rows following cursor in the
+// given order. cursor must have a value for each column of the keyset.
+func (k KeysetPager) Where(order string, cursor ...int64) (sq.Sqlizer, error) {
+	if len(cursor) != len(k.Columns) || len(k.Columns) == 0 {
+		return nil, errors.Errorf(
+			"expected a cursor of %d values, got %d", len(k.Columns), len(cursor),
+		)
+	}
+
+	var cmp string
+	switch order {
+	case OrderAscending:
+		cmp = ">"
+	case OrderDescending:
+		cmp = "<"
+	default:
+		return nil, errors.Errorf("invalid order: %s", order)
+	}
+
+	last := len(k.Columns) - 1
+	if last == 0 {
+		return sq.Expr(k.Columns[0]+" "+cmp+" ?", cursor[0]), nil
+	}
+
+	// (c1 >= ? AND (c1 > ? OR (c1 = ? AND (c2 > ? OR (c2 = ? AND c3 > ?)))))
+	var sql strings.Builder
+	args := make([]interface{}, 0, 2*len(cursor)+1)
+	sql.WriteString("(" + k.Columns[0] + " " + cmp + "= ? AND ")
+	args = append(args, cursor[0])
+	for i, column := range k.Columns[:last] {
+		sql.WriteString("(" + column + " " + cmp + " ? OR (" + column + " = ? AND ")
+		args = append(args, cursor[i], cursor[i])
+	}
+	sql.WriteString(k.Columns[last] + " " + cmp + " ?")
+	args = append(args, cursor[last])
+	sql.WriteString(strings.Repeat("))", last) + ")")
+
+	return sq.Expr(sql.String(), args...), nil
+}
+
+// OrderBy returns the ORDER BY clause sorting rows by the keyset in the
+// given order.
+func (k KeysetPager) OrderBy(order string) (string, error) {
+	if order != OrderAscending && order != OrderDescending {
+		return "", errors.Errorf("invalid order: %s", order)
+	}
+
+	columns := make([]string, len(k.Columns))
+	for i, column := range k.Columns {
+		columns[i] = column + " " + order
+	}
+	return strings.Join(columns, ", "), nil
+}
+
+// ApplyTo returns a new SelectBuilder selecting the rows following cursor
+// sorted by the keyset in the given order.
+func (k KeysetPager) ApplyTo(sql sq.SelectBuilder, order string, cursor ...int64) (sq.SelectBuilder, error) {
+	where, err := k.Where(order, cursor...)
+	if err != nil {
+		return sql, err
+	}
+	orderBy, err := k.OrderBy(order)
+	if err != nil {
+		return sql, err
+	}
+	return sql.Where(where).OrderBy(orderBy), nil
+}
+
+// ApplyStopTo returns a new SelectBuilder selecting the rows preceding
+// cursor in the given order, so that rows can be streamed up to a stop
+// cursor using the same paging tokens as the start cursor.
+func (k KeysetPager) ApplyStopTo(sql sq.SelectBuilder, order string, cursor ...int64) (sq.SelectBuilder, error) {
+	switch order {
+	case OrderAscending:
+		order = OrderDescending
+	case OrderDescending:
+		order = OrderAscending
+	default:
+		return sql, errors.Errorf("invalid order: %s", order)
+	}
+
+	where, err := k.Where(order, cursor...)
+	if err != nil {
+		return sql, err
+	}
+	return sql.Where(where), nil
+}
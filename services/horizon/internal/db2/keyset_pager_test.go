@@ -0,0 +1,88 @@
+package db2
+
+import (
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysetPagerWhere(t *testing.T) {
+	for _, testCase := range []struct {
+		columns  []string
+		order    string
+		cursor   []int64
+		expected string
+		args     []interface{}
+	}{
+		{
+			[]string{"id"}, OrderAscending, []int64{5},
+			"id > ?", []interface{}{int64(5)},
+		},
+		{
+			[]string{"id"}, OrderDescending, []int64{5},
+			"id < ?", []interface{}{int64(5)},
+		},
+		{
+			[]string{"a", "b"}, OrderAscending, []int64{5, 6},
+			"(a >= ? AND (a > ? OR (a = ? AND b > ?)))",
+			[]interface{}{int64(5), int64(5), int64(5), int64(6)},
+		},
+		{
+			[]string{"a", "b"}, OrderDescending, []int64{5, 6},
+			"(a <= ? AND (a < ? OR (a = ? AND b < ?)))",
+			[]interface{}{int64(5), int64(5), int64(5), int64(6)},
+		},
+		{
+			[]string{"a", "b", "c"}, OrderAscending, []int64{5, 6, 7},
+			"(a >= ? AND (a > ? OR (a = ? AND (b > ? OR (b = ? AND c > ?)))))",
+			[]interface{}{int64(5), int64(5), int64(5), int64(6), int64(6), int64(7)},
+		},
+	} {
+		where, err := NewKeysetPager(testCase.columns...).Where(testCase.order, testCase.cursor...)
+		require.NoError(t, err)
+		sql, args, err := where.ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, sql)
+		assert.Equal(t, testCase.args, args)
+	}
+
+	pager := NewKeysetPager("a", "b")
+	_, err := pager.Where(OrderAscending, 1)
+	assert.EqualError(t, err, "expected a cursor of 2 values, got 1")
+	_, err = pager.Where("foo", 1, 2)
+	assert.EqualError(t, err, "invalid order: foo")
+	_, err = NewKeysetPager().Where(OrderAscending)
+	assert.Error(t, err)
+}
+
+func TestKeysetPagerApplyTo(t *testing.T) {
+	pager := NewKeysetPager("a", "b")
+	orderBy, err := pager.OrderBy(OrderDescending)
+	require.NoError(t, err)
+	assert.Equal(t, "a desc, b desc", orderBy)
+	_, err = pager.OrderBy("foo")
+	assert.EqualError(t, err, "invalid order: foo")
+
+	sel, err := pager.ApplyTo(sq.Select("*").From("t"), OrderAscending, 1, 2)
+	require.NoError(t, err)
+	sel, err = pager.ApplyStopTo(sel, OrderAscending, 3, 4)
+	require.NoError(t, err)
+	sql, args, err := sel.ToSql()
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"SELECT * FROM t WHERE (a >= ? AND (a > ? OR (a = ? AND b > ?))) "+
+			"AND (a <= ? AND (a < ? OR (a = ? AND b < ?))) ORDER BY a asc, b asc",
+		sql,
+	)
+	assert.Equal(
+		t,
+		[]interface{}{int64(1), int64(1), int64(1), int64(2), int64(3), int64(3), int64(3), int64(4)},
+		args,
+	)
+
+	_, err = pager.ApplyStopTo(sel, "foo", 3, 4)
+	assert.EqualError(t, err, "invalid order: foo")
+}
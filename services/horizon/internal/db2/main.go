@@ -8,4 +8,8 @@ type PageQuery struct {
 	Cursor string
 	Order  string
 	Limit  uint64
+	// StopCursor, when not empty, bounds the page on the opposite side of
+	// Cursor: in descending order only records after StopCursor are returned
+	// and in ascending order only records before StopCursor.
+	StopCursor string
 }
@@ -47,6 +47,58 @@ func (e *InvalidFieldError) Error() string {
 	return fmt.Sprintf("%s: invalid value", e.Name)
 }
 
+// InvalidCursorError is returned when a cursor cannot be parsed. Reason
+// describes what is wrong with the cursor so it can be presented to the
+// user. The cause of the error is ErrInvalidCursor.
+type InvalidCursorError struct {
+	Reason string
+}
+
+func (e *InvalidCursorError) Error() string {
+	return fmt.Sprintf("cursor: %s", e.Reason)
+}
+
+// Cause returns ErrInvalidCursor so errors.Cause works for all invalid
+// cursors.
+func (e *InvalidCursorError) Cause() error {
+	return ErrInvalidCursor
+}
+
+func invalidCursor(format string, args ...interface{}) error {
+	return &InvalidCursorError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// CursorFormat is the format of cursors accepted by an endpoint.
+type CursorFormat int
+
+const (
+	// CursorFormatInt64Pair accepts two non-negative int64s separated by
+	// DefaultPairSep or a single non-negative int64. See CursorInt64Pair.
+	CursorFormatInt64Pair CursorFormat = iota
+	// CursorFormatInt64 accepts a single non-negative int64. See CursorInt64.
+	CursorFormatInt64
+)
+
+// ValidateCursor checks that cursor can be parsed as the given format. An
+// empty cursor is always valid.
+func ValidateCursor(cursor string, format CursorFormat) error {
+	if cursor == "" {
+		return nil
+	}
+
+	p := PageQuery{Cursor: cursor, Order: OrderAscending}
+	var err error
+	switch format {
+	case CursorFormatInt64:
+		_, err = p.CursorInt64()
+	case CursorFormatInt64Pair:
+		_, _, err = p.CursorInt64Pair(DefaultPairSep)
+	default:
+		err = errors.Errorf("unknown cursor format: %d", format)
+	}
+	return err
+}
+
 // ApplyTo returns a new SelectBuilder after applying the paging effects of
 // `p` to `sql`.  This method provides the default case for paging: int64
 // cursor-based paging by an id column.
@@ -122,18 +174,28 @@ func (p PageQuery) CursorInt64() (int64, error) {
 		}
 	}
 
-	i, err := strconv.ParseInt(p.Cursor, 10, 64)
+	return parseCursorInt64(p.Cursor)
+}
 
+// parseCursorInt64 parses a single non-negative int64 cursor component.
+func parseCursorInt64(value string) (int64, error) {
+	if value == "" {
+		return 0, invalidCursor("empty number")
+	}
+
+	i, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
-		return 0, ErrInvalidCursor
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return 0, invalidCursor("%q is out of range", value)
+		}
+		return 0, invalidCursor("%q is not a number", value)
 	}
 
 	if i < 0 {
-		return 0, ErrInvalidCursor
+		return 0, invalidCursor("%q is a negative number", value)
 	}
 
 	return i, nil
-
 }
 
 // CursorInt64Pair parses this query's Cursor string as two int64s, separated by the provided separator
@@ -152,7 +214,11 @@ func (p PageQuery) CursorInt64Pair(sep string) (l int64, r int64, err error) {
 		return
 	}
 
-	parts := strings.SplitN(p.Cursor, sep, 2)
+	parts := strings.Split(p.Cursor, sep)
+	if len(parts) > 2 {
+		err = invalidCursor("expected at most 2 numbers separated by %q", sep)
+		return
+	}
 
 	// In the event that the cursor is only a single number
 	// we use maxInt as the second element.  This ensures that
@@ -170,23 +236,33 @@ func (p PageQuery) CursorInt64Pair(sep string) (l int64, r int64, err error) {
 		parts = append(parts, fmt.Sprintf("%d", max))
 	}
 
-	l, err = strconv.ParseInt(parts[0], 10, 64)
+	l, err = parseCursorInt64(parts[0])
 	if err != nil {
-		err = errors.Wrap(err, "first component unparseable")
 		return
 	}
 
-	r, err = strconv.ParseInt(parts[1], 10, 64)
-	if err != nil {
-		err = errors.Wrap(err, "second component unparseable")
+	r, err = parseCursorInt64(parts[1])
+	return
+}
+
+// StopCursorInt64Pair parses this query's StopCursor string as two int64s,
+// separated by the provided separator. It uses the same format as
+// CursorInt64Pair so paging tokens can be used in both fields.
+func (p PageQuery) StopCursorInt64Pair(sep string) (l int64, r int64, err error) {
+	if p.StopCursor == "" {
+		err = errors.New("stop cursor is empty")
 		return
 	}
+	return PageQuery{Cursor: p.StopCursor, Order: p.Order}.CursorInt64Pair(sep)
+}
 
-	if l < 0 || r < 0 {
-		err = ErrInvalidCursor
+// StopCursorInt64 parses this query's StopCursor string as an int64. It uses
+// the same format as CursorInt64 so paging tokens can be used in both fields.
+func (p PageQuery) StopCursorInt64() (int64, error) {
+	if p.StopCursor == "" {
+		return 0, errors.New("stop cursor is empty")
 	}
-
-	return
+	return parseCursorInt64(p.StopCursor)
 }
 
 // NewPageQuery creates a new PageQuery struct, ensuring the order, limit, and
@@ -197,6 +273,18 @@ func NewPageQuery(
 	order string,
 	limit uint64,
 ) (result PageQuery, err error) {
+	return NewPageQueryWithMaxLimit(cursor, validateCursor, order, limit, MaxPageSize)
+}
+
+// NewPageQueryWithMaxLimit behaves as NewPageQuery but accepts limits up to
+// maxLimit instead of MaxPageSize.
+func NewPageQueryWithMaxLimit(
+	cursor string,
+	validateCursor bool,
+	order string,
+	limit uint64,
+	maxLimit uint64,
+) (result PageQuery, err error) {
 
 	// Set order
 	switch order {
@@ -212,9 +300,7 @@ func NewPageQuery(
 	// Set cursor
 	result.Cursor = cursor
 	if validateCursor {
-		_, _, err = result.CursorInt64Pair(DefaultPairSep)
-		if err != nil {
-			err = ErrInvalidCursor
+		if err = ValidateCursor(cursor, CursorFormatInt64Pair); err != nil {
 			return
 		}
 	}
@@ -224,7 +310,7 @@ func NewPageQuery(
 	case limit == 0:
 		err = ErrInvalidLimit
 		return
-	case limit > MaxPageSize:
+	case limit > maxLimit:
 		err = ErrInvalidLimit
 		return
 	default:
@@ -2,8 +2,13 @@ package db2
 
 import (
 	"math"
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/quick"
 
+	"github.com/stellar/go/support/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -97,3 +102,134 @@ func TestPageQuery_CursorInt64(t *testing.T) {
 	_, err = p.CursorInt64()
 	assertInstance.Error(err)
 }
+
+func TestPageQuery_StopCursorInt64Pair(t *testing.T) {
+	p := MustPageQuery("", false, "desc", 1)
+	p.StopCursor = "1231-4456"
+	l, r, err := p.StopCursorInt64Pair("-")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1231), l)
+	assert.Equal(t, int64(4456), r)
+
+	p.StopCursor = "1231"
+	_, r, err = p.StopCursorInt64Pair("-")
+	require.NoError(t, err)
+	assert.Equal(t, int64(math.MaxInt64), r)
+
+	p.StopCursor = "foo"
+	_, _, err = p.StopCursorInt64Pair("-")
+	assert.Error(t, err)
+
+	p.StopCursor = ""
+	_, _, err = p.StopCursorInt64Pair("-")
+	assert.EqualError(t, err, "stop cursor is empty")
+}
+
+func TestPageQuery_StopCursorInt64(t *testing.T) {
+	p := MustPageQuery("", false, "desc", 1)
+	p.StopCursor = "1231"
+	i, err := p.StopCursorInt64()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1231), i)
+
+	p.StopCursor = "1231-4456"
+	_, err = p.StopCursorInt64()
+	assert.Error(t, err)
+
+	p.StopCursor = ""
+	_, err = p.StopCursorInt64()
+	assert.EqualError(t, err, "stop cursor is empty")
+}
+
+func TestValidateCursor(t *testing.T) {
+	for _, testCase := range []struct {
+		cursor string
+		format CursorFormat
+		err    string
+	}{
+		{"", CursorFormatInt64, ""},
+		{"", CursorFormatInt64Pair, ""},
+		{"123", CursorFormatInt64, ""},
+		{"123", CursorFormatInt64Pair, ""},
+		{"123-4", CursorFormatInt64Pair, ""},
+		{"123-4", CursorFormatInt64, `cursor: "123-4" is not a number`},
+		{"-123", CursorFormatInt64, `cursor: "-123" is a negative number`},
+		{"-123", CursorFormatInt64Pair, "cursor: empty number"},
+		{"123-", CursorFormatInt64Pair, "cursor: empty number"},
+		{"1-2-3", CursorFormatInt64Pair, `cursor: expected at most 2 numbers separated by "-"`},
+		{"foo-1", CursorFormatInt64Pair, `cursor: "foo" is not a number`},
+		{"1-foo", CursorFormatInt64Pair, `cursor: "foo" is not a number`},
+		{"99999999999999999999", CursorFormatInt64, `cursor: "99999999999999999999" is out of range`},
+		{"1-99999999999999999999", CursorFormatInt64Pair, `cursor: "99999999999999999999" is out of range`},
+		{" 1", CursorFormatInt64, `cursor: " 1" is not a number`},
+	} {
+		t.Run(testCase.cursor, func(t *testing.T) {
+			err := ValidateCursor(testCase.cursor, testCase.format)
+			if testCase.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testCase.err)
+			assert.IsType(t, &InvalidCursorError{}, err)
+			assert.Equal(t, ErrInvalidCursor, errors.Cause(err))
+		})
+	}
+}
+
+// randomCursor generates cursors which are likely to be close to valid ones
+// so all the branches of the parsers are exercised.
+func randomCursor(r *rand.Rand) string {
+	alphabet := []string{"0", "1", "9", "-", "+", " ", "a", ".", "\x00", "9223372036854775807", "9223372036854775808", "é"}
+	parts := make([]string, r.Intn(8))
+	for i := range parts {
+		parts[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return strings.Join(parts, "")
+}
+
+func TestCursorParsingRandomInput(t *testing.T) {
+	config := &quick.Config{
+		MaxCount: 10000,
+		Values: func(values []reflect.Value, r *rand.Rand) {
+			values[0] = reflect.ValueOf(randomCursor(r))
+		},
+	}
+
+	checkInt64 := func(cursor string) bool {
+		for _, order := range []string{OrderAscending, OrderDescending} {
+			p := PageQuery{Cursor: cursor, Order: order}
+			i, err := p.CursorInt64()
+			if err != nil {
+				if errors.Cause(err) != ErrInvalidCursor {
+					return false
+				}
+				continue
+			}
+			if i < 0 {
+				return false
+			}
+		}
+		return true
+	}
+	assert.NoError(t, quick.Check(checkInt64, config))
+	assert.NoError(t, quick.Check(checkInt64, nil))
+
+	checkInt64Pair := func(cursor string) bool {
+		for _, order := range []string{OrderAscending, OrderDescending} {
+			p := PageQuery{Cursor: cursor, Order: order}
+			l, r, err := p.CursorInt64Pair(DefaultPairSep)
+			if err != nil {
+				if errors.Cause(err) != ErrInvalidCursor {
+					return false
+				}
+				continue
+			}
+			if l < 0 || r < 0 {
+				return false
+			}
+		}
+		return true
+	}
+	assert.NoError(t, quick.Check(checkInt64Pair, config))
+	assert.NoError(t, quick.Check(checkInt64Pair, nil))
+}
@@ -34,8 +34,29 @@
 // migrations/39_history_trades_indices.sql (183B)
 // migrations/3_use_sequence_in_history_accounts.sql (447B)
 // migrations/40_fix_inner_tx_max_fee_constraint.sql (392B)
+// migrations/41_trade_retention_policies.sql (550B)
+// migrations/42_history_operations_type_index.sql (177B)
+// migrations/43_history_transactions_memo_index.sql (211B)
+// migrations/44_history_offers.sql (1.066kB)
+// migrations/45_history_account_events.sql (647B)
+// migrations/46_history_operations_source_asset_index.sql (348B)
+// migrations/47_exp_asset_stats_sort_indexes.sql (350B)
+// migrations/48_history_data_quality_violations.sql (730B)
+// migrations/49_partition_history_tables.sql (1.885kB)
 // migrations/4_add_protocol_version.sql (188B)
+// migrations/50_asset_watches.sql (1.181kB)
+// migrations/51_trade_base_is_maker.sql (799B)
+// migrations/52_history_account_thresholds.sql (585B)
+// migrations/53_reingest_checkpoints.sql (556B)
+// migrations/54_history_ledger_manifests.sql (384B)
+// migrations/55_trades_unique_operation_order.sql (540B)
+// migrations/56_exp_state_quarantine.sql (1.059kB)
+// migrations/57_trade_sequence.sql (2.969kB)
+// migrations/58_trust_lines_by_asset_balance.sql (205B)
+// migrations/59_exp_asset_stats_issuer.sql (637B)
 // migrations/5_create_trades_table.sql (1.1kB)
+// migrations/60_transaction_trade_effect_counts.sql (2.573kB)
+// migrations/61_ledger_gap_claims.sql (509B)
 // migrations/6_create_assets_table.sql (366B)
 // migrations/7_modify_trades_table.sql (2.303kB)
 // migrations/8_add_aggregators.sql (907B)
@@ -789,6 +810,186 @@ func migrations40_fix_inner_tx_max_fee_constraintSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations41_trade_retention_policiesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\x91\x41\x4f\xc2\x30\x1c\xc5\xef\xfd\x14\xef\x38\x22\x23\xdc\x11\x93\x39\x6a\x34\xcc\xb1\xd4\x71\xe0\xb4\x8c\xed\x3f\x68\xd4\x76\xf9\xb7\x62\xfc\xf6\xc6\x31\x50\xdc\xc1\x83\xd7\xe6\xd7\x5f\xdf\x7b\x0d\x43\x5c\xbd\xea\x1d\x97\x9e\xb0\x6e\x85\x08\x43\x64\xc4\x28\x9d\x23\x8f\xb6\xd4\x0c\x7b\x20\x66\x5d\x93\x83\x6d\xe0\xf7\x84\xbd\x76\xde\xf2\x07\x98\x3c\x19\xaf\xad\x41\x63\xf9\x74\x5a\x78\x2e\x6b\x72\x93\x2f\xd1\x19\x28\x1c\x55\xd6\xd4\x0e\x73\x4c\xf1\x4c\xd4\xba\x4e\x74\x44\x4f\xda\xee\xb1\xc6\x32\x1d\x88\x27\x22\x56\x32\xca\x25\xf2\xe8\x36\x91\x47\xb0\xf8\xd6\xb5\xf6\x45\x57\x9a\x1c\x02\x01\x00\xdb\xd2\x51\xd1\x25\x2e\x74\x8d\xad\xde\x69\xe3\x91\xae\x72\xa4\xeb\x24\x81\x92\x77\x52\xc9\x34\x96\x4f\xe7\x8c\x1d\xeb\x02\x5d\x8f\xc6\x9d\xa0\xb2\x6f\xc6\x13\xff\xcb\x31\x2c\xfb\x5b\x12\xdf\xcb\x78\x89\x60\x08\xde\xcc\x31\xed\x2d\x99\x7a\x78\x8c\xd4\x06\x4b\xb9\x41\x70\xd1\x6b\x3c\x48\xd9\x5f\xe9\xb5\x97\x23\x5c\x0f\x69\x31\x9a\x09\xf1\xf3\xbb\x17\xf6\xdd\x08\xb1\x50\xab\xec\x8f\x99\x67\xe2\x73\x00\x66\x36\xba\x68\x26\x02\x00\x00")
+
+func migrations41_trade_retention_policiesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations41_trade_retention_policiesSql,
+		"migrations/41_trade_retention_policies.sql",
+	)
+}
+
+func migrations41_trade_retention_policiesSql() (*asset, error) {
+	bytes, err := migrations41_trade_retention_policiesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/41_trade_retention_policies.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xbb, 0x18, 0x73, 0xda, 0x39, 0x1b, 0x8b, 0x72, 0x3f, 0xf4, 0xff, 0x5c, 0x25, 0xc6, 0x93, 0x92, 0x9, 0x44, 0x90, 0x6b, 0xb0, 0x1b, 0xec, 0x59, 0xee, 0x14, 0xab, 0x19, 0x4, 0x3b, 0x76, 0xc0}}
+	return a, nil
+}
+
+var _migrations42_history_operations_type_indexSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd3\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\xe2\x72\x0e\x72\x75\x0c\x71\x55\xf0\xf4\x73\x71\x8d\x50\xc8\xcc\x4b\x49\xad\x88\xcf\xc8\x2c\x2e\xc9\x2f\xaa\x8c\xcf\x2f\x48\x05\x2a\xcb\xcc\xcf\x2b\x8e\xcf\xcf\x8b\x2f\xa9\x2c\x48\x8d\xcf\x4c\x51\xf0\xf7\x53\xc0\x54\xa0\x10\x1a\xec\xe9\xe7\xae\xe0\x14\x12\xe4\xea\xaa\x01\x52\xa9\xa3\x90\x99\xa2\x69\xcd\xc5\xa5\x8b\x64\x9d\x4b\x7e\x79\x1e\x17\x97\x4b\x90\x7f\x00\xf1\xd6\x59\x73\x01\x00\x45\xa7\x1c\xf4\xb1\x00\x00\x00")
+
+func migrations42_history_operations_type_indexSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations42_history_operations_type_indexSql,
+		"migrations/42_history_operations_type_index.sql",
+	)
+}
+
+func migrations42_history_operations_type_indexSql() (*asset, error) {
+	bytes, err := migrations42_history_operations_type_indexSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/42_history_operations_type_index.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xbc, 0x77, 0xf2, 0xf9, 0x11, 0xbd, 0x21, 0xe8, 0xe8, 0xf1, 0xc7, 0x89, 0x47, 0x90, 0xe8, 0xa1, 0x61, 0x55, 0xb, 0x76, 0xe2, 0xe, 0x46, 0x22, 0x9e, 0x67, 0x6f, 0xb2, 0xcb, 0xb6, 0x7f, 0xda}}
+	return a, nil
+}
+
+var _migrations43_history_transactions_memo_indexSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\x8e\xb1\x0e\x82\x30\x14\x45\xf7\xf7\x15\x77\xd4\x08\x5f\xc0\xa4\xf2\xa2\x24\xa4\x35\xa5\x8d\x6e\x0d\x91\x06\x3b\xd0\x12\x68\xa2\xfc\xbd\xc1\xc9\xc1\xc1\xed\xdc\x9c\x3b\x9c\x3c\xc7\x6e\xf0\xfd\xd4\x26\x07\x33\x12\x1d\x15\xef\x35\xa3\x12\x25\xdf\xe0\x43\xe7\x5e\xf6\xe1\xe7\x14\xa7\xc5\xa6\xa9\x0d\x73\x7b\x4f\x3e\x86\xd9\xc6\x60\x07\x37\x44\x48\x81\x5f\x1e\xa6\xa9\xc4\x09\x07\xad\x98\x37\xeb\xd1\xa6\x65\x74\x19\x56\xcc\xe0\xbb\x2d\xae\x67\x56\xfc\xd9\xa8\x1a\x08\xa9\x21\x4c\x5d\x17\x44\xf9\x57\x51\x19\x9f\x81\xa8\x54\xf2\xf2\x77\x51\x41\x6f\x53\x15\xc5\x65\xd3\x00\x00\x00")
+
+func migrations43_history_transactions_memo_indexSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations43_history_transactions_memo_indexSql,
+		"migrations/43_history_transactions_memo_index.sql",
+	)
+}
+
+func migrations43_history_transactions_memo_indexSql() (*asset, error) {
+	bytes, err := migrations43_history_transactions_memo_indexSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/43_history_transactions_memo_index.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd7, 0x39, 0xe7, 0x8, 0x7e, 0x1, 0xb, 0xd6, 0x5f, 0xbc, 0x9d, 0xc8, 0x73, 0xe5, 0xda, 0xda, 0x77, 0x65, 0xc2, 0xa2, 0xc1, 0x9d, 0xa8, 0x61, 0xb, 0xda, 0xa4, 0x83, 0xe9, 0xd0, 0x8c, 0x5e}}
+	return a, nil
+}
+
+var _migrations44_history_offersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\x93\x41\x6f\xa3\x30\x10\x85\xef\xfe\x15\x73\x4c\xd4\x90\xdb\xee\x25\xea\x81\x6e\xe8\x2a\xda\x94\x54\x94\x48\xdb\x13\x32\x78\x00\x4b\xc6\x4e\x6d\x93\x2c\xff\x7e\x6d\x48\xd2\x06\xa5\x4d\x11\x07\xf0\x3c\x3e\xbf\x99\x67\x82\x00\xee\x1a\x5e\x69\x6a\x11\xb6\x3b\x42\x82\x00\x5e\x24\xdd\x99\x5a\x59\x03\xaa\x74\x77\x89\xda\x00\xb5\x60\x6b\x04\x94\xcc\x2f\xe2\x1e\x75\x07\x02\x59\x85\x1a\xb8\x84\x43\xcd\x8b\xda\x0b\x3a\x38\xa0\x46\x28\x34\x3a\x1e\x9b\x79\x5a\xbb\x63\xfe\x19\x94\x06\x8d\x8d\xda\x23\x9b\x43\x08\xe6\xb8\x07\x70\x03\x7b\x2a\x38\x83\x52\xab\x06\xb8\xdb\x74\xc0\x66\x06\xdf\x5a\x94\x05\x42\x2b\x2d\x17\x1e\xee\x69\xe3\xa2\x33\xe3\x7d\x49\xfc\x67\xdf\x99\xc7\x45\x43\x1b\x1c\xfc\xcf\x21\x19\xb6\x3e\xb7\xa3\x7b\x9a\xc6\x42\x69\xe6\x96\x0f\xdc\xf6\xfe\xb9\x06\x41\x8d\x43\x59\x3f\x0f\xea\xba\x65\x28\xd0\xdb\xbf\x07\xab\x5b\x9c\x93\x5f\x49\x14\xa6\x11\xa4\xe1\xc3\x3a\x82\x9a\x1b\xab\x74\x97\x1d\xa9\x13\x02\xee\xea\x5f\x32\xd7\x51\xce\x2b\x2e\x2d\xc4\x9b\x14\xe2\xed\x7a\x3d\xeb\xab\xe3\x06\x9c\x02\xfd\x14\x2f\x55\x06\x85\x18\x20\x45\x4d\x35\x2d\xac\x53\xec\xa9\xee\xb8\xac\x26\x3f\x7e\x4e\xaf\xa8\x5d\x25\xa3\xc6\xa0\xcb\xc9\xcf\xe2\x52\x90\xb7\xdd\x97\x75\xda\x28\x37\xe5\xeb\x86\x77\x9a\x17\x28\x3f\xf1\xd9\x17\xd9\x57\x45\x60\xaa\xcd\x05\xba\x17\x2c\xb8\xe1\x4a\x8e\x44\xa5\xa0\x95\xf9\x04\x70\x9a\x7d\xae\x94\x40\xfa\xfe\x25\x2c\xa3\xc7\x70\xbb\x4e\xa1\xa4\xc2\xe0\xa0\x7d\x4e\x56\x4f\x61\xf2\x0a\x7f\xa2\x57\x98\x9c\x22\x98\x8d\xc7\x3d\x25\xd3\x05\x39\x65\xb8\x8a\x97\xd1\xdf\x51\x86\x59\xde\x65\xc7\x73\xbd\x89\xc7\xf9\x6e\x5f\x56\xf1\x6f\x78\x48\x93\x28\x9a\x8c\xc1\x8b\x5b\xd4\x21\xd2\x1b\xd4\x73\xee\xb7\x79\x7d\x98\xe6\x1b\xbc\x73\xf2\xb3\x8b\x73\xe0\x07\x11\x7c\xf8\xf9\x97\xea\x20\x09\x59\x26\x9b\xe7\xab\x87\x7b\x41\xfe\x03\x35\x38\x05\x51\x2a\x04\x00\x00")
+
+func migrations44_history_offersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations44_history_offersSql,
+		"migrations/44_history_offers.sql",
+	)
+}
+
+func migrations44_history_offersSql() (*asset, error) {
+	bytes, err := migrations44_history_offersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/44_history_offers.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x53, 0x8c, 0x2e, 0x9b, 0xc9, 0x40, 0x65, 0xf0, 0x84, 0xb6, 0x28, 0x2b, 0xe1, 0xd7, 0xf4, 0xdb, 0x78, 0x47, 0x4e, 0x67, 0xa1, 0x6a, 0xaa, 0x2, 0xf2, 0x92, 0xd4, 0x3b, 0xe7, 0x2f, 0x9c, 0x7f}}
+	return a, nil
+}
+
+var _migrations45_history_account_eventsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\x92\x5f\x4b\xc3\x30\x14\xc5\xdf\xf3\x29\xee\x63\x8b\xeb\x40\x41\x1f\x1c\x3e\x74\x2e\xc8\x70\x76\xa3\x76\xe0\x9e\x4a\x96\xde\x76\x81\x36\x19\x69\x36\xe9\xb7\x37\xfd\x93\x32\xc5\x89\x79\xbc\xf7\xe4\x77\xcf\x3d\x49\x10\xc0\x4d\x25\x0a\xcd\x0c\xc2\xf6\x48\x48\x10\x40\xc8\xb9\x3a\x49\x03\xa5\xc8\x91\x37\xbc\x44\xc0\x33\x4a\x53\x3f\x02\xd7\xc8\x8c\x50\x12\x3c\xd3\x1c\x11\x9e\xe0\xd6\x07\x26\x33\xa8\x50\x17\x38\x16\xef\xfc\x69\x8b\xd1\x58\x5a\x68\x96\xb2\x01\x27\x6a\x30\x07\x84\xfc\x24\x33\xd4\xa0\x72\x60\x3d\x0f\x33\x70\x12\xa5\x3b\x49\x86\xb5\x11\xb2\x9f\xa4\xf2\x96\xc5\xfa\x11\xa3\x72\x02\xac\xba\x84\xd6\x86\x69\x7b\xa5\x80\x3d\x2b\x99\xe4\xe8\x48\xc3\xad\xa1\x3a\x25\xcf\x31\x0d\x13\x0a\x49\x38\x5f\x51\x38\x88\xda\x28\xdd\x38\x83\x69\xbf\x25\x78\x04\xec\x71\x4d\x75\x44\xdd\x39\x49\x85\xe5\x88\x42\xd8\xa1\x9b\x78\xf9\x16\xc6\x3b\x78\xa5\xbb\x49\x27\x76\xfe\xf9\x81\x69\xc6\x8d\x5d\xef\xcc\x74\x63\xfd\x78\xf7\x0f\x3e\x44\xeb\x04\xa2\xed\x6a\xd5\x6b\xbb\x90\xea\x8a\x95\x65\x8b\xfa\xde\xfb\x19\xd9\x7f\x78\x43\x10\x83\x35\xd7\x23\xfe\x8c\xb8\x6d\x97\xd1\x82\x7e\x5c\xd9\x36\xdd\x8f\x15\x58\x47\xd7\x22\xd9\xbe\x2f\xa3\x17\x98\x27\x31\xa5\xde\xf8\x02\xbf\x25\xd4\x4e\x0d\x2e\x7e\xd4\x42\x7d\x4a\x42\x16\xf1\x7a\xf3\x67\xe6\x33\xf2\x05\xdf\xeb\x4b\xbd\x87\x02\x00\x00")
+
+func migrations45_history_account_eventsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations45_history_account_eventsSql,
+		"migrations/45_history_account_events.sql",
+	)
+}
+
+func migrations45_history_account_eventsSql() (*asset, error) {
+	bytes, err := migrations45_history_account_eventsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/45_history_account_events.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xdd, 0x37, 0xac, 0xe2, 0xf, 0xb7, 0x33, 0x8d, 0x6d, 0xda, 0x59, 0xe6, 0x96, 0x34, 0xf1, 0x66, 0x29, 0x2c, 0x8a, 0xe3, 0x2f, 0x31, 0x2c, 0x51, 0x83, 0xfc, 0x42, 0xd1, 0x57, 0xf4, 0x11, 0x5a}}
+	return a, nil
+}
+
+var _migrations46_history_operations_source_asset_indexSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa5\x90\x41\x0b\x82\x40\x14\x84\xef\xef\x57\xbc\x9b\x4a\x7a\xa8\x8e\x82\x50\xb9\x94\x17\x0d\x53\xea\xb6\x2c\xfa\xc8\x85\xdc\x15\x77\xa5\xfc\xf7\xd9\x21\x10\x82\x3a\x34\x87\x39\x7d\xc3\x30\x13\x04\xb8\x68\xe5\xb5\x17\x96\xb0\xec\x00\x76\x39\xdb\x14\x0c\x93\x34\x66\x17\x94\xaa\xa6\x07\x6f\xa4\xb1\xba\x1f\xb9\xee\x68\xc2\xa4\x56\x86\x6b\xc5\x3b\x61\x9b\xc9\xc6\x96\x94\xe5\x46\x0f\x7d\x45\x5c\x18\x43\x16\xb3\x14\x3f\x23\x58\x9e\x92\x74\x8f\xdb\x22\x67\xcc\x05\x9c\xe4\xd6\x64\x85\xbc\x99\x20\x8a\x9c\x79\x9e\xdb\xb1\x23\xc7\xf3\xbf\x43\x95\xae\x7f\x43\xd2\x98\x81\xfa\x37\x26\x6b\xf0\xf0\x7c\x60\x39\xc3\x57\xc5\xb4\x11\xdd\x95\x8f\xcb\xb5\x17\x02\x04\xb3\x1f\x62\x7d\x57\x00\x71\x9e\x1d\xff\xf9\x21\x84\x27\xbb\x60\x9b\x00\x5c\x01\x00\x00")
+
+func migrations46_history_operations_source_asset_indexSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations46_history_operations_source_asset_indexSql,
+		"migrations/46_history_operations_source_asset_index.sql",
+	)
+}
+
+func migrations46_history_operations_source_asset_indexSql() (*asset, error) {
+	bytes, err := migrations46_history_operations_source_asset_indexSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/46_history_operations_source_asset_index.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x87, 0xc, 0x12, 0xb9, 0x63, 0x44, 0x5d, 0x4e, 0x9c, 0x11, 0xaa, 0x5f, 0x46, 0x38, 0x60, 0x2b, 0x8, 0xe4, 0x34, 0x98, 0xba, 0xeb, 0x84, 0xd1, 0xf5, 0x1, 0x37, 0x4f, 0xd0, 0xd5, 0x59, 0x72}}
+	return a, nil
+}
+
+var _migrations47_exp_asset_stats_sort_indexesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd3\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\xe2\x72\x0e\x72\x75\x0c\x71\x55\xf0\xf4\x73\x71\x8d\x50\x48\xad\x28\x88\x4f\x2c\x2e\x4e\x2d\x89\x2f\x2e\x49\x2c\x29\x8e\x4f\xaa\x8c\xcf\x2b\xcd\x8d\x4f\x4c\x4e\xce\x2f\xcd\x2b\x29\x56\xf0\xf7\x43\x57\xa2\x10\x1a\xec\xe9\xe7\xae\xe0\x14\x12\xe4\xea\xaa\x81\xac\x56\x47\x01\xa2\x2a\x39\x3f\x25\x15\xc6\xce\x2c\x2e\x2e\x4d\x2d\xd2\xb4\x26\x68\x69\x62\x2e\xc8\x0c\x42\xd6\x69\x40\x94\x59\x59\x01\xed\x4d\x2d\xca\x4c\xd6\xc4\x6b\x27\x97\x2e\x92\xcf\x5d\xf2\xcb\xf3\xb8\xb8\x5c\x82\xfc\x03\x88\xf3\xb9\x35\x01\xb5\x10\x97\x58\x73\x01\x00\x83\xbb\xee\xe1\x5e\x01\x00\x00")
+
+func migrations47_exp_asset_stats_sort_indexesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations47_exp_asset_stats_sort_indexesSql,
+		"migrations/47_exp_asset_stats_sort_indexes.sql",
+	)
+}
+
+func migrations47_exp_asset_stats_sort_indexesSql() (*asset, error) {
+	bytes, err := migrations47_exp_asset_stats_sort_indexesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/47_exp_asset_stats_sort_indexes.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd4, 0x5d, 0x72, 0x41, 0x81, 0x38, 0xff, 0xcf, 0xb0, 0x71, 0x5e, 0x77, 0x17, 0x51, 0xeb, 0xf9, 0xf8, 0xab, 0x82, 0x10, 0x6e, 0xd9, 0x9a, 0x33, 0x44, 0xb8, 0x69, 0xf, 0x64, 0x3b, 0x67, 0x15}}
+	return a, nil
+}
+
+var _migrations48_history_data_quality_violationsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\x92\xc1\x6e\xc2\x30\x10\x44\xef\xfe\x8a\x39\x06\x95\x70\xaa\x7a\xe1\x04\x25\xaa\x90\x50\xa8\x68\xa8\x7a\x8b\x96\x64\x9b\x58\x4d\x6c\xb0\x1d\x68\xfe\xbe\x49\x0a\x22\xaa\xa8\x00\x1f\x2c\x79\x35\xbb\x33\xcf\xb6\xef\xe3\xa1\x94\x99\x21\xc7\x58\x6f\x85\xf0\x7d\xbc\x4b\x5d\x90\x93\x5a\x59\xe8\x4f\xb8\x9c\x91\x92\x23\xec\x2a\x2a\xa4\xab\x61\xaa\x82\x2d\x92\x9c\x93\x2f\x4e\x71\xc8\x59\x41\xaa\x8c\xad\x6b\x76\x38\x43\xca\x52\xd2\x75\x8f\xda\x61\x93\x7e\x09\x09\x29\xec\xbb\xf1\x8c\xb2\x2a\x9c\xdc\x16\x7c\x1c\xe8\x69\xd3\x79\x59\x2a\x7f\x4b\x67\x81\x93\x25\xdb\x41\x3b\xcd\x6a\x18\x7d\xb0\x20\xc3\x50\xda\xa1\x52\x72\x57\xf1\x48\x3c\xaf\x82\x49\x14\x20\x9a\x4c\x17\x01\x72\x69\x9d\x36\x75\xdc\xa6\x8e\x8f\xa9\xe3\xfd\x19\xca\x13\x68\xd6\x49\xd5\x8b\x17\xcb\x14\x1b\x99\x49\xe5\x10\x2e\x23\x84\xeb\xc5\x62\xd8\x69\xfb\x9a\x9c\x6c\xde\xd0\x93\x69\xce\x6c\xb0\x27\x53\x37\xe0\xde\xd3\xe3\xe0\x4f\x53\xc7\x70\x8b\x30\x65\x47\xb2\xb0\x70\xfc\x7d\x36\x16\x83\xb1\x38\x61\xcd\xc3\x59\xf0\x71\x0d\x2b\xde\xd4\x6d\xfe\x65\x78\x95\x7f\xfd\x36\x0f\x5f\x30\x8d\x56\x41\xe0\x5d\xbe\x85\xc6\xfb\x5e\xeb\x8e\xf6\x4e\xf3\xb6\x67\xf8\xcf\x43\xb4\xf8\x7e\xef\x6f\xce\xf4\x41\x09\x31\x5b\x2d\x5f\x6f\x7b\xe5\xb1\xf8\x01\xb5\xfc\x7b\xc6\xda\x02\x00\x00")
+
+func migrations48_history_data_quality_violationsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations48_history_data_quality_violationsSql,
+		"migrations/48_history_data_quality_violations.sql",
+	)
+}
+
+func migrations48_history_data_quality_violationsSql() (*asset, error) {
+	bytes, err := migrations48_history_data_quality_violationsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/48_history_data_quality_violations.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd8, 0x74, 0x72, 0xcb, 0xae, 0x56, 0x8b, 0xb7, 0x1c, 0x9e, 0xff, 0x61, 0x9f, 0x71, 0x97, 0x88, 0x4, 0x4e, 0xf1, 0x2a, 0x2d, 0xc4, 0x29, 0x80, 0xf1, 0x16, 0x32, 0x24, 0x70, 0xcb, 0x23, 0x99}}
+	return a, nil
+}
+
+var _migrations49_partition_history_tablesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\x54\xd1\x6e\xa3\x3a\x10\x7d\xe7\x2b\xe6\xa1\x15\x70\x37\xdd\xdb\xde\xfb\x96\xa4\x95\x28\x71\x5a\xb4\x94\x54\x40\x76\x7b\x9f\x10\x09\x0e\xb5\x96\x18\xd6\x36\xdd\xf6\x6a\x3f\x7e\xc7\x86\x26\xb4\x4d\xb5\x45\x4a\x64\x66\xec\x33\x67\xce\x19\x7c\x72\x02\x9f\xb6\xac\x14\xb9\xa2\xb0\x6c\x2c\xeb\xe4\x04\x9a\x5c\x28\xa6\x58\xcd\xb3\x7b\x26\x55\x2d\x9e\x32\x95\xaf\x2a\x0a\xeb\x9a\x3f\x50\xa1\x24\xe4\xd0\x27\xa0\x4b\xa8\x1a\x43\xdd\x72\x77\x96\x16\xb0\x7a\xd2\x68\x22\xe7\x25\x95\x50\x6f\x40\xdd\xeb\xad\xac\x40\xa0\xaa\xdd\x72\xf8\x4e\x9f\xb2\x6e\xf9\x19\x52\xcc\xd1\x47\x44\x65\xbc\x04\x51\xff\xc4\x22\x82\xe2\x8e\x46\x01\xe3\x88\x2e\x31\x5e\xd1\x17\xec\x10\x06\xe9\xe8\xfd\x1a\xb8\xa2\x45\x49\x85\x84\xb6\xd1\x74\x74\x84\xd3\x47\x05\xdb\xb6\x52\xac\x41\x62\x58\x7f\xdf\x57\xbf\x59\xc3\xe5\xbc\x00\x9e\x6f\x91\xee\xd4\x74\x90\xe9\x97\x8b\xec\x34\x9b\x52\xcc\x74\x1b\x2f\x46\xb0\x69\x05\x62\x8a\x3d\x46\x47\x70\x2d\x28\x0a\xf7\xdc\xea\x7d\x2d\xd8\xff\x48\x6c\x45\x37\x35\x26\x99\x6e\x5c\xf5\x04\x99\x80\x0d\x13\x52\xf5\x90\x5d\xc7\x8c\x17\xf4\x71\x20\x8e\x91\x10\x71\x8d\x6e\xb4\x95\x06\xd9\xa4\xf6\x5d\xaf\x5a\x35\xd8\xcc\x24\xc8\x75\xce\xb5\xdc\x35\x5f\x1b\x2b\x1e\xf2\x8a\x15\xda\x4e\x86\x56\x69\x29\x3f\x6b\x38\x5d\x6e\xd3\xf2\xb5\xc1\x28\x6a\x2c\xca\x6b\x75\xaf\xc9\xb1\xcd\x4b\xbc\xbc\xc2\xa6\x8a\xa7\x17\x56\x6a\x95\x04\xfd\xd1\x32\x41\x8d\x6a\x4d\x2d\x55\x89\x6b\x38\x3b\x83\x5a\x40\x85\xe5\x84\x29\xb3\x9b\xa5\x44\xe1\xff\x96\x72\x75\x49\x4b\xc6\x2d\x3f\x26\x5e\x4a\x60\x11\x43\x4c\x6e\x43\xcf\x27\x30\x5f\x46\x7e\x1a\x2c\xa2\xf7\xc6\xcd\xd9\xdb\x01\x0a\xad\x1c\x0d\xe6\xa5\x0f\xbc\x31\x14\x56\x0c\x8b\x29\xd7\x8a\x49\xba\x8c\xa3\x04\x1e\xf4\xb8\x79\x09\x1c\x1d\x59\x33\xe2\x87\x5e\x4c\x2c\xc0\xa7\xca\xa5\xea\xcf\xf4\x47\x26\x26\x8e\x96\x1f\x0a\xef\xeb\xec\xd8\x4c\xac\x4b\x72\x15\x44\x26\x1d\xcc\x61\xdd\x0a\x81\xbd\x66\x92\x2a\x6d\xb8\x63\x4b\x2a\x70\x38\x33\xfc\x49\x73\xae\xdd\xda\xee\x78\x8c\x88\x30\x45\xcd\x4e\xf1\x81\xf4\x9a\x74\xe7\xf5\x13\x7b\x41\x42\x80\xdc\xf9\xe4\xd6\x88\x62\xef\x6a\x6a\x8b\x8e\x77\xe2\x1f\x54\xde\x1e\xc1\x5e\xac\x8e\x32\x89\x66\xc8\x6b\x62\x3d\x13\x74\x12\x12\x12\x3f\x45\x9c\xea\x3b\x4e\x1d\xcc\xe3\xc5\x0d\x34\x65\xb6\x46\x29\x24\x7c\xbb\x26\x31\x01\xad\xd5\xf9\x00\x69\x3c\x16\xb4\x34\x1b\x5c\x8c\xdb\x8d\xfd\x8a\xb2\xd1\xf8\x40\x39\x72\x47\xfc\x25\x9a\x8d\x1f\xc1\x36\x57\x8e\xdd\x97\xf6\x17\x5e\x48\x12\x9f\x38\x37\xde\x9d\x73\x1c\xb8\x23\x38\x75\xe1\xe2\x02\xfe\xfd\xa7\x63\x73\x1c\xd8\x43\x8f\x87\x3d\xb9\x10\x44\xe9\x62\x68\xdb\x1b\xbf\xc6\xe7\xe0\x0c\x6d\xfd\xfb\xc0\x74\x7c\x82\x33\x17\xfe\x7a\x9b\x38\xe8\xf2\x78\x28\x05\xfc\xfa\x05\x36\x5e\x0a\xb6\x5e\xec\xab\xbe\xd3\xb0\x17\xa6\x24\x86\xd4\xbb\x0c\x09\x76\x85\x42\x45\xde\x0d\x01\x6c\xc0\xb4\xb8\x47\x1d\xbd\xaa\xe9\x4e\x0e\xc1\xed\x04\xb7\xfb\x8f\x68\x07\xec\x84\xc1\x17\xb3\x08\x22\x3f\x5c\xce\x82\xe8\x0a\x66\x64\xee\x2d\xc3\x34\x19\x84\x82\x68\x86\x88\x89\x0b\xb7\x5e\x9c\x06\x66\xba\x2e\xff\xc3\x81\x8b\xae\x08\x68\x1f\xec\xd1\xae\xc2\xfb\xd4\x86\xce\x98\xed\x7f\xe2\xfa\x4a\x03\x2f\x4d\x3d\xff\x7a\x40\x01\x63\x73\xbc\x0d\xbe\x7a\xe1\x92\x24\xdd\x00\x38\x37\x41\x64\xde\x5d\xad\x95\x73\x2c\x3f\x4a\x6d\x30\x06\xd3\x29\xce\xd3\x33\x41\x9c\xca\x89\x75\x74\x04\x21\xf6\xba\xf4\xb0\xdd\xa6\x6a\x4a\xf9\xa3\x9a\x1c\xbe\xa9\x08\x2f\xac\x17\x99\x59\xfd\x93\x5b\xd6\x2c\x5e\xdc\x7e\xe0\xb6\x32\x37\x52\xf7\xdf\xdf\x42\x13\xeb\x37\x03\x94\xed\xfc\x5d\x07\x00\x00")
+
+func migrations49_partition_history_tablesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations49_partition_history_tablesSql,
+		"migrations/49_partition_history_tables.sql",
+	)
+}
+
+func migrations49_partition_history_tablesSql() (*asset, error) {
+	bytes, err := migrations49_partition_history_tablesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/49_partition_history_tables.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa4, 0xe8, 0xb2, 0x55, 0x20, 0xe0, 0xbb, 0x58, 0xe2, 0x4c, 0xa3, 0x9c, 0x71, 0xdb, 0xeb, 0x16, 0x29, 0x70, 0xda, 0xe2, 0x9a, 0xb6, 0x69, 0x4b, 0x91, 0x44, 0xa3, 0x2, 0x64, 0xa9, 0x44, 0x30}}
+	return a, nil
+}
+
 var _migrations4_add_protocol_versionSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\xcd\xb1\x0a\xc2\x30\x10\x06\xe0\x3d\x4f\xf1\xef\x52\x70\xef\x14\x4d\x9d\xce\x44\x4a\x32\x38\x15\xd1\xa3\x06\x6a\xae\x5c\x82\xe2\xdb\xbb\xba\x88\x4f\xf0\x75\x1d\x36\x8f\x3c\xeb\xa5\x31\xd2\x6a\x2c\xc5\x61\x44\xb4\x3b\x1a\x10\x3c\x9d\x71\xcf\xb5\x89\xbe\xa7\x85\x6f\x33\x6b\x85\x01\xac\x73\xd8\x07\x4a\x47\x8f\x55\xa5\xc9\x55\x96\xe9\xc9\x5a\xb3\x14\xe4\xd2\x78\x66\x85\x1b\x0e\x36\x51\xc4\x16\x3e\x44\xf8\x44\xd4\x1b\xf3\x6d\x39\x79\x95\xff\x9a\x1b\xc3\xe9\x97\xd5\x9b\x4f\x00\x00\x00\xff\xff\x83\xbb\x30\x2e\xbc\x00\x00\x00")
 
 func migrations4_add_protocol_versionSqlBytes() ([]byte, error) {
@@ -809,6 +1010,206 @@ func migrations4_add_protocol_versionSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations50_asset_watchesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6d\x94\x51\x6f\xda\x30\x10\xc7\xdf\xf3\x29\xee\x8d\xa0\x85\xaa\x9b\xb4\x3d\xac\xda\x24\x0a\xd9\x86\xca\x42\xc5\x40\x5a\x9f\x22\x27\xb9\x24\x16\xc6\x46\xb6\xd3\x94\x7e\xfa\x9e\x63\x5a\xd2\x94\x3c\x20\xe5\x7c\xf7\xbb\xcb\xff\x7f\x66\x32\x81\x4f\x7b\x5e\x69\x66\x11\xb6\x87\x20\x98\x4c\x60\x53\x6b\x34\xb5\x12\x85\x01\x25\xc1\xd6\x08\x56\x37\xc6\x82\xe0\x12\x29\x54\x02\x93\xc0\x8c\x41\x0b\x1a\x2b\x6e\x2c\x6a\x2c\x20\x3b\x02\xb7\x06\xb8\x31\x0d\xea\x2b\x62\xa0\x43\xb5\x98\xd5\x4a\xed\x28\x0c\x52\x59\x5e\x72\xca\x6c\x6b\x3c\x51\x5f\xfb\xb8\x63\x7c\xca\x11\x0b\x3a\x66\x25\x01\x81\xcb\x0a\x8d\xa5\x5f\x60\x8e\x23\xb0\xa8\x50\x47\x34\x08\xaf\x2a\xd7\x2f\xf5\x11\x57\xe9\x50\xa7\xb7\xb6\xe6\x79\x7d\x46\x71\x4b\xa3\x76\x74\x6a\x44\xe3\xba\xce\x8e\xe6\x2a\x1e\x99\x68\x10\x4a\x26\x84\x81\x8c\xe5\x3b\xb0\x0a\x94\x86\x0c\x85\x6a\x07\xd3\x19\xd5\x05\x5a\x66\x09\x5e\x72\x0a\x03\xab\x18\x97\x57\xc4\x72\xb8\x1d\xf7\x4d\x94\x44\x52\xe7\xbb\x0b\x01\xb8\x4a\xd4\x06\xfc\xe3\xea\x65\xb3\xcf\x68\x46\xa7\x5f\x63\x6b\xa5\xf9\x33\x8d\xd8\x57\xd6\xcf\x6d\xce\x9d\xfb\xa4\xd4\xd4\x4c\xa3\x27\x65\x4c\x30\x99\x63\x87\x02\x43\x22\x89\xbe\x45\x1f\x39\x91\x17\x71\xf8\x94\x9a\xe5\x96\x93\xc5\xc4\x71\x58\xb6\x57\x8d\xb4\x6f\x6f\x9d\xc3\x35\x8a\xce\x5b\xd2\xa9\x3f\x6b\x30\x5b\xc7\xd3\x4d\x0c\x9b\xe9\xed\x32\xf6\xa9\x69\xa7\x0f\x7d\x46\x18\x38\x38\xa7\x32\x5e\x19\xd4\x9c\x09\xb8\x5f\x2f\xfe\x4e\xd7\x0f\x70\x17\x3f\x44\xdd\xa9\xaf\xb0\xc7\x03\x92\xd3\x16\x92\xd5\x06\x92\xed\x72\xd9\x3f\xcc\x55\x81\x90\xd3\x47\xd3\x90\x24\xdb\x23\xd3\x47\xfa\xd2\xf0\xf3\x97\xf1\xc5\x74\xbf\x78\x17\x0a\xbe\x7e\x1b\x16\x74\x7e\x5d\x20\xf7\x12\x61\xf6\x27\x9e\xdd\x41\xd8\xa5\x2e\x12\x08\x47\x27\x3f\x47\x11\x8c\xfa\x86\x8c\xc6\x63\x0f\x3d\xaf\x4b\xa1\x9a\x8c\x0c\x39\x68\xcc\xb9\x71\xf2\x0e\xa1\xe7\xd4\x9f\x3f\xe0\xfa\x54\x7f\xba\x29\x69\xa3\x49\x68\x7c\x1a\x6a\xf2\x71\xf1\xa5\x45\x77\x25\xba\xd3\x5c\x23\xdd\xdf\x22\x65\x16\x2c\xdf\xd3\xc5\x61\xfb\x03\xb4\x9c\xb6\xac\xf1\x11\x78\x76\xcb\xf9\x36\xc8\x3c\xfe\x35\xdd\x2e\x37\x74\x27\xdb\x70\x1c\x8c\x6f\x82\x57\x3f\x17\xc9\x3c\xfe\xff\xde\xcf\x34\x3b\xa6\x7e\x17\x56\xc9\xc0\xe9\xed\xbf\x45\xf2\x1b\x32\xab\x11\x21\x3c\xbb\x16\xbd\xb3\x24\xea\x99\xed\x3a\x4d\x7a\x7f\x38\x73\xd5\xca\x20\x98\xaf\x57\xf7\x97\x36\xe9\x26\x78\x01\xc8\x4b\x85\x37\x9d\x04\x00\x00")
+
+func migrations50_asset_watchesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations50_asset_watchesSql,
+		"migrations/50_asset_watches.sql",
+	)
+}
+
+func migrations50_asset_watchesSql() (*asset, error) {
+	bytes, err := migrations50_asset_watchesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/50_asset_watches.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x54, 0xa8, 0xd4, 0x77, 0x4f, 0xd3, 0xa4, 0x41, 0x31, 0x36, 0xc0, 0x7d, 0x8, 0xab, 0x75, 0xd4, 0x29, 0x2d, 0x93, 0xc1, 0x94, 0x35, 0x7e, 0x1d, 0xd8, 0x4b, 0x3d, 0x5a, 0x80, 0x9f, 0xe5, 0x1f}}
+	return a, nil
+}
+
+var _migrations51_trade_base_is_makerSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7d\x52\xc1\x6e\xc2\x30\x0c\xbd\xf7\x2b\x7c\x1c\x1a\xf0\x01\x43\x1c\x3a\xda\x5b\x37\x10\x94\x73\x15\x52\x97\x46\xb4\x09\x4a\x82\x2a\xfe\x7e\x76\x22\x3a\x15\xa6\xdd\x12\xdb\xcf\x7e\xef\xd9\x8b\x05\xbc\xf7\xea\x6c\x85\x47\x38\x5e\x93\x64\xb1\x80\x93\x70\x58\x29\x57\xf5\xe2\x82\x16\x94\x03\x6f\x6f\x08\x43\x8b\x1a\x7c\x8b\x21\x0d\x4e\xd5\x08\xa6\x09\x01\x6f\x05\x7d\xb8\xae\xe5\x58\x43\xa0\xa1\x55\xb2\xe5\x5e\x83\x70\x60\xd1\x79\xa5\xcf\xa0\x22\xde\xd8\x9a\x2a\x4e\xc6\x5c\xe0\x8d\xff\x61\xcc\x0c\x84\xae\xa1\x11\x9d\xfb\x6b\x12\x77\x92\xd6\x38\x87\x35\x28\x1f\x61\x3e\xc0\x96\x50\x3e\x5a\x80\x19\x74\xe4\x20\x3b\xa1\x7a\x2a\x0d\x5c\xe6\xd4\xcf\x50\x1f\x69\x2c\x91\x24\xbc\xe3\x6e\x21\x55\xd1\x97\xc7\x06\xb6\x2c\xc0\x79\x2a\xaa\xe1\xa6\x51\x4b\x53\xf3\x30\x1d\xdd\x18\xcb\x8d\x0d\x5c\xcc\x4d\x7b\x0a\x3c\xc2\x73\x18\x19\x41\x4b\x8a\x05\xb8\xbb\xa6\x90\x57\x12\xcc\x15\xc9\x5c\x65\x34\x04\x78\xa8\xe4\x57\xf0\x6e\xa4\x42\xf2\x90\x56\x40\x5c\x94\x27\x2a\x9e\x5d\xeb\x85\xd2\xec\x9b\xe8\x79\x1c\x29\x65\x9f\x1d\x71\x3a\x93\xa1\x54\x7a\xc2\x86\x35\x8d\xae\x73\x33\x55\x3b\x18\x90\xa2\x16\x25\x1b\x1d\x3c\xed\x48\x84\xbc\x80\x37\xe3\x6a\x1d\x76\x1d\xda\x8f\x80\x8d\x6f\x26\x24\x26\xab\x0c\xdc\x06\x1d\x53\x2f\xb6\x2e\x93\xb4\x28\xf3\x3d\x94\xe9\x67\x91\x43\xab\xd8\xba\x7b\xe5\x23\xc5\x34\xcb\x9e\xae\x88\xd6\xdd\xa1\xd0\xab\x24\x39\xee\xb2\xb4\x7c\x41\x1c\xf2\xf2\x09\xb1\x86\xcd\x36\x2d\xf2\xc3\x26\x7f\x9b\xae\x60\x0d\xbf\xb6\x4f\xf5\xcc\xe3\x01\xcd\x56\xe1\x8c\xc7\xb3\xce\x48\x45\xf2\x1f\xdd\x6c\xbf\xdd\xd1\xb4\xe2\xf8\xf5\x3d\x25\xb1\x4a\x7e\x00\x57\x5b\x76\x91\x1f\x03\x00\x00")
+
+func migrations51_trade_base_is_makerSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations51_trade_base_is_makerSql,
+		"migrations/51_trade_base_is_maker.sql",
+	)
+}
+
+func migrations51_trade_base_is_makerSql() (*asset, error) {
+	bytes, err := migrations51_trade_base_is_makerSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/51_trade_base_is_maker.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x37, 0x4d, 0x2, 0x34, 0x41, 0x16, 0x62, 0x79, 0xf8, 0xe6, 0x99, 0x7d, 0xed, 0xdf, 0x62, 0xf2, 0x66, 0x26, 0x4a, 0x36, 0x1d, 0xda, 0x87, 0x5b, 0xb3, 0x30, 0xb2, 0x57, 0x89, 0xf8, 0x15, 0xf1}}
+	return a, nil
+}
+
+var _migrations52_history_account_thresholdsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x85\x92\x31\x6f\x83\x30\x10\x85\x77\xff\x8a\x1b\x89\x1a\xba\xb5\x4b\x26\x52\xac\x0a\x95\x40\x44\x41\x6a\x26\xcb\x01\x07\x5b\x02\x1b\xd9\x6e\x10\xff\xbe\x26\x85\x34\x95\x5a\xe2\xcd\x77\x9f\xde\xbd\x7b\xb6\xef\xc3\x43\x2b\x6a\x4d\x2d\x83\xa2\x43\xc8\xf7\x61\x47\x8d\x65\x1a\x7a\x26\x6a\x6e\x81\xca\x0a\x2c\xd7\xcc\x70\xd5\x54\x06\xd4\xc9\x55\x80\x96\xa5\xfa\x94\xae\x79\x1a\x49\x76\x66\x7a\x00\xc3\x2c\x51\x9d\x15\x4a\x9a\x51\x45\x75\xcc\x89\xba\x1b\xf4\x5c\x94\x1c\x4a\x4e\x65\xcd\x46\x2d\xd6\x3e\xa2\x97\x0c\x07\x39\x86\x3c\xd8\xc6\x18\xb8\x30\x56\xe9\x81\x4c\xaa\xe4\x66\x9c\x87\xc0\x9d\x19\xb8\x6a\x12\x51\xc1\x51\xd4\xc2\x59\xd8\x67\xd1\x2e\xc8\x0e\xf0\x86\x0f\xeb\x0b\x3c\x7b\x73\x03\x35\x2d\x47\x7f\x67\xaa\x07\x21\x6b\xef\xe9\x79\x05\x49\x9a\x43\x52\xc4\xf1\x37\xdb\x5e\x56\x25\xd3\xaa\xa6\xa5\x4d\x33\x6a\xfe\x86\x1a\xd5\xff\x58\xfa\x0f\x6a\x59\x75\x1f\xe2\x6e\xcc\x12\x85\x56\x1b\x34\x47\x13\x25\x21\xfe\x58\x88\x86\x1c\xaf\x55\x48\x93\xa5\x0c\x8b\xf7\x28\x79\x85\x6d\x9e\x61\xec\x4d\xed\xf5\x9f\x91\x8e\xd3\xfd\x9b\xff\x10\xaa\x5e\x22\x14\x66\xe9\xfe\xee\x43\x6d\xd0\x17\x4e\xb6\xd7\x42\x49\x02\x00\x00")
+
+func migrations52_history_account_thresholdsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations52_history_account_thresholdsSql,
+		"migrations/52_history_account_thresholds.sql",
+	)
+}
+
+func migrations52_history_account_thresholdsSql() (*asset, error) {
+	bytes, err := migrations52_history_account_thresholdsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/52_history_account_thresholds.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xb5, 0xc2, 0x78, 0x46, 0xdc, 0x5f, 0x97, 0xa3, 0xd2, 0xa5, 0x26, 0xb3, 0xaf, 0x3, 0xdd, 0x2e, 0xae, 0x1c, 0xf0, 0x83, 0x71, 0x71, 0xfa, 0xf9, 0xe8, 0xc, 0x73, 0xa9, 0x98, 0x13, 0x1a, 0x87}}
+	return a, nil
+}
+
+var _migrations53_reingest_checkpointsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7d\x91\x41\x6e\x83\x30\x10\x45\xf7\x3e\xc5\x5f\x26\x2a\xe4\x02\x59\xd1\x86\x4a\x55\x69\x12\x21\xb2\x88\xaa\x8a\x00\x9e\x04\xb7\x80\x91\x3d\x08\xb5\xa7\xaf\x81\x8a\x64\x93\x7a\x63\xc9\xf3\xe7\xe9\x79\xc6\xf7\xf1\x50\xab\x8b\xc9\x98\x70\x68\x85\xf0\x7d\x44\x24\x2f\x64\x2c\x0a\x5d\xd7\x8a\x99\x24\xf2\x6f\x9c\x4a\x6d\xd4\x8f\x6e\x20\x73\x18\x52\xcd\x85\x2c\xc3\x64\xee\x3e\xe1\x53\xe7\xd6\x83\x92\xd4\xb0\x3a\xab\x29\xcf\x25\x29\x33\xd0\xc6\x8c\x07\xab\x91\x35\x50\x0d\x93\x31\x5d\x3b\x40\x5d\x97\x23\xd9\xae\x26\x8b\xbe\x24\x43\x50\x0c\xcb\xba\x6d\x49\xae\x10\x54\xd5\xc0\x40\xf5\x27\xa3\xcf\x03\xec\x3d\xcf\xb8\x28\xd3\xb3\xd1\xb5\x87\x2a\xb3\x9c\x4e\xf5\x0f\xf4\x03\x60\x36\x5e\x89\xa7\x38\x0c\x92\x10\x49\xf0\x18\x85\xb3\x70\x5a\x94\x54\x7c\xb5\xda\x69\x58\x2c\x04\xdc\x19\xf5\x46\xe0\x28\xe7\x58\xd8\xee\x12\x6c\x0f\x51\xe4\xdd\x04\x58\xdf\x29\x5f\x85\xee\x04\x6e\x2c\xef\x24\xba\x56\xba\xe9\xcb\x34\x63\xb0\x72\xd3\xe0\xac\x6e\xd1\x2b\x2e\x75\x37\xbd\xc0\xcd\x9d\xe6\x26\x6c\xc2\xe7\xe0\x10\x25\x68\x74\xbf\x58\x4e\x88\x7d\xfc\xf2\x16\xc4\x47\xbc\x86\x47\x2c\xae\x5f\xf2\x66\x7b\xef\x46\x74\x29\x96\xeb\x71\xd1\xf3\xe2\x37\xba\x6f\x84\xd8\xc4\xbb\xfd\x3f\x03\x5b\x8b\x5f\xb0\x6e\x75\x70\x2c\x02\x00\x00")
+
+func migrations53_reingest_checkpointsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations53_reingest_checkpointsSql,
+		"migrations/53_reingest_checkpoints.sql",
+	)
+}
+
+func migrations53_reingest_checkpointsSql() (*asset, error) {
+	bytes, err := migrations53_reingest_checkpointsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/53_reingest_checkpoints.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfb, 0x7f, 0x1b, 0x84, 0xf2, 0x7, 0x59, 0xe3, 0xe6, 0x31, 0x3, 0x41, 0x67, 0xf3, 0x7d, 0x8c, 0xf3, 0x97, 0xca, 0x69, 0x93, 0x49, 0xb2, 0xd5, 0x33, 0xd4, 0x67, 0x25, 0x4c, 0xf3, 0xaf, 0x31}}
+	return a, nil
+}
+
+var _migrations54_history_ledger_manifestsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x85\x90\x3f\x4f\xc3\x30\x10\xc5\x77\x7f\x8a\x37\xb6\xa2\x61\x42\x2c\x9d\x42\x1b\xa9\x88\xd0\x56\x51\x3a\x74\xaa\x4e\xf1\x25\xb6\xa8\xed\x60\x3b\xa0\xf0\xe9\xc9\x1f\xe8\x08\x37\xde\xbd\xfb\xbd\x77\x97\x24\xb8\x33\xba\xf1\x14\x19\xa7\x56\x88\x24\xc1\x46\x71\xf5\x16\x3a\x13\xe0\x6a\x44\xc5\x88\x9e\x24\x07\x90\x95\x68\xa9\x37\x6c\xe3\x34\x62\xaa\x14\xb4\x6d\x38\x44\x96\xb8\xb2\x6c\xd8\xaf\x10\x1c\x8c\xf6\xde\xf9\x30\xb2\x2a\xb2\xf8\x60\xaf\xeb\x7e\x24\xf5\x50\xee\x2a\x27\x66\x20\xc3\x90\x14\x09\x34\x92\xd1\xb5\x21\x7a\x26\x83\x9d\xf3\xfa\xcb\xd9\x7b\xb1\x29\xb2\xb4\xcc\x50\xa6\x4f\x79\x06\xa5\x43\x74\xbe\xbf\xcc\x2e\x17\x43\x56\xd7\x83\x6f\xc0\x42\x60\xa8\x9f\x76\xe0\xf7\x8e\x6d\xc5\x43\xaa\xc8\x43\x03\xfb\x43\x89\xfd\x29\xcf\x71\x2c\x9e\x5f\xd3\xe2\x8c\x97\xec\xbc\x9a\x36\xe6\x9b\x2e\x8a\x82\x42\xa5\xc8\x53\x15\xd9\x2f\x1e\x1f\x96\xb7\x9d\x59\xf7\x7b\xf0\x5f\x4a\xb1\x5c\x4f\x8f\xbb\x3d\x72\xeb\x3e\xad\x10\xdb\xe2\x70\xfc\x27\xfe\x5a\x7c\x03\x06\x77\xb2\xb3\x80\x01\x00\x00")
+
+func migrations54_history_ledger_manifestsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations54_history_ledger_manifestsSql,
+		"migrations/54_history_ledger_manifests.sql",
+	)
+}
+
+func migrations54_history_ledger_manifestsSql() (*asset, error) {
+	bytes, err := migrations54_history_ledger_manifestsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/54_history_ledger_manifests.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x3d, 0xc6, 0x3d, 0x48, 0xc2, 0x46, 0x65, 0x85, 0x9a, 0xa7, 0x42, 0x15, 0x28, 0x6a, 0x51, 0x11, 0xa3, 0x3f, 0x52, 0xab, 0xd6, 0xaa, 0x72, 0xe2, 0x41, 0x39, 0x35, 0x4, 0x51, 0x57, 0x72, 0x31}}
+	return a, nil
+}
+
+var _migrations55_trades_unique_operation_orderSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x85\x51\x4d\x4f\xc2\x40\x10\xbd\xf3\x2b\x5e\xbc\xa8\x11\xb8\x79\xf2\x64\xa0\x98\x26\xa6\x44\x29\x09\x37\xb2\xed\x4e\xe9\x44\xd8\xad\xb3\x5b\x91\x7f\xef\xb4\x0d\xa0\x27\x8f\xfb\xe6\x7d\xec\x9b\x99\x4c\xf0\x70\xe0\x9d\x98\x48\x58\x37\xa3\xd1\x64\x82\xbc\x26\x44\x31\x96\x50\x98\x58\xd6\x60\x17\x48\x22\x8a\x96\xf7\x96\x04\xe1\x83\x9b\x00\x2f\xf8\x22\xe1\x8a\x29\x20\x9e\x05\x0a\x57\x30\x10\x8a\xc2\x64\x3b\xaf\x3d\xd9\x9d\x6a\x8e\x1c\x6b\x2c\x33\xcc\x96\xd9\xe2\x35\x9d\xe5\xb8\xab\x39\x44\x2f\xa7\xad\x6f\x48\xb3\xd9\xbb\x2d\xdb\x31\x6e\xbc\x68\xc4\xcd\xfd\x18\xc7\x9a\xbb\xe8\xa0\xe9\x15\x89\x0c\x6e\x95\xf8\x43\x9f\x56\x47\xb1\xdb\x86\x2d\x5a\xc7\x9f\x2d\x29\xc9\xd2\x37\x4a\x21\xad\x61\x51\x9c\x30\x54\x52\x5b\x3c\x4e\xfb\x42\x03\x43\xfd\x9c\x8f\x9d\x55\x6c\xc5\x29\x95\x5d\xf4\xfa\xe3\xd2\xbb\xa0\x0d\xf4\x85\x82\x4a\xd3\x06\xfa\x05\x05\x94\xc6\xdd\x76\x13\x18\x6b\x55\xd4\x06\x76\x3b\x18\xd7\xf9\x0c\xbe\x7d\xed\xc6\x48\xe4\x2e\x53\x29\xd1\x14\x7b\x1a\x23\x10\x5d\xe1\xed\xb9\x73\x3f\x9c\x8e\x66\xef\xc9\x73\x9e\x60\x9d\xa5\x6f\xeb\x04\x69\x36\x4f\x36\x48\x17\xc8\x96\x39\x92\x4d\xba\xca\x57\xd7\x9a\xba\xba\x8b\x78\x58\xf4\x7a\x95\x66\x2f\x28\xa2\x68\xc2\x3f\xcb\x7c\xea\xaf\x7a\xb9\xf2\xdc\x1f\x5d\x8f\x5c\xdc\x0b\xda\x7b\xb7\xd3\x3b\xfa\x3f\x7b\x1b\xfd\x00\x73\xab\x88\x1d\x1c\x02\x00\x00")
+
+func migrations55_trades_unique_operation_orderSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations55_trades_unique_operation_orderSql,
+		"migrations/55_trades_unique_operation_order.sql",
+	)
+}
+
+func migrations55_trades_unique_operation_orderSql() (*asset, error) {
+	bytes, err := migrations55_trades_unique_operation_orderSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/55_trades_unique_operation_order.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf4, 0x55, 0xe8, 0xb4, 0x1, 0x5b, 0xc4, 0x6, 0xca, 0x2e, 0xa5, 0x16, 0x26, 0x62, 0x81, 0x5, 0xc9, 0x9e, 0x37, 0x28, 0x2f, 0x16, 0xe, 0x87, 0x13, 0x59, 0xc8, 0xd1, 0x51, 0x24, 0x36, 0xbd}}
+	return a, nil
+}
+
+var _migrations56_exp_state_quarantineSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7d\x53\xc1\x6e\xda\x40\x10\xbd\xfb\x2b\xe6\x06\xa8\x80\x54\xa9\xca\xa1\x39\x51\xa0\x6a\x14\x4a\x22\x04\x52\x72\x42\xcb\x7a\x8c\x57\xb1\x77\x9d\xdd\x31\xc4\xfd\xfa\xce\xae\x17\xe3\x52\x29\x3e\x59\x33\x6f\xde\xbc\x79\x33\x3b\x99\xc0\x97\x52\x1d\xad\x20\x84\x5d\x95\x24\x93\x09\xac\x30\x3d\xa2\x05\xd4\x64\x15\x3a\x38\xe7\xc6\x21\x28\x7d\x44\x47\x98\x82\x23\x0f\x4d\x0d\x3a\x3d\x20\x28\x05\xc9\x1c\x28\x47\xc8\x95\x23\x63\x1b\x10\x56\xe6\xea\x84\x9e\x48\xe6\x28\xdf\x2a\xa3\x34\x8d\x21\x33\xb5\x4e\xe1\xd0\x04\x6c\x21\x1c\x45\x22\x51\xa7\x8a\xa6\xb0\xe5\x28\x89\x43\xc1\x8d\x1c\x58\xac\x0a\x21\x31\xc0\xf1\x84\xb6\xf1\x64\x01\x08\x82\x49\x64\x81\xc2\x72\xf6\x9c\xa3\x0e\x74\x2d\x53\x28\x3c\xd4\xaa\xa0\x29\xe3\x7d\x89\x45\xe1\x8c\xf6\x09\xa3\x11\x4c\xf6\xdd\x07\x01\x4a\xe5\x5a\xd9\xa1\xd8\x8f\xd9\x40\xaa\xb2\x0c\xad\x83\xcc\x9a\x32\x84\xaf\xda\xbb\x22\xc7\x1e\x40\xbf\xc8\x64\x37\x50\xdf\x4a\x1b\xea\xcc\x6a\x4b\x25\x8f\x4e\x00\xb1\x54\xd7\xe5\x81\xdd\xe5\xda\xce\xd2\x8b\xd3\x1c\x0b\xc4\x7b\x6a\x2a\xfc\x4f\x52\x4b\x76\xfd\xfa\xee\x16\x61\x65\xfb\x37\x6c\xbc\x04\x2c\x2b\x6a\xa2\x07\xf8\x51\xa1\xf4\x4d\xbc\x73\x42\x52\x2d\x0a\x5e\x11\x06\x29\x07\xe1\xf0\xee\x1b\xf7\x94\x26\x65\xc4\xcb\x62\x13\x89\xfa\x8a\x62\xe7\xde\x90\x9e\x29\x8e\xfe\xef\x55\x8c\xc1\xd8\xde\x8c\xdd\x40\x9e\x29\xe3\x54\x30\x62\x9a\xcc\x37\xcb\xd9\x76\x09\xdb\xd9\x8f\xd5\xd2\xeb\xdb\x87\xe2\xfd\x7b\x2d\xac\xd0\xa4\x78\x55\xc3\xc4\x0f\xa8\xf8\x00\xd4\xd1\xa1\x55\xac\xf9\x79\xf3\xf0\x7b\xb6\x79\x85\xc7\xe5\xeb\x38\x64\xe3\xc8\x0e\xdf\x6b\x1e\xc0\x4b\x21\xf4\xd2\xd7\x4f\x5b\x58\xef\x56\xab\x16\xd5\xf3\x53\xe6\xcc\xcf\x56\x58\x38\x09\xdb\xb0\xf2\xe1\xd7\xbb\xd1\x0d\xbc\xe7\x23\xe1\x07\xdd\x64\xe3\x3d\x7d\x4e\x04\xf3\x5f\xcb\xf9\x23\x0c\x23\xf8\x61\x0d\xc3\xc1\xe5\xe2\x06\x63\x18\xc4\x43\xf2\xbf\xc1\x8f\xc1\x68\x14\xa5\x5e\x36\xe5\x3b\xb7\xa1\xb8\xaf\x6b\x40\x32\x2b\x43\xf6\x82\x80\x54\xc9\xd6\x8b\xb2\x82\xb3\xa2\xdc\xd4\x6d\x04\xfe\xf8\x53\xef\xb4\x2c\x96\x3f\x67\xbb\xd5\x96\x8f\xf2\x3c\x1c\x25\xa3\xfb\xf0\xbe\xbb\xf7\xbe\x30\x67\x9d\x24\x8b\xcd\xd3\xf3\x27\xcb\xb8\x4f\xfe\x02\xdd\xf7\x84\x9b\x23\x04\x00\x00")
+
+func migrations56_exp_state_quarantineSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations56_exp_state_quarantineSql,
+		"migrations/56_exp_state_quarantine.sql",
+	)
+}
+
+func migrations56_exp_state_quarantineSql() (*asset, error) {
+	bytes, err := migrations56_exp_state_quarantineSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/56_exp_state_quarantine.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x20, 0x45, 0x15, 0xbd, 0x99, 0x27, 0xf1, 0xb1, 0x8a, 0x44, 0x6a, 0xa3, 0x83, 0x12, 0x35, 0xba, 0xb8, 0x30, 0x78, 0xf4, 0x11, 0xbb, 0xac, 0x36, 0x18, 0xc4, 0x58, 0x68, 0xa0, 0x93, 0x2d, 0xb8}}
+	return a, nil
+}
+
+var _migrations57_trade_sequenceSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9d\x56\x6d\x4f\xe3\x48\x0c\xfe\x9e\x5f\x61\xad\x90\xda\x8a\x80\x96\xbb\x6f\x14\x90\x42\x33\x40\xa4\x92\x70\x4d\x7a\x70\x9f\xaa\x69\x32\x6d\x47\x9b\x17\x36\x33\x59\xe0\xdf\x9f\x9d\xa4\x69\x92\x0b\x45\x7b\x95\x10\x99\x19\xfb\xf1\x63\x8f\xc7\xf6\xd9\x19\x9c\x26\x72\x9b\x73\x2d\x60\xf9\x0a\x69\xa6\x73\x9e\x2a\x1e\x6a\x99\xa5\x86\x71\x76\x06\x4a\xfc\x2c\x44\x1a\x0a\x90\x0a\x38\x6c\xe3\x6c\xcd\x63\x48\x8b\x64\x2d\x72\xe0\x4a\xc9\x6d\x2a\x22\xd0\x19\xa0\x5e\x24\x14\xc8\x14\xf4\x4e\x40\x96\x47\x78\x8e\x8b\xb7\x9d\x0c\x77\xb4\xf5\x41\x60\x3c\x47\x9c\x74\x2b\x94\x16\xd1\x39\x2c\xd3\x58\xfe\x10\xa5\xfc\x78\x27\x95\xce\xf2\x8f\x55\xf6\x2a\x90\x0b\x1a\x5f\xc9\xc8\xac\x60\x26\xf0\xca\x25\x82\xe9\x8a\x82\x42\x80\x58\x10\x5a\x92\x21\xdd\x2c\x95\x21\x8f\xe3\x0f\xc4\x0d\x73\xc1\xe9\x14\x7e\xf1\xb8\x10\x26\xa8\x0c\xc2\x58\x8a\x54\x2b\x48\x44\xbe\xa5\x13\xa5\x51\x26\x51\x90\x6d\xf6\x84\xb3\x0d\x41\x45\x72\xb3\x11\x39\x8a\x02\x0f\xc3\xac\x40\x15\x34\x4e\x5b\x28\x50\xfa\x29\x10\x24\xe4\x29\x14\x4a\x10\x13\x4e\x4c\xc2\x22\x57\x59\x7e\x0e\xec\x1d\xc9\x23\x3a\x01\xd5\xa8\xe4\x68\x15\x24\x8c\x4e\x27\x26\x64\x79\x27\xc8\x9f\x48\x9d\xa3\x06\x29\x05\x78\x5a\x5d\x02\x3a\x0e\x79\x91\xa2\xd5\x42\x2b\x19\x09\x12\xe7\xd0\xba\x13\x72\x8a\xc0\x44\x6d\xb3\x65\x90\x90\xda\x36\xd7\x5c\x87\xbb\xd2\x43\x88\x45\xb4\x45\x5f\x4c\x08\xb3\x24\x91\x1a\xa3\x8f\xf7\xfa\xca\xe9\xd6\x31\x72\x88\xfa\x94\x29\xbd\xcd\x51\xf8\xe2\x02\x79\x12\x52\x8c\x67\xe4\xdb\x2f\x91\x7f\x60\xd8\x70\x95\x50\x78\x28\x06\x6b\x41\x1c\x81\x6f\xb9\x4c\x4d\xa4\xb7\xe1\x12\x0d\xb4\x3d\x10\xaa\x48\x10\x0c\x89\x1e\x48\x11\x59\xbe\x41\xd0\x92\x7f\xcc\x95\x6e\xb1\x29\xb9\x9e\x1b\xb3\x05\xb3\x02\x06\x3e\xfb\x6b\xc9\xdc\x19\x03\xe7\x0e\x5c\x2f\x00\xf6\xe2\xf8\x81\x0f\xfb\x1c\xa9\x5c\x5e\xed\x33\x93\x3e\xa6\x86\x61\xcd\x03\xb6\x80\xc0\xba\x9d\xb3\x9e\x24\x58\xb6\x0d\x33\x6f\xbe\x7c\x74\x7b\x88\x4d\x72\xaf\x25\xa6\x87\x9e\x96\x19\xdf\xbc\x07\x7f\xef\xf5\xad\xc0\x53\xc3\xf6\xe0\xe4\xc4\xb0\xd9\x6c\x6e\x2d\x98\x01\xf8\x2b\x59\xaf\xea\xe0\xa2\x37\x29\xc6\x09\x63\x54\x81\xc1\xe5\x35\x5c\x7c\xc7\xdf\xb4\x94\xdd\xe4\x59\x52\x8b\x36\xd6\x68\x9f\x02\x31\xb4\xdf\xdc\x64\x7b\xb3\x78\x8d\xb8\x6e\xed\xdd\xb2\x7b\xc7\x2d\x4f\x7c\x36\x67\xb3\x00\xbd\xb4\xe6\xcc\x9f\xb1\xf1\xa3\xe3\x0e\xbe\xa9\x89\x09\xdf\x27\x70\x73\x03\x7f\xfe\x61\xb6\xa4\xad\x97\xaf\xa4\x4b\x2b\xf4\x73\xdc\xc0\x6b\x7b\x63\xb6\x5d\x68\xa4\xee\x16\xde\x63\xff\x1a\x9e\x1f\xd8\x82\x1d\x62\xee\xf8\xe0\x2e\xe7\xf3\xe9\x30\x7f\x64\xb4\x97\xac\x58\x94\x76\x9b\xa8\x0c\xe0\xe3\xed\x11\xd2\xf3\x83\x83\x19\xd0\x0e\xf7\xd5\x75\x27\xca\x73\xcf\x7b\x6a\x78\x2e\x9f\x6c\xca\xb8\x1e\x53\x9f\x05\x07\x9e\xd7\x75\x76\xee\x37\xba\x3e\x8e\x9b\x65\xcb\x8d\xe1\x6a\xf6\xad\xac\x00\xdf\xcc\x8e\x46\xe7\xae\x4f\x21\xcf\xde\x56\xd5\x72\x3c\x01\xef\x6f\xcc\xe8\xb1\xb7\xb0\xf1\xdf\xed\x3f\xc7\x51\x27\x60\x1d\xf2\xb9\x63\x61\x20\x54\x9d\xf3\xe1\x6b\xf9\x0f\x49\xcb\xb5\x07\x19\xc0\xcd\x75\x37\xda\x57\xed\x6c\xf9\x52\xfb\x0a\xc6\x6d\xed\xd3\xee\xa3\x9a\xf4\xe0\x4a\x2f\x4b\x89\x66\xab\xa2\xdf\xf5\xef\xfc\xff\x11\x6d\x93\x3c\x06\xf4\x7b\x9c\x7f\x07\x79\x9f\x6b\x43\x87\x5f\x01\xd6\x89\xd0\x60\xd4\xeb\x69\xa3\x77\x8f\x59\x6d\x3b\xd6\xbd\xeb\xf9\x81\x33\xf3\x9b\x6a\x72\x0d\x0b\xef\x79\x35\xf3\x96\x6e\x50\xbf\xa1\x4e\x52\x62\x1d\x6b\x25\x68\xad\x74\x40\x6d\x47\xe2\xb2\x1b\xe1\x5e\x60\x0e\x3a\x58\x85\xb1\x79\x52\xbf\xc5\xe2\xad\xa9\x8f\x8d\x47\x4a\xe4\xd8\x6b\x56\xf8\xa7\xc8\x5f\x34\x39\x9a\x5c\x5e\x52\x21\xc5\x7b\xbb\x28\x2b\x29\x04\x0f\xcc\xed\x84\x61\xe6\x3d\x3e\x3a\xc1\x01\x98\x61\x58\x9c\xbb\x6a\x4d\xdf\xf4\xd6\xa7\x06\x7e\x4d\x8d\x93\x93\xe9\x70\x75\x67\x69\x64\x18\xf5\xc3\x45\x36\x38\x3c\x8c\x47\x47\x5a\xcd\xc8\x3c\x56\xa5\x4e\xe1\xc2\xc4\x86\x18\x2b\x31\xf9\xa4\x46\x7d\xde\xa7\x4a\xd6\xd5\x71\xf3\x1a\xa9\x12\xd9\xec\xce\x5a\xce\x03\x48\xc5\xfb\xd7\xec\x26\xe6\x67\x30\xd4\xf7\xaa\x82\x5b\x1d\x36\x5d\xf6\x08\x1c\x78\xcf\x2e\xb3\xdb\xc5\xa7\x4e\xb6\xbd\x50\xd5\x32\xa9\xa3\x37\xb6\x12\xfe\xa3\xea\xfd\x75\xda\x28\x28\x52\xf9\x93\x06\x32\xda\x93\x69\x24\xde\x71\x92\x4b\x47\xba\xde\xc7\x79\x22\xe4\x34\x57\x71\x82\xaa\xf7\x2a\xb1\x72\xfa\xc1\x39\x45\x4b\x7a\x03\x98\x7f\xbd\x3a\x9d\x14\x38\x45\xe0\xe0\x17\x17\x51\x39\x69\x0c\x3e\x9b\x7a\xa8\x70\x5c\x9b\xbd\xf4\x27\x0a\x9d\x47\xab\xf5\x47\xe3\x32\x78\x6e\xdf\xc4\xd2\x77\xdc\x7b\xb8\x0d\x16\x8c\x1d\xae\xba\x37\x28\xd8\xd9\x1b\x0e\xcb\xf6\xc2\x7b\xaa\xad\xf4\x71\xa7\xc7\xa6\x93\x52\xaf\x1e\x4f\x0e\x0a\xff\x02\xa1\xc6\x4b\x24\x99\x0b\x00\x00")
+
+func migrations57_trade_sequenceSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations57_trade_sequenceSql,
+		"migrations/57_trade_sequence.sql",
+	)
+}
+
+func migrations57_trade_sequenceSql() (*asset, error) {
+	bytes, err := migrations57_trade_sequenceSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/57_trade_sequence.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x1b, 0xd4, 0x8f, 0x3f, 0xf0, 0x68, 0xaf, 0x26, 0x78, 0x29, 0xef, 0xc, 0x15, 0xad, 0x53, 0x51, 0xc9, 0x84, 0x83, 0x8c, 0xa3, 0xf3, 0xaf, 0x8d, 0x24, 0xe8, 0x3e, 0x47, 0xd5, 0x35, 0xf, 0xb1}}
+	return a, nil
+}
+
+var _migrations58_trust_lines_by_asset_balanceSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd3\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\xe2\x72\x0e\x72\x75\x0c\x71\x55\xf0\xf4\x73\x71\x8d\x50\x28\x29\x2a\x2d\x2e\x89\xcf\xc9\xcc\x4b\x2d\x8e\x4f\xaa\x8c\x4f\x2c\x2e\x4e\x2d\x89\x4f\x4a\xcc\x49\xcc\x4b\x4e\x55\xf0\xf7\x43\x96\x57\x08\x0d\xf6\xf4\x73\x57\x70\x0a\x09\x72\x75\xd5\x80\x28\x2c\xa9\x2c\x48\xd5\x51\x80\xb0\x93\xf3\x53\xe0\xec\xcc\xe2\xe2\xd2\xd4\x22\x1d\x05\xa8\x41\x40\xe1\xe4\xe4\xfc\xd2\x3c\xa0\x44\x8a\xa6\x35\x17\x97\x2e\x92\x8b\x5c\xf2\xcb\xf3\xb8\xb8\x5c\x82\xfc\x03\x88\x70\x91\x35\x17\x00\xc4\x4e\xe2\x3c\xcd\x00\x00\x00")
+
+func migrations58_trust_lines_by_asset_balanceSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations58_trust_lines_by_asset_balanceSql,
+		"migrations/58_trust_lines_by_asset_balance.sql",
+	)
+}
+
+func migrations58_trust_lines_by_asset_balanceSql() (*asset, error) {
+	bytes, err := migrations58_trust_lines_by_asset_balanceSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/58_trust_lines_by_asset_balance.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x87, 0x51, 0xd7, 0xd6, 0x5a, 0xef, 0xeb, 0xb2, 0x59, 0x8, 0x2f, 0x1, 0x72, 0xa, 0x56, 0xfe, 0xfe, 0xd0, 0xa8, 0x90, 0xde, 0x46, 0x63, 0xc4, 0x30, 0x4f, 0x92, 0x72, 0x42, 0xb2, 0xc9, 0x7f}}
+	return a, nil
+}
+
+var _migrations59_exp_asset_stats_issuerSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\x92\x4f\x4f\x83\x40\x10\xc5\xef\xfb\x29\xe6\x86\xc6\x52\x8d\x1e\x89\x07\x14\x1a\x0f\x14\x1a\x84\x78\x24\x53\x98\xd2\x35\xb0\xdb\xec\x6e\xd5\xfa\xe9\xe5\x5f\x28\xd8\xc4\x78\xdb\xbc\x99\x79\xf3\xdb\x97\xb1\x6d\xb8\xa9\x79\xa9\xd0\x10\xa4\x07\xc6\x6c\x1b\x76\x15\x96\x1a\x50\x14\xb0\x97\x35\x65\x85\xac\x91\x0b\x90\x3b\x30\x7b\x02\xae\xf5\x91\x14\xa0\x22\x28\x48\x48\x55\x63\xc5\xbf\xa9\x00\x29\x8c\xec\x1a\xb4\x41\xa3\xdb\x6e\x6e\x74\xeb\x86\x5a\x53\x23\x6c\x4f\xfd\xb8\x28\x49\x1b\x2e\xc5\x02\xb4\x84\xdb\xa1\x58\x48\xd2\xc2\x32\xf0\x2e\x9b\x45\x6d\x1b\xe6\xb9\x3c\x8a\xa6\x62\x70\x5b\x51\xc7\x92\xa3\xe8\xe0\x78\x65\xda\xfd\x33\xd7\x9e\xb8\x27\xe4\x6a\x60\x5c\x32\x37\x48\xfc\x18\x12\xf7\x29\xf0\x81\xbe\x0e\x59\x37\x94\xf5\x80\xae\xe7\xc1\x73\x14\xa4\xeb\x70\x98\xe6\xc2\x50\xd9\x38\x87\x51\x02\x61\x1a\x04\xe0\xf9\x2b\x37\x0d\x12\xb8\x73\xfe\x6b\x34\x8d\x2b\xdf\xa3\xc2\xbc\x45\xfd\x40\x75\x6a\xbe\x7d\xf5\x70\x7f\x7d\x69\x6e\x59\x0e\x63\xe9\xc6\x73\x93\x0b\x67\xf6\xea\x27\x03\xdb\xe3\x18\xc8\xb2\x13\x16\xb3\x55\x93\xea\x44\x66\xab\x38\x5a\x8f\x15\xf6\xf6\xe2\xc7\xfe\xb9\x71\x78\x64\xbc\x68\xc6\x7f\x2d\x5e\xf6\xef\x3e\x45\xa7\x3b\x89\xf1\x44\x3c\xf9\x29\xd8\x9f\x79\x78\x71\xb4\x99\x25\xbb\x98\x49\x13\x42\x87\xfd\x00\x0c\x99\xbf\x5a\x7d\x02\x00\x00")
+
+func migrations59_exp_asset_stats_issuerSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations59_exp_asset_stats_issuerSql,
+		"migrations/59_exp_asset_stats_issuer.sql",
+	)
+}
+
+func migrations59_exp_asset_stats_issuerSql() (*asset, error) {
+	bytes, err := migrations59_exp_asset_stats_issuerSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/59_exp_asset_stats_issuer.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xdf, 0x85, 0x37, 0x79, 0xfd, 0xe8, 0x1a, 0x5e, 0x74, 0x47, 0xe8, 0xea, 0xb5, 0x7b, 0xa0, 0xec, 0xea, 0xbc, 0x81, 0x13, 0xed, 0xf8, 0x7e, 0xc5, 0x52, 0xed, 0x77, 0xd9, 0x99, 0xec, 0x7e, 0x24}}
+	return a, nil
+}
+
 var _migrations5_create_trades_tableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x94\x51\x6f\xaa\x40\x10\x85\xdf\xf9\x15\x13\x9f\x30\x17\x93\x7b\x6f\x5a\x5f\x4c\x9a\x58\x25\xad\xa9\xc1\xd6\x4a\xd2\x37\xb2\xb0\x23\x6c\xa2\x2c\x99\x1d\xda\xf0\xef\x1b\x68\x69\x10\x57\xad\xaf\x9c\x39\x67\x38\xbb\x5f\x76\x34\x82\x3f\x7b\x95\x92\x60\x84\xb0\x70\x66\x6b\x7f\xba\xf1\x61\x33\xbd\x5f\xfa\x90\x29\xc3\x9a\xaa\x88\x49\x48\x34\xe0\x3a\x00\xf0\xf3\x51\x17\x48\x82\x95\xce\x23\x25\x21\x56\xa9\xca\x19\x82\xd5\x06\x82\x70\xb9\xf4\x9a\xc9\x81\x26\x89\x34\x00\x95\x33\xa6\x48\x1d\xb5\x91\xf5\x76\x8b\x64\x35\x37\xb2\xc1\xdd\xee\x84\x5e\xcb\x71\x59\x9d\x75\xeb\x9d\x8c\x84\x31\xc8\x11\x57\x05\x42\x92\x09\x12\x09\x23\xc1\xbb\xa0\x4a\xe5\xa9\x3b\xbe\x19\xf6\x22\x3b\x1e\x65\x4c\x89\x64\x71\xdd\x8e\xcf\xb8\x12\x2d\x6d\x9b\xfe\xfd\xb7\x7b\xf6\xba\xcc\xb9\xff\xff\x30\x7b\xf4\x67\x4f\xe0\x76\x47\xee\xe0\xef\xf0\xbb\x57\xac\xcb\x34\xe3\x6b\x9b\x1d\xb8\xae\xe8\x76\xe0\xfb\x75\xbb\xd6\x75\xb6\xdf\xe1\x50\xdd\xd0\x19\x4e\x9c\x96\xbf\x30\x58\xbc\x84\x3e\x2c\x82\xb9\xff\x06\x19\x93\x8c\x0a\x25\x61\x15\xf4\x91\x0c\x5f\x17\xc1\x03\xc4\x4c\x88\xe0\xda\xc8\xf4\x5a\x0a\x3b\xe1\x9d\xd4\xb8\x8a\x1a\x0c\x2f\x45\xb7\xac\xda\x52\xea\x90\xfa\xb6\x2e\x65\xf4\x90\xf4\xfa\xe4\x78\xc7\x00\x9e\x5a\xf7\x75\x78\x97\x16\x1e\xb1\xe2\x1d\x5f\xa8\x67\x63\xa3\x5e\xdb\x7d\x17\xe6\xfa\x23\x77\xe6\xeb\xd5\xb3\xfd\x5d\x48\x84\x49\x84\xc4\x89\xf3\x19\x00\x00\xff\xff\x79\x87\x24\x6b\x4c\x04\x00\x00")
 
 func migrations5_create_trades_tableSqlBytes() ([]byte, error) {
@@ -829,6 +1230,46 @@ func migrations5_create_trades_tableSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations60_transaction_trade_effect_countsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xe5\x56\xdd\x6f\xda\x30\x10\x7f\xcf\x5f\x71\x0f\x95\x0a\x2b\xb0\xb2\xbd\xf1\x51\x29\x10\xd3\x46\x0a\x09\x0a\x41\xed\x9e\x50\x20\x06\x2c\x41\x52\xc5\x66\x2d\xff\xfd\xce\xce\x07\x36\x65\x53\xa5\x49\x7b\x59\xa4\x96\xf8\xee\x7c\xbf\xbb\xdf\xd9\x77\x69\xb7\xe1\xee\xc0\xb6\x79\x2c\x28\x2c\x5e\x21\xcd\x44\x1e\xa7\x3c\x5e\x0b\x96\xa5\x96\xd5\x6e\x03\xae\x13\xba\x5c\x67\xc7\x54\x40\x9c\x26\x40\x37\x1b\xba\x16\x95\x20\xa7\x20\x76\x14\xd2\xe3\x61\x45\x73\x0e\xd9\xa6\xb0\xe7\x9a\x29\x97\x5e\xb6\x34\xa5\x12\x23\x81\xd5\x49\xed\xd0\x60\x5a\xc0\xa9\xa8\xe4\x2c\xdd\x52\x2e\xa5\x1d\x20\xef\x0c\xdf\xd2\xad\x6e\xab\x9c\x49\x54\x85\x8f\xee\x36\x79\x76\x50\x1b\x77\x68\x9c\xe5\xa7\xa5\x86\x5f\x89\xca\x38\x40\xc4\xab\x3d\xe5\xad\x02\x27\xc1\x60\x55\x7e\x66\x30\x32\x85\x18\xff\xbf\xca\x70\xe5\x9a\x71\x60\xb8\x17\xed\xdf\x98\xd8\x29\xf3\xb3\x72\x25\x55\xeb\x3d\xc5\x88\x92\x0e\x7a\x93\x0e\x23\xb4\x28\x18\x95\x16\xf9\x31\x45\x5a\x8e\x82\xb3\x84\x2a\xdf\x06\x18\xcf\x94\x43\xfa\x99\x4c\x19\xc2\xc5\x62\xbd\xa3\x8a\xe6\x3d\x4d\xb6\xc8\x78\x0b\xd5\x87\x03\x13\xd2\x80\xd3\xd7\x58\x72\xbc\x3f\x01\xba\x9e\x65\x5c\x6c\x73\x34\xee\x76\x21\xcb\xa5\xb3\x3d\xea\x72\xa4\xf5\x27\xcd\x4f\xc0\x05\xae\x0e\x14\x6b\xb8\xc6\x74\x57\x54\x06\x0a\xf1\x36\x66\x69\xab\x40\x94\xe1\xc4\x05\x62\x21\x97\x55\xe2\x32\x5c\xe9\x8b\xc7\x87\x32\x32\xde\xb1\x6c\x2f\x22\x21\x44\xf6\xc8\x23\x7a\x19\xea\x4c\xc0\x76\x1c\x18\x07\xde\x62\xea\x83\x3b\x01\x3f\x88\x80\xbc\xb8\xf3\x68\x6e\x1c\x2e\x86\x59\x62\x4a\x4a\xed\x2f\x3c\x0f\x1c\x32\xb1\x17\x5e\x04\xf7\xfd\xbf\x40\x30\x8e\xeb\x9f\x20\x64\x56\xf5\x4d\x98\x57\xec\x8c\xe8\x96\xa5\x96\x13\xc0\xcd\x8d\xe5\x90\xb1\x67\x87\xc4\x02\x7c\x14\x2f\xcb\xb2\x08\x48\x44\x8a\x7c\x22\xc0\x8a\xa1\xb9\x80\xde\x10\xba\xf7\xf8\xf4\x95\xad\x3c\xa2\xa5\x69\x69\x50\xc8\xf7\x31\x17\x97\xf2\x11\x79\x74\x7d\xa5\x9d\x13\x8f\x8c\x23\x4c\xca\xf6\xc8\x7c\x4c\x1a\x53\xd7\x6f\xb0\xa4\xd9\x82\xfb\x26\x3c\x3c\xc0\xf7\x6f\x2d\x4d\x67\xbf\x98\x3a\xe5\x41\x3e\xae\x1f\x05\x7a\x00\x2d\x1d\xb5\xb6\x9a\x84\xc1\xf4\x2a\xaf\x48\x8b\xd4\x3f\x3f\xb9\x48\xbc\x9e\xc7\x60\x68\x84\xef\x05\xc1\xac\xf6\xb6\x98\x39\x76\xf4\x9b\x3a\xcd\x49\x64\x14\x7d\x58\x1d\x22\x4d\x68\x86\xd5\xa8\x97\x1a\x2b\x8d\xca\x79\x7d\x15\x97\x78\x41\x31\xf5\xee\xb7\x26\x0c\x06\xf8\x03\xf6\x5c\xbf\x4d\xa8\x2e\xcf\x75\xe3\x4b\xb3\xd4\x7d\xc0\xbb\x46\x05\xf6\x12\x43\xff\xfc\x44\xc2\x73\x6e\x26\xfc\xd0\xa4\x68\xa0\x17\xa2\x7a\x6c\xdf\xb9\xbe\x7b\x00\x0d\x7d\xf7\x9d\x79\xc4\x9a\x57\xdc\x3d\x86\xc1\x62\x06\xa3\x1f\xd0\xad\xc5\x2a\xb5\x82\xd1\x5a\x66\x46\xac\x57\xa3\x83\xb0\x7a\x01\x34\xb2\xca\xc2\x7f\xa6\x9c\xc6\x0d\xab\xdd\xe9\xd2\x7f\x52\xd0\xab\x80\x1f\x2a\x5a\x8d\xa4\xff\xbb\xa4\x7a\x54\x3d\x33\xc5\x8b\x20\xfb\xe7\x4e\x32\x81\xf5\x31\xcf\xb1\x27\x2e\x71\x10\xc8\xf1\xd0\xb8\xe5\x34\xc7\x61\xb2\xc4\x3f\x2e\x31\xf0\x2b\xe0\xb6\xd9\xeb\xc9\x0e\x88\xc4\x75\x55\x0b\x84\xe8\x89\xf8\x46\x8e\xe3\x60\x3a\x75\xa3\xb3\x63\x82\xf4\xb9\x93\x62\x2d\xdf\x65\x2f\xe9\x5b\xf8\xd6\xb7\x6e\x6e\xfa\xd7\xdb\x32\x49\x13\xd5\xb0\x0f\x38\xe4\x8c\xa1\x09\x49\x96\xde\x8a\xe2\x76\x17\x83\x1e\x67\xa2\x60\xf1\x1e\x7b\x7f\x42\xdf\x41\x45\xac\xa6\x18\xce\xe8\xb4\xba\xe0\xe8\x69\xc3\xf6\x38\x1d\xe5\x64\xfd\x6a\x0c\xe1\x71\x48\xe4\xe9\x77\x7d\x87\xbc\x5c\x8c\x96\x9d\x78\x5f\xca\x6f\x82\xea\x93\x23\xf0\xaf\x5f\x92\xc5\xdc\xf5\x1f\x61\x14\x85\x84\xc8\x2e\x5d\x56\x4f\xef\x82\x0f\x1f\x06\x90\x93\xbd\xe1\xe7\x97\x13\x06\xb3\x12\xfa\x02\xec\x13\x23\x51\x6d\x2e\x67\xa2\x06\xd6\x32\x14\xfa\xad\xe9\x5b\xbf\x00\x8b\x19\x4c\x98\x0d\x0a\x00\x00")
+
+func migrations60_transaction_trade_effect_countsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations60_transaction_trade_effect_countsSql,
+		"migrations/60_transaction_trade_effect_counts.sql",
+	)
+}
+
+func migrations60_transaction_trade_effect_countsSql() (*asset, error) {
+	bytes, err := migrations60_transaction_trade_effect_countsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/60_transaction_trade_effect_counts.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x97, 0x91, 0x4d, 0x47, 0x38, 0xda, 0x32, 0x94, 0x5e, 0x17, 0x99, 0x2e, 0x81, 0x31, 0x24, 0x74, 0x80, 0x37, 0x93, 0x9f, 0x52, 0x86, 0xb4, 0xf6, 0x1e, 0xa8, 0xdb, 0x74, 0x9b, 0x96, 0xf, 0x53}}
+	return a, nil
+}
+
+var _migrations61_ledger_gap_claimsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x85\x91\xc1\x6e\xc2\x30\x0c\x86\xef\x79\x8a\xff\x08\x1a\xe5\x05\x38\x75\xd0\x49\xd3\x3a\x40\x55\x39\x70\xaa\xd2\xd6\x6d\x23\xda\xa4\x4b\xc2\x2a\xf6\xf4\x73\x0b\x02\x71\x98\x96\x43\x64\xd9\x7f\xbe\xdf\x76\x82\x00\x2f\x9d\xaa\xad\xf4\x84\x43\x2f\x44\x10\x20\xa6\xb2\x26\x8b\x5a\xf6\x0e\x45\x2b\x55\x47\x25\xf2\x0b\x7c\x43\x50\xba\x26\xe7\xf9\xe6\xc8\x79\xa9\x0b\x72\xc8\x65\x71\xaa\x54\xdb\x8e\x59\xd6\x74\x0b\x38\x33\x62\x1e\x0a\xd7\x48\x7b\xab\xa2\x94\x5e\xe6\xd2\xd1\xfd\x19\x4a\x55\x55\x64\x49\xfb\xc9\x71\x89\xf5\x68\xe9\x30\x34\xaa\x68\x30\x70\x65\x84\x69\xe3\x61\xa9\xb2\xe4\x1a\xee\xc6\x52\xc1\xfa\xf6\x82\x9c\x5a\x33\x82\x0d\x9c\x37\x7d\xcf\xa5\x87\xab\xd4\x25\xa4\x25\x78\x79\x22\x0d\xf3\x4d\x76\x29\xd6\x49\x14\xa6\x11\xd2\xf0\x35\x8e\xd0\x4e\x73\x66\xec\x9a\x15\x57\xcf\x99\x00\x1f\x06\x58\x9f\x39\xfa\x3a\x13\x83\x98\xe8\x69\xdc\xc7\x76\x97\x62\x7b\x88\xe3\xc5\x24\x22\x5d\xfe\x27\xb9\xed\x2e\x93\x1e\x9e\x03\xc6\x76\x3d\x06\xe5\x1b\x73\xbe\x66\xf0\x63\x34\xdd\x1f\x61\x13\xbd\x85\x87\x38\xe5\x59\x87\xd9\xfc\x8a\xd8\x27\xef\x9f\x61\x72\xc4\x47\x74\xc4\xec\xb9\xaf\xc5\x53\x0b\x73\x31\x5f\x4d\x9f\x77\xff\xcc\x8d\x19\xb4\x10\x9b\x64\xb7\xff\x6b\xdc\x95\xf8\x05\xc9\x7d\xa2\x40\xfd\x01\x00\x00")
+
+func migrations61_ledger_gap_claimsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations61_ledger_gap_claimsSql,
+		"migrations/61_ledger_gap_claims.sql",
+	)
+}
+
+func migrations61_ledger_gap_claimsSql() (*asset, error) {
+	bytes, err := migrations61_ledger_gap_claimsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/61_ledger_gap_claims.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf8, 0x6d, 0xb0, 0xd6, 0x6f, 0x96, 0x2d, 0x3f, 0x2d, 0x98, 0x42, 0xdf, 0x3f, 0x4a, 0x91, 0x73, 0xe6, 0x61, 0x9, 0xe0, 0xce, 0x79, 0xd5, 0xa4, 0x40, 0xdd, 0xd5, 0xa0, 0x21, 0x6, 0x50, 0xf6}}
+	return a, nil
+}
+
 var _migrations6_create_assets_tableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x3d\x4f\xc3\x30\x18\x84\x77\xff\x8a\x1b\x1d\x91\x0e\x20\xe8\x92\xc9\x34\x16\x58\x18\xa7\xb8\x31\xa2\x53\xe5\x26\x16\x78\x80\x54\xb6\x11\xca\xbf\x47\xaa\x28\xf9\x50\xe6\x7b\xf4\xbc\xef\xdd\x6a\x85\xab\x4f\xff\x1e\x6c\x72\x30\x27\xb2\xd1\x9c\xd5\x1c\x35\xbb\x97\x1c\x1f\x3e\xa6\x2e\xf4\x07\x1b\xa3\x4b\x11\x94\x00\x80\x6f\xb1\xe3\x5a\x30\x89\xad\x16\xcf\x4c\xef\xf1\xc4\xf7\xc8\xcf\xd9\x19\x3c\xa4\xfe\xe4\xf0\xca\xf4\xe6\x91\x69\xba\xbe\xcd\xa0\xaa\x1a\xca\x48\x39\x86\x9a\xae\x1d\xa0\xeb\x9b\x65\xc8\xc7\xf8\xed\xc2\x3f\x76\xb7\x9e\x63\x46\x89\x17\xc3\xe9\xa0\xcc\x47\x3f\xe4\x13\x4b\x46\xb2\x82\x5c\xfa\x09\x55\xf2\xb7\xbf\xf8\xd8\x5f\xee\x54\x6a\x5e\xd9\xec\x84\x7a\xc0\x31\x05\xe7\x40\x27\xb6\x82\x90\xf1\x74\x65\xf7\xf3\x45\x4a\x5d\x6d\x97\xa7\x6b\x6c\x6c\x6c\xeb\x8a\xdf\x00\x00\x00\xff\xff\xfb\x53\x3e\x81\x6e\x01\x00\x00")
 
 func migrations6_create_assets_tableSqlBytes() ([]byte, error) {
@@ -1054,8 +1495,29 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations/39_history_trades_indices.sql":                migrations39_history_trades_indicesSql,
 	"migrations/3_use_sequence_in_history_accounts.sql":       migrations3_use_sequence_in_history_accountsSql,
 	"migrations/40_fix_inner_tx_max_fee_constraint.sql":       migrations40_fix_inner_tx_max_fee_constraintSql,
+	"migrations/41_trade_retention_policies.sql":              migrations41_trade_retention_policiesSql,
+	"migrations/42_history_operations_type_index.sql":         migrations42_history_operations_type_indexSql,
+	"migrations/43_history_transactions_memo_index.sql":       migrations43_history_transactions_memo_indexSql,
+	"migrations/44_history_offers.sql":                        migrations44_history_offersSql,
+	"migrations/45_history_account_events.sql":                migrations45_history_account_eventsSql,
+	"migrations/46_history_operations_source_asset_index.sql": migrations46_history_operations_source_asset_indexSql,
+	"migrations/47_exp_asset_stats_sort_indexes.sql":          migrations47_exp_asset_stats_sort_indexesSql,
+	"migrations/48_history_data_quality_violations.sql":       migrations48_history_data_quality_violationsSql,
+	"migrations/49_partition_history_tables.sql":              migrations49_partition_history_tablesSql,
 	"migrations/4_add_protocol_version.sql":                   migrations4_add_protocol_versionSql,
+	"migrations/50_asset_watches.sql":                         migrations50_asset_watchesSql,
+	"migrations/51_trade_base_is_maker.sql":                   migrations51_trade_base_is_makerSql,
+	"migrations/52_history_account_thresholds.sql":            migrations52_history_account_thresholdsSql,
+	"migrations/53_reingest_checkpoints.sql":                  migrations53_reingest_checkpointsSql,
+	"migrations/54_history_ledger_manifests.sql":              migrations54_history_ledger_manifestsSql,
+	"migrations/55_trades_unique_operation_order.sql":         migrations55_trades_unique_operation_orderSql,
+	"migrations/56_exp_state_quarantine.sql":                  migrations56_exp_state_quarantineSql,
+	"migrations/57_trade_sequence.sql":                        migrations57_trade_sequenceSql,
+	"migrations/58_trust_lines_by_asset_balance.sql":          migrations58_trust_lines_by_asset_balanceSql,
+	"migrations/59_exp_asset_stats_issuer.sql":                migrations59_exp_asset_stats_issuerSql,
 	"migrations/5_create_trades_table.sql":                    migrations5_create_trades_tableSql,
+	"migrations/60_transaction_trade_effect_counts.sql":       migrations60_transaction_trade_effect_countsSql,
+	"migrations/61_ledger_gap_claims.sql":                     migrations61_ledger_gap_claimsSql,
 	"migrations/6_create_assets_table.sql":                    migrations6_create_assets_tableSql,
 	"migrations/7_modify_trades_table.sql":                    migrations7_modify_trades_tableSql,
 	"migrations/8_add_aggregators.sql":                        migrations8_add_aggregatorsSql,
@@ -1067,11 +1529,13 @@ var _bindata = map[string]func() (*asset, error){
 // directory embedded in the file by go-bindata.
 // For example if you run go-bindata on data/... and data contains the
 // following hierarchy:
-//     data/
-//       foo.txt
-//       img/
-//         a.png
-//         b.png
+//
+//	data/
+//	  foo.txt
+//	  img/
+//	    a.png
+//	    b.png
+//
 // then AssetDir("data") would return []string{"foo.txt", "img"},
 // AssetDir("data/img") would return []string{"a.png", "b.png"},
 // AssetDir("foo.txt") and AssetDir("notexist") would return an error, and
@@ -1139,8 +1603,29 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"39_history_trades_indices.sql":                &bintree{migrations39_history_trades_indicesSql, map[string]*bintree{}},
 		"3_use_sequence_in_history_accounts.sql":       &bintree{migrations3_use_sequence_in_history_accountsSql, map[string]*bintree{}},
 		"40_fix_inner_tx_max_fee_constraint.sql":       &bintree{migrations40_fix_inner_tx_max_fee_constraintSql, map[string]*bintree{}},
+		"41_trade_retention_policies.sql":              &bintree{migrations41_trade_retention_policiesSql, map[string]*bintree{}},
+		"42_history_operations_type_index.sql":         &bintree{migrations42_history_operations_type_indexSql, map[string]*bintree{}},
+		"43_history_transactions_memo_index.sql":       &bintree{migrations43_history_transactions_memo_indexSql, map[string]*bintree{}},
+		"44_history_offers.sql":                        &bintree{migrations44_history_offersSql, map[string]*bintree{}},
+		"45_history_account_events.sql":                &bintree{migrations45_history_account_eventsSql, map[string]*bintree{}},
+		"46_history_operations_source_asset_index.sql": &bintree{migrations46_history_operations_source_asset_indexSql, map[string]*bintree{}},
+		"47_exp_asset_stats_sort_indexes.sql":          &bintree{migrations47_exp_asset_stats_sort_indexesSql, map[string]*bintree{}},
+		"48_history_data_quality_violations.sql":       &bintree{migrations48_history_data_quality_violationsSql, map[string]*bintree{}},
+		"49_partition_history_tables.sql":              &bintree{migrations49_partition_history_tablesSql, map[string]*bintree{}},
 		"4_add_protocol_version.sql":                   &bintree{migrations4_add_protocol_versionSql, map[string]*bintree{}},
+		"50_asset_watches.sql":                         &bintree{migrations50_asset_watchesSql, map[string]*bintree{}},
+		"51_trade_base_is_maker.sql":                   &bintree{migrations51_trade_base_is_makerSql, map[string]*bintree{}},
+		"52_history_account_thresholds.sql":            &bintree{migrations52_history_account_thresholdsSql, map[string]*bintree{}},
+		"53_reingest_checkpoints.sql":                  &bintree{migrations53_reingest_checkpointsSql, map[string]*bintree{}},
+		"54_history_ledger_manifests.sql":              &bintree{migrations54_history_ledger_manifestsSql, map[string]*bintree{}},
+		"55_trades_unique_operation_order.sql":         &bintree{migrations55_trades_unique_operation_orderSql, map[string]*bintree{}},
+		"56_exp_state_quarantine.sql":                  &bintree{migrations56_exp_state_quarantineSql, map[string]*bintree{}},
+		"57_trade_sequence.sql":                        &bintree{migrations57_trade_sequenceSql, map[string]*bintree{}},
+		"58_trust_lines_by_asset_balance.sql":          &bintree{migrations58_trust_lines_by_asset_balanceSql, map[string]*bintree{}},
+		"59_exp_asset_stats_issuer.sql":                &bintree{migrations59_exp_asset_stats_issuerSql, map[string]*bintree{}},
 		"5_create_trades_table.sql":                    &bintree{migrations5_create_trades_tableSql, map[string]*bintree{}},
+		"60_transaction_trade_effect_counts.sql":       &bintree{migrations60_transaction_trade_effect_countsSql, map[string]*bintree{}},
+		"61_ledger_gap_claims.sql":                     &bintree{migrations61_ledger_gap_claimsSql, map[string]*bintree{}},
 		"6_create_assets_table.sql":                    &bintree{migrations6_create_assets_tableSql, map[string]*bintree{}},
 		"7_modify_trades_table.sql":                    &bintree{migrations7_modify_trades_tableSql, map[string]*bintree{}},
 		"8_add_aggregators.sql":                        &bintree{migrations8_add_aggregatorsSql, map[string]*bintree{}},
@@ -44,26 +44,36 @@ var Migrations migrate.MigrationSource = &migrate.AssetMigrationSource{
 func Migrate(db *sql.DB, dir MigrateDir, count int) (int, error) {
 	switch dir {
 	case MigrateUp:
-		return migrate.ExecMax(db, "postgres", Migrations, migrate.Up, count)
+		return execMax(db, migrate.Up, count)
 	case MigrateDown:
-		return migrate.ExecMax(db, "postgres", Migrations, migrate.Down, count)
+		return execMax(db, migrate.Down, count)
 	case MigrateRedo:
 
 		if count == 0 {
 			count = 1
 		}
 
-		down, err := migrate.ExecMax(db, "postgres", Migrations, migrate.Down, count)
+		down, err := execMax(db, migrate.Down, count)
 		if err != nil {
 			return down, err
 		}
 
-		return migrate.ExecMax(db, "postgres", Migrations, migrate.Up, down)
+		return execMax(db, migrate.Up, down)
 	default:
 		return 0, errors.New("Invalid migration direction")
 	}
 }
 
+// execMax runs the migrations and records the checksums of the applied
+// migrations so later changes of their files can be detected, see Status.
+func execMax(db *sql.DB, dir migrate.MigrationDirection, count int) (int, error) {
+	n, err := migrate.ExecMax(db, "postgres", Migrations, dir, count)
+	if err != nil {
+		return n, err
+	}
+	return n, recordChecksums(db)
+}
+
 // GetMigrationsUp returns a list of names of any migrations needed in the
 // "up" direction (more recent schema versions).
 func GetMigrationsUp(dbUrl string) (migrationIds []string) {
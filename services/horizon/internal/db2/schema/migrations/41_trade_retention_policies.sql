@@ -0,0 +1,15 @@
+-- +migrate Up
+
+-- Per asset pair overrides of the history retention for history_trades.
+-- retention_seconds = 0 keeps the trades of the pair forever.
+CREATE TABLE trade_retention_policies (
+    base_asset_id bigint NOT NULL REFERENCES history_assets(id),
+    counter_asset_id bigint NOT NULL REFERENCES history_assets(id),
+    retention_seconds bigint NOT NULL CHECK (retention_seconds >= 0),
+    PRIMARY KEY (base_asset_id, counter_asset_id),
+    CHECK (base_asset_id < counter_asset_id)
+);
+
+-- +migrate Down
+
+DROP TABLE trade_retention_policies;
@@ -0,0 +1,7 @@
+-- +migrate Up
+
+CREATE INDEX index_history_operations_on_type_id ON history_operations USING BTREE(type, id);
+
+-- +migrate Down
+
+DROP INDEX index_history_operations_on_type_id;
@@ -0,0 +1,28 @@
+-- +migrate Up
+
+-- Snapshots of offers at the end of every ledger in which they were created,
+-- updated or removed. A snapshot is valid from its ledger_sequence until the
+-- ledger_sequence of the next snapshot of the same offer. Removed offers are
+-- recorded with their last state and deleted = true.
+CREATE TABLE history_offers (
+    offer_id bigint NOT NULL,
+    ledger_sequence integer NOT NULL,
+    seller_id character varying(56) NOT NULL,
+    selling_asset text NOT NULL,
+    buying_asset text NOT NULL,
+    amount bigint NOT NULL,
+    pricen integer NOT NULL,
+    priced integer NOT NULL,
+    price double precision NOT NULL,
+    flags integer NOT NULL,
+    deleted boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (offer_id, ledger_sequence)
+);
+
+CREATE INDEX history_offers_by_ledger ON history_offers USING BTREE(ledger_sequence);
+CREATE INDEX history_offers_by_seller ON history_offers USING BTREE(seller_id);
+CREATE INDEX history_offers_by_assets ON history_offers USING BTREE(selling_asset, buying_asset);
+
+-- +migrate Down
+
+DROP TABLE history_offers;
@@ -0,0 +1,18 @@
+-- +migrate Up
+
+-- Account lifecycle events: creation (type = 1) and merge (type = 2).
+-- related_account is the funder of a created account or the destination of
+-- a merged account, amount is the starting balance or the merged balance.
+CREATE TABLE history_account_events (
+    history_operation_id bigint PRIMARY KEY,
+    account character varying(56) NOT NULL,
+    type smallint NOT NULL,
+    related_account character varying(56) NOT NULL,
+    amount bigint NOT NULL
+);
+
+CREATE INDEX history_account_events_by_account ON history_account_events USING BTREE(account, history_operation_id);
+
+-- +migrate Down
+
+DROP TABLE history_account_events;
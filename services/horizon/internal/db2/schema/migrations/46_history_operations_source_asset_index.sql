@@ -0,0 +1,12 @@
+-- +migrate Up
+
+CREATE INDEX index_history_operations_on_path_payment_source_asset ON history_operations USING BTREE(
+    (details->>'source_asset_type'),
+    (details->>'source_asset_code'),
+    (details->>'source_asset_issuer'),
+    id
+) WHERE type IN (2, 13);
+
+-- +migrate Down
+
+DROP INDEX index_history_operations_on_path_payment_source_asset;
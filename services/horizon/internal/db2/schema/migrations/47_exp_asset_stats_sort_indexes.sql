@@ -0,0 +1,9 @@
+-- +migrate Up
+
+CREATE INDEX exp_asset_stats_by_num_accounts ON exp_asset_stats USING BTREE(num_accounts, asset_code, asset_issuer);
+CREATE INDEX exp_asset_stats_by_amount ON exp_asset_stats USING BTREE((amount::numeric), asset_code, asset_issuer);
+
+-- +migrate Down
+
+DROP INDEX exp_asset_stats_by_num_accounts;
+DROP INDEX exp_asset_stats_by_amount;
@@ -0,0 +1,18 @@
+-- +migrate Up
+
+-- Violations of the data quality rules checked when ingesting transactions.
+-- A transaction can violate multiple rules (or the same rule multiple times)
+-- so rows are not unique.
+CREATE TABLE history_data_quality_violations (
+    history_transaction_id bigint NOT NULL,
+    transaction_hash character varying(64) NOT NULL,
+    rule character varying(64) NOT NULL,
+    details text NOT NULL
+);
+
+CREATE INDEX history_data_quality_violations_by_id ON history_data_quality_violations USING BTREE(history_transaction_id);
+CREATE INDEX history_data_quality_violations_by_rule ON history_data_quality_violations USING BTREE(rule, history_transaction_id);
+
+-- +migrate Down
+
+DROP TABLE history_data_quality_violations;
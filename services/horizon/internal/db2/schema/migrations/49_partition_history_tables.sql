@@ -0,0 +1,46 @@
+-- +migrate Up
+
+-- partition_history_table converts a history table to a table partitioned by
+-- ranges of the toid column key_column. The existing rows are kept in a single
+-- partition covering the ledgers up to the next multiple of partition_ledgers
+-- and named <table_name>_0_<end ledger>, further partitions are created by
+-- horizon before ingesting their first ledger. The indexes of the table are
+-- reused by the partition but the table is scanned once to validate its rows.
+-- The function does nothing if the table is already partitioned and requires
+-- postgres 11 or later.
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION partition_history_table(table_name text, key_column text, partition_ledgers bigint)
+RETURNS void AS $$
+DECLARE
+    last_ledger bigint;
+    end_ledger bigint;
+    partition_name text;
+BEGIN
+    IF current_setting('server_version_num')::int < 110000 THEN
+        RAISE EXCEPTION 'partitioning % requires postgres 11 or later', table_name;
+    END IF;
+
+    IF (SELECT relkind FROM pg_class WHERE oid = table_name::regclass) = 'p' THEN
+        RETURN;
+    END IF;
+
+    EXECUTE format('SELECT COALESCE(MAX(%I), 0) >> 32 FROM %I', key_column, table_name) INTO last_ledger;
+    end_ledger := (last_ledger / partition_ledgers + 1) * partition_ledgers;
+    partition_name := table_name || '_0_' || end_ledger;
+
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', table_name, partition_name);
+    EXECUTE format(
+        'CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS INCLUDING INDEXES) PARTITION BY RANGE (%I)',
+        table_name, partition_name, key_column
+    );
+    EXECUTE format(
+        'ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (MINVALUE) TO (%s)',
+        table_name, partition_name, end_ledger << 32
+    );
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+-- +migrate Down
+
+DROP FUNCTION partition_history_table(text, text, bigint);
@@ -0,0 +1,28 @@
+-- +migrate Up
+
+-- Thresholds on the trust lines of an asset registered by its issuer. The
+-- webhook is notified when the threshold is exceeded after ingesting a
+-- ledger, triggered_ledger is the ledger which exceeded it and is reset when
+-- the value falls back to or below the threshold so the watch fires again.
+--
+-- kind is one of:
+--   holders       the number of authorized trust lines exceeds threshold
+--   holder_share  the balance of a single trust line exceeds threshold, a
+--                 fraction of the amount of the asset held by all trust lines
+CREATE TABLE asset_watches (
+    id bigserial PRIMARY KEY,
+    asset_type int NOT NULL,
+    asset_code character varying(12) NOT NULL,
+    asset_issuer character varying(56) NOT NULL,
+    kind character varying(16) NOT NULL CHECK (kind IN ('holders', 'holder_share')),
+    threshold double precision NOT NULL CHECK (threshold >= 0),
+    webhook_url text NOT NULL,
+    triggered_ledger integer,
+    created_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX asset_watches_by_asset ON asset_watches USING btree (asset_code, asset_issuer, asset_type);
+
+-- +migrate Down
+
+DROP TABLE asset_watches;
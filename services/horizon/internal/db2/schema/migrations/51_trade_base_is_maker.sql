@@ -0,0 +1,17 @@
+-- +migrate Up
+
+-- base_is_maker is true when the base side of the trade is the offer which
+-- was resting in the order book (the maker) and false when the base side
+-- crossed it (the taker). The maker owns the claimed offer, whose core id is
+-- offer_id and which is stored unencoded in base_offer_id or
+-- counter_offer_id, the taker has a synthetic operation id or the id of the
+-- offer created with its remaining amount. Trades ingested before the offer
+-- ids were recorded fall back to base_is_seller: the seller of a trade is the
+-- owner of the claimed offer.
+ALTER TABLE history_trades ADD base_is_maker boolean;
+
+UPDATE history_trades SET base_is_maker = COALESCE(base_offer_id = offer_id, base_is_seller, false);
+
+-- +migrate Down
+
+ALTER TABLE history_trades DROP COLUMN base_is_maker;
@@ -0,0 +1,18 @@
+-- +migrate Up
+
+-- Master weight and thresholds of an account after every set_options
+-- operation which changed them.
+CREATE TABLE history_account_thresholds (
+    history_operation_id bigint PRIMARY KEY,
+    account character varying(56) NOT NULL,
+    master_weight smallint NOT NULL,
+    low_threshold smallint NOT NULL,
+    med_threshold smallint NOT NULL,
+    high_threshold smallint NOT NULL
+);
+
+CREATE INDEX history_account_thresholds_by_account ON history_account_thresholds USING BTREE(account, history_operation_id);
+
+-- +migrate Down
+
+DROP TABLE history_account_thresholds;
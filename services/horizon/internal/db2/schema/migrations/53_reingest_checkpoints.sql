@@ -0,0 +1,17 @@
+-- +migrate Up
+
+-- Ledgers committed by `horizon db reingest range` jobs, identified by their
+-- range, so an interrupted job resumes where it stopped. All the ledgers of
+-- [batch_from, last_ledger] were committed.
+CREATE TABLE reingest_checkpoints (
+    range_from integer NOT NULL,
+    range_to integer NOT NULL,
+    batch_from integer NOT NULL,
+    last_ledger integer NOT NULL,
+    updated_at timestamp without time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (range_from, range_to, batch_from)
+);
+
+-- +migrate Down
+
+DROP TABLE reingest_checkpoints;
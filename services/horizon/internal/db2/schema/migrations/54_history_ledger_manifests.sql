@@ -0,0 +1,13 @@
+-- +migrate Up
+
+-- Checksums of the trades and payments of each ingested ledger, so mirrors
+-- can verify they hold the same data as an upstream Horizon.
+CREATE TABLE history_ledger_manifests (
+    ledger_sequence integer NOT NULL PRIMARY KEY,
+    trades_hash character(64) NOT NULL,
+    payments_hash character(64) NOT NULL
+);
+
+-- +migrate Down
+
+DROP TABLE history_ledger_manifests;
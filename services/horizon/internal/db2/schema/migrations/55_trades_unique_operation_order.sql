@@ -0,0 +1,12 @@
+-- +migrate Up
+
+-- The trade batch insert builder skips or verifies the trades of a retried
+-- ledger with ON CONFLICT (history_operation_id, "order"), which is inferred
+-- from the htrd_pid unique index created by migration 5. The index is not
+-- turned into a constraint because constraints can't be added using an
+-- index of a partitioned table, see partition_history_table.
+CREATE UNIQUE INDEX IF NOT EXISTS htrd_pid ON history_trades USING btree (history_operation_id, "order");
+
+-- +migrate Down
+
+-- htrd_pid belongs to migration 5.
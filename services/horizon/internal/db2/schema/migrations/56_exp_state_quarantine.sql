@@ -0,0 +1,28 @@
+-- +migrate Up
+
+-- Ledger entries whose ingested state doesn't match the history archive
+-- checkpoint, found by the last state audit. The table is replaced by every
+-- audit and cleared when the state is rebuilt.
+--
+-- reason is one of:
+--   mismatch  the entry differs from the checkpoint
+--   missing   the entry of the checkpoint is not ingested
+--   count     the number of ingested entries of entry_type differs from the
+--             checkpoint, ledger_key is empty
+--
+-- expected and actual are the base64 encoded XDR ledger entries of the
+-- checkpoint and of the ingested state, or the numbers of entries for count.
+CREATE TABLE exp_state_quarantine (
+    id bigserial PRIMARY KEY,
+    ledger_sequence integer NOT NULL,
+    entry_type character varying(16) NOT NULL,
+    ledger_key text NOT NULL,
+    reason character varying(16) NOT NULL CHECK (reason IN ('mismatch', 'missing', 'count')),
+    expected text,
+    actual text,
+    created_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+-- +migrate Down
+
+DROP TABLE exp_state_quarantine;
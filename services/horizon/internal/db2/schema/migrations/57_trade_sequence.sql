@@ -0,0 +1,72 @@
+-- +migrate Up notransaction
+
+-- sequence is a global number assigned to trades in the order in which they
+-- are ingested. Unlike the (history_operation_id, order) pair it is a single
+-- monotonically increasing value, so clients merging streams of trades of
+-- different accounts, offers or assets can use it as a cursor. Existing
+-- trades are numbered in the order of their ids.
+--
+-- The migration runs outside of a transaction so the existing trades are
+-- numbered in batches of ledgers, committed separately on Postgres 11 or
+-- later. Every statement can be run again, a failed migration resumes the
+-- numbering after the last committed batch.
+CREATE SEQUENCE IF NOT EXISTS history_trades_sequence_seq;
+
+ALTER TABLE history_trades ADD COLUMN IF NOT EXISTS sequence bigint;
+
+-- +migrate StatementBegin
+DO $$
+DECLARE
+    batch_ledgers constant bigint := 10000;
+    from_ledger bigint;
+    last_ledger bigint;
+    numbered bigint;
+    updated bigint;
+BEGIN
+    SELECT COALESCE(MIN(history_operation_id), 0) >> 32, COALESCE(MAX(history_operation_id), 0) >> 32
+        INTO from_ledger, last_ledger
+        FROM history_trades WHERE sequence IS NULL;
+    SELECT COALESCE(MAX(sequence), 0) INTO numbered FROM history_trades;
+
+    WHILE from_ledger <= last_ledger LOOP
+        UPDATE history_trades SET sequence = batch.sequence
+        FROM (
+            SELECT history_operation_id, "order",
+                numbered + row_number() OVER (ORDER BY history_operation_id, "order") AS sequence
+            FROM history_trades
+            WHERE sequence IS NULL
+                AND history_operation_id >= from_ledger << 32
+                AND history_operation_id < (from_ledger + batch_ledgers) << 32
+        ) AS batch
+        WHERE history_trades.history_operation_id >= from_ledger << 32
+            AND history_trades.history_operation_id < (from_ledger + batch_ledgers) << 32
+            AND history_trades.history_operation_id = batch.history_operation_id
+            AND history_trades."order" = batch."order";
+        GET DIAGNOSTICS updated = ROW_COUNT;
+
+        numbered := numbered + updated;
+        from_ledger := from_ledger + batch_ledgers;
+        IF current_setting('server_version_num')::int >= 110000 THEN
+            COMMIT;
+        END IF;
+    END LOOP;
+END;
+$$;
+-- +migrate StatementEnd
+
+SELECT setval('history_trades_sequence_seq', COALESCE(MAX(sequence), 0) + 1, false) FROM history_trades;
+
+ALTER TABLE history_trades
+    ALTER sequence SET DEFAULT nextval('history_trades_sequence_seq'),
+    ALTER sequence SET NOT NULL;
+ALTER SEQUENCE history_trades_sequence_seq OWNED BY history_trades.sequence;
+
+-- the sequence makes the numbers unique, the index isn't unique because a
+-- unique index of a partitioned history_trades must include
+-- history_operation_id
+CREATE INDEX IF NOT EXISTS htrd_by_sequence ON history_trades USING BTREE(sequence);
+
+-- +migrate Down
+
+DROP INDEX htrd_by_sequence;
+ALTER TABLE history_trades DROP COLUMN sequence;
@@ -0,0 +1,16 @@
+-- +migrate Up
+
+-- flags and home_domain of the issuer are denormalized onto the stats of its
+-- assets by the ingestion, so /assets doesn't join the accounts table and can
+-- filter assets by the flags of their issuer.
+ALTER TABLE exp_asset_stats ADD COLUMN flags integer NOT NULL DEFAULT 0;
+ALTER TABLE exp_asset_stats ADD COLUMN home_domain character varying(32) NOT NULL DEFAULT '';
+
+UPDATE exp_asset_stats
+SET flags = accounts.flags, home_domain = accounts.home_domain
+FROM accounts
+WHERE accounts.account_id = exp_asset_stats.asset_issuer;
+
+-- +migrate Down
+
+ALTER TABLE exp_asset_stats DROP COLUMN flags, DROP COLUMN home_domain;
@@ -0,0 +1,63 @@
+-- +migrate Up notransaction
+
+-- trade_count and effect_count are the numbers of trades and effects
+-- generated by the transaction, set by the ingestion. Existing transactions
+-- are counted from the history_trades and history_effects tables, the id of
+-- the transaction of an operation is its id with the operation bits cleared.
+--
+-- The migration runs outside of a transaction so the existing transactions
+-- are counted in batches of ledgers, committed separately on Postgres 11 or
+-- later. Every statement can be run again, counting a batch again sets the
+-- same counts.
+ALTER TABLE history_transactions ADD COLUMN IF NOT EXISTS trade_count integer NOT NULL DEFAULT 0;
+ALTER TABLE history_transactions ADD COLUMN IF NOT EXISTS effect_count integer NOT NULL DEFAULT 0;
+
+-- +migrate StatementBegin
+DO $$
+DECLARE
+    batch_ledgers constant bigint := 10000;
+    from_ledger bigint;
+    last_ledger bigint;
+BEGIN
+    SELECT COALESCE(MIN(id), 0) >> 32, COALESCE(MAX(id), 0) >> 32
+        INTO from_ledger, last_ledger
+        FROM history_transactions;
+
+    WHILE from_ledger <= last_ledger LOOP
+        UPDATE history_transactions SET trade_count = counts.trade_count
+        FROM (
+            SELECT (history_operation_id >> 12) << 12 AS transaction_id, count(*) AS trade_count
+            FROM history_trades
+            WHERE history_operation_id >= from_ledger << 32
+                AND history_operation_id < (from_ledger + batch_ledgers) << 32
+            GROUP BY 1
+        ) AS counts
+        WHERE history_transactions.id = counts.transaction_id;
+
+        UPDATE history_transactions SET effect_count = counts.effect_count
+        FROM (
+            SELECT (history_operation_id >> 12) << 12 AS transaction_id, count(*) AS effect_count
+            FROM history_effects
+            WHERE history_operation_id >= from_ledger << 32
+                AND history_operation_id < (from_ledger + batch_ledgers) << 32
+            GROUP BY 1
+        ) AS counts
+        WHERE history_transactions.id = counts.transaction_id;
+
+        from_ledger := from_ledger + batch_ledgers;
+        IF current_setting('server_version_num')::int >= 110000 THEN
+            COMMIT;
+        END IF;
+    END LOOP;
+END;
+$$;
+-- +migrate StatementEnd
+
+-- most transactions don't trade, the partial index serves the min_trades
+-- filter of /transactions
+CREATE INDEX IF NOT EXISTS htx_with_trades ON history_transactions USING BTREE(id) WHERE trade_count > 0;
+
+-- +migrate Down
+
+DROP INDEX htx_with_trades;
+ALTER TABLE history_transactions DROP COLUMN trade_count, DROP COLUMN effect_count;
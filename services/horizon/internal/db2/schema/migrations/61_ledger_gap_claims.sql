@@ -0,0 +1,15 @@
+-- +migrate Up
+
+-- Ledger gaps claimed by the ingesting instances backfilling them, so
+-- instances sharing the database backfill different gaps. Claims which were
+-- not refreshed recently belong to stopped instances and are taken over.
+CREATE TABLE ledger_gap_claims (
+    start_sequence integer NOT NULL,
+    end_sequence integer NOT NULL,
+    claimed_at timestamp without time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (start_sequence, end_sequence)
+);
+
+-- +migrate Down
+
+DROP TABLE ledger_gap_claims;
@@ -0,0 +1,241 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	migrate "github.com/rubenv/sql-migrate"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// checksumsTable stores the checksum of the file of every applied migration.
+// It's managed outside of the migrations so checksums of all migrations,
+// including the ones applied before it existed, can be recorded.
+const checksumsTable = "migration_checksums"
+
+// MigrationStatus is the state of a migration in the database. AppliedAt is
+// nil when the migration is pending. Checksum is the SHA-256 of the migration
+// file of this version of horizon, it's empty for applied migrations unknown
+// to this version. AppliedChecksum is the checksum of the file recorded when
+// the migration was applied, it's empty when it was applied before checksums
+// were recorded.
+type MigrationStatus struct {
+	ID              string
+	AppliedAt       *time.Time
+	Checksum        string
+	AppliedChecksum string
+}
+
+// Applied returns true if the migration was applied to the database.
+func (s MigrationStatus) Applied() bool {
+	return s.AppliedAt != nil
+}
+
+// Modified returns true if the migration file changed since the migration
+// was applied.
+func (s MigrationStatus) Modified() bool {
+	return s.Applied() && s.AppliedChecksum != "" && s.Checksum != "" &&
+		s.AppliedChecksum != s.Checksum
+}
+
+// Status returns the state of all the migrations of this version of horizon,
+// in the order they are applied, followed by the applied migrations unknown
+// to this version.
+func Status(db *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations.FindMigrations()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load migrations")
+	}
+	records, err := migrate.GetMigrationRecords(db, "postgres")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load migration records")
+	}
+	checksums, err := appliedChecksums(db)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[string]time.Time{}
+	for _, record := range records {
+		applied[record.Id] = record.AppliedAt
+	}
+
+	var statuses []MigrationStatus
+	known := map[string]bool{}
+	for _, m := range migrations {
+		checksum, err := migrationChecksum(m.Id)
+		if err != nil {
+			return nil, err
+		}
+		status := MigrationStatus{ID: m.Id, Checksum: checksum}
+		if appliedAt, ok := applied[m.Id]; ok {
+			status.AppliedAt = &appliedAt
+			status.AppliedChecksum = checksums[m.Id]
+		}
+		statuses = append(statuses, status)
+		known[m.Id] = true
+	}
+	for _, record := range records {
+		if known[record.Id] {
+			continue
+		}
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			ID:              record.Id,
+			AppliedAt:       &appliedAt,
+			AppliedChecksum: checksums[record.Id],
+		})
+	}
+
+	return statuses, nil
+}
+
+// DryRun writes the SQL of the migrations Migrate would run with the same
+// arguments to w without applying them. Returns the number of migrations
+// which would be run.
+func DryRun(db *sql.DB, w io.Writer, dir MigrateDir, count int) (int, error) {
+	switch dir {
+	case MigrateUp:
+		return dryRun(db, w, migrate.Up, count)
+	case MigrateDown:
+		return dryRun(db, w, migrate.Down, count)
+	case MigrateRedo:
+		if count == 0 {
+			count = 1
+		}
+
+		planned, _, err := migrate.PlanMigration(db, "postgres", Migrations, migrate.Down, count)
+		if err != nil {
+			return 0, err
+		}
+		for _, m := range planned {
+			if err := writeMigration(w, m.Id, migrate.Down, m.Queries); err != nil {
+				return 0, err
+			}
+		}
+		// the migrations are applied again in reverse order
+		for i := len(planned) - 1; i >= 0; i-- {
+			if err := writeMigration(w, planned[i].Id, migrate.Up, planned[i].Up); err != nil {
+				return 0, err
+			}
+		}
+		return len(planned), nil
+	default:
+		return 0, errors.New("Invalid migration direction")
+	}
+}
+
+func dryRun(db *sql.DB, w io.Writer, dir migrate.MigrationDirection, count int) (int, error) {
+	planned, _, err := migrate.PlanMigration(db, "postgres", Migrations, dir, count)
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range planned {
+		if err := writeMigration(w, m.Id, dir, m.Queries); err != nil {
+			return 0, err
+		}
+	}
+	return len(planned), nil
+}
+
+func writeMigration(w io.Writer, id string, dir migrate.MigrationDirection, queries []string) error {
+	direction := "up"
+	if dir == migrate.Down {
+		direction = "down"
+	}
+
+	if _, err := fmt.Fprintf(w, "-- %s (%s)\n", id, direction); err != nil {
+		return errors.Wrap(err, "could not write migration")
+	}
+	for _, query := range queries {
+		if _, err := fmt.Fprintf(w, "%s\n", strings.TrimSpace(query)); err != nil {
+			return errors.Wrap(err, "could not write migration")
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return errors.Wrap(err, "could not write migration")
+}
+
+// migrationChecksum returns the hex encoded SHA-256 of the file of the
+// migration id.
+func migrationChecksum(id string) (string, error) {
+	data, err := Asset("migrations/" + id)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not load migration %s", id)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// appliedChecksums returns the recorded checksums of the applied migrations
+// by id. It's empty when no checksums were recorded yet.
+func appliedChecksums(db *sql.DB) (map[string]string, error) {
+	var exists bool
+	err := db.QueryRow("SELECT to_regclass($1) IS NOT NULL", checksumsTable).Scan(&exists)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not check migration checksums table")
+	}
+
+	checksums := map[string]string{}
+	if !exists {
+		return checksums, nil
+	}
+
+	rows, err := db.Query("SELECT id, checksum FROM " + checksumsTable)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load migration checksums")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, checksum string
+		if err := rows.Scan(&id, &checksum); err != nil {
+			return nil, errors.Wrap(err, "could not scan migration checksum")
+		}
+		checksums[id] = checksum
+	}
+	return checksums, errors.Wrap(rows.Err(), "could not load migration checksums")
+}
+
+// recordChecksums records the checksums of the applied migrations which
+// don't have one yet and removes the checksums of reverted migrations.
+func recordChecksums(db *sql.DB) error {
+	_, err := db.Exec("CREATE TABLE IF NOT EXISTS " + checksumsTable + ` (
+		id text PRIMARY KEY,
+		checksum character varying(64) NOT NULL
+	)`)
+	if err != nil {
+		return errors.Wrap(err, "could not create migration checksums table")
+	}
+
+	records, err := migrate.GetMigrationRecords(db, "postgres")
+	if err != nil {
+		return errors.Wrap(err, "could not load migration records")
+	}
+
+	_, err = db.Exec("DELETE FROM " + checksumsTable + " WHERE id NOT IN (SELECT id FROM gorp_migrations)")
+	if err != nil {
+		return errors.Wrap(err, "could not remove migration checksums")
+	}
+
+	for _, record := range records {
+		checksum, err := migrationChecksum(record.Id)
+		if err != nil {
+			// applied by another version of horizon
+			continue
+		}
+		_, err = db.Exec(
+			"INSERT INTO "+checksumsTable+" (id, checksum) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING",
+			record.Id, checksum,
+		)
+		if err != nil {
+			return errors.Wrap(err, "could not record migration checksum")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"bytes"
+	"testing"
+
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/support/db/dbtest"
+)
+
+func TestWriteMigration(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeMigration(&buf, "1_a.sql", migrate.Down, []string{"DROP TABLE a;\n", " DROP TABLE b;"})
+	require.NoError(t, err)
+	assert.Equal(t, "-- 1_a.sql (down)\nDROP TABLE a;\nDROP TABLE b;\n\n", buf.String())
+}
+
+func TestMigrationChecksum(t *testing.T) {
+	checksum, err := migrationChecksum("1_initial_schema.sql")
+	require.NoError(t, err)
+	assert.Len(t, checksum, 64)
+
+	_, err = migrationChecksum("0_unknown.sql")
+	assert.Error(t, err)
+}
+
+func TestStatusAndDryRun(t *testing.T) {
+	tdb := dbtest.Postgres(t)
+	defer tdb.Close()
+	db := tdb.Open()
+	defer db.Close()
+
+	var buf bytes.Buffer
+	planned, err := DryRun(db.DB, &buf, MigrateUp, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, planned)
+	assert.Contains(t, buf.String(), "-- 1_initial_schema.sql (up)")
+
+	statuses, err := Status(db.DB)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.False(t, status.Applied())
+	}
+
+	_, err = Migrate(db.DB, MigrateUp, 2)
+	require.NoError(t, err)
+
+	statuses, err = Status(db.DB)
+	require.NoError(t, err)
+	for i, status := range statuses {
+		assert.Equal(t, i < 2, status.Applied(), status.ID)
+		if status.Applied() {
+			assert.Equal(t, status.Checksum, status.AppliedChecksum)
+			assert.False(t, status.Modified())
+		}
+	}
+
+	_, err = db.Exec("UPDATE migration_checksums SET checksum = 'x' WHERE id = $1", statuses[0].ID)
+	require.NoError(t, err)
+	statuses, err = Status(db.DB)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Modified())
+
+	_, err = Migrate(db.DB, MigrateDown, 1)
+	require.NoError(t, err)
+	var n int
+	require.NoError(t, db.Get(&n, "SELECT COUNT(*) FROM migration_checksums"))
+	assert.Equal(t, 1, n)
+}
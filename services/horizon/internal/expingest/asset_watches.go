@@ -0,0 +1,151 @@
+package expingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/services/horizon/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	logpkg "github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	// assetWatchWebhookTimeout is the maximum duration of a webhook request.
+	assetWatchWebhookTimeout = 10 * time.Second
+	// assetWatchWebhookAttempts is the number of times a notification is
+	// posted before it's dropped.
+	assetWatchWebhookAttempts = 5
+	// assetWatchWebhookBackoff is the delay before the first retry of a
+	// notification, it's doubled after every attempt.
+	assetWatchWebhookBackoff = time.Second
+)
+
+var assetWatchClient = &http.Client{Timeout: assetWatchWebhookTimeout}
+
+// AssetWatchNotification is the JSON body POSTed to the webhook of an asset
+// watch when its threshold is exceeded. ID is an idempotency key of the
+// notification, the same for every attempt to post it, of the form
+// "<toid of the ledger>-<watch id>-asset_watches".
+type AssetWatchNotification struct {
+	ID          string  `json:"id"`
+	WatchID     int64   `json:"watch_id"`
+	AssetType   string  `json:"asset_type"`
+	AssetCode   string  `json:"asset_code"`
+	AssetIssuer string  `json:"asset_issuer"`
+	Kind        string  `json:"kind"`
+	Threshold   float64 `json:"threshold"`
+	Value       float64 `json:"value"`
+	Ledger      uint32  `json:"ledger"`
+	Holder      string  `json:"holder,omitempty"`
+}
+
+func newAssetWatchNotification(event history.AssetWatchEvent) AssetWatchNotification {
+	return AssetWatchNotification{
+		ID:          ingest.EventID(event.Ledger, event.ID, "asset_watches"),
+		WatchID:     event.ID,
+		AssetType:   xdr.AssetTypeToString[event.AssetType],
+		AssetCode:   event.AssetCode,
+		AssetIssuer: event.AssetIssuer,
+		Kind:        event.Kind,
+		Threshold:   event.Threshold,
+		Value:       event.Value,
+		Ledger:      event.Ledger,
+		Holder:      event.Holder,
+	}
+}
+
+// notifyAssetWatches posts the events to their webhooks in the background so
+// slow webhooks don't delay ingestion. Failed notifications are retried with
+// an exponential backoff, they are not persisted so the notifications not
+// delivered yet are lost when horizon stops.
+func (s *system) notifyAssetWatches(events []history.AssetWatchEvent) {
+	for _, event := range events {
+		s.wg.Add(1)
+		go func(event history.AssetWatchEvent) {
+			defer s.wg.Done()
+
+			err := deliverAssetWatchNotification(
+				s.ctx,
+				assetWatchClient,
+				event.WebhookURL,
+				newAssetWatchNotification(event),
+				assetWatchWebhookBackoff,
+			)
+			logger := log.WithFields(logpkg.F{
+				"watch_id": event.ID,
+				"ledger":   event.Ledger,
+			})
+			if err != nil {
+				logger.WithError(err).Warn("Error notifying asset watch webhook")
+				return
+			}
+			logger.Info("Notified asset watch webhook")
+		}(event)
+	}
+}
+
+// deliverAssetWatchNotification posts notification until the webhook accepts
+// it, up to assetWatchWebhookAttempts times, waiting backoff before the first
+// retry and twice as long before every next one.
+func deliverAssetWatchNotification(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+	notification AssetWatchNotification,
+	backoff time.Duration,
+) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = postAssetWatchNotification(ctx, client, url, notification)
+		if err == nil || attempt == assetWatchWebhookAttempts {
+			return err
+		}
+		log.WithFields(logpkg.F{
+			"watch_id": notification.WatchID,
+			"ledger":   notification.Ledger,
+			"attempt":  attempt,
+		}).WithError(err).Info("Error notifying asset watch webhook, retrying")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func postAssetWatchNotification(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+	notification AssetWatchNotification,
+) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal notification")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not create request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "could not post notification")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package expingest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+)
+
+func TestPostAssetWatchNotification(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notification := AssetWatchNotification{WatchID: 1, Kind: "holders", Ledger: 2}
+	assert.NoError(t, postAssetWatchNotification(context.Background(), server.Client(), server.URL, notification))
+
+	status = http.StatusInternalServerError
+	assert.EqualError(
+		t,
+		postAssetWatchNotification(context.Background(), server.Client(), server.URL, notification),
+		"webhook responded with status 500",
+	)
+}
+
+func TestDeliverAssetWatchNotification(t *testing.T) {
+	failures := 2
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notification := newAssetWatchNotification(history.AssetWatchEvent{
+		AssetWatch: history.AssetWatch{ID: 7, Kind: history.AssetWatchHolders},
+		Ledger:     2,
+	})
+	assert.Equal(t, "8589934592-7-asset_watches", notification.ID)
+
+	assert.NoError(t, deliverAssetWatchNotification(
+		context.Background(), server.Client(), server.URL, notification, time.Millisecond,
+	))
+	assert.Equal(t, 3, requests)
+
+	// the notification is dropped after the last attempt
+	failures, requests = assetWatchWebhookAttempts, 0
+	assert.EqualError(
+		t,
+		deliverAssetWatchNotification(context.Background(), server.Client(), server.URL, notification, time.Millisecond),
+		"webhook responded with status 503",
+	)
+	assert.Equal(t, assetWatchWebhookAttempts, requests)
+}
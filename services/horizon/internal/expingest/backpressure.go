@@ -0,0 +1,100 @@
+package expingest
+
+import (
+	"sync"
+)
+
+// ledgersBehindWarningThreshold is the number of ledgers of the network not
+// ingested yet from which live ingestion is reported as falling behind.
+const ledgersBehindWarningThreshold = 10
+
+// CatchupPause pauses live ingestion while catch-up jobs, like the backfill of
+// ledger gaps, run so they don't compete with it for the database. Live
+// ingestion resumes, and catches up with stellar-core, once all the jobs are
+// done. It is safe for concurrent use and a nil CatchupPause never pauses
+// ingestion.
+type CatchupPause struct {
+	mutex sync.Mutex
+	jobs  int
+}
+
+// NewCatchupPause creates a CatchupPause with no running jobs.
+func NewCatchupPause() *CatchupPause {
+	return &CatchupPause{}
+}
+
+// Begin pauses live ingestion until the matching call to End.
+func (p *CatchupPause) Begin() {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.jobs == 0 {
+		log.Info("Pausing live ingestion while a catch-up job runs")
+	}
+	p.jobs++
+}
+
+// End marks the end of a job started by Begin.
+func (p *CatchupPause) End() {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.jobs--
+	if p.jobs == 0 {
+		log.Info("Resuming live ingestion")
+	}
+}
+
+// Paused returns true while catch-up jobs are running.
+func (p *CatchupPause) Paused() bool {
+	if p == nil {
+		return false
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.jobs > 0
+}
+
+// pausedForCatchup returns true if cur must not run because catch-up jobs are
+// running. Only live ingestion is paused, reingestion is run by the jobs.
+func (s *system) pausedForCatchup(cur stateMachineNode) bool {
+	if _, ok := cur.(reingestHistoryRangeState); ok {
+		return false
+	}
+	return s.config.CatchupPause.Paused()
+}
+
+// observeLedgersBehind updates LedgersBehindGauge with the number of ledgers
+// of the network which are not ingested yet, lastIngested being the last
+// ingested ledger, and logs when live ingestion starts falling behind, or
+// catches up. The latest ledger of the network is the latest ledger known to
+// stellar-core (Config.LatestNetworkLedger) because captive core only reads a
+// few ledgers ahead of ingestion, latestLedgerCore, the latest ledger of the
+// ledger backend, is used when it is unknown. Returns the number of ledgers
+// behind.
+func (s *system) observeLedgersBehind(lastIngested, latestLedgerCore uint32) uint32 {
+	latest := latestLedgerCore
+	if s.config.LatestNetworkLedger != nil {
+		if network := s.config.LatestNetworkLedger(); network > latest {
+			latest = network
+		}
+	}
+	var behind uint32
+	if latest > lastIngested {
+		behind = latest - lastIngested
+	}
+
+	s.Metrics().LedgersBehindGauge.Set(float64(behind))
+	if behind >= ledgersBehindWarningThreshold && !s.fallingBehind {
+		s.fallingBehind = true
+		log.WithField("ledgers_behind", behind).Warn("Ingestion is falling behind the network")
+	} else if behind == 0 && s.fallingBehind {
+		s.fallingBehind = false
+		log.Info("Ingestion caught up with the network")
+	}
+	return behind
+}
@@ -0,0 +1,98 @@
+package expingest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCatchupPause(t *testing.T) {
+	var pause *CatchupPause
+	pause.Begin()
+	assert.False(t, pause.Paused())
+	pause.End()
+
+	pause = NewCatchupPause()
+	assert.False(t, pause.Paused())
+	pause.Begin()
+	pause.Begin()
+	assert.True(t, pause.Paused())
+	pause.End()
+	assert.True(t, pause.Paused())
+	pause.End()
+	assert.False(t, pause.Paused())
+}
+
+func TestPausedForCatchup(t *testing.T) {
+	pause := NewCatchupPause()
+	s := &system{config: Config{CatchupPause: pause}}
+	assert.False(t, s.pausedForCatchup(resumeState{latestSuccessfullyProcessedLedger: 100}))
+
+	pause.Begin()
+	assert.True(t, s.pausedForCatchup(resumeState{latestSuccessfullyProcessedLedger: 100}))
+	assert.True(t, s.pausedForCatchup(startState{}))
+	// the catch-up jobs reingest ledgers
+	assert.False(t, s.pausedForCatchup(reingestHistoryRangeState{fromLedger: 5, toLedger: 6}))
+}
+
+func TestGapBackfillerPausesLiveIngestion(t *testing.T) {
+	q := &mockLedgerGapsQ{}
+	gap := history.LedgerGap{StartSequence: 5, EndSequence: 6}
+	q.On("GetLedgerGaps").Return([]history.LedgerGap{gap}, nil).Once()
+	q.expectClaims(gap)
+
+	pause := NewCatchupPause()
+	system := &mockSystem{}
+	system.On("ReingestRange", uint32(5), uint32(6), false).
+		Run(func(mock.Arguments) { assert.True(t, pause.Paused()) }).
+		Return(nil).Once()
+	system.On("Shutdown").Return()
+	defer mock.AssertExpectationsForObjects(t, q, system)
+
+	backfiller := newGapBackfiller(Config{CatchupPause: pause}, q, func(config Config) (System, error) {
+		return system, nil
+	})
+	assert.NoError(t, backfiller.Run(context.Background()))
+	assert.False(t, pause.Paused())
+}
+
+func TestObserveLedgersBehind(t *testing.T) {
+	s := &system{}
+	s.initMetrics()
+
+	assert.Equal(t, uint32(3), s.observeLedgersBehind(100, 103))
+	assert.Equal(t, float64(3), testutil.ToFloat64(s.Metrics().LedgersBehindGauge))
+	assert.False(t, s.fallingBehind)
+
+	s.observeLedgersBehind(100, 100+ledgersBehindWarningThreshold)
+	assert.True(t, s.fallingBehind)
+	s.observeLedgersBehind(100, 101)
+	assert.True(t, s.fallingBehind)
+
+	s.observeLedgersBehind(100, 100)
+	assert.Equal(t, float64(0), testutil.ToFloat64(s.Metrics().LedgersBehindGauge))
+	assert.False(t, s.fallingBehind)
+}
+
+func TestObserveLedgersBehindNetwork(t *testing.T) {
+	network := uint32(0)
+	s := &system{config: Config{LatestNetworkLedger: func() uint32 { return network }}}
+	s.initMetrics()
+
+	// the network ledger is unknown
+	assert.Equal(t, uint32(2), s.observeLedgersBehind(100, 102))
+
+	// captive core only reads a few ledgers ahead of ingestion
+	network = 150
+	assert.Equal(t, uint32(50), s.observeLedgersBehind(100, 102))
+	assert.Equal(t, float64(50), testutil.ToFloat64(s.Metrics().LedgersBehindGauge))
+	assert.True(t, s.fallingBehind)
+
+	// stellar-core info is refreshed periodically so it may be stale
+	assert.Equal(t, uint32(0), s.observeLedgersBehind(152, 152))
+	assert.False(t, s.fallingBehind)
+}
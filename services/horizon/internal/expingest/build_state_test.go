@@ -259,6 +259,9 @@ func (s *BuildStateTestSuite) TestUpdateLastLedgerExpIngestAfterIngestReturnsErr
 	s.historyQ.On("UpdateExpIngestVersion", CurrentVersion).
 		Return(nil).
 		Once()
+	s.historyQ.On("UpdateNetworkPassphrase", "").
+		Return(nil).
+		Once()
 	s.historyQ.On("UpdateLastLedgerExpIngest", s.checkpointLedger).
 		Return(errors.New("my error")).
 		Once()
@@ -297,6 +300,9 @@ func (s *BuildStateTestSuite) TestUpdateCommitReturnsError() {
 	s.historyQ.On("UpdateExpIngestVersion", CurrentVersion).
 		Return(nil).
 		Once()
+	s.historyQ.On("UpdateNetworkPassphrase", "").
+		Return(nil).
+		Once()
 	s.historyQ.On("Commit").
 		Return(errors.New("my error")).
 		Once()
@@ -319,6 +325,9 @@ func (s *BuildStateTestSuite) TestBuildStateSucceeds() {
 	s.historyQ.On("UpdateExpIngestVersion", CurrentVersion).
 		Return(nil).
 		Once()
+	s.historyQ.On("UpdateNetworkPassphrase", "").
+		Return(nil).
+		Once()
 	s.historyQ.On("Commit").
 		Return(nil).
 		Once()
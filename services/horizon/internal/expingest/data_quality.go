@@ -0,0 +1,64 @@
+package expingest
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// pendingDataQualityViolations counts the data quality violations found in
+// the ledgers processed in the current ingestion transaction. The counts are
+// added to the metric once the transaction is committed so that ledgers
+// rolled back and processed again are not counted twice.
+type pendingDataQualityViolations struct {
+	metric *prometheus.CounterVec
+
+	mutex  sync.Mutex
+	counts map[string]int
+}
+
+func newPendingDataQualityViolations(metric *prometheus.CounterVec) *pendingDataQualityViolations {
+	return &pendingDataQualityViolations{metric: metric}
+}
+
+// Add implements processors.DataQualityViolationsCounter.
+func (p *pendingDataQualityViolations) Add(rule string) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.counts == nil {
+		p.counts = map[string]int{}
+	}
+	p.counts[rule]++
+}
+
+// discard drops the violations found in a transaction which was not
+// committed.
+func (p *pendingDataQualityViolations) discard() {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.counts = nil
+}
+
+// commit adds the violations found in a committed transaction to the
+// metric.
+func (p *pendingDataQualityViolations) commit() {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for rule, count := range p.counts {
+		p.metric.WithLabelValues(rule).Add(float64(count))
+	}
+	p.counts = nil
+}
@@ -0,0 +1,38 @@
+package expingest
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingDataQualityViolations(t *testing.T) {
+	metric := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "violations"}, []string{"rule"})
+	pending := newPendingDataQualityViolations(metric)
+
+	// violations of a rolled back transaction are not counted
+	pending.Add("fee_charged")
+	pending.discard()
+	pending.commit()
+	assert.Equal(t, float64(0), testutil.ToFloat64(metric.WithLabelValues("fee_charged")))
+
+	pending.Add("fee_charged")
+	pending.Add("fee_charged")
+	pending.Add("trade_amounts")
+	assert.Equal(t, float64(0), testutil.ToFloat64(metric.WithLabelValues("fee_charged")))
+	pending.commit()
+	assert.Equal(t, float64(2), testutil.ToFloat64(metric.WithLabelValues("fee_charged")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metric.WithLabelValues("trade_amounts")))
+
+	// committed violations are counted once
+	pending.commit()
+	assert.Equal(t, float64(2), testutil.ToFloat64(metric.WithLabelValues("fee_charged")))
+
+	var disabled *pendingDataQualityViolations
+	// must not panic
+	disabled.Add("fee_charged")
+	disabled.discard()
+	disabled.commit()
+}
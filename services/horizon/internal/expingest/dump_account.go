@@ -0,0 +1,235 @@
+package expingest
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/exp/ingest/adapters"
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountSnapshot contains all ledger entries owned by a single account at
+// a given checkpoint ledger, as found in the history archive buckets.
+type AccountSnapshot struct {
+	AccountID  string                  `json:"account_id"`
+	Checkpoint uint32                  `json:"checkpoint"`
+	Account    *AccountSnapshotAccount `json:"account"`
+	TrustLines []AccountSnapshotLine   `json:"trustlines"`
+	Offers     []AccountSnapshotOffer  `json:"offers"`
+	Data       []AccountSnapshotData   `json:"data"`
+}
+
+// AccountSnapshotSigner is a signer of the account entry.
+type AccountSnapshotSigner struct {
+	Key    string `json:"key"`
+	Weight uint32 `json:"weight"`
+}
+
+// AccountSnapshotAccount is the account entry. XDR field contains the base64
+// encoded ledger entry so it can be decoded without any loss of information.
+type AccountSnapshotAccount struct {
+	Balance              string                  `json:"balance"`
+	Sequence             int64                   `json:"sequence"`
+	NumSubEntries        uint32                  `json:"num_subentries"`
+	InflationDestination string                  `json:"inflation_destination,omitempty"`
+	HomeDomain           string                  `json:"home_domain,omitempty"`
+	Flags                uint32                  `json:"flags"`
+	MasterWeight         byte                    `json:"master_weight"`
+	ThresholdLow         byte                    `json:"threshold_low"`
+	ThresholdMedium      byte                    `json:"threshold_medium"`
+	ThresholdHigh        byte                    `json:"threshold_high"`
+	Signers              []AccountSnapshotSigner `json:"signers"`
+	LastModifiedLedger   uint32                  `json:"last_modified_ledger"`
+	XDR                  string                  `json:"xdr"`
+}
+
+// AccountSnapshotLine is a trust line entry of the account.
+type AccountSnapshotLine struct {
+	AssetType          string `json:"asset_type"`
+	AssetCode          string `json:"asset_code"`
+	AssetIssuer        string `json:"asset_issuer"`
+	Balance            string `json:"balance"`
+	Limit              string `json:"limit"`
+	Flags              uint32 `json:"flags"`
+	LastModifiedLedger uint32 `json:"last_modified_ledger"`
+	XDR                string `json:"xdr"`
+}
+
+// AccountSnapshotOffer is an offer entry created by the account.
+type AccountSnapshotOffer struct {
+	OfferID            int64  `json:"offer_id"`
+	Selling            string `json:"selling"`
+	Buying             string `json:"buying"`
+	Amount             string `json:"amount"`
+	PriceN             int32  `json:"price_n"`
+	PriceD             int32  `json:"price_d"`
+	Price              string `json:"price"`
+	Flags              uint32 `json:"flags"`
+	LastModifiedLedger uint32 `json:"last_modified_ledger"`
+	XDR                string `json:"xdr"`
+}
+
+// AccountSnapshotData is a data entry of the account. Value is base64 encoded.
+type AccountSnapshotData struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	LastModifiedLedger uint32 `json:"last_modified_ledger"`
+	XDR                string `json:"xdr"`
+}
+
+// DumpAccount streams the state of the ledger at the given checkpoint from
+// the history archive and extracts all the entries belonging to accountID.
+// It does not require a running Stellar-Core.
+func DumpAccount(
+	ctx context.Context,
+	archive historyarchive.ArchiveInterface,
+	accountID string,
+	checkpointLedger uint32,
+) (AccountSnapshot, error) {
+	if !historyarchive.IsCheckpoint(checkpointLedger) {
+		return AccountSnapshot{}, errors.Errorf("ledger %d is not a checkpoint ledger", checkpointLedger)
+	}
+
+	changeReader, err := adapters.MakeHistoryArchiveAdapter(archive).GetState(ctx, checkpointLedger)
+	if err != nil {
+		return AccountSnapshot{}, errors.Wrap(err, "Error creating HAS reader")
+	}
+	defer changeReader.Close()
+
+	snapshot, err := dumpAccountFromReader(changeReader, accountID)
+	if err != nil {
+		return AccountSnapshot{}, err
+	}
+	snapshot.Checkpoint = checkpointLedger
+	return snapshot, nil
+}
+
+func dumpAccountFromReader(changeReader io.ChangeReader, accountID string) (AccountSnapshot, error) {
+	var address xdr.AccountId
+	if err := address.SetAddress(accountID); err != nil {
+		return AccountSnapshot{}, errors.Wrap(err, "invalid account id")
+	}
+
+	snapshot := AccountSnapshot{
+		AccountID:  accountID,
+		TrustLines: []AccountSnapshotLine{},
+		Offers:     []AccountSnapshotOffer{},
+		Data:       []AccountSnapshotData{},
+	}
+
+	for {
+		change, err := changeReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return AccountSnapshot{}, errors.Wrap(err, "Error reading from change reader")
+		}
+
+		if change.Post == nil {
+			continue
+		}
+
+		if err := snapshot.add(*change.Post, address); err != nil {
+			return AccountSnapshot{}, err
+		}
+	}
+
+	return snapshot, nil
+}
+
+func (s *AccountSnapshot) add(entry xdr.LedgerEntry, address xdr.AccountId) error {
+	var owner xdr.AccountId
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		owner = entry.Data.MustAccount().AccountId
+	case xdr.LedgerEntryTypeTrustline:
+		owner = entry.Data.MustTrustLine().AccountId
+	case xdr.LedgerEntryTypeOffer:
+		owner = entry.Data.MustOffer().SellerId
+	case xdr.LedgerEntryTypeData:
+		owner = entry.Data.MustData().AccountId
+	default:
+		return nil
+	}
+
+	if !owner.Equals(address) {
+		return nil
+	}
+
+	entryXDR, err := xdr.MarshalBase64(entry)
+	if err != nil {
+		return errors.Wrap(err, "Error marshaling ledger entry")
+	}
+	lastModifiedLedger := uint32(entry.LastModifiedLedgerSeq)
+
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		account := entry.Data.MustAccount()
+		row := &AccountSnapshotAccount{
+			Balance:            amount.String(account.Balance),
+			Sequence:           int64(account.SeqNum),
+			NumSubEntries:      uint32(account.NumSubEntries),
+			HomeDomain:         string(account.HomeDomain),
+			Flags:              uint32(account.Flags),
+			MasterWeight:       account.MasterKeyWeight(),
+			ThresholdLow:       account.ThresholdLow(),
+			ThresholdMedium:    account.ThresholdMedium(),
+			ThresholdHigh:      account.ThresholdHigh(),
+			Signers:            []AccountSnapshotSigner{},
+			LastModifiedLedger: lastModifiedLedger,
+			XDR:                entryXDR,
+		}
+		if account.InflationDest != nil {
+			row.InflationDestination = account.InflationDest.Address()
+		}
+		for _, signer := range account.Signers {
+			row.Signers = append(row.Signers, AccountSnapshotSigner{
+				Key:    signer.Key.Address(),
+				Weight: uint32(signer.Weight),
+			})
+		}
+		s.Account = row
+	case xdr.LedgerEntryTypeTrustline:
+		trustLine := entry.Data.MustTrustLine()
+		row := AccountSnapshotLine{
+			Balance:            amount.String(trustLine.Balance),
+			Limit:              amount.String(trustLine.Limit),
+			Flags:              uint32(trustLine.Flags),
+			LastModifiedLedger: lastModifiedLedger,
+			XDR:                entryXDR,
+		}
+		if err := trustLine.Asset.Extract(&row.AssetType, &row.AssetCode, &row.AssetIssuer); err != nil {
+			return errors.Wrap(err, "Error extracting trust line asset")
+		}
+		s.TrustLines = append(s.TrustLines, row)
+	case xdr.LedgerEntryTypeOffer:
+		offer := entry.Data.MustOffer()
+		s.Offers = append(s.Offers, AccountSnapshotOffer{
+			OfferID:            int64(offer.OfferId),
+			Selling:            offer.Selling.String(),
+			Buying:             offer.Buying.String(),
+			Amount:             amount.String(offer.Amount),
+			PriceN:             int32(offer.Price.N),
+			PriceD:             int32(offer.Price.D),
+			Price:              offer.Price.String(),
+			Flags:              uint32(offer.Flags),
+			LastModifiedLedger: lastModifiedLedger,
+			XDR:                entryXDR,
+		})
+	case xdr.LedgerEntryTypeData:
+		data := entry.Data.MustData()
+		s.Data = append(s.Data, AccountSnapshotData{
+			Name:               string(data.DataName),
+			Value:              base64.StdEncoding.EncodeToString(data.DataValue),
+			LastModifiedLedger: lastModifiedLedger,
+			XDR:                entryXDR,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package expingest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/xdr"
+)
+
+func TestDumpAccountFromReader(t *testing.T) {
+	other := xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB")
+
+	entries := []xdr.LedgerEntry{
+		{
+			LastModifiedLedgerSeq: 10,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId:  issuer,
+					Balance:    200000000,
+					SeqNum:     5,
+					Thresholds: [4]byte{1, 2, 3, 4},
+					Signers: []xdr.Signer{
+						{Key: xdr.MustSigner(other.Address()), Weight: 2},
+					},
+				},
+			},
+		},
+		{
+			LastModifiedLedgerSeq: 11,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId: other,
+					Balance:   100,
+				},
+			},
+		},
+		{
+			LastModifiedLedgerSeq: 12,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeTrustline,
+				TrustLine: &xdr.TrustLineEntry{
+					AccountId: issuer,
+					Asset:     eurAsset,
+					Balance:   10000000,
+					Limit:     20000000,
+					Flags:     1,
+				},
+			},
+		},
+		{
+			LastModifiedLedgerSeq: 13,
+			Data: xdr.LedgerEntryData{
+				Type:  xdr.LedgerEntryTypeOffer,
+				Offer: &eurOffer,
+			},
+		},
+		{
+			LastModifiedLedgerSeq: 14,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeData,
+				Data: &xdr.DataEntry{
+					AccountId: issuer,
+					DataName:  "test",
+					DataValue: []byte("value"),
+				},
+			},
+		},
+	}
+
+	reader := &io.MockChangeReader{}
+	for i := range entries {
+		reader.On("Read").Return(io.Change{
+			Type: entries[i].Data.Type,
+			Post: &entries[i],
+		}, nil).Once()
+	}
+	reader.On("Read").Return(io.Change{}, io.EOF).Once()
+
+	snapshot, err := dumpAccountFromReader(reader, issuer.Address())
+	assert.NoError(t, err)
+	reader.AssertExpectations(t)
+
+	assert.Equal(t, issuer.Address(), snapshot.AccountID)
+	if assert.NotNil(t, snapshot.Account) {
+		assert.Equal(t, "20.0000000", snapshot.Account.Balance)
+		assert.Equal(t, int64(5), snapshot.Account.Sequence)
+		assert.Equal(t, byte(1), snapshot.Account.MasterWeight)
+		assert.Equal(t, byte(4), snapshot.Account.ThresholdHigh)
+		assert.Equal(t, uint32(10), snapshot.Account.LastModifiedLedger)
+		assert.Equal(t, []AccountSnapshotSigner{{Key: other.Address(), Weight: 2}}, snapshot.Account.Signers)
+		assert.NotEmpty(t, snapshot.Account.XDR)
+	}
+
+	if assert.Len(t, snapshot.TrustLines, 1) {
+		assert.Equal(t, "credit_alphanum4", snapshot.TrustLines[0].AssetType)
+		assert.Equal(t, "eur", snapshot.TrustLines[0].AssetCode)
+		assert.Equal(t, issuer.Address(), snapshot.TrustLines[0].AssetIssuer)
+		assert.Equal(t, "1.0000000", snapshot.TrustLines[0].Balance)
+		assert.Equal(t, "2.0000000", snapshot.TrustLines[0].Limit)
+	}
+
+	if assert.Len(t, snapshot.Offers, 1) {
+		assert.Equal(t, int64(4), snapshot.Offers[0].OfferID)
+		assert.Equal(t, "0.0000500", snapshot.Offers[0].Amount)
+		assert.Equal(t, "1.0000000", snapshot.Offers[0].Price)
+	}
+
+	if assert.Len(t, snapshot.Data, 1) {
+		assert.Equal(t, "test", snapshot.Data[0].Name)
+		assert.Equal(t, "dmFsdWU=", snapshot.Data[0].Value)
+	}
+}
+
+func TestDumpAccountFromReaderInvalidAccount(t *testing.T) {
+	reader := &io.MockChangeReader{}
+	_, err := dumpAccountFromReader(reader, "GINVALID")
+	assert.EqualError(t, err, "invalid account id: invalid version byte")
+}
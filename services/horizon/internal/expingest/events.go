@@ -0,0 +1,88 @@
+package expingest
+
+import (
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/ingest"
+)
+
+// publishLedgerIngested publishes the events of a committed ledger to the
+// event bus: a TableChanged event for every table changed by the ledger
+// followed by a LedgerIngested event.
+func (s *system) publishLedgerIngested(
+	sequence uint32,
+	changeStats io.StatsChangeProcessorResults,
+	transactionStats io.StatsLedgerTransactionProcessorResults,
+) {
+	if s.config.EventBus == nil {
+		return
+	}
+
+	tables := []ingest.Event{
+		{Table: "history_transactions", Created: transactionStats.Transactions},
+		{Table: "history_operations", Created: transactionStats.Operations},
+		{
+			Table:   "accounts",
+			Created: changeStats.AccountsCreated,
+			Updated: changeStats.AccountsUpdated,
+			Removed: changeStats.AccountsRemoved,
+		},
+		{
+			Table:   "accounts_data",
+			Created: changeStats.DataCreated,
+			Updated: changeStats.DataUpdated,
+			Removed: changeStats.DataRemoved,
+		},
+		{
+			Table:   "offers",
+			Created: changeStats.OffersCreated,
+			Updated: changeStats.OffersUpdated,
+			Removed: changeStats.OffersRemoved,
+		},
+		{
+			Table:   "trust_lines",
+			Created: changeStats.TrustLinesCreated,
+			Updated: changeStats.TrustLinesUpdated,
+			Removed: changeStats.TrustLinesRemoved,
+		},
+	}
+
+	// the order of an event is the position of its table in tables, not in
+	// the published events, so IDs don't depend on the other tables changed
+	// by the ledger.
+	events := make([]ingest.Event, 0, len(tables)+1)
+	for order, event := range tables {
+		if event.Created == 0 && event.Updated == 0 && event.Removed == 0 {
+			continue
+		}
+		event.ID = ingest.EventID(sequence, int64(order), event.Table)
+		event.Type = ingest.TableChanged
+		event.Sequence = sequence
+		events = append(events, event)
+	}
+	events = append(events, ledgerIngestedEvent(sequence))
+
+	s.config.EventBus.Publish(events...)
+}
+
+// republishLastLedgerIngested publishes again the LedgerIngested event of
+// the last ledger committed before live ingestion resumed, once per system,
+// as it may not have been published before the restart.
+func (s *system) republishLastLedgerIngested(sequence uint32) {
+	if s.config.EventBus == nil || s.lastLedgerRepublished || sequence == 0 {
+		return
+	}
+	s.lastLedgerRepublished = true
+	s.config.EventBus.Publish(ledgerIngestedEvent(sequence))
+}
+
+func ledgerIngestedEvent(sequence uint32) ingest.Event {
+	return ingest.Event{
+		ID:       ingest.EventID(sequence, ledgerIngestedEventOrder, ingest.LedgerIngested),
+		Type:     ingest.LedgerIngested,
+		Sequence: sequence,
+	}
+}
+
+// ledgerIngestedEventOrder is the order of LedgerIngested events, after the
+// TableChanged events of all the tables.
+const ledgerIngestedEventOrder = 6
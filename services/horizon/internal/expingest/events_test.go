@@ -0,0 +1,52 @@
+package expingest
+
+import (
+	"testing"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/ingest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishLedgerIngested(t *testing.T) {
+	// publishing without a bus is a no-op
+	(&system{}).publishLedgerIngested(3, io.StatsChangeProcessorResults{}, io.StatsLedgerTransactionProcessorResults{})
+
+	bus := ingest.NewBus()
+	subscription := bus.Subscribe(10)
+	defer subscription.Close()
+
+	s := &system{config: Config{EventBus: bus}}
+	s.publishLedgerIngested(
+		3,
+		io.StatsChangeProcessorResults{AccountsCreated: 1, AccountsUpdated: 2, OffersRemoved: 1},
+		io.StatsLedgerTransactionProcessorResults{Transactions: 2, Operations: 5},
+	)
+
+	assert.Equal(t, ingest.Event{ID: "12884901888-0-history_transactions", Type: ingest.TableChanged, Sequence: 3, Table: "history_transactions", Created: 2}, <-subscription.C)
+	assert.Equal(t, ingest.Event{ID: "12884901888-1-history_operations", Type: ingest.TableChanged, Sequence: 3, Table: "history_operations", Created: 5}, <-subscription.C)
+	assert.Equal(t, ingest.Event{ID: "12884901888-2-accounts", Type: ingest.TableChanged, Sequence: 3, Table: "accounts", Created: 1, Updated: 2}, <-subscription.C)
+	assert.Equal(t, ingest.Event{ID: "12884901888-4-offers", Type: ingest.TableChanged, Sequence: 3, Table: "offers", Removed: 1}, <-subscription.C)
+	assert.Equal(t, ingest.Event{ID: "12884901888-6-ledger_ingested", Type: ingest.LedgerIngested, Sequence: 3}, <-subscription.C)
+	assert.Len(t, subscription.C, 0)
+}
+
+func TestRepublishLastLedgerIngested(t *testing.T) {
+	bus := ingest.NewBus()
+	subscription := bus.Subscribe(10)
+	defer subscription.Close()
+
+	s := &system{config: Config{EventBus: bus}}
+	// nothing was ingested yet
+	s.republishLastLedgerIngested(0)
+	assert.Len(t, subscription.C, 0)
+
+	s.republishLastLedgerIngested(3)
+	s.republishLastLedgerIngested(4)
+	s.publishLedgerIngested(4, io.StatsChangeProcessorResults{}, io.StatsLedgerTransactionProcessorResults{})
+
+	// the republished event has the ID of the event published before the restart
+	assert.Equal(t, ingest.Event{ID: "12884901888-6-ledger_ingested", Type: ingest.LedgerIngested, Sequence: 3}, <-subscription.C)
+	assert.Equal(t, ingest.Event{ID: "17179869184-6-ledger_ingested", Type: ingest.LedgerIngested, Sequence: 4}, <-subscription.C)
+	assert.Len(t, subscription.C, 0)
+}
@@ -0,0 +1,217 @@
+package expingest
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// ErrEntryNotChanged is returned by FindEntryChange when the ledger entry is
+// the same at both ends of the searched range.
+var ErrEntryNotChanged = errors.New("ledger entry did not change in the given range")
+
+// EntryChangeType describes how a ledger entry changed between checkpoints.
+type EntryChangeType string
+
+const (
+	// EntryCreated means the entry does not exist before the change.
+	EntryCreated EntryChangeType = "created"
+	// EntryUpdated means the entry exists before and after the change.
+	EntryUpdated EntryChangeType = "updated"
+	// EntryRemoved means the entry does not exist after the change.
+	EntryRemoved EntryChangeType = "removed"
+)
+
+// EntryChange is the result of FindEntryChange. The most recent change of the
+// entry happened in the (FromCheckpoint, ToCheckpoint] ledger range. When the
+// entry exists after the change its last_modified_ledger points to the exact
+// ledger.
+type EntryChange struct {
+	Type           EntryChangeType  `json:"type"`
+	FromCheckpoint uint32           `json:"from_checkpoint"`
+	ToCheckpoint   uint32           `json:"to_checkpoint"`
+	Before         *xdr.LedgerEntry `json:"-"`
+	After          *xdr.LedgerEntry `json:"-"`
+}
+
+// entryLookup returns the ledger entry at a given checkpoint or nil if the
+// entry does not exist at this checkpoint.
+type entryLookup func(checkpointLedger uint32) (*xdr.LedgerEntry, error)
+
+// FindEntryChange binary searches history archive checkpoints between
+// fromLedger and toLedger to find the checkpoint range in which the entry
+// identified by key was most recently created, updated or removed. It is
+// useful when history tables are not retained for the period in question.
+func FindEntryChange(
+	archive historyarchive.ArchiveInterface,
+	key xdr.LedgerKey,
+	fromLedger, toLedger uint32,
+) (EntryChange, error) {
+	return findEntryChange(
+		func(checkpointLedger uint32) (*xdr.LedgerEntry, error) {
+			return entryAtCheckpoint(archive, key, checkpointLedger)
+		},
+		fromLedger,
+		toLedger,
+	)
+}
+
+func findEntryChange(lookup entryLookup, fromLedger, toLedger uint32) (EntryChange, error) {
+	if !historyarchive.IsCheckpoint(fromLedger) || !historyarchive.IsCheckpoint(toLedger) {
+		return EntryChange{}, errors.New("from and to must be checkpoint ledgers")
+	}
+	if fromLedger >= toLedger {
+		return EntryChange{}, errors.New("from must be lower than to")
+	}
+
+	low, err := lookup(fromLedger)
+	if err != nil {
+		return EntryChange{}, errors.Wrapf(err, "Error getting entry at ledger %d", fromLedger)
+	}
+	high, err := lookup(toLedger)
+	if err != nil {
+		return EntryChange{}, errors.Wrapf(err, "Error getting entry at ledger %d", toLedger)
+	}
+
+	equal, err := entriesEqual(low, high)
+	if err != nil {
+		return EntryChange{}, err
+	}
+	if equal {
+		return EntryChange{}, ErrEntryNotChanged
+	}
+
+	// Invariant: entry at `from` differs from the entry at `toLedger` and the
+	// entry at `to` equals the entry at `toLedger`.
+	from, to := fromLedger, toLedger
+	for to-from > historyarchive.CheckpointFreq {
+		checkpoints := (to - from) / historyarchive.CheckpointFreq
+		mid := from + (checkpoints/2)*historyarchive.CheckpointFreq
+
+		var entry *xdr.LedgerEntry
+		entry, err = lookup(mid)
+		if err != nil {
+			return EntryChange{}, errors.Wrapf(err, "Error getting entry at ledger %d", mid)
+		}
+
+		equal, err = entriesEqual(entry, high)
+		if err != nil {
+			return EntryChange{}, err
+		}
+
+		if equal {
+			to = mid
+		} else {
+			from = mid
+			low = entry
+		}
+	}
+
+	change := EntryChange{
+		FromCheckpoint: from,
+		ToCheckpoint:   to,
+		Before:         low,
+		After:          high,
+	}
+	switch {
+	case low == nil:
+		change.Type = EntryCreated
+	case high == nil:
+		change.Type = EntryRemoved
+	default:
+		change.Type = EntryUpdated
+	}
+	return change, nil
+}
+
+func entriesEqual(a, b *xdr.LedgerEntry) (bool, error) {
+	if a == nil || b == nil {
+		return a == nil && b == nil, nil
+	}
+
+	aBytes, err := a.MarshalBinary()
+	if err != nil {
+		return false, errors.Wrap(err, "Error marshaling ledger entry")
+	}
+	bBytes, err := b.MarshalBinary()
+	if err != nil {
+		return false, errors.Wrap(err, "Error marshaling ledger entry")
+	}
+	return bytes.Equal(aBytes, bBytes), nil
+}
+
+// entryAtCheckpoint scans the buckets of a checkpoint from the newest to the
+// oldest and returns the first entry matching the key. Because newer buckets
+// shadow older ones the scan can stop at the first match.
+func entryAtCheckpoint(
+	archive historyarchive.ArchiveInterface,
+	key xdr.LedgerKey,
+	checkpointLedger uint32,
+) (*xdr.LedgerEntry, error) {
+	has, err := archive.GetCheckpointHAS(checkpointLedger)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get checkpoint HAS at ledger sequence %d", checkpointLedger)
+	}
+
+	for _, bucket := range has.CurrentBuckets {
+		for _, hashString := range []string{bucket.Curr, bucket.Snap} {
+			hash, decodeErr := historyarchive.DecodeHash(hashString)
+			if decodeErr != nil {
+				return nil, errors.Wrap(decodeErr, "Error decoding bucket hash")
+			}
+			if hash.IsZero() {
+				continue
+			}
+
+			found, entry, findErr := findInBucket(archive, hash, key)
+			if findErr != nil {
+				return nil, findErr
+			}
+			if found {
+				return entry, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func findInBucket(
+	archive historyarchive.ArchiveInterface,
+	hash historyarchive.Hash,
+	key xdr.LedgerKey,
+) (bool, *xdr.LedgerEntry, error) {
+	stream, err := archive.GetXdrStreamForHash(hash)
+	if err != nil {
+		return false, nil, errors.Wrapf(err, "cannot get xdr stream for hash '%s'", hash.String())
+	}
+	defer stream.Close()
+
+	for {
+		var entry xdr.BucketEntry
+		err = stream.ReadOne(&entry)
+		if err == io.EOF {
+			return false, nil, nil
+		}
+		if err != nil {
+			return false, nil, errors.Wrapf(err, "Error reading from bucket '%s'", hash.String())
+		}
+
+		switch entry.Type {
+		case xdr.BucketEntryTypeLiveentry, xdr.BucketEntryTypeInitentry:
+			liveEntry := entry.MustLiveEntry()
+			entryKey := liveEntry.LedgerKey()
+			if entryKey.Equals(key) {
+				return true, &liveEntry, nil
+			}
+		case xdr.BucketEntryTypeDeadentry:
+			deadKey := entry.MustDeadEntry()
+			if deadKey.Equals(key) {
+				return true, nil, nil
+			}
+		}
+	}
+}
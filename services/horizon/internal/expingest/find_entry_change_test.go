@@ -0,0 +1,94 @@
+package expingest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/xdr"
+)
+
+func accountEntryAt(lastModifiedLedger uint32, balance xdr.Int64) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{
+		LastModifiedLedgerSeq: xdr.Uint32(lastModifiedLedger),
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{
+				AccountId: issuer,
+				Balance:   balance,
+			},
+		},
+	}
+}
+
+// lookupWithChanges returns an entryLookup where the entry exists since
+// createdAt and is updated at updatedAt (when non-zero).
+func lookupWithChanges(createdAt, updatedAt uint32, calls *[]uint32) entryLookup {
+	return func(checkpointLedger uint32) (*xdr.LedgerEntry, error) {
+		*calls = append(*calls, checkpointLedger)
+		switch {
+		case checkpointLedger < createdAt:
+			return nil, nil
+		case updatedAt == 0 || checkpointLedger < updatedAt:
+			return accountEntryAt(createdAt, 100), nil
+		default:
+			return accountEntryAt(updatedAt, 200), nil
+		}
+	}
+}
+
+func TestFindEntryChangeCreated(t *testing.T) {
+	var calls []uint32
+	change, err := findEntryChange(lookupWithChanges(1000, 0, &calls), 63, 64*100-1)
+	assert.NoError(t, err)
+	assert.Equal(t, EntryCreated, change.Type)
+	assert.Equal(t, uint32(959), change.FromCheckpoint)
+	assert.Equal(t, uint32(1023), change.ToCheckpoint)
+	assert.Nil(t, change.Before)
+	assert.Equal(t, xdr.Uint32(1000), change.After.LastModifiedLedgerSeq)
+	// binary search should not check every checkpoint
+	assert.True(t, len(calls) < 12)
+}
+
+func TestFindEntryChangeUpdated(t *testing.T) {
+	var calls []uint32
+	change, err := findEntryChange(lookupWithChanges(10, 5000, &calls), 63, 64*100-1)
+	assert.NoError(t, err)
+	assert.Equal(t, EntryUpdated, change.Type)
+	assert.Equal(t, uint32(4991), change.FromCheckpoint)
+	assert.Equal(t, uint32(5055), change.ToCheckpoint)
+	assert.Equal(t, xdr.Int64(100), change.Before.Data.Account.Balance)
+	assert.Equal(t, xdr.Int64(200), change.After.Data.Account.Balance)
+}
+
+func TestFindEntryChangeRemoved(t *testing.T) {
+	lookup := func(checkpointLedger uint32) (*xdr.LedgerEntry, error) {
+		if checkpointLedger < 700 {
+			return accountEntryAt(10, 100), nil
+		}
+		return nil, nil
+	}
+
+	change, err := findEntryChange(lookup, 63, 1023)
+	assert.NoError(t, err)
+	assert.Equal(t, EntryRemoved, change.Type)
+	assert.Equal(t, uint32(639), change.FromCheckpoint)
+	assert.Equal(t, uint32(703), change.ToCheckpoint)
+	assert.Nil(t, change.After)
+}
+
+func TestFindEntryChangeNotChanged(t *testing.T) {
+	var calls []uint32
+	_, err := findEntryChange(lookupWithChanges(10, 0, &calls), 63, 1023)
+	assert.Equal(t, ErrEntryNotChanged, err)
+}
+
+func TestFindEntryChangeInvalidRange(t *testing.T) {
+	var calls []uint32
+	_, err := findEntryChange(lookupWithChanges(10, 0, &calls), 64, 1023)
+	assert.EqualError(t, err, "from and to must be checkpoint ledgers")
+
+	_, err = findEntryChange(lookupWithChanges(10, 0, &calls), 1023, 63)
+	assert.EqualError(t, err, "from must be lower than to")
+	assert.Empty(t, calls)
+}
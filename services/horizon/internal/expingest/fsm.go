@@ -7,6 +7,7 @@ import (
 	"github.com/stellar/go/exp/ingest/ledgerbackend"
 
 	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/toid"
 	"github.com/stellar/go/support/errors"
 	logpkg "github.com/stellar/go/support/log"
@@ -155,6 +156,28 @@ func (state startState) run(s *system) (transition, error) {
 		return start(), errors.Wrap(err, "Error getting last history ledger sequence")
 	}
 
+	if lastHistoryLedger != 0 {
+		reason, err := s.detectNetworkReset(lastHistoryLedger)
+		if err != nil {
+			return start(), err
+		}
+		if reason != "" {
+			if !s.config.AutoResetNetwork {
+				return stop(), errors.Errorf(
+					"network reset detected: %s, clear the database or enable automatic resets",
+					reason,
+				)
+			}
+			if err = s.resetNetwork(reason); err != nil {
+				return start(), err
+			}
+			if err = s.historyQ.Commit(); err != nil {
+				return start(), errors.Wrap(err, commitErrMsg)
+			}
+			return start(), nil
+		}
+	}
+
 	if ingestVersion != CurrentVersion || lastIngestedLedger == 0 {
 		// This block is either starting from empty state or ingestion
 		// version upgrade.
@@ -317,6 +340,10 @@ func (b buildState) run(s *system) (transition, error) {
 		return start(), errors.Wrap(err, "Error updating expingest version")
 	}
 
+	if err = s.historyQ.UpdateNetworkPassphrase(s.config.NetworkPassphrase); err != nil {
+		return start(), errors.Wrap(err, "Error updating network passphrase")
+	}
+
 	if err = s.completeIngestion(b.checkpointLedger); err != nil {
 		return start(), err
 	}
@@ -341,27 +368,99 @@ func (r resumeState) String() string {
 	return fmt.Sprintf("resume(latestSuccessfullyProcessedLedger=%d)", r.latestSuccessfullyProcessedLedger)
 }
 
+// errLedgerNotIngested ends the transaction of resumeState without
+// committing it when no ledger was ingested.
+var errLedgerNotIngested = errors.New("ledger not ingested")
+
+// resumedLedger is a ledger ingested by resumeState, reported once its
+// transaction is committed.
+type resumedLedger struct {
+	sequence               uint32
+	latestLedgerCore       uint32
+	startTime              time.Time
+	changeStats            io.StatsChangeProcessorResults
+	ledgerTransactionStats io.StatsLedgerTransactionProcessorResults
+	assetWatchEvents       []history.AssetWatchEvent
+}
+
 func (r resumeState) run(s *system) (transition, error) {
 	if r.latestSuccessfullyProcessedLedger == 0 {
 		return start(), errors.New("unexpected latestSuccessfullyProcessedLedger value")
 	}
 
-	if err := s.historyQ.Begin(); err != nil {
-		return retryResume(r),
-			errors.Wrap(err, "Error starting a transaction")
+	// the transaction is run again when it conflicts with a concurrent
+	// transaction, ex. the reaper deleting history
+	next := retryResume(r)
+	var ingested *resumedLedger
+	err := s.historyQ.RetryableTransaction(s.ctx, func() error {
+		var err error
+		next, ingested, err = r.ingestLedger(s)
+		if err == nil && ingested == nil {
+			return errLedgerNotIngested
+		}
+		return err
+	})
+	switch {
+	case err == errLedgerNotIngested:
+		return next, nil
+	case err != nil && ingested != nil:
+		// the ledger was processed but the transaction was not committed
+		return retryResume(r), err
+	case err != nil:
+		return next, err
 	}
-	defer s.historyQ.Rollback()
+
+	s.dataQualityViolations.commit()
+	ingestLedger := ingested.sequence
+	latestLedgerCore := ingested.latestLedgerCore
+
+	s.Metrics().LedgersIngestedCounter.Inc()
+	ledgersBehind := s.observeLedgersBehind(ingestLedger, latestLedgerCore)
+	s.observeLedgerIngestionLatency(ingestLedger)
+	s.notifyAssetWatches(ingested.assetWatchEvents)
+	s.publishLedgerIngested(ingestLedger, ingested.changeStats, ingested.ledgerTransactionStats)
+
+	if err = s.updateCursor(ingestLedger); err != nil {
+		// Don't return updateCursor error.
+		log.WithError(err).Warn("error updating stellar-core cursor")
+	}
+
+	duration := time.Since(ingested.startTime).Seconds()
+	s.Metrics().LedgerIngestionDuration.Observe(float64(duration))
+	log.
+		WithFields(ingested.changeStats.Map()).
+		WithFields(ingested.ledgerTransactionStats.Map()).
+		WithFields(logpkg.F{
+			"sequence":       ingestLedger,
+			"duration":       duration,
+			"state":          true,
+			"ledger":         true,
+			"commit":         true,
+			"ledgers_behind": ledgersBehind,
+		}).
+		Info("Processed ledger")
+
+	s.maybeVerifyState(ingestLedger)
+	s.maybeAuditState(ingestLedger)
+
+	return next, nil
+}
+
+// ingestLedger ingests the next ledger in the transaction of resumeState.
+// It returns a nil resumedLedger when no ledger was ingested.
+func (r resumeState) ingestLedger(s *system) (transition, *resumedLedger, error) {
+	s.dataQualityViolations.discard()
 
 	// This will get the value `FOR UPDATE`, blocking it for other nodes.
 	lastIngestedLedger, err := s.historyQ.GetLastLedgerExpIngest()
 	if err != nil {
-		return retryResume(r), errors.Wrap(err, getLastIngestedErrMsg)
+		return retryResume(r), nil, errors.Wrap(err, getLastIngestedErrMsg)
 	}
 
 	ingestLedger := r.latestSuccessfullyProcessedLedger + 1
 
 	if ingestLedger > lastIngestedLedger+1 {
-		return start(), errors.New("expected ingest ledger to be at most one greater " +
+		return start(), nil, errors.New("expected ingest ledger to be at most one greater " +
 			"than last ingested ledger in db")
 	} else if ingestLedger <= lastIngestedLedger {
 		log.WithField("ingestLedger", ingestLedger).
@@ -372,7 +471,7 @@ func (r resumeState) run(s *system) (transition, error) {
 
 	ingestVersion, err := s.historyQ.GetExpIngestVersion()
 	if err != nil {
-		return retryResume(r), errors.Wrap(err, getExpIngestVersionErrMsg)
+		return retryResume(r), nil, errors.Wrap(err, getExpIngestVersionErrMsg)
 	}
 
 	if ingestVersion != CurrentVersion {
@@ -380,12 +479,12 @@ func (r resumeState) run(s *system) (transition, error) {
 			"ingestVersion":  ingestVersion,
 			"currentVersion": CurrentVersion,
 		}).Info("ingestion version in db is not current, going back to start state")
-		return start(), nil
+		return start(), nil, nil
 	}
 
 	lastHistoryLedger, err := s.historyQ.GetLatestLedger()
 	if err != nil {
-		return retryResume(r), errors.Wrap(err, "could not get latest history ledger")
+		return retryResume(r), nil, errors.Wrap(err, "could not get latest history ledger")
 	}
 
 	if lastHistoryLedger != 0 && lastHistoryLedger != lastIngestedLedger {
@@ -396,18 +495,20 @@ func (r resumeState) run(s *system) (transition, error) {
 			"last history ledger does not match last ingested ledger, " +
 				"going back to start state",
 		)
-		return start(), nil
+		return start(), nil, nil
 	}
 
+	s.republishLastLedgerIngested(lastIngestedLedger)
+
 	lockReleased, err := s.maybePrepareRange(ingestLedger)
 	if lockReleased || err != nil {
-		return start(), err
+		return start(), nil, err
 	}
 
 	// Check if ledger is closed
 	latestLedgerCore, err := s.ledgerBackend.GetLatestLedgerSequence()
 	if err != nil {
-		return retryResume(r), errors.Wrap(err, "Error getting lastest ledger in stellar-core")
+		return retryResume(r), nil, errors.Wrap(err, "Error getting lastest ledger in stellar-core")
 	}
 
 	if latestLedgerCore < ingestLedger {
@@ -419,10 +520,11 @@ func (r resumeState) run(s *system) (transition, error) {
 		// Will fast-forward to the latest ledger in a buffer in case of captive core.
 		_, _, err = s.ledgerBackend.GetLedger(latestLedgerCore)
 		if err != nil {
-			return retryResume(r), errors.Wrap(err, "Error fast-forwarding to the latest ledger in stellar-core")
+			return retryResume(r), nil, errors.Wrap(err, "Error fast-forwarding to the latest ledger in stellar-core")
 		}
 
 		if latestLedgerCore == ingestLedger-1 {
+			s.observeLedgersBehind(latestLedgerCore, latestLedgerCore)
 			logger.Info("Waiting for ledger to be available in stellar-core")
 		} else {
 			logger.Info("Fast-forward to the latest ledger ingested in the cluster")
@@ -430,7 +532,7 @@ func (r resumeState) run(s *system) (transition, error) {
 
 		return retryResume(resumeState{
 			latestSuccessfullyProcessedLedger: latestLedgerCore,
-		}), nil
+		}), nil, nil
 	}
 
 	startTime := time.Now()
@@ -444,35 +546,29 @@ func (r resumeState) run(s *system) (transition, error) {
 
 	changeStats, ledgerTransactionStats, err := s.runner.RunAllProcessorsOnLedger(ingestLedger)
 	if err != nil {
-		return retryResume(r), errors.Wrap(err, "Error running processors on ledger")
+		return retryResume(r), nil, errors.Wrap(err, "Error running processors on ledger")
 	}
 
-	if err = s.completeIngestion(ingestLedger); err != nil {
-		return retryResume(r), err
+	var assetWatchEvents []history.AssetWatchEvent
+	if s.config.EnableAssetWatches {
+		assetWatchEvents, err = s.historyQ.EvaluateAssetWatches(ingestLedger)
+		if err != nil {
+			return retryResume(r), nil, errors.Wrap(err, "Error evaluating asset watches")
+		}
 	}
 
-	if err = s.updateCursor(ingestLedger); err != nil {
-		// Don't return updateCursor error.
-		log.WithError(err).Warn("error updating stellar-core cursor")
+	if err = s.historyQ.UpdateLastLedgerExpIngest(ingestLedger); err != nil {
+		return retryResume(r), nil, errors.Wrap(err, updateLastLedgerExpIngestErrMsg)
 	}
 
-	duration := time.Since(startTime).Seconds()
-	s.Metrics().LedgerIngestionDuration.Observe(float64(duration))
-	log.
-		WithFields(changeStats.Map()).
-		WithFields(ledgerTransactionStats.Map()).
-		WithFields(logpkg.F{
-			"sequence": ingestLedger,
-			"duration": duration,
-			"state":    true,
-			"ledger":   true,
-			"commit":   true,
-		}).
-		Info("Processed ledger")
-
-	s.maybeVerifyState(ingestLedger)
-
-	return resumeImmediately(ingestLedger), nil
+	return resumeImmediately(ingestLedger), &resumedLedger{
+		sequence:               ingestLedger,
+		latestLedgerCore:       latestLedgerCore,
+		startTime:              startTime,
+		changeStats:            changeStats,
+		ledgerTransactionStats: ledgerTransactionStats,
+		assetWatchEvents:       assetWatchEvents,
+	}, nil
 }
 
 type historyRangeState struct {
@@ -499,6 +595,7 @@ func (h historyRangeState) run(s *system) (transition, error) {
 		return start(), errors.Wrap(err, "Error starting a transaction")
 	}
 	defer s.historyQ.Rollback()
+	s.dataQualityViolations.discard()
 
 	// acquire distributed lock so no one else can perform ingestion operations.
 	if _, err := s.historyQ.GetLastLedgerExpIngest(); err != nil {
@@ -532,6 +629,7 @@ func (h historyRangeState) run(s *system) (transition, error) {
 	if err = s.historyQ.Commit(); err != nil {
 		return start(), errors.Wrap(err, commitErrMsg)
 	}
+	s.dataQualityViolations.commit()
 
 	return start(), nil
 }
@@ -582,6 +680,7 @@ func (h reingestHistoryRangeState) ingestRange(s *system, fromLedger, toLedger u
 	if s.historyQ.GetTx() == nil {
 		return errors.New("expected transaction to be present")
 	}
+	s.dataQualityViolations.discard()
 
 	// Clear history data before ingesting - used in `reingest range` command.
 	start, end, err := toid.LedgerRangeInclusive(
@@ -613,20 +712,91 @@ func (h reingestHistoryRangeState) run(s *system) (transition, error) {
 		return stop(), errors.Errorf("invalid range: [%d, %d]", h.fromLedger, h.toLedger)
 	}
 
+	progress := s.config.ReingestProgress
+	progress.rangeStarted(h.fromLedger, h.toLedger)
+	t, err := h.reingest(s, progress)
+	progress.rangeFinished(h.fromLedger, h.toLedger, err)
+
+	if err == nil && (s.config.AnalyzeAfterReingest || s.config.VacuumAfterReingest) {
+		h.analyzeHistoryTables(s)
+	}
+	return t, err
+}
+
+// analyzeHistoryTables refreshes statistics of history tables after a bulk
+// reingestion. Failures are only logged because the range itself has been
+// ingested successfully.
+func (h reingestHistoryRangeState) analyzeHistoryTables(s *system) {
+	startTime := time.Now()
+	vacuum := s.config.VacuumAfterReingest
+	if err := s.historyQ.AnalyzeHistoryTables(vacuum); err != nil {
+		log.WithError(err).Warn("Error analyzing history tables after reingestion")
+		return
+	}
+
 	log.WithFields(logpkg.F{
-		"from": h.fromLedger,
-		"to":   h.toLedger,
+		"from":     h.fromLedger,
+		"to":       h.toLedger,
+		"vacuum":   vacuum,
+		"duration": time.Since(startTime).Seconds(),
+	}).Info("Analyzed history tables")
+}
+
+func (h reingestHistoryRangeState) reingest(s *system, progress *ReingestProgress) (transition, error) {
+
+	// ledgers committed before the reingestion was interrupted
+	var committed ledgerRangeSet
+	job := h.job(s)
+	prepareFrom, prepareTo := h.fromLedger, h.toLedger
+	// ranges which are deleted and ingested
+	pending := []ledgerRange{{h.fromLedger, h.toLedger}}
+	if s.config.ResumeReingest && !h.force {
+		checkpoints, err := s.historyQ.GetReingestCheckpoints(job.from, job.to)
+		if err != nil {
+			return stop(), errors.Wrap(err, "Error getting reingest checkpoints")
+		}
+		for _, checkpoint := range checkpoints {
+			committed.add(ledgerRange{checkpoint.BatchFrom, checkpoint.LastLedger})
+		}
+
+		// ledger 1 is never ingested, see below
+		from := h.fromLedger
+		if from == 1 {
+			from = 2
+		}
+		missing := committed.missing(from, h.toLedger)
+		if len(missing) == 0 {
+			log.WithFields(logpkg.F{
+				"from": h.fromLedger,
+				"to":   h.toLedger,
+			}).Info("Range already reingested")
+			progress.ledgersCommitted(h.fromLedger, h.toLedger)
+			return stop(), h.completeJob(s, job)
+		}
+		prepareFrom, prepareTo = missing[0].from, missing[len(missing)-1].to
+		pending = missing
+	}
+
+	if s.config.IngestFromHistoryArchive {
+		if err := h.checkNotIngested(s, pending); err != nil {
+			return stop(), err
+		}
+	}
+
+	log.WithFields(logpkg.F{
+		"from": prepareFrom,
+		"to":   prepareTo,
 	}).Info("Preparing ledger backend to retrieve range")
 	startTime := time.Now()
 
-	err := s.ledgerBackend.PrepareRange(ledgerbackend.BoundedRange(h.fromLedger, h.toLedger))
+	err := s.ledgerBackend.PrepareRange(ledgerbackend.BoundedRange(prepareFrom, prepareTo))
 	if err != nil {
 		return stop(), errors.Wrap(err, "error preparing range")
 	}
 
 	log.WithFields(logpkg.F{
-		"from":     h.fromLedger,
-		"to":       h.toLedger,
+		"from":     prepareFrom,
+		"to":       prepareTo,
 		"duration": time.Since(startTime).Seconds(),
 	}).Info("Range ready")
 
@@ -638,23 +808,26 @@ func (h reingestHistoryRangeState) run(s *system) (transition, error) {
 	startTime = time.Now()
 
 	if h.force {
-		if err := s.historyQ.Begin(); err != nil {
-			return stop(), errors.Wrap(err, "Error starting a transaction")
-		}
-		defer s.historyQ.Rollback()
-
-		// acquire distributed lock so no one else can perform ingestion operations.
-		if _, err := s.historyQ.GetLastLedgerExpIngest(); err != nil {
-			return stop(), errors.Wrap(err, getLastIngestedErrMsg)
-		}
+		err := s.historyQ.RetryableTransaction(s.ctx, func() error {
+			// acquire distributed lock so no one else can perform ingestion operations.
+			if _, err := s.historyQ.GetLastLedgerExpIngest(); err != nil {
+				return errors.Wrap(err, getLastIngestedErrMsg)
+			}
 
-		if err := h.ingestRange(s, h.fromLedger, h.toLedger); err != nil {
+			if err := h.ingestRange(s, h.fromLedger, h.toLedger); err != nil {
+				return err
+			}
+			if !s.config.ResumeReingest {
+				return nil
+			}
+			// the checkpoints of an interrupted reingestion are obsolete
+			return h.completeJob(s, job)
+		})
+		if err != nil {
 			return stop(), err
 		}
-
-		if err := s.historyQ.Commit(); err != nil {
-			return stop(), errors.Wrap(err, commitErrMsg)
-		}
+		s.dataQualityViolations.commit()
+		progress.ledgersCommitted(h.fromLedger, h.toLedger)
 	} else {
 		lastIngestedLedger, err := s.historyQ.GetLastLedgerExpIngestNonBlocking()
 		if err != nil {
@@ -666,27 +839,44 @@ func (h reingestHistoryRangeState) run(s *system) (transition, error) {
 		}
 
 		for cur := h.fromLedger; cur <= h.toLedger; cur++ {
-			err := func(ledger uint32) error {
-				if err := s.historyQ.Begin(); err != nil {
-					return errors.Wrap(err, "Error starting a transaction")
+			if to, ok := committed.contiguousTo(cur); ok {
+				if to > h.toLedger {
+					to = h.toLedger
 				}
-				defer s.historyQ.Rollback()
+				progress.ledgersCommitted(cur, to)
+				cur = to
+				continue
+			}
 
-				// ingest each ledger in a separate transaction to prevent deadlocks
-				// when acquiring ShareLocks from multiple parallel reingest range processes
+			// ingest each ledger in a separate transaction to prevent deadlocks
+			// when acquiring ShareLocks from multiple parallel reingest range processes,
+			// transactions conflicting with another process are retried
+			ledger := cur
+			err := s.historyQ.RetryableTransaction(s.ctx, func() error {
 				if err := h.ingestRange(s, ledger, ledger); err != nil {
 					return err
 				}
-
-				if err := s.historyQ.Commit(); err != nil {
-					return errors.Wrap(err, commitErrMsg)
+				if !s.config.ResumeReingest {
+					return nil
 				}
-
-				return nil
-			}(cur)
+				// all the ledgers before ledger were committed now or
+				// before the interruption
+				return errors.Wrap(
+					s.historyQ.UpdateReingestCheckpoint(job.from, job.to, h.fromLedger, ledger),
+					"Error updating reingest checkpoint",
+				)
+			})
 			if err != nil {
 				return stop(), err
 			}
+			s.dataQualityViolations.commit()
+			progress.ledgersCommitted(ledger, ledger)
+		}
+
+		if s.config.ResumeReingest {
+			if err := h.completeJob(s, job); err != nil {
+				return stop(), err
+			}
 		}
 	}
 
@@ -699,6 +889,46 @@ func (h reingestHistoryRangeState) run(s *system) (transition, error) {
 	return stop(), nil
 }
 
+// checkNotIngested returns an error if one of the ranges contains ingested
+// ledgers. Ledgers read from the history archive have no transaction meta,
+// so reingesting them would replace their effects, trades and account
+// thresholds with nothing.
+func (h reingestHistoryRangeState) checkNotIngested(s *system, ranges []ledgerRange) error {
+	for _, r := range ranges {
+		count, err := s.historyQ.CountLedgersInRange(r.from, r.to)
+		if err != nil {
+			return errors.Wrap(err, "Error counting ingested ledgers")
+		}
+		if count > 0 {
+			return errors.Errorf(
+				"range [%d, %d] contains %d ingested ledgers, only missing ledgers can be ingested from the history archive",
+				r.from, r.to, count,
+			)
+		}
+	}
+	return nil
+}
+
+// job returns the range of the reingestion job the range belongs to.
+func (h reingestHistoryRangeState) job(s *system) ledgerRange {
+	if s.config.reingestJob != nil {
+		return *s.config.reingestJob
+	}
+	return ledgerRange{h.fromLedger, h.toLedger}
+}
+
+// completeJob removes the checkpoints of the job once the range is
+// reingested, unless the range is a part of a parallel job.
+func (h reingestHistoryRangeState) completeJob(s *system, job ledgerRange) error {
+	if s.config.reingestJob != nil {
+		return nil
+	}
+	return errors.Wrap(
+		s.historyQ.DeleteReingestCheckpoints(job.from, job.to),
+		"Error removing reingest checkpoints",
+	)
+}
+
 type waitForCheckpointState struct{}
 
 func (waitForCheckpointState) String() string {
@@ -786,6 +1016,7 @@ func (v verifyRangeState) run(s *system) (transition, error) {
 			err = errors.Wrap(err, "Error starting a transaction")
 			return stop(), err
 		}
+		s.dataQualityViolations.discard()
 
 		var changeStats io.StatsChangeProcessorResults
 		var ledgerTransactionStats io.StatsLedgerTransactionProcessorResults
@@ -897,6 +1128,7 @@ func (s *system) completeIngestion(ledger uint32) error {
 	if err := s.historyQ.Commit(); err != nil {
 		return errors.Wrap(err, commitErrMsg)
 	}
+	s.dataQualityViolations.commit()
 
 	return nil
 }
@@ -0,0 +1,277 @@
+package expingest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	logpkg "github.com/stellar/go/support/log"
+)
+
+// Ledger gap statuses reported by GapBackfiller.
+const (
+	LedgerGapPending     = "pending"
+	LedgerGapBackfilling = "backfilling"
+	LedgerGapFilled      = "filled"
+	LedgerGapFailed      = "failed"
+	// LedgerGapClaimed gaps are backfilled by another instance.
+	LedgerGapClaimed = "claimed"
+)
+
+const (
+	// ledgerGapClaimRefreshInterval is the interval at which the claim of
+	// the gap being backfilled is refreshed.
+	ledgerGapClaimRefreshInterval = time.Minute
+	// ledgerGapClaimStaleAfter is the duration after which the claim of a
+	// gap which wasn't refreshed is taken over by another instance.
+	ledgerGapClaimStaleAfter = 5 * ledgerGapClaimRefreshInterval
+)
+
+// LedgerGapStatus is the backfill status of a ledger gap.
+type LedgerGapStatus struct {
+	history.LedgerGap
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// GapBackfillStatus is the admin representation of the gaps detected by the
+// last scan of a GapBackfiller. Progress is the progress of the gap being
+// backfilled, if any.
+type GapBackfillStatus struct {
+	LastScan *time.Time                `json:"last_scan"`
+	Gaps     []LedgerGapStatus         `json:"gaps"`
+	Progress *ReingestProgressSnapshot `json:"progress,omitempty"`
+}
+
+type ledgerGapsQ interface {
+	GetLedgerGaps() ([]history.LedgerGap, error)
+	ClaimLedgerGap(gaps []history.LedgerGap, staleAfter time.Duration) (history.LedgerGap, bool, error)
+	RefreshLedgerGapClaim(gap history.LedgerGap) error
+	ReleaseLedgerGapClaim(gap history.LedgerGap) error
+}
+
+// GapBackfiller detects the gaps in the `history_ledgers` table and
+// reingests them. Instances sharing the database claim the gaps they
+// backfill so each gap is backfilled by one instance. It is safe for
+// concurrent use.
+type GapBackfiller struct {
+	config        Config
+	historyQ      ledgerGapsQ
+	systemFactory func(Config) (System, error)
+
+	mutex    sync.Mutex
+	lastScan time.Time
+	gaps     []LedgerGapStatus
+	progress *ReingestProgress
+}
+
+// NewGapBackfiller returns a GapBackfiller reingesting the gaps with
+// systems created from config, as done by `horizon db reingest range`. The
+// ledgers are read from the remote captive core or, when it's not
+// configured, from the stellar-core database: a local captive core would be
+// a second stellar-core process running next to the one of live ingestion.
+func NewGapBackfiller(config Config) *GapBackfiller {
+	config.StellarCoreBinaryPath = ""
+	historyQ := &history.Q{config.HistorySession.Clone()}
+	return newGapBackfiller(config, historyQ, NewSystem)
+}
+
+func newGapBackfiller(
+	config Config,
+	historyQ ledgerGapsQ,
+	systemFactory func(Config) (System, error),
+) *GapBackfiller {
+	config.ResumeReingest = true
+	return &GapBackfiller{
+		config:        config,
+		historyQ:      historyQ,
+		systemFactory: systemFactory,
+	}
+}
+
+// Run scans the ingested history for gaps and reingests them, one at a
+// time. Each gap is claimed before it's backfilled, the gaps claimed by
+// other instances are skipped. A failed gap doesn't prevent the following
+// ones from being backfilled, it's retried on the next run. Gaps are
+// reingested ledger by ledger so the ingestion of the latest ledgers is not
+// blocked.
+func (b *GapBackfiller) Run(ctx context.Context) error {
+	gaps, err := b.historyQ.GetLedgerGaps()
+	if err != nil {
+		return errors.Wrap(err, "could not detect ledger gaps")
+	}
+
+	b.mutex.Lock()
+	b.lastScan = time.Now()
+	b.gaps = make([]LedgerGapStatus, len(gaps))
+	for i, gap := range gaps {
+		b.gaps[i] = LedgerGapStatus{LedgerGap: gap, Status: LedgerGapPending}
+	}
+	b.mutex.Unlock()
+
+	if len(gaps) > 0 {
+		log.WithField("gaps", len(gaps)).Warn("Ledger gaps detected")
+	}
+
+	var firstErr error
+	pending := gaps
+	for len(pending) > 0 {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		gap, ok, err := b.historyQ.ClaimLedgerGap(pending, ledgerGapClaimStaleAfter)
+		if err != nil {
+			return errors.Wrap(err, "could not claim ledger gap")
+		}
+		if !ok {
+			// the remaining gaps are backfilled by other instances
+			for _, gap := range pending {
+				b.setGapStatus(indexOfGap(gaps, gap), LedgerGapClaimed, nil, nil)
+			}
+			break
+		}
+		pending = withoutGap(pending, gap)
+
+		err = b.backfillClaimed(ctx, indexOfGap(gaps, gap), gap)
+		logger := log.WithFields(logpkg.F{
+			"from": gap.StartSequence,
+			"to":   gap.EndSequence,
+		})
+		if err != nil {
+			logger.WithError(err).Error("Error backfilling ledger gap")
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "could not backfill ledgers [%d, %d]", gap.StartSequence, gap.EndSequence)
+			}
+			continue
+		}
+		logger.Info("Backfilled ledger gap")
+	}
+	return firstErr
+}
+
+func indexOfGap(gaps []history.LedgerGap, gap history.LedgerGap) int {
+	for i := range gaps {
+		if gaps[i] == gap {
+			return i
+		}
+	}
+	return -1
+}
+
+func withoutGap(gaps []history.LedgerGap, gap history.LedgerGap) []history.LedgerGap {
+	result := make([]history.LedgerGap, 0, len(gaps))
+	for _, other := range gaps {
+		if other != gap {
+			result = append(result, other)
+		}
+	}
+	return result
+}
+
+// backfillClaimed backfills the claimed gap, refreshing its claim until
+// it's done, and releases it.
+func (b *GapBackfiller) backfillClaimed(ctx context.Context, i int, gap history.LedgerGap) error {
+	done := make(chan struct{})
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		ticker := time.NewTicker(ledgerGapClaimRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := b.historyQ.RefreshLedgerGapClaim(gap); err != nil {
+					log.WithError(err).Warn("Error refreshing ledger gap claim")
+				}
+			}
+		}
+	}()
+
+	err := b.backfill(ctx, i, gap)
+	close(done)
+	<-refreshed
+
+	if releaseErr := b.historyQ.ReleaseLedgerGapClaim(gap); releaseErr != nil {
+		log.WithError(releaseErr).Warn("Error releasing ledger gap claim")
+	}
+	return err
+}
+
+func (b *GapBackfiller) backfill(ctx context.Context, i int, gap history.LedgerGap) error {
+	config := b.config
+	config.ReingestProgress = NewReingestProgress(gap.StartSequence, gap.EndSequence, 1)
+	config.CatchupPause.Begin()
+	defer config.CatchupPause.End()
+	b.setGapStatus(i, LedgerGapBackfilling, nil, config.ReingestProgress)
+
+	system, err := b.systemFactory(config)
+	if err == nil {
+		// the system is shut down when the run is cancelled
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				system.Shutdown()
+			case <-done:
+			}
+		}()
+		err = system.ReingestRange(gap.StartSequence, gap.EndSequence, false)
+		close(done)
+		system.Shutdown()
+	}
+
+	if err != nil {
+		b.setGapStatus(i, LedgerGapFailed, err, nil)
+		return err
+	}
+	b.setGapStatus(i, LedgerGapFilled, nil, nil)
+	return nil
+}
+
+func (b *GapBackfiller) setGapStatus(i int, status string, err error, progress *ReingestProgress) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.gaps[i].Status = status
+	b.gaps[i].Error = ""
+	if err != nil {
+		b.gaps[i].Error = err.Error()
+	}
+	b.progress = progress
+}
+
+// Status returns the gaps detected by the last run and their backfill
+// status.
+func (b *GapBackfiller) Status() GapBackfillStatus {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	status := GapBackfillStatus{Gaps: make([]LedgerGapStatus, len(b.gaps))}
+	copy(status.Gaps, b.gaps)
+	if !b.lastScan.IsZero() {
+		lastScan := b.lastScan
+		status.LastScan = &lastScan
+	}
+	if b.progress != nil {
+		snapshot := b.progress.Snapshot()
+		status.Progress = &snapshot
+	}
+	return status
+}
+
+// ServeHTTP renders the status as JSON.
+func (b *GapBackfiller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b.Status()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,183 @@
+package expingest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockLedgerGapsQ struct {
+	mock.Mock
+}
+
+func (m *mockLedgerGapsQ) GetLedgerGaps() ([]history.LedgerGap, error) {
+	args := m.Called()
+	return args.Get(0).([]history.LedgerGap), args.Error(1)
+}
+
+func (m *mockLedgerGapsQ) ClaimLedgerGap(gaps []history.LedgerGap, staleAfter time.Duration) (history.LedgerGap, bool, error) {
+	args := m.Called(gaps, staleAfter)
+	return args.Get(0).(history.LedgerGap), args.Bool(1), args.Error(2)
+}
+
+func (m *mockLedgerGapsQ) RefreshLedgerGapClaim(gap history.LedgerGap) error {
+	args := m.Called(gap)
+	return args.Error(0)
+}
+
+func (m *mockLedgerGapsQ) ReleaseLedgerGapClaim(gap history.LedgerGap) error {
+	args := m.Called(gap)
+	return args.Error(0)
+}
+
+// expectClaims expects the gaps to be claimed, in order, and released.
+func (m *mockLedgerGapsQ) expectClaims(gaps ...history.LedgerGap) {
+	for i, gap := range gaps {
+		m.On("ClaimLedgerGap", gaps[i:], ledgerGapClaimStaleAfter).Return(gap, true, nil).Once()
+		m.On("ReleaseLedgerGapClaim", gap).Return(nil).Once()
+	}
+}
+
+func TestGapBackfillerRun(t *testing.T) {
+	q := &mockLedgerGapsQ{}
+	gaps := []history.LedgerGap{
+		{StartSequence: 5, EndSequence: 6},
+		{StartSequence: 10, EndSequence: 20},
+		{StartSequence: 30, EndSequence: 30},
+	}
+	q.On("GetLedgerGaps").Return(gaps, nil).Once()
+	q.expectClaims(gaps...)
+
+	system := &mockSystem{}
+	system.On("ReingestRange", uint32(5), uint32(6), false).Return(nil).Once()
+	system.On("ReingestRange", uint32(10), uint32(20), false).Return(errors.New("core is down")).Once()
+	system.On("ReingestRange", uint32(30), uint32(30), false).Return(nil).Once()
+	system.On("Shutdown").Return()
+	defer mock.AssertExpectationsForObjects(t, q, system)
+
+	var configs []Config
+	backfiller := newGapBackfiller(Config{}, q, func(config Config) (System, error) {
+		configs = append(configs, config)
+		return system, nil
+	})
+
+	err := backfiller.Run(context.Background())
+	assert.EqualError(t, err, "could not backfill ledgers [10, 20]: core is down")
+
+	// the ledgers committed before a failure are skipped by the next run
+	assert.Len(t, configs, 3)
+	assert.True(t, configs[0].ResumeReingest)
+	assert.Equal(t, uint32(10), configs[1].ReingestProgress.Snapshot().FromLedger)
+
+	status := backfiller.Status()
+	assert.NotNil(t, status.LastScan)
+	assert.Nil(t, status.Progress)
+	assert.Equal(t, []LedgerGapStatus{
+		{LedgerGap: history.LedgerGap{StartSequence: 5, EndSequence: 6}, Status: LedgerGapFilled},
+		{LedgerGap: history.LedgerGap{StartSequence: 10, EndSequence: 20}, Status: LedgerGapFailed, Error: "core is down"},
+		{LedgerGap: history.LedgerGap{StartSequence: 30, EndSequence: 30}, Status: LedgerGapFilled},
+	}, status.Gaps)
+
+	w := httptest.NewRecorder()
+	backfiller.ServeHTTP(w, httptest.NewRequest("GET", "/ledger_gaps", nil))
+	var rendered map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rendered))
+	renderedGaps := rendered["gaps"].([]interface{})
+	assert.Len(t, renderedGaps, 3)
+	assert.Equal(t, map[string]interface{}{
+		"from_ledger": 10.0,
+		"to_ledger":   20.0,
+		"status":      "failed",
+		"error":       "core is down",
+	}, renderedGaps[1])
+}
+
+func TestGapBackfillerRunClaimedGaps(t *testing.T) {
+	q := &mockLedgerGapsQ{}
+	gaps := []history.LedgerGap{
+		{StartSequence: 5, EndSequence: 6},
+		{StartSequence: 10, EndSequence: 20},
+		{StartSequence: 30, EndSequence: 30},
+	}
+	q.On("GetLedgerGaps").Return(gaps, nil).Once()
+	// the first gap is backfilled by another instance
+	q.On("ClaimLedgerGap", gaps, ledgerGapClaimStaleAfter).Return(gaps[1], true, nil).Once()
+	q.On("ReleaseLedgerGapClaim", gaps[1]).Return(nil).Once()
+	q.On("ClaimLedgerGap", []history.LedgerGap{gaps[0], gaps[2]}, ledgerGapClaimStaleAfter).
+		Return(gaps[2], true, nil).Once()
+	q.On("ReleaseLedgerGapClaim", gaps[2]).Return(nil).Once()
+	q.On("ClaimLedgerGap", []history.LedgerGap{gaps[0]}, ledgerGapClaimStaleAfter).
+		Return(history.LedgerGap{}, false, nil).Once()
+
+	system := &mockSystem{}
+	system.On("ReingestRange", uint32(10), uint32(20), false).Return(nil).Once()
+	system.On("ReingestRange", uint32(30), uint32(30), false).Return(nil).Once()
+	system.On("Shutdown").Return()
+	defer mock.AssertExpectationsForObjects(t, q, system)
+
+	backfiller := newGapBackfiller(Config{}, q, func(config Config) (System, error) {
+		return system, nil
+	})
+	assert.NoError(t, backfiller.Run(context.Background()))
+	assert.Equal(t, []LedgerGapStatus{
+		{LedgerGap: gaps[0], Status: LedgerGapClaimed},
+		{LedgerGap: gaps[1], Status: LedgerGapFilled},
+		{LedgerGap: gaps[2], Status: LedgerGapFilled},
+	}, backfiller.Status().Gaps)
+}
+
+func TestGapBackfillerClaimError(t *testing.T) {
+	q := &mockLedgerGapsQ{}
+	gaps := []history.LedgerGap{{StartSequence: 5, EndSequence: 6}}
+	q.On("GetLedgerGaps").Return(gaps, nil).Once()
+	q.On("ClaimLedgerGap", gaps, ledgerGapClaimStaleAfter).
+		Return(history.LedgerGap{}, false, errors.New("db error")).Once()
+	defer mock.AssertExpectationsForObjects(t, q)
+
+	backfiller := newGapBackfiller(Config{}, q, nil)
+	assert.EqualError(t, backfiller.Run(context.Background()), "could not claim ledger gap: db error")
+	assert.Equal(t, LedgerGapPending, backfiller.Status().Gaps[0].Status)
+}
+
+func TestNewGapBackfillerDoesNotStartCaptiveCore(t *testing.T) {
+	backfiller := NewGapBackfiller(Config{
+		StellarCoreBinaryPath: "/usr/bin/stellar-core",
+		RemoteCaptiveCoreURL:  "http://localhost:8000",
+		HistorySession:        &db.Session{},
+	})
+	assert.Empty(t, backfiller.config.StellarCoreBinaryPath)
+	assert.Equal(t, "http://localhost:8000", backfiller.config.RemoteCaptiveCoreURL)
+}
+
+func TestGapBackfillerRunCancelled(t *testing.T) {
+	q := &mockLedgerGapsQ{}
+	q.On("GetLedgerGaps").Return([]history.LedgerGap{{StartSequence: 5, EndSequence: 6}}, nil).Once()
+	defer mock.AssertExpectationsForObjects(t, q)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	backfiller := newGapBackfiller(Config{}, q, func(config Config) (System, error) {
+		t.Fatal("unexpected system")
+		return nil, nil
+	})
+	assert.Equal(t, context.Canceled, backfiller.Run(ctx))
+	assert.Equal(t, LedgerGapPending, backfiller.Status().Gaps[0].Status)
+}
+
+func TestGapBackfillerGetLedgerGapsError(t *testing.T) {
+	q := &mockLedgerGapsQ{}
+	q.On("GetLedgerGaps").Return([]history.LedgerGap(nil), errors.New("db error")).Once()
+	defer mock.AssertExpectationsForObjects(t, q)
+
+	backfiller := newGapBackfiller(Config{}, q, nil)
+	assert.EqualError(t, backfiller.Run(context.Background()), "could not detect ledger gaps: db error")
+	assert.Nil(t, backfiller.Status().LastScan)
+}
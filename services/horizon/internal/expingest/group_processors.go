@@ -1,7 +1,13 @@
 package expingest
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/expingest/processors"
 	"github.com/stellar/go/support/errors"
 )
 
@@ -16,7 +22,7 @@ type groupChangeProcessors []horizonChangeProcessor
 func (g groupChangeProcessors) ProcessChange(change io.Change) error {
 	for _, p := range g {
 		if err := p.ProcessChange(change); err != nil {
-			return errors.Wrapf(err, "error in %T.ProcessChange", p)
+			return errors.Wrapf(err, "error in %s.ProcessChange", processorType(p))
 		}
 	}
 	return nil
@@ -25,7 +31,7 @@ func (g groupChangeProcessors) ProcessChange(change io.Change) error {
 func (g groupChangeProcessors) Commit() error {
 	for _, p := range g {
 		if err := p.Commit(); err != nil {
-			return errors.Wrapf(err, "error in %T.Commit", p)
+			return errors.Wrapf(err, "error in %s.Commit", processorType(p))
 		}
 	}
 	return nil
@@ -36,7 +42,7 @@ type groupTransactionProcessors []horizonTransactionProcessor
 func (g groupTransactionProcessors) ProcessTransaction(tx io.LedgerTransaction) error {
 	for _, p := range g {
 		if err := p.ProcessTransaction(tx); err != nil {
-			return errors.Wrapf(err, "error in %T.ProcessTransaction", p)
+			return errors.Wrapf(err, "error in %s.ProcessTransaction", processorType(p))
 		}
 	}
 	return nil
@@ -45,8 +51,112 @@ func (g groupTransactionProcessors) ProcessTransaction(tx io.LedgerTransaction)
 func (g groupTransactionProcessors) Commit() error {
 	for _, p := range g {
 		if err := p.Commit(); err != nil {
-			return errors.Wrapf(err, "error in %T.Commit", p)
+			return errors.Wrapf(err, "error in %s.Commit", processorType(p))
 		}
 	}
 	return nil
 }
+
+// filteredTransactionProcessor passes the transactions kept by filter to
+// processor and skips the others.
+type filteredTransactionProcessor struct {
+	filter    processors.TransactionFilter
+	sequence  uint32
+	processor horizonTransactionProcessor
+}
+
+func (p filteredTransactionProcessor) ProcessTransaction(tx io.LedgerTransaction) error {
+	keep, err := p.filter.Keep(p.sequence, tx)
+	if err != nil {
+		return errors.Wrap(err, "error filtering transaction")
+	}
+	if !keep {
+		return nil
+	}
+	return p.processor.ProcessTransaction(tx)
+}
+
+func (p filteredTransactionProcessor) Commit() error {
+	return p.processor.Commit()
+}
+
+// processorType returns the type of processor, or of the processor it
+// times, for error messages. Plugin processors are named after their plugin.
+func processorType(processor interface{}) string {
+	switch p := processor.(type) {
+	case *timedChangeProcessor:
+		processor = p.processor
+	case *timedTransactionProcessor:
+		processor = p.processor
+	}
+	switch p := processor.(type) {
+	case *pluginChangeProcessor:
+		return p.name
+	case *pluginTransactionProcessor:
+		return p.name
+	}
+	return fmt.Sprintf("%T", processor)
+}
+
+// processorName returns the name of the type of processor, without the
+// package, used as the label of the processor duration metric.
+func processorName(processor interface{}) string {
+	name := processorType(processor)
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// timedChangeProcessor measures the time spent in processor and observes
+// the total when the changes of the ledger are committed.
+type timedChangeProcessor struct {
+	processor horizonChangeProcessor
+	observer  prometheus.Observer
+	duration  time.Duration
+}
+
+func newTimedChangeProcessor(processor horizonChangeProcessor, summary *prometheus.SummaryVec) *timedChangeProcessor {
+	return &timedChangeProcessor{
+		processor: processor,
+		observer:  summary.WithLabelValues(processorName(processor)),
+	}
+}
+
+func (p *timedChangeProcessor) ProcessChange(change io.Change) error {
+	startTime := time.Now()
+	defer func() { p.duration += time.Since(startTime) }()
+	return p.processor.ProcessChange(change)
+}
+
+func (p *timedChangeProcessor) Commit() error {
+	startTime := time.Now()
+	err := p.processor.Commit()
+	p.observer.Observe((p.duration + time.Since(startTime)).Seconds())
+	return err
+}
+
+// timedTransactionProcessor measures the time spent in processor and
+// observes the total when the transactions of the ledger are committed.
+type timedTransactionProcessor struct {
+	processor horizonTransactionProcessor
+	observer  prometheus.Observer
+	duration  time.Duration
+}
+
+func newTimedTransactionProcessor(processor horizonTransactionProcessor, summary *prometheus.SummaryVec) *timedTransactionProcessor {
+	return &timedTransactionProcessor{
+		processor: processor,
+		observer:  summary.WithLabelValues(processorName(processor)),
+	}
+}
+
+func (p *timedTransactionProcessor) ProcessTransaction(tx io.LedgerTransaction) error {
+	startTime := time.Now()
+	defer func() { p.duration += time.Since(startTime) }()
+	return p.processor.ProcessTransaction(tx)
+}
+
+func (p *timedTransactionProcessor) Commit() error {
+	startTime := time.Now()
+	err := p.processor.Commit()
+	p.observer.Observe((p.duration + time.Since(startTime)).Seconds())
+	return err
+}
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -183,3 +186,49 @@ func (s *GroupTransactionProcessorsTestSuiteLedger) TestCommitSucceeds() {
 	err := s.processors.Commit()
 	s.Assert().NoError(err)
 }
+
+func observedDuration(t *testing.T, summary *prometheus.SummaryVec, processor string) *dto.Summary {
+	metric := &dto.Metric{}
+	observer := summary.WithLabelValues(processor).(prometheus.Metric)
+	if err := observer.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetSummary()
+}
+
+func TestTimedChangeProcessor(t *testing.T) {
+	summary := prometheus.NewSummaryVec(prometheus.SummaryOpts{Name: "test"}, []string{"processor"})
+	processor := &mockHorizonChangeProcessor{}
+	change := io.Change{}
+	processor.On("ProcessChange", change).Return(nil).Twice()
+	processor.On("Commit").Return(errors.New("transient error")).Once()
+
+	group := groupChangeProcessors{newTimedChangeProcessor(processor, summary)}
+	assert.NoError(t, group.ProcessChange(change))
+	assert.NoError(t, group.ProcessChange(change))
+	// errors name the timed processor
+	assert.EqualError(t, group.Commit(), "error in *expingest.mockHorizonChangeProcessor.Commit: transient error")
+	processor.AssertExpectations(t)
+
+	// the time spent in the processor is observed once per ledger
+	assert.Equal(t, uint64(1), observedDuration(t, summary, "mockHorizonChangeProcessor").GetSampleCount())
+}
+
+func TestTimedTransactionProcessor(t *testing.T) {
+	summary := prometheus.NewSummaryVec(prometheus.SummaryOpts{Name: "test"}, []string{"processor"})
+	processor := &mockHorizonTransactionProcessor{}
+	transaction := io.LedgerTransaction{}
+	processor.On("ProcessTransaction", transaction).Return(errors.New("transient error")).Once()
+	processor.On("Commit").Return(nil).Once()
+
+	group := groupTransactionProcessors{newTimedTransactionProcessor(processor, summary)}
+	assert.EqualError(
+		t,
+		group.ProcessTransaction(transaction),
+		"error in *expingest.mockHorizonTransactionProcessor.ProcessTransaction: transient error",
+	)
+	assert.NoError(t, group.Commit())
+	processor.AssertExpectations(t)
+
+	assert.Equal(t, uint64(1), observedDuration(t, summary, "mockHorizonTransactionProcessor").GetSampleCount())
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/stellar/go/exp/ingest/adapters"
 	"github.com/stellar/go/exp/ingest/io"
 	"github.com/stellar/go/exp/ingest/ledgerbackend"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/toid"
 	"github.com/stellar/go/support/errors"
 	"github.com/stretchr/testify/suite"
@@ -408,6 +409,29 @@ func (s *ReingestHistoryRangeStateTestSuite) TestSuccessOneLedger() {
 	s.Assert().NoError(err)
 }
 
+func (s *ReingestHistoryRangeStateTestSuite) TestSuccessAnalyzeTables() {
+	s.historyQ.On("GetLastLedgerExpIngestNonBlocking").Return(uint32(0), nil).Once()
+	s.historyQ.On("GetTx").Return(&sqlx.Tx{}).Once()
+
+	toidFrom := toid.New(100, 0, 0)
+	toidTo := toid.New(101, 0, 0)
+	s.historyQ.On(
+		"DeleteRangeAll", toidFrom.ToInt64(), toidTo.ToInt64(),
+	).Return(nil).Once()
+
+	s.runner.On("RunTransactionProcessorsOnLedger", uint32(100)).Return(io.StatsLedgerTransactionProcessorResults{}, nil).Once()
+	s.historyQ.On("Commit").Return(nil).Once()
+	// errors are only logged
+	s.historyQ.On("AnalyzeHistoryTables", true).Return(errors.New("my error")).Once()
+
+	*s.ledgerBackend = mockLedgerBackend{}
+	s.ledgerBackend.On("PrepareRange", ledgerbackend.BoundedRange(100, 100)).Return(nil).Once()
+
+	s.system.config.VacuumAfterReingest = true
+	err := s.system.ReingestRange(100, 100, false)
+	s.Assert().NoError(err)
+}
+
 func (s *ReingestHistoryRangeStateTestSuite) TestGetLastLedgerExpIngestError() {
 	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(0), errors.New("my error")).Once()
 
@@ -435,3 +459,132 @@ func (s *ReingestHistoryRangeStateTestSuite) TestReingestRangeForce() {
 	err := s.system.ReingestRange(100, 200, true)
 	s.Assert().NoError(err)
 }
+
+func (s *ReingestHistoryRangeStateTestSuite) TestResumeSkipsCommittedLedgers() {
+	*s.historyQ = mockDBQ{}
+	s.historyQ.On("GetTx").Return(nil).Once()
+	s.historyQ.On("GetReingestCheckpoints", uint32(100), uint32(200)).
+		Return([]history.ReingestCheckpoint{
+			{RangeFrom: 100, RangeTo: 200, BatchFrom: 100, LastLedger: 197},
+		}, nil).Once()
+	s.historyQ.On("GetLastLedgerExpIngestNonBlocking").Return(uint32(0), nil).Once()
+
+	for i := uint32(198); i <= uint32(200); i++ {
+		s.historyQ.On("Begin").Return(nil).Once()
+		s.historyQ.On("GetTx").Return(&sqlx.Tx{}).Once()
+
+		toidFrom := toid.New(int32(i), 0, 0)
+		toidTo := toid.New(int32(i+1), 0, 0)
+		s.historyQ.On(
+			"DeleteRangeAll", toidFrom.ToInt64(), toidTo.ToInt64(),
+		).Return(nil).Once()
+
+		s.runner.On("RunTransactionProcessorsOnLedger", i).Return(io.StatsLedgerTransactionProcessorResults{}, nil).Once()
+		s.historyQ.On("UpdateReingestCheckpoint", uint32(100), uint32(200), uint32(100), i).Return(nil).Once()
+
+		s.historyQ.On("Commit").Return(nil).Once()
+		s.historyQ.On("Rollback").Return(nil).Once()
+	}
+	s.historyQ.On("DeleteReingestCheckpoints", uint32(100), uint32(200)).Return(nil).Once()
+
+	// only the missing ledgers are prepared
+	*s.ledgerBackend = mockLedgerBackend{}
+	s.ledgerBackend.On("PrepareRange", ledgerbackend.BoundedRange(198, 200)).Return(nil).Once()
+
+	s.system.config.ResumeReingest = true
+	err := s.system.ReingestRange(100, 200, false)
+	s.Assert().NoError(err)
+}
+
+func (s *ReingestHistoryRangeStateTestSuite) TestResumeRangeAlreadyReingested() {
+	*s.historyQ = mockDBQ{}
+	s.historyQ.On("GetTx").Return(nil).Once()
+	s.historyQ.On("GetReingestCheckpoints", uint32(100), uint32(200)).
+		Return([]history.ReingestCheckpoint{
+			{RangeFrom: 100, RangeTo: 200, BatchFrom: 100, LastLedger: 150},
+			{RangeFrom: 100, RangeTo: 200, BatchFrom: 151, LastLedger: 200},
+		}, nil).Once()
+	s.historyQ.On("DeleteReingestCheckpoints", uint32(100), uint32(200)).Return(nil).Once()
+
+	*s.ledgerBackend = mockLedgerBackend{}
+
+	s.system.config.ResumeReingest = true
+	err := s.system.ReingestRange(100, 200, false)
+	s.Assert().NoError(err)
+	s.ledgerBackend.AssertExpectations(s.T())
+}
+
+func (s *ReingestHistoryRangeStateTestSuite) TestResumeGetCheckpointsError() {
+	*s.historyQ = mockDBQ{}
+	s.historyQ.On("GetTx").Return(nil).Once()
+	s.historyQ.On("GetReingestCheckpoints", uint32(100), uint32(200)).
+		Return([]history.ReingestCheckpoint(nil), errors.New("my error")).Once()
+
+	*s.ledgerBackend = mockLedgerBackend{}
+
+	s.system.config.ResumeReingest = true
+	err := s.system.ReingestRange(100, 200, false)
+	s.Assert().EqualError(err, "Error getting reingest checkpoints: my error")
+}
+
+func (s *ReingestHistoryRangeStateTestSuite) TestResumeParallelJobKeepsCheckpoints() {
+	s.historyQ.On("GetReingestCheckpoints", uint32(1), uint32(1000)).
+		Return([]history.ReingestCheckpoint{}, nil).Once()
+	s.historyQ.On("GetLastLedgerExpIngestNonBlocking").Return(uint32(0), nil).Once()
+	s.historyQ.On("GetTx").Return(&sqlx.Tx{}).Once()
+
+	toidFrom := toid.New(100, 0, 0)
+	toidTo := toid.New(101, 0, 0)
+	s.historyQ.On(
+		"DeleteRangeAll", toidFrom.ToInt64(), toidTo.ToInt64(),
+	).Return(nil).Once()
+
+	s.runner.On("RunTransactionProcessorsOnLedger", uint32(100)).Return(io.StatsLedgerTransactionProcessorResults{}, nil).Once()
+	s.historyQ.On("UpdateReingestCheckpoint", uint32(1), uint32(1000), uint32(100), uint32(100)).Return(nil).Once()
+	s.historyQ.On("Commit").Return(nil).Once()
+
+	*s.ledgerBackend = mockLedgerBackend{}
+	s.ledgerBackend.On("PrepareRange", ledgerbackend.BoundedRange(100, 100)).Return(nil).Once()
+
+	// the checkpoints of a parallel job are removed once all the batches
+	// are reingested
+	s.system.config.ResumeReingest = true
+	s.system.config.reingestJob = &ledgerRange{1, 1000}
+	err := s.system.ReingestRange(100, 100, false)
+	s.Assert().NoError(err)
+}
+
+func (s *ReingestHistoryRangeStateTestSuite) TestFromHistoryArchiveRefusesIngestedLedgers() {
+	*s.historyQ = mockDBQ{}
+	s.historyQ.On("GetTx").Return(nil).Once()
+	s.historyQ.On("CountLedgersInRange", uint32(100), uint32(200)).Return(3, nil).Once()
+
+	*s.ledgerBackend = mockLedgerBackend{}
+
+	s.system.config.IngestFromHistoryArchive = true
+	err := s.system.ReingestRange(100, 200, true)
+	s.Assert().EqualError(
+		err,
+		"range [100, 200] contains 3 ingested ledgers, only missing ledgers can be ingested from the history archive",
+	)
+	s.ledgerBackend.AssertExpectations(s.T())
+}
+
+func (s *ReingestHistoryRangeStateTestSuite) TestFromHistoryArchiveChecksMissingLedgers() {
+	*s.historyQ = mockDBQ{}
+	s.historyQ.On("GetTx").Return(nil).Once()
+	s.historyQ.On("GetReingestCheckpoints", uint32(100), uint32(200)).
+		Return([]history.ReingestCheckpoint{
+			{RangeFrom: 100, RangeTo: 200, BatchFrom: 100, LastLedger: 197},
+		}, nil).Once()
+	// the ledgers committed before the interruption are not checked
+	s.historyQ.On("CountLedgersInRange", uint32(198), uint32(200)).
+		Return(0, errors.New("my error")).Once()
+
+	*s.ledgerBackend = mockLedgerBackend{}
+
+	s.system.config.ResumeReingest = true
+	s.system.config.IngestFromHistoryArchive = true
+	err := s.system.ReingestRange(100, 200, false)
+	s.Assert().EqualError(err, "Error counting ingested ledgers: my error")
+}
@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/stellar/go/exp/ingest/adapters"
+	"github.com/stellar/go/exp/ingest/ledgerbackend"
 	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -40,6 +42,15 @@ func (s *InitStateTestSuite) TearDownTest() {
 	s.historyAdapter.AssertExpectations(t)
 }
 
+// mockNetworkResetCheck mocks the checks of detectNetworkReset finding no
+// reset, lastCheckpoint is the latest checkpoint in the archive and
+// hashCheckpoint the ledger whose hash is compared (missing in history).
+func (s *InitStateTestSuite) mockNetworkResetCheck(lastCheckpoint, hashCheckpoint uint32) {
+	s.historyQ.On("GetNetworkPassphrase").Return("", nil).Once()
+	s.historyAdapter.On("GetLatestLedgerSequence").Return(lastCheckpoint, nil).Once()
+	s.historyQ.On("GetLedgerHash", hashCheckpoint).Return("", nil).Once()
+}
+
 func (s *InitStateTestSuite) TestBeginReturnsError() {
 	// Recreate mock in this single test to remove Rollback assertion.
 	*s.historyQ = mockDBQ{}
@@ -132,6 +143,7 @@ func (s *InitStateTestSuite) TestBuildStateWait() {
 	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(100), nil).Once()
 	s.historyQ.On("GetExpIngestVersion").Return(0, nil).Once()
 	s.historyQ.On("GetLatestLedger").Return(uint32(100), nil).Once()
+	s.mockNetworkResetCheck(63, 63)
 
 	s.historyAdapter.On("GetLatestLedgerSequence").Return(uint32(63), nil).Once()
 
@@ -151,6 +163,7 @@ func (s *InitStateTestSuite) TestBuildStateCatchup() {
 	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(100), nil).Once()
 	s.historyQ.On("GetExpIngestVersion").Return(0, nil).Once()
 	s.historyQ.On("GetLatestLedger").Return(uint32(100), nil).Once()
+	s.mockNetworkResetCheck(127, 63)
 
 	s.historyAdapter.On("GetLatestLedgerSequence").Return(uint32(127), nil).Once()
 
@@ -173,6 +186,7 @@ func (s *InitStateTestSuite) TestBuildStateOldHistory() {
 	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(127), nil).Once()
 	s.historyQ.On("GetExpIngestVersion").Return(0, nil).Once()
 	s.historyQ.On("GetLatestLedger").Return(uint32(127), nil).Once()
+	s.mockNetworkResetCheck(127, 127)
 
 	s.historyAdapter.On("GetLatestLedgerSequence").Return(uint32(127), nil).Once()
 
@@ -195,6 +209,7 @@ func (s *InitStateTestSuite) TestResumeStateInFront() {
 	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(100), nil).Once()
 	s.historyQ.On("GetExpIngestVersion").Return(CurrentVersion, nil).Once()
 	s.historyQ.On("GetLatestLedger").Return(uint32(130), nil).Once()
+	s.mockNetworkResetCheck(127, 127)
 
 	s.historyQ.On("UpdateLastLedgerExpIngest", uint32(0)).Return(nil).Once()
 	s.historyQ.On("Commit").Return(nil).Once()
@@ -212,6 +227,7 @@ func (s *InitStateTestSuite) TestResumeStateBehind() {
 	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(130), nil).Once()
 	s.historyQ.On("GetExpIngestVersion").Return(CurrentVersion, nil).Once()
 	s.historyQ.On("GetLatestLedger").Return(uint32(100), nil).Once()
+	s.mockNetworkResetCheck(127, 63)
 
 	next, err := startState{}.run(s.system)
 	s.Assert().NoError(err)
@@ -253,6 +269,7 @@ func (s *InitStateTestSuite) TestResumeStateSync() {
 	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(130), nil).Once()
 	s.historyQ.On("GetExpIngestVersion").Return(CurrentVersion, nil).Once()
 	s.historyQ.On("GetLatestLedger").Return(uint32(130), nil).Once()
+	s.mockNetworkResetCheck(127, 127)
 
 	next, err := startState{}.run(s.system)
 	s.Assert().NoError(err)
@@ -264,3 +281,112 @@ func (s *InitStateTestSuite) TestResumeStateSync() {
 		next,
 	)
 }
+
+func (s *InitStateTestSuite) TestNetworkPassphraseChanged() {
+	s.system.config.NetworkPassphrase = "new network"
+	s.historyQ.On("Begin").Return(nil).Once()
+	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(130), nil).Once()
+	s.historyQ.On("GetExpIngestVersion").Return(CurrentVersion, nil).Once()
+	s.historyQ.On("GetLatestLedger").Return(uint32(130), nil).Once()
+	s.historyQ.On("GetNetworkPassphrase").Return("old network", nil).Once()
+
+	next, err := startState{}.run(s.system)
+	s.Assert().EqualError(
+		err,
+		`network reset detected: the history was ingested from the network with passphrase "old network", `+
+			"clear the database or enable automatic resets",
+	)
+	s.Assert().Equal(stop(), next)
+}
+
+func (s *InitStateTestSuite) TestNetworkResetLedgerHashMismatch() {
+	s.system.config.NetworkPassphrase = "test network"
+	s.system.config.AutoResetNetwork = true
+	s.historyQ.On("Begin").Return(nil).Once()
+	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(130), nil).Once()
+	s.historyQ.On("GetExpIngestVersion").Return(CurrentVersion, nil).Once()
+	s.historyQ.On("GetLatestLedger").Return(uint32(130), nil).Once()
+	s.historyQ.On("GetNetworkPassphrase").Return("test network", nil).Once()
+	s.historyAdapter.On("GetLatestLedgerSequence").Return(uint32(127), nil).Once()
+	s.historyQ.On("GetLedgerHash", uint32(127)).
+		Return("0100000000000000000000000000000000000000000000000000000000000000", nil).Once()
+	s.historyAdapter.On("LedgerHash", uint32(127)).Return(xdr.Hash{0x02}, nil).Once()
+
+	s.historyQ.On("TruncateHistoryTables").Return(nil).Once()
+	s.historyQ.On("TruncateExpingestStateTables").Return(nil).Once()
+	s.historyQ.On("UpdateLastLedgerExpIngest", uint32(0)).Return(nil).Once()
+	s.historyQ.On("UpdateExpStateInvalid", false).Return(nil).Once()
+	s.historyQ.On("UpdateNetworkPassphrase", "test network").Return(nil).Once()
+	s.historyQ.On("Commit").Return(nil).Once()
+
+	next, err := startState{}.run(s.system)
+	s.Assert().NoError(err)
+	s.Assert().Equal(transition{node: startState{}, sleepDuration: defaultSleep}, next)
+}
+
+func (s *InitStateTestSuite) TestNetworkResetArchiveCheckCached() {
+	// Recreate mock in this single test to remove Rollback assertion.
+	*s.historyQ = mockDBQ{}
+	s.system.config.NetworkPassphrase = "test network"
+	s.historyQ.On("GetNetworkPassphrase").Return("test network", nil).Twice()
+	s.historyAdapter.On("GetLatestLedgerSequence").Return(uint32(127), nil).Twice()
+	s.historyQ.On("GetLedgerHash", uint32(127)).
+		Return("0100000000000000000000000000000000000000000000000000000000000000", nil).Twice()
+	// the archive is only downloaded the first time
+	s.historyAdapter.On("LedgerHash", uint32(127)).Return(xdr.Hash{0x01}, nil).Once()
+
+	for i := 0; i < 2; i++ {
+		reason, err := s.system.detectNetworkReset(130)
+		s.Assert().NoError(err)
+		s.Assert().Equal("", reason)
+	}
+}
+
+func (s *InitStateTestSuite) TestNetworkResetHistoryAheadOfNetwork() {
+	ledgerBackend := &ledgerbackend.MockDatabaseBackend{}
+	defer ledgerBackend.AssertExpectations(s.T())
+	s.system.ledgerBackend = ledgerBackend
+
+	s.historyQ.On("Begin").Return(nil).Once()
+	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(100000), nil).Once()
+	s.historyQ.On("GetExpIngestVersion").Return(CurrentVersion, nil).Once()
+	s.historyQ.On("GetLatestLedger").Return(uint32(100000), nil).Once()
+	s.historyQ.On("GetNetworkPassphrase").Return("", nil).Once()
+	s.historyAdapter.On("GetLatestLedgerSequence").Return(uint32(127), nil).Once()
+	ledgerBackend.On("GetLatestLedgerSequence").Return(uint32(150), nil).Once()
+
+	next, err := startState{}.run(s.system)
+	s.Assert().EqualError(
+		err,
+		"network reset detected: the last history ledger 100000 is ahead of the history archive (127) "+
+			"and stellar-core (150), clear the database or enable automatic resets",
+	)
+	s.Assert().Equal(stop(), next)
+}
+
+// TestNetworkResetStaleArchive is testing the case when the history archive
+// is behind but stellar-core is not, the network was not reset.
+func (s *InitStateTestSuite) TestNetworkResetStaleArchive() {
+	ledgerBackend := &ledgerbackend.MockDatabaseBackend{}
+	defer ledgerBackend.AssertExpectations(s.T())
+	s.system.ledgerBackend = ledgerBackend
+
+	s.historyQ.On("Begin").Return(nil).Once()
+	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(100000), nil).Once()
+	s.historyQ.On("GetExpIngestVersion").Return(CurrentVersion, nil).Once()
+	s.historyQ.On("GetLatestLedger").Return(uint32(100000), nil).Once()
+	s.historyQ.On("GetNetworkPassphrase").Return("", nil).Once()
+	s.historyAdapter.On("GetLatestLedgerSequence").Return(uint32(63), nil).Once()
+	ledgerBackend.On("GetLatestLedgerSequence").Return(uint32(100001), nil).Once()
+	s.historyQ.On("GetLedgerHash", uint32(63)).Return("", nil).Once()
+
+	next, err := startState{}.run(s.system)
+	s.Assert().NoError(err)
+	s.Assert().Equal(
+		transition{
+			node:          resumeState{latestSuccessfullyProcessedLedger: 100000},
+			sleepDuration: defaultSleep,
+		},
+		next,
+	)
+}
@@ -15,7 +15,10 @@ import (
 	ingesterrors "github.com/stellar/go/exp/ingest/errors"
 	"github.com/stellar/go/exp/ingest/ledgerbackend"
 	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/services/horizon/ingest"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/expingest/processors"
+	"github.com/stellar/go/services/horizon/internal/readonly"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
 	logpkg "github.com/stellar/go/support/log"
@@ -51,6 +54,7 @@ const (
 
 	defaultCoreCursorName           = "HORIZON"
 	stateVerificationErrorThreshold = 3
+	readOnlyCheckInterval           = time.Second
 )
 
 var log = logpkg.DefaultLogger.WithField("service", "expingest")
@@ -68,8 +72,87 @@ type Config struct {
 	HistoryArchiveURL        string
 	DisableStateVerification bool
 
+	// IngestFromHistoryArchive reads the ledgers from the history archive
+	// instead of stellar-core. History archives don't contain the
+	// transaction meta so only the history which doesn't depend on it is
+	// ingested, see ProcessorRunner.buildTransactionProcessor. It is only
+	// supported by ReingestRange.
+	IngestFromHistoryArchive bool
+
 	MaxReingestRetries          int
 	ReingestRetryBackoffSeconds int
+
+	// ReingestProgress, when set, is updated as ledgers are reingested.
+	ReingestProgress *ReingestProgress
+
+	// ResumeReingest records the ledgers committed when reingesting a range
+	// in the reingest_checkpoints table, so reingesting the same range again
+	// after an interruption skips them. The checkpoints of a range are
+	// removed once it has been fully reingested. It has no effect on forced
+	// reingestion, which commits the whole range at once.
+	ResumeReingest bool
+	// reingestJob is the range of the parallel reingestion job the ranges
+	// reingested by the system belong to, checkpoints are recorded for the
+	// job range which is completed by ParallelSystems.
+	reingestJob *ledgerRange
+
+	// AnalyzeAfterReingest runs ANALYZE on history tables after every
+	// reingested range so query plans are not based on stale statistics.
+	AnalyzeAfterReingest bool
+	// VacuumAfterReingest runs VACUUM ANALYZE instead of ANALYZE.
+	VacuumAfterReingest bool
+
+	// ReadOnly, when enabled, pauses the ingestion state machine.
+	ReadOnly *readonly.Mode
+
+	// LatestNetworkLedger, when set, returns the latest ledger of the network
+	// known to stellar-core, or 0 if it's unknown. Live ingestion reports how
+	// many ledgers it is behind it.
+	LatestNetworkLedger func() uint32
+
+	// CatchupPause, when set, pauses live ingestion while the catch-up jobs
+	// sharing it (see GapBackfiller) run.
+	CatchupPause *CatchupPause
+
+	// CaptiveCoreReadAheadLedgers and CaptiveCoreReadAheadBytes limit the
+	// ledgers read from captive stellar-core ahead of ingestion, see
+	// ledgerbackend.CaptiveStellarCore.SetReadAheadLimits.
+	CaptiveCoreReadAheadLedgers int
+	CaptiveCoreReadAheadBytes   int64
+
+	// DataQualityRules are checked on every ingested transaction, their
+	// violations are recorded in the history_data_quality_violations table.
+	DataQualityRules []processors.DataQualityRule
+
+	// TransactionFilter, when set, selects the transactions ingested into
+	// the history tables. The ledger state is always ingested in full.
+	TransactionFilter processors.TransactionFilter
+
+	// IngestionProfile selects the history processors run when ingesting
+	// transactions, all of them are run when it is nil.
+	IngestionProfile processors.IngestionProfile
+
+	// TradeConflicts selects how the trades of a ledger which were already
+	// ingested are handled, they fail the ingestion when it's empty.
+	TradeConflicts history.TradeConflictMode
+
+	// AutoResetNetwork clears the history and the state, and restarts
+	// ingestion from the latest checkpoint, when the ingested history
+	// belongs to another network (ex. the test network before a reset).
+	// Ingestion stops when it's disabled.
+	AutoResetNetwork bool
+
+	// EnableAssetWatches evaluates the asset watches after ingesting every
+	// ledger and notifies the webhooks of the watches which fired.
+	EnableAssetWatches bool
+
+	// Plugins are the plugins whose processors run after the built-in
+	// processors of every ingested ledger.
+	Plugins []ingest.Plugin
+
+	// EventBus, when set, receives the events of every ledger ingested by
+	// the live ingestion, see ingest.Event.
+	EventBus *ingest.Bus
 }
 
 const (
@@ -96,6 +179,30 @@ type Metrics struct {
 	// StateInvalidGauge exposes state invalid metric. 1 if state is invalid,
 	// 0 otherwise.
 	StateInvalidGauge prometheus.GaugeFunc
+
+	// DataQualityViolationsCounter counts violations of the data quality
+	// rules by rule.
+	DataQualityViolationsCounter *prometheus.CounterVec
+
+	// LedgersIngestedCounter counts the ledgers ingested by the live
+	// ingestion, its rate is the number of ledgers ingested per second.
+	LedgersIngestedCounter prometheus.Counter
+
+	// LedgerIngestionLatency exposes the time between the close of a ledger
+	// and the commit of its ingestion.
+	LedgerIngestionLatency prometheus.Summary
+
+	// ProcessorDuration exposes the time spent in each processor (including
+	// its commit) per ledger by processor.
+	ProcessorDuration *prometheus.SummaryVec
+
+	// LedgersBehindGauge exposes the number of ledgers of the network which
+	// are not ingested yet by the live ingestion.
+	LedgersBehindGauge prometheus.Gauge
+
+	// StateQuarantinedEntriesGauge exposes the number of entries quarantined
+	// by the last state audit.
+	StateQuarantinedEntriesGauge prometheus.GaugeFunc
 }
 
 type System interface {
@@ -104,6 +211,7 @@ type System interface {
 	StressTest(numTransactions, changesPerTransaction int) error
 	VerifyRange(fromLedger, toLedger uint32, verifyState bool) error
 	ReingestRange(fromLedger, toLedger uint32, force bool) error
+	RequestStateAudit()
 	Shutdown()
 }
 
@@ -117,6 +225,10 @@ type system struct {
 	historyQ history.IngestionQ
 	runner   ProcessorRunnerInterface
 
+	// dataQualityViolations are the violations found by the runner in the
+	// current transaction, shared with the runner.
+	dataQualityViolations *pendingDataQualityViolations
+
 	ledgerBackend  ledgerbackend.LedgerBackend
 	historyAdapter adapters.HistoryArchiveAdapterInterface
 
@@ -133,6 +245,32 @@ type system struct {
 	stateVerificationErrors  int
 	stateVerificationRunning bool
 	disableStateVerification bool
+
+	// stateAuditRequested is true when a state audit is requested and waits
+	// for the next checkpoint ledger.
+	stateAuditMutex     sync.Mutex
+	stateAuditRequested bool
+	stateAuditRunning   bool
+	// quarantinedEntries is the number of entries quarantined by the last
+	// state audit, loaded at quarantinedEntriesLoadedAt.
+	quarantinedEntriesMutex    sync.Mutex
+	quarantinedEntries         int
+	quarantinedEntriesLoadedAt time.Time
+
+	// fallingBehind is true once live ingestion was reported as falling
+	// behind the network, until it catches up.
+	fallingBehind bool
+
+	// networkCheckpoint and networkCheckpointHash are the checkpoint ledger
+	// and its hash last found in both the history and the history archive by
+	// detectNetworkReset, so the archive is not downloaded again while they
+	// don't change.
+	networkCheckpoint     uint32
+	networkCheckpointHash string
+
+	// lastLedgerRepublished is true once the LedgerIngested event of the last
+	// ledger committed before the system started was published again.
+	lastLedgerRepublished bool
 }
 
 func NewSystem(config Config) (System, error) {
@@ -150,15 +288,11 @@ func NewSystem(config Config) (System, error) {
 	}
 
 	var ledgerBackend ledgerbackend.LedgerBackend
-	if len(config.RemoteCaptiveCoreURL) > 0 {
-		ledgerBackend, err = ledgerbackend.NewRemoteCaptive(config.RemoteCaptiveCoreURL)
-		if err != nil {
-			cancel()
-			return nil, errors.Wrap(err, "error creating captive core backend")
-		}
-	}
-	if len(config.StellarCoreBinaryPath) > 0 {
-		ledgerBackend, err = ledgerbackend.NewCaptive(
+	if config.IngestFromHistoryArchive {
+		ledgerBackend = ledgerbackend.NewHistoryArchiveBackendFromArchive(archive)
+	} else if len(config.StellarCoreBinaryPath) > 0 {
+		var captiveCore *ledgerbackend.CaptiveStellarCore
+		captiveCore, err = ledgerbackend.NewCaptive(
 			config.StellarCoreBinaryPath,
 			config.StellarCoreConfigPath,
 			config.NetworkPassphrase,
@@ -168,6 +302,14 @@ func NewSystem(config Config) (System, error) {
 			cancel()
 			return nil, errors.Wrap(err, "error creating captive core backend")
 		}
+		captiveCore.SetReadAheadLimits(config.CaptiveCoreReadAheadLedgers, config.CaptiveCoreReadAheadBytes)
+		ledgerBackend = captiveCore
+	} else if len(config.RemoteCaptiveCoreURL) > 0 {
+		ledgerBackend, err = ledgerbackend.NewRemoteCaptive(config.RemoteCaptiveCoreURL)
+		if err != nil {
+			cancel()
+			return nil, errors.Wrap(err, "error creating captive core backend")
+		}
 	} else {
 		coreSession := config.CoreSession.Clone()
 		coreSession.Ctx = ctx
@@ -196,16 +338,23 @@ func NewSystem(config Config) (System, error) {
 		stellarCoreClient: &stellarcore.Client{
 			URL: config.StellarCoreURL,
 		},
-		runner: &ProcessorRunner{
-			ctx:            ctx,
-			config:         config,
-			historyQ:       historyQ,
-			historyAdapter: historyAdapter,
-			ledgerBackend:  ledgerBackend,
-		},
+	}
+
+	for _, plugin := range config.Plugins {
+		log.WithField("plugin", plugin.Name).Info("Ingestion plugin registered")
 	}
 
 	system.initMetrics()
+	system.dataQualityViolations = newPendingDataQualityViolations(system.metrics.DataQualityViolationsCounter)
+	system.runner = &ProcessorRunner{
+		ctx:                   ctx,
+		config:                config,
+		historyQ:              historyQ,
+		historyAdapter:        historyAdapter,
+		ledgerBackend:         ledgerBackend,
+		dataQualityViolations: system.dataQualityViolations,
+		processorDuration:     system.metrics.ProcessorDuration,
+	}
 	return system, nil
 }
 
@@ -238,12 +387,68 @@ func (s *system) initMetrics() {
 			return invalidFloat
 		},
 	)
+
+	s.metrics.DataQualityViolationsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "horizon", Subsystem: "ingest", Name: "data_quality_violations_total",
+			Help: "number of violations of the data quality rules found in ingested transactions",
+		},
+		[]string{"rule"},
+	)
+
+	s.metrics.LedgersIngestedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "horizon", Subsystem: "ingest", Name: "ledgers_ingested_total",
+		Help: "number of ledgers ingested by the live ingestion",
+	})
+
+	s.metrics.LedgerIngestionLatency = prometheus.NewSummary(prometheus.SummaryOpts{
+		Namespace: "horizon", Subsystem: "ingest", Name: "ledger_ingestion_latency_seconds",
+		Help: "time between the close of a ledger and the commit of its ingestion, sliding window = 10m",
+	})
+
+	s.metrics.ProcessorDuration = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace: "horizon", Subsystem: "ingest", Name: "processor_duration_seconds",
+			Help: "time spent in each processor per ledger, sliding window = 10m",
+		},
+		[]string{"processor"},
+	)
+
+	s.metrics.LedgersBehindGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "horizon", Subsystem: "ingest", Name: "ledgers_behind",
+		Help: "number of ledgers of the network which are not ingested yet by the live ingestion",
+	})
+
+	s.metrics.StateQuarantinedEntriesGauge = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: "horizon", Subsystem: "ingest", Name: "state_quarantined_entries",
+			Help: "number of ledger entries not matching the checkpoint found by the last state audit",
+		},
+		func() float64 {
+			return float64(s.countQuarantinedEntries())
+		},
+	)
 }
 
 func (s *system) Metrics() Metrics {
 	return s.metrics
 }
 
+// observeLedgerIngestionLatency updates LedgerIngestionLatency with the time
+// elapsed since the close of the ingested ledger. The ledger is buffered by
+// the ledger backend so it doesn't need to be fetched again.
+func (s *system) observeLedgerIngestionLatency(sequence uint32) {
+	exists, ledgerCloseMeta, err := s.ledgerBackend.GetLedger(sequence)
+	if err != nil || !exists || ledgerCloseMeta.V0 == nil {
+		log.WithField("sequence", sequence).WithError(err).
+			Warn("Error getting ledger close time")
+		return
+	}
+
+	closeTime := time.Unix(int64(ledgerCloseMeta.V0.LedgerHeader.Header.ScpValue.CloseTime), 0)
+	s.Metrics().LedgerIngestionLatency.Observe(time.Since(closeTime).Seconds())
+}
+
 // Run starts ingestion system. Ingestion system supports distributed ingestion
 // that means that Horizon ingestion can be running on multiple machines and
 // only one, random node will lead the ingestion.
@@ -329,7 +534,24 @@ func (s *system) runStateMachine(cur stateMachineNode) error {
 
 	log.WithFields(logpkg.F{"current_state": cur}).Info("Ingestion system initial state")
 
+	if _, ok := cur.(reingestHistoryRangeState); s.config.IngestFromHistoryArchive && !ok {
+		return errors.New("ingesting from the history archive is only supported when reingesting ledgers")
+	}
+
 	for {
+		// Ingestion doesn't write to the DB in read-only mode, or while
+		// catch-up jobs run. The current state is run again when the mode is
+		// turned off.
+		if s.config.ReadOnly.Enabled() || s.pausedForCatchup(cur) {
+			select {
+			case <-s.ctx.Done():
+				log.Info("Received shut down signal...")
+				return nil
+			case <-time.After(readOnlyCheckInterval):
+			}
+			continue
+		}
+
 		// Every node in the state machine is responsible for
 		// creating and disposing its own transaction.
 		// We should never enter a new state with the transaction
@@ -13,7 +13,9 @@ import (
 	"github.com/stellar/go/exp/ingest/adapters"
 	"github.com/stellar/go/exp/ingest/io"
 	"github.com/stellar/go/exp/ingest/ledgerbackend"
+	"github.com/stellar/go/services/horizon/internal/dashboards"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/readonly"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
 	logpkg "github.com/stellar/go/support/log"
@@ -101,6 +103,29 @@ func TestNewSystem(t *testing.T) {
 	assert.Equal(t, system.ctx, system.runner.(*ProcessorRunner).ctx)
 }
 
+func TestDashboardMetrics(t *testing.T) {
+	system := &system{}
+	system.initMetrics()
+	metrics := system.Metrics()
+	names := dashboards.MetricNames(
+		metrics.LedgerIngestionDuration,
+		metrics.StateVerifyDuration,
+		metrics.StateInvalidGauge,
+		metrics.DataQualityViolationsCounter,
+		metrics.LedgersIngestedCounter,
+		metrics.LedgerIngestionLatency,
+		metrics.ProcessorDuration,
+		metrics.LedgersBehindGauge,
+		metrics.StateQuarantinedEntriesGauge,
+	)
+
+	referenced := dashboards.Metrics("horizon_ingest_")
+	assert.NotEmpty(t, referenced)
+	for _, name := range referenced {
+		assert.True(t, names[name], "%s is not exported", name)
+	}
+}
+
 func TestStateMachineRunReturnsUnexpectedTransaction(t *testing.T) {
 	historyQ := &mockDBQ{}
 	system := &system{
@@ -146,6 +171,21 @@ func TestContextCancel(t *testing.T) {
 	assert.NoError(t, system.runStateMachine(startState{}))
 }
 
+func TestReadOnlyModePausesStateMachine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	historyQ := &mockDBQ{}
+	system := &system{
+		config:   Config{ReadOnly: readonly.NewMode(true)},
+		historyQ: historyQ,
+		ctx:      ctx,
+	}
+
+	// no calls to historyQ are expected while paused
+	cancel()
+	assert.NoError(t, system.runStateMachine(startState{}))
+	historyQ.AssertExpectations(t)
+}
+
 // TestStateMachineRunReturnsErrorWhenNextStateIsShutdownWithError checks if the
 // state that goes to shutdownState and returns an error will make `run` function
 // return that error. This is essential because some commands rely on this to return
@@ -229,11 +269,16 @@ func TestMaybeVerifyInternalDBErrCancelOrContextCanceled(t *testing.T) {
 type mockDBQ struct {
 	mock.Mock
 
+	history.MockQAccountEvents
+	history.MockQAccountThresholds
 	history.MockQAccounts
 	history.MockQAssetStats
 	history.MockQData
+	history.MockQDataQualityViolations
 	history.MockQEffects
 	history.MockQLedgers
+	history.MockQLedgerManifests
+	history.MockQHistoryOffers
 	history.MockQOffers
 	history.MockQOperations
 	history.MockQSigners
@@ -329,11 +374,89 @@ func (m *mockDBQ) TruncateExpingestStateTables() error {
 	return args.Error(0)
 }
 
+func (m *mockDBQ) TruncateHistoryTables() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *mockDBQ) GetLedgerHash(sequence uint32) (string, error) {
+	args := m.Called(sequence)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockDBQ) GetNetworkPassphrase() (string, error) {
+	args := m.Called()
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockDBQ) UpdateNetworkPassphrase(passphrase string) error {
+	args := m.Called(passphrase)
+	return args.Error(0)
+}
+
+func (m *mockDBQ) CountLedgersInRange(fromSeq, toSeq uint32) (int, error) {
+	args := m.Called(fromSeq, toSeq)
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (m *mockDBQ) GetReingestCheckpoints(rangeFrom, rangeTo uint32) ([]history.ReingestCheckpoint, error) {
+	args := m.Called(rangeFrom, rangeTo)
+	return args.Get(0).([]history.ReingestCheckpoint), args.Error(1)
+}
+
+func (m *mockDBQ) UpdateReingestCheckpoint(rangeFrom, rangeTo, batchFrom, lastLedger uint32) error {
+	args := m.Called(rangeFrom, rangeTo, batchFrom, lastLedger)
+	return args.Error(0)
+}
+
+func (m *mockDBQ) DeleteReingestCheckpoints(rangeFrom, rangeTo uint32) error {
+	args := m.Called(rangeFrom, rangeTo)
+	return args.Error(0)
+}
+
 func (m *mockDBQ) DeleteRangeAll(start, end int64) error {
 	args := m.Called(start, end)
 	return args.Error(0)
 }
 
+func (m *mockDBQ) AnalyzeHistoryTables(vacuum bool) error {
+	args := m.Called(vacuum)
+	return args.Error(0)
+}
+
+func (m *mockDBQ) CreateHistoryPartitions(ledger uint32) error {
+	args := m.Called(ledger)
+	return args.Error(0)
+}
+
+// RetryableTransaction runs fn between the mocked Begin and Commit calls.
+func (m *mockDBQ) RetryableTransaction(ctx context.Context, fn func() error) error {
+	if err := m.Begin(); err != nil {
+		return errors.Wrap(err, "Error starting a transaction")
+	}
+	defer m.Rollback()
+
+	if err := fn(); err != nil {
+		return err
+	}
+	return errors.Wrap(m.Commit(), "Error committing db transaction")
+}
+
+func (m *mockDBQ) EvaluateAssetWatches(ledger uint32) ([]history.AssetWatchEvent, error) {
+	args := m.Called(ledger)
+	return args.Get(0).([]history.AssetWatchEvent), args.Error(1)
+}
+
+func (m *mockDBQ) ReplaceQuarantinedEntries(sequence uint32, entries []history.QuarantinedEntry) error {
+	args := m.Called(sequence, entries)
+	return args.Error(0)
+}
+
+func (m *mockDBQ) CountQuarantinedEntries() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
 // Methods from interfaces duplicating methods:
 
 func (m *mockDBQ) NewTransactionParticipantsBatchInsertBuilder(maxBatchSize int) history.TransactionParticipantsBatchInsertBuilder {
@@ -346,8 +469,8 @@ func (m *mockDBQ) NewOperationParticipantBatchInsertBuilder(maxBatchSize int) hi
 	return args.Get(0).(history.TransactionParticipantsBatchInsertBuilder)
 }
 
-func (m *mockDBQ) NewTradeBatchInsertBuilder(maxBatchSize int) history.TradeBatchInsertBuilder {
-	args := m.Called(maxBatchSize)
+func (m *mockDBQ) NewTradeBatchInsertBuilder(maxBatchSize int, onConflict history.TradeConflictMode) history.TradeBatchInsertBuilder {
+	args := m.Called(maxBatchSize, onConflict)
 	return args.Get(0).(history.TradeBatchInsertBuilder)
 }
 
@@ -463,6 +586,10 @@ func (m *mockSystem) ReingestRange(fromLedger, toLedger uint32, force bool) erro
 	return args.Error(0)
 }
 
+func (m *mockSystem) RequestStateAudit() {
+	m.Called()
+}
+
 func (m *mockSystem) Shutdown() {
 	m.Called()
 }
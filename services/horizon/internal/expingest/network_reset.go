@@ -0,0 +1,116 @@
+package expingest
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/support/errors"
+	logpkg "github.com/stellar/go/support/log"
+)
+
+// networkResetMargin is the number of ledgers the latest history ledger must
+// be ahead of both the history archive and stellar-core to consider that the
+// network was reset. Archives are published every checkpoint so they are
+// always slightly behind the network.
+const networkResetMargin = 10 * historyarchive.CheckpointFreq
+
+// detectNetworkReset compares the ingested history with the network and
+// returns the reason why the history belongs to another network (ex. the
+// test network before it was reset), or "" if it matches the network.
+func (s *system) detectNetworkReset(lastHistoryLedger uint32) (string, error) {
+	passphrase, err := s.historyQ.GetNetworkPassphrase()
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting network passphrase")
+	}
+	if passphrase != "" && passphrase != s.config.NetworkPassphrase {
+		return fmt.Sprintf(
+			"the history was ingested from the network with passphrase %q", passphrase,
+		), nil
+	}
+
+	lastCheckpoint, err := s.historyAdapter.GetLatestLedgerSequence()
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting last checkpoint")
+	}
+
+	if lastHistoryLedger > lastCheckpoint+networkResetMargin {
+		// the archive may only be stale, the network was reset if
+		// stellar-core is behind too
+		latestLedgerCore, err := s.ledgerBackend.GetLatestLedgerSequence()
+		if err != nil {
+			log.WithError(err).Warn("Error getting latest ledger in stellar-core, skipping network reset check")
+		} else if lastHistoryLedger > latestLedgerCore+networkResetMargin {
+			return fmt.Sprintf(
+				"the last history ledger %d is ahead of the history archive (%d) and stellar-core (%d)",
+				lastHistoryLedger, lastCheckpoint, latestLedgerCore,
+			), nil
+		}
+	}
+
+	// Compare the hash of the last checkpoint ledger known to both the
+	// history and the archive. It's not in the history if the ledger was
+	// reaped or never ingested.
+	checkpoint := lastCheckpoint
+	if lastHistoryLedger < checkpoint {
+		checkpoint = lastHistoryLedger
+	}
+	if !historyarchive.IsCheckpoint(checkpoint) {
+		if checkpoint < historyarchive.CheckpointFreq {
+			return "", nil
+		}
+		checkpoint = historyarchive.PrevCheckpoint(checkpoint)
+	}
+
+	hash, err := s.historyQ.GetLedgerHash(checkpoint)
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting history ledger hash")
+	}
+	if hash == "" {
+		return "", nil
+	}
+	if checkpoint == s.networkCheckpoint && hash == s.networkCheckpointHash {
+		return "", nil
+	}
+
+	archiveHash, err := s.historyAdapter.LedgerHash(checkpoint)
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting history archive ledger hash")
+	}
+	if archiveHex := hex.EncodeToString(archiveHash[:]); archiveHex != hash {
+		return fmt.Sprintf(
+			"the hash of ledger %d is %s in the history and %s in the history archive",
+			checkpoint, hash, archiveHex,
+		), nil
+	}
+
+	s.networkCheckpoint, s.networkCheckpointHash = checkpoint, hash
+	return "", nil
+}
+
+// resetNetwork clears the history and the state ingested from the previous
+// network so ingestion restarts from the latest checkpoint of the new
+// network. The tables configured by operators (ex. trade retention policies
+// and asset watches) are kept. It must be called in a transaction.
+func (s *system) resetNetwork(reason string) error {
+	log.WithFields(logpkg.F{
+		"reason": reason,
+	}).Warn("Network reset detected, clearing history and ingestion state...")
+
+	if err := s.historyQ.TruncateHistoryTables(); err != nil {
+		return errors.Wrap(err, "Error clearing history tables")
+	}
+	if err := s.historyQ.TruncateExpingestStateTables(); err != nil {
+		return errors.Wrap(err, "Error clearing ingest tables")
+	}
+	if err := s.historyQ.UpdateLastLedgerExpIngest(0); err != nil {
+		return errors.Wrap(err, updateLastLedgerExpIngestErrMsg)
+	}
+	if err := s.historyQ.UpdateExpStateInvalid(false); err != nil {
+		return errors.Wrap(err, updateExpStateInvalidErrMsg)
+	}
+	if err := s.historyQ.UpdateNetworkPassphrase(s.config.NetworkPassphrase); err != nil {
+		return errors.Wrap(err, "Error updating network passphrase")
+	}
+	return nil
+}
@@ -2,8 +2,11 @@ package expingest
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
+	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/support/errors"
 	logpkg "github.com/stellar/go/support/log"
 )
@@ -18,6 +21,88 @@ type ledgerRange struct {
 	to   uint32
 }
 
+func (r ledgerRange) String() string {
+	return fmt.Sprintf("[%d, %d]", r.from, r.to)
+}
+
+// ledgerRangeSet merges the ledger ranges reingested by the workers, which
+// complete in any order, into sorted disjoint ranges.
+type ledgerRangeSet struct {
+	ranges []ledgerRange
+}
+
+// add adds r to the set, merging it with the overlapping and adjacent ranges.
+func (s *ledgerRangeSet) add(r ledgerRange) {
+	merged := []ledgerRange{}
+	for _, existing := range s.ranges {
+		if uint64(existing.to)+1 < uint64(r.from) || uint64(r.to)+1 < uint64(existing.from) {
+			merged = append(merged, existing)
+			continue
+		}
+		if existing.from < r.from {
+			r.from = existing.from
+		}
+		if existing.to > r.to {
+			r.to = existing.to
+		}
+	}
+	merged = append(merged, r)
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].from < merged[j].from
+	})
+	s.ranges = merged
+}
+
+// contiguousTo returns the last ledger l such that [from, l] is in the set.
+// ok is false if from is not in the set.
+func (s *ledgerRangeSet) contiguousTo(from uint32) (l uint32, ok bool) {
+	for _, r := range s.ranges {
+		if r.from <= from && from <= r.to {
+			return r.to, true
+		}
+	}
+	return 0, false
+}
+
+// count returns the number of ledgers in the set.
+func (s *ledgerRangeSet) count() uint32 {
+	var n uint32
+	for _, r := range s.ranges {
+		n += r.to - r.from + 1
+	}
+	return n
+}
+
+// missing returns the sub ranges of [from, to] which are not in the set.
+func (s *ledgerRangeSet) missing(from, to uint32) []ledgerRange {
+	var result []ledgerRange
+	next := from
+	for _, r := range s.ranges {
+		if r.to < next {
+			continue
+		}
+		if r.from > to {
+			break
+		}
+		if r.from > next {
+			result = append(result, ledgerRange{next, r.from - 1})
+		}
+		if r.to >= to {
+			return result
+		}
+		next = r.to + 1
+	}
+	return append(result, ledgerRange{next, to})
+}
+
+func formatLedgerRanges(ranges []ledgerRange) string {
+	formatted := make([]string, len(ranges))
+	for i, r := range ranges {
+		formatted[i] = r.String()
+	}
+	return strings.Join(formatted, ", ")
+}
+
 type rangeError struct {
 	err         error
 	ledgerRange ledgerRange
@@ -51,7 +136,12 @@ func newParallelSystems(config Config, workerCount uint, systemFactory func(Conf
 	}, nil
 }
 
-func (ps *ParallelSystems) runReingestWorker(s System, stop <-chan struct{}, reingestJobQueue <-chan ledgerRange) rangeError {
+func (ps *ParallelSystems) runReingestWorker(
+	s System,
+	stop <-chan struct{},
+	reingestJobQueue <-chan ledgerRange,
+	reingested func(ledgerRange),
+) rangeError {
 	for {
 		select {
 		case <-stop:
@@ -65,6 +155,7 @@ func (ps *ParallelSystems) runReingestWorker(s System, stop <-chan struct{}, rei
 				}
 			}
 			log.WithFields(logpkg.F{"from": reingestRange.from, "to": reingestRange.to}).Info("successfully reingested range")
+			reingested(reingestRange)
 		}
 	}
 }
@@ -104,17 +195,41 @@ func (ps *ParallelSystems) ReingestRange(fromLedger, toLedger uint32, batchSizeS
 		// or failure). In case of a failure we save the range with the smallest sequence number because this is where
 		// the user needs to start again to prevent the gaps.
 		lowestRangeErr *rangeError
+
+		// completed merges the reingested ranges so the ledgers reingested
+		// without gaps from fromLedger (the checkpoint to restart from) and the
+		// ranges left to reingest after a failure can be reported.
+		completedMutex sync.Mutex
+		completed      ledgerRangeSet
+		checkpoint     *uint32
 	)
 
+	reingested := func(r ledgerRange) {
+		completedMutex.Lock()
+		defer completedMutex.Unlock()
+		completed.add(r)
+		if to, ok := completed.contiguousTo(fromLedger); ok && (checkpoint == nil || to > *checkpoint) {
+			checkpoint = &to
+			log.WithFields(logpkg.F{"from": fromLedger, "to": to}).Info("reingested all ledgers of range")
+		}
+	}
+
+	// the workers record the ledgers they commit as checkpoints of the whole
+	// range so an interrupted job resumes where it stopped
+	config := ps.config
+	if config.ResumeReingest {
+		config.reingestJob = &ledgerRange{fromLedger, toLedger}
+	}
+
 	for i := uint(0); i < ps.workerCount; i++ {
 		wg.Add(1)
-		s, err := ps.systemFactory(ps.config)
+		s, err := ps.systemFactory(config)
 		if err != nil {
 			return errors.Wrap(err, "error creating new system")
 		}
 		go func() {
 			defer wg.Done()
-			rangeErr := ps.runReingestWorker(s, stop, reingestJobQueue)
+			rangeErr := ps.runReingestWorker(s, stop, reingestJobQueue, reingested)
 			if rangeErr.err != nil {
 				log.WithError(rangeErr).Error("error in reingest worker")
 				lowestRangeErrMutex.Lock()
@@ -131,7 +246,7 @@ func (ps *ParallelSystems) ReingestRange(fromLedger, toLedger uint32, batchSizeS
 	}
 
 rangeQueueLoop:
-	for subRangeFrom := fromLedger; subRangeFrom < toLedger; {
+	for subRangeFrom := fromLedger; subRangeFrom <= toLedger; {
 		// job queuing
 		subRangeTo := subRangeFrom + (batchSize - 1) // we subtract one because both from and to are part of the batch
 		if subRangeTo > toLedger {
@@ -152,7 +267,17 @@ rangeQueueLoop:
 	close(reingestJobQueue)
 
 	if lowestRangeErr != nil {
+		log.WithField(
+			"ranges", formatLedgerRanges(completed.missing(fromLedger, toLedger)),
+		).Error("ledger ranges left to reingest")
 		return errors.Wrapf(lowestRangeErr, "job failed, recommended restart range: [%d, %d]", lowestRangeErr.ledgerRange.from, toLedger)
 	}
+
+	if config.ResumeReingest {
+		q := &history.Q{Session: config.HistorySession}
+		if err := q.DeleteReingestCheckpoints(fromLedger, toLedger); err != nil {
+			return errors.Wrap(err, "Error removing reingest checkpoints")
+		}
+	}
 	return nil
 }
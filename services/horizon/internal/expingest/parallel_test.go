@@ -108,3 +108,52 @@ func TestParallelReingestRangeErrorInEarlierJob(t *testing.T) {
 	assert.Equal(t, "job failed, recommended restart range: [1024, 2050]: error when processing [1024, 1279] range: failed because of foo", err.Error())
 
 }
+
+func TestParallelReingestRangeLastLedger(t *testing.T) {
+	var rangesCalled sorteableRanges
+	factory := func(c Config) (System, error) {
+		result := &mockSystem{}
+		result.On("ReingestRange", mock.AnythingOfType("uint32"), mock.AnythingOfType("uint32"), mock.AnythingOfType("bool")).Run(
+			func(args mock.Arguments) {
+				rangesCalled = append(rangesCalled, ledgerRange{
+					from: args.Get(0).(uint32),
+					to:   args.Get(1).(uint32),
+				})
+			}).Return(error(nil))
+		return result, nil
+	}
+	system, err := newParallelSystems(Config{}, 1, factory)
+	assert.NoError(t, err)
+	assert.NoError(t, system.ReingestRange(0, 256, 256))
+	assert.Equal(t, sorteableRanges{{from: 0, to: 255}, {from: 256, to: 256}}, rangesCalled)
+}
+
+func TestLedgerRangeSet(t *testing.T) {
+	var set ledgerRangeSet
+	_, ok := set.contiguousTo(10)
+	assert.False(t, ok)
+	assert.Equal(t, []ledgerRange{{10, 100}}, set.missing(10, 100))
+
+	set.add(ledgerRange{50, 59})
+	set.add(ledgerRange{10, 19})
+	set.add(ledgerRange{30, 39})
+	assert.Equal(t, []ledgerRange{{10, 19}, {30, 39}, {50, 59}}, set.ranges)
+	to, ok := set.contiguousTo(10)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(19), to)
+	assert.Equal(t, []ledgerRange{{20, 29}, {40, 49}, {60, 100}}, set.missing(10, 100))
+	assert.Equal(t, "[20, 29], [40, 49], [60, 100]", formatLedgerRanges(set.missing(10, 100)))
+
+	// adjacent ranges are merged
+	set.add(ledgerRange{20, 29})
+	assert.Equal(t, []ledgerRange{{10, 39}, {50, 59}}, set.ranges)
+	to, _ = set.contiguousTo(10)
+	assert.Equal(t, uint32(39), to)
+
+	// overlapping ranges are merged
+	set.add(ledgerRange{35, 55})
+	assert.Equal(t, []ledgerRange{{10, 59}}, set.ranges)
+	assert.Equal(t, []ledgerRange{{60, 100}}, set.missing(10, 100))
+	assert.Empty(t, set.missing(10, 59))
+	assert.Equal(t, []ledgerRange{{0, 9}}, set.missing(0, 59))
+}
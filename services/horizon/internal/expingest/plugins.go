@@ -0,0 +1,66 @@
+package expingest
+
+import (
+	"github.com/stellar/go/services/horizon/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/xdr"
+)
+
+// pluginChangeProcessor is a change processor of a plugin, it's named after
+// the plugin in errors and metrics.
+type pluginChangeProcessor struct {
+	name string
+	ingest.ChangeProcessor
+}
+
+// pluginTransactionProcessor is a transaction processor of a plugin, it's
+// named after the plugin in errors and metrics.
+type pluginTransactionProcessor struct {
+	name string
+	ingest.TransactionProcessor
+}
+
+// pluginChangeProcessors returns the change processors of the plugins for
+// the ledger with the given sequence.
+func (s *ProcessorRunner) pluginChangeProcessors(sequence uint32) []horizonChangeProcessor {
+	var group []horizonChangeProcessor
+	for _, plugin := range s.config.Plugins {
+		if plugin.NewChangeProcessor == nil {
+			continue
+		}
+		group = append(group, &pluginChangeProcessor{
+			name:            plugin.Name,
+			ChangeProcessor: plugin.NewChangeProcessor(sequence, s.pluginSession()),
+		})
+	}
+	return group
+}
+
+// pluginTransactionProcessors returns the transaction processors of the
+// plugins for ledger.
+func (s *ProcessorRunner) pluginTransactionProcessors(ledger xdr.LedgerHeaderHistoryEntry) []horizonTransactionProcessor {
+	var group []horizonTransactionProcessor
+	for _, plugin := range s.config.Plugins {
+		if plugin.NewTransactionProcessor == nil {
+			continue
+		}
+		group = append(group, &pluginTransactionProcessor{
+			name:                 plugin.Name,
+			TransactionProcessor: plugin.NewTransactionProcessor(ledger, s.pluginSession()),
+		})
+	}
+	return group
+}
+
+// pluginSession returns the session of the ingestion transaction, it's nil
+// when historyQ is not backed by a database (ex. in tests).
+func (s *ProcessorRunner) pluginSession() *db.Session {
+	if q, ok := s.historyQ.(*history.Q); ok {
+		return q.Session
+	}
+	return nil
+}
+
+var _ horizonChangeProcessor = (*pluginChangeProcessor)(nil)
+var _ horizonTransactionProcessor = (*pluginTransactionProcessor)(nil)
@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/stellar/go/exp/ingest/adapters"
 	"github.com/stellar/go/exp/ingest/io"
 	"github.com/stellar/go/exp/ingest/ledgerbackend"
@@ -69,6 +71,13 @@ type ProcessorRunner struct {
 	historyAdapter adapters.HistoryArchiveAdapterInterface
 	ledgerBackend  ledgerbackend.LedgerBackend
 	logMemoryStats bool
+
+	// dataQualityViolations optionally counts the violations of
+	// config.DataQualityRules until the ledgers are committed.
+	dataQualityViolations *pendingDataQualityViolations
+	// processorDuration is the optional summary observing the time spent
+	// in each processor of the ledgers.
+	processorDuration *prometheus.SummaryVec
 }
 
 func (s *ProcessorRunner) SetLedgerBackend(ledgerBackend ledgerbackend.LedgerBackend) {
@@ -97,7 +106,7 @@ func (s *ProcessorRunner) buildChangeProcessor(
 	}
 
 	useLedgerCache := source == ledgerSource
-	return groupChangeProcessors{
+	group := groupChangeProcessors{
 		statsChangeProcessor,
 		processors.NewAccountDataProcessor(s.historyQ),
 		processors.NewAccountsProcessor(s.historyQ),
@@ -106,6 +115,15 @@ func (s *ProcessorRunner) buildChangeProcessor(
 		processors.NewSignersProcessor(s.historyQ, useLedgerCache),
 		processors.NewTrustLinesProcessor(s.historyQ),
 	}
+	group = append(group, s.pluginChangeProcessors(sequence)...)
+	// only the processing of ledgers is timed, the history archive is
+	// ingested once when the state is built
+	if s.processorDuration != nil && source == ledgerSource {
+		for i, processor := range group {
+			group[i] = newTimedChangeProcessor(processor, s.processorDuration)
+		}
+	}
+	return group
 }
 
 func (s *ProcessorRunner) buildTransactionProcessor(
@@ -117,15 +135,136 @@ func (s *ProcessorRunner) buildTransactionProcessor(
 	}
 
 	sequence := uint32(ledger.Header.LedgerSeq)
-	return groupTransactionProcessors{
+	// the trades and effects of the transactions are counted by their
+	// processors, the counts of the processors removed below are 0
+	counter := processors.NewTransactionCounter()
+	group := groupTransactionProcessors{
 		statsLedgerTransactionProcessor,
-		processors.NewEffectProcessor(s.historyQ, sequence),
+		processors.NewEffectProcessor(s.historyQ, sequence, counter),
 		processors.NewLedgerProcessor(s.historyQ, ledger, CurrentVersion),
 		processors.NewOperationProcessor(s.historyQ, sequence),
-		processors.NewTradeProcessor(s.historyQ, ledger),
+		processors.NewTradeProcessor(s.historyQ, ledger, s.config.TradeConflicts, counter),
 		processors.NewParticipantsProcessor(s.historyQ, sequence),
-		processors.NewTransactionProcessor(s.historyQ, sequence),
+		processors.NewTransactionProcessor(s.historyQ, sequence, counter),
+		processors.NewHistoryOffersProcessor(s.historyQ, sequence),
+		processors.NewAccountEventsProcessor(s.historyQ, sequence),
+		processors.NewAccountThresholdsProcessor(s.historyQ, sequence),
+	}
+	if s.config.IngestFromHistoryArchive {
+		group = withoutMetaProcessors(group)
+	}
+	if s.config.IngestionProfile != nil {
+		group = withProfile(group, s.config.IngestionProfile)
+	}
+	// the manifest covers the trades and payments of the transactions
+	// ingested by the processors above
+	var trades, payments bool
+	for _, processor := range group {
+		switch processor.(type) {
+		case *processors.TradeProcessor:
+			trades = true
+		case *processors.OperationProcessor:
+			payments = true
+		}
 	}
+	group = append(group, processors.NewLedgerManifestProcessor(s.historyQ, ledger, trades, payments))
+	if s.config.TransactionFilter != nil {
+		// the stats and the ledger header always cover all the transactions
+		// of the ledger, only the history of the kept transactions is
+		// ingested
+		all := groupTransactionProcessors{s.timedTransactionProcessor(group[0])}
+		var history groupTransactionProcessors
+		for _, processor := range group[1:] {
+			if _, ok := processor.(*processors.LedgersProcessor); ok {
+				all = append(all, s.timedTransactionProcessor(processor))
+			} else {
+				history = append(history, s.timedTransactionProcessor(processor))
+			}
+		}
+		group = append(all, filteredTransactionProcessor{
+			filter:    s.config.TransactionFilter,
+			sequence:  sequence,
+			processor: history,
+		})
+	} else {
+		for i, processor := range group {
+			group[i] = s.timedTransactionProcessor(processor)
+		}
+	}
+	// plugins receive all the transactions, filtered or not
+	for _, processor := range s.pluginTransactionProcessors(ledger) {
+		group = append(group, s.timedTransactionProcessor(processor))
+	}
+	if len(s.config.DataQualityRules) > 0 && !s.config.IngestFromHistoryArchive {
+		group = append(group, s.timedTransactionProcessor(processors.NewDataQualityProcessor(
+			s.historyQ, sequence, s.config.DataQualityRules, s.dataQualityViolations,
+		)))
+	}
+	return group
+}
+
+// withoutMetaProcessors removes the processors which need the transaction
+// meta, which history archives don't contain, from group: effects, trades,
+// history offers and account thresholds are not ingested.
+func withoutMetaProcessors(group groupTransactionProcessors) groupTransactionProcessors {
+	var result groupTransactionProcessors
+	for _, processor := range group {
+		switch processor.(type) {
+		case *processors.EffectProcessor,
+			*processors.TradeProcessor,
+			*processors.HistoryOffersProcessor,
+			*processors.AccountThresholdsProcessor:
+		default:
+			result = append(result, processor)
+		}
+	}
+	return result
+}
+
+// withProfile removes the history processors disabled by profile from group.
+func withProfile(group groupTransactionProcessors, profile processors.IngestionProfile) groupTransactionProcessors {
+	var result groupTransactionProcessors
+	for _, processor := range group {
+		if name, ok := historyProcessorName(processor); ok && !profile.Enabled(name) {
+			continue
+		}
+		result = append(result, processor)
+	}
+	return result
+}
+
+// historyProcessorName returns the name of processor in ingestion profiles,
+// it returns false for the processors which are always run.
+func historyProcessorName(processor horizonTransactionProcessor) (string, bool) {
+	switch processor.(type) {
+	case *processors.EffectProcessor:
+		return processors.EffectsHistory, true
+	case *processors.OperationProcessor:
+		return processors.OperationsHistory, true
+	case *processors.TradeProcessor:
+		return processors.TradesHistory, true
+	case *processors.ParticipantsProcessor:
+		return processors.ParticipantsHistory, true
+	case *processors.TransactionProcessor:
+		return processors.TransactionsHistory, true
+	case *processors.HistoryOffersProcessor:
+		return processors.OffersHistory, true
+	case *processors.AccountEventsProcessor:
+		return processors.AccountEventsHistory, true
+	case *processors.AccountThresholdsProcessor:
+		return processors.AccountThresholdsHistory, true
+	default:
+		return "", false
+	}
+}
+
+// timedTransactionProcessor wraps processor so the time spent in it is
+// observed by processorDuration, if set.
+func (s *ProcessorRunner) timedTransactionProcessor(processor horizonTransactionProcessor) horizonTransactionProcessor {
+	if s.processorDuration == nil {
+		return processor
+	}
+	return newTimedTransactionProcessor(processor, s.processorDuration)
 }
 
 // validateBucketList validates if the bucket list hash in history archive
@@ -242,6 +381,13 @@ func (s *ProcessorRunner) runChangeProcessorOnLedger(
 func (s *ProcessorRunner) RunTransactionProcessorsOnLedger(ledger uint32) (io.StatsLedgerTransactionProcessorResults, error) {
 	ledgerTransactionStats := io.StatsLedgerTransactionProcessor{}
 
+	// partitions are created outside of the ingestion transaction so that
+	// the partitioned tables are not locked until it's committed
+	err := s.historyQ.CloneIngestionQ().CreateHistoryPartitions(ledger)
+	if err != nil {
+		return ledgerTransactionStats.GetResults(), errors.Wrap(err, "Error creating history partitions")
+	}
+
 	transactionReader, err := io.NewLedgerTransactionReader(s.ledgerBackend, s.config.NetworkPassphrase, ledger)
 	if err != nil {
 		return ledgerTransactionStats.GetResults(), errors.Wrap(err, "Error creating ledger reader")
@@ -9,8 +9,10 @@ import (
 	"github.com/stellar/go/exp/ingest/io"
 	"github.com/stellar/go/exp/ingest/ledgerbackend"
 	"github.com/stellar/go/network"
+	"github.com/stellar/go/services/horizon/ingest"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/expingest/processors"
+	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/xdr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -237,6 +239,10 @@ func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
 		Return(&history.MockOperationsBatchInsertBuilder{}).Twice() // Twice = with/without failed
 	q.MockQTransactions.On("NewTransactionBatchInsertBuilder", maxBatchSize).
 		Return(&history.MockTransactionsBatchInsertBuilder{}).Twice()
+	q.MockQAccountEvents.On("NewAccountEventsBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountEventsBatchInsertBuilder{}).Twice()
+	q.MockQAccountThresholds.On("NewAccountThresholdsBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountThresholdsBatchInsertBuilder{}).Twice()
 
 	runner := ProcessorRunner{
 		config:   Config{},
@@ -255,6 +261,197 @@ func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
 	assert.IsType(t, &processors.TradeProcessor{}, processor.(groupTransactionProcessors)[4])
 	assert.IsType(t, &processors.ParticipantsProcessor{}, processor.(groupTransactionProcessors)[5])
 	assert.IsType(t, &processors.TransactionProcessor{}, processor.(groupTransactionProcessors)[6])
+	assert.IsType(t, &processors.HistoryOffersProcessor{}, processor.(groupTransactionProcessors)[7])
+	assert.IsType(t, &processors.AccountEventsProcessor{}, processor.(groupTransactionProcessors)[8])
+	assert.IsType(t, &processors.AccountThresholdsProcessor{}, processor.(groupTransactionProcessors)[9])
+	assert.IsType(t, &processors.LedgerManifestProcessor{}, processor.(groupTransactionProcessors)[10])
+	assert.Len(t, processor.(groupTransactionProcessors), 11)
+
+	runner.config.DataQualityRules, _ = processors.ParseDataQualityRules("all")
+	processor = runner.buildTransactionProcessor(stats, ledger)
+	assert.IsType(t, &processors.DataQualityProcessor{}, processor.(groupTransactionProcessors)[11])
+}
+
+func TestProcessorRunnerBuildFilteredTransactionProcessor(t *testing.T) {
+	maxBatchSize := 100000
+
+	q := &mockDBQ{}
+	defer mock.AssertExpectationsForObjects(t, q)
+
+	q.MockQOperations.On("NewOperationBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockOperationsBatchInsertBuilder{}).Twice() // Twice = with/without failed
+	q.MockQTransactions.On("NewTransactionBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockTransactionsBatchInsertBuilder{}).Twice()
+	q.MockQAccountEvents.On("NewAccountEventsBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountEventsBatchInsertBuilder{}).Twice()
+	q.MockQAccountThresholds.On("NewAccountThresholdsBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountThresholdsBatchInsertBuilder{}).Twice()
+
+	filter, err := processors.ParseTransactionFilter("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY", "")
+	assert.NoError(t, err)
+	runner := ProcessorRunner{
+		config:   Config{TransactionFilter: filter},
+		historyQ: q,
+	}
+
+	stats := &io.StatsLedgerTransactionProcessor{}
+	ledger := xdr.LedgerHeaderHistoryEntry{}
+	processor := runner.buildTransactionProcessor(stats, ledger)
+	assert.IsType(t, groupTransactionProcessors{}, processor)
+	assert.Len(t, processor.(groupTransactionProcessors), 3)
+
+	assert.IsType(t, &statsLedgerTransactionProcessor{}, processor.(groupTransactionProcessors)[0])
+	assert.IsType(t, &processors.LedgersProcessor{}, processor.(groupTransactionProcessors)[1])
+	assert.IsType(t, filteredTransactionProcessor{}, processor.(groupTransactionProcessors)[2])
+
+	filtered := processor.(groupTransactionProcessors)[2].(filteredTransactionProcessor)
+	assert.Equal(t, filter, filtered.filter)
+	assert.IsType(t, groupTransactionProcessors{}, filtered.processor)
+	history := filtered.processor.(groupTransactionProcessors)
+	assert.Len(t, history, 9)
+	assert.IsType(t, &processors.EffectProcessor{}, history[0])
+	assert.IsType(t, &processors.OperationProcessor{}, history[1])
+	assert.IsType(t, &processors.AccountThresholdsProcessor{}, history[7])
+	// the manifest covers the kept transactions
+	assert.IsType(t, &processors.LedgerManifestProcessor{}, history[8])
+}
+
+func TestProcessorRunnerBuildHistoryArchiveTransactionProcessor(t *testing.T) {
+	maxBatchSize := 100000
+
+	q := &mockDBQ{}
+	defer mock.AssertExpectationsForObjects(t, q)
+
+	q.MockQOperations.On("NewOperationBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockOperationsBatchInsertBuilder{}).Once()
+	q.MockQTransactions.On("NewTransactionBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockTransactionsBatchInsertBuilder{}).Once()
+	q.MockQAccountEvents.On("NewAccountEventsBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountEventsBatchInsertBuilder{}).Once()
+	q.MockQAccountThresholds.On("NewAccountThresholdsBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountThresholdsBatchInsertBuilder{}).Once()
+
+	rules, err := processors.ParseDataQualityRules("all")
+	assert.NoError(t, err)
+	runner := ProcessorRunner{
+		config:   Config{IngestFromHistoryArchive: true, DataQualityRules: rules},
+		historyQ: q,
+	}
+
+	stats := &io.StatsLedgerTransactionProcessor{}
+	ledger := xdr.LedgerHeaderHistoryEntry{}
+	processor := runner.buildTransactionProcessor(stats, ledger)
+	assert.IsType(t, groupTransactionProcessors{}, processor)
+
+	// the processors which need the transaction meta are not run
+	assert.IsType(t, &statsLedgerTransactionProcessor{}, processor.(groupTransactionProcessors)[0])
+	assert.IsType(t, &processors.LedgersProcessor{}, processor.(groupTransactionProcessors)[1])
+	assert.IsType(t, &processors.OperationProcessor{}, processor.(groupTransactionProcessors)[2])
+	assert.IsType(t, &processors.ParticipantsProcessor{}, processor.(groupTransactionProcessors)[3])
+	assert.IsType(t, &processors.TransactionProcessor{}, processor.(groupTransactionProcessors)[4])
+	assert.IsType(t, &processors.AccountEventsProcessor{}, processor.(groupTransactionProcessors)[5])
+	assert.IsType(t, &processors.LedgerManifestProcessor{}, processor.(groupTransactionProcessors)[6])
+	assert.Len(t, processor.(groupTransactionProcessors), 7)
+}
+
+func TestProcessorRunnerBuildProfileTransactionProcessor(t *testing.T) {
+	maxBatchSize := 100000
+
+	q := &mockDBQ{}
+	defer mock.AssertExpectationsForObjects(t, q)
+
+	q.MockQOperations.On("NewOperationBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockOperationsBatchInsertBuilder{}).Once()
+	q.MockQTransactions.On("NewTransactionBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockTransactionsBatchInsertBuilder{}).Once()
+	q.MockQAccountEvents.On("NewAccountEventsBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountEventsBatchInsertBuilder{}).Once()
+	q.MockQAccountThresholds.On("NewAccountThresholdsBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountThresholdsBatchInsertBuilder{}).Once()
+
+	profile, err := processors.ParseIngestionProfile("payments-only")
+	assert.NoError(t, err)
+	runner := ProcessorRunner{
+		config:   Config{IngestionProfile: profile},
+		historyQ: q,
+	}
+
+	stats := &io.StatsLedgerTransactionProcessor{}
+	ledger := xdr.LedgerHeaderHistoryEntry{}
+	processor := runner.buildTransactionProcessor(stats, ledger)
+	assert.IsType(t, groupTransactionProcessors{}, processor)
+
+	// the stats and the ledgers are always ingested
+	assert.IsType(t, &statsLedgerTransactionProcessor{}, processor.(groupTransactionProcessors)[0])
+	assert.IsType(t, &processors.LedgersProcessor{}, processor.(groupTransactionProcessors)[1])
+	assert.IsType(t, &processors.OperationProcessor{}, processor.(groupTransactionProcessors)[2])
+	assert.IsType(t, &processors.ParticipantsProcessor{}, processor.(groupTransactionProcessors)[3])
+	assert.IsType(t, &processors.TransactionProcessor{}, processor.(groupTransactionProcessors)[4])
+	assert.IsType(t, &processors.LedgerManifestProcessor{}, processor.(groupTransactionProcessors)[5])
+	assert.Len(t, processor.(groupTransactionProcessors), 6)
+}
+
+func TestProcessorRunnerBuildPluginProcessors(t *testing.T) {
+	maxBatchSize := 100000
+
+	q := &mockDBQ{}
+	defer mock.AssertExpectationsForObjects(t, q)
+
+	q.MockQOffers.On("NewOffersBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockOffersBatchInsertBuilder{}).Once()
+	q.MockQData.On("NewAccountDataBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountDataBatchInsertBuilder{}).Once()
+	q.MockQSigners.On("NewAccountSignersBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountSignersBatchInsertBuilder{}).Once()
+	q.MockQOperations.On("NewOperationBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockOperationsBatchInsertBuilder{}).Twice()
+	q.MockQTransactions.On("NewTransactionBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockTransactionsBatchInsertBuilder{}).Twice()
+	q.MockQAccountEvents.On("NewAccountEventsBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountEventsBatchInsertBuilder{}).Twice()
+	q.MockQAccountThresholds.On("NewAccountThresholdsBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountThresholdsBatchInsertBuilder{}).Twice()
+
+	changeProcessor := &mockHorizonChangeProcessor{}
+	transactionProcessor := &mockHorizonTransactionProcessor{}
+	filter, err := processors.ParseTransactionFilter("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY", "")
+	assert.NoError(t, err)
+	runner := ProcessorRunner{
+		config: Config{
+			TransactionFilter: filter,
+			Plugins: []ingest.Plugin{
+				{
+					Name: "changes",
+					NewChangeProcessor: func(sequence uint32, session *db.Session) ingest.ChangeProcessor {
+						assert.Equal(t, uint32(123), sequence)
+						assert.Nil(t, session)
+						return changeProcessor
+					},
+				},
+				{
+					Name: "transactions",
+					NewTransactionProcessor: func(ledger xdr.LedgerHeaderHistoryEntry, session *db.Session) ingest.TransactionProcessor {
+						assert.Equal(t, xdr.Uint32(123), ledger.Header.LedgerSeq)
+						return transactionProcessor
+					},
+				},
+			},
+		},
+		historyQ: q,
+	}
+
+	changes := runner.buildChangeProcessor(&io.StatsChangeProcessor{}, ledgerSource, 123).(groupChangeProcessors)
+	assert.Len(t, changes, 8)
+	assert.Equal(t, "changes", processorType(changes[7]))
+	assert.Equal(t, changeProcessor, changes[7].(*pluginChangeProcessor).ChangeProcessor)
+
+	ledger := xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: 123}}
+	transactions := runner.buildTransactionProcessor(&io.StatsLedgerTransactionProcessor{}, ledger).(groupTransactionProcessors)
+	// plugins are not filtered
+	assert.Len(t, transactions, 4)
+	assert.IsType(t, filteredTransactionProcessor{}, transactions[2])
+	assert.Equal(t, "transactions", processorType(transactions[3]))
+	assert.Equal(t, transactionProcessor, transactions[3].(*pluginTransactionProcessor).TransactionProcessor)
 }
 
 func TestProcessorRunnerRunAllProcessorsOnLedger(t *testing.T) {
@@ -316,8 +513,25 @@ func TestProcessorRunnerRunAllProcessorsOnLedger(t *testing.T) {
 	q.MockQTransactions.On("NewTransactionBatchInsertBuilder", maxBatchSize).
 		Return(mockTransactionsBatchInsertBuilder).Twice()
 
+	mockAccountEventsBatchInsertBuilder := &history.MockAccountEventsBatchInsertBuilder{}
+	defer mock.AssertExpectationsForObjects(t, mockAccountEventsBatchInsertBuilder)
+	mockAccountEventsBatchInsertBuilder.On("Exec").Return(nil).Once()
+	q.MockQAccountEvents.On("NewAccountEventsBatchInsertBuilder", maxBatchSize).
+		Return(mockAccountEventsBatchInsertBuilder).Twice()
+
+	mockAccountThresholdsBatchInsertBuilder := &history.MockAccountThresholdsBatchInsertBuilder{}
+	defer mock.AssertExpectationsForObjects(t, mockAccountThresholdsBatchInsertBuilder)
+	mockAccountThresholdsBatchInsertBuilder.On("Exec").Return(nil).Once()
+	q.MockQAccountThresholds.On("NewAccountThresholdsBatchInsertBuilder", maxBatchSize).
+		Return(mockAccountThresholdsBatchInsertBuilder).Twice()
+
 	q.MockQLedgers.On("InsertLedger", ledger, 0, 0, 0, 0, CurrentVersion).
 		Return(int64(1), nil).Once()
+	q.MockQLedgerManifests.On("InsertLedgerManifest", mock.AnythingOfType("history.LedgerManifest")).Return(nil).Once()
+	partitionsQ := &mockDBQ{}
+	defer mock.AssertExpectationsForObjects(t, partitionsQ)
+	partitionsQ.On("CreateHistoryPartitions", uint32(63)).Return(nil).Once()
+	q.On("CloneIngestionQ").Return(partitionsQ).Once()
 
 	runner := ProcessorRunner{
 		ctx:           context.Background(),
@@ -0,0 +1,76 @@
+package processors
+
+import (
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountEventsProcessor records account lifecycle events (creations and
+// merges) of successful transactions in the history_account_events table.
+type AccountEventsProcessor struct {
+	accountEventsQ history.QAccountEvents
+
+	sequence uint32
+	batch    history.AccountEventsBatchInsertBuilder
+}
+
+func NewAccountEventsProcessor(accountEventsQ history.QAccountEvents, sequence uint32) *AccountEventsProcessor {
+	return &AccountEventsProcessor{
+		accountEventsQ: accountEventsQ,
+		sequence:       sequence,
+		batch:          accountEventsQ.NewAccountEventsBatchInsertBuilder(maxBatchSize),
+	}
+}
+
+// ProcessTransaction process the given transaction
+func (p *AccountEventsProcessor) ProcessTransaction(transaction io.LedgerTransaction) error {
+	if !transaction.Result.Successful() {
+		return nil
+	}
+
+	for i, op := range transaction.Envelope.Operations() {
+		operation := transactionOperationWrapper{
+			index:          uint32(i),
+			transaction:    transaction,
+			operation:      op,
+			ledgerSequence: p.sequence,
+		}
+
+		var err error
+		switch operation.OperationType() {
+		case xdr.OperationTypeCreateAccount:
+			createOp := op.Body.MustCreateAccountOp()
+			err = p.batch.Add(
+				operation.ID(),
+				history.AccountEventCreated,
+				createOp.Destination.Address(),
+				operation.SourceAccount().Address(),
+				createOp.StartingBalance,
+			)
+		case xdr.OperationTypeAccountMerge:
+			destination := op.Body.MustDestination().ToAccountId()
+			result := operation.OperationResult().MustAccountMergeResult()
+			err = p.batch.Add(
+				operation.ID(),
+				history.AccountEventMerged,
+				operation.SourceAccount().Address(),
+				destination.Address(),
+				result.MustSourceAccountBalance(),
+			)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "Error batch inserting account event rows")
+		}
+	}
+
+	return nil
+}
+
+func (p *AccountEventsProcessor) Commit() error {
+	return p.batch.Exec()
+}
@@ -0,0 +1,143 @@
+//lint:file-ignore U1001 Ignore all unused code, staticcheck doesn't understand testify/suite
+package processors
+
+import (
+	"testing"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/suite"
+)
+
+type AccountEventsProcessorTestSuiteLedger struct {
+	suite.Suite
+	processor              *AccountEventsProcessor
+	mockQ                  *history.MockQAccountEvents
+	mockBatchInsertBuilder *history.MockAccountEventsBatchInsertBuilder
+	sequence               uint32
+}
+
+func TestAccountEventsProcessorTestSuiteLedger(t *testing.T) {
+	suite.Run(t, new(AccountEventsProcessorTestSuiteLedger))
+}
+
+func (s *AccountEventsProcessorTestSuiteLedger) SetupTest() {
+	s.mockQ = &history.MockQAccountEvents{}
+	s.mockBatchInsertBuilder = &history.MockAccountEventsBatchInsertBuilder{}
+	s.sequence = 20
+
+	s.mockQ.
+		On("NewAccountEventsBatchInsertBuilder", maxBatchSize).
+		Return(s.mockBatchInsertBuilder).Once()
+
+	s.processor = NewAccountEventsProcessor(s.mockQ, s.sequence)
+}
+
+func (s *AccountEventsProcessorTestSuiteLedger) TearDownTest() {
+	s.mockQ.AssertExpectations(s.T())
+	s.mockBatchInsertBuilder.AssertExpectations(s.T())
+}
+
+func (s *AccountEventsProcessorTestSuiteLedger) transaction(code xdr.TransactionResultCode) io.LedgerTransaction {
+	funder := xdr.MustAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	created := xdr.MustAddress("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML")
+	merged := xdr.MustMuxedAddress("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+	balance := xdr.Int64(40000000)
+
+	return io.LedgerTransaction{
+		Index: 1,
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code: code,
+					Results: &[]xdr.OperationResult{
+						{
+							Tr: &xdr.OperationResultTr{
+								Type:                xdr.OperationTypeCreateAccount,
+								CreateAccountResult: &xdr.CreateAccountResult{},
+							},
+						},
+						{
+							Tr: &xdr.OperationResultTr{
+								Type:          xdr.OperationTypeBumpSequence,
+								BumpSeqResult: &xdr.BumpSequenceResult{},
+							},
+						},
+						{
+							Tr: &xdr.OperationResultTr{
+								Type: xdr.OperationTypeAccountMerge,
+								AccountMergeResult: &xdr.AccountMergeResult{
+									SourceAccountBalance: &balance,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: funder.ToMuxedAccount(),
+					Operations: []xdr.Operation{
+						{
+							Body: xdr.OperationBody{
+								Type: xdr.OperationTypeCreateAccount,
+								CreateAccountOp: &xdr.CreateAccountOp{
+									Destination:     created,
+									StartingBalance: 50000000,
+								},
+							},
+						},
+						{
+							Body: xdr.OperationBody{
+								Type:           xdr.OperationTypeBumpSequence,
+								BumpSequenceOp: &xdr.BumpSequenceOp{BumpTo: 30000},
+							},
+						},
+						{
+							SourceAccount: &merged,
+							Body: xdr.OperationBody{
+								Type:        xdr.OperationTypeAccountMerge,
+								Destination: &xdr.MuxedAccount{Type: xdr.CryptoKeyTypeKeyTypeEd25519, Ed25519: funder.Ed25519},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *AccountEventsProcessorTestSuiteLedger) TestAddsEvents() {
+	s.mockBatchInsertBuilder.On(
+		"Add",
+		toid.New(20, 1, 1).ToInt64(),
+		history.AccountEventCreated,
+		"GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML",
+		"GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+		xdr.Int64(50000000),
+	).Return(nil).Once()
+	s.mockBatchInsertBuilder.On(
+		"Add",
+		toid.New(20, 1, 3).ToInt64(),
+		history.AccountEventMerged,
+		"GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+		"GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+		xdr.Int64(40000000),
+	).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Exec").Return(nil).Once()
+
+	s.Assert().NoError(s.processor.ProcessTransaction(s.transaction(xdr.TransactionResultCodeTxSuccess)))
+	s.Assert().NoError(s.processor.Commit())
+}
+
+func (s *AccountEventsProcessorTestSuiteLedger) TestIgnoresFailedTransactions() {
+	s.mockBatchInsertBuilder.On("Exec").Return(nil).Once()
+
+	s.Assert().NoError(s.processor.ProcessTransaction(s.transaction(xdr.TransactionResultCodeTxFailed)))
+	s.Assert().NoError(s.processor.Commit())
+}
@@ -0,0 +1,76 @@
+package processors
+
+import (
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountThresholdsProcessor records the master weight and thresholds of
+// accounts changed by set_options operations of successful transactions in
+// the history_account_thresholds table.
+type AccountThresholdsProcessor struct {
+	accountThresholdsQ history.QAccountThresholds
+
+	sequence uint32
+	batch    history.AccountThresholdsBatchInsertBuilder
+}
+
+func NewAccountThresholdsProcessor(accountThresholdsQ history.QAccountThresholds, sequence uint32) *AccountThresholdsProcessor {
+	return &AccountThresholdsProcessor{
+		accountThresholdsQ: accountThresholdsQ,
+		sequence:           sequence,
+		batch:              accountThresholdsQ.NewAccountThresholdsBatchInsertBuilder(maxBatchSize),
+	}
+}
+
+// ProcessTransaction process the given transaction
+func (p *AccountThresholdsProcessor) ProcessTransaction(transaction io.LedgerTransaction) error {
+	if !transaction.Result.Successful() {
+		return nil
+	}
+
+	for i, op := range transaction.Envelope.Operations() {
+		if op.Body.Type != xdr.OperationTypeSetOptions {
+			continue
+		}
+
+		operation := transactionOperationWrapper{
+			index:          uint32(i),
+			transaction:    transaction,
+			operation:      op,
+			ledgerSequence: p.sequence,
+		}
+
+		changes, err := transaction.GetOperationChanges(uint32(i))
+		if err != nil {
+			return errors.Wrap(err, "Error reading operation changes")
+		}
+
+		for _, change := range changes {
+			if change.Type != xdr.LedgerEntryTypeAccount || change.Pre == nil || change.Post == nil {
+				continue
+			}
+
+			before := change.Pre.Data.MustAccount()
+			after := change.Post.Data.MustAccount()
+			// set_options operations which only change other options
+			// (ex. the home domain) are not recorded
+			if before.Thresholds == after.Thresholds {
+				continue
+			}
+
+			err = p.batch.Add(operation.ID(), after.AccountId.Address(), after.Thresholds)
+			if err != nil {
+				return errors.Wrap(err, "Error batch inserting account thresholds rows")
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *AccountThresholdsProcessor) Commit() error {
+	return p.batch.Exec()
+}
@@ -0,0 +1,152 @@
+//lint:file-ignore U1001 Ignore all unused code, staticcheck doesn't understand testify/suite
+package processors
+
+import (
+	"testing"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/suite"
+)
+
+type AccountThresholdsProcessorTestSuiteLedger struct {
+	suite.Suite
+	processor              *AccountThresholdsProcessor
+	mockQ                  *history.MockQAccountThresholds
+	mockBatchInsertBuilder *history.MockAccountThresholdsBatchInsertBuilder
+	sequence               uint32
+}
+
+func TestAccountThresholdsProcessorTestSuiteLedger(t *testing.T) {
+	suite.Run(t, new(AccountThresholdsProcessorTestSuiteLedger))
+}
+
+func (s *AccountThresholdsProcessorTestSuiteLedger) SetupTest() {
+	s.mockQ = &history.MockQAccountThresholds{}
+	s.mockBatchInsertBuilder = &history.MockAccountThresholdsBatchInsertBuilder{}
+	s.sequence = 20
+
+	s.mockQ.
+		On("NewAccountThresholdsBatchInsertBuilder", maxBatchSize).
+		Return(s.mockBatchInsertBuilder).Once()
+
+	s.processor = NewAccountThresholdsProcessor(s.mockQ, s.sequence)
+}
+
+func (s *AccountThresholdsProcessorTestSuiteLedger) TearDownTest() {
+	s.mockQ.AssertExpectations(s.T())
+	s.mockBatchInsertBuilder.AssertExpectations(s.T())
+}
+
+func accountThresholdsChange(account xdr.AccountId, before, after xdr.Thresholds) []xdr.LedgerEntryChange {
+	return []xdr.LedgerEntryChange{
+		{
+			Type: xdr.LedgerEntryChangeTypeLedgerEntryState,
+			State: &xdr.LedgerEntry{
+				Data: xdr.LedgerEntryData{
+					Type: xdr.LedgerEntryTypeAccount,
+					Account: &xdr.AccountEntry{
+						AccountId:  account,
+						Thresholds: before,
+					},
+				},
+			},
+		},
+		{
+			Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+			Updated: &xdr.LedgerEntry{
+				Data: xdr.LedgerEntryData{
+					Type: xdr.LedgerEntryTypeAccount,
+					Account: &xdr.AccountEntry{
+						AccountId:  account,
+						Thresholds: after,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *AccountThresholdsProcessorTestSuiteLedger) transaction(code xdr.TransactionResultCode) io.LedgerTransaction {
+	source := xdr.MustAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	other := xdr.MustAddress("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML")
+	otherMuxed := other.ToMuxedAccount()
+	masterWeight := xdr.Uint32(0)
+	threshold := xdr.Uint32(2)
+	homeDomain := xdr.String32("example.com")
+
+	return io.LedgerTransaction{
+		Index: 1,
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code:    code,
+					Results: &[]xdr.OperationResult{},
+				},
+			},
+		},
+		Meta: createTransactionMeta([]xdr.OperationMeta{
+			{Changes: accountThresholdsChange(source, xdr.Thresholds{1, 0, 0, 0}, xdr.Thresholds{0, 2, 2, 2})},
+			{},
+			{Changes: accountThresholdsChange(other, xdr.Thresholds{1, 0, 0, 0}, xdr.Thresholds{1, 0, 0, 0})},
+		}),
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: source.ToMuxedAccount(),
+					Operations: []xdr.Operation{
+						{
+							Body: xdr.OperationBody{
+								Type: xdr.OperationTypeSetOptions,
+								SetOptionsOp: &xdr.SetOptionsOp{
+									MasterWeight:  &masterWeight,
+									LowThreshold:  &threshold,
+									MedThreshold:  &threshold,
+									HighThreshold: &threshold,
+								},
+							},
+						},
+						{
+							Body: xdr.OperationBody{
+								Type:           xdr.OperationTypeBumpSequence,
+								BumpSequenceOp: &xdr.BumpSequenceOp{BumpTo: 30000},
+							},
+						},
+						{
+							SourceAccount: &otherMuxed,
+							Body: xdr.OperationBody{
+								Type: xdr.OperationTypeSetOptions,
+								SetOptionsOp: &xdr.SetOptionsOp{
+									HomeDomain: &homeDomain,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *AccountThresholdsProcessorTestSuiteLedger) TestAddsChangedThresholds() {
+	s.mockBatchInsertBuilder.On(
+		"Add",
+		toid.New(20, 1, 1).ToInt64(),
+		"GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+		xdr.Thresholds{0, 2, 2, 2},
+	).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Exec").Return(nil).Once()
+
+	s.Assert().NoError(s.processor.ProcessTransaction(s.transaction(xdr.TransactionResultCodeTxSuccess)))
+	s.Assert().NoError(s.processor.Commit())
+}
+
+func (s *AccountThresholdsProcessorTestSuiteLedger) TestIgnoresFailedTransactions() {
+	s.mockBatchInsertBuilder.On("Exec").Return(nil).Once()
+
+	s.Assert().NoError(s.processor.ProcessTransaction(s.transaction(xdr.TransactionResultCodeTxFailed)))
+	s.Assert().NoError(s.processor.Commit())
+}
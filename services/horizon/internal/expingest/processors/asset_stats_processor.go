@@ -3,6 +3,7 @@ package processors
 import (
 	"database/sql"
 	"math/big"
+	"sort"
 
 	ingesterrors "github.com/stellar/go/exp/ingest/errors"
 	"github.com/stellar/go/exp/ingest/io"
@@ -17,6 +18,10 @@ type AssetStatsProcessor struct {
 	cache               *io.LedgerEntryChangeCache
 	assetStatSet        AssetStatSet
 	useLedgerEntryCache bool
+	// issuers are the issuers whose flags and home domain must be copied to
+	// the stats of their assets on Commit: the issuers of new asset stats
+	// and the issuer accounts changed by the ledger.
+	issuers map[string]bool
 }
 
 // NewAssetStatsProcessor constructs a new AssetStatsProcessor instance.
@@ -31,6 +36,7 @@ func NewAssetStatsProcessor(
 	p := &AssetStatsProcessor{
 		assetStatsQ:         assetStatsQ,
 		useLedgerEntryCache: useLedgerEntryCache,
+		issuers:             map[string]bool{},
 	}
 	p.reset()
 	return p
@@ -42,6 +48,19 @@ func (p *AssetStatsProcessor) reset() {
 }
 
 func (p *AssetStatsProcessor) ProcessChange(change io.Change) error {
+	if change.Type == xdr.LedgerEntryTypeAccount {
+		// accounts created during state ingestion are ignored, the issuers of
+		// all the asset stats are updated on Commit
+		if p.useLedgerEntryCache && issuerFieldsChanged(change) {
+			entry := change.Post
+			if entry == nil {
+				entry = change.Pre
+			}
+			account := entry.Data.MustAccount()
+			p.issuers[account.AccountId.Address()] = true
+		}
+		return nil
+	}
 	if change.Type != xdr.LedgerEntryTypeTrustline {
 		return nil
 	}
@@ -53,9 +72,9 @@ func (p *AssetStatsProcessor) ProcessChange(change io.Change) error {
 		}
 
 		if p.cache.Size() > maxBatchSize {
-			err = p.Commit()
+			err = p.commitAssetStats()
 			if err != nil {
-				return errors.Wrap(err, "error in Commit")
+				return errors.Wrap(err, "error in commitAssetStats")
 			}
 			p.reset()
 		}
@@ -76,8 +95,43 @@ func (p *AssetStatsProcessor) ProcessChange(change io.Change) error {
 }
 
 func (p *AssetStatsProcessor) Commit() error {
+	if err := p.commitAssetStats(); err != nil {
+		return err
+	}
+
+	issuers := make([]string, 0, len(p.issuers))
+	for issuer := range p.issuers {
+		issuers = append(issuers, issuer)
+	}
+	sort.Strings(issuers)
+	p.issuers = map[string]bool{}
+
+	if len(issuers) == 0 {
+		return nil
+	}
+	if _, err := p.assetStatsQ.UpdateAssetStatsIssuers(issuers); err != nil {
+		return errors.Wrap(err, "could not update asset stats issuers")
+	}
+	return nil
+}
+
+// issuerFieldsChanged returns true if the account change can change the
+// issuer fields of the stats of its assets.
+func issuerFieldsChanged(change io.Change) bool {
+	if change.Pre == nil || change.Post == nil {
+		return true
+	}
+	pre, post := change.Pre.Data.MustAccount(), change.Post.Data.MustAccount()
+	return pre.Flags != post.Flags || pre.HomeDomain != post.HomeDomain
+}
+
+func (p *AssetStatsProcessor) commitAssetStats() error {
 	if !p.useLedgerEntryCache {
-		return p.assetStatsQ.InsertAssetStats(p.assetStatSet.All(), maxBatchSize)
+		assetStats := p.assetStatSet.All()
+		for _, assetStat := range assetStats {
+			p.issuers[assetStat.AssetIssuer] = true
+		}
+		return p.assetStatsQ.InsertAssetStats(assetStats, maxBatchSize)
 	}
 
 	changes := p.cache.GetChanges()
@@ -137,6 +191,7 @@ func (p *AssetStatsProcessor) Commit() error {
 			if errInsert != nil {
 				return errors.Wrap(errInsert, "could not insert asset stat")
 			}
+			p.issuers[delta.AssetIssuer] = true
 		} else {
 			statBalance, ok := new(big.Int).SetString(stat.Amount, 10)
 			if !ok {
@@ -178,6 +233,8 @@ func (p *AssetStatsProcessor) Commit() error {
 					AssetIssuer: delta.AssetIssuer,
 					Amount:      statBalance.String(),
 					NumAccounts: statAccounts,
+					Flags:       stat.Flags,
+					HomeDomain:  stat.HomeDomain,
 				})
 				if err != nil {
 					return errors.Wrap(err, "could not update asset stat")
@@ -61,6 +61,7 @@ func (s *AssetStatsProcessorTestSuiteState) TestCreateTrustLine() {
 			NumAccounts: 1,
 		},
 	}, maxBatchSize).Return(nil).Once()
+	s.mockQ.On("UpdateAssetStatsIssuers", []string{trustLineIssuer.Address()}).Return(int64(1), nil).Once()
 }
 
 func (s *AssetStatsProcessorTestSuiteState) TestCreateTrustLineUnauthorized() {
@@ -107,7 +108,8 @@ func (s *AssetStatsProcessorTestSuiteLedger) TearDownTest() {
 }
 
 func (s *AssetStatsProcessorTestSuiteLedger) TestInsertTrustLine() {
-	// should be ignored because it's not an trust line type
+	// the created account is an issuer whose flags must be copied to the
+	// stats of its assets, if any
 	err := s.processor.ProcessChange(io.Change{
 		Type: xdr.LedgerEntryTypeAccount,
 		Pre:  nil,
@@ -226,6 +228,10 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestInsertTrustLine() {
 		NumAccounts: 1,
 	}).Return(int64(1), nil).Once()
 
+	s.mockQ.On("UpdateAssetStatsIssuers", []string{
+		trustLineIssuer.Address(),
+		"GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML",
+	}).Return(int64(1), nil).Once()
 	s.Assert().NoError(s.processor.Commit())
 }
 
@@ -380,6 +386,7 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestUpdateTrustLineAuthorization()
 		"USD",
 		trustLineIssuer.Address(),
 	).Return(int64(1), nil).Once()
+	s.mockQ.On("UpdateAssetStatsIssuers", []string{trustLineIssuer.Address()}).Return(int64(1), nil).Once()
 	s.Assert().NoError(s.processor.Commit())
 }
 
@@ -498,5 +505,42 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestProcessUpgradeChange() {
 		Amount:      "10",
 		NumAccounts: 1,
 	}).Return(int64(1), nil).Once()
+	s.mockQ.On("UpdateAssetStatsIssuers", []string{trustLineIssuer.Address()}).Return(int64(1), nil).Once()
+	s.Assert().NoError(s.processor.Commit())
+}
+
+func (s *AssetStatsProcessorTestSuiteLedger) TestUpdateIssuerAccount() {
+	issuer := xdr.AccountEntry{
+		AccountId:  trustLineIssuer,
+		Thresholds: [4]byte{1, 1, 1, 1},
+	}
+	updatedIssuer := issuer
+	updatedIssuer.Flags = xdr.Uint32(xdr.AccountFlagsAuthRequiredFlag)
+	otherAccount := xdr.AccountEntry{
+		AccountId:  xdr.MustAddress("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"),
+		Thresholds: [4]byte{1, 1, 1, 1},
+	}
+	updatedOtherAccount := otherAccount
+	updatedOtherAccount.Balance = 100
+
+	for _, accounts := range [][2]xdr.AccountEntry{
+		{issuer, updatedIssuer},
+		// the flags and the home domain of the account don't change
+		{otherAccount, updatedOtherAccount},
+	} {
+		pre, post := accounts[0], accounts[1]
+		err := s.processor.ProcessChange(io.Change{
+			Type: xdr.LedgerEntryTypeAccount,
+			Pre: &xdr.LedgerEntry{
+				Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeAccount, Account: &pre},
+			},
+			Post: &xdr.LedgerEntry{
+				Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeAccount, Account: &post},
+			},
+		})
+		s.Assert().NoError(err)
+	}
+
+	s.mockQ.On("UpdateAssetStatsIssuers", []string{trustLineIssuer.Address()}).Return(int64(2), nil).Once()
 	s.Assert().NoError(s.processor.Commit())
 }
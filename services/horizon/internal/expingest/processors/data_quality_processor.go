@@ -0,0 +1,269 @@
+package processors
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// DataQualityRule is a check run on every ingested transaction. Check returns
+// a description of each violation of the rule found in the transaction. Rules
+// must not panic on malformed data, detecting it is their purpose.
+type DataQualityRule struct {
+	Name        string
+	Description string
+	Check       func(transaction io.LedgerTransaction) []string
+}
+
+// DataQualityRules are the built-in data quality rules indexed by name.
+var DataQualityRules = map[string]DataQualityRule{
+	"operation_count": {
+		Name:        "operation_count",
+		Description: "the number of operation results and operation metas matches the number of operations in the envelope",
+		Check:       checkOperationCount,
+	},
+	"trade_amounts": {
+		Name:        "trade_amounts",
+		Description: "the sold and bought amounts of every trade are positive",
+		Check:       checkTradeAmounts,
+	},
+	"fee_charged": {
+		Name:        "fee_charged",
+		Description: "the fee charged is not negative and does not exceed the max fee of the envelope",
+		Check:       checkFeeCharged,
+	},
+}
+
+// ParseDataQualityRules returns the rules from a comma separated list of
+// rule names. "all" selects all the built-in rules and "none" (or an empty
+// string) disables data quality checks.
+func ParseDataQualityRules(names string) ([]DataQualityRule, error) {
+	names = strings.TrimSpace(names)
+	switch names {
+	case "", "none":
+		return nil, nil
+	case "all":
+		rules := make([]DataQualityRule, 0, len(DataQualityRules))
+		for _, rule := range DataQualityRules {
+			rules = append(rules, rule)
+		}
+		sort.Slice(rules, func(i, j int) bool {
+			return rules[i].Name < rules[j].Name
+		})
+		return rules, nil
+	}
+
+	var rules []DataQualityRule
+	seen := map[string]bool{}
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		rule, ok := DataQualityRules[name]
+		if !ok {
+			return nil, errors.Errorf("unknown data quality rule: %s", name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// DataQualityViolationsCounter counts the violations recorded by
+// DataQualityProcessor.
+type DataQualityViolationsCounter interface {
+	Add(rule string)
+}
+
+// DataQualityProcessor checks ingested transactions against data quality
+// rules. Violations are recorded in the history_data_quality_violations table
+// (and passed to the violations counter, when set) instead of failing
+// ingestion so that bugs in stellar-core or in the meta processing can be
+// detected without halting the node.
+type DataQualityProcessor struct {
+	violationsQ history.QDataQualityViolations
+	sequence    uint32
+	rules       []DataQualityRule
+	counter     DataQualityViolationsCounter
+
+	violations []history.DataQualityViolation
+}
+
+// NewDataQualityProcessor creates a DataQualityProcessor. counter is
+// optional.
+func NewDataQualityProcessor(
+	violationsQ history.QDataQualityViolations,
+	sequence uint32,
+	rules []DataQualityRule,
+	counter DataQualityViolationsCounter,
+) *DataQualityProcessor {
+	return &DataQualityProcessor{
+		violationsQ: violationsQ,
+		sequence:    sequence,
+		rules:       rules,
+		counter:     counter,
+	}
+}
+
+// ProcessTransaction process the given transaction
+func (p *DataQualityProcessor) ProcessTransaction(transaction io.LedgerTransaction) error {
+	for _, rule := range p.rules {
+		for _, details := range rule.Check(transaction) {
+			p.violations = append(p.violations, history.DataQualityViolation{
+				HistoryTransactionID: toid.New(int32(p.sequence), int32(transaction.Index), 0).ToInt64(),
+				TransactionHash:      hex.EncodeToString(transaction.Result.TransactionHash[:]),
+				Rule:                 rule.Name,
+				Details:              details,
+			})
+		}
+	}
+	return nil
+}
+
+func (p *DataQualityProcessor) Commit() error {
+	if len(p.violations) == 0 {
+		return nil
+	}
+
+	batch := p.violationsQ.NewDataQualityViolationsBatchInsertBuilder(maxBatchSize)
+	for _, violation := range p.violations {
+		if err := batch.Add(violation); err != nil {
+			return errors.Wrap(err, "Error adding data quality violation to batch")
+		}
+	}
+	if err := batch.Exec(); err != nil {
+		return errors.Wrap(err, "Error flushing data quality violations batch")
+	}
+
+	for _, violation := range p.violations {
+		log.WithField("ledger", p.sequence).
+			WithField("tx_hash", violation.TransactionHash).
+			WithField("rule", violation.Rule).
+			Warn("Data quality violation: " + violation.Details)
+		if p.counter != nil {
+			p.counter.Add(violation.Rule)
+		}
+	}
+	return nil
+}
+
+func checkOperationCount(transaction io.LedgerTransaction) []string {
+	var violations []string
+	operations := len(transaction.Envelope.Operations())
+
+	if results, ok := transaction.Result.Result.OperationResults(); ok && len(results) != operations {
+		violations = append(violations, fmt.Sprintf(
+			"%d operation results for %d operations", len(results), operations,
+		))
+	}
+
+	if transaction.Result.Successful() {
+		if metas, ok := operationMetas(transaction.Meta); ok && len(metas) != operations {
+			violations = append(violations, fmt.Sprintf(
+				"%d operation metas for %d operations", len(metas), operations,
+			))
+		}
+	}
+
+	return violations
+}
+
+func checkTradeAmounts(transaction io.LedgerTransaction) []string {
+	if !transaction.Result.Successful() {
+		return nil
+	}
+	results, ok := transaction.Result.Result.OperationResults()
+	if !ok {
+		return nil
+	}
+
+	var violations []string
+	for opidx, result := range results {
+		for _, trade := range claimedOffers(result) {
+			// garbage collected offers are emitted with zero amounts, see
+			// TradeProcessor
+			if trade.AmountSold == 0 && trade.AmountBought == 0 {
+				continue
+			}
+			if trade.AmountSold <= 0 || trade.AmountBought <= 0 {
+				violations = append(violations, fmt.Sprintf(
+					"operation %d: trade of offer %d has amount sold %d and amount bought %d",
+					opidx, trade.OfferId, trade.AmountSold, trade.AmountBought,
+				))
+			}
+		}
+	}
+	return violations
+}
+
+func checkFeeCharged(transaction io.LedgerTransaction) []string {
+	var maxFee int64
+	if transaction.Envelope.IsFeeBump() {
+		maxFee = transaction.Envelope.FeeBumpFee()
+	} else {
+		maxFee = int64(transaction.Envelope.Fee())
+	}
+
+	feeCharged := int64(transaction.Result.Result.FeeCharged)
+	if feeCharged < 0 || feeCharged > maxFee {
+		return []string{fmt.Sprintf("fee charged %d is not in [0, %d]", feeCharged, maxFee)}
+	}
+	return nil
+}
+
+// operationMetas returns the operation metas of any version of the
+// transaction meta.
+func operationMetas(meta xdr.TransactionMeta) ([]xdr.OperationMeta, bool) {
+	if operations, ok := meta.GetOperations(); ok {
+		return operations, true
+	}
+	if v1, ok := meta.GetV1(); ok {
+		return v1.Operations, true
+	}
+	if v2, ok := meta.GetV2(); ok {
+		return v2.Operations, true
+	}
+	return nil, false
+}
+
+// claimedOffers returns the offers claimed by a successful operation. The
+// result arm is used instead of the operation type so that malformed results
+// don't panic.
+func claimedOffers(result xdr.OperationResult) []xdr.ClaimOfferAtom {
+	tr, ok := result.GetTr()
+	if !ok {
+		return nil
+	}
+
+	switch tr.Type {
+	case xdr.OperationTypePathPaymentStrictReceive:
+		if success, ok := tr.MustPathPaymentStrictReceiveResult().GetSuccess(); ok {
+			return success.Offers
+		}
+	case xdr.OperationTypePathPaymentStrictSend:
+		if success, ok := tr.MustPathPaymentStrictSendResult().GetSuccess(); ok {
+			return success.Offers
+		}
+	case xdr.OperationTypeManageBuyOffer:
+		if success, ok := tr.MustManageBuyOfferResult().GetSuccess(); ok {
+			return success.OffersClaimed
+		}
+	case xdr.OperationTypeManageSellOffer:
+		if success, ok := tr.MustManageSellOfferResult().GetSuccess(); ok {
+			return success.OffersClaimed
+		}
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		if success, ok := tr.MustCreatePassiveSellOfferResult().GetSuccess(); ok {
+			return success.OffersClaimed
+		}
+	}
+	return nil
+}
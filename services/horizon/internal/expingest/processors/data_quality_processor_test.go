@@ -0,0 +1,193 @@
+//lint:file-ignore U1001 Ignore all unused code, staticcheck doesn't understand testify/suite
+package processors
+
+import (
+	"testing"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DataQualityProcessorTestSuiteLedger struct {
+	suite.Suite
+	processor              *DataQualityProcessor
+	mockQ                  *history.MockQDataQualityViolations
+	mockBatchInsertBuilder *history.MockDataQualityViolationsBatchInsertBuilder
+	counter                dataQualityViolationsCounter
+	sequence               uint32
+}
+
+type dataQualityViolationsCounter map[string]int
+
+func (c dataQualityViolationsCounter) Add(rule string) {
+	c[rule]++
+}
+
+func TestDataQualityProcessorTestSuiteLedger(t *testing.T) {
+	suite.Run(t, new(DataQualityProcessorTestSuiteLedger))
+}
+
+func (s *DataQualityProcessorTestSuiteLedger) SetupTest() {
+	s.mockQ = &history.MockQDataQualityViolations{}
+	s.mockBatchInsertBuilder = &history.MockDataQualityViolationsBatchInsertBuilder{}
+	s.counter = dataQualityViolationsCounter{}
+	s.sequence = 20
+
+	rules, err := ParseDataQualityRules("all")
+	s.Assert().NoError(err)
+	s.processor = NewDataQualityProcessor(s.mockQ, s.sequence, rules, s.counter)
+}
+
+func (s *DataQualityProcessorTestSuiteLedger) TearDownTest() {
+	s.mockQ.AssertExpectations(s.T())
+	s.mockBatchInsertBuilder.AssertExpectations(s.T())
+}
+
+func dataQualityTransaction(amountBought xdr.Int64, feeCharged xdr.Int64, metas int) io.LedgerTransaction {
+	seller := xdr.MustAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	source := xdr.MustAddress("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML")
+
+	return io.LedgerTransaction{
+		Index: 1,
+		Result: xdr.TransactionResultPair{
+			TransactionHash: xdr.Hash{0x01},
+			Result: xdr.TransactionResult{
+				FeeCharged: feeCharged,
+				Result: xdr.TransactionResultResult{
+					Code: xdr.TransactionResultCodeTxSuccess,
+					Results: &[]xdr.OperationResult{
+						{
+							Tr: &xdr.OperationResultTr{
+								Type: xdr.OperationTypeManageSellOffer,
+								ManageSellOfferResult: &xdr.ManageSellOfferResult{
+									Code: xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+									Success: &xdr.ManageOfferSuccessResult{
+										OffersClaimed: []xdr.ClaimOfferAtom{
+											{
+												SellerId:     seller,
+												OfferId:      3,
+												AssetSold:    xdr.MustNewNativeAsset(),
+												AmountSold:   100,
+												AssetBought:  xdr.MustNewCreditAsset("USD", seller.Address()),
+												AmountBought: amountBought,
+											},
+											// garbage collected offer
+											{
+												SellerId:    seller,
+												OfferId:     4,
+												AssetSold:   xdr.MustNewNativeAsset(),
+												AssetBought: xdr.MustNewCreditAsset("USD", seller.Address()),
+											},
+										},
+										Offer: xdr.ManageOfferSuccessResultOffer{
+											Effect: xdr.ManageOfferEffectManageOfferDeleted,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Meta: xdr.TransactionMeta{
+			V: 1,
+			V1: &xdr.TransactionMetaV1{
+				Operations: make([]xdr.OperationMeta, metas),
+			},
+		},
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: source.ToMuxedAccount(),
+					Fee:           100,
+					Operations: []xdr.Operation{
+						{
+							Body: xdr.OperationBody{
+								Type: xdr.OperationTypeManageSellOffer,
+								ManageSellOfferOp: &xdr.ManageSellOfferOp{
+									Selling: xdr.MustNewCreditAsset("USD", seller.Address()),
+									Buying:  xdr.MustNewNativeAsset(),
+									Amount:  100,
+									Price:   xdr.Price{N: 1, D: 1},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *DataQualityProcessorTestSuiteLedger) TestNoViolations() {
+	s.Assert().NoError(s.processor.ProcessTransaction(dataQualityTransaction(100, 100, 1)))
+	s.Assert().NoError(s.processor.Commit())
+	s.Assert().Empty(s.counter)
+}
+
+func (s *DataQualityProcessorTestSuiteLedger) TestRecordsViolations() {
+	id := toid.New(20, 1, 0).ToInt64()
+	hash := "0100000000000000000000000000000000000000000000000000000000000000"
+
+	s.mockQ.
+		On("NewDataQualityViolationsBatchInsertBuilder", maxBatchSize).
+		Return(s.mockBatchInsertBuilder).Once()
+	s.mockBatchInsertBuilder.On("Add", history.DataQualityViolation{
+		HistoryTransactionID: id,
+		TransactionHash:      hash,
+		Rule:                 "fee_charged",
+		Details:              "fee charged 200 is not in [0, 100]",
+	}).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", history.DataQualityViolation{
+		HistoryTransactionID: id,
+		TransactionHash:      hash,
+		Rule:                 "operation_count",
+		Details:              "2 operation metas for 1 operations",
+	}).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", history.DataQualityViolation{
+		HistoryTransactionID: id,
+		TransactionHash:      hash,
+		Rule:                 "trade_amounts",
+		Details:              "operation 0: trade of offer 3 has amount sold 100 and amount bought 0",
+	}).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Exec").Return(nil).Once()
+
+	s.Assert().NoError(s.processor.ProcessTransaction(dataQualityTransaction(0, 200, 2)))
+	s.Assert().NoError(s.processor.Commit())
+
+	s.Assert().Equal(dataQualityViolationsCounter{
+		"fee_charged":     1,
+		"operation_count": 1,
+		"trade_amounts":   1,
+	}, s.counter)
+}
+
+func TestParseDataQualityRules(t *testing.T) {
+	rules, err := ParseDataQualityRules("")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	rules, err = ParseDataQualityRules("none")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	rules, err = ParseDataQualityRules("all")
+	assert.NoError(t, err)
+	assert.Len(t, rules, len(DataQualityRules))
+
+	rules, err = ParseDataQualityRules("trade_amounts, fee_charged,trade_amounts")
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 2) {
+		assert.Equal(t, "trade_amounts", rules[0].Name)
+		assert.Equal(t, "fee_charged", rules[1].Name)
+	}
+
+	_, err = ParseDataQualityRules("trade_amounts,unknown")
+	assert.EqualError(t, err, "unknown data quality rule: unknown")
+}
@@ -20,12 +20,17 @@ type EffectProcessor struct {
 	effects  []effect
 	effectsQ history.QEffects
 	sequence uint32
+	counter  *TransactionCounter
 }
 
-func NewEffectProcessor(effectsQ history.QEffects, sequence uint32) *EffectProcessor {
+// NewEffectProcessor returns a processor inserting the effects of the ledger
+// sequence, the effects of each transaction are counted by counter when it's
+// not nil.
+func NewEffectProcessor(effectsQ history.QEffects, sequence uint32, counter *TransactionCounter) *EffectProcessor {
 	return &EffectProcessor{
 		effectsQ: effectsQ,
 		sequence: sequence,
+		counter:  counter,
 	}
 }
 
@@ -119,6 +124,7 @@ func (p *EffectProcessor) ProcessTransaction(transaction io.LedgerTransaction) (
 		return err
 	}
 	p.effects = append(p.effects, effectsForTx...)
+	p.counter.addEffects(transaction.Index, len(effectsForTx))
 
 	return nil
 }
@@ -585,7 +591,10 @@ func (operation *transactionOperationWrapper) accountMergeEffects() []effect {
 	effects.add(source.Address(), history.EffectAccountDebited, details)
 	aid := dest.ToAccountId()
 	effects.add(aid.Address(), history.EffectAccountCredited, details)
-	effects.add(source.Address(), history.EffectAccountRemoved, map[string]interface{}{})
+	effects.add(source.Address(), history.EffectAccountRemoved, map[string]interface{}{
+		"destination": aid.Address(),
+		"amount":      amount.String(result.MustSourceAccountBalance()),
+	})
 
 	return effects.effects
 }
@@ -19,6 +19,7 @@ import (
 type EffectsProcessorTestSuiteLedger struct {
 	suite.Suite
 	processor              *EffectProcessor
+	counter                *TransactionCounter
 	mockQ                  *history.MockQEffects
 	mockBatchInsertBuilder *history.MockEffectBatchInsertBuilder
 
@@ -111,9 +112,11 @@ func (s *EffectsProcessorTestSuiteLedger) SetupTest() {
 		s.addresses[2]: 200,
 	}
 
+	s.counter = NewTransactionCounter()
 	s.processor = NewEffectProcessor(
 		s.mockQ,
 		20,
+		s.counter,
 	)
 
 	s.txs = []io.LedgerTransaction{
@@ -211,6 +214,14 @@ func (s *EffectsProcessorTestSuiteLedger) TestIngestEffectsSucceeds() {
 	}
 	err := s.processor.Commit()
 	s.Assert().NoError(err)
+
+	// the effects of each transaction are counted
+	for _, tx := range s.txs {
+		effects, err := operationsEffects(tx, s.sequence)
+		s.Assert().NoError(err)
+		s.Assert().Equal(int32(len(effects)), s.counter.get(tx.Index).Effects)
+		s.Assert().Equal(int32(0), s.counter.get(tx.Index).Trades)
+	}
 }
 
 func (s *EffectsProcessorTestSuiteLedger) TestCreateAccountsFails() {
@@ -891,7 +902,10 @@ func TestOperationEffects(t *testing.T) {
 					effectType:  history.EffectAccountRemoved,
 					operationID: int64(188978565121),
 					order:       uint32(3),
-					details:     map[string]interface{}{},
+					details: map[string]interface{}{
+						"destination": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+						"amount":      "999.9999900",
+					},
 				},
 			},
 		},
@@ -0,0 +1,73 @@
+package processors
+
+import (
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// HistoryOffersProcessor records snapshots of offers created, updated or
+// removed in a ledger in the history_offers table. Changes are read from
+// transaction meta (unlike OffersProcessor) so the table can be filled when
+// reingesting history ranges.
+type HistoryOffersProcessor struct {
+	offersQ  history.QHistoryOffers
+	sequence uint32
+	cache    *io.LedgerEntryChangeCache
+}
+
+func NewHistoryOffersProcessor(offersQ history.QHistoryOffers, sequence uint32) *HistoryOffersProcessor {
+	return &HistoryOffersProcessor{
+		offersQ:  offersQ,
+		sequence: sequence,
+		cache:    io.NewLedgerEntryChangeCache(),
+	}
+}
+
+// ProcessTransaction adds offer changes of the transaction to the ledger
+// changes cache. Multiple changes of the same offer in a ledger are squashed
+// so only the state at the end of the ledger is recorded.
+func (p *HistoryOffersProcessor) ProcessTransaction(transaction io.LedgerTransaction) error {
+	changes, err := transaction.GetChanges()
+	if err != nil {
+		return errors.Wrap(err, "could not get changes from transaction")
+	}
+
+	for _, change := range changes {
+		if change.Type != xdr.LedgerEntryTypeOffer {
+			continue
+		}
+
+		if err := p.cache.AddChange(change); err != nil {
+			return errors.Wrap(err, "error adding to ledgerCache")
+		}
+	}
+
+	return nil
+}
+
+func (p *HistoryOffersProcessor) Commit() error {
+	changes := p.cache.GetChanges()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	batch := p.offersQ.NewHistoryOffersBatchInsertBuilder(maxBatchSize)
+	for _, change := range changes {
+		var err error
+		if change.Post != nil {
+			err = batch.Add(change.Post.Data.MustOffer(), p.sequence, false)
+		} else {
+			err = batch.Add(change.Pre.Data.MustOffer(), p.sequence, true)
+		}
+		if err != nil {
+			return errors.Wrap(err, "error adding offer snapshot to batch")
+		}
+	}
+
+	if err := batch.Exec(); err != nil {
+		return errors.Wrap(err, "error flushing offer snapshots batch")
+	}
+	return nil
+}
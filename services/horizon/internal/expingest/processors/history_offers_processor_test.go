@@ -0,0 +1,142 @@
+//lint:file-ignore U1001 Ignore all unused code, staticcheck doesn't understand testify/suite
+package processors
+
+import (
+	"testing"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestHistoryOffersProcessorTestSuiteLedger(t *testing.T) {
+	suite.Run(t, new(HistoryOffersProcessorTestSuiteLedger))
+}
+
+type HistoryOffersProcessorTestSuiteLedger struct {
+	suite.Suite
+	processor              *HistoryOffersProcessor
+	mockQ                  *history.MockQHistoryOffers
+	mockBatchInsertBuilder *history.MockHistoryOffersBatchInsertBuilder
+	sequence               uint32
+}
+
+func (s *HistoryOffersProcessorTestSuiteLedger) SetupTest() {
+	s.mockQ = &history.MockQHistoryOffers{}
+	s.mockBatchInsertBuilder = &history.MockHistoryOffersBatchInsertBuilder{}
+	s.sequence = 456
+	s.processor = NewHistoryOffersProcessor(s.mockQ, s.sequence)
+}
+
+func (s *HistoryOffersProcessorTestSuiteLedger) TearDownTest() {
+	s.mockQ.AssertExpectations(s.T())
+	s.mockBatchInsertBuilder.AssertExpectations(s.T())
+}
+
+func historyOffer(id xdr.Int64, amount xdr.Int64) xdr.OfferEntry {
+	return xdr.OfferEntry{
+		SellerId: xdr.MustAddress("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"),
+		OfferId:  id,
+		Amount:   amount,
+		Price:    xdr.Price{N: 1, D: 2},
+	}
+}
+
+func offerLedgerEntry(offer xdr.OfferEntry) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 123,
+		Data: xdr.LedgerEntryData{
+			Type:  xdr.LedgerEntryTypeOffer,
+			Offer: &offer,
+		},
+	}
+}
+
+func offerChangesTransaction(changes ...xdr.LedgerEntryChange) io.LedgerTransaction {
+	return io.LedgerTransaction{
+		Meta: xdr.TransactionMeta{
+			V: 1,
+			V1: &xdr.TransactionMetaV1{
+				Operations: []xdr.OperationMeta{{Changes: changes}},
+			},
+		},
+	}
+}
+
+func createdChange(offer xdr.OfferEntry) xdr.LedgerEntryChange {
+	entry := offerLedgerEntry(offer)
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &entry}
+}
+
+func stateChange(offer xdr.OfferEntry) xdr.LedgerEntryChange {
+	entry := offerLedgerEntry(offer)
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &entry}
+}
+
+func updatedChange(offer xdr.OfferEntry) xdr.LedgerEntryChange {
+	entry := offerLedgerEntry(offer)
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &entry}
+}
+
+func removedChange(offer xdr.OfferEntry) xdr.LedgerEntryChange {
+	key := xdr.LedgerKey{}
+	key.SetOffer(offer.SellerId, uint64(offer.OfferId))
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &key}
+}
+
+func (s *HistoryOffersProcessorTestSuiteLedger) TestNoOfferChanges() {
+	err := s.processor.ProcessTransaction(offerChangesTransaction())
+	s.Assert().NoError(err)
+	s.Assert().NoError(s.processor.Commit())
+}
+
+func (s *HistoryOffersProcessorTestSuiteLedger) TestSnapshots() {
+	s.mockQ.
+		On("NewHistoryOffersBatchInsertBuilder", maxBatchSize).
+		Return(s.mockBatchInsertBuilder).Once()
+
+	// created and then updated in the same ledger
+	err := s.processor.ProcessTransaction(offerChangesTransaction(
+		createdChange(historyOffer(1, 100)),
+		stateChange(historyOffer(2, 100)),
+		updatedChange(historyOffer(2, 50)),
+		stateChange(historyOffer(3, 100)),
+		removedChange(historyOffer(3, 100)),
+		// created and removed in the same ledger: no snapshot
+		createdChange(historyOffer(4, 100)),
+	))
+	s.Assert().NoError(err)
+	err = s.processor.ProcessTransaction(offerChangesTransaction(
+		stateChange(historyOffer(1, 100)),
+		updatedChange(historyOffer(1, 70)),
+		stateChange(historyOffer(4, 100)),
+		removedChange(historyOffer(4, 100)),
+	))
+	s.Assert().NoError(err)
+
+	s.mockBatchInsertBuilder.On("Add", historyOffer(1, 70), s.sequence, false).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", historyOffer(2, 50), s.sequence, false).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", historyOffer(3, 100), s.sequence, true).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Exec").Return(nil).Once()
+
+	s.Assert().NoError(s.processor.Commit())
+}
+
+func (s *HistoryOffersProcessorTestSuiteLedger) TestExecFails() {
+	s.mockQ.
+		On("NewHistoryOffersBatchInsertBuilder", maxBatchSize).
+		Return(s.mockBatchInsertBuilder).Once()
+
+	err := s.processor.ProcessTransaction(offerChangesTransaction(
+		createdChange(historyOffer(1, 100)),
+	))
+	s.Assert().NoError(err)
+
+	s.mockBatchInsertBuilder.On("Add", historyOffer(1, 100), s.sequence, false).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Exec").Return(errors.New("transient error")).Once()
+
+	err = s.processor.Commit()
+	s.Assert().EqualError(err, "error flushing offer snapshots batch: transient error")
+}
@@ -0,0 +1,98 @@
+package processors
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// Names of the history processors which can be disabled by an ingestion
+// profile. The ledgers processor is always enabled.
+const (
+	EffectsHistory           = "effects"
+	OperationsHistory        = "operations"
+	TradesHistory            = "trades"
+	ParticipantsHistory      = "participants"
+	TransactionsHistory      = "transactions"
+	OffersHistory            = "offers"
+	AccountEventsHistory     = "account_events"
+	AccountThresholdsHistory = "account_thresholds"
+)
+
+// HistoryProcessors are the names of all the history processors which can be
+// disabled by an ingestion profile.
+var HistoryProcessors = []string{
+	EffectsHistory,
+	OperationsHistory,
+	TradesHistory,
+	ParticipantsHistory,
+	TransactionsHistory,
+	OffersHistory,
+	AccountEventsHistory,
+	AccountThresholdsHistory,
+}
+
+// IngestionProfiles are the built-in ingestion profiles indexed by name.
+var IngestionProfiles = map[string]IngestionProfile{
+	"trades-only": NewIngestionProfile(TradesHistory),
+	"payments-only": NewIngestionProfile(
+		TransactionsHistory, OperationsHistory, ParticipantsHistory,
+	),
+}
+
+// IngestionProfile is the set of the history processors enabled when
+// ingesting transactions. A nil profile enables all the processors.
+type IngestionProfile map[string]bool
+
+// NewIngestionProfile returns a profile enabling the given processors.
+func NewIngestionProfile(processors ...string) IngestionProfile {
+	profile := IngestionProfile{}
+	for _, processor := range processors {
+		profile[processor] = true
+	}
+	return profile
+}
+
+// Enabled returns true if the history processor called name is enabled.
+func (p IngestionProfile) Enabled(name string) bool {
+	return p == nil || p[name]
+}
+
+// String returns the comma separated list of the enabled processors.
+func (p IngestionProfile) String() string {
+	if p == nil {
+		return "full"
+	}
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// ParseIngestionProfile returns the profile called value ("full", or an empty
+// string, enables all the processors) or the profile enabling the processors
+// of a comma separated list of history processor names.
+func ParseIngestionProfile(value string) (IngestionProfile, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "", "full":
+		return nil, nil
+	}
+	if profile, ok := IngestionProfiles[value]; ok {
+		return profile, nil
+	}
+
+	known := NewIngestionProfile(HistoryProcessors...)
+	profile := IngestionProfile{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if !known[name] {
+			return nil, errors.Errorf("unknown ingestion profile or history processor: %s", name)
+		}
+		profile[name] = true
+	}
+	return profile, nil
+}
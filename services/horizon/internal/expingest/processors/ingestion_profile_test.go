@@ -0,0 +1,36 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIngestionProfile(t *testing.T) {
+	profile, err := ParseIngestionProfile("")
+	assert.NoError(t, err)
+	assert.Nil(t, profile)
+	assert.True(t, profile.Enabled(EffectsHistory))
+	assert.Equal(t, "full", profile.String())
+
+	profile, err = ParseIngestionProfile("full")
+	assert.NoError(t, err)
+	assert.Nil(t, profile)
+
+	profile, err = ParseIngestionProfile("trades-only")
+	assert.NoError(t, err)
+	assert.True(t, profile.Enabled(TradesHistory))
+	assert.False(t, profile.Enabled(EffectsHistory))
+	assert.False(t, profile.Enabled(OperationsHistory))
+
+	profile, err = ParseIngestionProfile("payments-only")
+	assert.NoError(t, err)
+	assert.Equal(t, "operations,participants,transactions", profile.String())
+
+	profile, err = ParseIngestionProfile("transactions, effects,transactions")
+	assert.NoError(t, err)
+	assert.Equal(t, "effects,transactions", profile.String())
+
+	_, err = ParseIngestionProfile("transactions,unknown")
+	assert.EqualError(t, err, "unknown ingestion profile or history processor: unknown")
+}
@@ -0,0 +1,100 @@
+package processors
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// LedgerManifestProcessor stores the checksums of the trades and payments of
+// a ledger. The checksums are computed from the transactions of the ledger,
+// the same way history.Q.UpdateLedgerManifest computes them from the rows in
+// the database. trades and payments are false when the trades or the
+// operations of the ledger are not ingested, the checksums are then the
+// checksums of no rows.
+type LedgerManifestProcessor struct {
+	manifestsQ history.QLedgerManifests
+	ledger     xdr.LedgerHeaderHistoryEntry
+	trades     bool
+	payments   bool
+
+	tradeEntries   []history.TradeManifestEntry
+	paymentEntries []history.PaymentManifestEntry
+}
+
+func NewLedgerManifestProcessor(
+	manifestsQ history.QLedgerManifests,
+	ledger xdr.LedgerHeaderHistoryEntry,
+	trades, payments bool,
+) *LedgerManifestProcessor {
+	return &LedgerManifestProcessor{
+		manifestsQ: manifestsQ,
+		ledger:     ledger,
+		trades:     trades,
+		payments:   payments,
+	}
+}
+
+// ProcessTransaction process the given transaction
+func (p *LedgerManifestProcessor) ProcessTransaction(transaction io.LedgerTransaction) error {
+	if p.trades && transaction.Result.Successful() {
+		inserts, buyers, err := (&TradeProcessor{}).extractTrades(p.ledger, transaction)
+		if err != nil {
+			return err
+		}
+		for i, insert := range inserts {
+			entry, err := history.NewTradeManifestEntry(insert, buyers[i])
+			if err != nil {
+				return errors.Wrap(err, "Error creating trade manifest entry")
+			}
+			p.tradeEntries = append(p.tradeEntries, entry)
+		}
+	}
+
+	if !p.payments {
+		return nil
+	}
+	for i, op := range transaction.Envelope.Operations() {
+		if !history.IsPaymentOperation(op.Body.Type) {
+			continue
+		}
+		operation := transactionOperationWrapper{
+			index:          uint32(i),
+			transaction:    transaction,
+			operation:      op,
+			ledgerSequence: uint32(p.ledger.Header.LedgerSeq),
+		}
+		detailsJSON, err := json.Marshal(operation.Details())
+		if err != nil {
+			return errors.Wrapf(err, "Error marshaling details for operation %v", operation.ID())
+		}
+		entry, err := history.NewPaymentManifestEntry(
+			operation.ID(),
+			hex.EncodeToString(transaction.Result.TransactionHash[:]),
+			operation.OperationType(),
+			operation.SourceAccount().Address(),
+			transaction.Result.Successful(),
+			detailsJSON,
+		)
+		if err != nil {
+			return errors.Wrap(err, "Error creating payment manifest entry")
+		}
+		p.paymentEntries = append(p.paymentEntries, entry)
+	}
+	return nil
+}
+
+// Commit stores the manifest of the ledger
+func (p *LedgerManifestProcessor) Commit() error {
+	manifest, err := history.NewLedgerManifest(
+		uint32(p.ledger.Header.LedgerSeq), p.tradeEntries, p.paymentEntries,
+	)
+	if err != nil {
+		return errors.Wrap(err, "Error computing ledger manifest")
+	}
+	return errors.Wrap(p.manifestsQ.InsertLedgerManifest(manifest), "Error inserting ledger manifest")
+}
@@ -0,0 +1,52 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerManifestProcessor(t *testing.T) {
+	q := &history.MockQLedgerManifests{}
+	ledger := xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: 20}}
+	processor := NewLedgerManifestProcessor(q, ledger, true, true)
+
+	transaction := filterTransaction(
+		paymentOperation(filterDestination, xdr.MustNewNativeAsset()),
+		xdr.Operation{Body: xdr.OperationBody{Type: xdr.OperationTypeInflation}},
+	)
+	transaction.Result.TransactionHash = xdr.Hash{0x01}
+	assert.NoError(t, processor.ProcessTransaction(transaction))
+
+	// the payment is described as it's stored in history_operations
+	payment, err := history.NewPaymentManifestEntry(
+		toid.New(20, 1, 1).ToInt64(),
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		xdr.OperationTypePayment,
+		filterSource,
+		true,
+		[]byte(`{"amount":"0.0000100","asset_type":"native","from":"`+filterSource+`","to":"`+filterDestination+`"}`),
+	)
+	assert.NoError(t, err)
+	expected, err := history.NewLedgerManifest(20, nil, []history.PaymentManifestEntry{payment})
+	assert.NoError(t, err)
+
+	q.On("InsertLedgerManifest", expected).Return(nil).Once()
+	assert.NoError(t, processor.Commit())
+
+	q.On("InsertLedgerManifest", expected).Return(errors.New("transient error")).Once()
+	assert.EqualError(t, processor.Commit(), "Error inserting ledger manifest: transient error")
+
+	// operations which are not ingested are not in the manifest
+	processor = NewLedgerManifestProcessor(q, ledger, false, false)
+	assert.NoError(t, processor.ProcessTransaction(transaction))
+	empty, err := history.NewLedgerManifest(20, nil, nil)
+	assert.NoError(t, err)
+	q.On("InsertLedgerManifest", empty).Return(nil).Once()
+	assert.NoError(t, processor.Commit())
+	q.AssertExpectations(t)
+}
@@ -36,6 +36,7 @@ func createTransaction(successful bool, numOps int) io.LedgerTransaction {
 	}
 
 	operations := []xdr.Operation{}
+	results := []xdr.OperationResult{}
 	op := xdr.BumpSequenceOp{BumpTo: 30000}
 	for i := 0; i < numOps; i++ {
 		operations = append(operations, xdr.Operation{
@@ -44,13 +45,23 @@ func createTransaction(successful bool, numOps int) io.LedgerTransaction {
 				BumpSequenceOp: &op,
 			},
 		})
+		results = append(results, xdr.OperationResult{
+			Code: xdr.OperationResultCodeOpInner,
+			Tr: &xdr.OperationResultTr{
+				Type: xdr.OperationTypeBumpSequence,
+				BumpSeqResult: &xdr.BumpSequenceResult{
+					Code: xdr.BumpSequenceResultCodeBumpSequenceSuccess,
+				},
+			},
+		})
 	}
 	sourceAID := xdr.MustAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
 	return io.LedgerTransaction{
 		Result: xdr.TransactionResultPair{
 			Result: xdr.TransactionResult{
 				Result: xdr.TransactionResultResult{
-					Code: code,
+					Code:    code,
+					Results: &results,
 				},
 			},
 		},
@@ -63,6 +74,7 @@ func createTransaction(successful bool, numOps int) io.LedgerTransaction {
 				},
 			},
 		},
+		Meta: createTransactionMeta(make([]xdr.OperationMeta, numOps)),
 	}
 }
 
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"sort"
 	"time"
 
 	"github.com/stellar/go/exp/ingest/io"
@@ -14,17 +15,29 @@ import (
 type TradeProcessor struct {
 	tradesQ    history.QTrades
 	ledger     xdr.LedgerHeaderHistoryEntry
+	onConflict history.TradeConflictMode
 	inserts    []history.InsertTrade
 	buyers     []string
 	accountSet map[string]int64
 	assets     []xdr.Asset
+	counter    *TransactionCounter
 }
 
-func NewTradeProcessor(tradesQ history.QTrades, ledger xdr.LedgerHeaderHistoryEntry) *TradeProcessor {
+// NewTradeProcessor returns a processor inserting the trades of ledger,
+// onConflict selects how the trades which were already inserted are handled.
+// The trades of each transaction are counted by counter when it's not nil.
+func NewTradeProcessor(
+	tradesQ history.QTrades,
+	ledger xdr.LedgerHeaderHistoryEntry,
+	onConflict history.TradeConflictMode,
+	counter *TransactionCounter,
+) *TradeProcessor {
 	return &TradeProcessor{
 		tradesQ:    tradesQ,
 		ledger:     ledger,
+		onConflict: onConflict,
 		accountSet: map[string]int64{},
+		counter:    counter,
 	}
 }
 
@@ -40,6 +53,7 @@ func (p *TradeProcessor) ProcessTransaction(transaction io.LedgerTransaction) (e
 	if err != nil {
 		return err
 	}
+	p.counter.addTrades(transaction.Index, len(txInserts))
 
 	for i, insert := range txInserts {
 		buyer := txBuyers[i]
@@ -56,7 +70,7 @@ func (p *TradeProcessor) ProcessTransaction(transaction io.LedgerTransaction) (e
 
 func (p *TradeProcessor) Commit() error {
 	if len(p.inserts) > 0 {
-		batch := p.tradesQ.NewTradeBatchInsertBuilder(maxBatchSize)
+		batch := p.tradesQ.NewTradeBatchInsertBuilder(maxBatchSize, p.onConflict)
 		accountSet, err := p.tradesQ.CreateAccounts(mapKeysToList(p.accountSet), maxBatchSize)
 		if err != nil {
 			return errors.Wrap(err, "Error creating account ids")
@@ -68,6 +82,9 @@ func (p *TradeProcessor) Commit() error {
 			return errors.Wrap(err, "Error creating asset ids")
 		}
 
+		// insert the trades in the order of their ids so that a ledger
+		// ingested again writes them in the same order
+		sort.Sort(tradesByID{p.inserts, p.buyers})
 		for i, insert := range p.inserts {
 			insert.BuyerAccountID = accountSet[p.buyers[i]]
 			insert.SellerAccountID = accountSet[insert.Trade.SellerId.Address()]
@@ -79,13 +96,33 @@ func (p *TradeProcessor) Commit() error {
 		}
 
 		if err = batch.Exec(); err != nil {
-			return errors.Wrap(err, "Error flushing operation batch")
+			return errors.Wrap(err, "Error flushing trade batch")
 		}
 	}
 
 	return nil
 }
 
+// tradesByID sorts the trades, and their buyers, by operation id and order.
+type tradesByID struct {
+	inserts []history.InsertTrade
+	buyers  []string
+}
+
+func (t tradesByID) Len() int { return len(t.inserts) }
+
+func (t tradesByID) Less(i, j int) bool {
+	if t.inserts[i].HistoryOperationID != t.inserts[j].HistoryOperationID {
+		return t.inserts[i].HistoryOperationID < t.inserts[j].HistoryOperationID
+	}
+	return t.inserts[i].Order < t.inserts[j].Order
+}
+
+func (t tradesByID) Swap(i, j int) {
+	t.inserts[i], t.inserts[j] = t.inserts[j], t.inserts[i]
+	t.buyers[i], t.buyers[j] = t.buyers[j], t.buyers[i]
+}
+
 func (p *TradeProcessor) findTradeSellPrice(
 	transaction io.LedgerTransaction,
 	opidx int,
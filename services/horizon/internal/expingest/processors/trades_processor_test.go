@@ -3,6 +3,7 @@ package processors
 
 import (
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/toid"
 	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -17,6 +19,7 @@ import (
 type TradeProcessorTestSuiteLedger struct {
 	suite.Suite
 	processor              *TradeProcessor
+	counter                *TransactionCounter
 	mockQ                  *history.MockQTrades
 	mockBatchInsertBuilder *history.MockTradeBatchInsertBuilder
 
@@ -139,6 +142,7 @@ func (s *TradeProcessorTestSuiteLedger) SetupTest() {
 		s.sellPrices = append(s.sellPrices, xdr.Price{N: n, D: 100})
 	}
 
+	s.counter = NewTransactionCounter()
 	s.processor = NewTradeProcessor(
 		s.mockQ,
 		xdr.LedgerHeaderHistoryEntry{
@@ -146,6 +150,8 @@ func (s *TradeProcessorTestSuiteLedger) SetupTest() {
 				LedgerSeq: 100,
 			},
 		},
+		history.TradeConflictVerify,
+		s.counter,
 	)
 }
 
@@ -483,7 +489,7 @@ func (s *TradeProcessorTestSuiteLedger) mockReadTradeTransactions(
 		tx,
 	}
 
-	s.mockQ.On("NewTradeBatchInsertBuilder", maxBatchSize).
+	s.mockQ.On("NewTradeBatchInsertBuilder", maxBatchSize, history.TradeConflictVerify).
 		Return(s.mockBatchInsertBuilder).Once()
 
 	return inserts
@@ -525,6 +531,16 @@ func (s *TradeProcessorTestSuiteLedger) TestIngestTradesSucceeds() {
 
 	err := s.processor.Commit()
 	s.Assert().NoError(err)
+
+	// the trades of each transaction are counted
+	expected := map[uint32]history.TransactionCounts{}
+	for _, insert := range inserts {
+		index := uint32(toid.Parse(insert.HistoryOperationID).TransactionOrder)
+		counts := expected[index]
+		counts.Trades++
+		expected[index] = counts
+	}
+	s.Assert().Equal(expected, s.counter.counts)
 }
 
 func (s *TradeProcessorTestSuiteLedger) TestCreateAccountsError() {
@@ -642,7 +658,7 @@ func (s *TradeProcessorTestSuiteLedger) TestBatchExecError() {
 	}
 
 	err := s.processor.Commit()
-	s.Assert().EqualError(err, "Error flushing operation batch: exec error")
+	s.Assert().EqualError(err, "Error flushing trade batch: exec error")
 }
 
 func (s *TradeProcessorTestSuiteLedger) TestIgnoreCheckIfSmallLedger() {
@@ -699,3 +715,22 @@ func TestTradeProcessor_ProcessTransaction_MuxedAccount(t *testing.T) {
 		},
 	}
 }
+
+func TestTradesByID(t *testing.T) {
+	trades := tradesByID{
+		inserts: []history.InsertTrade{
+			{HistoryOperationID: 2, Order: 0},
+			{HistoryOperationID: 1, Order: 1},
+			{HistoryOperationID: 1, Order: 0},
+		},
+		buyers: []string{"c", "b", "a"},
+	}
+	sort.Sort(trades)
+
+	assert.Equal(t, []history.InsertTrade{
+		{HistoryOperationID: 1, Order: 0},
+		{HistoryOperationID: 1, Order: 1},
+		{HistoryOperationID: 2, Order: 0},
+	}, trades.inserts)
+	assert.Equal(t, []string{"a", "b", "c"}, trades.buyers)
+}
@@ -0,0 +1,210 @@
+package processors
+
+import (
+	"strings"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// TransactionFilter selects the transactions ingested into the history
+// tables. Filtered out transactions are skipped by the history processors
+// but the ledger state is always ingested in full.
+type TransactionFilter interface {
+	// Keep returns true if the transaction of the ledger sequence must be
+	// ingested.
+	Keep(sequence uint32, transaction io.LedgerTransaction) (bool, error)
+}
+
+// AccountsFilter keeps the transactions with any of the accounts as a
+// participant (see ParticipantsProcessor), as the account of an effect (see
+// EffectProcessor) or as the seller of a trade (see TradeProcessor), so that
+// the history of the accounts is complete.
+type AccountsFilter map[string]bool
+
+// Keep implements TransactionFilter.
+func (f AccountsFilter) Keep(sequence uint32, transaction io.LedgerTransaction) (bool, error) {
+	participants, err := participantsForTransaction(sequence, transaction)
+	if err != nil {
+		return false, errors.Wrap(err, "could not determine transaction participants")
+	}
+	for _, participant := range participants {
+		if f[participant.Address()] {
+			return true, nil
+		}
+	}
+
+	// failed transactions have no effects nor trades
+	if !transaction.Result.Successful() {
+		return false, nil
+	}
+
+	// the buyer of a trade is the source of its operation, which is a
+	// participant
+	opResults, ok := transaction.Result.OperationResults()
+	if !ok {
+		return false, errors.New("transaction has no operation results")
+	}
+	for _, result := range opResults {
+		for _, trade := range claimedOffers(result) {
+			// garbage collected offers are not trades
+			if trade.AmountSold == 0 && trade.AmountBought == 0 {
+				continue
+			}
+			if f[trade.SellerId.Address()] {
+				return true, nil
+			}
+		}
+	}
+
+	effects, err := operationsEffects(transaction, sequence)
+	if err != nil {
+		return false, errors.Wrap(err, "could not determine transaction effects")
+	}
+	for _, effect := range effects {
+		if f[effect.address] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AssetsFilter keeps the transactions with an operation using any of the
+// assets (payments, offers, trust lines and the paths of path payments).
+// Assets are keyed by their string representation (see xdr.Asset.String).
+type AssetsFilter map[string]bool
+
+// Keep implements TransactionFilter.
+func (f AssetsFilter) Keep(sequence uint32, transaction io.LedgerTransaction) (bool, error) {
+	for _, op := range transaction.Envelope.Operations() {
+		source := transaction.Envelope.SourceAccount().ToAccountId()
+		if op.SourceAccount != nil {
+			source = op.SourceAccount.ToAccountId()
+		}
+		for _, asset := range operationAssets(op, source) {
+			if f[asset.String()] {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// operationAssets returns the assets used by op, source is the source
+// account of the operation.
+func operationAssets(op xdr.Operation, source xdr.AccountId) []xdr.Asset {
+	switch op.Body.Type {
+	case xdr.OperationTypePayment:
+		return []xdr.Asset{op.Body.MustPaymentOp().Asset}
+	case xdr.OperationTypePathPaymentStrictReceive:
+		body := op.Body.MustPathPaymentStrictReceiveOp()
+		return append([]xdr.Asset{body.SendAsset, body.DestAsset}, body.Path...)
+	case xdr.OperationTypePathPaymentStrictSend:
+		body := op.Body.MustPathPaymentStrictSendOp()
+		return append([]xdr.Asset{body.SendAsset, body.DestAsset}, body.Path...)
+	case xdr.OperationTypeManageSellOffer:
+		body := op.Body.MustManageSellOfferOp()
+		return []xdr.Asset{body.Selling, body.Buying}
+	case xdr.OperationTypeManageBuyOffer:
+		body := op.Body.MustManageBuyOfferOp()
+		return []xdr.Asset{body.Selling, body.Buying}
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		body := op.Body.MustCreatePassiveSellOfferOp()
+		return []xdr.Asset{body.Selling, body.Buying}
+	case xdr.OperationTypeChangeTrust:
+		return []xdr.Asset{op.Body.MustChangeTrustOp().Line}
+	case xdr.OperationTypeAllowTrust:
+		// the source account of allow trust operations is the issuer
+		return []xdr.Asset{op.Body.MustAllowTrustOp().Asset.ToAsset(source)}
+	default:
+		return nil
+	}
+}
+
+// anyTransactionFilter keeps the transactions kept by any of its filters.
+type anyTransactionFilter []TransactionFilter
+
+// Keep implements TransactionFilter.
+func (f anyTransactionFilter) Keep(sequence uint32, transaction io.LedgerTransaction) (bool, error) {
+	for _, filter := range f {
+		keep, err := filter.Keep(sequence, transaction)
+		if err != nil || keep {
+			return keep, err
+		}
+	}
+	return false, nil
+}
+
+// SuccessfulTransactionsFilter keeps the successful transactions.
+type SuccessfulTransactionsFilter struct{}
+
+// Keep implements TransactionFilter.
+func (SuccessfulTransactionsFilter) Keep(sequence uint32, transaction io.LedgerTransaction) (bool, error) {
+	return transaction.Result.Successful(), nil
+}
+
+// allTransactionFilter keeps the transactions kept by all of its filters.
+type allTransactionFilter []TransactionFilter
+
+// Keep implements TransactionFilter.
+func (f allTransactionFilter) Keep(sequence uint32, transaction io.LedgerTransaction) (bool, error) {
+	for _, filter := range f {
+		keep, err := filter.Keep(sequence, transaction)
+		if err != nil || !keep {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// SkipFailedTransactions returns the filter keeping the successful
+// transactions kept by filter. filter can be nil to keep all the successful
+// transactions.
+func SkipFailedTransactions(filter TransactionFilter) TransactionFilter {
+	if filter == nil {
+		return SuccessfulTransactionsFilter{}
+	}
+	return allTransactionFilter{SuccessfulTransactionsFilter{}, filter}
+}
+
+// ParseTransactionFilter returns the filter keeping the transactions of any
+// of the accounts or assets given as comma separated lists of account ids
+// and of assets in the CODE:ISSUER format. It returns nil, which ingests
+// all transactions, when both lists are empty.
+func ParseTransactionFilter(accounts, assets string) (TransactionFilter, error) {
+	var filter anyTransactionFilter
+
+	if accounts = strings.TrimSpace(accounts); accounts != "" {
+		accountsFilter := AccountsFilter{}
+		for _, address := range strings.Split(accounts, ",") {
+			address = strings.TrimSpace(address)
+			var account xdr.AccountId
+			if err := account.SetAddress(address); err != nil {
+				return nil, errors.Errorf("invalid account: %s", address)
+			}
+			accountsFilter[address] = true
+		}
+		filter = append(filter, accountsFilter)
+	}
+
+	if assets = strings.TrimSpace(assets); assets != "" {
+		parsed, err := xdr.BuildAssets(strings.Replace(assets, " ", "", -1))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid assets")
+		}
+		assetsFilter := AssetsFilter{}
+		for _, asset := range parsed {
+			if asset.Type == xdr.AssetTypeAssetTypeNative {
+				return nil, errors.New("the native asset can not be used as a filter, all transactions use it")
+			}
+			assetsFilter[asset.String()] = true
+		}
+		filter = append(filter, assetsFilter)
+	}
+
+	if len(filter) == 0 {
+		return nil, nil
+	}
+	return filter, nil
+}
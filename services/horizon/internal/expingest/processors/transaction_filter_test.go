@@ -0,0 +1,270 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	filterSource      = "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+	filterDestination = "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"
+	filterIssuer      = "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+)
+
+func filterMuxedAccount(address string) xdr.MuxedAccount {
+	account := xdr.MustAddress(address)
+	return account.ToMuxedAccount()
+}
+
+func filterTransaction(operations ...xdr.Operation) io.LedgerTransaction {
+	return io.LedgerTransaction{
+		Index: 1,
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code:    xdr.TransactionResultCodeTxSuccess,
+					Results: &[]xdr.OperationResult{},
+				},
+			},
+		},
+		Meta: xdr.TransactionMeta{
+			V:  1,
+			V1: &xdr.TransactionMetaV1{},
+		},
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: filterMuxedAccount(filterSource),
+					Fee:           100,
+					Operations:    operations,
+				},
+			},
+		},
+	}
+}
+
+func paymentOperation(destination string, asset xdr.Asset) xdr.Operation {
+	return xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePayment,
+			PaymentOp: &xdr.PaymentOp{
+				Destination: filterMuxedAccount(destination),
+				Asset:       asset,
+				Amount:      100,
+			},
+		},
+	}
+}
+
+func TestParseTransactionFilter(t *testing.T) {
+	filter, err := ParseTransactionFilter("", " ")
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+
+	filter, err = ParseTransactionFilter(filterSource+", "+filterDestination, "USD:"+filterIssuer)
+	assert.NoError(t, err)
+	assert.Equal(t, anyTransactionFilter{
+		AccountsFilter{filterSource: true, filterDestination: true},
+		AssetsFilter{"credit_alphanum4/USD/" + filterIssuer: true},
+	}, filter)
+
+	_, err = ParseTransactionFilter("GABC", "")
+	assert.EqualError(t, err, "invalid account: GABC")
+
+	_, err = ParseTransactionFilter("", "USD")
+	assert.Error(t, err)
+
+	_, err = ParseTransactionFilter("", "native")
+	assert.EqualError(t, err, "the native asset can not be used as a filter, all transactions use it")
+}
+
+func TestAccountsFilter(t *testing.T) {
+	tx := filterTransaction(paymentOperation(filterDestination, xdr.MustNewNativeAsset()))
+
+	keep, err := AccountsFilter{filterDestination: true}.Keep(20, tx)
+	assert.NoError(t, err)
+	assert.True(t, keep)
+
+	keep, err = AccountsFilter{filterIssuer: true}.Keep(20, tx)
+	assert.NoError(t, err)
+	assert.False(t, keep)
+}
+
+func TestAccountsFilterTradesAndEffects(t *testing.T) {
+	seller := xdr.MustAddress(filterIssuer)
+	trade := filterTransaction(xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferOp: &xdr.ManageSellOfferOp{
+				Selling: xdr.MustNewCreditAsset("USD", filterDestination),
+				Buying:  xdr.MustNewNativeAsset(),
+				Amount:  100,
+				Price:   xdr.Price{N: 1, D: 1},
+			},
+		},
+	})
+	trade.Result.Result.Result.Results = &[]xdr.OperationResult{
+		{
+			Code: xdr.OperationResultCodeOpInner,
+			Tr: &xdr.OperationResultTr{
+				Type: xdr.OperationTypeManageSellOffer,
+				ManageSellOfferResult: &xdr.ManageSellOfferResult{
+					Code: xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+					Success: &xdr.ManageOfferSuccessResult{
+						OffersClaimed: []xdr.ClaimOfferAtom{
+							{
+								SellerId:     seller,
+								OfferId:      3,
+								AssetSold:    xdr.MustNewNativeAsset(),
+								AmountSold:   100,
+								AssetBought:  xdr.MustNewCreditAsset("USD", filterDestination),
+								AmountBought: 100,
+							},
+						},
+						Offer: xdr.ManageOfferSuccessResultOffer{
+							Effect: xdr.ManageOfferEffectManageOfferDeleted,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// the seller of a trade is not a participant of the transaction
+	keep, err := AccountsFilter{filterIssuer: true}.Keep(20, trade)
+	assert.NoError(t, err)
+	assert.True(t, keep)
+
+	garbageCollected := trade
+	garbageCollected.Result.Result.Result.Results = &[]xdr.OperationResult{{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr: &xdr.OperationResultTr{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferResult: &xdr.ManageSellOfferResult{
+				Code: xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+				Success: &xdr.ManageOfferSuccessResult{
+					OffersClaimed: []xdr.ClaimOfferAtom{
+						{
+							SellerId:    seller,
+							OfferId:     4,
+							AssetSold:   xdr.MustNewNativeAsset(),
+							AssetBought: xdr.MustNewCreditAsset("USD", filterDestination),
+						},
+					},
+					Offer: xdr.ManageOfferSuccessResultOffer{
+						Effect: xdr.ManageOfferEffectManageOfferDeleted,
+					},
+				},
+			},
+		},
+	}}
+	keep, err = AccountsFilter{filterIssuer: true}.Keep(20, garbageCollected)
+	assert.NoError(t, err)
+	assert.False(t, keep)
+
+	// the destination of an inflation payout is only in the effects
+	inflation := filterTransaction(xdr.Operation{
+		Body: xdr.OperationBody{Type: xdr.OperationTypeInflation},
+	})
+	inflation.Result.Result.Result.Results = &[]xdr.OperationResult{{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr: &xdr.OperationResultTr{
+			Type: xdr.OperationTypeInflation,
+			InflationResult: &xdr.InflationResult{
+				Code: xdr.InflationResultCodeInflationSuccess,
+				Payouts: &[]xdr.InflationPayout{
+					{Destination: xdr.MustAddress(filterDestination), Amount: 100},
+				},
+			},
+		},
+	}}
+	keep, err = AccountsFilter{filterDestination: true}.Keep(20, inflation)
+	assert.NoError(t, err)
+	assert.True(t, keep)
+
+	keep, err = AccountsFilter{filterIssuer: true}.Keep(20, inflation)
+	assert.NoError(t, err)
+	assert.False(t, keep)
+}
+
+func TestSkipFailedTransactions(t *testing.T) {
+	successful := filterTransaction(paymentOperation(filterDestination, xdr.MustNewNativeAsset()))
+	failed := filterTransaction(paymentOperation(filterDestination, xdr.MustNewNativeAsset()))
+	failed.Result.Result.Result.Code = xdr.TransactionResultCodeTxFailed
+
+	filter := SkipFailedTransactions(nil)
+	keep, err := filter.Keep(20, successful)
+	assert.NoError(t, err)
+	assert.True(t, keep)
+	keep, err = filter.Keep(20, failed)
+	assert.NoError(t, err)
+	assert.False(t, keep)
+
+	filter = SkipFailedTransactions(AccountsFilter{filterDestination: true})
+	keep, err = filter.Keep(20, successful)
+	assert.NoError(t, err)
+	assert.True(t, keep)
+	keep, err = filter.Keep(20, failed)
+	assert.NoError(t, err)
+	assert.False(t, keep)
+
+	filter = SkipFailedTransactions(AccountsFilter{filterIssuer: true})
+	keep, err = filter.Keep(20, successful)
+	assert.NoError(t, err)
+	assert.False(t, keep)
+}
+
+func TestAssetsFilter(t *testing.T) {
+	usd := xdr.MustNewCreditAsset("USD", filterIssuer)
+	filter := AssetsFilter{usd.String(): true}
+
+	keep, err := filter.Keep(20, filterTransaction(paymentOperation(filterDestination, xdr.MustNewNativeAsset())))
+	assert.NoError(t, err)
+	assert.False(t, keep)
+
+	keep, err = filter.Keep(20, filterTransaction(
+		paymentOperation(filterDestination, xdr.MustNewNativeAsset()),
+		paymentOperation(filterDestination, usd),
+	))
+	assert.NoError(t, err)
+	assert.True(t, keep)
+
+	keep, err = filter.Keep(20, filterTransaction(xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePathPaymentStrictSend,
+			PathPaymentStrictSendOp: &xdr.PathPaymentStrictSendOp{
+				SendAsset:   xdr.MustNewNativeAsset(),
+				Destination: filterMuxedAccount(filterDestination),
+				DestAsset:   xdr.MustNewCreditAsset("EUR", filterIssuer),
+				Path:        []xdr.Asset{usd},
+			},
+		},
+	}))
+	assert.NoError(t, err)
+	assert.True(t, keep)
+
+	// the issuer of allow trust operations is their source account
+	issuer := filterMuxedAccount(filterIssuer)
+	code := xdr.AllowTrustOpAsset{Type: xdr.AssetTypeAssetTypeCreditAlphanum4, AssetCode4: &xdr.AssetCode4{'U', 'S', 'D'}}
+	allowTrust := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeAllowTrust,
+			AllowTrustOp: &xdr.AllowTrustOp{
+				Trustor: xdr.MustAddress(filterDestination),
+				Asset:   code,
+			},
+		},
+	}
+	keep, err = filter.Keep(20, filterTransaction(allowTrust))
+	assert.NoError(t, err)
+	assert.False(t, keep)
+
+	allowTrust.SourceAccount = &issuer
+	keep, err = filter.Keep(20, filterTransaction(allowTrust))
+	assert.NoError(t, err)
+	assert.True(t, keep)
+}
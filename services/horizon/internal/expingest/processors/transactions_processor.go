@@ -6,29 +6,83 @@ import (
 	"github.com/stellar/go/support/errors"
 )
 
+// TransactionCounter collects the numbers of trades and effects of the
+// transactions of a ledger as they are extracted by the TradeProcessor and
+// the EffectProcessor, so the TransactionProcessor stores them without
+// extracting them again. The counts of the records which are not ingested,
+// because their processor isn't run, are 0.
+type TransactionCounter struct {
+	counts map[uint32]history.TransactionCounts
+}
+
+// NewTransactionCounter returns an empty TransactionCounter.
+func NewTransactionCounter() *TransactionCounter {
+	return &TransactionCounter{counts: map[uint32]history.TransactionCounts{}}
+}
+
+// addTrades counts the trades of the transaction with the given index, it
+// does nothing on a nil counter.
+func (c *TransactionCounter) addTrades(index uint32, trades int) {
+	if c == nil {
+		return
+	}
+	counts := c.counts[index]
+	counts.Trades += int32(trades)
+	c.counts[index] = counts
+}
+
+// addEffects counts the effects of the transaction with the given index, it
+// does nothing on a nil counter.
+func (c *TransactionCounter) addEffects(index uint32, effects int) {
+	if c == nil {
+		return
+	}
+	counts := c.counts[index]
+	counts.Effects += int32(effects)
+	c.counts[index] = counts
+}
+
+func (c *TransactionCounter) get(index uint32) history.TransactionCounts {
+	if c == nil {
+		return history.TransactionCounts{}
+	}
+	return c.counts[index]
+}
+
 type TransactionProcessor struct {
 	transactionsQ history.QTransactions
 	sequence      uint32
+	counter       *TransactionCounter
+	transactions  []io.LedgerTransaction
 	batch         history.TransactionBatchInsertBuilder
 }
 
-func NewTransactionProcessor(transactionsQ history.QTransactions, sequence uint32) *TransactionProcessor {
+// NewTransactionProcessor returns a processor inserting the transactions of
+// the ledger sequence with the counts of their records collected by counter,
+// the counts are 0 when counter is nil.
+func NewTransactionProcessor(transactionsQ history.QTransactions, sequence uint32, counter *TransactionCounter) *TransactionProcessor {
 	return &TransactionProcessor{
 		transactionsQ: transactionsQ,
 		sequence:      sequence,
+		counter:       counter,
 		batch:         transactionsQ.NewTransactionBatchInsertBuilder(maxBatchSize),
 	}
 }
 
+// ProcessTransaction keeps transaction, the transactions are inserted on
+// Commit once the other processors counted their records.
 func (p *TransactionProcessor) ProcessTransaction(transaction io.LedgerTransaction) error {
-	if err := p.batch.Add(transaction, p.sequence); err != nil {
-		return errors.Wrap(err, "Error batch inserting transaction rows")
-	}
-
+	p.transactions = append(p.transactions, transaction)
 	return nil
 }
 
 func (p *TransactionProcessor) Commit() error {
+	for _, transaction := range p.transactions {
+		if err := p.batch.Add(transaction, p.sequence, p.counter.get(transaction.Index)); err != nil {
+			return errors.Wrap(err, "Error batch inserting transaction rows")
+		}
+	}
+
 	if err := p.batch.Exec(); err != nil {
 		return errors.Wrap(err, "Error flushing transaction batch")
 	}
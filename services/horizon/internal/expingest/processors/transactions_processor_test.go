@@ -6,12 +6,14 @@ import (
 
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/support/errors"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
 type TransactionsProcessorTestSuiteLedger struct {
 	suite.Suite
 	processor              *TransactionProcessor
+	counter                *TransactionCounter
 	mockQ                  *history.MockQTransactions
 	mockBatchInsertBuilder *history.MockTransactionsBatchInsertBuilder
 }
@@ -28,7 +30,8 @@ func (s *TransactionsProcessorTestSuiteLedger) SetupTest() {
 		On("NewTransactionBatchInsertBuilder", maxBatchSize).
 		Return(s.mockBatchInsertBuilder).Once()
 
-	s.processor = NewTransactionProcessor(s.mockQ, 20)
+	s.counter = NewTransactionCounter()
+	s.processor = NewTransactionProcessor(s.mockQ, 20, s.counter)
 }
 
 func (s *TransactionsProcessorTestSuiteLedger) TearDownTest() {
@@ -43,11 +46,10 @@ func (s *TransactionsProcessorTestSuiteLedger) TestAddTransactionsSucceeds() {
 	secondTx := createTransaction(false, 3)
 	thirdTx := createTransaction(true, 4)
 
-	s.mockBatchInsertBuilder.On("Add", firstTx, sequence).Return(nil).Once()
-	s.mockBatchInsertBuilder.On("Add", secondTx, sequence).Return(nil).Once()
-	s.mockBatchInsertBuilder.On("Add", thirdTx, sequence).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", firstTx, sequence, history.TransactionCounts{}).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", secondTx, sequence, history.TransactionCounts{}).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", thirdTx, sequence, history.TransactionCounts{}).Return(nil).Once()
 	s.mockBatchInsertBuilder.On("Exec").Return(nil).Once()
-	s.Assert().NoError(s.processor.Commit())
 
 	err := s.processor.ProcessTransaction(firstTx)
 	s.Assert().NoError(err)
@@ -57,15 +59,20 @@ func (s *TransactionsProcessorTestSuiteLedger) TestAddTransactionsSucceeds() {
 
 	err = s.processor.ProcessTransaction(thirdTx)
 	s.Assert().NoError(err)
+
+	s.Assert().NoError(s.processor.Commit())
 }
 
 func (s *TransactionsProcessorTestSuiteLedger) TestAddTransactionsFails() {
 	sequence := uint32(20)
 	firstTx := createTransaction(true, 1)
-	s.mockBatchInsertBuilder.On("Add", firstTx, sequence).
+	s.mockBatchInsertBuilder.On("Add", firstTx, sequence, history.TransactionCounts{}).
 		Return(errors.New("transient error")).Once()
 
 	err := s.processor.ProcessTransaction(firstTx)
+	s.Assert().NoError(err)
+
+	err = s.processor.Commit()
 	s.Assert().Error(err)
 	s.Assert().EqualError(err, "Error batch inserting transaction rows: transient error")
 }
@@ -74,7 +81,7 @@ func (s *TransactionsProcessorTestSuiteLedger) TestExecFails() {
 	sequence := uint32(20)
 	firstTx := createTransaction(true, 1)
 
-	s.mockBatchInsertBuilder.On("Add", firstTx, sequence).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", firstTx, sequence, history.TransactionCounts{}).Return(nil).Once()
 	s.mockBatchInsertBuilder.On("Exec").Return(errors.New("transient error")).Once()
 
 	err := s.processor.ProcessTransaction(firstTx)
@@ -84,3 +91,36 @@ func (s *TransactionsProcessorTestSuiteLedger) TestExecFails() {
 	s.Assert().Error(err)
 	s.Assert().EqualError(err, "Error flushing transaction batch: transient error")
 }
+
+func (s *TransactionsProcessorTestSuiteLedger) TestAddTransactionCounts() {
+	sequence := uint32(20)
+	firstTx := createTransaction(true, 1)
+	firstTx.Index = 1
+	secondTx := createTransaction(true, 1)
+	secondTx.Index = 2
+
+	// the counts reported by the trade and effect processors are stored,
+	// the transactions without reports have none
+	s.counter.addTrades(firstTx.Index, 1)
+	s.counter.addEffects(firstTx.Index, 2)
+	s.counter.addEffects(firstTx.Index, 1)
+
+	s.mockBatchInsertBuilder.On("Add", firstTx, sequence, history.TransactionCounts{
+		Trades:  1,
+		Effects: 3,
+	}).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", secondTx, sequence, history.TransactionCounts{}).
+		Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Exec").Return(nil).Once()
+
+	s.Assert().NoError(s.processor.ProcessTransaction(firstTx))
+	s.Assert().NoError(s.processor.ProcessTransaction(secondTx))
+	s.Assert().NoError(s.processor.Commit())
+}
+
+func TestNilTransactionCounter(t *testing.T) {
+	var counter *TransactionCounter
+	counter.addTrades(1, 1)
+	counter.addEffects(1, 1)
+	assert.Equal(t, history.TransactionCounts{}, counter.get(1))
+}
@@ -0,0 +1,282 @@
+package expingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reingestion statuses reported by ReingestProgress.
+const (
+	ReingestRunning   = "running"
+	ReingestSucceeded = "succeeded"
+	ReingestFailed    = "failed"
+)
+
+// Reingest worker statuses reported by ReingestProgress.
+const (
+	ReingestWorkerIdle    = "idle"
+	ReingestWorkerRunning = "running"
+	ReingestWorkerFailed  = "failed"
+)
+
+// ReingestWorkerStatus is the status of a single reingest worker.
+type ReingestWorkerStatus struct {
+	Worker              int    `json:"worker"`
+	Status              string `json:"status"`
+	FromLedger          uint32 `json:"from_ledger"`
+	ToLedger            uint32 `json:"to_ledger"`
+	LastCommittedLedger uint32 `json:"last_committed_ledger"`
+	Error               string `json:"error,omitempty"`
+}
+
+// ReingestProgressSnapshot is a point in time view of reingestion progress.
+type ReingestProgressSnapshot struct {
+	FromLedger          uint32                 `json:"from_ledger"`
+	ToLedger            uint32                 `json:"to_ledger"`
+	LedgersIngested     uint32                 `json:"ledgers_ingested"`
+	LastCommittedLedger uint32                 `json:"last_committed_ledger"`
+	LedgersPerSecond    float64                `json:"ledgers_per_second"`
+	ETASeconds          float64                `json:"eta_seconds"`
+	Workers             []ReingestWorkerStatus `json:"workers"`
+
+	// Status is ReingestRunning until Finish is called.
+	Status  string  `json:"status"`
+	Error   string  `json:"error,omitempty"`
+	Percent float64 `json:"percent"`
+}
+
+// progressBarWidth is the number of characters of the bar of ProgressLine.
+const progressBarWidth = 30
+
+// ProgressLine renders the snapshot as a single line with a progress bar,
+// for example:
+//
+//	[==========>                   ]  33.3% 100/300 ledgers, 10.0 ledgers/s, ETA 20s
+func (s ReingestProgressSnapshot) ProgressLine() string {
+	filled := int(s.Percent / 100 * progressBarWidth)
+	if filled > progressBarWidth {
+		filled = progressBarWidth
+	}
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+
+	line := fmt.Sprintf(
+		"[%s] %5.1f%% %d/%d ledgers, %.1f ledgers/s",
+		bar, s.Percent, s.LedgersIngested, s.ToLedger-s.FromLedger+1, s.LedgersPerSecond,
+	)
+	switch s.Status {
+	case ReingestRunning:
+		if s.ETASeconds > 0 {
+			eta := time.Duration(s.ETASeconds * float64(time.Second)).Round(time.Second)
+			line += fmt.Sprintf(", ETA %s", eta)
+		}
+	case ReingestFailed:
+		line += ", failed: " + s.Error
+	default:
+		line += ", " + s.Status
+	}
+	return line
+}
+
+// WriteFile writes the snapshot as JSON to path. The file is replaced
+// atomically so tools polling it never read a partial snapshot.
+func (s ReingestProgressSnapshot) WriteFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReingestProgress tracks progress of a range reingestion that can be run by
+// multiple workers in parallel. It is safe for concurrent use and all methods
+// are no-ops on a nil receiver so systems without a tracker don't need to
+// check for it.
+type ReingestProgress struct {
+	mutex      sync.Mutex
+	fromLedger uint32
+	toLedger   uint32
+	startTime  time.Time
+	// committed counts every ledger once, even if it's committed again
+	// when a range is retried or resumed
+	committed  ledgerRangeSet
+	lastLedger uint32
+	workers    []ReingestWorkerStatus
+	status     string
+	err        string
+	now        func() time.Time
+}
+
+// NewReingestProgress creates a tracker for reingesting the
+// [fromLedger, toLedger] range by workerCount workers.
+func NewReingestProgress(fromLedger, toLedger uint32, workerCount uint) *ReingestProgress {
+	return newReingestProgress(fromLedger, toLedger, workerCount, time.Now)
+}
+
+func newReingestProgress(fromLedger, toLedger uint32, workerCount uint, now func() time.Time) *ReingestProgress {
+	if workerCount < 1 {
+		workerCount = 1
+	}
+	p := &ReingestProgress{
+		fromLedger: fromLedger,
+		toLedger:   toLedger,
+		startTime:  now(),
+		workers:    make([]ReingestWorkerStatus, workerCount),
+		status:     ReingestRunning,
+		now:        now,
+	}
+	for i := range p.workers {
+		p.workers[i] = ReingestWorkerStatus{Worker: i, Status: ReingestWorkerIdle}
+	}
+	return p
+}
+
+// rangeStarted assigns the range to a worker. A range which was started
+// before (ex. when retrying) keeps its worker, otherwise the first worker not
+// running any range is used.
+func (p *ReingestProgress) rangeStarted(fromLedger, toLedger uint32) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	worker := p.workerFor(fromLedger, toLedger)
+	if worker == nil {
+		for i := range p.workers {
+			if p.workers[i].Status != ReingestWorkerRunning {
+				worker = &p.workers[i]
+				break
+			}
+		}
+	}
+	if worker == nil {
+		return
+	}
+
+	worker.Status = ReingestWorkerRunning
+	worker.FromLedger = fromLedger
+	worker.ToLedger = toLedger
+	worker.LastCommittedLedger = 0
+	worker.Error = ""
+}
+
+// ledgersCommitted records that the [fromLedger, toLedger] ledgers were
+// committed to the database.
+func (p *ReingestProgress) ledgersCommitted(fromLedger, toLedger uint32) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.committed.add(ledgerRange{fromLedger, toLedger})
+	if toLedger > p.lastLedger {
+		p.lastLedger = toLedger
+	}
+	for i := range p.workers {
+		worker := &p.workers[i]
+		if worker.Status == ReingestWorkerRunning &&
+			worker.FromLedger <= fromLedger && toLedger <= worker.ToLedger {
+			worker.LastCommittedLedger = toLedger
+		}
+	}
+}
+
+// rangeFinished releases the worker running the range.
+func (p *ReingestProgress) rangeFinished(fromLedger, toLedger uint32, err error) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	worker := p.workerFor(fromLedger, toLedger)
+	if worker == nil {
+		return
+	}
+	if err != nil {
+		worker.Status = ReingestWorkerFailed
+		worker.Error = err.Error()
+	} else {
+		worker.Status = ReingestWorkerIdle
+	}
+}
+
+// Finish records the result of the reingestion.
+func (p *ReingestProgress) Finish(err error) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err != nil {
+		p.status = ReingestFailed
+		p.err = err.Error()
+	} else {
+		p.status = ReingestSucceeded
+	}
+}
+
+func (p *ReingestProgress) workerFor(fromLedger, toLedger uint32) *ReingestWorkerStatus {
+	for i := range p.workers {
+		if p.workers[i].FromLedger == fromLedger && p.workers[i].ToLedger == toLedger {
+			return &p.workers[i]
+		}
+	}
+	return nil
+}
+
+// Snapshot returns the current progress. Ledgers per second is the average
+// since the tracker was created and ETA assumes the rate stays the same.
+func (p *ReingestProgress) Snapshot() ReingestProgressSnapshot {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	ingested := p.committed.count()
+	snapshot := ReingestProgressSnapshot{
+		FromLedger:          p.fromLedger,
+		ToLedger:            p.toLedger,
+		LedgersIngested:     ingested,
+		LastCommittedLedger: p.lastLedger,
+		Workers:             make([]ReingestWorkerStatus, len(p.workers)),
+		Status:              p.status,
+		Error:               p.err,
+	}
+	copy(snapshot.Workers, p.workers)
+
+	elapsed := p.now().Sub(p.startTime).Seconds()
+	if elapsed > 0 {
+		snapshot.LedgersPerSecond = float64(ingested) / elapsed
+	}
+
+	total := p.toLedger - p.fromLedger + 1
+	snapshot.Percent = 100 * float64(ingested) / float64(total)
+	if snapshot.LedgersPerSecond > 0 && ingested < total && p.status == ReingestRunning {
+		snapshot.ETASeconds = float64(total-ingested) / snapshot.LedgersPerSecond
+	}
+	return snapshot
+}
+
+// ServeHTTP renders the progress snapshot as JSON.
+func (p *ReingestProgress) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p.Snapshot()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,126 @@
+package expingest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReingestProgress(t *testing.T) {
+	now := time.Unix(1000, 0)
+	progress := newReingestProgress(1, 400, 2, func() time.Time { return now })
+
+	progress.rangeStarted(1, 200)
+	progress.rangeStarted(201, 400)
+	progress.ledgersCommitted(1, 1)
+	progress.ledgersCommitted(2, 2)
+	progress.ledgersCommitted(201, 298)
+
+	now = now.Add(10 * time.Second)
+	snapshot := progress.Snapshot()
+	assert.Equal(t, uint32(100), snapshot.LedgersIngested)
+	assert.Equal(t, uint32(298), snapshot.LastCommittedLedger)
+	assert.Equal(t, float64(10), snapshot.LedgersPerSecond)
+	assert.Equal(t, float64(30), snapshot.ETASeconds)
+	assert.Equal(t, []ReingestWorkerStatus{
+		{Worker: 0, Status: ReingestWorkerRunning, FromLedger: 1, ToLedger: 200, LastCommittedLedger: 2},
+		{Worker: 1, Status: ReingestWorkerRunning, FromLedger: 201, ToLedger: 400, LastCommittedLedger: 298},
+	}, snapshot.Workers)
+
+	progress.rangeFinished(1, 200, nil)
+	progress.rangeFinished(201, 400, errors.New("boom"))
+	snapshot = progress.Snapshot()
+	assert.Equal(t, ReingestWorkerIdle, snapshot.Workers[0].Status)
+	assert.Equal(t, ReingestWorkerFailed, snapshot.Workers[1].Status)
+	assert.Equal(t, "boom", snapshot.Workers[1].Error)
+
+	// retry of a failed range keeps its worker
+	progress.rangeStarted(201, 400)
+	snapshot = progress.Snapshot()
+	assert.Equal(t, ReingestWorkerRunning, snapshot.Workers[1].Status)
+	assert.Equal(t, "", snapshot.Workers[1].Error)
+
+	// ledgers committed again by the retry are not counted twice
+	progress.ledgersCommitted(201, 298)
+	progress.ledgersCommitted(299, 299)
+	snapshot = progress.Snapshot()
+	assert.Equal(t, uint32(101), snapshot.LedgersIngested)
+	assert.Equal(t, uint32(299), snapshot.LastCommittedLedger)
+
+	w := httptest.NewRecorder()
+	progress.ServeHTTP(w, httptest.NewRequest("GET", "/ingestion/progress", nil))
+	assert.Equal(t, 200, w.Code)
+	var decoded ReingestProgressSnapshot
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &decoded))
+	assert.Equal(t, snapshot, decoded)
+}
+
+func TestReingestProgressNil(t *testing.T) {
+	var progress *ReingestProgress
+	// must not panic
+	progress.rangeStarted(1, 10)
+	progress.ledgersCommitted(1, 1)
+	progress.rangeFinished(1, 10, nil)
+}
+
+func TestReingestProgressLine(t *testing.T) {
+	now := time.Unix(1000, 0)
+	progress := newReingestProgress(1, 300, 1, func() time.Time { return now })
+	progress.rangeStarted(1, 300)
+	progress.ledgersCommitted(1, 100)
+	now = now.Add(10 * time.Second)
+
+	snapshot := progress.Snapshot()
+	assert.Equal(t, ReingestRunning, snapshot.Status)
+	assert.InDelta(t, 33.3, snapshot.Percent, 0.1)
+	assert.Equal(
+		t,
+		"[==========>                   ]  33.3% 100/300 ledgers, 10.0 ledgers/s, ETA 20s",
+		snapshot.ProgressLine(),
+	)
+
+	progress.ledgersCommitted(101, 300)
+	progress.Finish(nil)
+	snapshot = progress.Snapshot()
+	assert.Equal(t, float64(100), snapshot.Percent)
+	assert.Equal(t, float64(0), snapshot.ETASeconds)
+	assert.Equal(
+		t,
+		"[==============================] 100.0% 300/300 ledgers, 30.0 ledgers/s, succeeded",
+		snapshot.ProgressLine(),
+	)
+
+	progress.Finish(errors.New("boom"))
+	snapshot = progress.Snapshot()
+	assert.Equal(t, ReingestFailed, snapshot.Status)
+	assert.Equal(t, "boom", snapshot.Error)
+	assert.Contains(t, snapshot.ProgressLine(), ", failed: boom")
+}
+
+func TestReingestProgressWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "progress")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "progress.json")
+
+	progress := NewReingestProgress(1, 10, 1)
+	progress.ledgersCommitted(1, 5)
+	snapshot := progress.Snapshot()
+	assert.NoError(t, snapshot.WriteFile(path))
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	var decoded ReingestProgressSnapshot
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, snapshot, decoded)
+
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,149 @@
+package expingest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/expingest/processors"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	logpkg "github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+)
+
+// RebuildTrades re-derives history_trades rows of ledgers in the
+// [fromLedger, toLedger] range (closed interval) from transactions and their
+// meta stored in the history database. Existing trades in the range are
+// removed first. All changes are done in a single DB transaction so the range
+// is either fully rebuilt or left untouched.
+func RebuildTrades(ctx context.Context, session *db.Session, fromLedger, toLedger uint32) error {
+	if fromLedger == 0 || fromLedger > toLedger {
+		return errors.Errorf("invalid range: [%d, %d]", fromLedger, toLedger)
+	}
+
+	historyQ := &history.Q{session.Clone()}
+	historyQ.Ctx = ctx
+
+	lastIngestedLedger, err := historyQ.GetLastLedgerExpIngestNonBlocking()
+	if err != nil {
+		return errors.Wrap(err, getLastIngestedErrMsg)
+	}
+	if toLedger > lastIngestedLedger {
+		return errors.Errorf(
+			"range end %d is greater than last ingested ledger %d",
+			toLedger, lastIngestedLedger,
+		)
+	}
+
+	if err = historyQ.Begin(); err != nil {
+		return errors.Wrap(err, "Error starting a transaction")
+	}
+	defer historyQ.Rollback()
+
+	start := toid.ID{LedgerSequence: int32(fromLedger)}.ToInt64()
+	end := toid.ID{LedgerSequence: int32(toLedger + 1)}.ToInt64()
+	if err = historyQ.DeleteRange(start, end, "history_trades", "history_operation_id"); err != nil {
+		return errors.Wrap(err, "Error clearing history_trades")
+	}
+
+	for sequence := fromLedger; sequence <= toLedger; sequence++ {
+		if err = rebuildLedgerTrades(ctx, historyQ, sequence); err != nil {
+			return errors.Wrapf(err, "could not rebuild trades of ledger %d", sequence)
+		}
+	}
+
+	if err = historyQ.Commit(); err != nil {
+		return errors.Wrap(err, commitErrMsg)
+	}
+
+	log.WithFields(logpkg.F{
+		"from": fromLedger,
+		"to":   toLedger,
+	}).Info("Rebuilt trades")
+	return nil
+}
+
+func rebuildLedgerTrades(ctx context.Context, historyQ *history.Q, sequence uint32) error {
+	var ledger history.Ledger
+	err := historyQ.LedgerBySequence(&ledger, int32(sequence))
+	if err == sql.ErrNoRows {
+		return errors.New("ledger not found in history")
+	} else if err != nil {
+		return errors.Wrap(err, "could not load ledger")
+	}
+
+	// The trade processor only needs the sequence and close time of a ledger.
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			LedgerSeq: xdr.Uint32(sequence),
+			ScpValue: xdr.StellarValue{
+				CloseTime: xdr.TimePoint(ledger.ClosedAt.Unix()),
+			},
+		},
+	}
+	processor := processors.NewTradeProcessor(historyQ, header, history.TradeConflictFail, nil)
+
+	page := db2.PageQuery{Order: db2.OrderAscending, Limit: db2.MaxPageSize}
+	for {
+		rows, err := historyQ.GetTransactions(ctx, history.TransactionsFilter{
+			LedgerSequence: int32(sequence),
+			IncludeFailed:  true,
+		}, page)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			transaction, err := ledgerTransactionFromRow(row)
+			if err != nil {
+				return errors.Wrapf(err, "could not decode transaction %s", row.TransactionHash)
+			}
+			if err = processor.ProcessTransaction(transaction); err != nil {
+				return errors.Wrapf(err, "could not process transaction %s", row.TransactionHash)
+			}
+		}
+
+		if uint64(len(rows)) < page.Limit {
+			break
+		}
+		page.Cursor = rows[len(rows)-1].PagingToken()
+	}
+
+	if err = processor.Commit(); err != nil {
+		return err
+	}
+	// the checksum of the trades of the ledger changed with them
+	return errors.Wrap(historyQ.UpdateLedgerManifest(sequence), "could not update ledger manifest")
+}
+
+// ledgerTransactionFromRow is the reverse of the conversion done when
+// inserting transactions into history_transactions.
+func ledgerTransactionFromRow(row history.Transaction) (io.LedgerTransaction, error) {
+	transaction := io.LedgerTransaction{Index: uint32(row.ApplicationOrder)}
+
+	if err := xdr.SafeUnmarshalBase64(row.TxEnvelope, &transaction.Envelope); err != nil {
+		return transaction, errors.Wrap(err, "invalid envelope")
+	}
+	if err := xdr.SafeUnmarshalBase64(row.TxResult, &transaction.Result.Result); err != nil {
+		return transaction, errors.Wrap(err, "invalid result")
+	}
+	if err := xdr.SafeUnmarshalBase64(row.TxMeta, &transaction.Meta); err != nil {
+		return transaction, errors.Wrap(err, "invalid meta")
+	}
+	if err := xdr.SafeUnmarshalBase64(row.TxFeeMeta, &transaction.FeeChanges); err != nil {
+		return transaction, errors.Wrap(err, "invalid fee meta")
+	}
+
+	hash, err := hex.DecodeString(row.TransactionHash)
+	if err != nil || len(hash) != len(transaction.Result.TransactionHash) {
+		return transaction, errors.New("invalid transaction hash")
+	}
+	copy(transaction.Result.TransactionHash[:], hash)
+
+	return transaction, nil
+}
@@ -0,0 +1,57 @@
+package expingest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestRebuildTrades(t *testing.T) {
+	tt := test.Start(t).Scenario("trades")
+	defer tt.Finish()
+	q := &history.Q{tt.HorizonSession()}
+
+	var latest int32
+	tt.Require.NoError(q.GetRaw(&latest, "SELECT MAX(sequence) FROM history_ledgers"))
+
+	// ingestion has not reached the range yet
+	err := RebuildTrades(context.Background(), tt.HorizonSession(), 1, uint32(latest))
+	tt.Assert.EqualError(err, "range end 11 is greater than last ingested ledger 0")
+	tt.Require.NoError(q.UpdateLastLedgerExpIngest(uint32(latest)))
+
+	err = RebuildTrades(context.Background(), tt.HorizonSession(), 5, 4)
+	tt.Assert.EqualError(err, "invalid range: [5, 4]")
+
+	selectTrades := func() []history.Trade {
+		var trades []history.Trade
+		err := q.Trades().Page(db2.MustPageQuery("", false, "asc", 100)).Select(tt.Ctx, &trades)
+		tt.Require.NoError(err)
+		return trades
+	}
+	expected := selectTrades()
+	tt.Require.Len(expected, 2)
+
+	// corrupt trades in the range
+	_, err = q.ExecRaw("UPDATE history_trades SET base_amount = 1")
+	tt.Require.NoError(err)
+	_, err = q.ExecRaw("INSERT INTO history_trades SELECT history_operation_id, \"order\" + 1, ledger_closed_at, offer_id, base_account_id, base_asset_id, base_amount, counter_account_id, counter_asset_id, counter_amount, base_is_seller, price_n, price_d, base_offer_id, counter_offer_id FROM history_trades")
+	tt.Require.NoError(err)
+
+	tt.Require.NoError(RebuildTrades(context.Background(), tt.HorizonSession(), 1, uint32(latest)))
+
+	rebuilt := selectTrades()
+	tt.Require.Len(rebuilt, len(expected))
+	for i := range expected {
+		tt.Assert.Equal(expected[i].HistoryOperationID, rebuilt[i].HistoryOperationID)
+		tt.Assert.Equal(expected[i].Order, rebuilt[i].Order)
+		tt.Assert.Equal(expected[i].BaseAccount, rebuilt[i].BaseAccount)
+		tt.Assert.Equal(expected[i].BaseAmount, rebuilt[i].BaseAmount)
+		tt.Assert.Equal(expected[i].CounterAccount, rebuilt[i].CounterAccount)
+		tt.Assert.Equal(expected[i].CounterAmount, rebuilt[i].CounterAmount)
+		tt.Assert.Equal(expected[i].BaseIsSeller, rebuilt[i].BaseIsSeller)
+		tt.Assert.True(expected[i].LedgerCloseTime.Equal(rebuilt[i].LedgerCloseTime))
+	}
+}
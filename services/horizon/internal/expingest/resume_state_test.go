@@ -3,11 +3,17 @@ package expingest
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stellar/go/exp/ingest/adapters"
 	"github.com/stellar/go/exp/ingest/io"
 	"github.com/stellar/go/exp/ingest/ledgerbackend"
+	"github.com/stellar/go/services/horizon/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 	"github.com/stretchr/testify/mock"
@@ -279,6 +285,8 @@ func (s *ResumeTestTestSuite) mockSuccessfulIngestion() {
 	s.runner.On("RunAllProcessorsOnLedger", uint32(102)).Return(io.StatsChangeProcessorResults{}, io.StatsLedgerTransactionProcessorResults{}, nil).Once()
 	s.historyQ.On("UpdateLastLedgerExpIngest", uint32(102)).Return(nil).Once()
 	s.historyQ.On("Commit").Return(nil).Once()
+	s.ledgerBackend.On("GetLedger", uint32(102)).
+		Return(true, xdr.LedgerCloseMeta{V0: &xdr.LedgerCloseMetaV0{}}, nil).Once()
 
 	s.stellarCoreClient.On(
 		"SetCursor",
@@ -301,6 +309,9 @@ func (s *ResumeTestTestSuite) TestBumpIngestLedger() {
 		},
 		next,
 	)
+	s.Assert().Equal(float64(1), testutil.ToFloat64(s.system.Metrics().LedgersIngestedCounter))
+	// stellar-core is at ledger 111
+	s.Assert().Equal(float64(9), testutil.ToFloat64(s.system.Metrics().LedgersBehindGauge))
 }
 
 func (s *ResumeTestTestSuite) TestBumpIngestLedgerWhenIngestLedgerEqualsLastLedgerExpIngest() {
@@ -331,6 +342,53 @@ func (s *ResumeTestTestSuite) TestIngestAllMasterNode() {
 	)
 }
 
+func (s *ResumeTestTestSuite) TestAssetWatchesNotified() {
+	var notifications []AssetWatchNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification AssetWatchNotification
+		s.Assert().NoError(json.NewDecoder(r.Body).Decode(&notification))
+		notifications = append(notifications, notification)
+	}))
+	defer server.Close()
+
+	s.system.config.EnableAssetWatches = true
+	s.mockSuccessfulIngestion()
+	s.historyQ.On("EvaluateAssetWatches", uint32(102)).Return([]history.AssetWatchEvent{
+		{
+			AssetWatch: history.AssetWatch{
+				ID:          1,
+				AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+				AssetCode:   "USD",
+				AssetIssuer: "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML",
+				Kind:        history.AssetWatchHolders,
+				Threshold:   10000,
+				WebhookURL:  server.URL,
+			},
+			Ledger: 102,
+			Value:  10001,
+		},
+	}, nil).Once()
+
+	next, err := resumeState{latestSuccessfullyProcessedLedger: 101}.run(s.system)
+	s.Assert().NoError(err)
+	s.Assert().Equal(resumeState{latestSuccessfullyProcessedLedger: 102}, next.node)
+
+	s.system.wg.Wait()
+	s.Assert().Equal([]AssetWatchNotification{
+		{
+			ID:          ingest.EventID(102, 1, "asset_watches"),
+			WatchID:     1,
+			AssetType:   "credit_alphanum4",
+			AssetCode:   "USD",
+			AssetIssuer: "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML",
+			Kind:        "holders",
+			Threshold:   10000,
+			Value:       10001,
+			Ledger:      102,
+		},
+	}, notifications)
+}
+
 func (s *ResumeTestTestSuite) TestErrorSettingCursorIgnored() {
 	s.historyQ.On("Begin").Return(nil).Once()
 	s.historyQ.On("GetLastLedgerExpIngest").Return(uint32(100), nil).Once()
@@ -343,6 +401,8 @@ func (s *ResumeTestTestSuite) TestErrorSettingCursorIgnored() {
 	s.runner.On("RunAllProcessorsOnLedger", uint32(101)).Return(io.StatsChangeProcessorResults{}, io.StatsLedgerTransactionProcessorResults{}, nil).Once()
 	s.historyQ.On("UpdateLastLedgerExpIngest", uint32(101)).Return(nil).Once()
 	s.historyQ.On("Commit").Return(nil).Once()
+	s.ledgerBackend.On("GetLedger", uint32(101)).
+		Return(true, xdr.LedgerCloseMeta{V0: &xdr.LedgerCloseMetaV0{}}, nil).Once()
 
 	s.stellarCoreClient.On(
 		"SetCursor",
@@ -0,0 +1,370 @@
+package expingest
+
+import (
+	"database/sql"
+	stdio "io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/expingest/processors"
+	"github.com/stellar/go/support/errors"
+	logpkg "github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+)
+
+// maxQuarantinedEntries is the maximum number of entries recorded by a state
+// audit, the audit keeps counting the mismatches above it.
+const maxQuarantinedEntries = 10000
+
+// quarantinedEntriesCountTTL is how long the number of quarantined entries is
+// cached by the state_quarantined_entries gauge. The audits are run by any
+// ingesting instance so the number is loaded from the database.
+const quarantinedEntriesCountTTL = time.Minute
+
+// checkpointPublicationDelay is the time given to stellar-core to publish
+// the checkpoint of the last ingested ledger before it's read.
+var checkpointPublicationDelay = 40 * time.Second
+
+var quarantineEntryTypes = map[xdr.LedgerEntryType]string{
+	xdr.LedgerEntryTypeAccount:   "account",
+	xdr.LedgerEntryTypeData:      "data",
+	xdr.LedgerEntryTypeOffer:     "offer",
+	xdr.LedgerEntryTypeTrustline: "trustline",
+}
+
+// stateAuditor compares the ingested state with a checkpoint like
+// verify.StateVerifier but records the entries which don't match instead of
+// stopping at the first one.
+type stateAuditor struct {
+	// expected are the entries of the current batch of the checkpoint, by
+	// base64 encoded ledger key, which were not written yet.
+	expected map[string]xdr.LedgerEntry
+	// counts are the numbers of entries of the checkpoint by type.
+	counts map[xdr.LedgerEntryType]int
+
+	entries    []history.QuarantinedEntry
+	mismatches int
+}
+
+func newStateAuditor() *stateAuditor {
+	return &stateAuditor{
+		expected: map[string]xdr.LedgerEntry{},
+		counts:   map[xdr.LedgerEntryType]int{},
+	}
+}
+
+// read reads up to count entries of the checkpoint and returns their keys.
+// The entries of the previous batch which were not written are quarantined as
+// missing.
+func (a *stateAuditor) read(reader io.ChangeReader, count int) ([]xdr.LedgerKey, error) {
+	if err := a.quarantineMissing(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]xdr.LedgerKey, 0, count)
+	for len(keys) < count {
+		change, err := reader.Read()
+		if err == stdio.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read checkpoint entry")
+		}
+
+		ignore, entry := transformEntry(*change.Post)
+		if ignore {
+			continue
+		}
+
+		key := entry.LedgerKey()
+		encodedKey, err := xdr.MarshalBase64(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not encode ledger key")
+		}
+		a.expected[encodedKey] = entry
+		a.counts[entry.Data.Type]++
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Write compares an ingested entry with the entry of the current batch of the
+// checkpoint.
+func (a *stateAuditor) Write(actual xdr.LedgerEntry) error {
+	encodedKey, err := xdr.MarshalBase64(actual.LedgerKey())
+	if err != nil {
+		return errors.Wrap(err, "could not encode ledger key")
+	}
+	encodedActual, err := xdr.MarshalBase64(actual)
+	if err != nil {
+		return errors.Wrap(err, "could not encode ingested entry")
+	}
+
+	entry := history.QuarantinedEntry{
+		EntryType: quarantineEntryTypes[actual.Data.Type],
+		LedgerKey: encodedKey,
+		Reason:    history.QuarantineMismatch,
+		Actual:    null.StringFrom(encodedActual),
+	}
+
+	expected, ok := a.expected[encodedKey]
+	if !ok {
+		a.quarantine(entry)
+		return nil
+	}
+	delete(a.expected, encodedKey)
+
+	encodedExpected, err := xdr.MarshalBase64(expected)
+	if err != nil {
+		return errors.Wrap(err, "could not encode checkpoint entry")
+	}
+	if encodedExpected != encodedActual {
+		entry.Expected = null.StringFrom(encodedExpected)
+		a.quarantine(entry)
+	}
+	return nil
+}
+
+// quarantineMissing quarantines the entries of the current batch which were
+// not written, in the order of their keys.
+func (a *stateAuditor) quarantineMissing() error {
+	keys := make([]string, 0, len(a.expected))
+	for key := range a.expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		expected := a.expected[key]
+		encodedExpected, err := xdr.MarshalBase64(expected)
+		if err != nil {
+			return errors.Wrap(err, "could not encode checkpoint entry")
+		}
+		a.quarantine(history.QuarantinedEntry{
+			EntryType: quarantineEntryTypes[expected.Data.Type],
+			LedgerKey: key,
+			Reason:    history.QuarantineMissing,
+			Expected:  null.StringFrom(encodedExpected),
+		})
+	}
+	a.expected = map[string]xdr.LedgerEntry{}
+	return nil
+}
+
+// checkCount quarantines entryType if the number of ingested entries of the
+// type differs from the checkpoint.
+func (a *stateAuditor) checkCount(entryType xdr.LedgerEntryType, actual int) {
+	if expected := a.counts[entryType]; expected != actual {
+		a.quarantine(history.QuarantinedEntry{
+			EntryType: quarantineEntryTypes[entryType],
+			Reason:    history.QuarantineCount,
+			Expected:  null.StringFrom(strconv.Itoa(expected)),
+			Actual:    null.StringFrom(strconv.Itoa(actual)),
+		})
+	}
+}
+
+func (a *stateAuditor) quarantine(entry history.QuarantinedEntry) {
+	a.mismatches++
+	if len(a.entries) < maxQuarantinedEntries {
+		a.entries = append(a.entries, entry)
+	}
+}
+
+// RequestStateAudit requests an audit of the state at the next checkpoint
+// ledger ingested by the live ingestion. The entries which don't match the
+// checkpoint replace the quarantined entries of the previous audit. Unlike
+// the state verification the audit doesn't mark the state as invalid.
+func (s *system) RequestStateAudit() {
+	s.stateAuditMutex.Lock()
+	defer s.stateAuditMutex.Unlock()
+	s.stateAuditRequested = true
+}
+
+// maybeAuditState starts the requested state audit in a go routine if
+// lastIngestedLedger is a checkpoint ledger.
+func (s *system) maybeAuditState(lastIngestedLedger uint32) {
+	if !historyarchive.IsCheckpoint(lastIngestedLedger) {
+		return
+	}
+
+	s.stateAuditMutex.Lock()
+	defer s.stateAuditMutex.Unlock()
+	if s.stateAuditRunning || !s.stateAuditRequested {
+		return
+	}
+	s.stateAuditRequested = false
+	s.stateAuditRunning = true
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		audited, err := s.auditState()
+		if err != nil && !isCancelledError(err) {
+			log.WithField("err", err).Error("State audit errored")
+		}
+
+		s.stateAuditMutex.Lock()
+		defer s.stateAuditMutex.Unlock()
+		s.stateAuditRunning = false
+		if !audited && err == nil {
+			// wait for the next checkpoint
+			s.stateAuditRequested = true
+		}
+	}()
+}
+
+// auditState compares the state with the checkpoint of the last ingested
+// ledger and quarantines the entries which don't match. It returns false if
+// the last ingested ledger is not a checkpoint anymore.
+func (s *system) auditState() (bool, error) {
+	startTime := time.Now()
+	historyQ := s.historyQ.CloneIngestionQ()
+	defer historyQ.Rollback()
+
+	err := historyQ.BeginTx(&sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "Error starting transaction")
+	}
+
+	ledgerSequence, err := historyQ.GetLastLedgerExpIngestNonBlocking()
+	if err != nil {
+		return false, errors.Wrap(err, "Error running historyQ.GetLastLedgerExpIngestNonBlocking")
+	}
+	if !historyarchive.IsCheckpoint(ledgerSequence) {
+		return false, nil
+	}
+
+	localLog := log.WithFields(logpkg.F{
+		"subservice": "state_audit",
+		"ledger":     ledgerSequence,
+	})
+	localLog.Info("Starting state audit. Waiting for stellar-core to publish HAS...")
+	select {
+	case <-s.ctx.Done():
+		return false, s.ctx.Err()
+	case <-time.After(checkpointPublicationDelay):
+	}
+
+	stateReader, err := s.historyAdapter.GetState(s.ctx, ledgerSequence)
+	if err != nil {
+		return false, errors.Wrap(err, "Error running GetState")
+	}
+	defer stateReader.Close()
+
+	auditor := newStateAuditor()
+	for {
+		var keys []xdr.LedgerKey
+		keys, err = auditor.read(stateReader, verifyBatchSize)
+		if err != nil {
+			return false, err
+		}
+		if len(keys) == 0 {
+			break
+		}
+
+		accounts := make([]string, 0, len(keys))
+		data := make([]xdr.LedgerKeyData, 0, len(keys))
+		offers := make([]int64, 0, len(keys))
+		trustLines := make([]xdr.LedgerKeyTrustLine, 0, len(keys))
+		for _, key := range keys {
+			switch key.Type {
+			case xdr.LedgerEntryTypeAccount:
+				accounts = append(accounts, key.Account.AccountId.Address())
+			case xdr.LedgerEntryTypeData:
+				data = append(data, *key.Data)
+			case xdr.LedgerEntryTypeOffer:
+				offers = append(offers, int64(key.Offer.OfferId))
+			case xdr.LedgerEntryTypeTrustline:
+				trustLines = append(trustLines, *key.TrustLine)
+			}
+		}
+
+		if err = addAccountsToStateVerifier(auditor, historyQ, accounts); err != nil {
+			return false, errors.Wrap(err, "addAccountsToStateVerifier failed")
+		}
+		if err = addDataToStateVerifier(auditor, historyQ, data); err != nil {
+			return false, errors.Wrap(err, "addDataToStateVerifier failed")
+		}
+		if err = addOffersToStateVerifier(auditor, historyQ, offers); err != nil {
+			return false, errors.Wrap(err, "addOffersToStateVerifier failed")
+		}
+		// asset stats are checked by the state verification
+		err = addTrustLinesToStateVerifier(auditor, processors.AssetStatSet{}, historyQ, trustLines)
+		if err != nil {
+			return false, errors.Wrap(err, "addTrustLinesToStateVerifier failed")
+		}
+	}
+
+	counts := []struct {
+		entryType xdr.LedgerEntryType
+		count     func() (int, error)
+	}{
+		{xdr.LedgerEntryTypeAccount, historyQ.CountAccounts},
+		{xdr.LedgerEntryTypeData, historyQ.CountAccountsData},
+		{xdr.LedgerEntryTypeOffer, historyQ.CountOffers},
+		{xdr.LedgerEntryTypeTrustline, historyQ.CountTrustLines},
+	}
+	for _, c := range counts {
+		var count int
+		if count, err = c.count(); err != nil {
+			return false, errors.Wrapf(err, "could not count %s entries", quarantineEntryTypes[c.entryType])
+		}
+		auditor.checkCount(c.entryType, count)
+	}
+
+	quarantineQ := s.historyQ.CloneIngestionQ()
+	if err = quarantineQ.Begin(); err != nil {
+		return false, errors.Wrap(err, "Error starting transaction")
+	}
+	defer quarantineQ.Rollback()
+	if err = quarantineQ.ReplaceQuarantinedEntries(ledgerSequence, auditor.entries); err != nil {
+		return false, err
+	}
+	if err = quarantineQ.Commit(); err != nil {
+		return false, errors.Wrap(err, "Error committing quarantined entries")
+	}
+	s.setQuarantinedEntries(len(auditor.entries))
+
+	localLog.WithFields(logpkg.F{
+		"mismatches": auditor.mismatches,
+		"duration":   time.Since(startTime).Seconds(),
+	}).Info("State audit finished")
+	return true, nil
+}
+
+// countQuarantinedEntries returns the number of entries quarantined by the
+// last state audit, it's loaded at most once per quarantinedEntriesCountTTL.
+func (s *system) countQuarantinedEntries() int {
+	s.quarantinedEntriesMutex.Lock()
+	defer s.quarantinedEntriesMutex.Unlock()
+	if time.Since(s.quarantinedEntriesLoadedAt) < quarantinedEntriesCountTTL {
+		return s.quarantinedEntries
+	}
+
+	count, err := s.historyQ.CloneIngestionQ().CountQuarantinedEntries()
+	if err != nil {
+		log.WithError(err).Error("Error counting quarantined entries")
+		return s.quarantinedEntries
+	}
+	s.quarantinedEntries = count
+	s.quarantinedEntriesLoadedAt = time.Now()
+	return count
+}
+
+// setQuarantinedEntries records the number of entries quarantined by a state
+// audit.
+func (s *system) setQuarantinedEntries(count int) {
+	s.quarantinedEntriesMutex.Lock()
+	defer s.quarantinedEntriesMutex.Unlock()
+	s.quarantinedEntries = count
+	s.quarantinedEntriesLoadedAt = time.Now()
+}
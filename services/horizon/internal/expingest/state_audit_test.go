@@ -0,0 +1,237 @@
+package expingest
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stellar/go/exp/ingest/adapters"
+	ingestio "github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+func auditAccountEntry(balance xdr.Int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{
+				AccountId:  issuer,
+				Balance:    balance,
+				Thresholds: [4]byte{1, 0, 0, 0},
+			},
+		},
+		LastModifiedLedgerSeq: xdr.Uint32(62),
+	}
+}
+
+func auditOfferEntry() xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type:  xdr.LedgerEntryTypeOffer,
+			Offer: &eurOffer,
+		},
+		LastModifiedLedgerSeq: xdr.Uint32(62),
+	}
+}
+
+func TestStateAuditor(t *testing.T) {
+	reader := &ingestio.MockChangeReader{}
+	account, offer := auditAccountEntry(600), auditOfferEntry()
+	reader.On("Read").Return(ingestio.Change{Type: xdr.LedgerEntryTypeAccount, Post: &account}, nil).Once()
+	reader.On("Read").Return(ingestio.Change{Type: xdr.LedgerEntryTypeOffer, Post: &offer}, nil).Once()
+	reader.On("Read").Return(ingestio.Change{}, io.EOF).Twice()
+
+	auditor := newStateAuditor()
+	keys, err := auditor.read(reader, 10)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	// the account is ingested with another balance, the offer is missing
+	_, expectedAccount := transformEntry(auditAccountEntry(600))
+	_, actualAccount := transformEntry(auditAccountEntry(500))
+	assert.NoError(t, auditor.Write(actualAccount))
+
+	keys, err = auditor.read(reader, 10)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 0)
+
+	auditor.checkCount(xdr.LedgerEntryTypeAccount, 1)
+	auditor.checkCount(xdr.LedgerEntryTypeOffer, 0)
+
+	accountKey, err := xdr.MarshalBase64(account.LedgerKey())
+	assert.NoError(t, err)
+	offerKey, err := xdr.MarshalBase64(offer.LedgerKey())
+	assert.NoError(t, err)
+	encodedExpectedAccount, err := xdr.MarshalBase64(expectedAccount)
+	assert.NoError(t, err)
+	encodedActualAccount, err := xdr.MarshalBase64(actualAccount)
+	assert.NoError(t, err)
+	encodedOffer, err := xdr.MarshalBase64(offer)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []history.QuarantinedEntry{
+		{
+			EntryType: "account",
+			LedgerKey: accountKey,
+			Reason:    history.QuarantineMismatch,
+			Expected:  null.StringFrom(encodedExpectedAccount),
+			Actual:    null.StringFrom(encodedActualAccount),
+		},
+		{
+			EntryType: "offer",
+			LedgerKey: offerKey,
+			Reason:    history.QuarantineMissing,
+			Expected:  null.StringFrom(encodedOffer),
+		},
+		{
+			EntryType: "offer",
+			Reason:    history.QuarantineCount,
+			Expected:  null.StringFrom("1"),
+			Actual:    null.StringFrom("0"),
+		},
+	}, auditor.entries)
+	assert.Equal(t, 3, auditor.mismatches)
+	reader.AssertExpectations(t)
+}
+
+func TestStateAuditorMatchingEntry(t *testing.T) {
+	reader := &ingestio.MockChangeReader{}
+	account := auditAccountEntry(600)
+	reader.On("Read").Return(ingestio.Change{Type: xdr.LedgerEntryTypeAccount, Post: &account}, nil).Once()
+	reader.On("Read").Return(ingestio.Change{}, io.EOF).Twice()
+
+	auditor := newStateAuditor()
+	_, err := auditor.read(reader, 10)
+	assert.NoError(t, err)
+	_, actual := transformEntry(auditAccountEntry(600))
+	assert.NoError(t, auditor.Write(actual))
+	_, err = auditor.read(reader, 10)
+	assert.NoError(t, err)
+	auditor.checkCount(xdr.LedgerEntryTypeAccount, 1)
+
+	assert.Empty(t, auditor.entries)
+	assert.Equal(t, 0, auditor.mismatches)
+}
+
+func TestAuditState(t *testing.T) {
+	defer func(delay time.Duration) { checkpointPublicationDelay = delay }(checkpointPublicationDelay)
+	checkpointPublicationDelay = 0
+
+	historyQ := &mockDBQ{}
+	historyAdapter := &adapters.MockHistoryArchiveAdapter{}
+	system := &system{
+		ctx:            context.Background(),
+		historyQ:       historyQ,
+		historyAdapter: historyAdapter,
+	}
+
+	readQ, writeQ := &mockDBQ{}, &mockDBQ{}
+	historyQ.On("CloneIngestionQ").Return(readQ).Once()
+	historyQ.On("CloneIngestionQ").Return(writeQ).Once()
+
+	readQ.On("BeginTx", &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}).Return(nil).Once()
+	readQ.On("Rollback").Return(nil).Once()
+	readQ.On("GetLastLedgerExpIngestNonBlocking").Return(uint32(63), nil).Once()
+
+	reader := &ingestio.MockChangeReader{}
+	account := auditAccountEntry(600)
+	reader.On("Read").Return(ingestio.Change{Type: xdr.LedgerEntryTypeAccount, Post: &account}, nil).Once()
+	reader.On("Read").Return(ingestio.Change{}, io.EOF).Twice()
+	reader.On("Close").Return(nil).Once()
+	historyAdapter.On("GetState", mock.Anything, uint32(63)).Return(reader, nil).Once()
+
+	accountID := issuer.Address()
+	readQ.MockQAccounts.On("GetAccountsByIDs", []string{accountID}).Return([]history.AccountEntry{{
+		AccountID:          accountID,
+		Balance:            500,
+		LastModifiedLedger: 62,
+		MasterWeight:       1,
+	}}, nil).Once()
+	readQ.MockQSigners.On("SignersForAccounts", []string{accountID}).
+		Return([]history.AccountSigner{{Account: accountID, Signer: accountID, Weight: 1}}, nil).Once()
+	readQ.MockQSigners.On("CountAccounts").Return(1, nil).Once()
+	readQ.MockQData.On("CountAccountsData").Return(0, nil).Once()
+	readQ.MockQOffers.On("CountOffers").Return(0, nil).Once()
+	readQ.MockQAssetStats.On("CountTrustLines").Return(0, nil).Once()
+
+	writeQ.On("Begin").Return(nil).Once()
+	writeQ.On("ReplaceQuarantinedEntries", uint32(63), mock.Anything).Run(func(args mock.Arguments) {
+		entries := args.Get(1).([]history.QuarantinedEntry)
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, "account", entries[0].EntryType)
+			assert.Equal(t, history.QuarantineMismatch, entries[0].Reason)
+		}
+	}).Return(nil).Once()
+	writeQ.On("Commit").Return(nil).Once()
+	writeQ.On("Rollback").Return(nil).Once()
+
+	system.RequestStateAudit()
+
+	// the audit waits for a checkpoint ledger
+	system.maybeAuditState(62)
+	assert.True(t, system.stateAuditRequested)
+	system.maybeAuditState(63)
+	system.wg.Wait()
+
+	assert.False(t, system.stateAuditRequested)
+	assert.False(t, system.stateAuditRunning)
+	// the number of quarantined entries is known without counting them
+	assert.Equal(t, 1, system.countQuarantinedEntries())
+	historyQ.AssertExpectations(t)
+	readQ.AssertExpectations(t)
+	writeQ.AssertExpectations(t)
+	historyAdapter.AssertExpectations(t)
+	reader.AssertExpectations(t)
+}
+
+func TestAuditStateWaitsForNextCheckpoint(t *testing.T) {
+	historyQ := &mockDBQ{}
+	system := &system{ctx: context.Background(), historyQ: historyQ}
+
+	// a ledger was ingested after the checkpoint
+	readQ := &mockDBQ{}
+	historyQ.On("CloneIngestionQ").Return(readQ).Once()
+	readQ.On("BeginTx", mock.Anything).Return(nil).Once()
+	readQ.On("Rollback").Return(nil).Once()
+	readQ.On("GetLastLedgerExpIngestNonBlocking").Return(uint32(64), nil).Once()
+
+	system.RequestStateAudit()
+	system.maybeAuditState(63)
+	system.wg.Wait()
+	assert.True(t, system.stateAuditRequested)
+	assert.False(t, system.stateAuditRunning)
+
+	historyQ.AssertExpectations(t)
+	readQ.AssertExpectations(t)
+}
+
+func TestCountQuarantinedEntries(t *testing.T) {
+	historyQ := &mockDBQ{}
+	system := &system{historyQ: historyQ}
+
+	countQ := &mockDBQ{}
+	historyQ.On("CloneIngestionQ").Return(countQ).Twice()
+	countQ.On("CountQuarantinedEntries").Return(0, errors.New("db error")).Once()
+	countQ.On("CountQuarantinedEntries").Return(3, nil).Once()
+
+	assert.Equal(t, 0, system.countQuarantinedEntries())
+	// the count is loaded again after an error, then cached
+	assert.Equal(t, 3, system.countQuarantinedEntries())
+	assert.Equal(t, 3, system.countQuarantinedEntries())
+
+	system.quarantinedEntriesLoadedAt = time.Now().Add(-quarantinedEntriesCountTTL)
+	historyQ.On("CloneIngestionQ").Return(countQ).Once()
+	countQ.On("CountQuarantinedEntries").Return(5, nil).Once()
+	assert.Equal(t, 5, system.countQuarantinedEntries())
+
+	historyQ.AssertExpectations(t)
+	countQ.AssertExpectations(t)
+}
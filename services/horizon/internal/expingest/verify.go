@@ -7,6 +7,7 @@ import (
 	"time"
 
 	ingesterrors "github.com/stellar/go/exp/ingest/errors"
+	ingestio "github.com/stellar/go/exp/ingest/io"
 	"github.com/stellar/go/exp/ingest/verify"
 	"github.com/stellar/go/historyarchive"
 	"github.com/stellar/go/services/horizon/internal/db2"
@@ -20,6 +21,13 @@ import (
 const verifyBatchSize = 50000
 const assetStatsBatchSize = 500
 
+// ledgerEntryWriter receives the ledger entries loaded from the database by
+// the add*ToStateVerifier functions, it's implemented by verify.StateVerifier
+// and stateAuditor.
+type ledgerEntryWriter interface {
+	Write(entry xdr.LedgerEntry) error
+}
+
 // stateVerifierExpectedIngestionVersion defines a version of ingestion system
 // required by state verifier. This is done to prevent situations where
 // ingestion has been updated with new features but state verifier does not
@@ -119,6 +127,11 @@ func (s *system) verifyState(verifyAgainstLatestCheckpoint bool) error {
 		}
 	}
 
+	issuers, err := loadAssetStatsIssuers(s.ctx, historyQ)
+	if err != nil {
+		return errors.Wrap(err, "Error loading the issuers of asset stats")
+	}
+
 	localLog.Info("Creating state reader...")
 
 	stateReader, err := s.historyAdapter.GetState(s.ctx, ledgerSequence)
@@ -128,7 +141,7 @@ func (s *system) verifyState(verifyAgainstLatestCheckpoint bool) error {
 	defer stateReader.Close()
 
 	verifier := &verify.StateVerifier{
-		StateReader:       stateReader,
+		StateReader:       &issuersReader{ChangeReader: stateReader, issuers: issuers},
 		TransformFunction: transformEntry,
 	}
 
@@ -215,7 +228,7 @@ func (s *system) verifyState(verifyAgainstLatestCheckpoint bool) error {
 		return errors.Wrap(err, "verifier.Verify failed")
 	}
 
-	err = checkAssetStats(assetStats, historyQ)
+	err = checkAssetStats(s.ctx, assetStats, issuers, historyQ)
 	if err != nil {
 		return errors.Wrap(err, "checkAssetStats failed")
 	}
@@ -225,14 +238,72 @@ func (s *system) verifyState(verifyAgainstLatestCheckpoint bool) error {
 	return nil
 }
 
-func checkAssetStats(set processors.AssetStatSet, q history.IngestionQ) error {
+// issuerFields are the fields of an issuer account copied to the stats of
+// its assets.
+type issuerFields struct {
+	flags      uint32
+	homeDomain string
+}
+
+// issuersReader records the fields of the issuers in issuers when their
+// accounts are read from the checkpoint state. The issuers are the ones of
+// the asset stats in the db: the stats of assets of other issuers are missing
+// from the db and don't match anyway.
+type issuersReader struct {
+	ingestio.ChangeReader
+	issuers map[string]issuerFields
+}
+
+func (r *issuersReader) Read() (ingestio.Change, error) {
+	change, err := r.ChangeReader.Read()
+	if err != nil || change.Post == nil || change.Post.Data.Type != xdr.LedgerEntryTypeAccount {
+		return change, err
+	}
+
+	account := change.Post.Data.MustAccount()
+	address := account.AccountId.Address()
+	if _, ok := r.issuers[address]; ok {
+		r.issuers[address] = issuerFields{
+			flags:      uint32(account.Flags),
+			homeDomain: string(account.HomeDomain),
+		}
+	}
+	return change, nil
+}
+
+// loadAssetStatsIssuers returns the issuers of the asset stats in the db,
+// with empty fields like the stats of issuers without an account.
+func loadAssetStatsIssuers(ctx context.Context, q history.IngestionQ) (map[string]issuerFields, error) {
+	page := db2.PageQuery{
+		Order: "asc",
+		Limit: assetStatsBatchSize,
+	}
+
+	issuers := map[string]issuerFields{}
+	for {
+		assetStats, err := q.GetAssetStats(ctx, history.AssetStatsFilter{}, page)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not fetch asset stats from db")
+		}
+		if len(assetStats) == 0 {
+			return issuers, nil
+		}
+
+		for _, assetStat := range assetStats {
+			issuers[assetStat.AssetIssuer] = issuerFields{}
+		}
+		page.Cursor = assetStats[len(assetStats)-1].PagingToken()
+	}
+}
+
+func checkAssetStats(ctx context.Context, set processors.AssetStatSet, issuers map[string]issuerFields, q history.IngestionQ) error {
 	page := db2.PageQuery{
 		Order: "asc",
 		Limit: assetStatsBatchSize,
 	}
 
 	for {
-		assetStats, err := q.GetAssetStats("", "", page)
+		assetStats, err := q.GetAssetStats(ctx, history.AssetStatsFilter{}, page)
 		if err != nil {
 			return errors.Wrap(err, "could not fetch asset stats from db")
 		}
@@ -251,6 +322,8 @@ func checkAssetStats(set processors.AssetStatSet, q history.IngestionQ) error {
 				)
 			}
 
+			issuer := issuers[assetStat.AssetIssuer]
+			fromSet.Flags, fromSet.HomeDomain = issuer.flags, issuer.homeDomain
 			if fromSet != assetStat {
 				return ingesterrors.NewStateError(
 					fmt.Errorf(
@@ -275,7 +348,7 @@ func checkAssetStats(set processors.AssetStatSet, q history.IngestionQ) error {
 	return nil
 }
 
-func addAccountsToStateVerifier(verifier *verify.StateVerifier, q history.IngestionQ, ids []string) error {
+func addAccountsToStateVerifier(verifier ledgerEntryWriter, q history.IngestionQ, ids []string) error {
 	if len(ids) == 0 {
 		return nil
 	}
@@ -368,7 +441,7 @@ func addAccountsToStateVerifier(verifier *verify.StateVerifier, q history.Ingest
 	return nil
 }
 
-func addDataToStateVerifier(verifier *verify.StateVerifier, q history.IngestionQ, keys []xdr.LedgerKeyData) error {
+func addDataToStateVerifier(verifier ledgerEntryWriter, q history.IngestionQ, keys []xdr.LedgerKeyData) error {
 	if len(keys) == 0 {
 		return nil
 	}
@@ -400,7 +473,7 @@ func addDataToStateVerifier(verifier *verify.StateVerifier, q history.IngestionQ
 }
 
 func addOffersToStateVerifier(
-	verifier *verify.StateVerifier,
+	verifier ledgerEntryWriter,
 	q history.IngestionQ,
 	ids []int64,
 ) error {
@@ -443,7 +516,7 @@ func addOffersToStateVerifier(
 }
 
 func addTrustLinesToStateVerifier(
-	verifier *verify.StateVerifier,
+	verifier ledgerEntryWriter,
 	assetStats processors.AssetStatSet,
 	q history.IngestionQ,
 	keys []xdr.LedgerKeyTrustLine,
@@ -257,10 +257,10 @@ func (s *VerifyRangeStateTestSuite) TestSuccessWithVerify() {
 	// TODO: add accounts data, trustlines and asset stats
 	clonedQ.MockQData.On("CountAccountsData").Return(0, nil).Once()
 	clonedQ.MockQAssetStats.On("CountTrustLines").Return(0, nil).Once()
-	clonedQ.MockQAssetStats.On("GetAssetStats", "", "", db2.PageQuery{
+	clonedQ.MockQAssetStats.On("GetAssetStats", mock.Anything, history.AssetStatsFilter{}, db2.PageQuery{
 		Order: "asc",
 		Limit: assetStatsBatchSize,
-	}).Return([]history.ExpAssetStat{}, nil).Once()
+	}).Return([]history.ExpAssetStat{}, nil).Twice()
 
 	next, err := verifyRangeState{
 		fromLedger: 100, toLedger: 110, verifyState: true,
@@ -0,0 +1,91 @@
+package expingest
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	ingesterrors "github.com/stellar/go/exp/ingest/errors"
+	ingestio "github.com/stellar/go/exp/ingest/io"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/expingest/processors"
+	"github.com/stellar/go/xdr"
+)
+
+func TestCheckAssetStatsIssuerFields(t *testing.T) {
+	issuer := "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+	assetStat := history.ExpAssetStat{
+		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+		AssetCode:   "USD",
+		AssetIssuer: issuer,
+		Amount:      "100",
+		NumAccounts: 1,
+		Flags:       uint32(xdr.AccountFlagsAuthRequiredFlag),
+		HomeDomain:  "example.com",
+	}
+
+	q := &mockDBQ{}
+	page := db2.PageQuery{Order: "asc", Limit: assetStatsBatchSize}
+	q.MockQAssetStats.On("GetAssetStats", mock.Anything, history.AssetStatsFilter{}, page).
+		Return([]history.ExpAssetStat{assetStat}, nil).Once()
+	page.Cursor = assetStat.PagingToken()
+	q.MockQAssetStats.On("GetAssetStats", mock.Anything, history.AssetStatsFilter{}, page).
+		Return([]history.ExpAssetStat{}, nil).Once()
+
+	issuers, err := loadAssetStatsIssuers(context.Background(), q)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]issuerFields{issuer: {}}, issuers)
+	q.AssertExpectations(t)
+
+	changeReader := &ingestio.MockChangeReader{}
+	changeReader.On("Read").Return(ingestio.Change{
+		Type: xdr.LedgerEntryTypeAccount,
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId:  xdr.MustAddress(issuer),
+					Flags:      xdr.Uint32(xdr.AccountFlagsAuthRequiredFlag),
+					HomeDomain: "example.com",
+				},
+			},
+		},
+	}, nil).Once()
+	changeReader.On("Read").Return(ingestio.Change{}, io.EOF).Once()
+
+	reader := &issuersReader{ChangeReader: changeReader, issuers: issuers}
+	_, err = reader.Read()
+	assert.NoError(t, err)
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+
+	newSet := func() processors.AssetStatSet {
+		set := processors.AssetStatSet{}
+		assert.NoError(t, set.Add(xdr.TrustLineEntry{
+			AccountId: xdr.MustAddress("GAXMF43TGZHW3QN3REOUA2U5PW5BTARXGGYJ3JIFHW3YT6QRKRL3CPPU"),
+			Asset:     xdr.MustNewCreditAsset("USD", issuer),
+			Balance:   100,
+			Flags:     xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+		}))
+		return set
+	}
+
+	page.Cursor = ""
+	q.MockQAssetStats.On("GetAssetStats", mock.Anything, history.AssetStatsFilter{}, page).
+		Return([]history.ExpAssetStat{assetStat}, nil).Twice()
+	page.Cursor = assetStat.PagingToken()
+	q.MockQAssetStats.On("GetAssetStats", mock.Anything, history.AssetStatsFilter{}, page).
+		Return([]history.ExpAssetStat{}, nil).Once()
+
+	assert.NoError(t, checkAssetStats(context.Background(), newSet(), reader.issuers, q))
+
+	// the denormalized issuer fields must match the checkpoint
+	reader.issuers[issuer] = issuerFields{flags: uint32(xdr.AccountFlagsAuthRevocableFlag)}
+	err = checkAssetStats(context.Background(), newSet(), reader.issuers, q)
+	assert.IsType(t, ingesterrors.StateError{}, err)
+	q.AssertExpectations(t)
+}
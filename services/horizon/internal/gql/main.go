@@ -0,0 +1,183 @@
+// Package gql implements the optional GraphQL end-point of horizon. Its schema
+// maps the history queries of accounts, transactions, operations, trades and
+// effects, with nested resolvers so clients can fetch an account and its
+// latest records in one request.
+package gql
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+)
+
+// maxQueryDepth is the maximum depth of the selections of a query, it allows
+// the operations of the transactions of an account but not deeper nesting.
+const maxQueryDepth = 8
+
+// NewHandler returns the handler of the GraphQL end-point. Queries are posted
+// as JSON objects with `query`, `operationName` and `variables` fields. The
+// history database session is read from the request context, as set by the
+// history middleware.
+func NewHandler() http.Handler {
+	s := graphql.MustParseSchema(
+		schema,
+		&resolver{},
+		graphql.MaxDepth(maxQueryDepth),
+	)
+	return &relay.Handler{Schema: s}
+}
+
+type resolver struct{}
+
+// errNoSession is returned when the handler is not served behind the history
+// middleware.
+var errNoSession = errors.New("no history session in the request context")
+
+// historyQ returns the history queries of the session of the request.
+func historyQ(ctx context.Context) (*history.Q, error) {
+	session, ok := ctx.Value(&horizonContext.SessionContextKey).(*db.Session)
+	if !ok {
+		return nil, errNoSession
+	}
+	return &history.Q{Session: session}, nil
+}
+
+// pageArgs are the pagination arguments of the lists of records.
+type pageArgs struct {
+	First *int32
+	After *string
+	Order *string
+}
+
+// pageQuery returns the page query of args. The limit is one more than the
+// page size to find if there is a next page.
+func (args pageArgs) pageQuery() (db2.PageQuery, error) {
+	var cursor, order string
+	if args.After != nil {
+		cursor = *args.After
+	}
+	if args.Order != nil && *args.Order == "DESC" {
+		order = db2.OrderDescending
+	}
+
+	limit := uint64(db2.DefaultPageSize)
+	if args.First != nil {
+		if *args.First <= 0 || *args.First > db2.MaxPageSize {
+			return db2.PageQuery{}, errors.Errorf("first must be between 1 and %d", db2.MaxPageSize)
+		}
+		limit = uint64(*args.First)
+	}
+
+	page, err := db2.NewPageQueryWithMaxLimit(cursor, true, order, limit+1, db2.MaxPageSize+1)
+	if err != nil {
+		return page, errors.Wrap(err, "invalid after")
+	}
+	return page, nil
+}
+
+// pageInfo is the pagination state of a list of records.
+type pageInfo struct {
+	endCursor   *string
+	hasNextPage bool
+}
+
+// newPageInfo returns the page info of the n records loaded for page, the
+// extra record fetched to find the next page excluded. lastCursor returns
+// the paging token of the i-th record.
+func newPageInfo(page db2.PageQuery, n int, lastCursor func(i int) string) (pageInfo, int) {
+	info := pageInfo{}
+	if uint64(n) == page.Limit {
+		info.hasNextPage = true
+		n--
+	}
+	if n > 0 {
+		cursor := lastCursor(n - 1)
+		info.endCursor = &cursor
+	}
+	return info, n
+}
+
+func (p pageInfo) EndCursor() *string {
+	return p.endCursor
+}
+
+func (p pageInfo) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+// Account resolves the account query, it's null if the account doesn't exist.
+func (r *resolver) Account(ctx context.Context, args struct{ ID graphql.ID }) (*account, error) {
+	q, err := historyQ(ctx)
+	if err != nil {
+		return nil, err
+	}
+	record, err := q.GetAccountByID(string(args.ID))
+	if q.NoRows(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load account")
+	}
+	return &account{record: record}, nil
+}
+
+type accountPageArgs struct {
+	Account       *graphql.ID
+	First         *int32
+	After         *string
+	Order         *string
+	IncludeFailed *bool
+}
+
+func (args accountPageArgs) account() string {
+	if args.Account == nil {
+		return ""
+	}
+	return string(*args.Account)
+}
+
+func (args accountPageArgs) includeFailed() bool {
+	return args.IncludeFailed != nil && *args.IncludeFailed
+}
+
+func (args accountPageArgs) page() pageArgs {
+	return pageArgs{First: args.First, After: args.After, Order: args.Order}
+}
+
+// Transactions resolves the transactions query.
+func (r *resolver) Transactions(ctx context.Context, args accountPageArgs) (*transactionConnection, error) {
+	return loadTransactions(ctx, args.account(), args.page(), args.includeFailed())
+}
+
+// Operations resolves the operations query.
+func (r *resolver) Operations(ctx context.Context, args accountPageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, operationsFilter{account: args.account()}, args.page(), args.includeFailed())
+}
+
+// Trades resolves the trades query.
+func (r *resolver) Trades(ctx context.Context, args struct {
+	Account *graphql.ID
+	First   *int32
+	After   *string
+	Order   *string
+}) (*tradeConnection, error) {
+	return loadTrades(ctx, accountPageArgs{Account: args.Account}.account(), pageArgs{args.First, args.After, args.Order})
+}
+
+// Effects resolves the effects query.
+func (r *resolver) Effects(ctx context.Context, args struct {
+	Account *graphql.ID
+	First   *int32
+	After   *string
+	Order   *string
+}) (*effectConnection, error) {
+	return loadEffects(ctx, accountPageArgs{Account: args.Account}.account(), pageArgs{args.First, args.After, args.Order})
+}
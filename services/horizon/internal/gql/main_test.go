@@ -0,0 +1,187 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/xdr"
+)
+
+func TestSchema(t *testing.T) {
+	s := graphql.MustParseSchema(schema, &resolver{}, graphql.MaxDepth(maxQueryDepth))
+
+	// the records are loaded from the session of the request context
+	response := s.Exec(context.Background(), `{ transactions { edges { cursor } } }`, "", nil)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, errNoSession.Error(), response.Errors[0].Message)
+
+	// the operations of the transactions of an account are within the
+	// maximum depth
+	response = s.Exec(context.Background(), `{
+		account(id: "GABC") {
+			transactions(first: 5, order: DESC) { edges { node { operations { edges { node { id } } } } } }
+		}
+	}`, "", nil)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, errNoSession.Error(), response.Errors[0].Message)
+}
+
+func TestPageQuery(t *testing.T) {
+	first := int32(5)
+	after := "12884905984"
+	desc := "DESC"
+	page, err := pageArgs{First: &first, After: &after, Order: &desc}.pageQuery()
+	require.NoError(t, err)
+	assert.Equal(t, db2.PageQuery{Cursor: after, Order: db2.OrderDescending, Limit: 6}, page)
+
+	page, err = pageArgs{}.pageQuery()
+	require.NoError(t, err)
+	assert.Equal(t, db2.OrderAscending, page.Order)
+	assert.Equal(t, uint64(db2.DefaultPageSize+1), page.Limit)
+
+	max := int32(db2.MaxPageSize)
+	_, err = pageArgs{First: &max}.pageQuery()
+	assert.NoError(t, err)
+
+	for _, invalid := range []int32{0, db2.MaxPageSize + 1} {
+		invalid := invalid
+		_, err = pageArgs{First: &invalid}.pageQuery()
+		assert.EqualError(t, err, "first must be between 1 and 200")
+	}
+
+	invalidCursor := "a"
+	_, err = pageArgs{After: &invalidCursor}.pageQuery()
+	assert.Error(t, err)
+}
+
+func TestNewPageInfo(t *testing.T) {
+	tokens := []string{"1", "2", "3"}
+	lastCursor := func(i int) string { return tokens[i] }
+
+	info, n := newPageInfo(db2.PageQuery{Limit: 3}, 3, lastCursor)
+	assert.Equal(t, 2, n)
+	assert.True(t, info.HasNextPage())
+	assert.Equal(t, "2", *info.EndCursor())
+
+	info, n = newPageInfo(db2.PageQuery{Limit: 4}, 3, lastCursor)
+	assert.Equal(t, 3, n)
+	assert.False(t, info.HasNextPage())
+	assert.Equal(t, "3", *info.EndCursor())
+
+	info, n = newPageInfo(db2.PageQuery{Limit: 4}, 0, lastCursor)
+	assert.Equal(t, 0, n)
+	assert.Nil(t, info.EndCursor())
+}
+
+func TestHandlerRejectsInvalidBody(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAccountNestedConnections(t *testing.T) {
+	tt := test.Start(t).Scenario("trades")
+	defer tt.Finish()
+	q := &history.Q{tt.HorizonSession()}
+
+	// the trades scenario doesn't ingest the state, the account is only in
+	// the history
+	address := "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+	tt.Require.NoError(q.UpsertAccounts([]xdr.LedgerEntry{{
+		LastModifiedLedgerSeq: 1,
+		Data: xdr.LedgerEntryData{
+			Type:    xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{AccountId: xdr.MustAddress(address), Balance: 10000000},
+		},
+	}}))
+
+	transactions, err := q.GetTransactions(tt.Ctx, history.TransactionsFilter{AccountID: address}, db2.MustPageQuery("", false, "asc", 200))
+	tt.Require.NoError(err)
+	tt.Require.Len(transactions, 7)
+	trades, err := q.GetTrades(tt.Ctx, history.TradesFilter{AccountID: address}, db2.MustPageQuery("", false, "asc", 200))
+	tt.Require.NoError(err)
+	tt.Require.Len(trades, 2)
+
+	type connection struct {
+		Edges []struct {
+			Cursor string
+			Node   struct{ ID string }
+		}
+		PageInfo struct {
+			EndCursor   *string
+			HasNextPage bool
+		}
+	}
+	cursors := func(c connection) []string {
+		var cursors []string
+		for _, edge := range c.Edges {
+			cursors = append(cursors, edge.Cursor)
+		}
+		return cursors
+	}
+
+	ctx := context.WithValue(context.Background(), &horizonContext.SessionContextKey, tt.HorizonSession())
+	s := graphql.MustParseSchema(schema, &resolver{}, graphql.MaxDepth(maxQueryDepth))
+	exec := func(transactionsAfter string) (connection, connection) {
+		response := s.Exec(ctx, `query($id: ID!, $after: String) {
+			account(id: $id) {
+				transactions(first: 5, after: $after) {
+					edges { cursor node { id } }
+					pageInfo { endCursor hasNextPage }
+				}
+				trades(first: 1) {
+					edges { cursor node { id } }
+					pageInfo { endCursor hasNextPage }
+				}
+			}
+		}`, "", map[string]interface{}{"id": address, "after": transactionsAfter})
+		tt.Require.Empty(response.Errors)
+
+		var data struct {
+			Account struct {
+				Transactions connection
+				Trades       connection
+			}
+		}
+		tt.Require.NoError(json.Unmarshal(response.Data, &data))
+		return data.Account.Transactions, data.Account.Trades
+	}
+
+	page, tradesPage := exec("")
+	tt.Assert.Equal(
+		[]string{
+			transactions[0].PagingToken(),
+			transactions[1].PagingToken(),
+			transactions[2].PagingToken(),
+			transactions[3].PagingToken(),
+			transactions[4].PagingToken(),
+		},
+		cursors(page),
+	)
+	tt.Assert.Equal(transactions[0].TransactionHash, page.Edges[0].Node.ID)
+	tt.Assert.True(page.PageInfo.HasNextPage)
+	tt.Require.NotNil(page.PageInfo.EndCursor)
+	tt.Assert.Equal(transactions[4].PagingToken(), *page.PageInfo.EndCursor)
+
+	tt.Assert.Equal([]string{trades[0].PagingToken()}, cursors(tradesPage))
+	tt.Assert.True(tradesPage.PageInfo.HasNextPage)
+
+	page, _ = exec(*page.PageInfo.EndCursor)
+	tt.Assert.Equal(
+		[]string{transactions[5].PagingToken(), transactions[6].PagingToken()},
+		cursors(page),
+	)
+	tt.Assert.False(page.PageInfo.HasNextPage)
+}
@@ -0,0 +1,487 @@
+package gql
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+type account struct {
+	record history.AccountEntry
+}
+
+func (a *account) ID() graphql.ID {
+	return graphql.ID(a.record.AccountID)
+}
+
+func (a *account) Sequence() string {
+	return strconv.FormatInt(a.record.SequenceNumber, 10)
+}
+
+func (a *account) Balance() string {
+	return amount.StringFromInt64(a.record.Balance)
+}
+
+func (a *account) SubentryCount() int32 {
+	return int32(a.record.NumSubEntries)
+}
+
+func (a *account) HomeDomain() string {
+	return a.record.HomeDomain
+}
+
+func (a *account) Flags() int32 {
+	return int32(a.record.Flags)
+}
+
+func (a *account) LastModifiedLedger() int32 {
+	return int32(a.record.LastModifiedLedger)
+}
+
+func (a *account) Transactions(ctx context.Context, args accountPageArgs) (*transactionConnection, error) {
+	return loadTransactions(ctx, a.record.AccountID, args.page(), args.includeFailed())
+}
+
+func (a *account) Operations(ctx context.Context, args accountPageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, operationsFilter{account: a.record.AccountID}, args.page(), args.includeFailed())
+}
+
+func (a *account) Trades(ctx context.Context, args pageArgs) (*tradeConnection, error) {
+	return loadTrades(ctx, a.record.AccountID, args)
+}
+
+func (a *account) Effects(ctx context.Context, args pageArgs) (*effectConnection, error) {
+	return loadEffects(ctx, a.record.AccountID, args)
+}
+
+type transactionConnection struct {
+	edges []*transactionEdge
+	info  pageInfo
+}
+
+func (c *transactionConnection) Edges() []*transactionEdge {
+	return c.edges
+}
+
+func (c *transactionConnection) PageInfo() pageInfo {
+	return c.info
+}
+
+type transactionEdge struct {
+	node *transaction
+}
+
+func (e *transactionEdge) Cursor() string {
+	return e.node.record.PagingToken()
+}
+
+func (e *transactionEdge) Node() *transaction {
+	return e.node
+}
+
+// loadTransactions loads a page of the transactions of account, or of all
+// the transactions if account is empty.
+func loadTransactions(ctx context.Context, account string, args pageArgs, includeFailed bool) (*transactionConnection, error) {
+	page, err := args.pageQuery()
+	if err != nil {
+		return nil, err
+	}
+	q, err := historyQ(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := q.GetTransactions(ctx, history.TransactionsFilter{
+		AccountID:     account,
+		IncludeFailed: includeFailed,
+	}, page)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load transactions")
+	}
+
+	info, n := newPageInfo(page, len(records), func(i int) string { return records[i].PagingToken() })
+	connection := &transactionConnection{info: info}
+	for i := range records[:n] {
+		connection.edges = append(connection.edges, &transactionEdge{node: &transaction{record: records[i]}})
+	}
+	return connection, nil
+}
+
+type transaction struct {
+	record history.Transaction
+}
+
+func (t *transaction) ID() graphql.ID {
+	return graphql.ID(t.record.TransactionHash)
+}
+
+func (t *transaction) Hash() string {
+	return t.record.TransactionHash
+}
+
+func (t *transaction) Ledger() int32 {
+	return t.record.LedgerSequence
+}
+
+func (t *transaction) CreatedAt() string {
+	return t.record.LedgerCloseTime.Format(time.RFC3339)
+}
+
+func (t *transaction) SourceAccount() string {
+	return t.record.Account
+}
+
+func (t *transaction) SourceAccountSequence() string {
+	return t.record.AccountSequence
+}
+
+func (t *transaction) FeeAccount() string {
+	if t.record.FeeAccount.Valid {
+		return t.record.FeeAccount.String
+	}
+	return t.record.Account
+}
+
+func (t *transaction) FeeCharged() string {
+	return strconv.FormatInt(t.record.FeeCharged, 10)
+}
+
+func (t *transaction) MaxFee() string {
+	return strconv.FormatInt(t.record.MaxFee, 10)
+}
+
+func (t *transaction) OperationCount() int32 {
+	return t.record.OperationCount
+}
+
+func (t *transaction) Successful() bool {
+	return t.record.Successful
+}
+
+func (t *transaction) MemoType() string {
+	return t.record.MemoType
+}
+
+func (t *transaction) Memo() *string {
+	if !t.record.Memo.Valid {
+		return nil
+	}
+	return &t.record.Memo.String
+}
+
+func (t *transaction) EnvelopeXdr() string {
+	return t.record.TxEnvelope
+}
+
+func (t *transaction) ResultXdr() string {
+	return t.record.TxResult
+}
+
+func (t *transaction) Operations(ctx context.Context, args pageArgs) (*operationConnection, error) {
+	return loadOperations(ctx, operationsFilter{transaction: t.record.TransactionHash}, args, true)
+}
+
+type operationConnection struct {
+	edges []*operationEdge
+	info  pageInfo
+}
+
+func (c *operationConnection) Edges() []*operationEdge {
+	return c.edges
+}
+
+func (c *operationConnection) PageInfo() pageInfo {
+	return c.info
+}
+
+type operationEdge struct {
+	node *operation
+}
+
+func (e *operationEdge) Cursor() string {
+	return e.node.record.PagingToken()
+}
+
+func (e *operationEdge) Node() *operation {
+	return e.node
+}
+
+// operationsFilter selects the operations of an account or of a
+// transaction, or all the operations if both are empty.
+type operationsFilter struct {
+	account     string
+	transaction string
+}
+
+func loadOperations(ctx context.Context, filter operationsFilter, args pageArgs, includeFailed bool) (*operationConnection, error) {
+	page, err := args.pageQuery()
+	if err != nil {
+		return nil, err
+	}
+	q, err := historyQ(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := history.OperationsFilter{IncludeFailed: includeFailed}
+	switch {
+	case filter.account != "":
+		query.AccountID = filter.account
+	case filter.transaction != "":
+		query.TransactionHash = filter.transaction
+	}
+	records, _, err := q.GetOperations(ctx, query, page)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load operations")
+	}
+
+	info, n := newPageInfo(page, len(records), func(i int) string { return records[i].PagingToken() })
+	connection := &operationConnection{info: info}
+	for i := range records[:n] {
+		connection.edges = append(connection.edges, &operationEdge{node: &operation{record: records[i]}})
+	}
+	return connection, nil
+}
+
+type operation struct {
+	record history.Operation
+}
+
+func (o *operation) ID() graphql.ID {
+	return graphql.ID(o.record.PagingToken())
+}
+
+func (o *operation) Type() string {
+	return operations.TypeNames[o.record.Type]
+}
+
+func (o *operation) SourceAccount() string {
+	return o.record.SourceAccount
+}
+
+func (o *operation) TransactionHash() string {
+	return o.record.TransactionHash
+}
+
+func (o *operation) TransactionSuccessful() bool {
+	return o.record.TransactionSuccessful
+}
+
+// Details is the JSON object of the type specific fields of the operation.
+func (o *operation) Details() *string {
+	if !o.record.DetailsString.Valid {
+		return nil
+	}
+	return &o.record.DetailsString.String
+}
+
+type tradeConnection struct {
+	edges []*tradeEdge
+	info  pageInfo
+}
+
+func (c *tradeConnection) Edges() []*tradeEdge {
+	return c.edges
+}
+
+func (c *tradeConnection) PageInfo() pageInfo {
+	return c.info
+}
+
+type tradeEdge struct {
+	node *trade
+}
+
+func (e *tradeEdge) Cursor() string {
+	return e.node.record.PagingToken()
+}
+
+func (e *tradeEdge) Node() *trade {
+	return e.node
+}
+
+func loadTrades(ctx context.Context, account string, args pageArgs) (*tradeConnection, error) {
+	page, err := args.pageQuery()
+	if err != nil {
+		return nil, err
+	}
+	q, err := historyQ(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := q.GetTrades(ctx, history.TradesFilter{AccountID: account}, page)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load trades")
+	}
+
+	info, n := newPageInfo(page, len(records), func(i int) string { return records[i].PagingToken() })
+	connection := &tradeConnection{info: info}
+	for i := range records[:n] {
+		connection.edges = append(connection.edges, &tradeEdge{node: &trade{record: records[i]}})
+	}
+	return connection, nil
+}
+
+type trade struct {
+	record history.Trade
+}
+
+func (t *trade) ID() graphql.ID {
+	return graphql.ID(t.record.PagingToken())
+}
+
+func (t *trade) LedgerCloseTime() string {
+	return t.record.LedgerCloseTime.Format(time.RFC3339)
+}
+
+func offerID(id *int64) *string {
+	if id == nil {
+		return nil
+	}
+	s := strconv.FormatInt(*id, 10)
+	return &s
+}
+
+func (t *trade) BaseOfferId() *string {
+	return offerID(t.record.BaseOfferID)
+}
+
+func (t *trade) BaseAccount() string {
+	return t.record.BaseAccount
+}
+
+func (t *trade) BaseAsset() *asset {
+	return &asset{t.record.BaseAssetType, t.record.BaseAssetCode, t.record.BaseAssetIssuer}
+}
+
+func (t *trade) BaseAmount() string {
+	return amount.String(t.record.BaseAmount)
+}
+
+func (t *trade) CounterOfferId() *string {
+	return offerID(t.record.CounterOfferID)
+}
+
+func (t *trade) CounterAccount() string {
+	return t.record.CounterAccount
+}
+
+func (t *trade) CounterAsset() *asset {
+	return &asset{t.record.CounterAssetType, t.record.CounterAssetCode, t.record.CounterAssetIssuer}
+}
+
+func (t *trade) CounterAmount() string {
+	return amount.String(t.record.CounterAmount)
+}
+
+func (t *trade) BaseIsSeller() bool {
+	return t.record.BaseIsSeller
+}
+
+func (t *trade) Price() *string {
+	if !t.record.PriceN.Valid || !t.record.PriceD.Valid {
+		return nil
+	}
+	price := xdr.Price{N: xdr.Int32(t.record.PriceN.Int64), D: xdr.Int32(t.record.PriceD.Int64)}.String()
+	return &price
+}
+
+type asset struct {
+	assetType string
+	code      string
+	issuer    string
+}
+
+func (a *asset) Type() string {
+	return a.assetType
+}
+
+func (a *asset) Code() string {
+	return a.code
+}
+
+func (a *asset) Issuer() string {
+	return a.issuer
+}
+
+type effectConnection struct {
+	edges []*effectEdge
+	info  pageInfo
+}
+
+func (c *effectConnection) Edges() []*effectEdge {
+	return c.edges
+}
+
+func (c *effectConnection) PageInfo() pageInfo {
+	return c.info
+}
+
+type effectEdge struct {
+	node *effect
+}
+
+func (e *effectEdge) Cursor() string {
+	return e.node.record.PagingToken()
+}
+
+func (e *effectEdge) Node() *effect {
+	return e.node
+}
+
+func loadEffects(ctx context.Context, account string, args pageArgs) (*effectConnection, error) {
+	page, err := args.pageQuery()
+	if err != nil {
+		return nil, err
+	}
+	q, err := historyQ(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := q.GetEffects(ctx, history.EffectsFilter{AccountID: account}, page)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load effects")
+	}
+
+	info, n := newPageInfo(page, len(records), func(i int) string { return records[i].PagingToken() })
+	connection := &effectConnection{info: info}
+	for i := range records[:n] {
+		connection.edges = append(connection.edges, &effectEdge{node: &effect{record: records[i]}})
+	}
+	return connection, nil
+}
+
+type effect struct {
+	record history.Effect
+}
+
+func (e *effect) ID() graphql.ID {
+	return graphql.ID(e.record.PagingToken())
+}
+
+func (e *effect) Account() string {
+	return e.record.Account
+}
+
+func (e *effect) Type() string {
+	return effects.EffectTypeNames[effects.EffectType(e.record.Type)]
+}
+
+// Details is the JSON object of the type specific fields of the effect.
+func (e *effect) Details() *string {
+	if !e.record.DetailsString.Valid {
+		return nil
+	}
+	return &e.record.DetailsString.String
+}
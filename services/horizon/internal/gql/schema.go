@@ -0,0 +1,138 @@
+package gql
+
+// schema is the GraphQL schema served by Handler. Lists of records are
+// paginated like the REST end-points: `first` is the page size, `after` the
+// paging token of the last record of the previous page.
+const schema = `
+schema {
+	query: Query
+}
+
+enum Order {
+	ASC
+	DESC
+}
+
+type Query {
+	account(id: ID!): Account
+	transactions(account: ID, first: Int, after: String, order: Order, includeFailed: Boolean): TransactionConnection!
+	operations(account: ID, first: Int, after: String, order: Order, includeFailed: Boolean): OperationConnection!
+	trades(account: ID, first: Int, after: String, order: Order): TradeConnection!
+	effects(account: ID, first: Int, after: String, order: Order): EffectConnection!
+}
+
+type PageInfo {
+	endCursor: String
+	hasNextPage: Boolean!
+}
+
+type Account {
+	id: ID!
+	sequence: String!
+	balance: String!
+	subentryCount: Int!
+	homeDomain: String!
+	flags: Int!
+	lastModifiedLedger: Int!
+	transactions(first: Int, after: String, order: Order, includeFailed: Boolean): TransactionConnection!
+	operations(first: Int, after: String, order: Order, includeFailed: Boolean): OperationConnection!
+	trades(first: Int, after: String, order: Order): TradeConnection!
+	effects(first: Int, after: String, order: Order): EffectConnection!
+}
+
+type TransactionConnection {
+	edges: [TransactionEdge!]!
+	pageInfo: PageInfo!
+}
+
+type TransactionEdge {
+	cursor: String!
+	node: Transaction!
+}
+
+type Transaction {
+	id: ID!
+	hash: String!
+	ledger: Int!
+	createdAt: String!
+	sourceAccount: String!
+	sourceAccountSequence: String!
+	feeAccount: String!
+	feeCharged: String!
+	maxFee: String!
+	operationCount: Int!
+	successful: Boolean!
+	memoType: String!
+	memo: String
+	envelopeXdr: String!
+	resultXdr: String!
+	operations(first: Int, after: String, order: Order): OperationConnection!
+}
+
+type OperationConnection {
+	edges: [OperationEdge!]!
+	pageInfo: PageInfo!
+}
+
+type OperationEdge {
+	cursor: String!
+	node: Operation!
+}
+
+type Operation {
+	id: ID!
+	type: String!
+	sourceAccount: String!
+	transactionHash: String!
+	transactionSuccessful: Boolean!
+	details: String
+}
+
+type TradeConnection {
+	edges: [TradeEdge!]!
+	pageInfo: PageInfo!
+}
+
+type TradeEdge {
+	cursor: String!
+	node: Trade!
+}
+
+type Asset {
+	type: String!
+	code: String!
+	issuer: String!
+}
+
+type Trade {
+	id: ID!
+	ledgerCloseTime: String!
+	baseOfferId: String
+	baseAccount: String!
+	baseAsset: Asset!
+	baseAmount: String!
+	counterOfferId: String
+	counterAccount: String!
+	counterAsset: Asset!
+	counterAmount: String!
+	baseIsSeller: Boolean!
+	price: String
+}
+
+type EffectConnection {
+	edges: [EffectEdge!]!
+	pageInfo: PageInfo!
+}
+
+type EffectEdge {
+	cursor: String!
+	node: Effect!
+}
+
+type Effect {
+	id: ID!
+	account: String!
+	type: String!
+	details: String
+}
+`
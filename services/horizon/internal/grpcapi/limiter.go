@@ -0,0 +1,117 @@
+package grpcapi
+
+import (
+	"net"
+	"sync"
+
+	"github.com/stellar/throttled"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// lruCacheSize is the number of peers whose rate is tracked, as in the rate
+// limiter of the HTTP server.
+const lruCacheSize = 50000
+
+// poolMonitor reports if a database connection pool is exhausted, it's
+// implemented by db.PoolMonitor.
+type poolMonitor interface {
+	Exhausted() bool
+}
+
+// streamLimiter rejects the calls opened while the database connection pool
+// is exhausted, above the rate quota of their peer or above the limits of
+// concurrent streams. Every stream queries the database for each ingested
+// ledger, so the limits bound the load of the gRPC server on the database.
+type streamLimiter struct {
+	maxStreams        int
+	maxStreamsPerPeer int
+	rateLimiter       throttled.RateLimiter
+	poolMonitor       poolMonitor
+
+	lock        sync.Mutex
+	streams     int
+	peerStreams map[string]int
+}
+
+func newStreamLimiter(config Config) (*streamLimiter, error) {
+	limiter := &streamLimiter{
+		maxStreams:        config.MaxStreams,
+		maxStreamsPerPeer: config.MaxStreamsPerPeer,
+		poolMonitor:       config.PoolMonitor,
+		peerStreams:       map[string]int{},
+	}
+	if config.RateQuota != nil {
+		rateLimiter, err := throttled.NewGCRARateLimiter(lruCacheSize, *config.RateQuota)
+		if err != nil {
+			return nil, err
+		}
+		limiter.rateLimiter = rateLimiter
+	}
+	return limiter, nil
+}
+
+// peerAddress returns the IP address of the peer of a call, the calls of all
+// the connections of a client share its limits.
+func peerAddress(stream grpc.ServerStream) string {
+	p, ok := peer.FromContext(stream.Context())
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// acquire counts a new stream of address, it returns an error when the stream
+// is rejected.
+func (l *streamLimiter) acquire(address string) error {
+	if l.poolMonitor != nil && l.poolMonitor.Exhausted() {
+		return status.Error(codes.Unavailable, "server is over capacity")
+	}
+	if l.rateLimiter != nil {
+		limited, _, err := l.rateLimiter.RateLimit(address, 1)
+		if err != nil {
+			return status.Error(codes.Internal, "could not apply the rate limit")
+		}
+		if limited {
+			return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.maxStreams > 0 && l.streams >= l.maxStreams {
+		return status.Error(codes.ResourceExhausted, "too many streams")
+	}
+	if l.maxStreamsPerPeer > 0 && l.peerStreams[address] >= l.maxStreamsPerPeer {
+		return status.Error(codes.ResourceExhausted, "too many streams of this client")
+	}
+	l.streams++
+	l.peerStreams[address]++
+	return nil
+}
+
+// release counts the end of a stream of address.
+func (l *streamLimiter) release(address string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.streams--
+	if l.peerStreams[address]--; l.peerStreams[address] <= 0 {
+		delete(l.peerStreams, address)
+	}
+}
+
+// intercept is the grpc.StreamServerInterceptor applying the limits.
+func (l *streamLimiter) intercept(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	address := peerAddress(stream)
+	if err := l.acquire(address); err != nil {
+		return err
+	}
+	defer l.release(address)
+	return handler(srv, stream)
+}
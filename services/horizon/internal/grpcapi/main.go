@@ -0,0 +1,310 @@
+// Package grpcapi implements the gRPC server of horizon. It serves the
+// Streams service of streams.proto: server-streaming calls sending new
+// ledgers, transactions of an account and trades of an asset pair as they
+// are ingested, for backend consumers to which SSE is awkward.
+package grpcapi
+
+//go:generate protoc --go_out=plugins=grpc:. streams.proto
+
+import (
+	"context"
+
+	"github.com/stellar/throttled"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/toid"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+)
+
+// streamPageSize is the maximum number of records loaded by a query of a
+// stream. Streams behind the latest ledger load pages until they catch up.
+const streamPageSize = 200
+
+// Config configures the gRPC server.
+type Config struct {
+	// Session is the session of the horizon database records are loaded
+	// from.
+	Session *db.Session
+	// LedgerSource returns the source notifying a stream of the ingested
+	// ledgers, it's closed when the stream ends.
+	LedgerSource func() ledger.Source
+	// MaxStreams and MaxStreamsPerPeer limit the concurrent streams of the
+	// server and of a single client IP address, they are not limited when
+	// 0.
+	MaxStreams        int
+	MaxStreamsPerPeer int
+	// RateQuota limits the rate of the calls of a client IP address, it's
+	// not limited when nil.
+	RateQuota *throttled.RateQuota
+	// PoolMonitor rejects calls while the connection pool of the database is
+	// exhausted, when it's not nil.
+	PoolMonitor poolMonitor
+}
+
+// NewServer returns a gRPC server serving the Streams service.
+func NewServer(config Config, opts ...grpc.ServerOption) (*grpc.Server, error) {
+	limiter, err := newStreamLimiter(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the stream limiter")
+	}
+	opts = append(opts, grpc.StreamInterceptor(limiter.intercept))
+	if config.MaxStreamsPerPeer > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(uint32(config.MaxStreamsPerPeer)))
+	}
+
+	s := grpc.NewServer(opts...)
+	RegisterStreamsServer(s, &streamsServer{config: config})
+	return s, nil
+}
+
+type streamsServer struct {
+	config Config
+}
+
+// sendPageFunc loads the page of records after the cursor of page, sends
+// them and returns their number and the paging token of the last one.
+type sendPageFunc func(q *history.Q, page db2.PageQuery) (int, string, error)
+
+// stream sends the pages of records after cursor, then waits for the next
+// ingested ledger and sends the new records, until ctx is done.
+func (s *streamsServer) stream(ctx context.Context, cursor string, sendPage sendPageFunc) error {
+	page, err := newStreamPageQuery(cursor)
+	if err != nil {
+		return err
+	}
+
+	source := s.config.LedgerSource()
+	defer source.Close()
+
+	q := &history.Q{Session: s.config.Session.WithContext(ctx)}
+	currentLedger := source.CurrentLedger()
+	for {
+		n, lastPagingToken, err := sendPage(q, page)
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		if err != nil {
+			if _, ok := status.FromError(err); ok {
+				return err
+			}
+			log.Ctx(ctx).WithError(err).Error("could not send stream page")
+			return status.Error(codes.Internal, "could not load records")
+		}
+		if n > 0 {
+			page.Cursor = lastPagingToken
+		}
+		if uint64(n) == page.Limit {
+			continue
+		}
+
+		select {
+		case currentLedger = <-source.NextLedger(currentLedger):
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// newStreamPageQuery returns the page query of the first page of a stream.
+// An empty cursor, or "now", starts the stream after the latest ingested
+// ledger.
+func newStreamPageQuery(cursor string) (db2.PageQuery, error) {
+	if cursor == "" || cursor == "now" {
+		cursor = toid.AfterLedger(ledger.CurrentState().HistoryLatest).String()
+	}
+	page, err := db2.NewPageQuery(cursor, true, db2.OrderAscending, streamPageSize)
+	if err != nil {
+		return page, status.Errorf(codes.InvalidArgument, "invalid cursor: %v", err)
+	}
+	return page, nil
+}
+
+// Ledgers implements StreamsServer.
+func (s *streamsServer) Ledgers(request *LedgersRequest, stream Streams_LedgersServer) error {
+	ctx := stream.Context()
+	return s.stream(ctx, request.Cursor, func(q *history.Q, page db2.PageQuery) (int, string, error) {
+		records, err := q.GetLedgers(ctx, history.LedgersFilter{}, page)
+		if err != nil {
+			return 0, "", err
+		}
+		for i := range records {
+			if err := stream.Send(ledgerMessage(records[i])); err != nil {
+				return 0, "", err
+			}
+		}
+		if len(records) == 0 {
+			return 0, "", nil
+		}
+		return len(records), records[len(records)-1].PagingToken(), nil
+	})
+}
+
+// Transactions implements StreamsServer.
+func (s *streamsServer) Transactions(request *TransactionsRequest, stream Streams_TransactionsServer) error {
+	if _, err := strkey.Decode(strkey.VersionByteAccountID, request.AccountId); err != nil {
+		return status.Error(codes.InvalidArgument, "account_id must be a valid account ID")
+	}
+
+	ctx := stream.Context()
+	return s.stream(ctx, request.Cursor, func(q *history.Q, page db2.PageQuery) (int, string, error) {
+		records, err := q.GetTransactions(ctx, history.TransactionsFilter{
+			AccountID:     request.AccountId,
+			IncludeFailed: request.IncludeFailed,
+		}, page)
+		if err != nil {
+			return 0, "", err
+		}
+		for i := range records {
+			if err := stream.Send(transactionMessage(records[i])); err != nil {
+				return 0, "", err
+			}
+		}
+		if len(records) == 0 {
+			return 0, "", nil
+		}
+		return len(records), records[len(records)-1].PagingToken(), nil
+	})
+}
+
+// Trades implements StreamsServer.
+func (s *streamsServer) Trades(request *TradesRequest, stream Streams_TradesServer) error {
+	ctx := stream.Context()
+	baseAsset, err := buildAsset("base_asset", request.BaseAsset)
+	if err != nil {
+		return err
+	}
+	counterAsset, err := buildAsset("counter_asset", request.CounterAsset)
+	if err != nil {
+		return err
+	}
+
+	q := &history.Q{Session: s.config.Session.WithContext(ctx)}
+	baseAssetID, err := findAssetID(q, "base_asset", baseAsset)
+	if err != nil {
+		return err
+	}
+	counterAssetID, err := findAssetID(q, "counter_asset", counterAsset)
+	if err != nil {
+		return err
+	}
+
+	return s.stream(ctx, request.Cursor, func(q *history.Q, page db2.PageQuery) (int, string, error) {
+		records, err := q.GetTrades(ctx, history.TradesFilter{
+			BaseAssetID:    baseAssetID,
+			CounterAssetID: counterAssetID,
+		}, page)
+		if err != nil {
+			return 0, "", err
+		}
+		for i := range records {
+			if err := stream.Send(tradeMessage(records[i])); err != nil {
+				return 0, "", err
+			}
+		}
+		if len(records) == 0 {
+			return 0, "", nil
+		}
+		return len(records), records[len(records)-1].PagingToken(), nil
+	})
+}
+
+func buildAsset(field string, asset *Asset) (xdr.Asset, error) {
+	if asset == nil {
+		return xdr.Asset{}, status.Errorf(codes.InvalidArgument, "%s is required", field)
+	}
+	result, err := xdr.BuildAsset(asset.Type, asset.Issuer, asset.Code)
+	if err != nil {
+		return result, status.Errorf(codes.InvalidArgument, "invalid %s: %v", field, err)
+	}
+	return result, nil
+}
+
+// findAssetID returns the id of the asset in the history_assets table. Assets
+// which were never traded are not found.
+func findAssetID(q *history.Q, field string, asset xdr.Asset) (int64, error) {
+	id, err := q.GetAssetID(asset)
+	if q.NoRows(err) {
+		return 0, status.Errorf(codes.NotFound, "%s not found", field)
+	}
+	return id, err
+}
+
+func ledgerMessage(record history.Ledger) *Ledger {
+	message := &Ledger{
+		PagingToken:     record.PagingToken(),
+		Sequence:        uint32(record.Sequence),
+		Hash:            record.LedgerHash,
+		PrevHash:        record.PreviousLedgerHash.String,
+		ClosedAt:        record.ClosedAt.Unix(),
+		OperationCount:  record.OperationCount,
+		ProtocolVersion: record.ProtocolVersion,
+		HeaderXdr:       record.LedgerHeaderXDR.String,
+	}
+	if record.SuccessfulTransactionCount != nil {
+		message.SuccessfulTransactionCount = *record.SuccessfulTransactionCount
+	}
+	if record.FailedTransactionCount != nil {
+		message.FailedTransactionCount = *record.FailedTransactionCount
+	}
+	return message
+}
+
+func transactionMessage(record history.Transaction) *Transaction {
+	return &Transaction{
+		PagingToken:           record.PagingToken(),
+		Hash:                  record.TransactionHash,
+		Ledger:                uint32(record.LedgerSequence),
+		LedgerCloseTime:       record.LedgerCloseTime.Unix(),
+		SourceAccount:         record.Account,
+		SourceAccountSequence: record.AccountSequence,
+		FeeAccount:            record.FeeAccount.String,
+		FeeCharged:            record.FeeCharged,
+		MaxFee:                record.MaxFee,
+		OperationCount:        record.OperationCount,
+		Successful:            record.Successful,
+		EnvelopeXdr:           record.TxEnvelope,
+		ResultXdr:             record.TxResult,
+		ResultMetaXdr:         record.TxMeta,
+	}
+}
+
+func tradeMessage(record history.Trade) *Trade {
+	message := &Trade{
+		PagingToken:     record.PagingToken(),
+		LedgerCloseTime: record.LedgerCloseTime.Unix(),
+		BaseAccount:     record.BaseAccount,
+		BaseAsset: &Asset{
+			Type:   record.BaseAssetType,
+			Code:   record.BaseAssetCode,
+			Issuer: record.BaseAssetIssuer,
+		},
+		BaseAmount:     amount.String(record.BaseAmount),
+		CounterAccount: record.CounterAccount,
+		CounterAsset: &Asset{
+			Type:   record.CounterAssetType,
+			Code:   record.CounterAssetCode,
+			Issuer: record.CounterAssetIssuer,
+		},
+		CounterAmount: amount.String(record.CounterAmount),
+		BaseIsSeller:  record.BaseIsSeller,
+		PriceN:        record.PriceN.Int64,
+		PriceD:        record.PriceD.Int64,
+	}
+	if record.BaseOfferID != nil {
+		message.BaseOfferId = *record.BaseOfferID
+	}
+	if record.CounterOfferID != nil {
+		message.CounterOfferId = *record.CounterOfferID
+	}
+	return message
+}
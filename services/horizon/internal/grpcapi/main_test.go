@@ -0,0 +1,157 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stellar/throttled"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/support/db"
+)
+
+func TestMessagesRoundTrip(t *testing.T) {
+	trade := &Trade{
+		PagingToken:  "107449584845914113-0",
+		BaseAsset:    &Asset{Type: "native"},
+		BaseAmount:   "10.0000000",
+		CounterAsset: &Asset{Type: "credit_alphanum4", Code: "USD", Issuer: "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"},
+		BaseOfferId:  42,
+		BaseIsSeller: true,
+		PriceN:       1,
+		PriceD:       2,
+	}
+	encoded, err := proto.Marshal(trade)
+	require.NoError(t, err)
+	decoded := &Trade{}
+	require.NoError(t, proto.Unmarshal(encoded, decoded))
+	assert.True(t, proto.Equal(trade, decoded), "%v != %v", trade, decoded)
+
+	// field 1 (paging_token) is length delimited, field 2 (sequence) a varint
+	encoded, err = proto.Marshal(&Ledger{PagingToken: "1", Sequence: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x0a, 0x01, '1', 0x10, 0x03}, encoded)
+}
+
+func TestStreamSendsPagesThenWaitsForLedgers(t *testing.T) {
+	source := ledger.NewTestingSource(10)
+	s := &streamsServer{config: Config{
+		Session:      &db.Session{},
+		LedgerSource: func() ledger.Source { return source },
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var cursors []string
+	pages := []int{streamPageSize, 1, 0, 2}
+	done := make(chan error)
+	go func() {
+		done <- s.stream(ctx, "100", func(q *history.Q, page db2.PageQuery) (int, string, error) {
+			cursors = append(cursors, page.Cursor)
+			n := pages[len(cursors)-1]
+			if len(cursors) == len(pages) {
+				cancel()
+			}
+			return n, fmt.Sprintf("token%d", len(cursors)), nil
+		})
+	}()
+
+	// a full page is followed by the next page, the others wait for a ledger
+	source.AddLedger(11)
+	source.AddLedger(12)
+	err := <-done
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Equal(t, []string{"100", "token1", "token2", "token2"}, cursors)
+}
+
+func TestInvalidRequests(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server, err := NewServer(Config{
+		Session:      &db.Session{},
+		LedgerSource: func() ledger.Source { return ledger.NewTestingSource(1) },
+	})
+	require.NoError(t, err)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := NewStreamsClient(conn)
+	ctx := context.Background()
+
+	ledgers, err := client.Ledgers(ctx, &LedgersRequest{Cursor: "-1"})
+	require.NoError(t, err)
+	_, err = ledgers.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	transactions, err := client.Transactions(ctx, &TransactionsRequest{AccountId: "GABC"})
+	require.NoError(t, err)
+	_, err = transactions.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "account_id must be a valid account ID", status.Convert(err).Message())
+
+	trades, err := client.Trades(ctx, &TradesRequest{BaseAsset: &Asset{Type: "native"}})
+	require.NoError(t, err)
+	_, err = trades.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "counter_asset is required", status.Convert(err).Message())
+
+	trades, err = client.Trades(ctx, &TradesRequest{
+		BaseAsset:    &Asset{Type: "native"},
+		CounterAsset: &Asset{Type: "credit_alphanum4", Code: "USD"},
+	})
+	require.NoError(t, err)
+	_, err = trades.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+type exhaustedPool bool
+
+func (p *exhaustedPool) Exhausted() bool { return bool(*p) }
+
+func TestStreamLimiter(t *testing.T) {
+	pool := exhaustedPool(false)
+	limiter, err := newStreamLimiter(Config{
+		MaxStreams:        3,
+		MaxStreamsPerPeer: 2,
+		RateQuota:         &throttled.RateQuota{MaxRate: throttled.PerHour(1), MaxBurst: 2},
+		PoolMonitor:       &pool,
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, limiter.acquire("1.1.1.1"))
+	assert.NoError(t, limiter.acquire("1.1.1.1"))
+	err = limiter.acquire("1.1.1.1")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "too many streams of this client", status.Convert(err).Message())
+
+	assert.NoError(t, limiter.acquire("2.2.2.2"))
+	err = limiter.acquire("3.3.3.3")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "too many streams", status.Convert(err).Message())
+
+	// ended streams free their slot but count against the rate quota
+	limiter.release("1.1.1.1")
+	assert.NoError(t, limiter.acquire("3.3.3.3"))
+	limiter.release("1.1.1.1")
+	err = limiter.acquire("1.1.1.1")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "rate limit exceeded", status.Convert(err).Message())
+	assert.Equal(t, map[string]int{"2.2.2.2": 1, "3.3.3.3": 1}, limiter.peerStreams)
+
+	pool = true
+	err = limiter.acquire("4.4.4.4")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 2, limiter.streams)
+}
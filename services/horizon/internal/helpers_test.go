@@ -30,6 +30,8 @@ func NewTestConfig() Config {
 		ConnectionTimeout: 55 * time.Second, // Default
 		LogLevel:          supportLog.InfoLevel,
 		NetworkPassphrase: network.TestNetworkPassphrase,
+
+		MaxTransactionBatchSize: 100,
 	}
 }
 
@@ -4,12 +4,14 @@ import (
 	"database/sql"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/stellar/go/services/horizon/internal/actions"
 	horizonContext "github.com/stellar/go/services/horizon/internal/context"
 	"github.com/stellar/go/services/horizon/internal/render"
 	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
 	"github.com/stellar/go/services/horizon/internal/render/sse"
+	"github.com/stellar/go/services/horizon/internal/timing"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/hal"
@@ -40,11 +42,7 @@ func (handler ObjectActionHandler) ServeHTTP(
 			return
 		}
 
-		httpjson.Render(
-			w,
-			response,
-			httpjson.HALJSON,
-		)
+		renderHAL(w, r, response)
 		return
 	}
 
@@ -78,11 +76,7 @@ func (handler streamableObjectActionHandler) ServeHTTP(
 			return
 		}
 
-		httpjson.Render(
-			w,
-			response,
-			httpjson.HALJSON,
-		)
+		renderHAL(w, r, response)
 		return
 	case render.MimeEventStream:
 		handler.renderStream(w, r)
@@ -190,6 +184,11 @@ func streamableHistoryPageHandler(
 }
 
 func (handler pageActionHandler) renderPage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get(actions.ParamValidateOnly) == "true" {
+		handler.renderQueryDescription(w, r)
+		return
+	}
+
 	records, err := handler.action.GetResourcePage(w, r)
 	if err != nil {
 		problem.Render(r.Context(), w, err)
@@ -203,16 +202,32 @@ func (handler pageActionHandler) renderPage(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	httpjson.Render(
-		w,
-		page,
-		httpjson.HALJSON,
-	)
+	renderHAL(w, r, page)
+}
+
+// renderHAL renders data as HAL JSON, the time spent is measured as the
+// render phase of the request.
+func renderHAL(w http.ResponseWriter, r *http.Request, data interface{}) {
+	defer timing.FromContext(r.Context()).Since(timing.Render, time.Now())
+	httpjson.Render(w, data, httpjson.HALJSON)
+}
+
+// renderQueryDescription validates the request and renders the description
+// of its query without executing it.
+func (handler pageActionHandler) renderQueryDescription(w http.ResponseWriter, r *http.Request) {
+	description, err := actions.DescribePageQuery(w, r, handler.action.GetResourcePage)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	httpjson.Render(w, description, httpjson.JSON)
 }
 
 func (handler pageActionHandler) renderStream(w http.ResponseWriter, r *http.Request) {
-	// Use pq to Get SSE limit.
-	pq, err := actions.GetPageQuery(r)
+	// Use pq to Get SSE limit. The stop cursor is validated by the actions
+	// which support it, GetResourcePage fails for the other end-points.
+	pq, err := actions.GetPageQuery(r, actions.AllowStopCursor)
 	if err != nil {
 		problem.Render(r.Context(), w, err)
 		return
@@ -243,6 +258,12 @@ func (handler pageActionHandler) renderStream(w http.ResponseWriter, r *http.Req
 			r.Header.Set("Last-Event-ID", pq.Cursor)
 		}
 
+		// When the stream is bounded by a stop cursor it ends once all the
+		// records up to it have been sent.
+		if pq.StopCursor != "" && len(events) == 0 {
+			return events, sse.ErrEndOfStream
+		}
+
 		return events, nil
 	}
 
@@ -276,7 +297,7 @@ func (handler pageActionHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 func buildPage(r *http.Request, records []hal.Pageable) (hal.Page, error) {
 	// Always DisableCursorValidation - we can assume it's valid since the
 	// validation is done in GetResourcePage.
-	pageQuery, err := actions.GetPageQuery(r, actions.DisableCursorValidation)
+	pageQuery, err := actions.GetPageQuery(r, actions.DisableCursorValidation, actions.AllowStopCursor)
 	if err != nil {
 		return hal.Page{}, err
 	}
@@ -21,6 +21,7 @@ import (
 	"github.com/stellar/go/services/horizon/internal/ledger"
 	"github.com/stellar/go/services/horizon/internal/render"
 	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/services/horizon/internal/timing"
 	"github.com/stellar/go/support/db"
 	supportErrors "github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/log"
@@ -48,6 +49,16 @@ func contextMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// pageLimitsMiddleware stores the page limits of the route groups in the
+// context of requests, they are enforced by actions.GetPageQuery.
+func pageLimitsMiddleware(limits actions.PageLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(actions.WithPageLimits(r.Context(), limits)))
+		})
+	}
+}
+
 const (
 	clientNameHeader    = "X-Client-Name"
 	clientVersionHeader = "X-Client-Version"
@@ -81,11 +92,16 @@ func loggerMiddleware(serverMetrics *ServerMetrics) func(next http.Handler) http
 
 			logStartOfRequest(ctx, r, streaming)
 			then := time.Now()
+			ctx, timings := timing.NewContext(ctx)
 
 			next.ServeHTTP(mw, r.WithContext(ctx))
 
 			duration := time.Since(then)
 			logEndOfRequest(ctx, r, serverMetrics.RequestDurationSummary, duration, mw, streaming)
+			// the phases of streams are spread over their whole duration
+			if !streaming {
+				observeRequestPhases(r, serverMetrics.RequestPhaseDurationHistogram, timings)
+			}
 		})
 	}
 }
@@ -94,6 +110,13 @@ func loggerMiddleware(serverMetrics *ServerMetrics) func(next http.Handler) http
 func timeoutMiddleware(timeout time.Duration) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
+			// WebSocket connections are long lived, the timeout applies to
+			// each of their subscriptions instead
+			if isWebSocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			mw := newWrapResponseWriter(w, r)
 			ctx, cancel := context.WithTimeout(r.Context(), timeout)
 			defer func() {
@@ -194,6 +217,23 @@ func logEndOfRequest(ctx context.Context, r *http.Request, requestDurationSummar
 	}).Observe(float64(duration.Seconds()))
 }
 
+// observeRequestPhases records the time spent by the request in each phase.
+func observeRequestPhases(r *http.Request, histogram *prometheus.HistogramVec, timings *timing.Timings) {
+	if histogram == nil {
+		return
+	}
+	routePattern := chi.RouteContext(r.Context()).RoutePattern()
+	if routePattern == "" {
+		routePattern = "undefined"
+	}
+	for phase, duration := range timings.Durations() {
+		histogram.With(prometheus.Labels{
+			"phase": phase,
+			"route": routePattern,
+		}).Observe(duration.Seconds())
+	}
+}
+
 func firstXForwardedFor(r *http.Request) string {
 	return strings.TrimSpace(strings.SplitN(r.Header.Get("X-Forwarded-For"), ",", 2)[0])
 }
@@ -250,6 +290,84 @@ func NewHistoryMiddleware(staleThreshold int32, session *db.Session) func(http.H
 	}
 }
 
+// NewInstanceMiddleware sets the X-Horizon-Instance and
+// X-Horizon-Ledger-Watermark headers on all responses so that clients paging
+// through results behind a load balancer can pin their requests to instances
+// at least as up to date as the one which served the first page. Requests
+// with a X-Horizon-Min-Ledger header above the latest ledger in the history
+// database are rejected with a stale_instance problem.
+func NewInstanceMiddleware(instanceID string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			historyLatest := ledger.CurrentState().HistoryLatest
+			w.Header().Set(actions.InstanceHeaderName, instanceID)
+			w.Header().Set(
+				actions.LedgerWatermarkHeaderName,
+				strconv.FormatInt(int64(historyLatest), 10),
+			)
+
+			if value := r.Header.Get(actions.MinLedgerHeaderName); value != "" {
+				minLedger, err := strconv.ParseInt(value, 10, 32)
+				if err != nil || minLedger < 0 {
+					problem.Render(r.Context(), w, problem.MakeInvalidFieldProblem(
+						actions.MinLedgerHeaderName,
+						supportErrors.New("the header must be a ledger sequence"),
+					))
+					return
+				}
+				if int32(minLedger) > historyLatest {
+					err := hProblem.StaleInstance
+					err.Extras = map[string]interface{}{
+						"history_latest_ledger": historyLatest,
+						"min_ledger":            minLedger,
+					}
+					problem.Render(r.Context(), w, err)
+					return
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// poolMonitor reports if a database connection pool is exhausted, it's
+// implemented by db.PoolMonitor.
+type poolMonitor interface {
+	Exhausted() bool
+}
+
+// NewLoadSheddingMiddleware rejects requests with a server_over_capacity
+// problem while the database connection pool sampled by monitor is
+// exhausted, so that requests fail fast instead of queueing for a connection
+// until they time out. Transaction submissions and the root resource, which
+// is used as a health check, are never rejected.
+func NewLoadSheddingMiddleware(monitor poolMonitor) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !loadSheddingExempt(r) && monitor.Exhausted() {
+				problem.Render(r.Context(), w, hProblem.ServerOverCapacity)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// loadSheddingExempt returns true for the requests which are not rejected by
+// the load shedding middleware.
+func loadSheddingExempt(r *http.Request) bool {
+	path := strings.TrimRight(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodPost:
+		return path == "/transactions" || path == "/transactions/batch"
+	case http.MethodGet, http.MethodHead:
+		return path == ""
+	default:
+		return false
+	}
+}
+
 // StateMiddleware is a middleware which enables a state handler if the state
 // has been initialized.
 // Unless NoStateVerification is set, it ensures that the state (ledger entries)
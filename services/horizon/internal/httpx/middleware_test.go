@@ -0,0 +1,94 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/services/horizon/internal/timing"
+)
+
+func TestLoggerMiddlewareObservesRequestPhases(t *testing.T) {
+	metrics := &ServerMetrics{
+		RequestDurationSummary: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{Name: "requests_duration_seconds"},
+			[]string{"status", "route", "streaming", "method"},
+		),
+		RequestPhaseDurationHistogram: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{Name: "request_phase_duration_seconds"},
+			[]string{"phase", "route"},
+		),
+	}
+
+	router := chi.NewRouter()
+	router.Use(loggerMiddleware(metrics))
+	router.Get("/trades", func(w http.ResponseWriter, r *http.Request) {
+		timings := timing.FromContext(r.Context())
+		timings.Since(timing.XDR, time.Now().Add(-time.Second))
+		renderHAL(w, r, map[string]string{"status": "ok"})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/trades", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, observedPhase(t, metrics.RequestPhaseDurationHistogram, timing.XDR).GetSampleSum() >= 1)
+
+	// streams are not observed
+	r := httptest.NewRequest(http.MethodGet, "/trades", nil)
+	r.Header.Set("Accept", "text/event-stream")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	for _, phase := range timing.Phases {
+		assert.Equal(t, uint64(1), observedPhase(t, metrics.RequestPhaseDurationHistogram, phase).GetSampleCount(), phase)
+	}
+}
+
+func observedPhase(t *testing.T, histogram *prometheus.HistogramVec, phase string) *dto.Histogram {
+	metric := &dto.Metric{}
+	observer := histogram.WithLabelValues(phase, "/trades").(prometheus.Metric)
+	if err := observer.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetHistogram()
+}
+
+type exhaustedPool bool
+
+func (p exhaustedPool) Exhausted() bool {
+	return bool(p)
+}
+
+func TestLoadSheddingMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, tc := range []struct {
+		method   string
+		path     string
+		expected int
+	}{
+		{http.MethodGet, "/ledgers", http.StatusServiceUnavailable},
+		{http.MethodGet, "/transactions", http.StatusServiceUnavailable},
+		{http.MethodPost, "/transactions", http.StatusOK},
+		{http.MethodPost, "/transactions/", http.StatusOK},
+		{http.MethodPost, "/transactions/batch", http.StatusOK},
+		{http.MethodGet, "/", http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		handler := NewLoadSheddingMiddleware(exhaustedPool(true))(ok)
+		handler.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		assert.Equal(t, tc.expected, w.Code, "%s %s", tc.method, tc.path)
+	}
+
+	w := httptest.NewRecorder()
+	handler := NewLoadSheddingMiddleware(exhaustedPool(false))(ok)
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ledgers", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
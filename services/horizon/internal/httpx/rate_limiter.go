@@ -7,6 +7,7 @@ import (
 
 	"github.com/stellar/throttled"
 
+	"github.com/stellar/go/services/horizon/ingest"
 	"github.com/stellar/go/services/horizon/internal/ledger"
 	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
 	"github.com/stellar/go/support/render/problem"
@@ -16,9 +17,13 @@ const lruCacheSize = 50000
 
 type historyLedgerSourceFactory struct {
 	updateFrequency time.Duration
+	eventBus        *ingest.Bus
 }
 
 func (f historyLedgerSourceFactory) Get() ledger.Source {
+	if f.eventBus != nil {
+		return ledger.NewEventSource(f.eventBus, f.updateFrequency)
+	}
 	return ledger.NewHistoryDBSource(f.updateFrequency)
 }
 
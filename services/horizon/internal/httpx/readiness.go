@@ -0,0 +1,98 @@
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/stellar/go/services/horizon/internal/db2/schema"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+)
+
+// ReadinessResponse is the response of the readiness admin end-point. Ready
+// is false, and Reasons lists the failed checks, while the instance should
+// not receive traffic.
+type ReadinessResponse struct {
+	Ready               bool     `json:"ready"`
+	OrderBookLedger     uint32   `json:"order_book_ledger"`
+	HistoryLatestLedger int32    `json:"history_latest_ledger"`
+	CoreLatestLedger    int32    `json:"core_latest_ledger"`
+	Reasons             []string `json:"reasons,omitempty"`
+}
+
+// orderBookLedger is the part of the order book graph used by the readiness
+// checks, the last ledger applied to the graph is 0 until it's loaded.
+type orderBookLedger interface {
+	LastLedger() uint32
+}
+
+// readinessHandler serves the readiness admin end-point. Unlike liveness, an
+// instance is ready only when it can serve requests with up to date data:
+// the order book graph is loaded, the history database is at most
+// maxLedgerLag ledgers behind stellar-core and its schema matches the
+// migrations of this version of horizon. It responds with 503 otherwise so
+// load balancers do not route traffic to replicas which are still warming up.
+type readinessHandler struct {
+	orderBook    orderBookLedger
+	ledgerState  func() ledger.State
+	migrations   func() ([]schema.MigrationStatus, error)
+	maxLedgerLag uint
+}
+
+func (h readinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result := h.check()
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if result.Ready {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(result)
+}
+
+func (h readinessHandler) check() ReadinessResponse {
+	state := h.ledgerState()
+	result := ReadinessResponse{
+		HistoryLatestLedger: state.HistoryLatest,
+		CoreLatestLedger:    state.CoreLatest,
+	}
+	if h.orderBook != nil {
+		result.OrderBookLedger = h.orderBook.LastLedger()
+	}
+
+	if result.OrderBookLedger == 0 {
+		result.Reasons = append(result.Reasons, "order book graph is not loaded")
+	}
+
+	switch {
+	case state.CoreLatest == 0:
+		result.Reasons = append(result.Reasons, "latest ledger of stellar-core is unknown")
+	case state.HistoryLatest == 0:
+		result.Reasons = append(result.Reasons, "history database is empty")
+	case state.CoreLatest-state.HistoryLatest > int32(h.maxLedgerLag):
+		result.Reasons = append(result.Reasons, fmt.Sprintf(
+			"history database is %d ledgers behind stellar-core, at most %d allowed",
+			state.CoreLatest-state.HistoryLatest, h.maxLedgerLag,
+		))
+	}
+
+	statuses, err := h.migrations()
+	if err != nil {
+		result.Reasons = append(result.Reasons, "could not load migrations: "+err.Error())
+	}
+	for _, status := range statuses {
+		if !status.Applied() {
+			result.Reasons = append(result.Reasons, "migration "+status.ID+" is not applied")
+		} else if status.Checksum == "" {
+			result.Reasons = append(result.Reasons, "migration "+status.ID+" is unknown to this version")
+		} else if status.Modified() {
+			result.Reasons = append(result.Reasons, "migration "+status.ID+" was modified since it was applied")
+		}
+	}
+
+	result.Ready = len(result.Reasons) == 0
+	return result
+}
@@ -0,0 +1,112 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/services/horizon/internal/db2/schema"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+)
+
+type fixedOrderBookLedger uint32
+
+func (l fixedOrderBookLedger) LastLedger() uint32 {
+	return uint32(l)
+}
+
+func TestReadinessHandler(t *testing.T) {
+	appliedAt := time.Now()
+	applied := []schema.MigrationStatus{
+		{ID: "1_initial_schema.sql", AppliedAt: &appliedAt, Checksum: "abc"},
+	}
+
+	for _, testCase := range []struct {
+		name            string
+		orderBook       orderBookLedger
+		state           ledger.State
+		migrations      []schema.MigrationStatus
+		migrationsError error
+		expectedReasons []string
+	}{
+		{
+			name:       "ready",
+			orderBook:  fixedOrderBookLedger(100),
+			state:      ledger.State{CoreLatest: 105, HistoryLatest: 100},
+			migrations: applied,
+		},
+		{
+			name:       "cold replica",
+			state:      ledger.State{},
+			migrations: applied,
+			expectedReasons: []string{
+				"order book graph is not loaded",
+				"latest ledger of stellar-core is unknown",
+			},
+		},
+		{
+			name:       "history behind core",
+			orderBook:  fixedOrderBookLedger(100),
+			state:      ledger.State{CoreLatest: 106, HistoryLatest: 100},
+			migrations: applied,
+			expectedReasons: []string{
+				"history database is 6 ledgers behind stellar-core, at most 5 allowed",
+			},
+		},
+		{
+			name:      "migrations mismatch",
+			orderBook: fixedOrderBookLedger(100),
+			state:     ledger.State{CoreLatest: 100, HistoryLatest: 100},
+			migrations: []schema.MigrationStatus{
+				{ID: "1_initial_schema.sql", Checksum: "abc"},
+				{ID: "2_edited.sql", AppliedAt: &appliedAt, Checksum: "abc", AppliedChecksum: "def"},
+				{ID: "99_future.sql", AppliedAt: &appliedAt},
+			},
+			expectedReasons: []string{
+				"migration 1_initial_schema.sql is not applied",
+				"migration 2_edited.sql was modified since it was applied",
+				"migration 99_future.sql is unknown to this version",
+			},
+		},
+		{
+			name:            "migrations error",
+			orderBook:       fixedOrderBookLedger(100),
+			state:           ledger.State{CoreLatest: 100, HistoryLatest: 100},
+			migrationsError: errors.New("connection refused"),
+			expectedReasons: []string{
+				"could not load migrations: connection refused",
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := readinessHandler{
+				orderBook:   testCase.orderBook,
+				ledgerState: func() ledger.State { return testCase.state },
+				migrations: func() ([]schema.MigrationStatus, error) {
+					return testCase.migrations, testCase.migrationsError
+				},
+				maxLedgerLag: 5,
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			var response ReadinessResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, testCase.expectedReasons, response.Reasons)
+			assert.Equal(t, testCase.state.CoreLatest, response.CoreLatestLedger)
+			if len(testCase.expectedReasons) == 0 {
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.True(t, response.Ready)
+			} else {
+				assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+				assert.False(t, response.Ready)
+			}
+		})
+	}
+}
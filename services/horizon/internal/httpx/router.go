@@ -16,9 +16,19 @@ import (
 	"github.com/sebest/xff"
 	"github.com/stellar/throttled"
 
+	"github.com/stellar/go/exp/orderbook"
+	"github.com/stellar/go/services/horizon/ingest"
 	"github.com/stellar/go/services/horizon/internal/actions"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/db2/schema"
+	"github.com/stellar/go/services/horizon/internal/expingest"
+	"github.com/stellar/go/services/horizon/internal/gql"
+	"github.com/stellar/go/services/horizon/internal/jobs"
+	"github.com/stellar/go/services/horizon/internal/ledger"
 	"github.com/stellar/go/services/horizon/internal/paths"
+	"github.com/stellar/go/services/horizon/internal/readonly"
 	"github.com/stellar/go/services/horizon/internal/render/sse"
+	"github.com/stellar/go/services/horizon/internal/schemastats"
 	"github.com/stellar/go/services/horizon/internal/txsub"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/render/problem"
@@ -41,6 +51,43 @@ type RouterConfig struct {
 	CoreGetter         actions.CoreSettingsGetter
 	HorizonVersion     string
 	FriendbotURL       *url.URL
+	ReadOnly           *readonly.Mode
+	SchemaStats        *schemastats.System
+	Jobs               *jobs.Scheduler
+	DBPoolMonitor      *db.PoolMonitor
+	// ShedLoad rejects requests while DBPoolMonitor reports the pool as
+	// exhausted.
+	ShedLoad   bool
+	InstanceID string
+	// AdminDebugToken, when set, enables the SQL plan admin end-point which
+	// requires it as a bearer token.
+	AdminDebugToken string
+	// PageLimits override the default page limits of route groups.
+	PageLimits actions.PageLimits
+	// GapBackfiller, when set, is served by the ledger gaps admin end-point.
+	GapBackfiller *expingest.GapBackfiller
+	// SSEBufferSize is the number of events buffered per stream, streams are
+	// unbuffered when it is 0.
+	SSEBufferSize int
+	// SSESlowConsumerPolicy is applied to the streams whose buffer is full.
+	SSESlowConsumerPolicy sse.SlowConsumerPolicy
+	// EventBus, when set, wakes up the streams when the ingestion of this
+	// instance publishes a new ledger instead of waiting for the next ledger
+	// state update.
+	EventBus *ingest.Bus
+	// LatestLedgerSource is the default source of the latest ledger end-point.
+	LatestLedgerSource ledger.LatestSource
+	// EnableGraphQL serves the GraphQL end-point.
+	EnableGraphQL bool
+	// OrderBookGraph is checked by the readiness admin end-point, the instance
+	// is not ready until the graph is loaded.
+	OrderBookGraph *orderbook.OrderBookGraph
+	// ReadinessMaxLedgerLag is the maximum number of ledgers the history
+	// database may be behind stellar-core for the instance to be ready.
+	ReadinessMaxLedgerLag uint
+	// MaxTransactionBatchSize is the maximum number of envelopes of a batch
+	// submission.
+	MaxTransactionBatchSize uint
 }
 
 type Router struct {
@@ -62,7 +109,7 @@ func NewRouter(config *RouterConfig, serverMetrics *ServerMetrics) (*Router, err
 		}
 	}
 	result.addMiddleware(config, rateLimiter, serverMetrics)
-	result.addRoutes(config, rateLimiter)
+	result.addRoutes(config, rateLimiter, serverMetrics)
 	return &result, nil
 }
 
@@ -84,9 +131,18 @@ func (r *Router) addMiddleware(config *RouterConfig,
 	c := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
 		AllowedHeaders: []string{"*"},
-		ExposedHeaders: []string{"Date"},
+		ExposedHeaders: []string{
+			"Date",
+			actions.InstanceHeaderName,
+			actions.LedgerWatermarkHeaderName,
+		},
 	})
 	r.Use(c.Handler)
+	r.Use(NewInstanceMiddleware(config.InstanceID))
+	r.Use(pageLimitsMiddleware(config.PageLimits))
+	if config.ShedLoad {
+		r.Use(NewLoadSheddingMiddleware(config.DBPoolMonitor))
+	}
 
 	if rateLimitter != nil {
 		r.Use(rateLimitter.RateLimit)
@@ -98,7 +154,7 @@ func (r *Router) addMiddleware(config *RouterConfig,
 	r.Internal.Use(loggerMiddleware(serverMetrics))
 }
 
-func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRateLimiter) {
+func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRateLimiter, serverMetrics *ServerMetrics) {
 	stateMiddleware := StateMiddleware{
 		HorizonSession: config.DBSession,
 	}
@@ -108,11 +164,24 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		NetworkPassphrase:  config.NetworkPassphrase,
 		FriendbotURL:       config.FriendbotURL,
 		HorizonVersion:     config.HorizonVersion,
+		LatestLedgerSource: config.LatestLedgerSource,
 	}})
 
+	r.Method(http.MethodGet, websocketPath, websocketHandler{router: r.Mux})
+
+	ledgerSourceFactory := historyLedgerSourceFactory{
+		updateFrequency: config.SSEUpdateFrequency,
+		eventBus:        config.EventBus,
+	}
 	streamHandler := sse.StreamHandler{
 		RateLimiter:         rateLimiter,
-		LedgerSourceFactory: historyLedgerSourceFactory{updateFrequency: config.SSEUpdateFrequency},
+		LedgerSourceFactory: ledgerSourceFactory,
+		Buffer: sse.BufferOptions{
+			Size:          config.SSEBufferSize,
+			Policy:        config.SSESlowConsumerPolicy,
+			SlowConsumers: serverMetrics.SSESlowConsumersCounter,
+			DroppedEvents: serverMetrics.SSEDroppedEventsCounter,
+		},
 	}
 
 	historyMiddleware := NewHistoryMiddleware(int32(config.StaleThreshold), config.DBSession)
@@ -147,6 +216,7 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		})
 
 		r.Method(http.MethodGet, "/assets", restPageHandler(actions.AssetStatsHandler{}))
+		r.Method(http.MethodGet, "/assets/{asset}/holders", restPageHandler(actions.GetAssetHoldersHandler{}))
 
 		findPaths := ObjectActionHandler{actions.FindPathsHandler{
 			StaleThreshold:       config.StaleThreshold,
@@ -162,6 +232,10 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 			PathFinder:           config.PathFinder,
 		}}
 
+		r.Method(http.MethodPost, "/transactions/check", ObjectActionHandler{actions.CheckTransactionHandler{
+			NetworkPassphrase: config.NetworkPassphrase,
+		}})
+
 		r.Method(http.MethodGet, "/paths", findPaths)
 		r.Method(http.MethodGet, "/paths/strict-receive", findPaths)
 		r.Method(http.MethodGet, "/paths/strict-send", findFixedPaths)
@@ -190,13 +264,24 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		}, streamHandler))
 		r.Method(http.MethodGet, "/accounts/{account_id:\\w+}/trades", streamableHistoryPageHandler(actions.GetTradesHandler{}, streamHandler))
 		r.Method(http.MethodGet, "/accounts/{account_id:\\w+}/transactions", streamableHistoryPageHandler(actions.GetTransactionsHandler{}, streamHandler))
+		r.Method(http.MethodGet, "/accounts/{account_id:\\w+}/thresholds/history", restPageHandler(actions.GetAccountThresholdsHistoryHandler{}))
 	})
+
+	if config.EnableGraphQL {
+		r.With(historyMiddleware).Method(http.MethodPost, "/graphql", gql.NewHandler())
+	}
 	// ledger actions
 	r.Route("/ledgers", func(r chi.Router) {
 		r.Use(historyMiddleware)
 		r.Method(http.MethodGet, "/", streamableHistoryPageHandler(actions.GetLedgersHandler{}, streamHandler))
+		r.Method(http.MethodGet, "/latest", ObjectActionHandler{actions.GetLatestLedgerHandler{
+			DefaultSource: config.LatestLedgerSource,
+		}})
 		r.Route("/{ledger_id}", func(r chi.Router) {
 			r.Method(http.MethodGet, "/", ObjectActionHandler{actions.GetLedgerByIDHandler{}})
+			r.Method(http.MethodGet, "/manifest", ObjectActionHandler{actions.GetLedgerManifestHandler{}})
+			r.Method(http.MethodGet, "/export", ObjectActionHandler{actions.GetLedgerExportHandler{}})
+			r.Method(http.MethodGet, "/order_book", ObjectActionHandler{actions.GetLedgerOrderbookHandler{}})
 			r.Method(http.MethodGet, "/transactions", streamableHistoryPageHandler(actions.GetTransactionsHandler{}, streamHandler))
 			r.Group(func(r chi.Router) {
 				r.Method(http.MethodGet, "/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
@@ -258,6 +343,16 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 	r.Method(http.MethodPost, "/transactions", ObjectActionHandler{actions.SubmitTransactionHandler{
 		Submitter:         config.TxSubmitter,
 		NetworkPassphrase: config.NetworkPassphrase,
+		ReadOnly:          config.ReadOnly,
+	}})
+	r.Method(http.MethodPost, "/transactions/batch", ObjectActionHandler{actions.SubmitTransactionBatchHandler{
+		SubmitTransactionHandler: actions.SubmitTransactionHandler{
+			Submitter:         config.TxSubmitter,
+			NetworkPassphrase: config.NetworkPassphrase,
+			ReadOnly:          config.ReadOnly,
+		},
+		MaxSize:     config.MaxTransactionBatchSize,
+		RateLimiter: rateLimiter,
 	}})
 
 	// Network state related endpoints
@@ -279,6 +374,62 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 
 	// internal
 	r.Internal.Get("/metrics", promhttp.HandlerFor(config.PrometheusRegistry, promhttp.HandlerOpts{}).ServeHTTP)
+	readiness := readinessHandler{
+		ledgerState: ledger.CurrentState,
+		migrations: func() ([]schema.MigrationStatus, error) {
+			return schema.Status(config.DBSession.DB.DB)
+		},
+		maxLedgerLag: config.ReadinessMaxLedgerLag,
+	}
+	if config.OrderBookGraph != nil {
+		readiness.orderBook = config.OrderBookGraph
+	}
+	r.Internal.Method(http.MethodGet, "/ready", readiness)
 	r.Internal.Get("/debug/pprof/heap", pprof.Index)
 	r.Internal.Get("/debug/pprof/profile", pprof.Profile)
+	if config.AdminDebugToken != "" {
+		r.Internal.Method(http.MethodPost, "/debug/sql_plan", sqlPlanHandler{
+			public:  r.Mux,
+			session: config.DBSession,
+			token:   config.AdminDebugToken,
+		})
+	}
+
+	tradeRetentionPolicies := ObjectActionHandler{actions.TradeRetentionPoliciesHandler{
+		HistoryQ: &history.Q{Session: config.DBSession},
+	}}
+	// admin changes are written to the database so they are rejected in
+	// read-only mode
+	adminWrites := r.Internal.With(config.ReadOnly.Middleware)
+	r.Internal.Method(http.MethodGet, "/trade_retention_policies", tradeRetentionPolicies)
+	adminWrites.Method(http.MethodPut, "/trade_retention_policies", tradeRetentionPolicies)
+	adminWrites.Method(http.MethodDelete, "/trade_retention_policies", tradeRetentionPolicies)
+
+	assetWatches := ObjectActionHandler{actions.AssetWatchesHandler{
+		HistoryQ: &history.Q{Session: config.DBSession},
+	}}
+	r.Internal.Method(http.MethodGet, "/asset_watches", assetWatches)
+	adminWrites.Method(http.MethodPut, "/asset_watches", assetWatches)
+	adminWrites.Method(http.MethodDelete, "/asset_watches", assetWatches)
+
+	r.Internal.Method(http.MethodGet, "/state_quarantine", ObjectActionHandler{actions.StateQuarantineHandler{
+		HistoryQ: &history.Q{Session: config.DBSession},
+	}})
+
+	if config.ReadOnly != nil {
+		r.Internal.Method(http.MethodGet, "/read_only", config.ReadOnly)
+		r.Internal.Method(http.MethodPut, "/read_only", config.ReadOnly)
+	}
+
+	if config.SchemaStats != nil {
+		r.Internal.Method(http.MethodGet, "/schema_stats", config.SchemaStats)
+	}
+
+	if config.GapBackfiller != nil {
+		r.Internal.Method(http.MethodGet, "/ledger_gaps", config.GapBackfiller)
+	}
+	if config.Jobs != nil {
+		r.Internal.Method(http.MethodGet, "/jobs", config.Jobs)
+		r.Internal.Method(http.MethodPut, "/jobs", config.Jobs)
+	}
 }
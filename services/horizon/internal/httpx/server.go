@@ -21,7 +21,12 @@ import (
 )
 
 type ServerMetrics struct {
-	RequestDurationSummary *prometheus.SummaryVec
+	RequestDurationSummary  *prometheus.SummaryVec
+	SSESlowConsumersCounter *prometheus.CounterVec
+	SSEDroppedEventsCounter prometheus.Counter
+	// RequestPhaseDurationHistogram exposes the time spent by non-streaming
+	// requests in each phase (see timing.Phases) by route.
+	RequestPhaseDurationHistogram *prometheus.HistogramVec
 }
 
 type TLSConfig struct {
@@ -54,10 +59,13 @@ func init() {
 	problem.RegisterError(context.DeadlineExceeded, hProblem.Timeout)
 	problem.RegisterError(context.Canceled, hProblem.ServiceUnavailable)
 	problem.RegisterError(db.ErrCancelled, hProblem.ServiceUnavailable)
+	problem.RegisterError(db.ErrTimeout, hProblem.Timeout)
 }
 
-func NewServer(serverConfig ServerConfig, routerConfig RouterConfig) (*Server, error) {
-	sm := &ServerMetrics{
+// NewServerMetrics creates the metrics of the http server, they are registered
+// by the app.
+func NewServerMetrics() *ServerMetrics {
+	return &ServerMetrics{
 		RequestDurationSummary: prometheus.NewSummaryVec(
 			prometheus.SummaryOpts{
 				Namespace: "horizon", Subsystem: "http", Name: "requests_duration_seconds",
@@ -65,7 +73,32 @@ func NewServer(serverConfig ServerConfig, routerConfig RouterConfig) (*Server, e
 			},
 			[]string{"status", "route", "streaming", "method"},
 		),
+		SSESlowConsumersCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "horizon", Subsystem: "sse", Name: "slow_consumers_total",
+				Help: "number of streams whose buffer overflowed, by slow consumer policy",
+			},
+			[]string{"policy"},
+		),
+		SSEDroppedEventsCounter: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: "horizon", Subsystem: "sse", Name: "dropped_events_total",
+				Help: "number of stream events dropped because their client was too slow",
+			},
+		),
+		RequestPhaseDurationHistogram: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "horizon", Subsystem: "http", Name: "request_phase_duration_seconds",
+				Help:    "time spent by HTTP requests running database queries (db), decoding XDR (xdr) and rendering JSON (render)",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"phase", "route"},
+		),
 	}
+}
+
+func NewServer(serverConfig ServerConfig, routerConfig RouterConfig) (*Server, error) {
+	sm := NewServerMetrics()
 	router, err := NewRouter(&routerConfig, sm)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,25 @@
+package httpx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/services/horizon/internal/dashboards"
+)
+
+func TestDashboardMetrics(t *testing.T) {
+	metrics := NewServerMetrics()
+	names := dashboards.MetricNames(
+		metrics.RequestDurationSummary,
+		metrics.SSESlowConsumersCounter,
+		metrics.SSEDroppedEventsCounter,
+		metrics.RequestPhaseDurationHistogram,
+	)
+
+	referenced := append(dashboards.Metrics("horizon_http_"), dashboards.Metrics("horizon_sse_")...)
+	assert.NotEmpty(t, referenced)
+	for _, name := range referenced {
+		assert.True(t, names[name], "%s is not exported", name)
+	}
+}
@@ -0,0 +1,149 @@
+package httpx
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/stellar/go/support/db"
+)
+
+// SQLPlanRequest describes the public API request whose queries are
+// explained by the SQL plan admin end-point.
+type SQLPlanRequest struct {
+	// Route is the path of the request, ex. /accounts/{account_id}/trades.
+	Route string `json:"route"`
+	// Params are the query parameters of the request.
+	Params map[string]string `json:"params"`
+}
+
+// SQLPlanQuery is a query run while serving the request with the output of
+// EXPLAIN ANALYZE, one line per element. Plan is empty for queries which are
+// not explained (writes) and Error is set when explaining the query failed.
+type SQLPlanQuery struct {
+	Type  string   `json:"type"`
+	SQL   string   `json:"sql"`
+	Args  []string `json:"args"`
+	Plan  []string `json:"plan,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// SQLPlanResponse is the response of the SQL plan admin end-point. Status is
+// the status code of the public API response.
+type SQLPlanResponse struct {
+	Route   string            `json:"route"`
+	Params  map[string]string `json:"params"`
+	Status  int               `json:"status"`
+	Queries []SQLPlanQuery    `json:"queries"`
+}
+
+// sqlPlanHandler serves the SQL plan admin end-point. It replays a GET
+// request on the public router, records the queries it ran and returns them
+// with their EXPLAIN ANALYZE output from a replica (or the primary database
+// when there is no healthy replica). EXPLAIN ANALYZE runs the queries again
+// so requests must carry the admin debug token.
+type sqlPlanHandler struct {
+	public  http.Handler
+	session *db.Session
+	token   string
+}
+
+func (h sqlPlanHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	var descriptor SQLPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&descriptor); err != nil {
+		http.Error(w, "invalid request descriptor: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !strings.HasPrefix(descriptor.Route, "/") || strings.ContainsAny(descriptor.Route, "?#") {
+		http.Error(w, "route must be an absolute path without query, pass query parameters in params", http.StatusBadRequest)
+		return
+	}
+
+	query := url.Values{}
+	for key, value := range descriptor.Params {
+		query.Set(key, value)
+	}
+	replay, err := http.NewRequest(http.MethodGet, descriptor.Route+"?"+query.Encode(), nil)
+	if err != nil {
+		http.Error(w, "invalid request descriptor: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	replay.Header.Set("Accept", "application/hal+json")
+	recorder := &db.QueryRecorder{}
+	replay = replay.WithContext(db.WithQueryRecorder(r.Context(), recorder))
+
+	response := httptest.NewRecorder()
+	h.public.ServeHTTP(response, replay)
+
+	result := SQLPlanResponse{
+		Route:   descriptor.Route,
+		Params:  descriptor.Params,
+		Status:  response.Code,
+		Queries: []SQLPlanQuery{},
+	}
+	session := h.session.Replica().WithContext(r.Context())
+	for _, recorded := range recorder.Queries() {
+		result.Queries = append(result.Queries, explainQuery(session, recorded))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// authorized returns true if the request carries the admin debug token in
+// the Authorization header.
+func (h sqlPlanHandler) authorized(r *http.Request) bool {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if h.token == "" || !strings.HasPrefix(header, prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, prefix)), []byte(h.token)) == 1
+}
+
+// explainQuery returns the plan of the recorded query. Only read queries are
+// explained because EXPLAIN ANALYZE executes the query.
+func explainQuery(session *db.Session, recorded db.RecordedQuery) SQLPlanQuery {
+	result := SQLPlanQuery{
+		Type: recorded.Type,
+		SQL:  recorded.SQL,
+		Args: make([]string, len(recorded.Args)),
+	}
+	for i, arg := range recorded.Args {
+		result.Args[i] = fmt.Sprintf("%v", arg)
+	}
+
+	if !isReadQuery(recorded) {
+		return result
+	}
+
+	// the placeholders of the recorded query are already replaced, escape
+	// the remaining question marks so they are not replaced again
+	sql := "EXPLAIN (ANALYZE, BUFFERS) " + strings.Replace(recorded.SQL, "?", "??", -1)
+	if err := session.SelectRaw(&result.Plan, sql, recorded.Args...); err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func isReadQuery(recorded db.RecordedQuery) bool {
+	if recorded.Type == "exec" {
+		return false
+	}
+	sql := strings.ToUpper(strings.TrimSpace(recorded.SQL))
+	return strings.HasPrefix(sql, "SELECT") || strings.HasPrefix(sql, "WITH")
+}
@@ -0,0 +1,68 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/support/db"
+)
+
+func TestSQLPlanHandler(t *testing.T) {
+	var replayed *http.Request
+	handler := sqlPlanHandler{
+		public: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			replayed = r
+			w.WriteHeader(http.StatusNotFound)
+		}),
+		session: &db.Session{},
+		token:   "secret",
+	}
+	request := func(authorization, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/debug/sql_plan", strings.NewReader(body))
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	for _, authorization := range []string{"", "secret", "Bearer other", "Basic secret"} {
+		w := request(authorization, `{"route": "/trades"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, authorization)
+	}
+	assert.Nil(t, replayed)
+
+	for _, body := range []string{"{", `{"route": "trades"}`, `{"route": "/trades?limit=1"}`} {
+		w := request("Bearer secret", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.Nil(t, replayed)
+
+	w := request("Bearer secret", `{"route": "/accounts/GABC/trades", "params": {"limit": "200", "order": "desc"}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, replayed)
+	assert.Equal(t, http.MethodGet, replayed.Method)
+	assert.Equal(t, "/accounts/GABC/trades", replayed.URL.Path)
+	assert.Equal(t, "200", replayed.URL.Query().Get("limit"))
+	assert.Equal(t, "desc", replayed.URL.Query().Get("order"))
+
+	var response SQLPlanResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusNotFound, response.Status)
+	assert.Equal(t, "/accounts/GABC/trades", response.Route)
+	assert.Empty(t, response.Queries)
+}
+
+func TestIsReadQuery(t *testing.T) {
+	assert.True(t, isReadQuery(db.RecordedQuery{Type: "select", SQL: "SELECT * FROM history_trades"}))
+	assert.True(t, isReadQuery(db.RecordedQuery{Type: "get", SQL: "  with x AS (SELECT 1) SELECT * FROM x"}))
+	assert.False(t, isReadQuery(db.RecordedQuery{Type: "exec", SQL: "SELECT pg_advisory_lock(1)"}))
+	assert.False(t, isReadQuery(db.RecordedQuery{Type: "query", SQL: "DELETE FROM history_trades"}))
+}
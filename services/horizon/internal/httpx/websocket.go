@@ -0,0 +1,350 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/stellar/go/services/horizon/internal/render"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
+	"github.com/stellar/go/support/log"
+)
+
+// websocketPath is the path of the WebSocket end-point.
+const websocketPath = "/ws"
+
+// maxWebSocketSubscriptions is the maximum number of streams subscribed to
+// at the same time on a WebSocket connection.
+const maxWebSocketSubscriptions = 50
+
+// websocketWriteTimeout is the maximum duration of sending a message to a
+// WebSocket client, the connection is ended when it expires so a client which
+// stopped reading doesn't block the streams of its subscriptions.
+const websocketWriteTimeout = 10 * time.Second
+
+// websocketRequest is a message sent by WebSocket clients. Type is
+// "subscribe", to subscribe to the stream of Path under the ID chosen by the
+// client, or "unsubscribe" to end the subscription ID.
+type websocketRequest struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
+
+// websocketMessage is a message sent to WebSocket clients, it carries an
+// event of the stream of the subscription. Event and Data are the ones of the
+// event-stream, a subscription ends with a "close" or an "error" event.
+type websocketMessage struct {
+	Subscription string      `json:"subscription"`
+	ID           string      `json:"id,omitempty"`
+	Event        string      `json:"event"`
+	Data         interface{} `json:"data"`
+}
+
+// websocketHandler serves WebSocket connections on which clients subscribe
+// to several streams. A subscription is served by router like a GET request
+// of its path with an `Accept: text/event-stream` header, the events of
+// the stream are sent as websocketMessages.
+type websocketHandler struct {
+	router http.Handler
+	// writeTimeout is the maximum duration of sending a message, it's
+	// websocketWriteTimeout when 0.
+	writeTimeout time.Duration
+}
+
+func (handler websocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// the handshake of websocket.Server, unlike websocket.Handler, accepts
+	// any origin like the CORS configuration of the other end-points
+	websocket.Server{
+		Handler: func(ws *websocket.Conn) {
+			handler.serve(r, ws)
+		},
+	}.ServeHTTP(w, r)
+}
+
+// isWebSocketUpgrade returns true for the requests opening a WebSocket
+// connection.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// websocketConn holds the subscriptions of a connection.
+type websocketConn struct {
+	ws           *websocket.Conn
+	writeTimeout time.Duration
+	// sendMutex serializes the messages sent by the subscriptions and
+	// protects closed
+	sendMutex sync.Mutex
+	closed    bool
+	// mutex protects subscriptions
+	mutex         sync.Mutex
+	subscriptions map[string]*websocketSubscription
+	wg            sync.WaitGroup
+}
+
+func (handler websocketHandler) serve(r *http.Request, ws *websocket.Conn) {
+	// the read timeout of the http server doesn't apply to the connection,
+	// the write deadline is set for each message
+	if err := ws.SetReadDeadline(time.Time{}); err != nil {
+		log.Ctx(r.Context()).WithError(err).Warn("could not reset WebSocket read deadline")
+	}
+
+	conn := &websocketConn{
+		ws:            ws,
+		writeTimeout:  handler.writeTimeout,
+		subscriptions: map[string]*websocketSubscription{},
+	}
+	if conn.writeTimeout == 0 {
+		conn.writeTimeout = websocketWriteTimeout
+	}
+	defer func() {
+		conn.unsubscribeAll()
+		conn.wg.Wait()
+	}()
+
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			if err != io.EOF {
+				log.Ctx(r.Context()).WithError(err).Debug("WebSocket connection closed")
+			}
+			return
+		}
+
+		var request websocketRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			conn.sendError("", "invalid message: "+err.Error())
+			continue
+		}
+		if request.ID == "" {
+			conn.sendError("", "id is required")
+			continue
+		}
+
+		switch request.Type {
+		case "subscribe":
+			handler.subscribe(r, conn, request)
+		case "unsubscribe":
+			if !conn.unsubscribe(request.ID) {
+				conn.sendError(request.ID, "unknown subscription")
+			}
+		default:
+			conn.sendError(request.ID, fmt.Sprintf("unknown message type: %q", request.Type))
+		}
+	}
+}
+
+// subscribe starts the subscription of request, its stream is served from a
+// goroutine until it ends or the subscription is cancelled.
+func (handler websocketHandler) subscribe(r *http.Request, conn *websocketConn, request websocketRequest) {
+	target, err := url.ParseRequestURI(request.Path)
+	if err != nil || target.IsAbs() || strings.TrimSuffix(target.Path, "/") == websocketPath {
+		conn.sendError(request.ID, "path must be the absolute path of a streaming end-point")
+		return
+	}
+
+	// subscriptions don't inherit the values of the connection context, ex.
+	// its chi route context, they are cancelled when the connection ends
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription := &websocketSubscription{
+		conn:   conn,
+		id:     request.ID,
+		cancel: cancel,
+		header: http.Header{},
+	}
+	if err := conn.add(subscription); err != nil {
+		cancel()
+		conn.sendError(request.ID, err.Error())
+		return
+	}
+
+	streamRequest := &http.Request{
+		Method:     http.MethodGet,
+		URL:        target,
+		Proto:      r.Proto,
+		ProtoMajor: r.ProtoMajor,
+		ProtoMinor: r.ProtoMinor,
+		Header:     subscriptionHeader(r.Header),
+		Host:       r.Host,
+		RemoteAddr: r.RemoteAddr,
+		RequestURI: target.RequestURI(),
+		TLS:        r.TLS,
+	}
+	streamRequest = streamRequest.WithContext(sse.NewEventWriterContext(ctx, subscription))
+
+	conn.wg.Add(1)
+	go func() {
+		defer conn.wg.Done()
+		defer conn.remove(subscription)
+		handler.router.ServeHTTP(subscription, streamRequest)
+		subscription.finish()
+	}()
+}
+
+// subscriptionHeader returns the header of the requests of the subscriptions
+// of a connection opened with the given header.
+func subscriptionHeader(header http.Header) http.Header {
+	result := http.Header{}
+	for name, values := range header {
+		switch {
+		case name == "Connection", name == "Upgrade", name == "Accept-Encoding",
+			strings.HasPrefix(name, "Sec-Websocket-"):
+			continue
+		}
+		result[name] = append([]string(nil), values...)
+	}
+	result.Set("Accept", render.MimeEventStream)
+	return result
+}
+
+func (conn *websocketConn) add(subscription *websocketSubscription) error {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	if _, ok := conn.subscriptions[subscription.id]; ok {
+		return fmt.Errorf("subscription %s already exists", subscription.id)
+	}
+	if len(conn.subscriptions) >= maxWebSocketSubscriptions {
+		return fmt.Errorf("too many subscriptions, the maximum is %d", maxWebSocketSubscriptions)
+	}
+	conn.subscriptions[subscription.id] = subscription
+	return nil
+}
+
+// remove removes the ended subscription, its id can be reused once it's
+// removed.
+func (conn *websocketConn) remove(subscription *websocketSubscription) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	subscription.cancel()
+	if conn.subscriptions[subscription.id] == subscription {
+		delete(conn.subscriptions, subscription.id)
+	}
+}
+
+// unsubscribe cancels the subscription id, its stream sends the "close"
+// event. The id can't be reused until then. It returns false if there is no
+// such subscription.
+func (conn *websocketConn) unsubscribe(id string) bool {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	subscription, ok := conn.subscriptions[id]
+	if ok {
+		subscription.cancel()
+	}
+	return ok
+}
+
+func (conn *websocketConn) unsubscribeAll() {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	for _, subscription := range conn.subscriptions {
+		subscription.cancel()
+	}
+}
+
+// send sends message to the client. The connection is closed when the
+// message can't be sent before the write timeout, the read loop then ends it
+// and cancels its subscriptions, the following messages are dropped.
+func (conn *websocketConn) send(message websocketMessage) {
+	conn.sendMutex.Lock()
+	defer conn.sendMutex.Unlock()
+	if conn.closed {
+		return
+	}
+
+	err := conn.ws.SetWriteDeadline(time.Now().Add(conn.writeTimeout))
+	if err == nil {
+		err = websocket.JSON.Send(conn.ws, message)
+	}
+	if err != nil {
+		conn.closed = true
+		conn.ws.Close()
+	}
+}
+
+func (conn *websocketConn) sendError(subscription string, data interface{}) {
+	conn.send(websocketMessage{Subscription: subscription, Event: "error", Data: data})
+}
+
+// websocketSubscription is the response writer of the stream of a
+// subscription, it implements sse.EventWriter to send the events of the
+// stream to the connection.
+type websocketSubscription struct {
+	conn   *websocketConn
+	id     string
+	cancel context.CancelFunc
+
+	header http.Header
+	status int
+	// body holds the response written instead of a stream, ex. the problem
+	// of a request which failed before the stream was started.
+	body      bytes.Buffer
+	streaming bool
+}
+
+// WriteEvent implements sse.EventWriter.
+func (s *websocketSubscription) WriteEvent(e sse.Event) {
+	s.streaming = true
+	message := websocketMessage{
+		Subscription: s.id,
+		ID:           e.ID,
+		Event:        e.Event,
+		Data:         e.Data,
+	}
+	if e.Error != nil {
+		message.Event = "error"
+		message.Data = e.Error.Error()
+	} else if message.Event == "" {
+		message.Event = "message"
+	}
+	// the id of the subscription can be reused as soon as the client
+	// receives the event ending it
+	if message.Event == "close" || message.Event == "error" {
+		s.conn.remove(s)
+	}
+	s.conn.send(message)
+}
+
+func (s *websocketSubscription) Header() http.Header {
+	return s.header
+}
+
+func (s *websocketSubscription) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+}
+
+func (s *websocketSubscription) Write(data []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.body.Write(data)
+}
+
+// finish sends the response of a request which didn't stream, ex. a problem,
+// as the error ending the subscription.
+func (s *websocketSubscription) finish() {
+	if s.streaming || s.status == 0 {
+		return
+	}
+
+	var data interface{} = fmt.Sprintf("%d %s", s.status, http.StatusText(s.status))
+	switch body := bytes.TrimSpace(s.body.Bytes()); {
+	case json.Valid(body):
+		data = json.RawMessage(body)
+	case len(body) > 0:
+		data = string(body)
+	}
+	s.conn.remove(s)
+	s.conn.sendError(s.id, data)
+}
@@ -0,0 +1,175 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
+)
+
+type testWebSocketMessage struct {
+	Subscription string          `json:"subscription"`
+	ID           string          `json:"id"`
+	Event        string          `json:"event"`
+	Data         json.RawMessage `json:"data"`
+}
+
+// dialTestWebSocket opens a WebSocket connection to a server streaming the
+// objects of a testPageAction, the returned function closes it.
+func dialTestWebSocket(t *testing.T) (*websocket.Conn, func()) {
+	ledgerSource := ledger.NewTestingSource(3)
+	action := &testPageAction{
+		objects:      map[uint32][]string{3: {"a", "b", "c"}},
+		ledgerSource: ledgerSource,
+	}
+	streamHandler := sse.StreamHandler{LedgerSourceFactory: &testingFactory{ledgerSource}}
+
+	mux := chi.NewMux()
+	mux.Method(http.MethodGet, "/test", streamableHistoryPageHandler(action, streamHandler))
+	mux.Method(http.MethodGet, "/rest", restPageHandler(action))
+	mux.Method(http.MethodGet, websocketPath, websocketHandler{router: mux})
+	server := httptest.NewServer(mux)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + websocketPath
+	ws, err := websocket.Dial(url, "", server.URL)
+	if err != nil {
+		server.Close()
+		t.Fatalf("could not dial WebSocket: %v", err)
+	}
+	return ws, func() {
+		ws.Close()
+		server.Close()
+	}
+}
+
+func sendWebSocketRequest(t *testing.T, ws *websocket.Conn, request websocketRequest) {
+	require.NoError(t, websocket.JSON.Send(ws, request))
+}
+
+func receiveWebSocketMessage(t *testing.T, ws *websocket.Conn) testWebSocketMessage {
+	var message testWebSocketMessage
+	require.NoError(t, websocket.JSON.Receive(ws, &message))
+	return message
+}
+
+func TestWebSocketSubscriptions(t *testing.T) {
+	ws, closeWebSocket := dialTestWebSocket(t)
+	defer closeWebSocket()
+
+	sendWebSocketRequest(t, ws, websocketRequest{Type: "subscribe", ID: "first", Path: "/test?cursor=1"})
+	assert.Equal(t, testWebSocketMessage{
+		Subscription: "first", Event: "open", Data: json.RawMessage(`"hello"`),
+	}, receiveWebSocketMessage(t, ws))
+	for _, expected := range []testWebSocketMessage{
+		{Subscription: "first", ID: "2", Event: "message", Data: json.RawMessage(`{"value":"b"}`)},
+		{Subscription: "first", ID: "3", Event: "message", Data: json.RawMessage(`{"value":"c"}`)},
+	} {
+		assert.Equal(t, expected, receiveWebSocketMessage(t, ws))
+	}
+
+	// the id of an active subscription can't be reused
+	sendWebSocketRequest(t, ws, websocketRequest{Type: "subscribe", ID: "first", Path: "/test"})
+	assert.Equal(t, testWebSocketMessage{
+		Subscription: "first", Event: "error", Data: json.RawMessage(`"subscription first already exists"`),
+	}, receiveWebSocketMessage(t, ws))
+
+	sendWebSocketRequest(t, ws, websocketRequest{Type: "subscribe", ID: "second", Path: "/test?cursor=2&limit=1"})
+	for _, expected := range []testWebSocketMessage{
+		{Subscription: "second", Event: "open", Data: json.RawMessage(`"hello"`)},
+		{Subscription: "second", ID: "3", Event: "message", Data: json.RawMessage(`{"value":"c"}`)},
+		{Subscription: "second", Event: "close", Data: json.RawMessage(`"byebye"`)},
+	} {
+		assert.Equal(t, expected, receiveWebSocketMessage(t, ws))
+	}
+
+	sendWebSocketRequest(t, ws, websocketRequest{Type: "unsubscribe", ID: "first"})
+	assert.Equal(t, testWebSocketMessage{
+		Subscription: "first", Event: "close", Data: json.RawMessage(`"byebye"`),
+	}, receiveWebSocketMessage(t, ws))
+
+	// ended subscriptions are removed
+	sendWebSocketRequest(t, ws, websocketRequest{Type: "unsubscribe", ID: "second"})
+	assert.Equal(t, testWebSocketMessage{
+		Subscription: "second", Event: "error", Data: json.RawMessage(`"unknown subscription"`),
+	}, receiveWebSocketMessage(t, ws))
+}
+
+func TestWebSocketInvalidRequests(t *testing.T) {
+	ws, closeWebSocket := dialTestWebSocket(t)
+	defer closeWebSocket()
+
+	require.NoError(t, websocket.Message.Send(ws, "{"))
+	message := receiveWebSocketMessage(t, ws)
+	assert.Equal(t, "error", message.Event)
+	assert.Contains(t, string(message.Data), "invalid message")
+
+	for _, testCase := range []struct {
+		request  websocketRequest
+		expected string
+	}{
+		{websocketRequest{Type: "subscribe", Path: "/test"}, `"id is required"`},
+		{websocketRequest{Type: "watch", ID: "a"}, `"unknown message type: \"watch\""`},
+		{websocketRequest{Type: "subscribe", ID: "a", Path: "test"}, `"path must be the absolute path of a streaming end-point"`},
+		{websocketRequest{Type: "subscribe", ID: "a", Path: websocketPath}, `"path must be the absolute path of a streaming end-point"`},
+		{websocketRequest{Type: "subscribe", ID: "a", Path: "/missing"}, `"404 page not found"`},
+	} {
+		sendWebSocketRequest(t, ws, testCase.request)
+		message := receiveWebSocketMessage(t, ws)
+		assert.Equal(t, "error", message.Event)
+		assert.Equal(t, testCase.expected, string(message.Data))
+	}
+
+	// the problems of requests failing before streaming are sent as is
+	sendWebSocketRequest(t, ws, websocketRequest{Type: "subscribe", ID: "a", Path: "/rest"})
+	message = receiveWebSocketMessage(t, ws)
+	assert.Equal(t, "a", message.Subscription)
+	assert.Equal(t, "error", message.Event)
+	var problem struct {
+		Status int `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(message.Data, &problem))
+	assert.Equal(t, http.StatusNotAcceptable, problem.Status)
+}
+
+func TestWebSocketWriteTimeout(t *testing.T) {
+	// the stream sends large events until the subscription is cancelled
+	data := strings.Repeat("x", 64*1024)
+	mux := chi.NewMux()
+	mux.Method(http.MethodGet, "/flood", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subscription := w.(*websocketSubscription)
+		for r.Context().Err() == nil {
+			subscription.WriteEvent(sse.Event{Data: data})
+		}
+	}))
+	done := make(chan struct{})
+	mux.Method(http.MethodGet, websocketPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		websocketHandler{router: mux, writeTimeout: 100 * time.Millisecond}.ServeHTTP(w, r)
+		close(done)
+	}))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + websocketPath
+	ws, err := websocket.Dial(url, "", server.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	// the client doesn't read the events, the connection is ended once a
+	// message can't be sent before the write timeout
+	sendWebSocketRequest(t, ws, websocketRequest{Type: "subscribe", ID: "a", Path: "/flood"})
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the connection of the client which doesn't read wasn't ended")
+	}
+}
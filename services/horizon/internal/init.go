@@ -4,21 +4,33 @@ import (
 	"context"
 	"net/http"
 	"runtime"
+	"time"
 
 	"github.com/getsentry/raven-go"
+	"github.com/jmoiron/sqlx"
 	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/stellar/go/clients/horizonclient"
 	"github.com/stellar/go/exp/orderbook"
+	"github.com/stellar/go/services/horizon/ingest"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/expingest"
+	"github.com/stellar/go/services/horizon/internal/expingest/processors"
+	"github.com/stellar/go/services/horizon/internal/grpcapi"
+	"github.com/stellar/go/services/horizon/internal/jobs"
 	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/paths"
 	"github.com/stellar/go/services/horizon/internal/simplepath"
 	"github.com/stellar/go/services/horizon/internal/txsub"
 	"github.com/stellar/go/services/horizon/internal/txsub/sequence"
 	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/log"
 )
 
-func mustNewDBSession(databaseURL string, maxIdle, maxOpen int) *db.Session {
+func mustNewDBSession(databaseURL string, maxIdle, maxOpen int, maxLifetime time.Duration) *db.Session {
 	session, err := db.Open("postgres", databaseURL)
 	if err != nil {
 		log.Fatalf("cannot open Horizon DB: %v", err)
@@ -26,6 +38,7 @@ func mustNewDBSession(databaseURL string, maxIdle, maxOpen int) *db.Session {
 
 	session.DB.SetMaxIdleConns(maxIdle)
 	session.DB.SetMaxOpenConns(maxOpen)
+	session.DB.SetConnMaxLifetime(maxLifetime)
 	return session
 }
 
@@ -47,17 +60,58 @@ func mustInitHorizonDB(app *App) {
 		app.config.DatabaseURL,
 		maxIdle,
 		maxOpen,
+		app.config.HorizonDBConnMaxLifetime,
 	)}
+	app.dbPoolMonitor = db.NewPoolMonitor("horizon", app.historyQ.Session.DB)
+	app.historyQ.Session.QueryTimeout = app.config.HorizonDBQueryTimeout
+	app.historyQ.Session.Metrics = db.NewQueryMetrics("horizon")
+	if app.config.HorizonDBStatementCacheSize > 0 {
+		app.historyQ.Session.Statements = db.NewStatementCache(app.config.HorizonDBStatementCacheSize)
+	}
+
+	if len(app.config.ReplicaDatabaseURLs) > 0 {
+		var replicas []*sqlx.DB
+		for _, url := range app.config.ReplicaDatabaseURLs {
+			session := mustNewDBSession(url, maxIdle, maxOpen, app.config.HorizonDBConnMaxLifetime)
+			replicas = append(replicas, session.DB)
+		}
+		app.historyQ.Session.Replicas = db.NewReplicaSet(replicas...)
+	}
 }
 
 func initExpIngester(app *App) {
-	var err error
-	app.expingester, err = expingest.NewSystem(expingest.Config{
+	dataQualityRules, err := processors.ParseDataQualityRules(app.config.IngestDataQualityRules)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	transactionFilter, err := processors.ParseTransactionFilter(
+		app.config.IngestFilterAccounts, app.config.IngestFilterAssets,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if app.config.IngestSkipFailedTransactions {
+		transactionFilter = processors.SkipFailedTransactions(transactionFilter)
+	}
+
+	ingestionProfile, err := processors.ParseIngestionProfile(app.config.IngestProfile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tradeConflicts, err := history.ParseTradeConflictMode(app.config.IngestTradeConflicts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	config := expingest.Config{
 		CoreSession: mustNewDBSession(
-			app.config.StellarCoreDatabaseURL, expingest.MaxDBConnections, expingest.MaxDBConnections,
+			app.config.StellarCoreDatabaseURL, expingest.MaxDBConnections, expingest.MaxDBConnections, 0,
 		),
 		HistorySession: mustNewDBSession(
 			app.config.DatabaseURL, expingest.MaxDBConnections, expingest.MaxDBConnections,
+			app.config.HorizonDBConnMaxLifetime,
 		),
 		NetworkPassphrase: app.config.NetworkPassphrase,
 		// TODO:
@@ -70,21 +124,53 @@ func initExpIngester(app *App) {
 		StellarCoreConfigPath:    app.config.StellarCoreConfigPath,
 		RemoteCaptiveCoreURL:     app.config.RemoteCaptiveCoreURL,
 		DisableStateVerification: app.config.IngestDisableStateVerification,
-	})
+		ReadOnly:                 app.readOnly,
+		DataQualityRules:         dataQualityRules,
+		TransactionFilter:        transactionFilter,
+		IngestionProfile:         ingestionProfile,
+		TradeConflicts:           tradeConflicts,
+		EnableAssetWatches:       app.config.IngestAssetWatches,
+		AutoResetNetwork:         app.config.AutoResetTestnet,
+		Plugins:                  ingest.Plugins(),
+		EventBus:                 ingest.Events,
+	}
+	config.LatestNetworkLedger = func() uint32 {
+		// updated by UpdateLedgerState from the stellar-core info
+		return uint32(ledger.CurrentState().CoreLatest)
+	}
+	config.CaptiveCoreReadAheadLedgers = int(app.config.CaptiveCoreReadAheadLedgers)
+	config.CaptiveCoreReadAheadBytes = int64(app.config.CaptiveCoreReadAheadBytes)
+	if app.config.IngestPauseDuringCatchup {
+		config.CatchupPause = expingest.NewCatchupPause()
+	}
 
+	app.expingester, err = expingest.NewSystem(config)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	if app.config.IngestGapBackfillInterval > 0 {
+		app.gapBackfiller = expingest.NewGapBackfiller(config)
+	}
 }
 
 func initPathFinder(app *App) {
 	orderBookGraph := orderbook.NewOrderBookGraph()
+	app.orderBookGraph = orderBookGraph
 	app.orderBookStream = expingest.NewOrderBookStream(
 		&history.Q{app.HorizonSession(app.ctx)},
 		orderBookGraph,
 	)
 
 	app.paths = simplepath.NewInMemoryFinder(orderBookGraph)
+	if app.config.PathCacheMaxAge > 0 {
+		app.pathsCache = paths.NewCachedFinder(
+			app.paths,
+			orderBookGraph,
+			uint32(app.config.PathCacheMaxAge),
+		)
+		app.paths = app.pathsCache
+	}
 }
 
 // initSentry initialized the default sentry client with the configured DSN
@@ -212,7 +298,13 @@ func initDbMetrics(app *App) {
 	)
 	app.prometheusRegistry.MustRegister(app.dbWaitDurationCounter)
 
+	app.historyQ.Session.Metrics.Register(app.prometheusRegistry)
+	app.dbPoolMonitor.Register(app.prometheusRegistry)
+
 	app.prometheusRegistry.MustRegister(app.orderBookStream.LatestLedgerGauge)
+	if app.pathsCache != nil {
+		app.prometheusRegistry.MustRegister(app.pathsCache.RequestsCounter)
+	}
 }
 
 // initIngestMetrics registers the metrics for the ingestion into the provided
@@ -226,6 +318,12 @@ func initIngestMetrics(app *App) {
 	app.prometheusRegistry.MustRegister(app.expingester.Metrics().LedgerIngestionDuration)
 	app.prometheusRegistry.MustRegister(app.expingester.Metrics().StateVerifyDuration)
 	app.prometheusRegistry.MustRegister(app.expingester.Metrics().StateInvalidGauge)
+	app.prometheusRegistry.MustRegister(app.expingester.Metrics().DataQualityViolationsCounter)
+	app.prometheusRegistry.MustRegister(app.expingester.Metrics().LedgersIngestedCounter)
+	app.prometheusRegistry.MustRegister(app.expingester.Metrics().LedgerIngestionLatency)
+	app.prometheusRegistry.MustRegister(app.expingester.Metrics().ProcessorDuration)
+	app.prometheusRegistry.MustRegister(app.expingester.Metrics().LedgersBehindGauge)
+	app.prometheusRegistry.MustRegister(app.expingester.Metrics().StateQuarantinedEntriesGauge)
 }
 
 func initTxSubMetrics(app *App) {
@@ -242,15 +340,141 @@ func initTxSubMetrics(app *App) {
 
 func initWebMetrics(app *App) {
 	app.prometheusRegistry.MustRegister(app.webServer.Metrics.RequestDurationSummary)
+	app.prometheusRegistry.MustRegister(app.webServer.Metrics.SSESlowConsumersCounter)
+	app.prometheusRegistry.MustRegister(app.webServer.Metrics.SSEDroppedEventsCounter)
+	app.prometheusRegistry.MustRegister(app.webServer.Metrics.RequestPhaseDurationHistogram)
 }
 
 func initSubmissionSystem(app *App) {
+	sequenceSource, err := txsub.ParseSequenceSource(app.config.TxSubSequenceSource)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var sequenceProvider txsub.SequenceProvider
+	switch sequenceSource {
+	case txsub.SequenceSourceCore:
+		sequenceProvider = &txsub.CoreSequenceProvider{
+			Session: mustNewDBSession(
+				app.config.StellarCoreDatabaseURL,
+				app.config.HorizonDBMaxIdleConnections,
+				app.config.HorizonDBMaxOpenConnections,
+				0,
+			),
+		}
+	case txsub.SequenceSourceRemote:
+		sequenceProvider = &txsub.RemoteSequenceProvider{
+			Client: &horizonclient.Client{
+				HorizonURL: app.config.TxSubRemoteHorizonURL,
+				HTTP:       &http.Client{Timeout: 10 * time.Second},
+			},
+		}
+	}
+
 	app.submitter = &txsub.System{
-		Pending:         txsub.NewDefaultSubmissionList(),
-		Submitter:       txsub.NewDefaultSubmitter(http.DefaultClient, app.config.StellarCoreURL),
-		SubmissionQueue: sequence.NewManager(),
+		Pending:          txsub.NewDefaultSubmissionList(),
+		Submitter:        txsub.NewDefaultSubmitter(http.DefaultClient, app.config.StellarCoreURL),
+		SubmissionQueue:  sequence.NewManager(),
+		SequenceProvider: sequenceProvider,
 		DB: func(ctx context.Context) txsub.HorizonDB {
 			return &history.Q{Session: app.HorizonSession(ctx)}
 		},
 	}
 }
+
+func initJobs(app *App) {
+	app.jobs = jobs.New(app.HorizonSession(context.Background()))
+	app.jobs.Add(jobs.Job{
+		Name:      "reap",
+		Interval:  time.Hour,
+		Exclusive: true,
+		Run: func(ctx context.Context) error {
+			// history is not reaped in read-only mode
+			if app.readOnly.Enabled() {
+				return nil
+			}
+			return app.reaper.DeleteUnretainedHistory()
+		},
+	})
+	// each instance sheds its own load so the job is not exclusive
+	app.jobs.Add(jobs.Job{
+		Name:      "db_pool_monitor",
+		Interval:  time.Second,
+		Immediate: true,
+		Run: func(ctx context.Context) error {
+			app.dbPoolMonitor.Sample()
+			return nil
+		},
+	})
+	// the audit is run by the instance ingesting the next checkpoint ledger,
+	// which can be any ingesting instance, so the job is not exclusive
+	if app.expingester != nil && app.config.IngestStateAuditInterval > 0 {
+		app.jobs.Add(jobs.Job{
+			Name:     "state_audit",
+			Interval: app.config.IngestStateAuditInterval,
+			Run: func(ctx context.Context) error {
+				app.expingester.RequestStateAudit()
+				return nil
+			},
+		})
+	}
+	// instances claim the gaps they backfill in the database, so they can
+	// backfill different gaps at the same time and the job is not exclusive
+	if app.gapBackfiller != nil {
+		app.jobs.Add(jobs.Job{
+			Name:      "ledger_gap_backfill",
+			Interval:  app.config.IngestGapBackfillInterval,
+			Immediate: true,
+			Run:       app.gapBackfiller.Run,
+		})
+	}
+	// statistics are exported by each instance so the job is not exclusive
+	if app.config.SchemaStatsInterval > 0 {
+		app.jobs.Add(jobs.Job{
+			Name:      "schema_stats",
+			Interval:  app.config.SchemaStatsInterval,
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				return app.schemaStats.Update()
+			},
+		})
+	}
+}
+
+func initGRPCServer(app *App) error {
+	if app.config.GRPCPort == 0 {
+		return nil
+	}
+
+	var opts []grpc.ServerOption
+	if app.config.TLSCert != "" && app.config.TLSKey != "" {
+		creds, err := credentials.NewServerTLSFromFile(app.config.TLSCert, app.config.TLSKey)
+		if err != nil {
+			return errors.Wrap(err, "could not load TLS certificate of the gRPC server")
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	config := grpcapi.Config{
+		Session: app.HorizonSession(context.Background()),
+		LedgerSource: func() ledger.Source {
+			if app.expingester != nil {
+				return ledger.NewEventSource(ingest.Events, app.config.SSEUpdateFrequency)
+			}
+			return ledger.NewHistoryDBSource(app.config.SSEUpdateFrequency)
+		},
+		MaxStreams:        app.config.GRPCMaxStreams,
+		MaxStreamsPerPeer: app.config.GRPCMaxStreamsPerIP,
+		RateQuota:         app.config.RateQuota,
+	}
+	if app.config.HorizonDBShedLoad {
+		config.PoolMonitor = app.dbPoolMonitor
+	}
+
+	var err error
+	app.grpcServer, err = grpcapi.NewServer(config, opts...)
+	if err != nil {
+		return errors.Wrap(err, "could not create the gRPC server")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Package jobs contains the background job scheduler of horizon. Jobs like
+// reaping history or collecting schema statistics run periodically from the
+// app ticker. Exclusive jobs run on a single horizon instance at a time: the
+// instance running them holds a postgres advisory lock for the duration of
+// the run and the other instances skip it. Jobs can be triggered and paused
+// through an admin end-point.
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stellar/go/support/db"
+)
+
+// Job is a task run periodically by the Scheduler.
+type Job struct {
+	// Name identifies the job in logs, metrics and the admin end-point.
+	Name string
+	// Interval is the minimum time between the start of two runs.
+	Interval time.Duration
+	// Immediate runs the job on the first tick of the scheduler instead of
+	// waiting for Interval to elapse.
+	Immediate bool
+	// Exclusive jobs only run on one horizon instance sharing the database
+	// at a time.
+	Exclusive bool
+	// Run runs the job. It's cancelled when ctx is done.
+	Run func(ctx context.Context) error
+}
+
+// Status is the admin representation of a job.
+type Status struct {
+	Name            string     `json:"name"`
+	IntervalSeconds float64    `json:"interval_seconds"`
+	Exclusive       bool       `json:"exclusive"`
+	Paused          bool       `json:"paused"`
+	Running         bool       `json:"running"`
+	NextRun         time.Time  `json:"next_run"`
+	LastRun         *time.Time `json:"last_run"`
+	LastError       string     `json:"last_error,omitempty"`
+}
+
+// Scheduler runs the jobs added to it when they are due. It is safe for
+// concurrent use.
+type Scheduler struct {
+	// Session is used to hold the advisory locks of exclusive jobs.
+	Session *db.Session
+
+	mutex sync.Mutex
+	jobs  []*scheduledJob
+
+	runsCounter     *prometheus.CounterVec
+	durationSummary *prometheus.SummaryVec
+}
+
+type scheduledJob struct {
+	Job
+
+	paused    bool
+	triggered bool
+	running   bool
+	nextRun   time.Time
+	lastRun   time.Time
+	lastError error
+}
+
+// New initializes the scheduler. Exclusive jobs are locked using session.
+func New(session *db.Session) *Scheduler {
+	return &Scheduler{
+		Session: session,
+		runsCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "horizon", Subsystem: "jobs", Name: "runs_total",
+				Help: "number of runs of background jobs by result (success, error or skipped when " +
+					"an exclusive job is running on another instance)",
+			},
+			[]string{"job", "result"},
+		),
+		durationSummary: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: "horizon", Subsystem: "jobs", Name: "duration_seconds",
+				Help: "duration of the runs of background jobs",
+			},
+			[]string{"job"},
+		),
+	}
+}
@@ -0,0 +1,239 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stellar/go/services/horizon/internal/errors"
+	supporterrors "github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+)
+
+// ErrUnknownJob is returned when a job is not added to the scheduler.
+var ErrUnknownJob = supporterrors.New("unknown job")
+
+// RegisterMetrics registers the job metrics in the given registry.
+func (s *Scheduler) RegisterMetrics(registry *prometheus.Registry) {
+	registry.MustRegister(s.runsCounter)
+	registry.MustRegister(s.durationSummary)
+}
+
+// Add adds job to the scheduler.
+func (s *Scheduler) Add(job Job) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	scheduled := &scheduledJob{Job: job}
+	if !job.Immediate {
+		scheduled.nextRun = time.Now().Add(job.Interval)
+	}
+	s.jobs = append(s.jobs, scheduled)
+}
+
+// Tick runs the jobs which are due and waits for them to finish.
+func (s *Scheduler) Tick(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.due(time.Now()) {
+		wg.Add(1)
+		go func(job *scheduledJob) {
+			s.run(ctx, job)
+			wg.Done()
+		}(job)
+	}
+	wg.Wait()
+}
+
+// due returns the jobs to run at now and marks them as running.
+func (s *Scheduler) due(now time.Time) []*scheduledJob {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var due []*scheduledJob
+	for _, job := range s.jobs {
+		if job.running || !(job.triggered || (!job.paused && !now.Before(job.nextRun))) {
+			continue
+		}
+		job.running = true
+		job.triggered = false
+		due = append(due, job)
+	}
+	return due
+}
+
+func (s *Scheduler) run(ctx context.Context, job *scheduledJob) {
+	start := time.Now()
+	ran, err := s.runOnce(ctx, job)
+
+	result := "success"
+	switch {
+	case !ran:
+		result = "skipped"
+	case err != nil:
+		result = "error"
+		log.WithField("job", job.Name).Errorf("job failed: %s", err)
+	}
+	s.runsCounter.WithLabelValues(job.Name, result).Inc()
+	if ran {
+		s.durationSummary.WithLabelValues(job.Name).Observe(time.Since(start).Seconds())
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	job.running = false
+	job.nextRun = start.Add(job.Interval)
+	if ran {
+		job.lastRun = start
+		job.lastError = err
+	}
+}
+
+// runOnce runs job, returning false if it's an exclusive job running on
+// another instance.
+func (s *Scheduler) runOnce(ctx context.Context, job *scheduledJob) (ran bool, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			ran = true
+			err = errors.FromPanic(rec)
+			errors.ReportToSentry(err, nil)
+		}
+	}()
+
+	if job.Exclusive {
+		// the lock is released when the transaction is rolled back, or when
+		// the connection is closed if horizon is killed during the run
+		session := s.Session.Clone()
+		if err = session.Begin(); err != nil {
+			return true, supporterrors.Wrap(err, "could not begin lock transaction")
+		}
+		defer session.Rollback()
+
+		var locked bool
+		if err = session.GetRaw(&locked, "SELECT pg_try_advisory_xact_lock(?)", lockID(job.Name)); err != nil {
+			return true, supporterrors.Wrap(err, "could not acquire lock")
+		}
+		if !locked {
+			return false, nil
+		}
+	}
+
+	return true, job.Run(ctx)
+}
+
+// lockID returns the advisory lock key of the job called name.
+func lockID(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("horizon-job:" + name))
+	return int64(hash.Sum64())
+}
+
+func (s *Scheduler) find(name string) (*scheduledJob, error) {
+	for _, job := range s.jobs {
+		if job.Name == name {
+			return job, nil
+		}
+	}
+	return nil, ErrUnknownJob
+}
+
+// Trigger runs the job called name on the next tick, even if it's paused.
+func (s *Scheduler) Trigger(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, err := s.find(name)
+	if err != nil {
+		return err
+	}
+	job.triggered = true
+	return nil
+}
+
+// SetPaused pauses or resumes the job called name. A run in progress is not
+// interrupted.
+func (s *Scheduler) SetPaused(name string, paused bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, err := s.find(name)
+	if err != nil {
+		return err
+	}
+	if job.paused != paused {
+		log.WithField("job", name).WithField("paused", paused).Info("Changing job state")
+	}
+	job.paused = paused
+	return nil
+}
+
+// Statuses returns the state of the jobs.
+func (s *Scheduler) Statuses() []Status {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	statuses := make([]Status, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		status := Status{
+			Name:            job.Name,
+			IntervalSeconds: job.Interval.Seconds(),
+			Exclusive:       job.Exclusive,
+			Paused:          job.paused,
+			Running:         job.running,
+			NextRun:         job.nextRun,
+		}
+		if job.triggered {
+			status.NextRun = time.Now()
+		}
+		if !job.lastRun.IsZero() {
+			lastRun := job.lastRun
+			status.LastRun = &lastRun
+		}
+		if job.lastError != nil {
+			status.LastError = job.lastError.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// ServeHTTP renders the state of the jobs as JSON. PUT requests first
+// trigger, pause or resume the job of the `name` parameter according to the
+// `action` parameter.
+func (s *Scheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		name := r.FormValue("name")
+		var err error
+		switch r.FormValue("action") {
+		case "trigger":
+			err = s.Trigger(name)
+		case "pause":
+			err = s.SetPaused(name, true)
+		case "resume":
+			err = s.SetPaused(name, false)
+		default:
+			http.Error(w, "action must be trigger, pause or resume", http.StatusBadRequest)
+			return
+		}
+		if err == ErrUnknownJob {
+			http.Error(w, "unknown job: "+name, http.StatusNotFound)
+			return
+		}
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.Statuses()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerTick(t *testing.T) {
+	s := New(nil)
+	runs := map[string]int{}
+	s.Add(Job{
+		Name:     "hourly",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			runs["hourly"]++
+			return nil
+		},
+	})
+	s.Add(Job{
+		Name:      "immediate",
+		Interval:  time.Hour,
+		Immediate: true,
+		Run: func(ctx context.Context) error {
+			runs["immediate"]++
+			return errors.New("failed")
+		},
+	})
+
+	s.Tick(context.Background())
+	assert.Equal(t, map[string]int{"immediate": 1}, runs)
+	s.Tick(context.Background())
+	assert.Equal(t, map[string]int{"immediate": 1}, runs, "job run before Interval elapsed")
+
+	statuses := s.Statuses()
+	require.Len(t, statuses, 2)
+	assert.Nil(t, statuses[0].LastRun)
+	assert.Equal(t, float64(3600), statuses[0].IntervalSeconds)
+	assert.NotNil(t, statuses[1].LastRun)
+	assert.Equal(t, "failed", statuses[1].LastError)
+
+	// triggered jobs run on the next tick, even when paused
+	require.NoError(t, s.SetPaused("hourly", true))
+	require.NoError(t, s.Trigger("hourly"))
+	s.Tick(context.Background())
+	assert.Equal(t, map[string]int{"immediate": 1, "hourly": 1}, runs)
+	s.Tick(context.Background())
+	assert.Equal(t, map[string]int{"immediate": 1, "hourly": 1}, runs)
+
+	assert.Equal(t, ErrUnknownJob, s.Trigger("unknown"))
+	assert.Equal(t, ErrUnknownJob, s.SetPaused("unknown", true))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(s.runsCounter.WithLabelValues("hourly", "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(s.runsCounter.WithLabelValues("immediate", "error")))
+}
+
+func TestSchedulerPausedJobs(t *testing.T) {
+	s := New(nil)
+	runs := 0
+	s.Add(Job{
+		Name:      "immediate",
+		Interval:  time.Hour,
+		Immediate: true,
+		Run: func(ctx context.Context) error {
+			runs++
+			panic("job panicked")
+		},
+	})
+
+	require.NoError(t, s.SetPaused("immediate", true))
+	s.Tick(context.Background())
+	assert.Equal(t, 0, runs)
+
+	// panics are recovered and reported as errors
+	require.NoError(t, s.SetPaused("immediate", false))
+	s.Tick(context.Background())
+	assert.Equal(t, 1, runs)
+	assert.Equal(t, "job panicked", s.Statuses()[0].LastError)
+	assert.False(t, s.Statuses()[0].Running)
+}
+
+func TestSchedulerServeHTTP(t *testing.T) {
+	s := New(nil)
+	s.Add(Job{
+		Name:     "hourly",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			return nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var statuses []Status
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &statuses))
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "hourly", statuses[0].Name)
+	assert.False(t, statuses[0].Paused)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/jobs?name=hourly&action=pause", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &statuses))
+	assert.True(t, statuses[0].Paused)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/jobs?name=hourly&action=stop", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/jobs?name=unknown&action=trigger", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/jobs", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
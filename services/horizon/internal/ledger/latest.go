@@ -0,0 +1,41 @@
+package ledger
+
+import (
+	"github.com/stellar/go/support/errors"
+)
+
+// LatestSource selects which view of the latest ledger is reported: the
+// latest ledger closed by stellar-core or the latest ledger ingested in
+// Horizon's history. They differ when the ingestion is behind stellar-core,
+// for example when it runs on another instance.
+type LatestSource string
+
+const (
+	// LatestSourceIngested is the latest ledger of Horizon's history, the
+	// latest ledger whose history can be queried.
+	LatestSourceIngested LatestSource = "ingested"
+	// LatestSourceCore is the latest ledger closed by stellar-core, which
+	// may not be ingested yet.
+	LatestSourceCore LatestSource = "core"
+)
+
+// ParseLatestSource returns the source called name, "" is
+// LatestSourceIngested.
+func ParseLatestSource(name string) (LatestSource, error) {
+	switch source := LatestSource(name); source {
+	case "":
+		return LatestSourceIngested, nil
+	case LatestSourceIngested, LatestSourceCore:
+		return source, nil
+	default:
+		return "", errors.Errorf("unknown latest ledger source: %s", name)
+	}
+}
+
+// Latest returns the latest ledger of the view selected by source.
+func (s State) Latest(source LatestSource) int32 {
+	if source == LatestSourceCore {
+		return s.CoreLatest
+	}
+	return s.HistoryLatest
+}
@@ -0,0 +1,28 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLatestSource(t *testing.T) {
+	for name, expected := range map[string]LatestSource{
+		"":         LatestSourceIngested,
+		"ingested": LatestSourceIngested,
+		"core":     LatestSourceCore,
+	} {
+		source, err := ParseLatestSource(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, source)
+	}
+
+	_, err := ParseLatestSource("history")
+	assert.EqualError(t, err, "unknown latest ledger source: history")
+}
+
+func TestStateLatest(t *testing.T) {
+	state := State{CoreLatest: 10, HistoryLatest: 8}
+	assert.Equal(t, int32(10), state.Latest(LatestSourceCore))
+	assert.Equal(t, int32(8), state.Latest(LatestSourceIngested))
+}
@@ -3,6 +3,8 @@ package ledger
 import (
 	"sync"
 	"time"
+
+	"github.com/stellar/go/services/horizon/ingest"
 )
 
 // Source exposes two helpers methods to help you find out the current
@@ -128,3 +130,71 @@ func (source *TestingSource) NextLedger(currentSequence uint32) chan uint32 {
 }
 
 func (source *TestingSource) Close() {}
+
+// EventSource is a Source woken up by the ledger ingested events published by
+// the live ingestion instead of polling the ledger state. The ledger state is
+// still checked every fallbackFrequency because only the instance holding
+// the ingestion lock publishes events.
+type EventSource struct {
+	fallbackFrequency time.Duration
+	currentState      currentStateFunc
+	subscription      *ingest.Subscription
+}
+
+// eventSourceBuffer is the number of events buffered between two NextLedger
+// calls, the events which don't fit delay the stream until the next fallback
+// check.
+const eventSourceBuffer = 32
+
+// NewEventSource constructs a new instance of EventSource subscribed to bus.
+func NewEventSource(bus *ingest.Bus, fallbackFrequency time.Duration) *EventSource {
+	return &EventSource{
+		fallbackFrequency: fallbackFrequency,
+		currentState:      CurrentState,
+		subscription:      bus.Subscribe(eventSourceBuffer),
+	}
+}
+
+// CurrentLedger returns the current ledger.
+func (source *EventSource) CurrentLedger() uint32 {
+	return source.currentState().ExpHistoryLatest
+}
+
+// NextLedger returns a channel which yields every time there is a new ledger with a sequence number larger than currentSequence.
+func (source *EventSource) NextLedger(currentSequence uint32) chan uint32 {
+	newLedgers := make(chan uint32, 1)
+	go func() {
+		var fallback <-chan time.Time
+		if source.fallbackFrequency > 0 {
+			ticker := time.NewTicker(source.fallbackFrequency)
+			defer ticker.Stop()
+			fallback = ticker.C
+		}
+
+		for {
+			select {
+			case event, ok := <-source.subscription.C:
+				if !ok {
+					return
+				}
+				if event.Type == ingest.LedgerIngested && event.Sequence > currentSequence {
+					newLedgers <- event.Sequence
+					return
+				}
+			case <-fallback:
+				if latest := source.currentState().ExpHistoryLatest; latest > currentSequence {
+					newLedgers <- latest
+					return
+				}
+			}
+		}
+	}()
+
+	return newLedgers
+}
+
+// Close unsubscribes the source from the events and stops the internal go
+// routines.
+func (source *EventSource) Close() {
+	source.subscription.Close()
+}
@@ -2,6 +2,9 @@ package ledger
 
 import (
 	"testing"
+	"time"
+
+	"github.com/stellar/go/services/horizon/ingest"
 )
 
 func Test_HistoryDBLedgerSourceCurrentLedger(t *testing.T) {
@@ -41,3 +44,40 @@ func Test_HistoryDBLedgerSourceNextLedger(t *testing.T) {
 		t.Errorf("NextLedger = %d, want 3", nextLedger)
 	}
 }
+
+func Test_EventLedgerSourceNextLedger(t *testing.T) {
+	bus := ingest.NewBus()
+	ledgerSource := NewEventSource(bus, 0)
+	defer ledgerSource.Close()
+
+	ledgerChan := ledgerSource.NextLedger(3)
+	bus.Publish(
+		ingest.Event{Type: ingest.LedgerIngested, Sequence: 3},
+		ingest.Event{Type: ingest.TableChanged, Sequence: 4, Table: "accounts", Created: 1},
+		ingest.Event{Type: ingest.LedgerIngested, Sequence: 4},
+	)
+
+	nextLedger := <-ledgerChan
+	if nextLedger != 4 {
+		t.Errorf("NextLedger = %d, want 4", nextLedger)
+	}
+}
+
+func Test_EventLedgerSourceNextLedgerFallback(t *testing.T) {
+	state := State{
+		ExpHistoryLatest: 5,
+	}
+
+	ledgerSource := NewEventSource(ingest.NewBus(), time.Millisecond)
+	ledgerSource.currentState = func() State {
+		return state
+	}
+	defer ledgerSource.Close()
+
+	ledgerChan := ledgerSource.NextLedger(4)
+
+	nextLedger := <-ledgerChan
+	if nextLedger != 5 {
+		t.Errorf("NextLedger = %d, want 5", nextLedger)
+	}
+}
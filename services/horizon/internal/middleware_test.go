@@ -359,3 +359,56 @@ func TestCheckHistoryStaleMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestInstanceMiddleware(t *testing.T) {
+	ledger.SetState(ledger.State{CoreLatest: 6, HistoryLatest: 5})
+	defer ledger.SetState(ledger.State{})
+
+	endpoint := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	handler := httpx.NewInstanceMiddleware("horizon-1")(http.HandlerFunc(endpoint))
+
+	for _, testCase := range []struct {
+		name           string
+		minLedger      string
+		expectedStatus int
+	}{
+		{
+			name:           "succeeds without min ledger",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "succeeds when min ledger is ingested",
+			minLedger:      "5",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "responds with a service unavailable if min ledger is not ingested",
+			minLedger:      "6",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "responds with a bad request if min ledger is invalid",
+			minLedger:      "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, err := http.NewRequest("GET", "http://localhost", nil)
+			assert.NoError(t, err)
+			if testCase.minLedger != "" {
+				request.Header.Set(actions.MinLedgerHeaderName, testCase.minLedger)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, request)
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, "horizon-1", w.Header().Get(actions.InstanceHeaderName))
+			assert.Equal(t, "5", w.Header().Get(actions.LedgerWatermarkHeaderName))
+			if testCase.expectedStatus == http.StatusServiceUnavailable {
+				assert.Contains(t, w.Body.String(), "stale_instance")
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package paths
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stellar/go/xdr"
+)
+
+// maxCacheEntries is the maximum number of queries cached by a CachedFinder.
+// Once reached, results are not cached until older entries expire.
+const maxCacheEntries = 10000
+
+// LedgerSource returns the last ledger of the order book used to find paths.
+type LedgerSource interface {
+	LastLedger() uint32
+}
+
+var _ Finder = (*CachedFinder)(nil)
+
+// CachedFinder is a Finder memoizing the paths found for identical queries.
+// Wallets repeat the same queries every ledger while users are on the send
+// screen and the results only change when the order book does. Paths found
+// at ledger L are returned (with ledger L) until the order book reaches ledger
+// L+maxAge, so a maxAge of 1 only reuses results within the same ledger.
+// Returned paths are shared between callers and must not be modified.
+type CachedFinder struct {
+	// RequestsCounter counts queries answered from the cache (result "hit")
+	// and by the underlying finder (result "miss").
+	RequestsCounter *prometheus.CounterVec
+
+	finder  Finder
+	ledgers LedgerSource
+	maxAge  uint32
+
+	lock    sync.Mutex
+	entries map[string]cacheEntry
+	// ledger is the order book ledger at which expired entries were removed
+	ledger uint32
+}
+
+type cacheEntry struct {
+	paths  []Path
+	ledger uint32
+}
+
+// NewCachedFinder constructs a CachedFinder caching the results of finder for
+// maxAge ledgers. ledgers returns the current ledger of the order book used
+// by finder.
+func NewCachedFinder(finder Finder, ledgers LedgerSource, maxAge uint32) *CachedFinder {
+	return &CachedFinder{
+		RequestsCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "horizon", Subsystem: "path_finding", Name: "cache_requests_total",
+				Help: "number of path finding queries answered from the cache (hit) or not (miss)",
+			},
+			[]string{"result"},
+		),
+		finder:  finder,
+		ledgers: ledgers,
+		maxAge:  maxAge,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+// Find implements the path payments finder interface
+func (f *CachedFinder) Find(q Query, maxLength uint) ([]Path, uint32, error) {
+	var key strings.Builder
+	fmt.Fprintf(
+		&key, "receive|%d|%s|%d|%t|",
+		maxLength, q.DestinationAsset.String(), q.DestinationAmount, q.ValidateSourceBalance,
+	)
+	if q.SourceAccount != nil {
+		key.WriteString(q.SourceAccount.Address())
+	}
+	for i, asset := range q.SourceAssets {
+		fmt.Fprintf(&key, "|%s", asset.String())
+		if i < len(q.SourceAssetBalances) {
+			fmt.Fprintf(&key, ":%d", q.SourceAssetBalances[i])
+		}
+	}
+
+	return f.find(key.String(), func() ([]Path, uint32, error) {
+		return f.finder.Find(q, maxLength)
+	})
+}
+
+// FindFixedPaths implements the path payments finder interface
+func (f *CachedFinder) FindFixedPaths(
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAssets []xdr.Asset,
+	maxLength uint,
+) ([]Path, uint32, error) {
+	var key strings.Builder
+	fmt.Fprintf(&key, "send|%d|%s|%d", maxLength, sourceAsset.String(), amountToSpend)
+	for _, asset := range destinationAssets {
+		fmt.Fprintf(&key, "|%s", asset.String())
+	}
+
+	return f.find(key.String(), func() ([]Path, uint32, error) {
+		return f.finder.FindFixedPaths(sourceAsset, amountToSpend, destinationAssets, maxLength)
+	})
+}
+
+func (f *CachedFinder) find(key string, find func() ([]Path, uint32, error)) ([]Path, uint32, error) {
+	// the order book is empty while it's loaded, there is nothing to cache
+	if ledger := f.ledgers.LastLedger(); ledger > 0 {
+		if entry, ok := f.get(key, ledger); ok {
+			f.RequestsCounter.WithLabelValues("hit").Inc()
+			return entry.paths, entry.ledger, nil
+		}
+	}
+
+	f.RequestsCounter.WithLabelValues("miss").Inc()
+	paths, lastLedger, err := find()
+	if err == nil && lastLedger > 0 {
+		f.put(key, cacheEntry{paths: paths, ledger: lastLedger})
+	}
+	return paths, lastLedger, err
+}
+
+// expired returns true if entry can't be returned at ledger. Entries found
+// at later ledgers are expired too because the order book is reset when its
+// stream is restarted.
+func (f *CachedFinder) expired(entry cacheEntry, ledger uint32) bool {
+	return entry.ledger > ledger || ledger-entry.ledger >= f.maxAge
+}
+
+func (f *CachedFinder) get(key string, ledger uint32) (cacheEntry, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if ledger != f.ledger {
+		for k, entry := range f.entries {
+			if f.expired(entry, ledger) {
+				delete(f.entries, k)
+			}
+		}
+		f.ledger = ledger
+	}
+
+	entry, ok := f.entries[key]
+	if !ok || f.expired(entry, ledger) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (f *CachedFinder) put(key string, entry cacheEntry) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// paths found at an older ledger than the one seen by get may already
+	// be expired
+	if len(f.entries) >= maxCacheEntries || f.ledger >= entry.ledger+f.maxAge {
+		return
+	}
+	f.entries[key] = entry
+}
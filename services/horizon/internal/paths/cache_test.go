@@ -0,0 +1,116 @@
+package paths
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLedgerSource struct {
+	ledger uint32
+}
+
+func (m *mockLedgerSource) LastLedger() uint32 {
+	return m.ledger
+}
+
+func TestCachedFinderFind(t *testing.T) {
+	usd := xdr.MustNewCreditAsset("USD", "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	native := xdr.MustNewNativeAsset()
+	query := Query{
+		DestinationAsset:    usd,
+		DestinationAmount:   100,
+		SourceAssets:        []xdr.Asset{native},
+		SourceAssetBalances: []xdr.Int64{0},
+	}
+	otherQuery := query
+	otherQuery.DestinationAmount = 200
+
+	found := []Path{{Source: native, SourceAmount: 10, Destination: usd, DestinationAmount: 100}}
+	finder := &MockFinder{}
+	ledgers := &mockLedgerSource{ledger: 10}
+	cached := NewCachedFinder(finder, ledgers, 2)
+
+	finder.On("Find", query, uint(3)).Return(found, uint32(10), nil).Once()
+	finder.On("Find", otherQuery, uint(3)).Return([]Path{}, uint32(10), nil).Once()
+
+	for i := 0; i < 2; i++ {
+		paths, ledger, err := cached.Find(query, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, found, paths)
+		assert.Equal(t, uint32(10), ledger)
+	}
+	paths, ledger, err := cached.Find(otherQuery, 3)
+	assert.NoError(t, err)
+	assert.Empty(t, paths)
+	assert.Equal(t, uint32(10), ledger)
+
+	// results are returned with the ledger they were found at
+	ledgers.ledger = 11
+	paths, ledger, err = cached.Find(query, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, found, paths)
+	assert.Equal(t, uint32(10), ledger)
+	finder.AssertExpectations(t)
+
+	// results older than max age are found again
+	ledgers.ledger = 12
+	finder.On("Find", query, uint(3)).Return(found, uint32(12), nil).Once()
+	paths, ledger, err = cached.Find(query, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, found, paths)
+	assert.Equal(t, uint32(12), ledger)
+	finder.AssertExpectations(t)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(cached.RequestsCounter.WithLabelValues("hit")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(cached.RequestsCounter.WithLabelValues("miss")))
+}
+
+func TestCachedFinderFindFixedPaths(t *testing.T) {
+	usd := xdr.MustNewCreditAsset("USD", "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	native := xdr.MustNewNativeAsset()
+	destinations := []xdr.Asset{usd}
+
+	found := []Path{{Source: native, SourceAmount: 10, Destination: usd, DestinationAmount: 100}}
+	finder := &MockFinder{}
+	ledgers := &mockLedgerSource{}
+	cached := NewCachedFinder(finder, ledgers, 1)
+
+	// nothing is cached while the order book is empty
+	finder.On("FindFixedPaths", native, xdr.Int64(10), destinations, uint(3)).
+		Return([]Path{}, uint32(0), nil).Twice()
+	for i := 0; i < 2; i++ {
+		paths, ledger, err := cached.FindFixedPaths(native, 10, destinations, 3)
+		assert.NoError(t, err)
+		assert.Empty(t, paths)
+		assert.Equal(t, uint32(0), ledger)
+	}
+	finder.AssertExpectations(t)
+
+	// errors are not cached
+	ledgers.ledger = 5
+	finder.On("FindFixedPaths", native, xdr.Int64(20), destinations, uint(3)).
+		Return([]Path{}, uint32(5), assert.AnError).Once()
+	finder.On("FindFixedPaths", native, xdr.Int64(20), destinations, uint(3)).
+		Return(found, uint32(5), nil).Once()
+	_, _, err := cached.FindFixedPaths(native, 20, destinations, 3)
+	assert.Equal(t, assert.AnError, err)
+	for i := 0; i < 2; i++ {
+		paths, ledger, err := cached.FindFixedPaths(native, 20, destinations, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, found, paths)
+		assert.Equal(t, uint32(5), ledger)
+	}
+	finder.AssertExpectations(t)
+
+	// a reset order book expires all the results
+	ledgers.ledger = 4
+	finder.On("FindFixedPaths", native, xdr.Int64(20), destinations, uint(3)).
+		Return(found, uint32(4), nil).Once()
+	_, ledger, err := cached.FindFixedPaths(native, 20, destinations, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(4), ledger)
+	finder.AssertExpectations(t)
+}
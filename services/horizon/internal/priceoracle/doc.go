@@ -0,0 +1,7 @@
+// Package priceoracle provides the prices of assets as needed by horizon
+// features valuing amounts of an asset in another one. Most importantly, it
+// provides the Oracle interface, allowing for pluggable price sources: the
+// built-in "trades" oracle using recent trades ingested by horizon, the
+// "http" oracle querying an external service and oracles registered with
+// Register.
+package priceoracle
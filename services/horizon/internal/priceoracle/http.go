@@ -0,0 +1,107 @@
+package priceoracle
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// httpOracleTimeout is the maximum duration of a price request.
+const httpOracleTimeout = 10 * time.Second
+
+var _ Oracle = (*HTTPOracle)(nil)
+
+// HTTPOracle is an oracle adapter querying the prices from an external
+// service. Prices are requested with a GET to URL with the base_asset_type,
+// base_asset_code, base_asset_issuer, counter_asset_type,
+// counter_asset_code and counter_asset_issuer query parameters. The service
+// responds with a JSON object with the decimal price as a string and the time
+// it was observed in the RFC 3339 format:
+//
+//	{"price": "0.1234567", "updated_at": "2020-06-01T12:00:00Z"}
+//
+// or with a 404 status when it doesn't know the price of the pair.
+type HTTPOracle struct {
+	URL    string
+	Client *http.Client
+}
+
+type httpOraclePrice struct {
+	Price     string    `json:"price"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// newHTTPOracle constructs an HTTPOracle, options is the URL of the service.
+func newHTTPOracle(historyQ *history.Q, options string) (Oracle, error) {
+	u, err := url.Parse(options)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("the http oracle requires an absolute http or https URL")
+	}
+	return &HTTPOracle{URL: options, Client: &http.Client{Timeout: httpOracleTimeout}}, nil
+}
+
+// Price implements Oracle.
+func (o *HTTPOracle) Price(ctx context.Context, base, counter xdr.Asset) (Price, error) {
+	u, err := url.Parse(o.URL)
+	if err != nil {
+		return Price{}, errors.Wrap(err, "invalid oracle URL")
+	}
+	query := u.Query()
+	if err = addAssetParams(query, "base_", base); err != nil {
+		return Price{}, err
+	}
+	if err = addAssetParams(query, "counter_", counter); err != nil {
+		return Price{}, err
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Price{}, errors.Wrap(err, "could not create request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return Price{}, errors.Wrap(err, "could not request price")
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return Price{}, ErrNoPrice
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return Price{}, errors.Errorf("oracle responded with status %d", resp.StatusCode)
+	}
+
+	var body httpOraclePrice
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Price{}, errors.Wrap(err, "could not decode price")
+	}
+	value, ok := new(big.Rat).SetString(body.Price)
+	if !ok || value.Sign() <= 0 {
+		return Price{}, errors.Errorf("invalid price: %s", body.Price)
+	}
+	return Price{Value: value, UpdatedAt: body.UpdatedAt}, nil
+}
+
+func addAssetParams(query url.Values, prefix string, asset xdr.Asset) error {
+	var assetType, code, issuer string
+	if err := asset.Extract(&assetType, &code, &issuer); err != nil {
+		return errors.Wrap(err, "could not extract asset")
+	}
+	query.Set(prefix+"asset_type", assetType)
+	if asset.Type != xdr.AssetTypeAssetTypeNative {
+		query.Set(prefix+"asset_code", code)
+		query.Set(prefix+"asset_issuer", issuer)
+	}
+	return nil
+}
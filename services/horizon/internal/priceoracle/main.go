@@ -0,0 +1,91 @@
+package priceoracle
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// ErrNoPrice is returned by oracles which don't know the price of an asset
+// pair.
+var ErrNoPrice = errors.New("no price for the asset pair")
+
+// Price is the price of one unit of a base asset in a counter asset.
+type Price struct {
+	Value *big.Rat
+	// UpdatedAt is the time the price was observed, for example the close
+	// time of the ledger of the trade it was derived from.
+	UpdatedAt time.Time
+}
+
+// Oracle provides the prices of asset pairs.
+type Oracle interface {
+	// Price returns the price of one unit of base in counter, or ErrNoPrice
+	// when it's unknown.
+	Price(ctx context.Context, base, counter xdr.Asset) (Price, error)
+}
+
+// Factory constructs an oracle from its options, which are specific to each
+// oracle. historyQ gives oracles access to the horizon database.
+type Factory func(historyQ *history.Q, options string) (Oracle, error)
+
+var (
+	factoriesLock sync.RWMutex
+	factories     = map[string]Factory{}
+)
+
+func init() {
+	Register("trades", newTradesOracle)
+	Register("http", newHTTPOracle)
+}
+
+// Register makes an oracle available by name to New. It's meant to be called
+// from the init function of the package implementing the oracle and panics
+// when the name is already registered.
+func Register(name string, factory Factory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+
+	if factory == nil {
+		panic("priceoracle: Register factory is nil")
+	}
+	if _, exists := factories[name]; exists {
+		panic("priceoracle: Register called twice for oracle " + name)
+	}
+	factories[name] = factory
+}
+
+// Names returns the sorted names of the registered oracles.
+func Names() []string {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New constructs the oracle registered as name with its options.
+func New(name, options string, historyQ *history.Q) (Oracle, error) {
+	factoriesLock.RLock()
+	factory, ok := factories[name]
+	factoriesLock.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("unknown price oracle: %s", name)
+	}
+
+	oracle, err := factory(historyQ, options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create %s price oracle", name)
+	}
+	return oracle, nil
+}
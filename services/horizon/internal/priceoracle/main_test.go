@@ -0,0 +1,104 @@
+package priceoracle
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+const issuer = "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+
+type fixedOracle struct {
+	price Price
+}
+
+func (o fixedOracle) Price(ctx context.Context, base, counter xdr.Asset) (Price, error) {
+	return o.price, nil
+}
+
+func TestRegister(t *testing.T) {
+	assert.Equal(t, []string{"http", "trades"}, Names())
+
+	Register("fixed", func(historyQ *history.Q, options string) (Oracle, error) {
+		return fixedOracle{Price{Value: big.NewRat(1, 2)}}, nil
+	})
+	defer func() {
+		factoriesLock.Lock()
+		delete(factories, "fixed")
+		factoriesLock.Unlock()
+	}()
+	assert.Equal(t, []string{"fixed", "http", "trades"}, Names())
+
+	oracle, err := New("fixed", "", nil)
+	assert.NoError(t, err)
+	price, err := oracle.Price(context.Background(), xdr.MustNewNativeAsset(), xdr.MustNewCreditAsset("USD", issuer))
+	assert.NoError(t, err)
+	assert.Equal(t, "1/2", price.Value.String())
+
+	assert.Panics(t, func() {
+		Register("fixed", func(historyQ *history.Q, options string) (Oracle, error) {
+			return nil, nil
+		})
+	})
+
+	_, err = New("unknown", "", nil)
+	assert.EqualError(t, err, "unknown price oracle: unknown")
+	_, err = New("trades", "", nil)
+	assert.EqualError(t, err, "could not create trades price oracle: the trades oracle requires the horizon database")
+	_, err = New("trades", "-1h", &history.Q{})
+	assert.EqualError(t, err, "could not create trades price oracle: maximum trade age must be positive")
+	_, err = New("http", "localhost", nil)
+	assert.EqualError(t, err, "could not create http price oracle: the http oracle requires an absolute http or https URL")
+}
+
+func TestHTTPOracle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "USD", query.Get("counter_asset_code"))
+		assert.Equal(t, issuer, query.Get("counter_asset_issuer"))
+		switch query.Get("base_asset_type") {
+		case "native":
+			assert.Equal(t, "", query.Get("base_asset_code"))
+			w.Write([]byte(`{"price": "0.125", "updated_at": "2020-06-01T12:00:00Z"}`))
+		case "credit_alphanum4":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	oracle, err := New("http", server.URL, nil)
+	assert.NoError(t, err)
+	usd := xdr.MustNewCreditAsset("USD", issuer)
+
+	price, err := oracle.Price(context.Background(), xdr.MustNewNativeAsset(), usd)
+	assert.NoError(t, err)
+	assert.Equal(t, "1/8", price.Value.String())
+	assert.Equal(t, time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC), price.UpdatedAt.UTC())
+
+	_, err = oracle.Price(context.Background(), xdr.MustNewCreditAsset("EUR", issuer), usd)
+	assert.Equal(t, ErrNoPrice, err)
+
+	_, err = oracle.Price(context.Background(), xdr.MustNewCreditAsset("LONGASSET", issuer), usd)
+	assert.EqualError(t, err, "oracle responded with status 500")
+}
+
+func TestTradePrice(t *testing.T) {
+	trade := history.Trade{BaseAmount: 200, CounterAmount: 50}
+	assert.Equal(t, "1/4", tradePrice(trade).String())
+
+	trade.PriceN = null.IntFrom(1)
+	trade.PriceD = null.IntFrom(3)
+	assert.Equal(t, "1/3", tradePrice(trade).String())
+
+	assert.Nil(t, tradePrice(history.Trade{}))
+}
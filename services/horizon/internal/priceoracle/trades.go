@@ -0,0 +1,99 @@
+package priceoracle
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// defaultTradesMaxAge is the maximum age of the trades used by the trades
+// oracle when its options don't set one.
+const defaultTradesMaxAge = 24 * time.Hour
+
+var _ Oracle = (*TradesOracle)(nil)
+
+// TradesOracle is the built-in oracle pricing asset pairs at the price of
+// their last trade ingested by horizon. Pairs without a trade in the last
+// MaxAge have no price.
+type TradesOracle struct {
+	HistoryQ *history.Q
+	MaxAge   time.Duration
+}
+
+// newTradesOracle constructs a TradesOracle, options is the maximum age of
+// the trades as a duration (ex. "1h"), empty for defaultTradesMaxAge.
+func newTradesOracle(historyQ *history.Q, options string) (Oracle, error) {
+	if historyQ == nil {
+		return nil, errors.New("the trades oracle requires the horizon database")
+	}
+
+	maxAge := defaultTradesMaxAge
+	if options != "" {
+		var err error
+		if maxAge, err = time.ParseDuration(options); err != nil {
+			return nil, errors.Wrap(err, "invalid maximum trade age")
+		}
+		if maxAge <= 0 {
+			return nil, errors.New("maximum trade age must be positive")
+		}
+	}
+	return &TradesOracle{HistoryQ: historyQ, MaxAge: maxAge}, nil
+}
+
+// Price implements Oracle.
+func (o *TradesOracle) Price(ctx context.Context, base, counter xdr.Asset) (Price, error) {
+	q := &history.Q{Session: o.HistoryQ.WithContext(ctx)}
+
+	baseID, err := q.GetAssetID(base)
+	if q.NoRows(err) {
+		return Price{}, ErrNoPrice
+	} else if err != nil {
+		return Price{}, errors.Wrap(err, "could not load base asset")
+	}
+	counterID, err := q.GetAssetID(counter)
+	if q.NoRows(err) {
+		return Price{}, ErrNoPrice
+	} else if err != nil {
+		return Price{}, errors.Wrap(err, "could not load counter asset")
+	}
+
+	page, err := db2.NewPageQuery("", false, db2.OrderDescending, 1)
+	if err != nil {
+		return Price{}, err
+	}
+	trades, err := q.GetTrades(ctx, history.TradesFilter{
+		BaseAssetID:    baseID,
+		CounterAssetID: counterID,
+	}, page)
+	if err != nil {
+		return Price{}, errors.Wrap(err, "could not load last trade")
+	}
+	if len(trades) == 0 || time.Since(trades[0].LedgerCloseTime) > o.MaxAge {
+		return Price{}, ErrNoPrice
+	}
+
+	value := tradePrice(trades[0])
+	if value == nil {
+		return Price{}, ErrNoPrice
+	}
+	return Price{Value: value, UpdatedAt: trades[0].LedgerCloseTime}, nil
+}
+
+// tradePrice returns the price of the base asset of trade in its counter
+// asset, nil when it can't be determined. Old trades have no price, it's
+// derived from the amounts.
+func tradePrice(trade history.Trade) *big.Rat {
+	switch {
+	case trade.HasPrice() && trade.PriceD.Int64 != 0:
+		return big.NewRat(trade.PriceN.Int64, trade.PriceD.Int64)
+	case trade.BaseAmount != 0:
+		return big.NewRat(int64(trade.CounterAmount), int64(trade.BaseAmount))
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+// Package readonly implements the read-only mode of horizon, a runtime toggle
+// used during database maintenance windows. When it's enabled horizon keeps
+// serving reads but rejects transaction submission and admin changes, and
+// ingestion and history reaping stop writing to the database.
+package readonly
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// Mode is the state of the read-only mode. It is safe for concurrent use and
+// a nil Mode is never enabled.
+type Mode struct {
+	mutex   sync.RWMutex
+	enabled bool
+}
+
+// Status is the admin representation of Mode.
+type Status struct {
+	ReadOnly bool `json:"read_only"`
+}
+
+// NewMode creates a Mode with the given initial state.
+func NewMode(enabled bool) *Mode {
+	return &Mode{enabled: enabled}
+}
+
+// Enabled returns true if read-only mode is on.
+func (m *Mode) Enabled() bool {
+	if m == nil {
+		return false
+	}
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.enabled
+}
+
+// Set turns read-only mode on or off.
+func (m *Mode) Set(enabled bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.enabled != enabled {
+		log.WithField("read_only", enabled).Info("Changing read-only mode")
+	}
+	m.enabled = enabled
+}
+
+// Middleware rejects the requests which change data with the read only
+// problem while read-only mode is on. GET and HEAD requests are always
+// served.
+func (m *Mode) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && m.Enabled() {
+			problem.Render(r.Context(), w, &hProblem.ReadOnly)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ServeHTTP renders the current state as JSON. PUT requests change the state
+// to the value of the `enabled` parameter first.
+func (m *Mode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		enabled, err := strconv.ParseBool(r.FormValue("enabled"))
+		if err != nil {
+			http.Error(w, "enabled must be true or false", http.StatusBadRequest)
+			return
+		}
+		m.Set(enabled)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(Status{ReadOnly: m.Enabled()}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,70 @@
+package readonly
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMode(t *testing.T) {
+	var mode *Mode
+	assert.False(t, mode.Enabled())
+
+	mode = NewMode(true)
+	assert.True(t, mode.Enabled())
+	mode.Set(false)
+	assert.False(t, mode.Enabled())
+
+	get := func() Status {
+		w := httptest.NewRecorder()
+		mode.ServeHTTP(w, httptest.NewRequest("GET", "/read_only", nil))
+		assert.Equal(t, 200, w.Code)
+		var status Status
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+		return status
+	}
+	put := func(value string) int {
+		w := httptest.NewRecorder()
+		form := url.Values{"enabled": []string{value}}
+		r := httptest.NewRequest("PUT", "/read_only", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		mode.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, Status{ReadOnly: false}, get())
+	assert.Equal(t, 200, put("true"))
+	assert.True(t, mode.Enabled())
+	assert.Equal(t, Status{ReadOnly: true}, get())
+
+	assert.Equal(t, 400, put("maybe"))
+	assert.True(t, mode.Enabled())
+
+	w := httptest.NewRecorder()
+	mode.ServeHTTP(w, httptest.NewRequest("DELETE", "/read_only", nil))
+	assert.Equal(t, 405, w.Code)
+}
+
+func TestModeMiddleware(t *testing.T) {
+	mode := NewMode(false)
+	handler := mode.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(method string) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/asset_watches", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodPut))
+
+	mode.Set(true)
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodGet))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPut))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodDelete))
+}
@@ -5,8 +5,6 @@
 package reap
 
 import (
-	"time"
-
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/support/db"
 )
@@ -15,18 +13,13 @@ import (
 type System struct {
 	HistoryQ       *history.Q
 	RetentionCount uint
-
-	nextRun time.Time
 }
 
-// New initializes the reaper, causing it to begin polling the stellar-core
-// database for now ledgers and ingesting data into the horizon database.
+// New initializes the reaper. History is reaped by DeleteUnretainedHistory,
+// which horizon runs hourly as a background job.
 func New(retention uint, dbSession *db.Session) *System {
-	r := &System{
+	return &System{
 		HistoryQ:       &history.Q{dbSession},
 		RetentionCount: retention,
 	}
-
-	r.nextRun = time.Now().Add(1 * time.Hour)
-	return r
 }
@@ -3,14 +3,20 @@ package reap
 import (
 	"time"
 
-	"github.com/stellar/go/services/horizon/internal/errors"
 	"github.com/stellar/go/services/horizon/internal/ledger"
 	"github.com/stellar/go/services/horizon/internal/toid"
 	"github.com/stellar/go/support/log"
 )
 
 // DeleteUnretainedHistory removes all data associated with unretained ledgers.
+// Trades of asset pairs with a trade retention policy are not removed with
+// the rest of the history but according to the policy of the pair.
 func (r *System) DeleteUnretainedHistory() error {
+	err := r.deleteExpiredRetainedTrades()
+	if err != nil {
+		return err
+	}
+
 	// RetentionCount of 0 indicates "keep all history"
 	if r.RetentionCount == 0 {
 		return nil
@@ -25,7 +31,7 @@ func (r *System) DeleteUnretainedHistory() error {
 		return nil
 	}
 
-	err := r.clearBefore(targetElder)
+	err = r.clearBefore(targetElder)
 	if err != nil {
 		return err
 	}
@@ -37,32 +43,6 @@ func (r *System) DeleteUnretainedHistory() error {
 	return nil
 }
 
-// Tick triggers the reaper system to update itself, deleted unretained history
-// if it is the appropriate time.
-func (r *System) Tick() {
-	if time.Now().Before(r.nextRun) {
-		return
-	}
-
-	r.runOnce()
-	r.nextRun = time.Now().Add(1 * time.Hour)
-}
-
-func (r *System) runOnce() {
-	defer func() {
-		if rec := recover(); rec != nil {
-			err := errors.FromPanic(rec)
-			log.Errorf("reaper panicked: %s", err)
-			errors.ReportToSentry(err, nil)
-		}
-	}()
-
-	err := r.DeleteUnretainedHistory()
-	if err != nil {
-		log.Errorf("reaper failed: %s", err)
-	}
-}
-
 func (r *System) clearBefore(seq int32) error {
 	log.WithField("new_elder", seq).Info("reaper: clearing")
 
@@ -71,10 +51,22 @@ func (r *System) clearBefore(seq int32) error {
 		return err
 	}
 
-	err = r.HistoryQ.DeleteRangeAll(start, end)
+	err = r.HistoryQ.DeleteUnretainedRangeAll(start, end)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *System) deleteExpiredRetainedTrades() error {
+	deleted, err := r.HistoryQ.DeleteExpiredRetainedTrades(time.Now())
 	if err != nil {
 		return err
 	}
 
+	if deleted > 0 {
+		log.WithField("deleted", deleted).Info("reaper: cleared trades outside of their retention policy")
+	}
 	return nil
 }
@@ -113,4 +113,39 @@ var (
 		Detail: "Data cannot be presented because it's still being ingested. Please " +
 			"wait for several minutes before trying your request again.",
 	}
+
+	// StaleInstance is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	StaleInstance = problem.P{
+		Type:   "stale_instance",
+		Title:  "Instance Is Behind Requested Ledger",
+		Status: http.StatusServiceUnavailable,
+		Detail: "This horizon instance has not yet ingested the ledger " +
+			"required by the X-Horizon-Min-Ledger header of the request, " +
+			"usually because a previous page of results was served by a more " +
+			"up to date instance. Please try your request again.",
+	}
+
+	// ReadOnly is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	ReadOnly = problem.P{
+		Type:   "read_only",
+		Title:  "Read Only Mode",
+		Status: http.StatusServiceUnavailable,
+		Detail: "This horizon instance is in read-only mode, usually because " +
+			"its database is under maintenance. Data can be read but " +
+			"transactions cannot be submitted. Please try your request again " +
+			"later.",
+	}
+
+	// TransactionSkipped is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	TransactionSkipped = problem.P{
+		Type:   "transaction_skipped",
+		Title:  "Transaction Skipped",
+		Status: http.StatusBadRequest,
+		Detail: "The transaction was not submitted because a previous " +
+			"transaction of the batch with the same source account failed. " +
+			"Please submit it again once the failure is resolved.",
+	}
 )
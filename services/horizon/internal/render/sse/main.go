@@ -17,10 +17,35 @@ type Event struct {
 	Retry int
 }
 
+// EventWriter writes the events of the streams whose context carries it, see
+// NewEventWriterContext, instead of the response. It lets other transports,
+// such as WebSocket subscriptions, serve the streams.
+type EventWriter interface {
+	WriteEvent(e Event)
+}
+
+type eventWriterContextKey struct{}
+
+// NewEventWriterContext returns a context whose streams write their events to
+// w.
+func NewEventWriterContext(ctx context.Context, w EventWriter) context.Context {
+	return context.WithValue(ctx, eventWriterContextKey{}, w)
+}
+
+func eventWriterFromContext(ctx context.Context) EventWriter {
+	w, _ := ctx.Value(eventWriterContextKey{}).(EventWriter)
+	return w
+}
+
 // WritePreamble prepares this http connection for streaming using Server Sent
 // Events. It sends the initial http response with the appropriate headers to
 // do so.
 func WritePreamble(ctx context.Context, w http.ResponseWriter) bool {
+	if ew := eventWriterFromContext(ctx); ew != nil {
+		ew.WriteEvent(helloEvent)
+		return true
+	}
+
 	_, flushable := w.(http.Flusher)
 	if !flushable {
 		//TODO: render a problem struct instead of simple string
@@ -42,6 +67,11 @@ func WritePreamble(ctx context.Context, w http.ResponseWriter) bool {
 // WriteEvent does the actual work of formatting an SSE compliant message
 // sending it over the provided ResponseWriter and flushing.
 func WriteEvent(ctx context.Context, w http.ResponseWriter, e Event) {
+	if ew := eventWriterFromContext(ctx); ew != nil {
+		ew.WriteEvent(e)
+		return
+	}
+
 	if e.Error != nil {
 		fmt.Fprint(w, "event: error\n")
 		fmt.Fprintf(w, "data: %s\n\n", e.Error.Error())
@@ -53,3 +53,24 @@ func TestWritePreamble(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 	assert.Contains(t, w.Body.String(), "retry: 1000\nevent: open\ndata: \"hello\"\n\n")
 }
+
+type recordingEventWriter []Event
+
+func (w *recordingEventWriter) WriteEvent(e Event) {
+	*w = append(*w, e)
+}
+
+// Tests that the events of streams whose context carries an event writer are
+// written to it instead of the response.
+func TestEventWriterContext(t *testing.T) {
+	ctx, _ := test.ContextWithLogBuffer()
+	events := &recordingEventWriter{}
+	ctx = NewEventWriterContext(ctx, events)
+	w := httptest.NewRecorder()
+
+	assert.True(t, WritePreamble(ctx, w))
+	WriteEvent(ctx, w, Event{ID: "1", Data: "test"})
+	assert.Equal(t, []Event{helloEvent, {ID: "1", Data: "test"}}, []Event(*events))
+	assert.Empty(t, w.Body.String())
+	assert.Empty(t, w.Header().Get("Content-Type"))
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stellar/go/support/log"
 	"github.com/stellar/go/support/render/problem"
 )
@@ -18,6 +19,61 @@ var (
 	ErrRateLimited = errors.New("Rate limit exceeded")
 )
 
+// SlowConsumerPolicy is the behaviour of a buffered stream when its buffer is
+// full because the client doesn't read the events as fast as they are sent.
+type SlowConsumerPolicy string
+
+const (
+	// SlowConsumerDisconnect stops the stream, which is closed by Done once
+	// the buffered events are written. The last event has the id of the last buffered event and asks
+	// the client to reconnect, which resumes the stream after it.
+	SlowConsumerDisconnect SlowConsumerPolicy = "disconnect"
+	// SlowConsumerDrop drops the events which don't fit in the buffer and
+	// sends a gap event describing them once the buffer has room again.
+	SlowConsumerDrop SlowConsumerPolicy = "drop"
+)
+
+// ParseSlowConsumerPolicy returns the policy called name.
+func ParseSlowConsumerPolicy(name string) (SlowConsumerPolicy, error) {
+	switch policy := SlowConsumerPolicy(name); policy {
+	case SlowConsumerDisconnect, SlowConsumerDrop:
+		return policy, nil
+	default:
+		return "", errors.Errorf("unknown slow consumer policy: %s", name)
+	}
+}
+
+// BufferOptions configure the buffer of a stream.
+type BufferOptions struct {
+	// Size is the maximum number of events waiting to be written to the
+	// client.
+	Size   int
+	Policy SlowConsumerPolicy
+	// SlowConsumers, if set, is incremented, labelled by policy, for every
+	// stream whose buffer overflowed.
+	SlowConsumers *prometheus.CounterVec
+	// DroppedEvents, if set, counts the events dropped by the
+	// SlowConsumerDrop policy.
+	DroppedEvents prometheus.Counter
+}
+
+// GapEvent is the data of the "gap" event sent by the SlowConsumerDrop
+// policy. The dropped events are the events after the After cursor up to
+// the Until cursor, included.
+type GapEvent struct {
+	After   string `json:"after"`
+	Until   string `json:"until"`
+	Dropped int    `json:"dropped"`
+}
+
+// SlowConsumerEvent is the data of the "close" event ending the streams
+// disconnected by the SlowConsumerDisconnect policy. Cursor is the id of the
+// last event sent.
+type SlowConsumerEvent struct {
+	Reason string `json:"reason"`
+	Cursor string `json:"cursor"`
+}
+
 type Stream struct {
 	ctx      context.Context
 	initSync sync.Once  // Variable to ensure that Init only writes the preamble once.
@@ -26,6 +82,19 @@ type Stream struct {
 	done     bool
 	sent     int
 	limit    int
+
+	// buffered streams queue the events written by the writer goroutine
+	buffer     BufferOptions
+	queue      chan Event
+	writerDone chan struct{}
+	// lastID is the id of the last queued event, or the cursor the stream
+	// started after until an event is queued
+	lastID string
+	// gap holds the events dropped since the last queued event
+	gap        *GapEvent
+	overflowed bool
+	// closeEvent ends the stream of a disconnected slow consumer
+	closeEvent *Event
 }
 
 // NewStream creates a new stream against the provided response writer.
@@ -36,6 +105,21 @@ func NewStream(ctx context.Context, w http.ResponseWriter) *Stream {
 	}
 }
 
+// NewBufferedStream creates a stream writing the events to the provided
+// response writer from a goroutine, the events waiting to be written are
+// buffered up to buffer.Size. The stream must be ended with Done or Err,
+// which wait for the buffered events to be written.
+func NewBufferedStream(ctx context.Context, w http.ResponseWriter, buffer BufferOptions) *Stream {
+	if buffer.Size <= 0 {
+		return NewStream(ctx, w)
+	}
+	return &Stream{
+		ctx:    ctx,
+		w:      w,
+		buffer: buffer,
+	}
+}
+
 // Init function is only executed once. It writes the preamble event which includes the HTTP response code and a
 // hello message. This should be called before any method that writes to the client to ensure that the preamble
 // has been sent first.
@@ -44,16 +128,109 @@ func (s *Stream) Init() {
 		ok := WritePreamble(s.ctx, s.w)
 		if !ok {
 			s.done = true
+			return
+		}
+		if s.buffer.Size > 0 {
+			s.queue = make(chan Event, s.buffer.Size)
+			s.writerDone = make(chan struct{})
+			go s.write()
 		}
 	})
 }
 
+// write writes the queued events until the queue is closed.
+func (s *Stream) write() {
+	defer close(s.writerDone)
+	for e := range s.queue {
+		WriteEvent(s.ctx, s.w, e)
+	}
+}
+
+// stopWriter closes the queue, once the given events are queued, and waits
+// for the writer to write them. It's a no-op for unbuffered streams.
+func (s *Stream) stopWriter(events ...Event) {
+	if s.queue == nil {
+		return
+	}
+	for _, e := range events {
+		s.queue <- e
+	}
+	close(s.queue)
+	<-s.writerDone
+	s.queue = nil
+}
+
 func (s *Stream) Send(e Event) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.Init()
-	WriteEvent(s.ctx, s.w, e)
-	s.sent++
+	if s.buffer.Size <= 0 {
+		WriteEvent(s.ctx, s.w, e)
+		s.sent++
+		return
+	}
+	if s.done {
+		return
+	}
+
+	if s.gap != nil && s.tryQueue(s.gapEvent()) {
+		s.lastID = s.gap.Until
+		s.gap = nil
+	}
+	if s.gap == nil && s.tryQueue(e) {
+		s.lastID = e.ID
+		s.sent++
+		return
+	}
+	s.overflow(e)
+}
+
+// tryQueue queues e unless the queue is full.
+func (s *Stream) tryQueue(e Event) bool {
+	select {
+	case s.queue <- e:
+		return true
+	default:
+		return false
+	}
+}
+
+// overflow applies the slow consumer policy to e which doesn't fit in the
+// queue.
+func (s *Stream) overflow(e Event) {
+	if !s.overflowed {
+		s.overflowed = true
+		if s.buffer.SlowConsumers != nil {
+			s.buffer.SlowConsumers.WithLabelValues(string(s.buffer.Policy)).Inc()
+		}
+		log.Ctx(s.ctx).WithField("policy", s.buffer.Policy).Warn("Slow stream consumer")
+	}
+
+	if s.buffer.Policy == SlowConsumerDrop {
+		if s.gap == nil {
+			s.gap = &GapEvent{After: s.lastID}
+		}
+		s.gap.Until = e.ID
+		s.gap.Dropped++
+		if s.buffer.DroppedEvents != nil {
+			s.buffer.DroppedEvents.Inc()
+		}
+		return
+	}
+
+	s.closeEvent = &Event{
+		ID:    s.lastID,
+		Event: "close",
+		Data:  SlowConsumerEvent{Reason: "slow_consumer", Cursor: s.lastID},
+		Retry: goodbyeEvent.Retry,
+	}
+	s.done = true
+}
+
+// gapEvent returns the event describing the dropped events. Its id is the
+// cursor of the last dropped event so reconnecting clients resume after it.
+func (s *Stream) gapEvent() Event {
+	return Event{ID: s.gap.Until, Event: "gap", Data: *s.gap}
 }
 
 func (s *Stream) SetLimit(limit int) {
@@ -66,17 +243,44 @@ func (s *Stream) Done() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.Init()
-	WriteEvent(s.ctx, s.w, goodbyeEvent)
+	if s.buffer.Size <= 0 {
+		WriteEvent(s.ctx, s.w, goodbyeEvent)
+		s.done = true
+		return
+	}
+	if s.closeEvent != nil {
+		s.stopWriter(*s.closeEvent)
+		s.closeEvent = nil
+		return
+	}
+	if s.done {
+		return
+	}
+
+	events := []Event{goodbyeEvent}
+	if s.gap != nil {
+		events = []Event{s.gapEvent(), goodbyeEvent}
+		s.gap = nil
+	}
+	s.stopWriter(events...)
 	s.done = true
 }
 
 func (s *Stream) Err(err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.buffer.Size > 0 && s.done {
+		if s.closeEvent != nil {
+			s.stopWriter(*s.closeEvent)
+			s.closeEvent = nil
+		}
+		return
+	}
 
 	// If we haven't sent an event, we should simply return the normal HTTP
 	// error because it means that we haven't sent the preamble.
 	if s.sent == 0 {
+		s.stopWriter()
 		problem.Render(s.ctx, s.w, err)
 		return
 	}
@@ -89,6 +293,19 @@ func (s *Stream) Err(err error) {
 	}
 
 	s.Init()
-	WriteEvent(s.ctx, s.w, Event{Error: err})
+	if s.queue == nil {
+		WriteEvent(s.ctx, s.w, Event{Error: err})
+	} else {
+		s.stopWriter(Event{Error: err})
+	}
 	s.done = true
 }
+
+// closed returns true once a buffered stream is ended, ex. when its client
+// was disconnected by the SlowConsumerDisconnect policy. The stream must still
+// be ended with Done.
+func (s *Stream) closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buffer.Size > 0 && s.done
+}
@@ -16,12 +16,20 @@ type LedgerSourceFactory interface {
 type StreamHandler struct {
 	RateLimiter         *throttled.HTTPRateLimiter
 	LedgerSourceFactory LedgerSourceFactory
+	// Buffer configures the buffer of the streams, they are unbuffered when
+	// its size is 0.
+	Buffer BufferOptions
 }
 
 // GenerateEventsFunc generates a slice of sse.Event which are sent via
 // streaming.
 type GenerateEventsFunc func() ([]Event, error)
 
+// ErrEndOfStream is returned by GenerateEventsFunc when a bounded stream has
+// no more events to send. Events returned along with it are still sent before
+// the stream is closed.
+var ErrEndOfStream = errors.New("end of stream")
+
 // ServeStream handles a SSE requests, sending data every time there is a new
 // ledger.
 func (handler StreamHandler) ServeStream(
@@ -31,8 +39,9 @@ func (handler StreamHandler) ServeStream(
 	generateEvents GenerateEventsFunc,
 ) {
 	ctx := r.Context()
-	stream := NewStream(ctx, w)
+	stream := NewBufferedStream(ctx, w, handler.Buffer)
 	stream.SetLimit(limit)
+	stream.lastID = requestCursor(r)
 
 	ledgerSource := handler.LedgerSourceFactory.Get()
 	defer ledgerSource.Close()
@@ -55,7 +64,7 @@ func (handler StreamHandler) ServeStream(
 		}
 
 		events, err := generateEvents()
-		if err != nil {
+		if err != nil && err != ErrEndOfStream {
 			stream.Err(err)
 			return
 		}
@@ -67,7 +76,7 @@ func (handler StreamHandler) ServeStream(
 			limit--
 		}
 
-		if limit <= 0 {
+		if limit <= 0 || err == ErrEndOfStream || stream.closed() {
 			stream.Done()
 			return
 		}
@@ -86,3 +95,16 @@ func (handler StreamHandler) ServeStream(
 		}
 	}
 }
+
+// requestCursor returns the cursor the stream of r starts after, it's empty
+// when the stream starts at the current ledger.
+func requestCursor(r *http.Request) string {
+	cursor := r.Header.Get("Last-Event-ID")
+	if cursor == "" {
+		cursor = r.URL.Query().Get("cursor")
+	}
+	if cursor == "now" {
+		return ""
+	}
+	return cursor
+}
@@ -42,3 +42,51 @@ func TestSendByeByeOnContextDone(t *testing.T) {
 		t.Fatalf("expected '%v' but got '%v'", expected, got)
 	}
 }
+
+func TestEndOfStream(t *testing.T) {
+	ledgerSource := ledger.NewTestingSource(1)
+	handler := StreamHandler{LedgerSourceFactory: &testingFactory{ledgerSource}}
+
+	r, err := http.NewRequest("GET", "http://localhost", nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	w := httptest.NewRecorder()
+
+	handler.ServeStream(w, r, 10, func() ([]Event, error) {
+		return []Event{{ID: "1", Data: "last"}}, ErrEndOfStream
+	})
+
+	expected := "retry: 1000\nevent: open\ndata: \"hello\"\n\n" +
+		"id: 1\ndata: \"last\"\n\n" +
+		"retry: 10\nevent: close\ndata: \"byebye\"\n\n"
+
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("expected '%v' but got '%v'", expected, got)
+	}
+}
+
+func TestRequestCursor(t *testing.T) {
+	for _, testCase := range []struct {
+		url         string
+		lastEventID string
+		expected    string
+	}{
+		{"http://localhost", "", ""},
+		{"http://localhost?cursor=now", "", ""},
+		{"http://localhost?cursor=12", "", "12"},
+		{"http://localhost?cursor=12", "15", "15"},
+	} {
+		r, err := http.NewRequest("GET", testCase.url, nil)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if testCase.lastEventID != "" {
+			r.Header.Set("Last-Event-ID", testCase.lastEventID)
+		}
+		if got := requestCursor(r); got != testCase.expected {
+			t.Fatalf("expected '%v' but got '%v' for %v", testCase.expected, got, testCase.url)
+		}
+	}
+}
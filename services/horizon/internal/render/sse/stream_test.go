@@ -7,8 +7,11 @@ import (
 	"errors"
 	"net/http/httptest"
 	"strconv"
+	"sync"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
 	"github.com/stellar/go/support/render/problem"
 	"github.com/stellar/go/support/test"
@@ -147,3 +150,139 @@ func (suite *StreamTestSuite) TestStream_SentCount() {
 func TestStreamTestSuite(t *testing.T) {
 	suite.Run(t, new(StreamTestSuite))
 }
+
+// slowWriter blocks the writes once block is called until release is called.
+type slowWriter struct {
+	*httptest.ResponseRecorder
+	mu      sync.Mutex
+	writing chan struct{}
+	gate    chan struct{}
+}
+
+func (w *slowWriter) block() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writing = make(chan struct{}, 1)
+	w.gate = make(chan struct{})
+}
+
+func (w *slowWriter) release() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	close(w.gate)
+	w.gate = nil
+}
+
+func (w *slowWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	writing, gate := w.writing, w.gate
+	w.mu.Unlock()
+	if gate != nil {
+		select {
+		case writing <- struct{}{}:
+		default:
+		}
+		<-gate
+	}
+	return w.ResponseRecorder.Write(b)
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	var metric dto.Metric
+	assert.NoError(t, counter.Write(&metric))
+	return metric.GetCounter().GetValue()
+}
+
+// sendToSlowConsumer sends 6 events to a stream whose buffer holds 2 events
+// while its client is stuck writing the first one.
+func sendToSlowConsumer(t *testing.T, policy SlowConsumerPolicy) (*Stream, *slowWriter, BufferOptions) {
+	ctx, _ := test.ContextWithLogBuffer()
+	w := &slowWriter{ResponseRecorder: httptest.NewRecorder()}
+	buffer := BufferOptions{
+		Size:   2,
+		Policy: policy,
+		SlowConsumers: prometheus.NewCounterVec(
+			prometheus.CounterOpts{Name: "slow_consumers_total"},
+			[]string{"policy"},
+		),
+		DroppedEvents: prometheus.NewCounter(prometheus.CounterOpts{Name: "dropped_events_total"}),
+	}
+	stream := NewBufferedStream(ctx, w, buffer)
+	stream.Init()
+
+	w.block()
+	stream.Send(Event{ID: "1", Data: "1"})
+	<-w.writing
+	for i := 2; i <= 6; i++ {
+		stream.Send(Event{ID: strconv.Itoa(i), Data: strconv.Itoa(i)})
+	}
+	return stream, w, buffer
+}
+
+func TestBufferedStreamSend(t *testing.T) {
+	ctx, _ := test.ContextWithLogBuffer()
+	w := httptest.NewRecorder()
+	stream := NewBufferedStream(ctx, w, BufferOptions{Size: 10, Policy: SlowConsumerDisconnect})
+	for i := 1; i <= 3; i++ {
+		stream.Send(Event{ID: strconv.Itoa(i), Data: i})
+	}
+	stream.Done()
+
+	assert.Equal(t, 3, stream.SentCount())
+	assert.True(t, stream.IsDone())
+	assert.Equal(
+		t,
+		"retry: 1000\nevent: open\ndata: \"hello\"\n\n"+
+			"id: 1\ndata: 1\n\nid: 2\ndata: 2\n\nid: 3\ndata: 3\n\n"+
+			"retry: 10\nevent: close\ndata: \"byebye\"\n\n",
+		w.Body.String(),
+	)
+}
+
+func TestBufferedStreamDisconnectsSlowConsumer(t *testing.T) {
+	stream, w, buffer := sendToSlowConsumer(t, SlowConsumerDisconnect)
+	assert.True(t, stream.closed())
+	// the close event is sent once the buffered events are written
+	w.release()
+	stream.Send(Event{ID: "7", Data: "7"})
+	stream.Done()
+
+	assert.Equal(t, 3, stream.SentCount())
+	assert.Equal(
+		t,
+		"retry: 1000\nevent: open\ndata: \"hello\"\n\n"+
+			"id: 1\ndata: \"1\"\n\nid: 2\ndata: \"2\"\n\nid: 3\ndata: \"3\"\n\n"+
+			"retry: 10\nid: 3\nevent: close\ndata: {\"reason\":\"slow_consumer\",\"cursor\":\"3\"}\n\n",
+		w.Body.String(),
+	)
+	assert.Equal(t, float64(1), counterValue(t, buffer.SlowConsumers.WithLabelValues("disconnect")))
+	assert.Equal(t, float64(0), counterValue(t, buffer.DroppedEvents))
+}
+
+func TestBufferedStreamDropsSlowConsumerEvents(t *testing.T) {
+	stream, w, buffer := sendToSlowConsumer(t, SlowConsumerDrop)
+	assert.False(t, stream.closed())
+	w.release()
+	stream.Done()
+
+	assert.Equal(t, 3, stream.SentCount())
+	assert.Equal(
+		t,
+		"retry: 1000\nevent: open\ndata: \"hello\"\n\n"+
+			"id: 1\ndata: \"1\"\n\nid: 2\ndata: \"2\"\n\nid: 3\ndata: \"3\"\n\n"+
+			"id: 6\nevent: gap\ndata: {\"after\":\"3\",\"until\":\"6\",\"dropped\":3}\n\n"+
+			"retry: 10\nevent: close\ndata: \"byebye\"\n\n",
+		w.Body.String(),
+	)
+	assert.Equal(t, float64(1), counterValue(t, buffer.SlowConsumers.WithLabelValues("drop")))
+	assert.Equal(t, float64(3), counterValue(t, buffer.DroppedEvents))
+}
+
+func TestParseSlowConsumerPolicy(t *testing.T) {
+	policy, err := ParseSlowConsumerPolicy("drop")
+	assert.NoError(t, err)
+	assert.Equal(t, SlowConsumerDrop, policy)
+
+	_, err = ParseSlowConsumerPolicy("block")
+	assert.EqualError(t, err, "unknown slow consumer policy: block")
+}
@@ -143,9 +143,10 @@ func TestPopulateAccountEntry(t *testing.T) {
 	tt.Equal(wantAccountThresholds, hAccount.Thresholds)
 
 	wantFlags := AccountFlags{
-		AuthRequired:  account.IsAuthRequired(),
-		AuthRevocable: account.IsAuthRevocable(),
-		AuthImmutable: account.IsAuthImmutable(),
+		AuthRequired:        account.IsAuthRequired(),
+		AuthRevocable:       account.IsAuthRevocable(),
+		AuthImmutable:       account.IsAuthImmutable(),
+		AuthClawbackEnabled: account.IsAuthClawbackEnabled(),
 	}
 
 	tt.Equal(wantFlags, hAccount.Flags)
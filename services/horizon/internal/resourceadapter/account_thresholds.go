@@ -0,0 +1,34 @@
+package resourceadapter
+
+import (
+	"context"
+	"fmt"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/render/hal"
+)
+
+// PopulateAccountThresholdsChange fills out the details of a thresholds
+// change using a row from the history_account_thresholds table.
+func PopulateAccountThresholdsChange(
+	ctx context.Context,
+	dest *protocol.AccountThresholdsChange,
+	row history.AccountThresholdsChange,
+) {
+	dest.ID = row.PagingToken()
+	dest.PT = row.PagingToken()
+	dest.AccountID = row.Account
+	dest.LedgerCloseTime = row.LedgerCloseTime
+	dest.MasterKeyWeight = byte(row.MasterWeight)
+	dest.Thresholds = protocol.AccountThresholds{
+		LowThreshold:  byte(row.LowThreshold),
+		MedThreshold:  byte(row.MedThreshold),
+		HighThreshold: byte(row.HighThreshold),
+	}
+
+	lb := hal.LinkBuilder{horizonContext.BaseURL(ctx)}
+	dest.Links.Account = lb.Link("/accounts", row.Account)
+	dest.Links.Operation = lb.Link("/operations", fmt.Sprintf("%d", row.HistoryOperationID))
+}
@@ -0,0 +1,26 @@
+package resourceadapter
+
+import (
+	"context"
+
+	"github.com/stellar/go/amount"
+	protocol "github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/render/hal"
+)
+
+// PopulateAssetHolder fills out the details of an account holding an asset
+// from its trust line.
+func PopulateAssetHolder(ctx context.Context, dest *protocol.AssetHolder, row history.TrustLine) {
+	dest.PT = row.PagingToken()
+	dest.AccountID = row.AccountID
+	dest.Balance = amount.StringFromInt64(row.Balance)
+	dest.Limit = amount.StringFromInt64(row.Limit)
+	dest.LastModifiedLedger = row.LastModifiedLedger
+	dest.IsAuthorized = row.IsAuthorized()
+	dest.IsAuthorizedToMaintainLiabilities = dest.IsAuthorized || row.IsAuthorizedToMaintainLiabilities()
+
+	lb := hal.LinkBuilder{horizonContext.BaseURL(ctx)}
+	dest.Links.Account = lb.Linkf("/accounts/%s", row.AccountID)
+}
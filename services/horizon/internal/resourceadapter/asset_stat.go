@@ -12,13 +12,12 @@ import (
 	"github.com/stellar/go/xdr"
 )
 
-// PopulateAssetStat populates an AssetStat using asset stats and account entries
-// generated from the ingestion system.
+// PopulateAssetStat populates an AssetStat using asset stats generated from
+// the ingestion system, including the flags and the home domain of the issuer.
 func PopulateAssetStat(
 	ctx context.Context,
 	res *protocol.AssetStat,
 	row history.ExpAssetStat,
-	issuer history.AccountEntry,
 ) (err error) {
 	res.Asset.Type = xdr.AssetTypeToString[row.AssetType]
 	res.Asset.Code = row.AssetCode
@@ -28,18 +27,19 @@ func PopulateAssetStat(
 		return errors.Wrap(err, "Invalid amount in PopulateAssetStat")
 	}
 	res.NumAccounts = row.NumAccounts
-	flags := int8(issuer.Flags)
+	flags := xdr.AccountFlags(row.Flags)
 	res.Flags = protocol.AccountFlags{
-		(flags & int8(xdr.AccountFlagsAuthRequiredFlag)) != 0,
-		(flags & int8(xdr.AccountFlagsAuthRevocableFlag)) != 0,
-		(flags & int8(xdr.AccountFlagsAuthImmutableFlag)) != 0,
+		AuthRequired:        flags.IsAuthRequired(),
+		AuthRevocable:       flags.IsAuthRevocable(),
+		AuthImmutable:       flags.IsAuthImmutable(),
+		AuthClawbackEnabled: flags&history.AuthClawbackEnabledFlag != 0,
 	}
 	res.PT = row.PagingToken()
 
-	trimmed := strings.TrimSpace(issuer.HomeDomain)
+	trimmed := strings.TrimSpace(row.HomeDomain)
 	var toml string
 	if trimmed != "" {
-		toml = "https://" + issuer.HomeDomain + "/.well-known/stellar.toml"
+		toml = "https://" + row.HomeDomain + "/.well-known/stellar.toml"
 	}
 	res.Links.Toml = hal.NewLink(toml)
 	return
@@ -18,15 +18,12 @@ func TestPopulateExpAssetStat(t *testing.T) {
 		AssetIssuer: "GBZ35ZJRIKJGYH5PBKLKOZ5L6EXCNTO7BKIL7DAVVDFQ2ODJEEHHJXIM",
 		Amount:      "100000000000000000000", // 10T
 		NumAccounts: 429,
-	}
-	issuer := history.AccountEntry{
-		AccountID:  "GBZ35ZJRIKJGYH5PBKLKOZ5L6EXCNTO7BKIL7DAVVDFQ2ODJEEHHJXIM",
-		Flags:      0,
-		HomeDomain: "xim.com",
+		Flags:       0,
+		HomeDomain:  "xim.com",
 	}
 
 	var res protocol.AssetStat
-	err := PopulateAssetStat(context.Background(), &res, row, issuer)
+	err := PopulateAssetStat(context.Background(), &res, row)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "credit_alphanum4", res.Type)
@@ -38,11 +35,12 @@ func TestPopulateExpAssetStat(t *testing.T) {
 	assert.Equal(t, "https://xim.com/.well-known/stellar.toml", res.Links.Toml.Href)
 	assert.Equal(t, row.PagingToken(), res.PagingToken())
 
-	issuer.HomeDomain = ""
-	issuer.Flags = uint32(xdr.AccountFlagsAuthRequiredFlag) |
-		uint32(xdr.AccountFlagsAuthImmutableFlag)
+	row.HomeDomain = ""
+	row.Flags = uint32(xdr.AccountFlagsAuthRequiredFlag) |
+		uint32(xdr.AccountFlagsAuthImmutableFlag) |
+		uint32(history.AuthClawbackEnabledFlag)
 
-	err = PopulateAssetStat(context.Background(), &res, row, issuer)
+	err = PopulateAssetStat(context.Background(), &res, row)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "credit_alphanum4", res.Type)
@@ -53,8 +51,9 @@ func TestPopulateExpAssetStat(t *testing.T) {
 	assert.Equal(
 		t,
 		horizon.AccountFlags{
-			AuthRequired:  true,
-			AuthImmutable: true,
+			AuthRequired:        true,
+			AuthImmutable:       true,
+			AuthClawbackEnabled: true,
 		},
 		res.Flags,
 	)
@@ -3,6 +3,7 @@ package resourceadapter
 import (
 	"context"
 
+	"github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/protocols/horizon/base"
 	"github.com/stellar/go/protocols/horizon/effects"
 	horizonContext "github.com/stellar/go/services/horizon/internal/context"
@@ -44,16 +45,27 @@ func NewEffect(
 	ctx context.Context,
 	row history.Effect,
 	ledger history.Ledger,
+	transactionRow *history.Transaction,
 ) (result hal.Pageable, err error) {
 
 	basev := effects.Base{}
 	PopulateBaseEffect(ctx, &basev, row, ledger)
+	if transactionRow != nil {
+		basev.Transaction = new(horizon.Transaction)
+		if err = PopulateTransaction(ctx, transactionRow.TransactionHash, basev.Transaction, *transactionRow); err != nil {
+			return
+		}
+	}
 
 	switch row.Type {
 	case history.EffectAccountCreated:
 		e := effects.AccountCreated{Base: basev}
 		err = row.UnmarshalDetails(&e)
 		result = e
+	case history.EffectAccountRemoved:
+		e := effects.AccountRemoved{Base: basev}
+		err = row.UnmarshalDetails(&e)
+		result = e
 	case history.EffectAccountCredited:
 		e := effects.AccountCredited{Base: basev}
 		err = row.UnmarshalDetails(&e)
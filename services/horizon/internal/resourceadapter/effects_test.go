@@ -30,7 +30,7 @@ func TestNewEffect_EffectTrustlineAuthorizedToMaintainLiabilities(t *testing.T)
 		Type:               history.EffectTrustlineAuthorizedToMaintainLiabilities,
 		DetailsString:      null.StringFrom(details),
 	}
-	resource, err := NewEffect(ctx, hEffect, history.Ledger{})
+	resource, err := NewEffect(ctx, hEffect, history.Ledger{}, nil)
 	tt.NoError(err)
 
 	var resourcePage hal.Page
@@ -8,6 +8,7 @@ import (
 	protocol "github.com/stellar/go/protocols/horizon"
 	horizonContext "github.com/stellar/go/services/horizon/internal/context"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
 	"github.com/stellar/go/support/render/hal"
 	"github.com/stellar/go/xdr"
 )
@@ -48,3 +49,16 @@ func PopulateLedger(ctx context.Context, dest *protocol.Ledger, row history.Ledg
 	dest.Links.Payments = lb.PagedLink(self, "payments")
 	dest.Links.Effects = lb.PagedLink(self, "effects")
 }
+
+// PopulateLatestLedger fills in the latest ledger of the view selected by
+// source.
+func PopulateLatestLedger(ctx context.Context, dest *protocol.LatestLedger, state ledger.State, source ledger.LatestSource) {
+	dest.Source = string(source)
+	dest.Sequence = state.Latest(source)
+	dest.CoreSequence = state.CoreLatest
+	dest.HorizonSequence = state.HistoryLatest
+
+	lb := hal.LinkBuilder{horizonContext.BaseURL(ctx)}
+	dest.Links.Self = lb.Link(fmt.Sprintf("/ledgers/latest?source=%s", source))
+	dest.Links.Ledger = lb.Link(fmt.Sprintf("/ledgers/%d", dest.Sequence))
+}
@@ -0,0 +1,55 @@
+package resourceadapter
+
+import (
+	"context"
+	"fmt"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+)
+
+// PopulateLedgerExport fills out the details of a ledger export using the
+// history rows of the ledger.
+func PopulateLedgerExport(ctx context.Context, dest *protocol.LedgerExport, row history.LedgerExport) error {
+	PopulateLedger(ctx, &dest.Ledger, row.Ledger)
+
+	dest.Transactions = make([]protocol.Transaction, len(row.Transactions))
+	for i, transaction := range row.Transactions {
+		err := PopulateTransaction(ctx, transaction.TransactionHash, &dest.Transactions[i], transaction)
+		if err != nil {
+			return errors.Wrapf(err, "could not populate transaction %s", transaction.TransactionHash)
+		}
+	}
+
+	dest.Operations = make([]hal.Pageable, len(row.Operations))
+	for i, operation := range row.Operations {
+		resource, err := NewOperation(ctx, operation, operation.TransactionHash, nil, row.Ledger)
+		if err != nil {
+			return errors.Wrapf(err, "could not populate operation %d", operation.ID)
+		}
+		dest.Operations[i] = resource
+	}
+
+	dest.Trades = make([]protocol.Trade, len(row.Trades))
+	for i, trade := range row.Trades {
+		PopulateTrade(ctx, &dest.Trades[i], trade)
+	}
+
+	dest.Effects = make([]hal.Pageable, len(row.Effects))
+	for i, effect := range row.Effects {
+		resource, err := NewEffect(ctx, effect, row.Ledger, nil)
+		if err != nil {
+			return errors.Wrapf(err, "could not populate effect %s", effect.PagingToken())
+		}
+		dest.Effects[i] = resource
+	}
+
+	ledger := fmt.Sprintf("/ledgers/%d", row.Ledger.Sequence)
+	lb := hal.LinkBuilder{horizonContext.BaseURL(ctx)}
+	dest.Links.Self = lb.Link(ledger, "export")
+	dest.Links.Ledger = lb.Link(ledger)
+	return nil
+}
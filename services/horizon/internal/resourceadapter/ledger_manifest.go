@@ -0,0 +1,25 @@
+package resourceadapter
+
+import (
+	"context"
+	"fmt"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/render/hal"
+)
+
+// PopulateLedgerManifest fills out the details of a ledger manifest using a
+// row from the history_ledger_manifests table.
+func PopulateLedgerManifest(ctx context.Context, dest *protocol.LedgerManifest, row history.LedgerManifest) {
+	dest.Sequence = int32(row.LedgerSequence)
+	dest.TradesHash = row.TradesHash
+	dest.PaymentsHash = row.PaymentsHash
+
+	ledger := fmt.Sprintf("/ledgers/%d", row.LedgerSequence)
+	lb := hal.LinkBuilder{horizonContext.BaseURL(ctx)}
+	dest.Links.Self = lb.Link(ledger, "manifest")
+	dest.Links.Ledger = lb.Link(ledger)
+	dest.Links.Payments = lb.PagedLink(ledger, "payments")
+}
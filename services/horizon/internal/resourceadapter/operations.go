@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/stellar/go/amount"
 	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/base"
 	"github.com/stellar/go/protocols/horizon/operations"
 	horizonContext "github.com/stellar/go/services/horizon/internal/context"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/timing"
+	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/hal"
 	"github.com/stellar/go/xdr"
 )
@@ -115,6 +119,67 @@ func NewOperation(
 	return
 }
 
+// NewAccountMergePayment creates a payment resource of an account merge
+// operation. The amount is the merged balance read from the operation result,
+// it is zero when the transaction failed.
+func NewAccountMergePayment(
+	ctx context.Context,
+	operationRow history.Operation,
+	transactionHash string,
+	transactionRow *history.Transaction,
+	ledger history.Ledger,
+) (hal.Pageable, error) {
+	if operationRow.Type != xdr.OperationTypeAccountMerge {
+		return nil, errors.Errorf("operation %d is not an account merge", operationRow.ID)
+	}
+
+	e := operations.AccountMergePayment{}
+	err := PopulateBaseOperation(
+		ctx, &e.Base, operationRow, transactionHash, transactionRow, ledger,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = operationRow.UnmarshalDetails(&e); err != nil {
+		return nil, err
+	}
+
+	balance, err := mergedBalance(ctx, operationRow)
+	if err != nil {
+		return nil, err
+	}
+	e.Asset = base.Asset{Type: "native"}
+	e.From = e.Account
+	e.To = e.Into
+	e.Amount = amount.String(balance)
+	return e, nil
+}
+
+// mergedBalance returns the balance transferred by a successful account
+// merge operation, 0 if the operation failed.
+func mergedBalance(ctx context.Context, operationRow history.Operation) (xdr.Int64, error) {
+	var result xdr.TransactionResult
+	if err := timing.UnmarshalXDR(ctx, operationRow.TxResult, &result); err != nil {
+		return 0, errors.Wrap(err, "invalid transaction result")
+	}
+
+	results, ok := result.OperationResults()
+	index := int(operationRow.ApplicationOrder) - 1
+	if !ok || index < 0 || index >= len(results) {
+		return 0, nil
+	}
+	tr, ok := results[index].GetTr()
+	if !ok {
+		return 0, nil
+	}
+	mergeResult, ok := tr.GetAccountMergeResult()
+	if !ok {
+		return 0, nil
+	}
+	balance, _ := mergeResult.GetSourceAccountBalance()
+	return balance, nil
+}
+
 // Populate fills out this resource using `row` as the source.
 func PopulateBaseOperation(
 	ctx context.Context,
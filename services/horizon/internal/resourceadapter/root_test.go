@@ -35,6 +35,7 @@ func TestPopulateRoot(t *testing.T) {
 	assert.Equal(t, int32(1), res.CoreSequence)
 	assert.Equal(t, int32(2), res.HistoryElderSequence)
 	assert.Equal(t, int32(3), res.HorizonSequence)
+	assert.Equal(t, "/ledgers/latest{?source}", res.Links.LatestLedger.Href)
 	assert.Equal(t, "hVersion", res.HorizonVersion)
 	assert.Equal(t, "cVersion", res.StellarCoreVersion)
 	assert.Equal(t, "passphrase", res.NetworkPassphrase)
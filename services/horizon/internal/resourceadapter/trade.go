@@ -41,6 +41,7 @@ func PopulateTrade(
 	dest.CounterAmount = amount.String(row.CounterAmount)
 	dest.LedgerCloseTime = row.LedgerCloseTime
 	dest.BaseIsSeller = row.BaseIsSeller
+	dest.BaseIsMaker = row.BaseIsMaker
 
 	if row.HasPrice() {
 		dest.Price = &protocol.Price{
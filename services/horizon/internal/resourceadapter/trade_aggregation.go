@@ -28,6 +28,8 @@ func PopulateTradeAggregation(
 		return err
 	}
 	dest.Average = price.StringFromFloat64(row.Average)
+	dest.VWAP = price.StringFromFloat64(row.VWAP)
+	dest.AveragePrice = price.StringFromFloat64(row.AveragePrice)
 	dest.High = row.High.String()
 	dest.HighR = row.High
 	dest.Low = row.Low.String()
@@ -9,6 +9,7 @@ import (
 	"github.com/guregu/null"
 
 	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/timing"
 	"github.com/stellar/go/xdr"
 
 	protocol "github.com/stellar/go/protocols/horizon"
@@ -35,6 +36,8 @@ func PopulateTransaction(
 	dest.FeeCharged = row.FeeCharged
 
 	dest.OperationCount = row.OperationCount
+	dest.TradeCount = row.TradeCount
+	dest.EffectCount = row.EffectCount
 	dest.EnvelopeXdr = row.TxEnvelope
 	dest.ResultXdr = row.TxResult
 	dest.ResultMetaXdr = row.TxMeta
@@ -42,7 +45,7 @@ func PopulateTransaction(
 	dest.MemoType = row.MemoType
 	dest.Memo = row.Memo.String
 	if row.MemoType == "text" {
-		if memoBytes, err := memoBytes(row.TxEnvelope); err != nil {
+		if memoBytes, err := memoBytes(ctx, row.TxEnvelope); err != nil {
 			return err
 		} else {
 			dest.MemoBytes = memoBytes
@@ -87,9 +90,9 @@ func PopulateTransaction(
 	return nil
 }
 
-func memoBytes(envelopeXDR string) (string, error) {
+func memoBytes(ctx context.Context, envelopeXDR string) (string, error) {
 	var parsedEnvelope xdr.TransactionEnvelope
-	if err := xdr.SafeUnmarshalBase64(envelopeXDR, &parsedEnvelope); err != nil {
+	if err := timing.UnmarshalXDR(ctx, envelopeXDR, &parsedEnvelope); err != nil {
 		return "", err
 	}
 
@@ -150,6 +150,23 @@ func TestPopulateTransaction_Fee(t *testing.T) {
 	assert.Equal(t, int64(10000), dest.MaxFee)
 }
 
+func TestPopulateTransaction_Counts(t *testing.T) {
+	ctx, _ := test.ContextWithLogBuffer()
+	dest := Transaction{}
+	row := history.Transaction{
+		TransactionWithoutLedger: history.TransactionWithoutLedger{
+			OperationCount: 3,
+			TradeCount:     2,
+			EffectCount:    5,
+		},
+	}
+
+	assert.NoError(t, PopulateTransaction(ctx, row.TransactionHash, &dest, row))
+	assert.Equal(t, int32(3), dest.OperationCount)
+	assert.Equal(t, int32(2), dest.TradeCount)
+	assert.Equal(t, int32(5), dest.EffectCount)
+}
+
 func TestFeeBumpTransaction(t *testing.T) {
 	ctx, _ := test.ContextWithLogBuffer()
 	dest := Transaction{}
@@ -0,0 +1,74 @@
+// Package schemastats contains the schema statistics subsystem for horizon.
+// It periodically collects the sizes of the history tables and their indexes,
+// bloat estimates and the ledger range of each table, and exports them as
+// Prometheus metrics and through an admin end-point so that capacity planning
+// doesn't require direct access to the database.
+package schemastats
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/db"
+)
+
+// System represents the schema statistics subsystem of horizon.
+type System struct {
+	HistoryQ *history.Q
+
+	updateMutex sync.Mutex
+
+	mutex     sync.RWMutex
+	stats     []history.TableStats
+	updatedAt time.Time
+
+	tableSizeGauge     *prometheus.GaugeVec
+	indexSizeGauge     *prometheus.GaugeVec
+	bloatEstimateGauge *prometheus.GaugeVec
+	rowsGauge          *prometheus.GaugeVec
+	oldestLedgerGauge  *prometheus.GaugeVec
+	newestLedgerGauge  *prometheus.GaugeVec
+}
+
+// Snapshot is the admin representation of the last collected statistics.
+type Snapshot struct {
+	UpdatedAt *time.Time           `json:"updated_at"`
+	Tables    []history.TableStats `json:"tables"`
+}
+
+// New initializes the schema statistics system. Statistics are collected by
+// Update, which horizon runs periodically as a background job.
+func New(dbSession *db.Session) *System {
+	newGaugeVec := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Namespace: "horizon", Subsystem: "schema", Name: name, Help: help},
+			labels,
+		)
+	}
+
+	return &System{
+		HistoryQ: &history.Q{dbSession},
+
+		tableSizeGauge: newGaugeVec(
+			"table_size_bytes", "size of the history table in bytes", "table",
+		),
+		indexSizeGauge: newGaugeVec(
+			"index_size_bytes", "size of the index of a history table in bytes", "table", "index",
+		),
+		bloatEstimateGauge: newGaugeVec(
+			"table_bloat_estimate_bytes", "estimated number of bytes used by dead rows of the history table", "table",
+		),
+		rowsGauge: newGaugeVec(
+			"table_estimated_rows", "number of rows of the history table estimated by the query planner", "table",
+		),
+		oldestLedgerGauge: newGaugeVec(
+			"table_oldest_ledger", "oldest ledger stored in the history table", "table",
+		),
+		newestLedgerGauge: newGaugeVec(
+			"table_newest_ledger", "newest ledger stored in the history table", "table",
+		),
+	}
+}
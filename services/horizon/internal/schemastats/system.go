@@ -0,0 +1,96 @@
+package schemastats
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterMetrics registers the schema metrics in the given registry.
+func (s *System) RegisterMetrics(registry *prometheus.Registry) {
+	registry.MustRegister(s.tableSizeGauge)
+	registry.MustRegister(s.indexSizeGauge)
+	registry.MustRegister(s.bloatEstimateGauge)
+	registry.MustRegister(s.rowsGauge)
+	registry.MustRegister(s.oldestLedgerGauge)
+	registry.MustRegister(s.newestLedgerGauge)
+}
+
+// Update collects the statistics of the history tables and updates the
+// metrics.
+func (s *System) Update() error {
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
+
+	stats, err := s.HistoryQ.SchemaStats()
+	if err != nil {
+		return err
+	}
+
+	// reset the gauges so that metrics of dropped tables and indexes are
+	// not exported anymore
+	s.tableSizeGauge.Reset()
+	s.indexSizeGauge.Reset()
+	s.bloatEstimateGauge.Reset()
+	s.rowsGauge.Reset()
+	s.oldestLedgerGauge.Reset()
+	s.newestLedgerGauge.Reset()
+
+	for _, table := range stats {
+		s.tableSizeGauge.WithLabelValues(table.Table).Set(float64(table.TableSize))
+		s.bloatEstimateGauge.WithLabelValues(table.Table).Set(float64(table.BloatEstimate))
+		s.rowsGauge.WithLabelValues(table.Table).Set(float64(table.EstimatedRows))
+		s.oldestLedgerGauge.WithLabelValues(table.Table).Set(float64(table.OldestLedger))
+		s.newestLedgerGauge.WithLabelValues(table.Table).Set(float64(table.NewestLedger))
+		for _, index := range table.Indexes {
+			s.indexSizeGauge.WithLabelValues(table.Table, index.Index).Set(float64(index.Size))
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stats = stats
+	s.updatedAt = time.Now()
+	return nil
+}
+
+// Snapshot returns the last collected statistics.
+func (s *System) Snapshot() Snapshot {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	snapshot := Snapshot{Tables: s.stats}
+	if !s.updatedAt.IsZero() {
+		updatedAt := s.updatedAt
+		snapshot.UpdatedAt = &updatedAt
+	}
+	return snapshot
+}
+
+// ServeHTTP renders the last collected statistics as JSON. The statistics are
+// collected first when the `refresh` parameter is true.
+func (s *System) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if value := r.URL.Query().Get("refresh"); value != "" {
+		refresh, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "refresh must be true or false", http.StatusBadRequest)
+			return
+		}
+		if refresh {
+			if err := s.Update(); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.Snapshot()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
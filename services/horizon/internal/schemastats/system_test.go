@@ -0,0 +1,65 @@
+package schemastats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestUpdate(t *testing.T) {
+	tt := test.Start(t).Scenario("base")
+	defer tt.Finish()
+
+	sys := New(tt.HorizonSession())
+	registry := prometheus.NewRegistry()
+	sys.RegisterMetrics(registry)
+
+	snapshot := sys.Snapshot()
+	tt.Assert.Nil(snapshot.UpdatedAt)
+	tt.Assert.Empty(snapshot.Tables)
+
+	tt.Require.NoError(sys.Update())
+	snapshot = sys.Snapshot()
+	tt.Assert.NotNil(snapshot.UpdatedAt)
+	tt.Assert.NotEmpty(snapshot.Tables)
+
+	families, err := registry.Gather()
+	tt.Require.NoError(err)
+	names := map[string]int{}
+	for _, family := range families {
+		names[family.GetName()] = len(family.GetMetric())
+	}
+	tt.Assert.Equal(len(snapshot.Tables), names["horizon_schema_table_size_bytes"])
+	tt.Assert.Equal(len(snapshot.Tables), names["horizon_schema_table_newest_ledger"])
+	tt.Assert.True(names["horizon_schema_index_size_bytes"] > 0)
+}
+
+func TestServeHTTP(t *testing.T) {
+	tt := test.Start(t).Scenario("base")
+	defer tt.Finish()
+
+	sys := New(tt.HorizonSession())
+
+	w := httptest.NewRecorder()
+	sys.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/schema_stats", nil))
+	tt.Assert.Equal(http.StatusOK, w.Code)
+	tt.Assert.JSONEq(`{"updated_at": null, "tables": null}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	sys.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/schema_stats?refresh=yes", nil))
+	tt.Assert.Equal(http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	sys.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/schema_stats?refresh=true", nil))
+	tt.Assert.Equal(http.StatusOK, w.Code)
+
+	var snapshot Snapshot
+	tt.Require.NoError(json.Unmarshal(w.Body.Bytes(), &snapshot))
+	tt.Assert.NotNil(snapshot.UpdatedAt)
+	tt.Assert.NotEmpty(snapshot.Tables)
+}
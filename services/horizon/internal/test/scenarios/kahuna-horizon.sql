@@ -43,6 +43,7 @@ DROP INDEX IF EXISTS public.htrd_by_counter_account;
 DROP INDEX IF EXISTS public.htrd_by_base_offer;
 DROP INDEX IF EXISTS public.htrd_by_base_account;
 DROP INDEX IF EXISTS public.htp_by_htid;
+DROP INDEX IF EXISTS public.htx_with_trades;
 DROP INDEX IF EXISTS public.hs_transaction_by_id;
 DROP INDEX IF EXISTS public.hs_ledger_by_id;
 DROP INDEX IF EXISTS public.hop_by_hoid;
@@ -68,6 +69,7 @@ DROP TABLE IF EXISTS public.history_transactions;
 DROP SEQUENCE IF EXISTS public.history_transaction_participants_id_seq;
 DROP TABLE IF EXISTS public.history_transaction_participants;
 DROP TABLE IF EXISTS public.history_trades;
+DROP SEQUENCE IF EXISTS public.history_trades_sequence_seq;
 DROP TABLE IF EXISTS public.history_operations;
 DROP SEQUENCE IF EXISTS public.history_operation_participants_id_seq;
 DROP TABLE IF EXISTS public.history_operation_participants;
@@ -362,6 +364,18 @@ CREATE TABLE history_operations (
 );
 
 
+--
+-- Name: history_trades_sequence_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE history_trades_sequence_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
 --
 -- Name: history_trades; Type: TABLE; Schema: public; Owner: -
 --
@@ -382,12 +396,97 @@ CREATE TABLE history_trades (
     price_d bigint,
     base_offer_id bigint,
     counter_offer_id bigint,
+    base_is_maker boolean,
+    sequence bigint DEFAULT nextval('history_trades_sequence_seq'::regclass) NOT NULL,
     CONSTRAINT history_trades_base_amount_check CHECK ((base_amount > 0)),
     CONSTRAINT history_trades_check CHECK ((base_asset_id < counter_asset_id)),
     CONSTRAINT history_trades_counter_amount_check CHECK ((counter_amount > 0))
 );
 
 
+--
+-- Name: trade_retention_policies; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE trade_retention_policies (
+    base_asset_id bigint NOT NULL,
+    counter_asset_id bigint NOT NULL,
+    retention_seconds bigint NOT NULL,
+    CONSTRAINT trade_retention_policies_check CHECK ((base_asset_id < counter_asset_id)),
+    CONSTRAINT trade_retention_policies_retention_seconds_check CHECK ((retention_seconds >= 0))
+);
+
+
+--
+-- Name: history_offers; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE history_offers (
+    offer_id bigint NOT NULL,
+    ledger_sequence integer NOT NULL,
+    seller_id character varying(56) NOT NULL,
+    selling_asset text NOT NULL,
+    buying_asset text NOT NULL,
+    amount bigint NOT NULL,
+    pricen integer NOT NULL,
+    priced integer NOT NULL,
+    price double precision NOT NULL,
+    flags integer NOT NULL,
+    deleted boolean DEFAULT false NOT NULL
+);
+
+
+--
+-- Name: history_account_events; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE history_account_events (
+    history_operation_id bigint NOT NULL,
+    account character varying(56) NOT NULL,
+    type smallint NOT NULL,
+    related_account character varying(56) NOT NULL,
+    amount bigint NOT NULL
+);
+
+
+--
+-- Name: history_account_thresholds; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE history_account_thresholds (
+    history_operation_id bigint NOT NULL,
+    account character varying(56) NOT NULL,
+    master_weight smallint NOT NULL,
+    low_threshold smallint NOT NULL,
+    med_threshold smallint NOT NULL,
+    high_threshold smallint NOT NULL
+);
+
+
+--
+-- Name: reingest_checkpoints; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE reingest_checkpoints (
+    range_from integer NOT NULL,
+    range_to integer NOT NULL,
+    batch_from integer NOT NULL,
+    last_ledger integer NOT NULL,
+    updated_at timestamp without time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: history_ledger_manifests; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE history_ledger_manifests (
+    ledger_sequence integer NOT NULL,
+    trades_hash character(64) NOT NULL,
+    payments_hash character(64) NOT NULL
+);
+
+
 --
 -- Name: history_transaction_participants; Type: TABLE; Schema: public; Owner: -
 --
@@ -446,7 +545,9 @@ CREATE TABLE history_transactions (
     inner_transaction_hash character varying(64),
     fee_account character varying(64),
     inner_signatures character varying(96)[],
-    new_max_fee bigint
+    new_max_fee bigint,
+    trade_count integer DEFAULT 0 NOT NULL,
+    effect_count integer DEFAULT 0 NOT NULL
 );
 
 
@@ -1032,10 +1133,17 @@ INSERT INTO history_operations VALUES (12884905985, 12884905984, 1, 0, '{"funder
 -- Data for Name: history_trades; Type: TABLE DATA; Schema: public; Owner: -
 --
 
-INSERT INTO history_trades VALUES (103079219201, 0, '2019-06-03 16:35:50', 3, 14, 1, 200000000, 13, 7, 200000000, false, 1, 1, 4, 3);
-INSERT INTO history_trades VALUES (85899350017, 0, '2019-06-03 16:35:46', 2, 15, 4, 1000000000, 16, 7, 1000000000, true, 1, 1, 2, 4611686104326737921);
-INSERT INTO history_trades VALUES (81604382721, 0, '2019-06-03 16:35:45', 1, 16, 2, 1000000000, 15, 7, 2000000000, false, 2, 1, 4611686100031770625, 1);
-INSERT INTO history_trades VALUES (81604382721, 1, '2019-06-03 16:35:45', 2, 15, 4, 2000000000, 16, 7, 2000000000, true, 1, 1, 2, 4611686100031770625);
+INSERT INTO history_trades VALUES (103079219201, 0, '2019-06-03 16:35:50', 3, 14, 1, 200000000, 13, 7, 200000000, false, 1, 1, 4, 3, false, 4);
+INSERT INTO history_trades VALUES (85899350017, 0, '2019-06-03 16:35:46', 2, 15, 4, 1000000000, 16, 7, 1000000000, true, 1, 1, 2, 4611686104326737921, true, 3);
+INSERT INTO history_trades VALUES (81604382721, 0, '2019-06-03 16:35:45', 1, 16, 2, 1000000000, 15, 7, 2000000000, false, 2, 1, 4611686100031770625, 1, false, 1);
+INSERT INTO history_trades VALUES (81604382721, 1, '2019-06-03 16:35:45', 2, 15, 4, 2000000000, 16, 7, 2000000000, true, 1, 1, 2, 4611686100031770625, true, 2);
+
+
+--
+-- Name: history_trades_sequence_seq; Type: SEQUENCE SET; Schema: public; Owner: -
+--
+
+SELECT pg_catalog.setval('history_trades_sequence_seq', 4, true);
 
 
 --
@@ -1412,6 +1520,13 @@ CREATE UNIQUE INDEX hs_ledger_by_id ON history_ledgers USING btree (id);
 CREATE UNIQUE INDEX hs_transaction_by_id ON history_transactions USING btree (id);
 
 
+--
+-- Name: htx_with_trades; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX htx_with_trades ON history_transactions USING btree (id) WHERE (trade_count > 0);
+
+
 --
 -- Name: htp_by_htid; Type: INDEX; Schema: public; Owner: -
 --
@@ -1475,6 +1590,13 @@ CREATE INDEX htrd_pair_time_lookup ON history_trades USING btree (base_asset_id,
 CREATE UNIQUE INDEX htrd_pid ON history_trades USING btree (history_operation_id, "order");
 
 
+--
+-- Name: htrd_by_sequence; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX htrd_by_sequence ON history_trades USING btree (sequence);
+
+
 --
 -- Name: htrd_time_lookup; Type: INDEX; Schema: public; Owner: -
 --
@@ -1620,6 +1742,70 @@ ALTER TABLE ONLY history_trades
     ADD CONSTRAINT history_trades_counter_asset_id_fkey FOREIGN KEY (counter_asset_id) REFERENCES history_assets(id);
 
 
+--
+-- Name: trade_retention_policies trade_retention_policies_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY trade_retention_policies
+    ADD CONSTRAINT trade_retention_policies_pkey PRIMARY KEY (base_asset_id, counter_asset_id);
+
+
+--
+-- Name: trade_retention_policies trade_retention_policies_base_asset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY trade_retention_policies
+    ADD CONSTRAINT trade_retention_policies_base_asset_id_fkey FOREIGN KEY (base_asset_id) REFERENCES history_assets(id);
+
+
+--
+-- Name: trade_retention_policies trade_retention_policies_counter_asset_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY trade_retention_policies
+    ADD CONSTRAINT trade_retention_policies_counter_asset_id_fkey FOREIGN KEY (counter_asset_id) REFERENCES history_assets(id);
+
+
+--
+-- Name: history_offers history_offers_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY history_offers
+    ADD CONSTRAINT history_offers_pkey PRIMARY KEY (offer_id, ledger_sequence);
+
+
+--
+-- Name: history_account_events history_account_events_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY history_account_events
+    ADD CONSTRAINT history_account_events_pkey PRIMARY KEY (history_operation_id);
+
+
+--
+-- Name: history_account_thresholds history_account_thresholds_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY history_account_thresholds
+    ADD CONSTRAINT history_account_thresholds_pkey PRIMARY KEY (history_operation_id);
+
+
+--
+-- Name: reingest_checkpoints reingest_checkpoints_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY reingest_checkpoints
+    ADD CONSTRAINT reingest_checkpoints_pkey PRIMARY KEY (range_from, range_to, batch_from);
+
+
+--
+-- Name: history_ledger_manifests history_ledger_manifests_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY history_ledger_manifests
+    ADD CONSTRAINT history_ledger_manifests_pkey PRIMARY KEY (ledger_sequence);
+
+
 --
 -- PostgreSQL database dump complete
 --
@@ -43,6 +43,7 @@ DROP INDEX IF EXISTS public.htrd_by_counter_account;
 DROP INDEX IF EXISTS public.htrd_by_base_offer;
 DROP INDEX IF EXISTS public.htrd_by_base_account;
 DROP INDEX IF EXISTS public.htp_by_htid;
+DROP INDEX IF EXISTS public.htx_with_trades;
 DROP INDEX IF EXISTS public.hs_transaction_by_id;
 DROP INDEX IF EXISTS public.hs_ledger_by_id;
 DROP INDEX IF EXISTS public.hop_by_hoid;
@@ -68,6 +69,7 @@ DROP TABLE IF EXISTS public.history_transactions;
 DROP SEQUENCE IF EXISTS public.history_transaction_participants_id_seq;
 DROP TABLE IF EXISTS public.history_transaction_participants;
 DROP TABLE IF EXISTS public.history_trades;
+DROP SEQUENCE IF EXISTS public.history_trades_sequence_seq;
 DROP TABLE IF EXISTS public.history_operations;
 DROP SEQUENCE IF EXISTS public.history_operation_participants_id_seq;
 DROP TABLE IF EXISTS public.history_operation_participants;
@@ -361,6 +363,18 @@ CREATE TABLE history_operations (
 );
 
 
+--
+-- Name: history_trades_sequence_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE history_trades_sequence_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
 --
 -- Name: history_trades; Type: TABLE; Schema: public; Owner: -
 --
@@ -381,6 +395,8 @@ CREATE TABLE history_trades (
     price_d bigint,
     base_offer_id bigint,
     counter_offer_id bigint,
+    base_is_maker boolean,
+    sequence bigint DEFAULT nextval('history_trades_sequence_seq'::regclass) NOT NULL,
     CONSTRAINT history_trades_base_amount_check CHECK ((base_amount > 0)),
     CONSTRAINT history_trades_check CHECK ((base_asset_id < counter_asset_id)),
     CONSTRAINT history_trades_counter_amount_check CHECK ((counter_amount > 0))
@@ -441,7 +457,9 @@ CREATE TABLE history_transactions (
     memo character varying,
     time_bounds int8range,
     successful boolean,
-    fee_charged integer
+    fee_charged integer,
+    trade_count integer DEFAULT 0 NOT NULL,
+    effect_count integer DEFAULT 0 NOT NULL
 );
 
 
@@ -640,10 +658,17 @@ INSERT INTO history_operations VALUES (8589950977, 8589950976, 1, 0, '{"funder":
 -- Data for Name: history_trades; Type: TABLE DATA; Schema: public; Owner: -
 --
 
-INSERT INTO history_trades VALUES (38654709761, 0, '2019-06-03 16:35:18', 2, 2, 1, 499999999, 1, 2, 450000000, false, 1000000000, 1111111111, 4, 2);
-INSERT INTO history_trades VALUES (38654709761, 1, '2019-06-03 16:35:18', 3, 2, 1, 1000000000, 1, 2, 800000000, false, 4, 5, 4, 3);
-INSERT INTO history_trades VALUES (34359742465, 0, '2019-06-03 16:35:17', 1, 2, 1, 1000000000, 1, 2, 1000000000, false, 1, 1, 4611686052787130369, 1);
-INSERT INTO history_trades VALUES (34359742465, 1, '2019-06-03 16:35:17', 2, 2, 1, 500000000, 1, 2, 450000000, false, 1000000000, 1111111111, 4611686052787130369, 2);
+INSERT INTO history_trades VALUES (38654709761, 0, '2019-06-03 16:35:18', 2, 2, 1, 499999999, 1, 2, 450000000, false, 1000000000, 1111111111, 4, 2, false, 3);
+INSERT INTO history_trades VALUES (38654709761, 1, '2019-06-03 16:35:18', 3, 2, 1, 1000000000, 1, 2, 800000000, false, 4, 5, 4, 3, false, 4);
+INSERT INTO history_trades VALUES (34359742465, 0, '2019-06-03 16:35:17', 1, 2, 1, 1000000000, 1, 2, 1000000000, false, 1, 1, 4611686052787130369, 1, false, 1);
+INSERT INTO history_trades VALUES (34359742465, 1, '2019-06-03 16:35:17', 2, 2, 1, 500000000, 1, 2, 450000000, false, 1000000000, 1111111111, 4611686052787130369, 2, false, 2);
+
+
+--
+-- Name: history_trades_sequence_seq; Type: SEQUENCE SET; Schema: public; Owner: -
+--
+
+SELECT pg_catalog.setval('history_trades_sequence_seq', 4, true);
 
 
 --
@@ -833,6 +858,13 @@ CREATE UNIQUE INDEX hs_ledger_by_id ON history_ledgers USING btree (id);
 CREATE UNIQUE INDEX hs_transaction_by_id ON history_transactions USING btree (id);
 
 
+--
+-- Name: htx_with_trades; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX htx_with_trades ON history_transactions USING btree (id) WHERE (trade_count > 0);
+
+
 --
 -- Name: htp_by_htid; Type: INDEX; Schema: public; Owner: -
 --
@@ -896,6 +928,13 @@ CREATE INDEX htrd_pair_time_lookup ON history_trades USING btree (base_asset_id,
 CREATE UNIQUE INDEX htrd_pid ON history_trades USING btree (history_operation_id, "order");
 
 
+--
+-- Name: htrd_by_sequence; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX htrd_by_sequence ON history_trades USING btree (sequence);
+
+
 --
 -- Name: htrd_time_lookup; Type: INDEX; Schema: public; Owner: -
 --
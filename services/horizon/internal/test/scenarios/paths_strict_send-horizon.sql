@@ -48,6 +48,7 @@ DROP INDEX IF EXISTS public.htrd_by_counter_account;
 DROP INDEX IF EXISTS public.htrd_by_base_offer;
 DROP INDEX IF EXISTS public.htrd_by_base_account;
 DROP INDEX IF EXISTS public.htp_by_htid;
+DROP INDEX IF EXISTS public.htx_with_trades;
 DROP INDEX IF EXISTS public.hs_transaction_by_id;
 DROP INDEX IF EXISTS public.hs_ledger_by_id;
 DROP INDEX IF EXISTS public.hop_by_hoid;
@@ -78,6 +79,7 @@ DROP TABLE IF EXISTS public.history_transactions;
 DROP SEQUENCE IF EXISTS public.history_transaction_participants_id_seq;
 DROP TABLE IF EXISTS public.history_transaction_participants;
 DROP TABLE IF EXISTS public.history_trades;
+DROP SEQUENCE IF EXISTS public.history_trades_sequence_seq;
 DROP TABLE IF EXISTS public.history_operations;
 DROP SEQUENCE IF EXISTS public.history_operation_participants_id_seq;
 DROP TABLE IF EXISTS public.history_operation_participants;
@@ -384,6 +386,18 @@ CREATE TABLE history_operations (
 );
 
 
+--
+-- Name: history_trades_sequence_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE history_trades_sequence_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
 --
 -- Name: history_trades; Type: TABLE; Schema: public; Owner: -
 --
@@ -404,6 +418,8 @@ CREATE TABLE history_trades (
     price_d bigint,
     base_offer_id bigint,
     counter_offer_id bigint,
+    base_is_maker boolean,
+    sequence bigint DEFAULT nextval('history_trades_sequence_seq'::regclass) NOT NULL,
     CONSTRAINT history_trades_base_amount_check CHECK ((base_amount >= 0)),
     CONSTRAINT history_trades_check CHECK ((base_asset_id < counter_asset_id)),
     CONSTRAINT history_trades_counter_amount_check CHECK ((counter_amount >= 0))
@@ -464,7 +480,9 @@ CREATE TABLE history_transactions (
     memo character varying,
     time_bounds int8range,
     successful boolean,
-    fee_charged integer
+    fee_charged integer,
+    trade_count integer DEFAULT 0 NOT NULL,
+    effect_count integer DEFAULT 0 NOT NULL
 );
 
 
@@ -720,9 +738,16 @@ INSERT INTO history_operations VALUES (8589955073, 8589955072, 1, 0, '{"funder":
 -- Data for Name: history_trades; Type: TABLE DATA; Schema: public; Owner: -
 --
 
-INSERT INTO history_trades VALUES (25769807873, 0, '2019-10-07 17:43:06', 3, 1, 1, 130000000, 2, 2, 100000000, true, 10, 13, 3, 4611686044197195777);
-INSERT INTO history_trades VALUES (25769811969, 0, '2019-10-07 17:43:06', 1, 2, 2, 120000000, 1, 3, 132000000, false, 11, 10, 4611686044197199873, 1);
-INSERT INTO history_trades VALUES (25769811969, 1, '2019-10-07 17:43:06', 2, 1, 1, 158400000, 2, 3, 132000000, true, 5, 6, 2, 4611686044197199873);
+INSERT INTO history_trades VALUES (25769807873, 0, '2019-10-07 17:43:06', 3, 1, 1, 130000000, 2, 2, 100000000, true, 10, 13, 3, 4611686044197195777, true, 1);
+INSERT INTO history_trades VALUES (25769811969, 0, '2019-10-07 17:43:06', 1, 2, 2, 120000000, 1, 3, 132000000, false, 11, 10, 4611686044197199873, 1, false, 2);
+INSERT INTO history_trades VALUES (25769811969, 1, '2019-10-07 17:43:06', 2, 1, 1, 158400000, 2, 3, 132000000, true, 5, 6, 2, 4611686044197199873, true, 3);
+
+
+--
+-- Name: history_trades_sequence_seq; Type: SEQUENCE SET; Schema: public; Owner: -
+--
+
+SELECT pg_catalog.setval('history_trades_sequence_seq', 3, true);
 
 
 --
@@ -963,6 +988,13 @@ CREATE UNIQUE INDEX hs_ledger_by_id ON history_ledgers USING btree (id);
 CREATE UNIQUE INDEX hs_transaction_by_id ON history_transactions USING btree (id);
 
 
+--
+-- Name: htx_with_trades; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX htx_with_trades ON history_transactions USING btree (id) WHERE (trade_count > 0);
+
+
 --
 -- Name: htp_by_htid; Type: INDEX; Schema: public; Owner: -
 --
@@ -1026,6 +1058,13 @@ CREATE INDEX htrd_pair_time_lookup ON history_trades USING btree (base_asset_id,
 CREATE UNIQUE INDEX htrd_pid ON history_trades USING btree (history_operation_id, "order");
 
 
+--
+-- Name: htrd_by_sequence; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX htrd_by_sequence ON history_trades USING btree (sequence);
+
+
 --
 -- Name: htrd_time_lookup; Type: INDEX; Schema: public; Owner: -
 --
@@ -37,6 +37,28 @@ func IngestTestTrade(
 	timestamp time.Millis,
 	opCounter int64) error {
 
+	price := xdr.Price{
+		N: xdr.Int32(amountBought),
+		D: xdr.Int32(amountSold),
+	}
+	return IngestTestTradeAtPrice(
+		q, assetSold, assetBought, seller, buyer, amountSold, amountBought, price, timestamp, opCounter)
+}
+
+//IngestTestTradeAtPrice mock ingests a trade whose price differs from the ratio of its amounts, as
+//offers crossed at their price can be filled with rounded amounts
+func IngestTestTradeAtPrice(
+	q *history.Q,
+	assetSold xdr.Asset,
+	assetBought xdr.Asset,
+	seller xdr.AccountId,
+	buyer xdr.AccountId,
+	amountSold int64,
+	amountBought int64,
+	price xdr.Price,
+	timestamp time.Millis,
+	opCounter int64) error {
+
 	trade := xdr.ClaimOfferAtom{}
 	trade.AmountBought = xdr.Int64(amountBought)
 	trade.SellerId = seller
@@ -44,11 +66,6 @@ func IngestTestTrade(
 	trade.AssetBought = assetBought
 	trade.AssetSold = assetSold
 
-	price := xdr.Price{
-		N: xdr.Int32(amountBought),
-		D: xdr.Int32(amountSold),
-	}
-
 	accounts, err := q.CreateAccounts([]string{seller.Address(), buyer.Address()}, 2)
 	if err != nil {
 		return err
@@ -58,7 +75,7 @@ func IngestTestTrade(
 		return err
 	}
 
-	batch := q.NewTradeBatchInsertBuilder(0)
+	batch := q.NewTradeBatchInsertBuilder(0, history.TradeConflictFail)
 	batch.Add(history.InsertTrade{
 		HistoryOperationID: opCounter,
 		Order:              0,
@@ -0,0 +1,77 @@
+// Package timing measures the time spent by requests in each layer of
+// horizon: running database queries, decoding XDR and rendering JSON.
+package timing
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/xdr"
+)
+
+// Phases of a request measured by Timings.
+const (
+	DB     = "db"
+	XDR    = "xdr"
+	Render = "render"
+)
+
+// Phases are all the phases measured by Timings.
+var Phases = []string{DB, XDR, Render}
+
+type contextKey struct{}
+
+// Timings accumulates the time spent by a request in each phase. It's safe
+// for concurrent use and a nil Timings ignores the measures.
+type Timings struct {
+	db     db.QueryTimer
+	xdr    int64
+	render int64
+}
+
+// NewContext returns a copy of ctx carrying new Timings. The queries of the
+// sessions whose context is derived from it are timed as the DB phase.
+func NewContext(ctx context.Context) (context.Context, *Timings) {
+	timings := &Timings{}
+	ctx = db.WithQueryTimer(ctx, &timings.db)
+	return context.WithValue(ctx, contextKey{}, timings), timings
+}
+
+// FromContext returns the Timings of ctx, nil if there are none.
+func FromContext(ctx context.Context) *Timings {
+	timings, _ := ctx.Value(contextKey{}).(*Timings)
+	return timings
+}
+
+// Since adds the time elapsed since start to the XDR or Render phase.
+func (t *Timings) Since(phase string, start time.Time) {
+	if t == nil {
+		return
+	}
+	switch phase {
+	case XDR:
+		atomic.AddInt64(&t.xdr, int64(time.Since(start)))
+	case Render:
+		atomic.AddInt64(&t.render, int64(time.Since(start)))
+	default:
+		panic("unknown timing phase: " + phase)
+	}
+}
+
+// Durations returns the time spent in each phase.
+func (t *Timings) Durations() map[string]time.Duration {
+	return map[string]time.Duration{
+		DB:     t.db.Duration(),
+		XDR:    time.Duration(atomic.LoadInt64(&t.xdr)),
+		Render: time.Duration(atomic.LoadInt64(&t.render)),
+	}
+}
+
+// UnmarshalXDR decodes the base64 encoded XDR into dest, like
+// xdr.SafeUnmarshalBase64, timing it as the XDR phase of the request of ctx.
+func UnmarshalXDR(ctx context.Context, data string, dest interface{}) error {
+	defer FromContext(ctx).Since(XDR, time.Now())
+	return xdr.SafeUnmarshalBase64(data, dest)
+}
@@ -0,0 +1,44 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimings(t *testing.T) {
+	assert.Nil(t, FromContext(context.Background()))
+	// requests without timings are not measured
+	var timings *Timings
+	timings.Since(XDR, time.Now())
+
+	ctx, timings := NewContext(context.Background())
+	assert.Equal(t, timings, FromContext(ctx))
+
+	timings.Since(XDR, time.Now().Add(-time.Second))
+	timings.Since(Render, time.Now().Add(-2*time.Second))
+	timings.Since(Render, time.Now().Add(-time.Second))
+
+	durations := timings.Durations()
+	assert.Len(t, durations, len(Phases))
+	assert.Equal(t, time.Duration(0), durations[DB])
+	assert.True(t, durations[XDR] >= time.Second)
+	assert.True(t, durations[Render] >= 3*time.Second)
+
+	assert.Panics(t, func() { timings.Since(DB, time.Now()) })
+}
+
+func TestUnmarshalXDR(t *testing.T) {
+	ctx, timings := NewContext(context.Background())
+
+	var result xdr.TransactionResult
+	err := UnmarshalXDR(ctx, "AAAAAAAAAGQAAAAAAAAAAQAAAAAAAAABAAAAAAAAAAA=", &result)
+	assert.NoError(t, err)
+	assert.Equal(t, xdr.TransactionResultCodeTxSuccess, result.Result.Code)
+	assert.True(t, timings.Durations()[XDR] > 0)
+
+	assert.Error(t, UnmarshalXDR(context.Background(), "invalid", &result))
+}
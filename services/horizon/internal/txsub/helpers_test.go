@@ -52,3 +52,12 @@ func (m *mockDBQ) TransactionByHash(dest interface{}, hash string) error {
 	args := m.Called(dest, hash)
 	return args.Error(0)
 }
+
+type mockSequenceProvider struct {
+	mock.Mock
+}
+
+func (m *mockSequenceProvider) GetSequenceNumbers(addresses []string) (map[string]uint64, error) {
+	args := m.Called(addresses)
+	return args.Get(0).(map[string]uint64), args.Error(1)
+}
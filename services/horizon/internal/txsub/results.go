@@ -44,8 +44,8 @@ func txResultFromHistory(tx history.Transaction) (history.Transaction, error) {
 // and sequence numbers. Without the repeatable read transaction it is possible that the two database
 // queries execute on different ledgers. In this case, txsub can mistakenly respond with a bad_seq error
 // because the first query occurs when the tx is not yet ingested and the second query occurs when the tx
-// is ingested.
-func checkTxAlreadyExists(db HorizonDB, hash, sourceAddress string) (history.Transaction, uint64, error) {
+// is ingested. Sequence numbers are only read in the transaction when sequences is db.
+func checkTxAlreadyExists(db HorizonDB, sequences SequenceProvider, hash, sourceAddress string) (history.Transaction, uint64, error) {
 	err := db.BeginTx(&sql.TxOptions{
 		Isolation: sql.LevelRepeatableRead,
 		ReadOnly:  true,
@@ -58,7 +58,7 @@ func checkTxAlreadyExists(db HorizonDB, hash, sourceAddress string) (history.Tra
 	tx, err := txResultByHash(db, hash)
 	if err == ErrNoResults {
 		var sequenceNumbers map[string]uint64
-		sequenceNumbers, err = db.GetSequenceNumbers([]string{sourceAddress})
+		sequenceNumbers, err = sequences.GetSequenceNumbers([]string{sourceAddress})
 		if err != nil {
 			return tx, 0, errors.Wrapf(err, "cannot fetch sequence number for %v", sourceAddress)
 		}
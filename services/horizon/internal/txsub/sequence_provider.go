@@ -0,0 +1,98 @@
+package txsub
+
+import (
+	"strconv"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+)
+
+// SequenceProvider returns the current sequence numbers of the given
+// accounts by address. Accounts which don't exist are omitted.
+type SequenceProvider interface {
+	GetSequenceNumbers(addresses []string) (map[string]uint64, error)
+}
+
+// SequenceSource names where the sequence numbers of the source accounts of
+// the submitted transactions are read from.
+type SequenceSource string
+
+const (
+	// SequenceSourceHorizon reads the state tables of the Horizon database,
+	// in the same transaction as the transaction results.
+	SequenceSourceHorizon SequenceSource = "horizon"
+	// SequenceSourceCore reads the accounts table of the stellar-core
+	// database.
+	SequenceSourceCore SequenceSource = "core"
+	// SequenceSourceRemote requests the accounts from a remote Horizon
+	// instance, it doesn't need access to the state tables or to the
+	// stellar-core database.
+	SequenceSourceRemote SequenceSource = "remote"
+)
+
+// ParseSequenceSource returns the sequence source called name, "" is
+// SequenceSourceHorizon.
+func ParseSequenceSource(name string) (SequenceSource, error) {
+	switch source := SequenceSource(name); source {
+	case "":
+		return SequenceSourceHorizon, nil
+	case SequenceSourceHorizon, SequenceSourceCore, SequenceSourceRemote:
+		return source, nil
+	default:
+		return "", errors.Errorf("unknown sequence source: %s", name)
+	}
+}
+
+// CoreSequenceProvider reads the sequence numbers from the accounts table of
+// a stellar-core database.
+type CoreSequenceProvider struct {
+	Session *db.Session
+}
+
+// GetSequenceNumbers implements SequenceProvider.
+func (p *CoreSequenceProvider) GetSequenceNumbers(addresses []string) (map[string]uint64, error) {
+	var accounts []struct {
+		AccountID      string `db:"accountid"`
+		SequenceNumber int64  `db:"seqnum"`
+	}
+	sql := sq.Select("accountid", "seqnum").From("accounts").
+		Where(map[string]interface{}{"accountid": addresses})
+	if err := p.Session.Select(&accounts, sql); err != nil {
+		return nil, errors.Wrap(err, "could not query core accounts")
+	}
+
+	sequenceNumbers := map[string]uint64{}
+	for _, account := range accounts {
+		sequenceNumbers[account.AccountID] = uint64(account.SequenceNumber)
+	}
+	return sequenceNumbers, nil
+}
+
+// RemoteSequenceProvider requests the sequence numbers of the accounts from a
+// remote Horizon instance, one account at a time.
+type RemoteSequenceProvider struct {
+	Client horizonclient.ClientInterface
+}
+
+// GetSequenceNumbers implements SequenceProvider.
+func (p *RemoteSequenceProvider) GetSequenceNumbers(addresses []string) (map[string]uint64, error) {
+	sequenceNumbers := map[string]uint64{}
+	for _, address := range addresses {
+		account, err := p.Client.AccountDetail(horizonclient.AccountRequest{AccountID: address})
+		if horizonclient.IsNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not request account %s", address)
+		}
+
+		sequence, err := strconv.ParseUint(account.Sequence, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid sequence number of account %s", address)
+		}
+		sequenceNumbers[address] = sequence
+	}
+	return sequenceNumbers, nil
+}
@@ -0,0 +1,55 @@
+package txsub
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/clients/horizonclient"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/render/problem"
+)
+
+func TestParseSequenceSource(t *testing.T) {
+	for name, expected := range map[string]SequenceSource{
+		"":        SequenceSourceHorizon,
+		"horizon": SequenceSourceHorizon,
+		"core":    SequenceSourceCore,
+		"remote":  SequenceSourceRemote,
+	} {
+		source, err := ParseSequenceSource(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, source)
+	}
+
+	_, err := ParseSequenceSource("captive")
+	assert.EqualError(t, err, "unknown sequence source: captive")
+}
+
+func TestRemoteSequenceProvider(t *testing.T) {
+	client := &horizonclient.MockClient{}
+	provider := &RemoteSequenceProvider{Client: client}
+
+	client.On("AccountDetail", horizonclient.AccountRequest{AccountID: "GA"}).
+		Return(hProtocol.Account{Sequence: "12884901890"}, nil).Once()
+	client.On("AccountDetail", horizonclient.AccountRequest{AccountID: "GB"}).
+		Return(hProtocol.Account{}, &horizonclient.Error{
+			Problem: problem.P{
+				Type:   "https://stellar.org/horizon-errors/not_found",
+				Status: http.StatusNotFound,
+			},
+		}).Once()
+
+	sequences, err := provider.GetSequenceNumbers([]string{"GA", "GB"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"GA": 12884901890}, sequences)
+
+	client.On("AccountDetail", horizonclient.AccountRequest{AccountID: "GC"}).
+		Return(hProtocol.Account{}, errors.New("connection refused")).Once()
+	_, err = provider.GetSequenceNumbers([]string{"GC"})
+	assert.EqualError(t, err, "could not request account GC: connection refused")
+
+	client.AssertExpectations(t)
+}
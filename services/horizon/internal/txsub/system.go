@@ -30,9 +30,18 @@ type System struct {
 	tickMutex      sync.Mutex
 	tickInProgress bool
 
+	sequenceUpdateMutex      sync.Mutex
+	sequenceUpdateInProgress bool
+
 	accountSeqPollInterval time.Duration
 
-	DB                func(context.Context) HorizonDB
+	DB func(context.Context) HorizonDB
+	// SequenceProvider provides the sequence numbers of the source accounts
+	// of the transactions. They are read from the state tables of DB when
+	// it's nil. A SequenceProvider other than DB can be ahead of ingestion,
+	// a transaction whose sequence number is already consumed is then waited
+	// for as an open submission instead of failing with bad_seq.
+	SequenceProvider  SequenceProvider
 	Pending           OpenSubmissionList
 	Submitter         Submitter
 	SubmissionQueue   *sequence.Manager
@@ -98,7 +107,8 @@ func (sys *System) Submit(
 		"tx":      rawTx,
 	}).Info("Processing transaction")
 
-	tx, sequenceNumber, err := checkTxAlreadyExists(db, hash, sourceAddress)
+	sequences := sys.sequenceProvider(db)
+	tx, sequenceNumber, err := checkTxAlreadyExists(db, sequences, hash, sourceAddress)
 	if err == nil {
 		sys.Log.Ctx(ctx).WithField("hash", hash).Info("Found submission result in a DB")
 		sys.finish(ctx, hash, response, Result{Transaction: tx})
@@ -110,6 +120,13 @@ func (sys *System) Submit(
 		return
 	}
 
+	if sys.SequenceProvider != nil && sequenceNumber >= uint64(envelope.SeqNum()) {
+		// the transaction may have been applied but not ingested yet
+		sys.Log.Ctx(ctx).WithField("hash", hash).Info("Waiting for the ingestion of the submission result")
+		sys.Pending.Add(ctx, hash, response)
+		return
+	}
+
 	// queue the submission and get the channel that will emit when
 	// submission is valid
 	seq := sys.SubmissionQueue.Push(sourceAddress, uint64(envelope.SeqNum()))
@@ -158,7 +175,7 @@ func (sys *System) Submit(
 			return
 		}
 
-		if sys.waitUntilAccountSequence(ctx, db, sourceAddress, uint64(envelope.SeqNum())) {
+		if sys.waitUntilAccountSequence(ctx, sequences, sourceAddress, uint64(envelope.SeqNum())) {
 			sys.finish(ctx, hash, response, Result{Err: ErrCanceled})
 			return
 		}
@@ -168,6 +185,10 @@ func (sys *System) Submit(
 		if err == nil {
 			// If the found use it as the result
 			sys.finish(ctx, hash, response, Result{Transaction: tx})
+		} else if sys.SequenceProvider != nil {
+			// the sequence provider can be ahead of ingestion, the result
+			// is waited for as an open submission
+			sys.Pending.Add(ctx, hash, response)
 		} else {
 			// finally, return the bad_seq error if no result was found on 2nd attempt
 			sys.finish(ctx, hash, response, Result{Err: sr.Err})
@@ -182,12 +203,12 @@ func (sys *System) Submit(
 
 // waitUntilAccountSequence blocks until either the context times out or the sequence number of the
 // given source account is greater than or equal to `seq`
-func (sys *System) waitUntilAccountSequence(ctx context.Context, db HorizonDB, sourceAddress string, seq uint64) bool {
+func (sys *System) waitUntilAccountSequence(ctx context.Context, sequences SequenceProvider, sourceAddress string, seq uint64) bool {
 	timer := time.NewTimer(sys.accountSeqPollInterval)
 	defer timer.Stop()
 
 	for {
-		sequenceNumbers, err := db.GetSequenceNumbers([]string{sourceAddress})
+		sequenceNumbers, err := sequences.GetSequenceNumbers([]string{sourceAddress})
 		if err != nil {
 			sys.Log.Ctx(ctx).
 				WithError(err).
@@ -215,6 +236,15 @@ func (sys *System) waitUntilAccountSequence(ctx context.Context, db HorizonDB, s
 	}
 }
 
+// sequenceProvider returns the provider of the sequence numbers of the source
+// accounts, db when SequenceProvider is not set.
+func (sys *System) sequenceProvider(db HorizonDB) SequenceProvider {
+	if sys.SequenceProvider != nil {
+		return sys.SequenceProvider
+	}
+	return db
+}
+
 // Submit submits the provided base64 encoded transaction envelope to the
 // network using this submission system.
 func (sys *System) submitOnce(ctx context.Context, env string) SubmissionResult {
@@ -294,7 +324,10 @@ func (sys *System) Tick(ctx context.Context) {
 	defer db.Rollback()
 
 	addys := sys.SubmissionQueue.Addresses()
-	if len(addys) > 0 {
+	if len(addys) > 0 && sys.SequenceProvider != nil {
+		// the provider may request a remote server, it must not delay the tick
+		go sys.updateSequences(ctx, addys)
+	} else if len(addys) > 0 {
 		curSeq, err := db.GetSequenceNumbers(addys)
 		if err != nil {
 			logger.WithStack(err).Error(err)
@@ -334,6 +367,32 @@ func (sys *System) Tick(ctx context.Context) {
 	sys.Metrics.BufferedSubmissionsGauge.Set(float64(sys.SubmissionQueue.Size()))
 }
 
+// updateSequences updates the submission queue with the sequence numbers of
+// addresses read from SequenceProvider. It returns at once when an update is
+// already in progress.
+func (sys *System) updateSequences(ctx context.Context, addresses []string) {
+	sys.sequenceUpdateMutex.Lock()
+	if sys.sequenceUpdateInProgress {
+		sys.sequenceUpdateMutex.Unlock()
+		return
+	}
+	sys.sequenceUpdateInProgress = true
+	sys.sequenceUpdateMutex.Unlock()
+
+	defer func() {
+		sys.sequenceUpdateMutex.Lock()
+		sys.sequenceUpdateInProgress = false
+		sys.sequenceUpdateMutex.Unlock()
+	}()
+
+	curSeq, err := sys.SequenceProvider.GetSequenceNumbers(addresses)
+	if err != nil {
+		log.Ctx(ctx).WithStack(err).Error(err)
+		return
+	}
+	sys.SubmissionQueue.Update(curSeq)
+}
+
 // Init initializes `sys`
 func (sys *System) Init() {
 	sys.initializer.Do(func() {
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/stellar/go/services/horizon/internal/dashboards"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/test"
 	"github.com/stellar/go/services/horizon/internal/txsub/sequence"
@@ -183,6 +184,67 @@ func (suite *SystemTestSuite) TestSubmit_NotFoundError() {
 	assert.Equal(suite.T(), uint64(1), getMetricValue(suite.system.Metrics.SubmissionDuration).GetSummary().GetSampleCount())
 }
 
+// Sequence numbers are read from the SequenceProvider when it is set.
+func (suite *SystemTestSuite) TestSubmit_SequenceProvider() {
+	sequences := &mockSequenceProvider{}
+	suite.system.SequenceProvider = sequences
+
+	suite.db.On("BeginTx", &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	}).Return(nil).Once()
+	suite.db.On("Rollback").Return(nil).Once()
+	suite.db.On("TransactionByHash", mock.Anything, suite.successTx.Transaction.TransactionHash).
+		Return(sql.ErrNoRows).Once()
+	suite.db.On("NoRows", sql.ErrNoRows).Return(true).Once()
+	sequences.On("GetSequenceNumbers", []string{suite.unmuxedSource.Address()}).
+		Return(map[string]uint64{suite.unmuxedSource.Address(): 0}, nil).
+		Once()
+
+	suite.submitter.R.Err = errors.New("busted for some reason")
+	r := <-suite.system.Submit(
+		suite.ctx,
+		suite.successTx.Transaction.TxEnvelope,
+		suite.successXDR,
+		suite.successTx.Transaction.TransactionHash,
+	)
+
+	assert.NotNil(suite.T(), r.Err)
+	assert.True(suite.T(), suite.submitter.WasSubmittedTo)
+	sequences.AssertExpectations(suite.T())
+}
+
+// A SequenceProvider can be ahead of ingestion, when the sequence number of the
+// transaction is already consumed its result is waited for.
+func (suite *SystemTestSuite) TestSubmit_SequenceProviderAheadOfIngestion() {
+	sequences := &mockSequenceProvider{}
+	suite.system.SequenceProvider = sequences
+
+	suite.db.On("BeginTx", &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	}).Return(nil).Once()
+	suite.db.On("Rollback").Return(nil).Once()
+	suite.db.On("TransactionByHash", mock.Anything, suite.successTx.Transaction.TransactionHash).
+		Return(sql.ErrNoRows).Once()
+	suite.db.On("NoRows", sql.ErrNoRows).Return(true).Once()
+	sequences.On("GetSequenceNumbers", []string{suite.unmuxedSource.Address()}).
+		Return(map[string]uint64{suite.unmuxedSource.Address(): 1}, nil).
+		Once()
+
+	r := suite.system.Submit(
+		suite.ctx,
+		suite.successTx.Transaction.TxEnvelope,
+		suite.successXDR,
+		suite.successTx.Transaction.TransactionHash,
+	)
+
+	assert.Equal(suite.T(), 0, len(r))
+	assert.False(suite.T(), suite.submitter.WasSubmittedTo)
+	assert.Equal(suite.T(), []string{suite.successTx.Transaction.TransactionHash}, suite.system.Pending.Pending(suite.ctx))
+	sequences.AssertExpectations(suite.T())
+}
+
 // If the error is bad_seq and the result at the transaction's sequence number is for the same hash, return result.
 func (suite *SystemTestSuite) TestSubmit_BadSeq() {
 	suite.submitter.R = suite.badSeq
@@ -316,6 +378,38 @@ func (suite *SystemTestSuite) TestTick_Deadlock() {
 	suite.system.Tick(suite.ctx)
 }
 
+// Tick doesn't wait for the sequence numbers read from a SequenceProvider.
+func (suite *SystemTestSuite) TestTick_SequenceProvider() {
+	sequences := &mockSequenceProvider{}
+	suite.system.SequenceProvider = sequences
+
+	suite.db.On("BeginTx", &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	}).Return(nil).Once()
+	suite.db.On("Rollback").Return(nil).Once()
+
+	ready := suite.system.SubmissionQueue.Push("address", 1)
+	release := make(chan struct{})
+	sequences.On("GetSequenceNumbers", []string{"address"}).
+		Return(map[string]uint64{"address": 0}, nil).
+		Run(func(args mock.Arguments) {
+			<-release
+		}).
+		Once()
+
+	suite.system.Tick(suite.ctx)
+	close(release)
+
+	select {
+	case err := <-ready:
+		assert.NoError(suite.T(), err)
+	case <-time.After(time.Second):
+		suite.T().Fatal("submission queue was not updated")
+	}
+	sequences.AssertExpectations(suite.T())
+}
+
 // Test that Tick finishes any available transactions,
 func (suite *SystemTestSuite) TestTick_FinishesTransactions() {
 	l := make(chan Result, 1)
@@ -442,3 +536,24 @@ func (suite *SystemTestSuite) TestTick_RemovesStaleSubmissions() {
 func TestSystemTestSuite(t *testing.T) {
 	suite.Run(t, new(SystemTestSuite))
 }
+
+func TestDashboardMetrics(t *testing.T) {
+	system := &System{}
+	system.Init()
+	names := dashboards.MetricNames(
+		system.Metrics.SubmissionDuration,
+		system.Metrics.BufferedSubmissionsGauge,
+		system.Metrics.OpenSubmissionsGauge,
+		system.Metrics.FailedSubmissionsCounter,
+		system.Metrics.SuccessfulSubmissionsCounter,
+		system.Metrics.V0TransactionsCounter,
+		system.Metrics.V1TransactionsCounter,
+		system.Metrics.FeeBumpTransactionsCounter,
+	)
+
+	referenced := dashboards.Metrics("horizon_txsub_")
+	assert.NotEmpty(t, referenced)
+	for _, name := range referenced {
+		assert.True(t, names[name], "%s is not exported", name)
+	}
+}
@@ -86,3 +86,35 @@ func TestDecode(t *testing.T) {
 	_, err = Decode(VersionByteAccountID, "GA3D5KRYM6CB7OWOOOORR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQHES5")
 	assert.Error(t, err)
 }
+
+func TestDecodeMuxedAccount(t *testing.T) {
+	address := "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK"
+	expected := []byte{
+		0x3f, 0x0c, 0x34, 0xbf, 0x93, 0xad, 0x0d, 0x99,
+		0x71, 0xd0, 0x4c, 0xcc, 0x90, 0xf7, 0x05, 0x51,
+		0x1c, 0x83, 0x8a, 0xad, 0x97, 0x34, 0xa4, 0xa2,
+		0xfb, 0x0d, 0x7a, 0x03, 0xfc, 0x7f, 0xe8, 0x9a,
+		0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+
+	payload, err := DecodeMuxedAccount(address)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, payload)
+	}
+	encoded, err := EncodeMuxedAccount(expected)
+	if assert.NoError(t, err) {
+		assert.Equal(t, address, encoded)
+	}
+
+	// M-strkeys are not accepted by the generic functions
+	_, err = Decode(VersionByteMuxedAccount, address)
+	assert.Equal(t, ErrInvalidVersionByte, err)
+	_, _, err = DecodeAny(address)
+	assert.Equal(t, ErrInvalidVersionByte, err)
+	_, err = Encode(VersionByteMuxedAccount, expected)
+	assert.Equal(t, ErrInvalidVersionByte, err)
+
+	// account ids are not muxed accounts
+	_, err = DecodeMuxedAccount("GA3D5KRYM6CB7OWQ6TWYRR3Z4T7GNZLKERYNZGGA5SOAOPIFY6YQHES5")
+	assert.Equal(t, ErrInvalidVersionByte, err)
+}
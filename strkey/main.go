@@ -31,6 +31,10 @@ const (
 	//VersionByteHashX is the version byte used for encoded stellar hashX
 	//signer keys.
 	VersionByteHashX = 23 << 3 // Base32-encodes to 'X...'
+
+	//VersionByteMuxedAccount is the version byte used for encoded stellar
+	//multiplexed addresses (SEP-23).
+	VersionByteMuxedAccount = 12 << 3 // Base32-encodes to 'M...'
 )
 
 // DecodeAny decodes the provided StrKey into a raw value, checking the checksum
@@ -68,7 +72,17 @@ func Decode(expected VersionByte, src string) ([]byte, error) {
 	if err := checkValidVersionByte(expected); err != nil {
 		return nil, err
 	}
+	return decode(expected, src)
+}
+
+// DecodeMuxedAccount decodes the provided multiplexed account address (M...)
+// into a raw value. M-strkeys are not accepted by Decode while SEP23 is a
+// draft, so this is only used where muxed accounts are allowed.
+func DecodeMuxedAccount(src string) ([]byte, error) {
+	return decode(VersionByteMuxedAccount, src)
+}
 
+func decode(expected VersionByte, src string) ([]byte, error) {
 	raw, err := decodeString(src)
 	if err != nil {
 		return nil, err
@@ -109,7 +123,16 @@ func Encode(version VersionByte, src []byte) (string, error) {
 	if err := checkValidVersionByte(version); err != nil {
 		return "", err
 	}
+	return encode(version, src)
+}
+
+// EncodeMuxedAccount encodes the provided raw multiplexed account to a
+// multiplexed account address (M...), see DecodeMuxedAccount.
+func EncodeMuxedAccount(src []byte) (string, error) {
+	return encode(VersionByteMuxedAccount, src)
+}
 
+func encode(version VersionByte, src []byte) (string, error) {
 	var raw bytes.Buffer
 
 	// write version byte
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/stellar/go/support/errors"
@@ -20,12 +21,44 @@ type BatchInsertBuilder struct {
 	// Zero (default) will not add rows until explicitly calling Exec.
 	MaxBatchSize int
 
+	// Columns are the columns inserted in every row. When empty, they are the
+	// keys of the first row added with Row() or the db tags of the first struct
+	// added with RowStruct().
+	Columns []string
+
+	// OnConflict defines what happens to rows conflicting with existing rows.
+	// When nil, conflicting rows fail the insert.
+	OnConflict *ConflictStrategy
+
 	// Suffix adds a sql expression to the end of the query (e.g. an ON CONFLICT clause)
 	Suffix string
 
 	columns       []string
 	rows          [][]interface{}
 	rowStructType reflect.Type
+	rowsAffected  int64
+}
+
+// ConflictStrategy is the ON CONFLICT clause of a BatchInsertBuilder. Rows
+// conflicting on the Target columns are skipped unless Update columns are set,
+// in which case the existing rows are updated with the values of Update
+// columns of the inserted rows.
+type ConflictStrategy struct {
+	Target []string
+	Update []string
+}
+
+func (s *ConflictStrategy) sql() string {
+	clause := "ON CONFLICT (" + strings.Join(s.Target, ", ") + ")"
+	if len(s.Update) == 0 {
+		return clause + " DO NOTHING"
+	}
+
+	set := make([]string, 0, len(s.Update))
+	for _, column := range s.Update {
+		set = append(set, column+" = EXCLUDED."+column)
+	}
+	return clause + " DO UPDATE SET " + strings.Join(set, ", ")
 }
 
 // Row adds a new row to the batch. All rows must have exactly the same columns
@@ -34,14 +67,17 @@ type BatchInsertBuilder struct {
 // reached).
 func (b *BatchInsertBuilder) Row(row map[string]interface{}) error {
 	if b.columns == nil {
-		b.columns = make([]string, 0, len(row))
 		b.rows = make([][]interface{}, 0)
 
-		for column := range row {
-			b.columns = append(b.columns, column)
+		if len(b.Columns) > 0 {
+			b.columns = b.Columns
+		} else {
+			b.columns = make([]string, 0, len(row))
+			for column := range row {
+				b.columns = append(b.columns, column)
+			}
+			sort.Strings(b.columns)
 		}
-
-		sort.Strings(b.columns)
 	}
 
 	if len(b.columns) != len(row) {
@@ -69,7 +105,11 @@ func (b *BatchInsertBuilder) Row(row map[string]interface{}) error {
 
 func (b *BatchInsertBuilder) RowStruct(row interface{}) error {
 	if b.columns == nil {
-		b.columns = columnsForStruct(row)
+		if len(b.Columns) > 0 {
+			b.columns = b.Columns
+		} else {
+			b.columns = columnsForStruct(row)
+		}
 		b.rows = make([][]interface{}, 0)
 	}
 
@@ -86,6 +126,9 @@ func (b *BatchInsertBuilder) RowStruct(row interface{}) error {
 	// convert fields values to interface{}
 	columnValues := make([]interface{}, len(b.columns))
 	for i, rval := range rvals {
+		if !rval.IsValid() {
+			return errors.Errorf(`column "%s" does not exist`, b.columns[i])
+		}
 		columnValues[i] = rval.Interface()
 	}
 
@@ -101,12 +144,25 @@ func (b *BatchInsertBuilder) RowStruct(row interface{}) error {
 
 func (b *BatchInsertBuilder) insertSQL() sq.InsertBuilder {
 	insertStatement := sq.Insert(b.Table.Name).Columns(b.columns...)
+	if b.OnConflict != nil {
+		insertStatement = insertStatement.Suffix(b.OnConflict.sql())
+	}
 	if len(b.Suffix) > 0 {
 		return insertStatement.Suffix(b.Suffix)
 	}
 	return insertStatement
 }
 
+// ExecRowsAffected works like Exec but also returns the number of rows
+// inserted (or updated by OnConflict) since the previous call, including
+// batches inserted when MaxBatchSize was reached.
+func (b *BatchInsertBuilder) ExecRowsAffected() (int64, error) {
+	err := b.Exec()
+	rowsAffected := b.rowsAffected
+	b.rowsAffected = 0
+	return rowsAffected, err
+}
+
 // Exec inserts rows in batches. In case of errors it's possible that some batches
 // were added so this should be run in a DB transaction for easy rollbacks.
 func (b *BatchInsertBuilder) Exec() error {
@@ -118,9 +174,8 @@ func (b *BatchInsertBuilder) Exec() error {
 		paramsCount += len(row)
 
 		if paramsCount > postgresQueryMaxParams-2*len(b.columns) {
-			_, err := b.Table.Session.Exec(sql)
-			if err != nil {
-				return errors.Wrap(err, fmt.Sprintf("error adding values while inserting to %s", b.Table.Name))
+			if err := b.exec(sql); err != nil {
+				return err
 			}
 			paramsCount = 0
 			sql = b.insertSQL()
@@ -129,9 +184,8 @@ func (b *BatchInsertBuilder) Exec() error {
 
 	// Insert last batch
 	if paramsCount > 0 {
-		_, err := b.Table.Session.Exec(sql)
-		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("error adding values while inserting to %s", b.Table.Name))
+		if err := b.exec(sql); err != nil {
+			return err
 		}
 	}
 
@@ -139,3 +193,14 @@ func (b *BatchInsertBuilder) Exec() error {
 	b.rows = make([][]interface{}, 0)
 	return nil
 }
+
+func (b *BatchInsertBuilder) exec(sql sq.InsertBuilder) error {
+	result, err := b.Table.Session.Exec(sql)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error adding values while inserting to %s", b.Table.Name))
+	}
+	if rows, err := result.RowsAffected(); err == nil {
+		b.rowsAffected += rows
+	}
+	return nil
+}
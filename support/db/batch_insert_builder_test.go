@@ -149,3 +149,84 @@ func TestBatchInsertBuilder(t *testing.T) {
 		},
 	)
 }
+
+func TestBatchInsertBuilderConflictStrategy(t *testing.T) {
+	db := dbtest.Postgres(t).Load(testSchema)
+	defer db.Close()
+	sess := &Session{DB: db.Open(), Ctx: context.Background()}
+	defer sess.DB.Close()
+
+	insertBuilder := &BatchInsertBuilder{
+		Table:      sess.GetTable("people"),
+		Columns:    []string{"name", "hunger_level"},
+		OnConflict: &ConflictStrategy{Target: []string{"name"}},
+	}
+
+	// rows are inserted with the given columns only
+	err := insertBuilder.RowStruct(invalidHungerRow{
+		Name:        "scott",
+		HungerLevel: "1",
+		LastName:    "unused",
+	})
+	assert.NoError(t, err)
+
+	// conflicting rows are skipped
+	rows, err := insertBuilder.ExecRowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), rows)
+
+	var found []person
+	err = sess.SelectRaw(&found, `SELECT * FROM people WHERE name = 'scott'`)
+	require.NoError(t, err)
+	assert.Equal(t, []person{{Name: "scott", HungerLevel: "1000000"}}, found)
+
+	insertBuilder = &BatchInsertBuilder{
+		Table:        sess.GetTable("people"),
+		MaxBatchSize: 1,
+		OnConflict: &ConflictStrategy{
+			Target: []string{"name"},
+			Update: []string{"hunger_level"},
+		},
+	}
+	err = insertBuilder.Row(map[string]interface{}{
+		"name":         "scott",
+		"hunger_level": "1",
+	})
+	assert.NoError(t, err)
+	err = insertBuilder.Row(map[string]interface{}{
+		"name":         "bubba",
+		"hunger_level": "120",
+	})
+	assert.NoError(t, err)
+
+	// rows affected include the batches inserted when MaxBatchSize is reached
+	rows, err = insertBuilder.ExecRowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+
+	rows, err = insertBuilder.ExecRowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), rows)
+
+	err = sess.SelectRaw(&found, `SELECT * FROM people WHERE name IN ('scott', 'bubba') ORDER BY name`)
+	require.NoError(t, err)
+	assert.Equal(t, []person{
+		{Name: "bubba", HungerLevel: "120"},
+		{Name: "scott", HungerLevel: "1"},
+	}, found)
+
+	insertBuilder = &BatchInsertBuilder{
+		Table:   sess.GetTable("people"),
+		Columns: []string{"name", "middle_name"},
+	}
+	err = insertBuilder.RowStruct(hungerRow{Name: "bubba2"})
+	assert.EqualError(t, err, `column "middle_name" does not exist`)
+}
+
+func TestConflictStrategySQL(t *testing.T) {
+	strategy := &ConflictStrategy{Target: []string{"a", "b"}}
+	assert.Equal(t, "ON CONFLICT (a, b) DO NOTHING", strategy.sql())
+
+	strategy.Update = []string{"c", "d"}
+	assert.Equal(t, "ON CONFLICT (a, b) DO UPDATE SET c = EXCLUDED.c, d = EXCLUDED.d", strategy.sql())
+}
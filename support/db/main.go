@@ -14,6 +14,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
@@ -30,6 +31,9 @@ var (
 	// ErrCancelled is an error returned by Session methods when request has
 	// been cancelled (ex. context cancelled).
 	ErrCancelled = errors.New("canceling statement due to user request")
+	// ErrTimeout is an error returned by Session methods when a query took
+	// longer than Session.QueryTimeout.
+	ErrTimeout = errors.New("canceling statement due to query timeout")
 )
 
 // Conn represents a connection to a single database.
@@ -101,8 +105,28 @@ type Session struct {
 	// Ctx is the context in which the repo is operating under.
 	Ctx context.Context
 
-	tx        *sqlx.Tx
-	txOptions *sql.TxOptions
+	// Replicas, when set, are read replicas of DB used by sessions returned
+	// by Replica.
+	Replicas *ReplicaSet
+
+	// QueryTimeout, when greater than 0, is the maximum duration of a single
+	// Get, Select or Exec query. Queries exceeding it are cancelled and
+	// return ErrTimeout. It does not apply to Query methods because the
+	// returned rows are read after they return.
+	QueryTimeout time.Duration
+
+	// Metrics, when set, records the latency, the number of rows and the
+	// errors of queries.
+	Metrics *QueryMetrics
+
+	// Statements, when set, caches prepared statements of the queries run
+	// by the session.
+	Statements *StatementCache
+
+	tx         *sqlx.Tx
+	txOptions  *sql.TxOptions
+	useReplica bool
+	queryName  string
 }
 
 type SessionInterface interface {
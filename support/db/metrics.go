@@ -0,0 +1,113 @@
+package db
+
+import (
+	"database/sql"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QueryMetrics records the latency, the number of rows and the errors of the
+// queries run by sessions. Queries are labelled by type (get, select, exec or
+// query) and by name. The name is set with Session.Named or, by default, is
+// the first table the query reads from or writes to.
+type QueryMetrics struct {
+	// DurationSummary exposes the duration of queries.
+	DurationSummary *prometheus.SummaryVec
+	// RowsSummary exposes the number of rows returned by get and select
+	// queries and affected by exec queries.
+	RowsSummary *prometheus.SummaryVec
+	// ErrorsCounter counts failed queries. Get queries which found no rows
+	// are not failed.
+	ErrorsCounter *prometheus.CounterVec
+}
+
+// NewQueryMetrics creates QueryMetrics with metrics in the given namespace.
+// They must be registered before being exported.
+func NewQueryMetrics(namespace string) *QueryMetrics {
+	labels := []string{"type", "query"}
+	return &QueryMetrics{
+		DurationSummary: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace, Subsystem: "db", Name: "query_duration_seconds",
+				Help: "duration of database queries",
+			},
+			labels,
+		),
+		RowsSummary: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Namespace: namespace, Subsystem: "db", Name: "query_rows",
+				Help: "number of rows returned or affected by database queries",
+			},
+			labels,
+		),
+		ErrorsCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace, Subsystem: "db", Name: "query_errors_total",
+				Help: "number of failed database queries",
+			},
+			labels,
+		),
+	}
+}
+
+// Register registers the metrics in the given registerer.
+func (m *QueryMetrics) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(m.DurationSummary)
+	registerer.MustRegister(m.RowsSummary)
+	registerer.MustRegister(m.ErrorsCounter)
+}
+
+// observe records a query which started at start and returned rows (-1 when
+// unknown) and err.
+func (m *QueryMetrics) observe(typ, name string, start time.Time, rows int64, err error) {
+	labels := prometheus.Labels{"type": typ, "query": name}
+	m.DurationSummary.With(labels).Observe(time.Since(start).Seconds())
+	switch {
+	case err == sql.ErrNoRows:
+		rows = 0
+	case err != nil:
+		m.ErrorsCounter.With(labels).Inc()
+		return
+	}
+	if rows >= 0 {
+		m.RowsSummary.With(labels).Observe(float64(rows))
+	}
+}
+
+var queryTablePattern = regexp.MustCompile(`(?i)\b(?:from|into|update)\s+"?([a-z_][a-z0-9_]*)`)
+
+// queryName returns the first table in query, "other" if there is none.
+func queryName(query string) string {
+	match := queryTablePattern.FindStringSubmatch(query)
+	if match == nil {
+		return "other"
+	}
+	return strings.ToLower(match[1])
+}
+
+// sliceLen returns the length of the slice dest points to, -1 if dest is not
+// a pointer to a slice.
+func sliceLen(dest interface{}) int64 {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return -1
+	}
+	return int64(v.Elem().Len())
+}
+
+// rowsAffected returns the number of rows affected by an exec query, -1 when
+// unknown.
+func rowsAffected(result sql.Result, err error) int64 {
+	if err != nil || result == nil {
+		return -1
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return rows
+}
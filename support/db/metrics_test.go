@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stellar/go/support/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryName(t *testing.T) {
+	assert.Equal(t, "people", queryName("SELECT * FROM people WHERE name = ?"))
+	assert.Equal(t, "history_trades", queryName("select htrd.* from history_trades htrd join history_assets ha on true"))
+	assert.Equal(t, "history_trades", queryName(`SELECT * FROM (SELECT * FROM "history_trades") t`))
+	assert.Equal(t, "people", queryName("INSERT INTO people (name) VALUES (?)"))
+	assert.Equal(t, "people", queryName("UPDATE people SET name = ?"))
+	assert.Equal(t, "people", queryName("DELETE FROM people"))
+	assert.Equal(t, "other", queryName("SELECT pg_sleep(1)"))
+}
+
+func getSummary(t *testing.T, summary *prometheus.SummaryVec, labels ...string) *dto.Summary {
+	value := &dto.Metric{}
+	require.NoError(t, summary.WithLabelValues(labels...).(prometheus.Metric).Write(value))
+	return value.GetSummary()
+}
+
+func TestQueryMetricsObserve(t *testing.T) {
+	metrics := NewQueryMetrics("test")
+	start := time.Now()
+
+	metrics.observe("select", "people", start, 3, nil)
+	metrics.observe("select", "people", start, 1, nil)
+	assert.Equal(t, uint64(2), getSummary(t, metrics.DurationSummary, "select", "people").GetSampleCount())
+	assert.Equal(t, float64(4), getSummary(t, metrics.RowsSummary, "select", "people").GetSampleSum())
+
+	// no rows is not an error
+	metrics.observe("get", "people", start, 1, sql.ErrNoRows)
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.ErrorsCounter.WithLabelValues("get", "people")))
+	assert.Equal(t, uint64(1), getSummary(t, metrics.RowsSummary, "get", "people").GetSampleCount())
+	assert.Equal(t, float64(0), getSummary(t, metrics.RowsSummary, "get", "people").GetSampleSum())
+
+	metrics.observe("get", "people", start, 1, errors.New("connection refused"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.ErrorsCounter.WithLabelValues("get", "people")))
+	assert.Equal(t, uint64(2), getSummary(t, metrics.DurationSummary, "get", "people").GetSampleCount())
+	assert.Equal(t, uint64(1), getSummary(t, metrics.RowsSummary, "get", "people").GetSampleCount())
+
+	// unknown number of rows
+	metrics.observe("query", "people", start, -1, nil)
+	assert.Equal(t, uint64(1), getSummary(t, metrics.DurationSummary, "query", "people").GetSampleCount())
+	assert.Equal(t, uint64(0), getSummary(t, metrics.RowsSummary, "query", "people").GetSampleCount())
+}
+
+func TestSessionMetrics(t *testing.T) {
+	db := dbtest.Postgres(t).Load(testSchema)
+	defer db.Close()
+
+	sess := &Session{DB: db.Open(), Ctx: context.Background(), Metrics: NewQueryMetrics("test")}
+	defer sess.DB.Close()
+	assert.Equal(t, sess.Metrics, sess.Clone().Metrics)
+	assert.Equal(t, sess.Metrics, sess.WithContext(context.Background()).Metrics)
+	assert.Equal(t, sess.Metrics, sess.Replica().Metrics)
+
+	var names []string
+	require.NoError(t, sess.SelectRaw(&names, "SELECT name FROM people"))
+	require.NoError(t, sess.Named("names").SelectRaw(&names, "SELECT name FROM people"))
+	_, err := sess.ExecRaw("DELETE FROM people WHERE name = 'scott'")
+	require.NoError(t, err)
+	_, err = sess.ExecRaw("SELECT * FROM unknown_table")
+	require.Error(t, err)
+
+	assert.Equal(t, float64(3), getSummary(t, sess.Metrics.RowsSummary, "select", "people").GetSampleSum())
+	assert.Equal(t, float64(3), getSummary(t, sess.Metrics.RowsSummary, "select", "names").GetSampleSum())
+	assert.Equal(t, float64(1), getSummary(t, sess.Metrics.RowsSummary, "exec", "people").GetSampleSum())
+	assert.Equal(t, float64(1), testutil.ToFloat64(sess.Metrics.ErrorsCounter.WithLabelValues("exec", "unknown_table")))
+}
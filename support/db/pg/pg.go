@@ -13,3 +13,14 @@ func IsUniqueViolation(err error) bool {
 		return false
 	}
 }
+
+// IsDuplicateTable returns true if err was raised because a table (or
+// another relation) of the same name already exists.
+func IsDuplicateTable(err error) bool {
+	switch pgerr := errors.Cause(err).(type) {
+	case *pq.Error:
+		return string(pgerr.Code) == "42P07"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stellar/go/support/log"
+)
+
+// PoolStats is the state of a connection pool sampled by a PoolMonitor.
+type PoolStats struct {
+	// Saturation is the fraction of the maximum number of open connections
+	// which are in use, it's 0 when the number of connections is unlimited.
+	Saturation float64
+	// WaitCount and WaitDuration are the number of connections waited for
+	// and the time spent waiting for them since the previous sample.
+	WaitCount    int64
+	WaitDuration time.Duration
+	// StaleClosed is the number of connections closed since the previous
+	// sample because they were idle or reached their maximum lifetime.
+	StaleClosed int64
+	// Exhausted is true when all the connections are in use and queries
+	// waited for a connection since the previous sample.
+	Exhausted bool
+}
+
+// PoolMonitor samples the statistics of a connection pool, when Sample is
+// called periodically, to report its saturation, the time spent waiting for
+// connections and the stale connections closed by the pool. OnExhausted is
+// called when the pool is exhausted or recovers so that load can be shed. It
+// is safe for concurrent use.
+type PoolMonitor struct {
+	// OnExhausted, if set, is called with true when the pool becomes
+	// exhausted and with false when it recovers.
+	OnExhausted func(exhausted bool)
+
+	db *sqlx.DB
+
+	mutex     sync.RWMutex
+	last      sql.DBStats
+	exhausted bool
+
+	saturationGauge       prometheus.Gauge
+	exhaustedGauge        prometheus.Gauge
+	idleGauge             prometheus.GaugeFunc
+	maxIdleClosedCounter  prometheus.CounterFunc
+	lifetimeClosedCounter prometheus.CounterFunc
+}
+
+// NewPoolMonitor creates a PoolMonitor of db with metrics in the given
+// namespace. They must be registered before being exported.
+func NewPoolMonitor(namespace string, db *sqlx.DB) *PoolMonitor {
+	return &PoolMonitor{
+		db: db,
+		saturationGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "db", Name: "pool_saturation",
+			Help: "fraction of the maximum number of open connections in use",
+		}),
+		exhaustedGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "db", Name: "pool_exhausted",
+			Help: "1 when all the connections are in use and queries wait for a connection",
+		}),
+		idleGauge: prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: namespace, Subsystem: "db", Name: "idle_connections",
+				Help: "number of idle connections",
+			},
+			func() float64 {
+				return float64(db.Stats().Idle)
+			},
+		),
+		maxIdleClosedCounter: prometheus.NewCounterFunc(
+			prometheus.CounterOpts{
+				Namespace: namespace, Subsystem: "db", Name: "max_idle_closed_total",
+				Help: "total number of connections closed because of the maximum number of idle connections",
+			},
+			func() float64 {
+				return float64(db.Stats().MaxIdleClosed)
+			},
+		),
+		lifetimeClosedCounter: prometheus.NewCounterFunc(
+			prometheus.CounterOpts{
+				Namespace: namespace, Subsystem: "db", Name: "max_lifetime_closed_total",
+				Help: "total number of connections closed because of the maximum connection lifetime",
+			},
+			func() float64 {
+				return float64(db.Stats().MaxLifetimeClosed)
+			},
+		),
+	}
+}
+
+// Register registers the metrics in the given registerer.
+func (m *PoolMonitor) Register(registerer prometheus.Registerer) {
+	registerer.MustRegister(m.saturationGauge)
+	registerer.MustRegister(m.exhaustedGauge)
+	registerer.MustRegister(m.idleGauge)
+	registerer.MustRegister(m.maxIdleClosedCounter)
+	registerer.MustRegister(m.lifetimeClosedCounter)
+}
+
+// Exhausted returns true if the pool was exhausted when it was last sampled.
+// A nil PoolMonitor is never exhausted.
+func (m *PoolMonitor) Exhausted() bool {
+	if m == nil {
+		return false
+	}
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.exhausted
+}
+
+// Sample samples the statistics of the pool and updates the metrics.
+func (m *PoolMonitor) Sample() PoolStats {
+	return m.update(m.db.Stats())
+}
+
+func (m *PoolMonitor) update(current sql.DBStats) PoolStats {
+	m.mutex.Lock()
+	stats := PoolStats{
+		WaitCount:    current.WaitCount - m.last.WaitCount,
+		WaitDuration: current.WaitDuration - m.last.WaitDuration,
+		StaleClosed: current.MaxIdleClosed - m.last.MaxIdleClosed +
+			current.MaxLifetimeClosed - m.last.MaxLifetimeClosed,
+	}
+	if current.MaxOpenConnections > 0 {
+		stats.Saturation = float64(current.InUse) / float64(current.MaxOpenConnections)
+		stats.Exhausted = current.InUse >= current.MaxOpenConnections && stats.WaitCount > 0
+	}
+	changed := stats.Exhausted != m.exhausted
+	m.last = current
+	m.exhausted = stats.Exhausted
+	m.mutex.Unlock()
+
+	m.saturationGauge.Set(stats.Saturation)
+	if stats.Exhausted {
+		m.exhaustedGauge.Set(1)
+	} else {
+		m.exhaustedGauge.Set(0)
+	}
+
+	if changed {
+		logger := log.WithFields(log.F{
+			"in_use":        current.InUse,
+			"max_open":      current.MaxOpenConnections,
+			"wait_count":    stats.WaitCount,
+			"wait_duration": stats.WaitDuration.Seconds(),
+		})
+		if stats.Exhausted {
+			logger.Warn("Database connection pool exhausted")
+		} else {
+			logger.Info("Database connection pool recovered")
+		}
+		if m.OnExhausted != nil {
+			m.OnExhausted(stats.Exhausted)
+		}
+	}
+	return stats
+}
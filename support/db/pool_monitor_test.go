@@ -0,0 +1,69 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolMonitor(t *testing.T) {
+	db, err := sqlx.Open("postgres", "postgres://localhost:1/pool?sslmode=disable")
+	require.NoError(t, err)
+	defer db.Close()
+
+	monitor := NewPoolMonitor("test", db)
+	var changes []bool
+	monitor.OnExhausted = func(exhausted bool) {
+		changes = append(changes, exhausted)
+	}
+	var nilMonitor *PoolMonitor
+	assert.False(t, nilMonitor.Exhausted())
+
+	// no connection is opened until a query is run
+	stats := monitor.Sample()
+	assert.Equal(t, PoolStats{}, stats)
+	assert.False(t, monitor.Exhausted())
+
+	// all the connections are in use but no query waited
+	stats = monitor.update(sql.DBStats{MaxOpenConnections: 4, InUse: 4})
+	assert.Equal(t, PoolStats{Saturation: 1}, stats)
+	assert.False(t, monitor.Exhausted())
+
+	stats = monitor.update(sql.DBStats{
+		MaxOpenConnections: 4,
+		InUse:              4,
+		WaitCount:          3,
+		WaitDuration:       time.Second,
+		MaxIdleClosed:      1,
+	})
+	assert.Equal(t, PoolStats{
+		Saturation:   1,
+		WaitCount:    3,
+		WaitDuration: time.Second,
+		StaleClosed:  1,
+		Exhausted:    true,
+	}, stats)
+	assert.True(t, monitor.Exhausted())
+	assert.Equal(t, float64(1), testutil.ToFloat64(monitor.exhaustedGauge))
+
+	// waits are counted since the previous sample
+	stats = monitor.update(sql.DBStats{
+		MaxOpenConnections: 4,
+		InUse:              2,
+		WaitCount:          3,
+		WaitDuration:       time.Second,
+		MaxIdleClosed:      1,
+		MaxLifetimeClosed:  2,
+	})
+	assert.Equal(t, PoolStats{Saturation: 0.5, StaleClosed: 2}, stats)
+	assert.False(t, monitor.Exhausted())
+	assert.Equal(t, float64(0), testutil.ToFloat64(monitor.exhaustedGauge))
+	assert.Equal(t, 0.5, testutil.ToFloat64(monitor.saturationGauge))
+
+	assert.Equal(t, []bool{true, false}, changes)
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"sync"
+)
+
+type queryRecorderContextKey struct{}
+
+// RecordedQuery is a query run by a session, see QueryRecorder.
+type RecordedQuery struct {
+	// Type is the method which ran the query: get, select, query or exec.
+	Type string
+	// SQL is the query sent to the database, with the placeholders of the
+	// dialect.
+	SQL  string
+	Args []interface{}
+}
+
+// QueryRecorder records the queries run by sessions whose context carries
+// it, see WithQueryRecorder. It's safe for concurrent use.
+type QueryRecorder struct {
+	mutex   sync.Mutex
+	queries []RecordedQuery
+}
+
+// WithQueryRecorder returns a copy of ctx carrying recorder. Sessions whose
+// Ctx is derived from it record the queries they run in recorder.
+func WithQueryRecorder(ctx context.Context, recorder *QueryRecorder) context.Context {
+	return context.WithValue(ctx, queryRecorderContextKey{}, recorder)
+}
+
+// Queries returns the recorded queries in the order they were run.
+func (r *QueryRecorder) Queries() []RecordedQuery {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]RecordedQuery(nil), r.queries...)
+}
+
+func (r *QueryRecorder) record(typ, query string, args []interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.queries = append(r.queries, RecordedQuery{Type: typ, SQL: query, Args: args})
+}
+
+// record records the query in the QueryRecorder of the session context, if
+// any.
+func (s *Session) record(typ, query string, args []interface{}) {
+	if s.Ctx == nil {
+		return
+	}
+	if recorder, ok := s.Ctx.Value(queryRecorderContextKey{}).(*QueryRecorder); ok {
+		recorder.record(typ, query, args)
+	}
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stellar/go/support/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRecorderContext(t *testing.T) {
+	recorder := &QueryRecorder{}
+	sess := &Session{Ctx: WithQueryRecorder(context.Background(), recorder)}
+	sess.record("select", "SELECT 1", nil)
+	sess.WithContext(sess.Ctx).record("get", "SELECT $1", []interface{}{2})
+
+	// sessions without a recorder don't record queries
+	(&Session{Ctx: context.Background()}).record("select", "SELECT 3", nil)
+	(&Session{}).record("select", "SELECT 4", nil)
+
+	assert.Equal(t, []RecordedQuery{
+		{Type: "select", SQL: "SELECT 1"},
+		{Type: "get", SQL: "SELECT $1", Args: []interface{}{2}},
+	}, recorder.Queries())
+}
+
+func TestQueryRecorder(t *testing.T) {
+	db := dbtest.Postgres(t).Load(testSchema)
+	defer db.Close()
+
+	recorder := &QueryRecorder{}
+	sess := &Session{DB: db.Open(), Ctx: WithQueryRecorder(context.Background(), recorder)}
+	defer sess.DB.Close()
+
+	var name string
+	require.NoError(t, sess.GetRaw(&name, "SELECT name FROM people WHERE hunger_level = ?", 1000000))
+
+	queries := recorder.Queries()
+	require.Len(t, queries, 1)
+	assert.Equal(t, "get", queries[0].Type)
+	assert.Equal(t, "SELECT name FROM people WHERE hunger_level = $1", queries[0].SQL)
+	assert.Equal(t, []interface{}{1000000}, queries[0].Args)
+}
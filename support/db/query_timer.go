@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+type queryTimerContextKey struct{}
+
+// QueryTimer accumulates the time spent running the queries of sessions
+// whose context carries it, see WithQueryTimer. It's safe for concurrent use.
+type QueryTimer struct {
+	nanoseconds int64
+}
+
+// WithQueryTimer returns a copy of ctx carrying timer. Sessions whose Ctx is
+// derived from it add the duration of the queries they run to timer.
+func WithQueryTimer(ctx context.Context, timer *QueryTimer) context.Context {
+	return context.WithValue(ctx, queryTimerContextKey{}, timer)
+}
+
+// Duration returns the total duration of the timed queries.
+func (t *QueryTimer) Duration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.nanoseconds))
+}
+
+// time adds the duration of the query which started at start to the
+// QueryTimer of the session context, if any.
+func (s *Session) time(start time.Time) {
+	if s.Ctx == nil {
+		return
+	}
+	if timer, ok := s.Ctx.Value(queryTimerContextKey{}).(*QueryTimer); ok {
+		atomic.AddInt64(&timer.nanoseconds, int64(time.Since(start)))
+	}
+}
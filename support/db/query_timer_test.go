@@ -0,0 +1,23 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryTimerContext(t *testing.T) {
+	timer := &QueryTimer{}
+	sess := &Session{Ctx: WithQueryTimer(context.Background(), timer)}
+	sess.time(time.Now().Add(-time.Second))
+	sess.WithContext(sess.Ctx).time(time.Now().Add(-2 * time.Second))
+
+	// sessions without a timer don't time queries
+	(&Session{Ctx: context.Background()}).time(time.Now().Add(-time.Hour))
+	(&Session{}).time(time.Now().Add(-time.Hour))
+
+	assert.True(t, timer.Duration() >= 3*time.Second)
+	assert.True(t, timer.Duration() < time.Hour)
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+)
+
+// ReplicaSet is a set of read replicas of the primary database of a Session.
+// Read queries of sessions returned by Session.Replica are sent to healthy
+// replicas in a round robin fashion. When no replica is healthy queries are
+// sent to the primary database. It is safe for concurrent use.
+type ReplicaSet struct {
+	replicas []*replica
+	next     uint32
+}
+
+type replica struct {
+	db      *sqlx.DB
+	healthy int32
+}
+
+// NewReplicaSet creates a ReplicaSet of the given databases. All replicas are
+// considered healthy until a health check or a query fails.
+func NewReplicaSet(dbs ...*sqlx.DB) *ReplicaSet {
+	set := &ReplicaSet{}
+	for _, db := range dbs {
+		set.replicas = append(set.replicas, &replica{db: db, healthy: 1})
+	}
+	return set
+}
+
+// Len returns the number of replicas in the set.
+func (r *ReplicaSet) Len() int {
+	return len(r.replicas)
+}
+
+// HealthyCount returns the number of replicas which are currently healthy.
+func (r *ReplicaSet) HealthyCount() int {
+	count := 0
+	for _, replica := range r.replicas {
+		if replica.isHealthy() {
+			count++
+		}
+	}
+	return count
+}
+
+// CheckHealth pings all the replicas and updates their health.
+func (r *ReplicaSet) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i, member := range r.replicas {
+		wg.Add(1)
+		go func(i int, member *replica) {
+			defer wg.Done()
+			err := member.db.PingContext(ctx)
+			if err != nil && ctx.Err() != nil {
+				// the check was cancelled, health is unknown
+				return
+			}
+			member.setHealthy(err == nil, i, err)
+		}(i, member)
+	}
+	wg.Wait()
+}
+
+// Run checks health of the replicas every interval until ctx is cancelled.
+func (r *ReplicaSet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.CheckHealth(ctx)
+		}
+	}
+}
+
+// Close closes all the replicas.
+func (r *ReplicaSet) Close() error {
+	var result error
+	for _, replica := range r.replicas {
+		if err := replica.db.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// pick returns the next healthy replica or nil if there is none.
+func (r *ReplicaSet) pick() *replica {
+	n := uint32(len(r.replicas))
+	if n == 0 {
+		return nil
+	}
+	start := atomic.AddUint32(&r.next, 1)
+	for i := uint32(0); i < n; i++ {
+		replica := r.replicas[(start+i)%n]
+		if replica.isHealthy() {
+			return replica
+		}
+	}
+	return nil
+}
+
+// markUnhealthy is called when a query sent to the replica failed because of
+// a connection error. The replica is not used until a health check succeeds.
+func (r *ReplicaSet) markUnhealthy(unhealthy *replica, err error) {
+	for i, replica := range r.replicas {
+		if replica == unhealthy {
+			replica.setHealthy(false, i, err)
+		}
+	}
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+func (r *replica) setHealthy(healthy bool, index int, err error) {
+	var value int32
+	if healthy {
+		value = 1
+	}
+	if atomic.SwapInt32(&r.healthy, value) == value {
+		return
+	}
+
+	logger := log.WithField("replica", index)
+	if healthy {
+		logger.Info("Database replica is healthy")
+	} else {
+		logger.WithField("err", err).Warn("Database replica is unhealthy")
+	}
+}
+
+// isConnectionError returns true if err is caused by a broken connection to
+// the database (as opposed to an invalid query).
+func isConnectionError(err error) bool {
+	err = errors.Cause(err)
+	if err == driver.ErrBadConn {
+		return true
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stellar/go/support/db/dbtest"
+	"github.com/stellar/go/support/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicaSetPick(t *testing.T) {
+	first, err := sqlx.Open("postgres", "postgres://localhost:1/first?sslmode=disable")
+	require.NoError(t, err)
+	second, err := sqlx.Open("postgres", "postgres://localhost:1/second?sslmode=disable")
+	require.NoError(t, err)
+
+	set := NewReplicaSet(first, second)
+	defer set.Close()
+	assert.Equal(t, 2, set.Len())
+	assert.Equal(t, 2, set.HealthyCount())
+
+	// round robin
+	picked := map[*sqlx.DB]int{}
+	for i := 0; i < 4; i++ {
+		picked[set.pick().db]++
+	}
+	assert.Equal(t, map[*sqlx.DB]int{first: 2, second: 2}, picked)
+
+	set.markUnhealthy(set.replicas[0], driver.ErrBadConn)
+	assert.Equal(t, 1, set.HealthyCount())
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, second, set.pick().db)
+	}
+
+	// nothing listens on port 1
+	set.CheckHealth(context.Background())
+	assert.Equal(t, 0, set.HealthyCount())
+	assert.Nil(t, set.pick())
+
+	assert.Nil(t, NewReplicaSet().pick())
+}
+
+func TestIsConnectionError(t *testing.T) {
+	assert.True(t, isConnectionError(driver.ErrBadConn))
+	assert.True(t, isConnectionError(errors.Wrap(driver.ErrBadConn, "select failed")))
+	assert.False(t, isConnectionError(errors.New("pq: syntax error")))
+}
+
+func TestSessionReplica(t *testing.T) {
+	primaryDB := dbtest.Postgres(t).Load(testSchema)
+	defer primaryDB.Close()
+	replicaDB := dbtest.Postgres(t).Load(testSchema)
+	defer replicaDB.Close()
+
+	sess := &Session{DB: primaryDB.Open(), Ctx: context.Background()}
+	defer sess.DB.Close()
+	sess.Replicas = NewReplicaSet(replicaDB.Open())
+	defer sess.Replicas.Close()
+
+	// make the databases distinguishable
+	_, err := sess.Replicas.replicas[0].db.Exec("DELETE FROM people")
+	require.NoError(t, err)
+
+	countPeople := func(s *Session) int {
+		var count int
+		require.NoError(t, s.GetRaw(&count, "SELECT COUNT(*) FROM people"))
+		return count
+	}
+
+	assert.Equal(t, 3, countPeople(sess))
+	assert.Equal(t, 0, countPeople(sess.Replica()))
+	assert.Equal(t, 0, countPeople(sess.Clone().Replica()))
+
+	var names []string
+	require.NoError(t, sess.Replica().SelectRaw(&names, "SELECT name FROM people"))
+	assert.Len(t, names, 0)
+
+	// writes go to the primary
+	_, err = sess.Replica().ExecRaw("DELETE FROM people WHERE name = 'scott'")
+	require.NoError(t, err)
+	assert.Equal(t, 2, countPeople(sess))
+
+	// queries in a transaction go to the primary
+	replica := sess.Replica()
+	require.NoError(t, replica.Begin())
+	assert.Equal(t, 2, countPeople(replica))
+	require.NoError(t, replica.Rollback())
+
+	// fall back to the primary when no replica is healthy
+	sess.Replicas.replicas[0].db.Close()
+	sess.Replicas.CheckHealth(context.Background())
+	assert.Equal(t, 0, sess.Replicas.HealthyCount())
+	assert.Equal(t, 2, countPeople(sess.Replica()))
+}
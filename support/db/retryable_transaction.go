@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+)
+
+const (
+	// pgSerializationFailure and pgDeadlockDetected are the SQLSTATE codes of
+	// the errors raised by transactions conflicting with concurrent ones.
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+var (
+	// RetryableTransactionAttempts is the maximum number of times
+	// RetryableTransaction runs a transaction.
+	RetryableTransactionAttempts = 5
+	// retryableTransactionBackoff is the delay before the first retry, it's
+	// doubled before every following retry.
+	retryableTransactionBackoff = 100 * time.Millisecond
+)
+
+// IsRetryable returns true if err was raised because the transaction
+// conflicted with a concurrent one (a serialization failure or a deadlock)
+// and running it again could succeed.
+func IsRetryable(err error) bool {
+	pqErr, ok := errors.Cause(err).(*pq.Error)
+	if !ok {
+		return false
+	}
+	return pqErr.Code == pgSerializationFailure || pqErr.Code == pgDeadlockDetected
+}
+
+// RetryableTransaction runs fn in a new transaction of the session and
+// commits it. When the transaction fails because it conflicted with a
+// concurrent transaction it is rolled back and fn is run again in a new
+// transaction after a backoff, up to RetryableTransactionAttempts times. fn
+// must only use the session to access the database and must be safe to run
+// several times. The backoff is interrupted when ctx is done.
+func (s *Session) RetryableTransaction(ctx context.Context, fn func() error) error {
+	backoff := retryableTransactionBackoff
+	for attempt := 1; ; attempt++ {
+		err := s.runTransaction(fn)
+		if err == nil || !IsRetryable(err) || attempt >= RetryableTransactionAttempts {
+			return err
+		}
+
+		log.WithFields(log.F{
+			"attempt": attempt,
+			"backoff": backoff.Seconds(),
+		}).WithError(err).Warn("Retrying transaction after conflict")
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "transaction not retried")
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (s *Session) runTransaction(fn func() error) error {
+	if err := s.Begin(); err != nil {
+		return errors.Wrap(err, "Error starting a transaction")
+	}
+	defer func() {
+		// Commit ends the transaction even when it fails so it's only
+		// rolled back when fn failed
+		if s.tx != nil {
+			s.Rollback()
+		}
+	}()
+
+	if err := fn(); err != nil {
+		return err
+	}
+	return errors.Wrap(s.Commit(), "Error committing db transaction")
+}
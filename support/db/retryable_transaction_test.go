@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stellar/go/support/db/dbtest"
+	"github.com/stellar/go/support/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(&pq.Error{Code: "40001"}))
+	assert.True(t, IsRetryable(errors.Wrap(&pq.Error{Code: "40P01"}, "exec failed")))
+	assert.False(t, IsRetryable(&pq.Error{Code: "23505"}))
+	assert.False(t, IsRetryable(errors.New("40001")))
+	assert.False(t, IsRetryable(nil))
+}
+
+func TestRetryableTransaction(t *testing.T) {
+	db := dbtest.Postgres(t).Load(testSchema)
+	defer db.Close()
+	sess := &Session{DB: db.Open(), Ctx: context.Background()}
+	defer sess.DB.Close()
+
+	defer func(backoff time.Duration) {
+		retryableTransactionBackoff = backoff
+	}(retryableTransactionBackoff)
+	retryableTransactionBackoff = time.Millisecond
+
+	// conflicts are retried in a new transaction
+	attempts := 0
+	err := sess.RetryableTransaction(context.Background(), func() error {
+		attempts++
+		if _, err := sess.ExecRaw("DELETE FROM people WHERE name = 'scott'"); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return &pq.Error{Code: "40001"}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Nil(t, sess.GetTx())
+
+	var count int
+	require.NoError(t, sess.GetRaw(&count, "SELECT COUNT(*) FROM people"))
+	assert.Equal(t, 2, count)
+
+	// other errors roll back the transaction and are not retried
+	attempts = 0
+	err = sess.RetryableTransaction(context.Background(), func() error {
+		attempts++
+		if _, err := sess.ExecRaw("DELETE FROM people"); err != nil {
+			return err
+		}
+		return errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, 1, attempts)
+	require.NoError(t, sess.GetRaw(&count, "SELECT COUNT(*) FROM people"))
+	assert.Equal(t, 2, count)
+
+	// conflicts are retried at most RetryableTransactionAttempts times
+	attempts = 0
+	err = sess.RetryableTransaction(context.Background(), func() error {
+		attempts++
+		return &pq.Error{Code: "40P01"}
+	})
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, RetryableTransactionAttempts, attempts)
+}
@@ -70,10 +70,50 @@ func (s *Session) GetTxOptions() *sql.TxOptions {
 // context and db. The result will not be bound to any transaction that the
 // source is currently within.
 func (s *Session) Clone() *Session {
-	return &Session{
-		DB:  s.DB,
-		Ctx: s.Ctx,
-	}
+	clone := s.copy()
+	clone.tx = nil
+	clone.txOptions = nil
+	clone.useReplica = false
+	clone.queryName = ""
+	return clone
+}
+
+// Replica returns a copy of the receiver which runs read queries (Get, Select
+// and Query methods) on a healthy replica from Replicas. When there is no
+// healthy replica or the replica connection fails the query is run on DB.
+// Writes and queries of sessions in a transaction always use DB. The data
+// read can be slightly behind DB because of replication lag so it should
+// only be used for queries which don't need to see the latest writes.
+func (s *Session) Replica() *Session {
+	replica := s.copy()
+	replica.useReplica = true
+	return replica
+}
+
+// WithContext returns a copy of the receiver which runs queries with the
+// given context. Unlike Clone the copy stays bound to the transaction the
+// receiver is currently within, so it must only be used to run queries:
+// committing or rolling back the transaction must be done on the receiver.
+func (s *Session) WithContext(ctx context.Context) *Session {
+	copied := s.copy()
+	copied.Ctx = ctx
+	return copied
+}
+
+// Named returns a copy of the receiver whose queries are labelled with name
+// in Metrics instead of the first table they use. Like WithContext the copy
+// stays bound to the transaction the receiver is currently within.
+func (s *Session) Named(name string) *Session {
+	named := s.copy()
+	named.queryName = name
+	return named
+}
+
+// copy returns a shallow copy of the receiver, bound to the same
+// transaction.
+func (s *Session) copy() *Session {
+	copied := *s
+	return &copied
 }
 
 // Close delegates to the underlying database Close method, closing the database
@@ -91,6 +131,9 @@ func (s *Session) Commit() error {
 
 	err := s.tx.Commit()
 	s.logCommit()
+	if s.Statements != nil {
+		s.Statements.release(s.tx)
+	}
 	s.tx = nil
 	s.txOptions = nil
 	return err
@@ -135,14 +178,24 @@ func (s *Session) GetRaw(dest interface{}, query string, args ...interface{}) er
 		return errors.Wrap(err, "replace placeholders failed")
 	}
 
+	ctx, cancel := s.queryContext()
+	defer cancel()
+
 	start := time.Now()
-	err = s.conn().GetContext(s.Ctx, dest, query, args...)
+	err = s.read(func(conn Conn) error {
+		return conn.GetContext(ctx, dest, query, args...)
+	})
 	s.log("get", start, query, args)
+	s.observe("get", start, query, 1, err)
 
 	if err == nil {
 		return nil
 	}
 
+	if s.timedOut(ctx) {
+		return ErrTimeout
+	}
+
 	if s.cancelled(err) {
 		return ErrCancelled
 	}
@@ -204,14 +257,22 @@ func (s *Session) ExecRaw(query string, args ...interface{}) (sql.Result, error)
 		return nil, errors.Wrap(err, "replace placeholders failed")
 	}
 
+	ctx, cancel := s.queryContext()
+	defer cancel()
+
 	start := time.Now()
-	result, err := s.conn().ExecContext(s.Ctx, query, args...)
+	result, err := s.conn().ExecContext(ctx, query, args...)
 	s.log("exec", start, query, args)
+	s.observe("exec", start, query, rowsAffected(result, err), err)
 
 	if err == nil {
 		return result, nil
 	}
 
+	if s.timedOut(ctx) {
+		return nil, ErrTimeout
+	}
+
 	if s.cancelled(err) {
 		return nil, ErrCancelled
 	}
@@ -229,6 +290,28 @@ func (s *Session) NoRows(err error) bool {
 	return err == sql.ErrNoRows
 }
 
+// queryContext returns the context of a single query, which is Ctx limited by
+// QueryTimeout when it is set.
+func (s *Session) queryContext() (context.Context, context.CancelFunc) {
+	ctx := s.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if s.QueryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.QueryTimeout)
+}
+
+// timedOut returns true if the query run with ctx returned by queryContext
+// was cancelled because of QueryTimeout rather than cancellation of Ctx.
+func (s *Session) timedOut(ctx context.Context) bool {
+	if s.QueryTimeout <= 0 || ctx.Err() != context.DeadlineExceeded {
+		return false
+	}
+	return s.Ctx == nil || s.Ctx.Err() == nil
+}
+
 // Cancelled returns true if the provided error resulted from a cancel.
 func (s *Session) cancelled(err error) bool {
 	return strings.Contains(err.Error(), "pq: canceling statement due to user request")
@@ -251,8 +334,15 @@ func (s *Session) QueryRaw(query string, args ...interface{}) (*sqlx.Rows, error
 	}
 
 	start := time.Now()
-	result, err := s.conn().QueryxContext(s.Ctx, query, args...)
+	var result *sqlx.Rows
+	err = s.read(func(conn Conn) error {
+		var queryErr error
+		result, queryErr = conn.QueryxContext(s.Ctx, query, args...)
+		return queryErr
+	})
 	s.log("query", start, query, args)
+	// rows are read after QueryRaw returns so they can't be counted
+	s.observe("query", start, query, -1, err)
 
 	if err == nil {
 		return result, nil
@@ -289,6 +379,9 @@ func (s *Session) Rollback() error {
 
 	err := s.tx.Rollback()
 	s.logRollback()
+	if s.Statements != nil {
+		s.Statements.release(s.tx)
+	}
 	s.tx = nil
 	s.txOptions = nil
 	return err
@@ -315,14 +408,24 @@ func (s *Session) SelectRaw(
 		return errors.Wrap(err, "replace placeholders failed")
 	}
 
+	ctx, cancel := s.queryContext()
+	defer cancel()
+
 	start := time.Now()
-	err = s.conn().SelectContext(s.Ctx, dest, query, args...)
+	err = s.read(func(conn Conn) error {
+		return conn.SelectContext(ctx, dest, query, args...)
+	})
 	s.log("select", start, query, args)
+	s.observe("select", start, query, sliceLen(dest), err)
 
 	if err == nil {
 		return nil
 	}
 
+	if s.timedOut(ctx) {
+		return ErrTimeout
+	}
+
 	if s.cancelled(err) {
 		return ErrCancelled
 	}
@@ -368,13 +471,40 @@ func (s *Session) clearSliceIfPossible(dest interface{}) {
 
 func (s *Session) conn() Conn {
 	if s.tx != nil {
-		return s.tx
+		return s.prepared(s.tx, s.DB, s.tx)
+	}
+
+	return s.prepared(s.DB, s.DB, nil)
+}
+
+// prepared returns conn or, when Statements is set, a Conn running queries
+// with statements prepared on db (in tx when it is not nil).
+func (s *Session) prepared(conn Conn, db *sqlx.DB, tx *sqlx.Tx) Conn {
+	if s.Statements == nil {
+		return conn
+	}
+	return &preparedConn{cache: s.Statements, db: db, tx: tx}
+}
+
+// read runs a read query on a replica when the session was returned by
+// Replica, falling back to conn() when there is no healthy replica or the
+// replica connection failed.
+func (s *Session) read(query func(conn Conn) error) error {
+	if s.tx == nil && s.useReplica && s.Replicas != nil {
+		if replica := s.Replicas.pick(); replica != nil {
+			err := query(s.prepared(replica.db, replica.db, nil))
+			if err == nil || !isConnectionError(err) {
+				return err
+			}
+			s.Replicas.markUnhealthy(replica, err)
+		}
 	}
 
-	return s.DB
+	return query(s.conn())
 }
 
 func (s *Session) log(typ string, start time.Time, query string, args []interface{}) {
+	s.record(typ, query, args)
 	log.
 		Ctx(s.logCtx()).
 		WithField("args", args).
@@ -383,6 +513,20 @@ func (s *Session) log(typ string, start time.Time, query string, args []interfac
 		Debugf("sql: %s", typ)
 }
 
+// observe records the query in Metrics, if set, and in the QueryTimer of the
+// session context.
+func (s *Session) observe(typ string, start time.Time, query string, rows int64, err error) {
+	s.time(start)
+	if s.Metrics == nil {
+		return
+	}
+	name := s.queryName
+	if name == "" {
+		name = queryName(query)
+	}
+	s.Metrics.observe(typ, name, start, rows, err)
+}
+
 func (s *Session) logBegin() {
 	log.Ctx(s.logCtx()).Debug("sql: begin")
 }
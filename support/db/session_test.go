@@ -2,8 +2,11 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/stellar/go/support/db/dbtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -67,8 +70,18 @@ func TestSession(t *testing.T) {
 	err = sess.GetRaw(&count, "SELECT COUNT(*) FROM people")
 	assert.NoError(err)
 	assert.Equal(0, count, "people did not appear deleted inside transaction")
+
+	// WithContext stays within the transaction
+	err = sess.WithContext(context.Background()).GetRaw(&count, "SELECT COUNT(*) FROM people")
+	assert.NoError(err)
+	assert.Equal(0, count, "people did not appear deleted inside transaction")
 	assert.NoError(sess.Rollback(), "rollback failed")
 
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = sess.WithContext(cancelled).GetRaw(&count, "SELECT COUNT(*) FROM people")
+	assert.Error(err)
+
 	// Ensure commit works
 	require.NoError(sess.Begin(), "begin failed")
 	sess.ExecRaw("DELETE FROM people")
@@ -90,3 +103,84 @@ func TestSession(t *testing.T) {
 		assert.Equal("$1 = $2 = $3 = ?", out)
 	}
 }
+
+func TestSessionQueryTimeout(t *testing.T) {
+	db := dbtest.Postgres(t).Load(testSchema)
+	defer db.Close()
+
+	assert := assert.New(t)
+	sess := &Session{DB: db.Open(), Ctx: context.Background()}
+	defer sess.DB.Close()
+
+	// no timeout by default
+	_, err := sess.ExecRaw("SELECT pg_sleep(0.1)")
+	assert.NoError(err)
+
+	sess.QueryTimeout = 10 * time.Millisecond
+	assert.Equal(sess.QueryTimeout, sess.Clone().QueryTimeout)
+	assert.Equal(sess.QueryTimeout, sess.WithContext(context.Background()).QueryTimeout)
+
+	_, err = sess.ExecRaw("SELECT pg_sleep(1)")
+	assert.Equal(ErrTimeout, err)
+
+	var result string
+	err = sess.GetRaw(&result, "SELECT pg_sleep(1)::text")
+	assert.Equal(ErrTimeout, err)
+
+	var results []string
+	err = sess.SelectRaw(&results, "SELECT pg_sleep(1)::text")
+	assert.Equal(ErrTimeout, err)
+
+	// fast queries are not affected
+	var count int
+	err = sess.GetRaw(&count, "SELECT COUNT(*) FROM people")
+	assert.NoError(err)
+	assert.Equal(3, count)
+
+	// cancelling the session context is not a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	sess.Ctx = ctx
+	sess.QueryTimeout = time.Minute
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = sess.ExecRaw("SELECT pg_sleep(1)")
+	assert.Equal(ErrCancelled, err)
+}
+
+func TestSessionCopies(t *testing.T) {
+	tx := &sqlx.Tx{}
+	sess := &Session{
+		Ctx:          context.Background(),
+		QueryTimeout: time.Second,
+		Metrics:      &QueryMetrics{},
+		Statements:   &StatementCache{},
+		tx:           tx,
+		txOptions:    &sql.TxOptions{ReadOnly: true},
+		queryName:    "name",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	withContext := sess.WithContext(ctx)
+	assert.Equal(t, ctx, withContext.Ctx)
+	withContext.Ctx = sess.Ctx
+	assert.Equal(t, sess, withContext)
+	assert.False(t, sess == withContext)
+
+	named := sess.Named("other")
+	assert.Equal(t, "other", named.queryName)
+	assert.Equal(t, tx, named.tx)
+	assert.Equal(t, "name", sess.queryName)
+
+	replica := sess.Replica()
+	assert.True(t, replica.useReplica)
+	assert.Equal(t, tx, replica.tx)
+	assert.False(t, sess.useReplica)
+
+	clone := replica.Clone()
+	assert.Equal(t, &Session{
+		Ctx:          sess.Ctx,
+		QueryTimeout: sess.QueryTimeout,
+		Metrics:      sess.Metrics,
+		Statements:   sess.Statements,
+	}, clone)
+}
@@ -0,0 +1,253 @@
+package db
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// StatementCache is a cache of prepared statements keyed by SQL text. When
+// set on a Session, queries are run with prepared statements so the database
+// doesn't parse and plan frequent queries again on every call. The least
+// recently used statements are closed when the cache is full. Queries which
+// the database refuses to prepare are cached too so that they are not sent to
+// be prepared again. It is safe for concurrent use.
+type StatementCache struct {
+	size int
+
+	mutex      sync.Mutex
+	lru        *list.List
+	statements map[statementKey]*list.Element
+	// txStatements are the statements prepared in open transactions, they
+	// are closed with their transaction.
+	txStatements map[*sqlx.Tx]map[string]*sqlx.Stmt
+}
+
+type statementKey struct {
+	db    *sqlx.DB
+	query string
+}
+
+// cachedStatement is a prepared statement or, when stmt is nil, the error
+// returned by the database when preparing it.
+type cachedStatement struct {
+	key  statementKey
+	stmt *sqlx.Stmt
+	err  error
+}
+
+// NewStatementCache creates a StatementCache keeping at most size prepared
+// statements.
+func NewStatementCache(size int) *StatementCache {
+	return &StatementCache{
+		size:         size,
+		lru:          list.New(),
+		statements:   map[statementKey]*list.Element{},
+		txStatements: map[*sqlx.Tx]map[string]*sqlx.Stmt{},
+	}
+}
+
+// Len returns the number of statements in the cache.
+func (c *StatementCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Len()
+}
+
+// Close closes all the statements in the cache.
+func (c *StatementCache) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var result error
+	for element := c.lru.Front(); element != nil; element = element.Next() {
+		if err := element.Value.(*cachedStatement).close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	c.lru.Init()
+	c.statements = map[statementKey]*list.Element{}
+	return result
+}
+
+// get returns the statement of query prepared on db, preparing it if it's
+// not in the cache.
+func (c *StatementCache) get(ctx context.Context, db *sqlx.DB, query string) (*sqlx.Stmt, error) {
+	key := statementKey{db: db, query: query}
+
+	c.mutex.Lock()
+	if element, ok := c.statements[key]; ok {
+		c.lru.MoveToFront(element)
+		c.mutex.Unlock()
+		cached := element.Value.(*cachedStatement)
+		return cached.stmt, cached.err
+	}
+	c.mutex.Unlock()
+
+	// the statement is prepared without holding the lock so that a slow
+	// prepare doesn't block queries using other statements
+	stmt, err := db.PreparexContext(ctx, query)
+	if err != nil {
+		// only errors returned by the database are cached, connection
+		// errors and cancelled contexts are not caused by the query
+		if _, ok := err.(*pq.Error); !ok {
+			return nil, err
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.statements[key]; ok {
+		// prepared concurrently by another query
+		if stmt != nil {
+			stmt.Close()
+		}
+		c.lru.MoveToFront(element)
+		cached := element.Value.(*cachedStatement)
+		return cached.stmt, cached.err
+	}
+
+	c.statements[key] = c.lru.PushFront(&cachedStatement{key: key, stmt: stmt, err: err})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		evicted := oldest.Value.(*cachedStatement)
+		delete(c.statements, evicted.key)
+		// queries already running with the statement are not affected,
+		// queries about to use it fall back to an unprepared query
+		evicted.close()
+	}
+	return stmt, err
+}
+
+// getTx returns the statement of query prepared in tx. Statements are
+// prepared once per transaction, the first time they are used in it.
+func (c *StatementCache) getTx(ctx context.Context, db *sqlx.DB, tx *sqlx.Tx, query string) (*sqlx.Stmt, error) {
+	c.mutex.Lock()
+	if stmt, ok := c.txStatements[tx][query]; ok {
+		c.mutex.Unlock()
+		return stmt, nil
+	}
+	c.mutex.Unlock()
+
+	stmt, err := c.get(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	txStmt := tx.StmtxContext(ctx, stmt)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	statements, ok := c.txStatements[tx]
+	if !ok {
+		statements = map[string]*sqlx.Stmt{}
+		c.txStatements[tx] = statements
+	}
+	statements[query] = txStmt
+	return txStmt, nil
+}
+
+// release forgets the statements prepared in tx once it is committed or
+// rolled back, which closes them.
+func (c *StatementCache) release(tx *sqlx.Tx) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.txStatements, tx)
+}
+
+func (s *cachedStatement) close() error {
+	if s.stmt == nil {
+		return nil
+	}
+	return s.stmt.Close()
+}
+
+// preparedConn is a Conn running queries with statements from a
+// StatementCache prepared on db. When tx is set the statements are run in
+// the transaction.
+type preparedConn struct {
+	cache *StatementCache
+	db    *sqlx.DB
+	tx    *sqlx.Tx
+}
+
+func (c *preparedConn) conn() Conn {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.db
+}
+
+// stmt returns the prepared statement of query or nil if it can't be
+// prepared, in which case the query is run without a prepared statement.
+func (c *preparedConn) stmt(ctx context.Context, query string) *sqlx.Stmt {
+	var stmt *sqlx.Stmt
+	var err error
+	if c.tx != nil {
+		stmt, err = c.cache.getTx(ctx, c.db, c.tx, query)
+	} else {
+		stmt, err = c.cache.get(ctx, c.db, query)
+	}
+	if err != nil {
+		return nil
+	}
+	return stmt
+}
+
+func (c *preparedConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if stmt := c.stmt(ctx, query); stmt != nil {
+		result, err := stmt.ExecContext(ctx, args...)
+		if !statementClosed(err) {
+			return result, err
+		}
+	}
+	return c.conn().ExecContext(ctx, query, args...)
+}
+
+func (c *preparedConn) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if stmt := c.stmt(ctx, query); stmt != nil {
+		err := stmt.GetContext(ctx, dest, args...)
+		if !statementClosed(err) {
+			return err
+		}
+	}
+	return c.conn().GetContext(ctx, dest, query, args...)
+}
+
+func (c *preparedConn) Rebind(sql string) string {
+	return c.db.Rebind(sql)
+}
+
+func (c *preparedConn) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	if stmt := c.stmt(ctx, query); stmt != nil {
+		rows, err := stmt.QueryxContext(ctx, args...)
+		if !statementClosed(err) {
+			return rows, err
+		}
+	}
+	return c.conn().QueryxContext(ctx, query, args...)
+}
+
+func (c *preparedConn) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if stmt := c.stmt(ctx, query); stmt != nil {
+		err := stmt.SelectContext(ctx, dest, args...)
+		if !statementClosed(err) {
+			return err
+		}
+	}
+	return c.conn().SelectContext(ctx, dest, query, args...)
+}
+
+// statementClosed returns true if err is returned by a statement which was
+// evicted from the cache (and closed) by a concurrent query.
+func statementClosed(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "sql: statement is closed")
+}
+
+var _ Conn = (*preparedConn)(nil)
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stellar/go/support/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionStatements(t *testing.T) {
+	db := dbtest.Postgres(t).Load(testSchema)
+	defer db.Close()
+
+	sess := &Session{DB: db.Open(), Ctx: context.Background(), Statements: NewStatementCache(2)}
+	defer sess.DB.Close()
+	defer sess.Statements.Close()
+	assert.Equal(t, sess.Statements, sess.Clone().Statements)
+	assert.Equal(t, sess.Statements, sess.WithContext(context.Background()).Statements)
+
+	var count int
+	require.NoError(t, sess.GetRaw(&count, "SELECT COUNT(*) FROM people WHERE hunger_level = ?", 10))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 1, sess.Statements.Len())
+
+	// the same query with other arguments reuses the statement
+	require.NoError(t, sess.GetRaw(&count, "SELECT COUNT(*) FROM people WHERE hunger_level = ?", 1000000))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, sess.Statements.Len())
+
+	var names []string
+	require.NoError(t, sess.SelectRaw(&names, "SELECT name FROM people ORDER BY name"))
+	assert.Equal(t, []string{"bartek", "jed", "scott"}, names)
+	assert.Equal(t, 2, sess.Statements.Len())
+
+	// the least recently used statement is evicted
+	_, err := sess.ExecRaw("UPDATE people SET hunger_level = ? WHERE name = ?", 20, "jed")
+	require.NoError(t, err)
+	assert.Equal(t, 2, sess.Statements.Len())
+
+	// statements are run in transactions and prepared once per transaction
+	require.NoError(t, sess.Begin())
+	tx := sess.GetTx()
+	_, err = sess.ExecRaw("DELETE FROM people WHERE name = ?", "scott")
+	require.NoError(t, err)
+	require.NoError(t, sess.SelectRaw(&names, "SELECT name FROM people ORDER BY name"))
+	assert.Equal(t, []string{"bartek", "jed"}, names)
+	stmt, err := sess.Statements.getTx(context.Background(), sess.DB, tx, "SELECT name FROM people ORDER BY name")
+	require.NoError(t, err)
+	require.NoError(t, sess.SelectRaw(&names, "SELECT name FROM people ORDER BY name"))
+	assert.Len(t, sess.Statements.txStatements[tx], 2)
+	assert.Equal(t, stmt, sess.Statements.txStatements[tx]["SELECT name FROM people ORDER BY name"])
+	require.NoError(t, sess.Rollback())
+	assert.Empty(t, sess.Statements.txStatements)
+
+	require.NoError(t, sess.SelectRaw(&names, "SELECT name FROM people ORDER BY name"))
+	assert.Equal(t, []string{"bartek", "jed", "scott"}, names)
+
+	// a closed statement falls back to an unprepared query
+	require.NoError(t, sess.Statements.Close())
+	assert.Equal(t, 0, sess.Statements.Len())
+	stmt, err = sess.Statements.get(context.Background(), sess.DB, "SELECT name FROM people ORDER BY name")
+	require.NoError(t, err)
+	require.NoError(t, stmt.Close())
+	require.NoError(t, sess.SelectRaw(&names, "SELECT name FROM people ORDER BY name"))
+	assert.Len(t, names, 3)
+
+	// invalid queries return the same errors and are not prepared again
+	err = sess.GetRaw(&count, "SELECT COUNT(*) FROM unknown_table")
+	assert.Contains(t, err.Error(), `relation "unknown_table" does not exist`)
+	_, err = sess.Statements.get(context.Background(), sess.DB, "SELECT COUNT(*) FROM unknown_table")
+	assert.IsType(t, &pq.Error{}, err)
+	assert.Equal(t, 2, sess.Statements.Len())
+	err = sess.GetRaw(&count, "SELECT COUNT(*) FROM unknown_table")
+	assert.Contains(t, err.Error(), `relation "unknown_table" does not exist`)
+}
@@ -27,7 +27,9 @@ func (am *AccountMerge) BuildXDR() (xdr.Operation, error) {
 		return xdr.Operation{}, errors.Wrap(err, "failed to build XDR OperationBody")
 	}
 	op := xdr.Operation{Body: body}
-	SetOpSourceAccount(&op, am.SourceAccount)
+	if err := SetOpSourceAccount(&op, am.SourceAccount); err != nil {
+		return xdr.Operation{}, errors.Wrap(err, "failed to set source account")
+	}
 	return op, nil
 }
 
@@ -53,7 +53,9 @@ func (at *AllowTrust) BuildXDR() (xdr.Operation, error) {
 		return xdr.Operation{}, errors.Wrap(err, "failed to build XDR OperationBody")
 	}
 	op := xdr.Operation{Body: body}
-	SetOpSourceAccount(&op, at.SourceAccount)
+	if err := SetOpSourceAccount(&op, at.SourceAccount); err != nil {
+		return xdr.Operation{}, errors.Wrap(err, "failed to set source account")
+	}
 	return op, nil
 }
 
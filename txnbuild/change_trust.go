@@ -58,7 +58,9 @@ func (ct *ChangeTrust) BuildXDR() (xdr.Operation, error) {
 		return xdr.Operation{}, errors.Wrap(err, "failed to build XDR OperationBody")
 	}
 	op := xdr.Operation{Body: body}
-	SetOpSourceAccount(&op, ct.SourceAccount)
+	if err := SetOpSourceAccount(&op, ct.SourceAccount); err != nil {
+		return xdr.Operation{}, errors.Wrap(err, "failed to set source account")
+	}
 	return op, nil
 }
 
@@ -34,7 +34,9 @@ func (ca *CreateAccount) BuildXDR() (xdr.Operation, error) {
 		return xdr.Operation{}, errors.Wrap(err, "failed to build XDR OperationBody")
 	}
 	op := xdr.Operation{Body: body}
-	SetOpSourceAccount(&op, ca.SourceAccount)
+	if err := SetOpSourceAccount(&op, ca.SourceAccount); err != nil {
+		return xdr.Operation{}, errors.Wrap(err, "failed to set source account")
+	}
 	return op, nil
 }
 
@@ -1,6 +1,7 @@
 package txnbuild
 
 import (
+	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
 
@@ -12,14 +13,19 @@ type Operation interface {
 	GetSourceAccount() Account
 }
 
-// SetOpSourceAccount sets the source account ID on an Operation.
-func SetOpSourceAccount(op *xdr.Operation, sourceAccount Account) {
+// SetOpSourceAccount sets the source account ID on an Operation. The account id
+// can be a multiplexed account address (M...) since operation source accounts
+// are muxed accounts. An error is returned if the account id is invalid.
+func SetOpSourceAccount(op *xdr.Operation, sourceAccount Account) error {
 	if sourceAccount == nil {
-		return
+		return nil
 	}
 	var opSourceAccountID xdr.MuxedAccount
-	opSourceAccountID.SetAddress(sourceAccount.GetAccountID())
+	if err := opSourceAccountID.SetMuxedAddress(sourceAccount.GetAccountID()); err != nil {
+		return err
+	}
 	op.SourceAccount = &opSourceAccountID
+	return nil
 }
 
 // operationFromXDR returns a txnbuild Operation from its corresponding XDR operation
@@ -60,11 +66,27 @@ func operationFromXDR(xdrOp xdr.Operation) (Operation, error) {
 	return newOp, err
 }
 
-// accountFromXDR returns a txnbuild Account from a XDR Account.
+// validateOpSourceAccount returns an error if the source account of the
+// operation is set to an address which is neither an account id nor a
+// multiplexed account address.
+func validateOpSourceAccount(op Operation) error {
+	sourceAccount := op.GetSourceAccount()
+	if sourceAccount == nil {
+		return nil
+	}
+
+	var muxed xdr.MuxedAccount
+	if err := muxed.SetMuxedAddress(sourceAccount.GetAccountID()); err != nil {
+		return errors.Wrap(err, "invalid source account")
+	}
+	return nil
+}
+
+// accountFromXDR returns a txnbuild Account from a XDR Account. Multiplexed
+// accounts are preserved as multiplexed account addresses (M...).
 func accountFromXDR(account *xdr.MuxedAccount) Account {
 	if account != nil {
-		aid := account.ToAccountId()
-		return &SimpleAccount{AccountID: aid.Address()}
+		return &SimpleAccount{AccountID: account.Address()}
 	}
 	return nil
 }
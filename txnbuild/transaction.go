@@ -655,6 +655,9 @@ func NewTransaction(params TransactionParams) (*Transaction, error) {
 	}
 
 	for _, op := range tx.operations {
+		if verr := validateOpSourceAccount(op); verr != nil {
+			return nil, errors.Wrap(verr, fmt.Sprintf("validation failed for %T operation", op))
+		}
 		if verr := op.Validate(); verr != nil {
 			return nil, errors.Wrap(verr, fmt.Sprintf("validation failed for %T operation", op))
 		}
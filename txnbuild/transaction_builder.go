@@ -0,0 +1,200 @@
+package txnbuild
+
+import (
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// maxOperations is the maximum number of operations allowed in a transaction
+// by the Stellar protocol.
+const maxOperations = 100
+
+// TransactionBuilder is a mutable representation of a parsed transaction
+// envelope. It can be inspected, modified and then built into a new
+// Transaction which can be (re-)signed.
+//
+// Changing a transaction changes its hash so existing signatures become
+// invalid. Because of this any modification discards the signatures of the
+// parsed envelope. When the builder is not modified Build() returns the
+// parsed envelope unchanged, signatures included. The built envelope has the
+// type and the (muxed) source account of the parsed one.
+type TransactionBuilder struct {
+	parsed        *Transaction
+	modified      bool
+	sourceAccount SimpleAccount
+	baseFee       int64
+	operations    []Operation
+	memo          Memo
+	timebounds    Timebounds
+	signatures    []xdr.DecoratedSignature
+}
+
+// ParseTransaction parses the supplied transaction envelope in base64 XDR
+// and returns a TransactionBuilder instance. Fee bump transactions cannot
+// be modified without invalidating the inner transaction signatures so they
+// are not supported, parse the inner transaction instead.
+func ParseTransaction(txeB64 string) (*TransactionBuilder, error) {
+	parsed, err := TransactionFromXDR(txeB64)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, ok := parsed.Transaction()
+	if !ok {
+		return nil, errors.New("fee bump transactions cannot be modified")
+	}
+
+	return &TransactionBuilder{
+		parsed:        tx,
+		sourceAccount: tx.sourceAccount,
+		baseFee:       tx.baseFee,
+		operations:    append([]Operation{}, tx.operations...),
+		memo:          tx.memo,
+		timebounds:    tx.timebounds,
+		signatures:    append([]xdr.DecoratedSignature{}, tx.signatures...),
+	}, nil
+}
+
+// SourceAccount returns the source account of the transaction.
+func (b *TransactionBuilder) SourceAccount() SimpleAccount {
+	return b.sourceAccount
+}
+
+// BaseFee returns the per operation fee of the transaction.
+func (b *TransactionBuilder) BaseFee() int64 {
+	return b.baseFee
+}
+
+// Operations returns the list of operations of the transaction.
+// The contents of the returned slice should not be modified.
+func (b *TransactionBuilder) Operations() []Operation {
+	return b.operations
+}
+
+// Memo returns the memo of the transaction.
+func (b *TransactionBuilder) Memo() Memo {
+	return b.memo
+}
+
+// Timebounds returns the Timebounds of the transaction. It is not valid if
+// the parsed envelope had no time bounds, use SetTimebounds in such case.
+func (b *TransactionBuilder) Timebounds() Timebounds {
+	return b.timebounds
+}
+
+// Signatures returns the signatures which will be attached to the built
+// transaction. It is empty when the builder was modified.
+// The contents of the returned slice should not be modified.
+func (b *TransactionBuilder) Signatures() []xdr.DecoratedSignature {
+	return b.signatures
+}
+
+// AddOperations appends valid operations to the transaction. It returns an
+// error when an operation is invalid or the transaction would exceed the
+// maximum number of operations.
+func (b *TransactionBuilder) AddOperations(ops ...Operation) error {
+	if len(b.operations)+len(ops) > maxOperations {
+		return errors.Errorf("transaction cannot have more than %d operations", maxOperations)
+	}
+
+	for _, op := range ops {
+		if err := validateOpSourceAccount(op); err != nil {
+			return errors.Wrapf(err, "validation failed for %T operation", op)
+		}
+		if err := op.Validate(); err != nil {
+			return errors.Wrapf(err, "validation failed for %T operation", op)
+		}
+	}
+
+	b.operations = append(b.operations, ops...)
+	b.modify()
+	return nil
+}
+
+// SetTimebounds replaces the time bounds of the transaction.
+func (b *TransactionBuilder) SetTimebounds(timebounds Timebounds) error {
+	if err := timebounds.Validate(); err != nil {
+		return errors.Wrap(err, "invalid time bounds")
+	}
+
+	b.timebounds = timebounds
+	b.modify()
+	return nil
+}
+
+// SetMemo replaces the memo of the transaction. A nil memo removes it.
+func (b *TransactionBuilder) SetMemo(memo Memo) {
+	b.memo = memo
+	b.modify()
+}
+
+// SetBaseFee replaces the per operation fee of the transaction.
+func (b *TransactionBuilder) SetBaseFee(baseFee int64) error {
+	if baseFee < MinBaseFee {
+		return errors.Errorf(
+			"base fee cannot be lower than network minimum of %d", MinBaseFee,
+		)
+	}
+
+	b.baseFee = baseFee
+	b.modify()
+	return nil
+}
+
+// modify discards the signatures of the parsed envelope which are invalid
+// once the transaction changes.
+func (b *TransactionBuilder) modify() {
+	b.modified = true
+	b.signatures = nil
+}
+
+// Build returns a new Transaction with the current state of the builder.
+// The parsed envelope is returned with its signatures if the builder was not
+// modified. The sequence number of the source account is not incremented.
+func (b *TransactionBuilder) Build() (*Transaction, error) {
+	if !b.modified {
+		envelope, err := cloneEnvelope(b.parsed.envelope, nil)
+		if err != nil {
+			return nil, err
+		}
+		tx := new(Transaction)
+		*tx = *b.parsed
+		tx.envelope = envelope
+		tx.operations = append([]Operation{}, b.parsed.operations...)
+		tx.signatures = append([]xdr.DecoratedSignature{}, b.signatures...)
+		return tx, nil
+	}
+
+	sourceAccount := b.sourceAccount
+	tx, err := NewTransaction(TransactionParams{
+		SourceAccount:        &sourceAccount,
+		IncrementSequenceNum: false,
+		Operations:           b.operations,
+		BaseFee:              b.baseFee,
+		Memo:                 b.memo,
+		Timebounds:           b.timebounds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// NewTransaction builds V0 envelopes which can't have a muxed source
+	// account, V1 envelopes are rebuilt as V1 with their source account
+	if b.parsed.envelope.Type == xdr.EnvelopeTypeEnvelopeTypeTx {
+		v0 := tx.envelope.V0.Tx
+		tx.envelope = xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: b.parsed.envelope.SourceAccount(),
+					Fee:           v0.Fee,
+					SeqNum:        v0.SeqNum,
+					TimeBounds:    v0.TimeBounds,
+					Memo:          v0.Memo,
+					Operations:    v0.Operations,
+				},
+			},
+		}
+	}
+	return tx, nil
+}
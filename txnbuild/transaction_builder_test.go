@@ -0,0 +1,177 @@
+package txnbuild
+
+import (
+	"testing"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTransaction(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	sourceAccount := NewSimpleAccount(kp0.Address(), 10)
+
+	tx, err := NewTransaction(
+		TransactionParams{
+			SourceAccount: &sourceAccount,
+			Operations:    []Operation{&BumpSequence{BumpTo: 20}},
+			BaseFee:       MinBaseFee,
+			Memo:          MemoText("deposit"),
+			Timebounds:    NewTimebounds(0, 1000),
+		},
+	)
+	require.NoError(t, err)
+	tx, err = tx.Sign(network.TestNetworkPassphrase, kp0)
+	require.NoError(t, err)
+	expected, err := tx.Base64()
+	require.NoError(t, err)
+
+	builder, err := ParseTransaction(expected)
+	require.NoError(t, err)
+	assert.Equal(t, kp0.Address(), builder.SourceAccount().AccountID)
+	assert.Equal(t, int64(10), builder.SourceAccount().Sequence)
+	assert.Equal(t, int64(MinBaseFee), builder.BaseFee())
+	assert.Equal(t, MemoText("deposit"), builder.Memo())
+	assert.Equal(t, NewTimebounds(0, 1000), builder.Timebounds())
+	assert.Len(t, builder.Operations(), 1)
+	assert.Len(t, builder.Signatures(), 1)
+
+	// an unmodified builder keeps signatures
+	unmodified, err := builder.Build()
+	require.NoError(t, err)
+	actual, err := unmodified.Base64()
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	err = builder.AddOperations(&ManageData{Name: "co-signed", Value: []byte("yes"), SourceAccount: &SimpleAccount{AccountID: kp1.Address()}})
+	require.NoError(t, err)
+	require.NoError(t, builder.SetTimebounds(NewTimebounds(0, 2000)))
+	assert.Len(t, builder.Signatures(), 0)
+
+	modified, err := builder.Build()
+	require.NoError(t, err)
+	assert.Len(t, modified.Operations(), 2)
+	assert.Equal(t, int64(2*MinBaseFee), modified.MaxFee())
+	assert.Equal(t, int64(2000), modified.Timebounds().MaxTime)
+	assert.Equal(t, int64(10), modified.SourceAccount().Sequence)
+	assert.Len(t, modified.Signatures(), 0)
+
+	modified, err = modified.Sign(network.TestNetworkPassphrase, kp0, kp1)
+	require.NoError(t, err)
+	assert.Len(t, modified.Signatures(), 2)
+}
+
+func TestParseTransactionV1(t *testing.T) {
+	kp0 := newKeypair0()
+	sourceAccount := NewSimpleAccount(kp0.Address(), 10)
+
+	tx, err := NewTransaction(
+		TransactionParams{
+			SourceAccount: &sourceAccount,
+			Operations:    []Operation{&BumpSequence{BumpTo: 20}},
+			BaseFee:       MinBaseFee,
+			Timebounds:    NewTimebounds(0, 1000),
+		},
+	)
+	require.NoError(t, err)
+	convertToV1Tx(tx)
+	muxedSource := xdr.MuxedAccount{
+		Type: xdr.CryptoKeyTypeKeyTypeMuxedEd25519,
+		Med25519: &xdr.MuxedAccountMed25519{
+			Id:      123,
+			Ed25519: tx.envelope.V1.Tx.SourceAccount.MustEd25519(),
+		},
+	}
+	tx.envelope.V1.Tx.SourceAccount = muxedSource
+	tx, err = tx.Sign(network.TestNetworkPassphrase, kp0)
+	require.NoError(t, err)
+	expected, err := tx.Base64()
+	require.NoError(t, err)
+
+	builder, err := ParseTransaction(expected)
+	require.NoError(t, err)
+	assert.Equal(t, kp0.Address(), builder.SourceAccount().AccountID)
+
+	// an unmodified builder returns the parsed envelope
+	unmodified, err := builder.Build()
+	require.NoError(t, err)
+	actual, err := unmodified.Base64()
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	builder.SetMemo(MemoText("co-signed"))
+	modified, err := builder.Build()
+	require.NoError(t, err)
+	assert.Len(t, modified.Signatures(), 0)
+	envelope, err := modified.TxEnvelope()
+	require.NoError(t, err)
+	assert.Equal(t, xdr.EnvelopeTypeEnvelopeTypeTx, envelope.Type)
+	assert.Equal(t, muxedSource, envelope.SourceAccount())
+	assert.Equal(t, xdr.SequenceNumber(10), envelope.V1.Tx.SeqNum)
+	assert.Equal(t, xdr.Uint32(MinBaseFee), envelope.V1.Tx.Fee)
+	assert.Equal(t, xdr.TimePoint(1000), envelope.V1.Tx.TimeBounds.MaxTime)
+	assert.Equal(t, "co-signed", *envelope.V1.Tx.Memo.Text)
+	assert.Len(t, envelope.V1.Tx.Operations, 1)
+
+	modified, err = modified.Sign(network.TestNetworkPassphrase, kp0)
+	require.NoError(t, err)
+	modifiedB64, err := modified.Base64()
+	require.NoError(t, err)
+	reparsed, err := TransactionFromXDR(modifiedB64)
+	require.NoError(t, err)
+	reparsedTx, ok := reparsed.Transaction()
+	require.True(t, ok)
+	assert.Equal(t, MemoText("co-signed"), reparsedTx.Memo())
+}
+
+func TestParseTransactionValidation(t *testing.T) {
+	kp0 := newKeypair0()
+	sourceAccount := NewSimpleAccount(kp0.Address(), 10)
+
+	tx, err := NewTransaction(
+		TransactionParams{
+			SourceAccount: &sourceAccount,
+			Operations:    []Operation{&BumpSequence{BumpTo: 20}},
+			BaseFee:       MinBaseFee,
+			Timebounds:    NewInfiniteTimeout(),
+		},
+	)
+	require.NoError(t, err)
+
+	convertToV1Tx(tx)
+	feeBump, err := NewFeeBumpTransaction(
+		FeeBumpTransactionParams{
+			Inner:      tx,
+			FeeAccount: kp0.Address(),
+			BaseFee:    MinBaseFee,
+		},
+	)
+	require.NoError(t, err)
+	feeBumpB64, err := feeBump.Base64()
+	require.NoError(t, err)
+	_, err = ParseTransaction(feeBumpB64)
+	assert.EqualError(t, err, "fee bump transactions cannot be modified")
+
+	txB64, err := tx.Base64()
+	require.NoError(t, err)
+	builder, err := ParseTransaction(txB64)
+	require.NoError(t, err)
+
+	err = builder.AddOperations(&Payment{Destination: "invalid"})
+	assert.Contains(t, err.Error(), "validation failed for *txnbuild.Payment operation")
+
+	ops := make([]Operation, maxOperations)
+	for i := range ops {
+		ops[i] = &Inflation{}
+	}
+	err = builder.AddOperations(ops...)
+	assert.EqualError(t, err, "transaction cannot have more than 100 operations")
+	assert.Len(t, builder.Operations(), 1)
+
+	assert.EqualError(t, builder.SetBaseFee(1), "base fee cannot be lower than network minimum of 100")
+	err = builder.SetTimebounds(Timebounds{MinTime: 10})
+	assert.Contains(t, err.Error(), "invalid time bounds")
+}
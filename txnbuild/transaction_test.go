@@ -20,6 +20,65 @@ func TestMissingSourceAccount(t *testing.T) {
 	assert.EqualError(t, err, "transaction has no source account")
 }
 
+func TestMuxedOperationSourceAccount(t *testing.T) {
+	kp0 := newKeypair0()
+	sourceAccount := NewSimpleAccount(kp0.Address(), 1)
+	muxedAddress := "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK"
+
+	tx, err := NewTransaction(
+		TransactionParams{
+			SourceAccount: &sourceAccount,
+			Operations: []Operation{
+				&BumpSequence{
+					BumpTo:        10,
+					SourceAccount: &SimpleAccount{AccountID: muxedAddress},
+				},
+			},
+			BaseFee:    MinBaseFee,
+			Timebounds: NewInfiniteTimeout(),
+		},
+	)
+	assert.NoError(t, err)
+
+	envelope, err := tx.TxEnvelope()
+	assert.NoError(t, err)
+	assert.Equal(t, xdr.CryptoKeyTypeKeyTypeMuxedEd25519, envelope.Operations()[0].SourceAccount.Type)
+
+	b64, err := tx.Base64()
+	assert.NoError(t, err)
+	parsed, err := TransactionFromXDR(b64)
+	assert.NoError(t, err)
+	parsedTx, ok := parsed.Transaction()
+	assert.True(t, ok)
+	assert.Equal(t, muxedAddress, parsedTx.Operations()[0].GetSourceAccount().GetAccountID())
+
+	_, err = NewTransaction(
+		TransactionParams{
+			SourceAccount: &sourceAccount,
+			Operations: []Operation{
+				&BumpSequence{
+					BumpTo:        10,
+					SourceAccount: &SimpleAccount{AccountID: "GBADSOURCEACCOUNT"},
+				},
+			},
+			BaseFee:    MinBaseFee,
+			Timebounds: NewInfiniteTimeout(),
+		},
+	)
+	assert.EqualError(t, err, "validation failed for *txnbuild.BumpSequence operation: invalid source account: invalid address")
+
+	// transaction source accounts of v0 envelopes can't be muxed
+	_, err = NewTransaction(
+		TransactionParams{
+			SourceAccount: &SimpleAccount{AccountID: muxedAddress, Sequence: 1},
+			Operations:    []Operation{&Inflation{}},
+			BaseFee:       MinBaseFee,
+			Timebounds:    NewInfiniteTimeout(),
+		},
+	)
+	assert.Error(t, err)
+}
+
 func TestIncrementSequenceNum(t *testing.T) {
 	kp0 := newKeypair0()
 	sourceAccount := NewSimpleAccount(kp0.Address(), 1)
@@ -1,6 +1,7 @@
 package xdr
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 
@@ -22,7 +23,8 @@ func MustMuxedAddressPtr(address string) *MuxedAccount {
 }
 
 // SetAddress modifies the receiver, setting it's value to the MuxedAccount form
-// of the provided address.
+// of the provided address. Only account ids (G...) are accepted, see
+// SetMuxedAddress.
 func (m *MuxedAccount) SetAddress(address string) error {
 	if m == nil {
 		return nil
@@ -47,6 +49,66 @@ func (m *MuxedAccount) SetAddress(address string) error {
 
 }
 
+// SetMuxedAddress is like SetAddress but it also accepts multiplexed account
+// addresses (M...). It must only be used where the protocol allows muxed
+// accounts, M-strkeys are not accepted elsewhere while SEP23 is a draft.
+func (m *MuxedAccount) SetMuxedAddress(address string) error {
+	if m == nil {
+		return nil
+	}
+	if len(address) != 69 {
+		return m.SetAddress(address)
+	}
+
+	raw, err := strkey.DecodeMuxedAccount(address)
+	if err != nil {
+		return err
+	}
+	if len(raw) != 40 {
+		return errors.New("invalid address")
+	}
+	var muxed MuxedAccountMed25519
+	copy(muxed.Ed25519[:], raw[:32])
+	muxed.Id = Uint64(binary.BigEndian.Uint64(raw[32:]))
+	*m, err = NewMuxedAccount(CryptoKeyTypeKeyTypeMuxedEd25519, muxed)
+	return err
+}
+
+// Address returns the strkey encoded form of this MuxedAccount: an account id
+// (G...) or a multiplexed account address (M...). This method will panic if the
+// MuxedAccount is of an unknown type.
+func (m MuxedAccount) Address() string {
+	address, err := m.GetAddress()
+	if err != nil {
+		panic(err)
+	}
+	return address
+}
+
+// GetAddress returns the strkey encoded form of this MuxedAccount, and an
+// error if the MuxedAccount is of an unknown type.
+func (m MuxedAccount) GetAddress() (string, error) {
+	switch m.Type {
+	case CryptoKeyTypeKeyTypeEd25519:
+		ed, ok := m.GetEd25519()
+		if !ok {
+			return "", errors.New("could not get Ed25519")
+		}
+		return strkey.Encode(strkey.VersionByteAccountID, ed[:])
+	case CryptoKeyTypeKeyTypeMuxedEd25519:
+		muxed, ok := m.GetMed25519()
+		if !ok {
+			return "", errors.New("could not get Med25519")
+		}
+		raw := make([]byte, 40)
+		copy(raw, muxed.Ed25519[:])
+		binary.BigEndian.PutUint64(raw[32:], uint64(muxed.Id))
+		return strkey.EncodeMuxedAccount(raw)
+	default:
+		return "", fmt.Errorf("Unknown muxed account type: %v", m.Type)
+	}
+}
+
 // ToAccountId transforms a MuxedAccount to an AccountId, dropping the
 // memo Id if necessary
 func (m MuxedAccount) ToAccountId() AccountId {
@@ -26,6 +26,45 @@ var _ = Describe("xdr.MuxedAccount#Get/SetAddress()", func() {
 		err = muxed.SetAddress("G47QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVP2I")
 		Expect(err).Should(HaveOccurred())
 
+		err = muxed.SetMuxedAddress("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLL")
+		Expect(err).Should(HaveOccurred())
+
+	})
+
+	It("only accepts multiplexed addresses in SetMuxedAddress", func() {
+		var muxed MuxedAccount
+		err := muxed.SetAddress("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK")
+		Expect(err).Should(HaveOccurred())
+
+		err = muxed.SetMuxedAddress("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK")
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("xdr.MuxedAccount round trip", func() {
+	It("works with account ids", func() {
+		var muxed MuxedAccount
+		err := muxed.SetAddress("GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(muxed.Type).To(Equal(CryptoKeyTypeKeyTypeEd25519))
+		Expect(muxed.Address()).To(Equal("GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ"))
+	})
+
+	It("works with multiplexed addresses", func() {
+		var muxed MuxedAccount
+		err := muxed.SetMuxedAddress("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(muxed.Type).To(Equal(CryptoKeyTypeKeyTypeMuxedEd25519))
+		Expect(muxed.Med25519.Id).To(Equal(Uint64(9223372036854775808)))
+		aid := muxed.ToAccountId()
+		Expect(aid.Address()).To(Equal("GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ"))
+		Expect(muxed.Address()).To(Equal("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK"))
+
+		muxed.Med25519.Id = 0xcafebabe
+		address := muxed.Address()
+		var parsed MuxedAccount
+		Expect(parsed.SetMuxedAddress(address)).ShouldNot(HaveOccurred())
+		Expect(parsed).To(Equal(muxed))
 	})
 })
 